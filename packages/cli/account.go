@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	usersDomain "github.com/kajidog/aivis-cloud-cli/client/users/domain"
+)
+
+// accountReport is the account dashboard: the profile and balance, the
+// subscriptions, the usage of a period and the most recent transactions.
+// Sections that could not be fetched are left empty and listed in Errors.
+type accountReport struct {
+	User                *usersDomain.UserMe               `json:"user,omitempty"`
+	LastObservedCredits *paymentDomain.CreditObservation  `json:"last_observed_credits,omitempty"`
+	Subscriptions       []paymentDomain.Subscription      `json:"subscriptions"`
+	Usage               *paymentDomain.UsageSummary       `json:"usage,omitempty"`
+	RecentTransactions  []paymentDomain.CreditTransaction `json:"recent_transactions"`
+	Errors              map[string]string                 `json:"errors,omitempty"`
+}
+
+var accountCmd = &cobra.Command{
+	Use:   "account",
+	Short: "Show an account dashboard",
+	Long: `Show the account at a glance: profile and credit balance (with its breakdown
+and next expiration), subscriptions, usage of the current period and the most
+recent credit transactions.
+
+A section the API does not answer is reported as unavailable; the command only
+fails when none can be fetched.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		period, _ := cmd.Flags().GetString("period")
+		transactions, _ := cmd.Flags().GetInt("transactions")
+
+		switch outputFormat {
+		case "json", "table", "":
+		default:
+			return fmt.Errorf("unsupported output format: %s. Supported formats: json, table", outputFormat)
+		}
+
+		report := fetchAccountReport(context.Background(), period, transactions)
+		if len(report.Errors) == 4 {
+			return fmt.Errorf("failed to get account information: %s", report.Errors["user"])
+		}
+
+		if outputFormat == "json" {
+			return printJSON(report)
+		}
+		return printAccountReport(report, period)
+	},
+}
+
+// fetchAccountReport fetches the sections of the dashboard concurrently
+func fetchAccountReport(ctx context.Context, period string, transactions int) *accountReport {
+	report := &accountReport{Errors: map[string]string{}}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	fetch := func(section string, fn func() error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := fn(); err != nil {
+				mu.Lock()
+				report.Errors[section] = err.Error()
+				mu.Unlock()
+			}
+		}()
+	}
+
+	fetch("user", func() (err error) {
+		report.User, err = aivisClient.GetMe(ctx)
+		return err
+	})
+	fetch("subscriptions", func() error {
+		response, err := aivisClient.GetSubscriptions(ctx, 20, 0)
+		if err == nil {
+			report.Subscriptions = response.Subscriptions
+		}
+		return err
+	})
+	fetch("usage", func() (err error) {
+		report.Usage, err = aivisClient.GetUsageSummaries(ctx, period, nil, nil, "")
+		return err
+	})
+	fetch("transactions", func() error {
+		response, err := aivisClient.GetCreditTransactions(ctx, "", "", nil, nil, transactions, 0)
+		if err == nil {
+			report.RecentTransactions = response.Transactions
+		}
+		return err
+	})
+	wg.Wait()
+
+	// Local state, not an API section
+	report.LastObservedCredits, _ = aivisClient.GetLastObservedCredits(ctx)
+	if len(report.Errors) == 0 {
+		report.Errors = nil
+	}
+	return report
+}
+
+func printAccountReport(report *accountReport, period string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "Account")
+	if user := report.User; user != nil {
+		fmt.Fprintf(w, "  Name:\t%s (@%s)\n", user.Name, user.Handle)
+		if user.Email != "" {
+			fmt.Fprintf(w, "  Email:\t%s\n", user.Email)
+		}
+		fmt.Fprintf(w, "  Credit balance:\t%s\n", describeCreditBalance(user.CreditBalance))
+		if user.CreditBalance != nil {
+			if next := user.CreditBalance.NextExpiration(time.Now()); next != nil {
+				kind := ""
+				if next.Kind != "" {
+					kind = " " + next.Kind
+				}
+				fmt.Fprintf(w, "  Next expiration:\t%s%s credits on %s\n", formatCredits(next.Credits), kind, next.ExpiresAt.Local().Format("2006-01-02"))
+			}
+		}
+	} else {
+		fmt.Fprintf(w, "  unavailable: %s\n", report.Errors["user"])
+	}
+	if last := report.LastObservedCredits; last != nil {
+		fmt.Fprintf(w, "  Last observed remaining:\t%s (%s)\n", formatCredits(last.Remaining), last.ObservedAt.Local().Format("2006-01-02 15:04"))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println()
+	fmt.Println("Subscriptions")
+	switch {
+	case report.Errors["subscriptions"] != "":
+		fmt.Printf("  unavailable: %s\n", report.Errors["subscriptions"])
+	case len(report.Subscriptions) == 0:
+		fmt.Println("  none")
+	default:
+		fmt.Fprintln(w, "  Plan\tStatus\tAmount\tPeriod ends\tRenews")
+		for _, subscription := range report.Subscriptions {
+			renews := "yes"
+			if subscription.CancelAtPeriodEnd {
+				renews = "no"
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%.2f %s\t%s\t%s\n", subscription.PlanName, subscription.Status,
+				subscription.Amount, subscription.Currency, subscription.CurrentPeriodEnd.Local().Format("2006-01-02"), renews)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Usage (%s)\n", period)
+	if usage := report.Usage; usage != nil {
+		fmt.Fprintf(w, "  Credits used:\t%d of %d\n", usage.UsedCredits, usage.TotalCredits)
+		fmt.Fprintf(w, "  Requests:\t%d\n", usage.TTSRequests)
+		fmt.Fprintf(w, "  Audio:\t%.1f minutes\n", usage.AudioMinutes)
+		if len(usage.BreakdownByModel) > 0 {
+			fmt.Fprintln(w, "  Model\tRequests\tCredits\tMinutes")
+			for _, model := range usage.BreakdownByModel {
+				name := model.ModelName
+				if name == "" {
+					name = model.ModelID
+				}
+				fmt.Fprintf(w, "  %s\t%d\t%d\t%.1f\n", name, model.Requests, model.Credits, model.AudioMinutes)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("  unavailable: %s\n", report.Errors["usage"])
+	}
+
+	fmt.Println()
+	fmt.Println("Recent transactions")
+	switch {
+	case report.Errors["transactions"] != "":
+		fmt.Printf("  unavailable: %s\n", report.Errors["transactions"])
+	case len(report.RecentTransactions) == 0:
+		fmt.Println("  none")
+	default:
+		fmt.Fprintln(w, "  Date\tType\tStatus\tCredits\tDescription")
+		for _, transaction := range report.RecentTransactions {
+			credits := "-"
+			if transaction.Credits != nil {
+				credits = fmt.Sprintf("%d", *transaction.Credits)
+			}
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\n", transaction.CreatedAt.Local().Format("2006-01-02 15:04"),
+				transaction.Type, transaction.Status, credits, transaction.Description)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	accountCmd.Flags().String("output", "table", "Output format (table, json)")
+	accountCmd.Flags().String("period", "month", "Usage period (day, week, month, year)")
+	accountCmd.Flags().Int("transactions", 5, "Number of recent transactions to show")
+}
@@ -42,6 +42,14 @@ func parseFloatInRange(min, max float64) func(string) (any, error) {
     }
 }
 
+func parseFloatNonNegative(s string) (any, error) {
+    f, err := strconv.ParseFloat(s, 64)
+    if err != nil || f < 0 {
+        return nil, fmt.Errorf("expected non-negative number")
+    }
+    return f, nil
+}
+
 func parseIntPositive(s string) (any, error) {
     i, err := strconv.Atoi(s)
     if err != nil || i <= 0 {
@@ -50,6 +58,14 @@ func parseIntPositive(s string) (any, error) {
     return i, nil
 }
 
+func parseIntNonNegative(s string) (any, error) {
+    i, err := strconv.Atoi(s)
+    if err != nil || i < 0 {
+        return nil, fmt.Errorf("expected non-negative integer")
+    }
+    return i, nil
+}
+
 func parseDuration(s string) (any, error) {
     d, err := time.ParseDuration(s)
     if err != nil {
@@ -72,6 +88,32 @@ func parseEnum(allowed ...string) func(string) (any, error) {
     }
 }
 
+// parseList validates a comma-separated list item by item and returns it
+// normalized, still as a string so that it reads the same from the config
+// file and from an environment variable
+func parseList(item func(string) (any, error)) func(string) (any, error) {
+    return func(s string) (any, error) {
+        items := splitList(s)
+        for _, i := range items {
+            if _, err := item(i); err != nil {
+                return nil, fmt.Errorf("%q: %v", i, err)
+            }
+        }
+        return strings.Join(items, ","), nil
+    }
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
 func getConfigSpecs() []ConfigSpec {
     return []ConfigSpec{
         {Key: "api_key", Type: "string", Description: "Aivis Cloud API key", Validate: func(s string) (any, error) { return s, nil }},
@@ -81,8 +123,17 @@ func getConfigSpecs() []ConfigSpec {
             return s, nil
         }},
         {Key: "timeout", Type: "duration", Description: "HTTP timeout (e.g. 60s)", Validate: parseDuration},
+        {Key: "max_retries", Type: "int", Description: "Retries for rate-limited/failed requests (>=0, 0 disables)", Validate: parseIntNonNegative},
+        {Key: "retry_base_delay", Type: "duration", Description: "Initial retry backoff delay (e.g. 500ms)", Validate: parseDuration},
+        {Key: "retry_max_delay", Type: "duration", Description: "Maximum retry backoff delay (e.g. 30s)", Validate: parseDuration},
+        {Key: "rate_limit_enabled", Type: "bool", Description: "Delay requests client-side when the API rate limit is exhausted", Validate: parseBool},
         {Key: "default_playback_mode", Type: "enum", Description: "Playback mode (immediate|queue|no_queue)", Validate: parseEnum("immediate", "queue", "no_queue")},
         {Key: "default_model_uuid", Type: "string", Description: "Default voice model UUID", Validate: func(s string) (any, error) { return s, nil }},
+        {Key: "active_profile", Type: "string", Description: "Voice profile used when --profile is not given (see 'config profile')", Validate: func(s string) (any, error) {
+            if s == "" { return s, nil }
+            if _, err := getProfile(s); err != nil { return nil, err }
+            return strings.ToLower(s), nil
+        }},
         {Key: "default_format", Type: "enum", Description: "Default audio format (mp3|wav|flac|aac|opus)", Validate: parseEnum("wav", "mp3", "flac", "aac", "opus")},
         {Key: "default_channels", Type: "enum", Description: "Audio channels (mono|stereo)", Validate: parseEnum("mono", "stereo")},
         {Key: "default_volume", Type: "number", Description: "Default TTS volume (0.0..2.0)", Validate: parseFloatInRange(0.0, 2.0)},
@@ -97,7 +148,28 @@ func getConfigSpecs() []ConfigSpec {
         {Key: "use_simplified_tts_tools", Type: "bool", Description: "Use simplified TTS tools for MCP", Validate: parseBool},
         {Key: "history_enabled", Type: "bool", Description: "Enable TTS history management", Validate: parseBool},
         {Key: "history_max_count", Type: "int", Description: "Max history records to keep (>0)", Validate: parseIntPositive},
+        {Key: "history_backend", Type: "enum", Description: "History storage backend (file|sqlite); sqlite migrates existing file history", Validate: parseEnum("file", "sqlite")},
         {Key: "history_store_path", Type: "string", Description: "History storage directory", Validate: func(s string) (any, error) { return s, nil }},
+        {Key: "cache_enabled", Type: "bool", Description: "Serve repeated synthesis requests from a local cache", Validate: parseBool},
+        {Key: "cache_max_size_mb", Type: "int", Description: "Max synthesis cache size in MB (>=0, 0 is unlimited)", Validate: parseIntNonNegative},
+        {Key: "cache_max_age", Type: "duration", Description: "Expire cached audio after this duration (e.g. 720h)", Validate: parseDuration},
+        {Key: "cache_path", Type: "string", Description: "Synthesis cache directory", Validate: func(s string) (any, error) { return s, nil }},
+        {Key: "use_daemon", Type: "bool", Description: "Forward playback to a running playback daemon", Validate: parseBool},
+        {Key: "daemon_socket", Type: "string", Description: "Playback daemon Unix socket path", Validate: func(s string) (any, error) { return s, nil }},
+        {Key: "budget_daily_credits", Type: "number", Description: "Daily credit budget; syntheses that would exceed it are refused or confirmed (0 is unlimited)", Validate: parseFloatNonNegative},
+        {Key: "budget_monthly_credits", Type: "number", Description: "Monthly credit budget; syntheses that would exceed it are refused or confirmed (0 is unlimited)", Validate: parseFloatNonNegative},
+        {Key: "budget_path", Type: "string", Description: "Credit spending ledger file (written only while a budget is set)", Validate: func(s string) (any, error) { return s, nil }},
+        {Key: "credit_alert_thresholds", Type: "list", Description: "Warn when the remaining credits fall to or below these values (comma-separated, e.g. 1000,100)", Validate: parseList(parseFloatNonNegative)},
+        {Key: "credit_alert_notifiers", Type: "list", Description: "Low-credit alert delivery (comma-separated: stderr,log,webhook,command; default stderr)", Validate: parseList(parseEnum("stderr", "log", "webhook", "command"))},
+        {Key: "credit_alert_webhook_url", Type: "string", Description: "URL that low-credit alerts are POSTed to as JSON (webhook notifier)", Validate: func(s string) (any, error) {
+            if s != "" && !strings.HasPrefix(s, "http://") && !strings.HasPrefix(s, "https://") {
+                return nil, fmt.Errorf("must start with http:// or https://")
+            }
+            return s, nil
+        }},
+        {Key: "credit_alert_command", Type: "string", Description: "Shell command run for low-credit alerts, e.g. notify-send \"Aivis\" \"$AIVIS_CREDITS_MESSAGE\" (command notifier)", Validate: func(s string) (any, error) { return s, nil }},
+        {Key: "credit_state_path", Type: "string", Description: "File recording the last observed credit balance", Validate: func(s string) (any, error) { return s, nil }},
+        {Key: "player_backend", Type: "enum", Description: "Audio player (command|native); native decodes WAV/FLAC/MP3 in process with real pause and volume (AAC/Opus still use the OS command)", Validate: parseEnum("command", "native")},
         {Key: "log_level", Type: "enum", Description: "Log level (DEBUG|INFO|WARN|ERROR)", Validate: parseEnum("DEBUG", "INFO", "WARN", "ERROR")},
         {Key: "log_output", Type: "enum|string", Description: "Log output (stdout|stderr|file path)", Validate: func(s string) (any, error) {
             if s == "stdout" || s == "stderr" || s == "" { return s, nil }
@@ -379,18 +451,26 @@ var configKeysCmd = &cobra.Command{
 }
 
 func saveConfig() error {
+	if _, err := configFilePath(); err != nil {
+		return err
+	}
+	return viper.WriteConfig()
+}
+
+// configFilePath returns the config file in use, selecting the default one
+// when there is none yet
+func configFilePath() (string, error) {
 	configFile := viper.ConfigFileUsed()
 	if configFile == "" {
 		// No config file set, create default one
 		home, err := os.UserHomeDir()
 		if err != nil {
-			return err
+			return "", err
 		}
 		configFile = fmt.Sprintf("%s/.aivis-cli.yaml", home)
 		viper.SetConfigFile(configFile)
 	}
-
-	return viper.WriteConfig()
+	return configFile, nil
 }
 
 func init() {
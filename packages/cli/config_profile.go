@@ -0,0 +1,397 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Profiles are stored in the config file as profiles.<name>; viper lowercases
+// keys, so names are restricted to lowercase letters, digits, - and _.
+var profileNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func validateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q (use lowercase letters, digits, - and _)", name)
+	}
+	return nil
+}
+
+func loadProfiles() (map[string]*ttsDomain.VoiceProfile, error) {
+	profiles := make(map[string]*ttsDomain.VoiceProfile)
+	if err := viper.UnmarshalKey("profiles", &profiles); err != nil {
+		return nil, fmt.Errorf("failed to read profiles from configuration: %v", err)
+	}
+	for name, profile := range profiles {
+		if profile == nil {
+			delete(profiles, name)
+		}
+	}
+	return profiles, nil
+}
+
+func getProfile(name string) (*ttsDomain.VoiceProfile, error) {
+	profiles, err := loadProfiles()
+	if err != nil {
+		return nil, err
+	}
+	profile, ok := profiles[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found. See 'config profile list'", name)
+	}
+	return profile, nil
+}
+
+// resolveProfile returns the named profile, or the active profile when name is
+// empty. It returns nil when no profile is selected.
+func resolveProfile(name string) (*ttsDomain.VoiceProfile, error) {
+	if name == "" {
+		name = viper.GetString("active_profile")
+	}
+	if name == "" {
+		return nil, nil
+	}
+	return getProfile(name)
+}
+
+// profileToMap converts a profile to the plain map viper writes to the file
+func profileToMap(p *ttsDomain.VoiceProfile) map[string]any {
+	m := make(map[string]any)
+	setString := func(key, value string) {
+		if value != "" {
+			m[key] = value
+		}
+	}
+	setFloat := func(key string, value *float64) {
+		if value != nil {
+			m[key] = *value
+		}
+	}
+	setString("model_uuid", p.ModelUUID)
+	setString("speaker_uuid", p.SpeakerUUID)
+	if p.StyleID != nil {
+		m["style_id"] = *p.StyleID
+	}
+	setString("style_name", p.StyleName)
+	setFloat("speaking_rate", p.SpeakingRate)
+	setFloat("emotional_intensity", p.EmotionalIntensity)
+	setFloat("tempo_dynamics", p.TempoDynamics)
+	setFloat("pitch", p.Pitch)
+	setFloat("volume", p.Volume)
+	setString("output_format", string(p.OutputFormat))
+	setString("user_dictionary_uuid", p.UserDictionaryUUID)
+	return m
+}
+
+// saveProfiles writes profiles to the config file, clearing the active
+// profile when it no longer exists
+func saveProfiles(profiles map[string]*ttsDomain.VoiceProfile) error {
+	values := make(map[string]any, len(profiles))
+	for name, profile := range profiles {
+		values[name] = profileToMap(profile)
+	}
+	return rewriteConfigFile(func(settings map[string]any) {
+		settings["profiles"] = values
+		if active, _ := settings["active_profile"].(string); active != "" && profiles[active] == nil {
+			delete(settings, "active_profile")
+		}
+	})
+}
+
+// rewriteConfigFile applies update to the settings stored in the config file
+// and writes them back. viper cannot delete keys that were read from the file,
+// so removals go through a fresh instance holding only the file's settings.
+func rewriteConfigFile(update func(settings map[string]any)) error {
+	configFile, err := configFilePath()
+	if err != nil {
+		return err
+	}
+
+	settings := make(map[string]any)
+	if _, err := os.Stat(configFile); err == nil {
+		file := viper.New()
+		file.SetConfigFile(configFile)
+		if err := file.ReadInConfig(); err != nil {
+			return err
+		}
+		settings = file.AllSettings()
+	}
+	update(settings)
+
+	file := viper.New()
+	if err := file.MergeConfigMap(settings); err != nil {
+		return err
+	}
+	if err := file.WriteConfigAs(configFile); err != nil {
+		return err
+	}
+	return viper.ReadInConfig()
+}
+
+func printProfile(name string, p *ttsDomain.VoiceProfile) {
+	fmt.Printf("Profile: %s", name)
+	if name == viper.GetString("active_profile") {
+		fmt.Print(" (active)")
+	}
+	fmt.Println()
+
+	printField := func(label, value string) {
+		if value != "" {
+			fmt.Printf("  %s: %s\n", label, value)
+		}
+	}
+	printFloat := func(label string, value *float64) {
+		if value != nil {
+			fmt.Printf("  %s: %.2f\n", label, *value)
+		}
+	}
+	printField("Model UUID", p.ModelUUID)
+	printField("Speaker UUID", p.SpeakerUUID)
+	if p.StyleID != nil {
+		fmt.Printf("  Style ID: %d\n", *p.StyleID)
+	}
+	printField("Style Name", p.StyleName)
+	printFloat("Speaking Rate", p.SpeakingRate)
+	printFloat("Emotional Intensity", p.EmotionalIntensity)
+	printFloat("Tempo Dynamics", p.TempoDynamics)
+	printFloat("Pitch", p.Pitch)
+	printFloat("Volume", p.Volume)
+	printField("Output Format", string(p.OutputFormat))
+	printField("User Dictionary UUID", p.UserDictionaryUUID)
+}
+
+var configProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named voice profiles",
+	Long: `Manage named voice profiles stored in the configuration file under profiles.<name>.
+
+A profile bundles a voice (model, speaker and style), voice parameters, an output
+format and a user dictionary. Select one with --profile on any tts command, or make
+it the default with 'config profile use'. Explicit flags always win over the profile,
+and the profile wins over the default_* settings.`,
+}
+
+var configProfileAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or update a voice profile",
+	Example: `  aivis-cloud-cli config profile add narrator --model-uuid <model-uuid> --style-name Calm --rate 0.9
+  aivis-cloud-cli config profile add narrator --pitch 0.1 --update`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := validateProfileName(name); err != nil {
+			return err
+		}
+		profiles, err := loadProfiles()
+		if err != nil {
+			return err
+		}
+
+		update, _ := cmd.Flags().GetBool("update")
+		profile, exists := profiles[name]
+		switch {
+		case exists && !update:
+			return fmt.Errorf("profile %q already exists. Use --update to change it", name)
+		case !exists && update:
+			return fmt.Errorf("profile %q not found", name)
+		case !exists:
+			profile = &ttsDomain.VoiceProfile{}
+		}
+
+		flags := cmd.Flags()
+		if flags.Changed("model-uuid") {
+			profile.ModelUUID, _ = flags.GetString("model-uuid")
+		}
+		if flags.Changed("speaker-uuid") {
+			profile.SpeakerUUID, _ = flags.GetString("speaker-uuid")
+		}
+		if flags.Changed("style-id") {
+			styleID, _ := flags.GetInt("style-id")
+			profile.StyleID, profile.StyleName = &styleID, ""
+		}
+		if flags.Changed("style-name") {
+			profile.StyleName, _ = flags.GetString("style-name")
+			profile.StyleID = nil
+		}
+		for flag, field := range map[string]**float64{
+			"rate":                &profile.SpeakingRate,
+			"emotional-intensity": &profile.EmotionalIntensity,
+			"tempo-dynamics":      &profile.TempoDynamics,
+			"pitch":               &profile.Pitch,
+			"volume":              &profile.Volume,
+		} {
+			if flags.Changed(flag) {
+				value, _ := flags.GetFloat64(flag)
+				*field = &value
+			}
+		}
+		if flags.Changed("format") {
+			format, _ := flags.GetString("format")
+			profile.OutputFormat = ttsDomain.OutputFormat(format)
+		}
+		if flags.Changed("user-dictionary-uuid") {
+			profile.UserDictionaryUUID, _ = flags.GetString("user-dictionary-uuid")
+		}
+
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("invalid profile: %v", err)
+		}
+		profiles[name] = profile
+		if err := saveProfiles(profiles); err != nil {
+			return fmt.Errorf("failed to save configuration: %v", err)
+		}
+
+		if exists {
+			fmt.Printf("Updated profile %s\n", name)
+		} else {
+			fmt.Printf("Added profile %s\n", name)
+		}
+		return nil
+	},
+}
+
+var configProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List voice profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := loadProfiles()
+		if err != nil {
+			return err
+		}
+		if len(profiles) == 0 {
+			fmt.Println("No profiles configured. Add one with 'config profile add <name>'")
+			return nil
+		}
+
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		active := viper.GetString("active_profile")
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\tName\tModel\tStyle\tFormat")
+		for _, name := range names {
+			p := profiles[name]
+			marker := ""
+			if name == active {
+				marker = "*"
+			}
+			style := p.StyleName
+			if p.StyleID != nil {
+				style = fmt.Sprintf("%d", *p.StyleID)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", marker, name, p.ModelUUID, style, p.OutputFormat)
+		}
+		return w.Flush()
+	},
+}
+
+var configProfileShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a voice profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profile, err := getProfile(args[0])
+		if err != nil {
+			return err
+		}
+		printProfile(strings.ToLower(args[0]), profile)
+		return nil
+	},
+}
+
+var configProfileRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a voice profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		profiles, err := loadProfiles()
+		if err != nil {
+			return err
+		}
+		if _, ok := profiles[name]; !ok {
+			return fmt.Errorf("profile %q not found", name)
+		}
+
+		delete(profiles, name)
+		if err := saveProfiles(profiles); err != nil {
+			return fmt.Errorf("failed to save configuration: %v", err)
+		}
+
+		fmt.Printf("Removed profile %s\n", name)
+		return nil
+	},
+}
+
+var configProfileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Make a voice profile the default for tts commands",
+	Long:  "Make a voice profile the default for tts commands and MCP tools. Use --clear to stop using a default profile.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if clear, _ := cmd.Flags().GetBool("clear"); clear {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if clear, _ := cmd.Flags().GetBool("clear"); clear {
+			err := rewriteConfigFile(func(settings map[string]any) {
+				delete(settings, "active_profile")
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save configuration: %v", err)
+			}
+			fmt.Println("No profile is active")
+			return nil
+		}
+
+		name := strings.ToLower(args[0])
+		if _, err := getProfile(name); err != nil {
+			return err
+		}
+		err := rewriteConfigFile(func(settings map[string]any) {
+			settings["active_profile"] = name
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save configuration: %v", err)
+		}
+
+		fmt.Printf("Active profile: %s\n", name)
+		return nil
+	},
+}
+
+func init() {
+	configProfileAddCmd.Flags().String("model-uuid", "", "Voice model UUID")
+	configProfileAddCmd.Flags().String("speaker-uuid", "", "Speaker UUID")
+	configProfileAddCmd.Flags().Int("style-id", 0, "Style ID (0-31)")
+	configProfileAddCmd.Flags().String("style-name", "", "Style name")
+	configProfileAddCmd.Flags().Float64("rate", 0, "Speaking rate (0.5-2.0)")
+	configProfileAddCmd.Flags().Float64("emotional-intensity", 0, "Emotional intensity (0.0-2.0)")
+	configProfileAddCmd.Flags().Float64("tempo-dynamics", 0, "Tempo dynamics (0.0-2.0)")
+	configProfileAddCmd.Flags().Float64("pitch", 0, "Pitch (-1.0-1.0)")
+	configProfileAddCmd.Flags().Float64("volume", 0, "Volume (0.0-2.0)")
+	configProfileAddCmd.Flags().String("format", "", "Output format (wav, flac, mp3, aac, opus)")
+	configProfileAddCmd.Flags().String("user-dictionary-uuid", "", "User dictionary UUID")
+	configProfileAddCmd.Flags().Bool("update", false, "Change the given settings of an existing profile")
+	configProfileAddCmd.MarkFlagsMutuallyExclusive("style-id", "style-name")
+
+	configProfileUseCmd.Flags().Bool("clear", false, "Stop using a default profile")
+
+	configProfileCmd.AddCommand(configProfileAddCmd)
+	configProfileCmd.AddCommand(configProfileListCmd)
+	configProfileCmd.AddCommand(configProfileShowCmd)
+	configProfileCmd.AddCommand(configProfileRemoveCmd)
+	configProfileCmd.AddCommand(configProfileUseCmd)
+	configCmd.AddCommand(configProfileCmd)
+}
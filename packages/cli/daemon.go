@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client"
+	"github.com/spf13/cobra"
+)
+
+// daemonStartTimeout is how long 'daemon start' waits for the socket to answer
+const daemonStartTimeout = 10 * time.Second
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Manage the background playback daemon",
+	Long: `Run a long-lived playback daemon that owns the playback queue and audio player.
+
+While the daemon is running, 'tts play', 'tts control', 'tts volume' and
+'tts history play' forward to it over a Unix domain socket, so audio started by
+one command can be paused, stopped or inspected by the next. Set use_daemon to
+false to always play in-process, and daemon_socket to change the socket path
+(default ~/.aivis-cli/daemon.sock).`,
+}
+
+var daemonStartCmd = &cobra.Command{
+	Use:   "start",
+	Short: "Start the playback daemon in the background",
+	Long: `Start the playback daemon in the background and wait until it accepts
+connections. Its log is written next to the socket (daemon.log). Use
+--foreground to run it in the current terminal instead.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		socketPath, err := aivisClient.GetConfig().GetDaemonSocketPath()
+		if err != nil {
+			return fmt.Errorf("failed to get daemon socket path: %v", err)
+		}
+
+		if foreground, _ := cmd.Flags().GetBool("foreground"); foreground {
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			fmt.Fprintf(os.Stderr, "Playback daemon listening on %s (PID %d)\n", socketPath, os.Getpid())
+			if err := aivisClient.ServeDaemon(ctx); err != nil {
+				return fmt.Errorf("playback daemon failed: %v", err)
+			}
+			return nil
+		}
+
+		ctx := context.Background()
+		if status, err := aivisClient.GetDaemonStatus(ctx); err == nil {
+			fmt.Printf("Playback daemon is already running (PID %d)\n", status.PID)
+			return nil
+		}
+
+		executable, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to locate executable: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+			return fmt.Errorf("failed to create socket directory: %v", err)
+		}
+		// The log records the text of playback requests, so only the owner may read it
+		logPath := filepath.Join(filepath.Dir(socketPath), "daemon.log")
+		logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("failed to open daemon log: %v", err)
+		}
+		defer logFile.Close()
+		// Logs created by earlier versions were readable by everyone
+		if err := logFile.Chmod(0600); err != nil {
+			return fmt.Errorf("failed to restrict daemon log permissions: %v", err)
+		}
+
+		daemonArgs := []string{"daemon", "start", "--foreground"}
+		if cfgFile != "" {
+			daemonArgs = append(daemonArgs, "--config", cfgFile)
+		}
+		child := exec.Command(executable, daemonArgs...)
+		// Pass the key through the environment so it does not show up in ps
+		child.Env = append(os.Environ(), "AIVIS_API_KEY="+aivisClient.GetConfig().APIKey)
+		child.Stdout = logFile
+		child.Stderr = logFile
+		detachProcess(child)
+		if err := child.Start(); err != nil {
+			return fmt.Errorf("failed to start playback daemon: %v", err)
+		}
+
+		exited := make(chan error, 1)
+		go func() { exited <- child.Wait() }()
+		deadline := time.After(daemonStartTimeout)
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case err := <-exited:
+				return fmt.Errorf("playback daemon exited during startup (%v); see %s", err, logPath)
+			case <-deadline:
+				return fmt.Errorf("playback daemon did not start within %s; see %s", daemonStartTimeout, logPath)
+			case <-ticker.C:
+			}
+			if status, err := aivisClient.GetDaemonStatus(ctx); err == nil {
+				fmt.Printf("Playback daemon started (PID %d)\n", status.PID)
+				fmt.Printf("Socket: %s\n", status.SocketPath)
+				fmt.Printf("Log: %s\n", logPath)
+				return nil
+			}
+		}
+	},
+}
+
+var daemonStopCmd = &cobra.Command{
+	Use:   "stop",
+	Short: "Stop the playback daemon",
+	Long:  "Stop playback, clear the queue and shut the playback daemon down",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		if err := aivisClient.StopDaemon(ctx); err != nil {
+			if errors.Is(err, client.ErrDaemonNotRunning) {
+				fmt.Println("Playback daemon is not running")
+				return nil
+			}
+			return fmt.Errorf("failed to stop playback daemon: %v", err)
+		}
+
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(100 * time.Millisecond) {
+			if _, err := aivisClient.GetDaemonStatus(ctx); errors.Is(err, client.ErrDaemonNotRunning) {
+				fmt.Println("Playback daemon stopped")
+				return nil
+			}
+		}
+		return fmt.Errorf("playback daemon is still running after the shutdown request")
+	},
+}
+
+var daemonStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show playback daemon status",
+	Long:  "Show whether the playback daemon is running and what it is playing",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := aivisClient.GetDaemonStatus(context.Background())
+		if err != nil {
+			if errors.Is(err, client.ErrDaemonNotRunning) {
+				fmt.Println("Playback daemon is not running")
+				return nil
+			}
+			return fmt.Errorf("failed to get playback daemon status: %v", err)
+		}
+
+		fmt.Println("Playback daemon is running")
+		fmt.Printf("PID: %d\n", status.PID)
+		fmt.Printf("Socket: %s\n", status.SocketPath)
+		fmt.Printf("Started: %s (up %s)\n", status.StartedAt.Format("2006-01-02 15:04:05"),
+			time.Since(status.StartedAt).Round(time.Second))
+		fmt.Printf("Playback: %s\n", status.Playback.Status)
+		fmt.Printf("Queue Length: %d\n", status.Playback.QueueLength)
+		fmt.Printf("Volume: %.2f\n", status.Playback.Volume)
+		if status.Playback.CurrentText != "" {
+			fmt.Printf("Current Text: %s\n", truncateText(status.Playback.CurrentText, 60))
+		}
+		return nil
+	},
+}
+
+func init() {
+	daemonStartCmd.Flags().Bool("foreground", false, "Run the daemon in the current process instead of the background")
+
+	daemonCmd.AddCommand(daemonStartCmd)
+	daemonCmd.AddCommand(daemonStopCmd)
+	daemonCmd.AddCommand(daemonStatusCmd)
+}
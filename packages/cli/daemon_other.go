@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// detachProcess is a no-op where sessions are not available; the daemon runs
+// as a regular background child process
+func detachProcess(cmd *exec.Cmd) {}
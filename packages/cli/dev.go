@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/testing/mockapi"
+	"github.com/spf13/cobra"
+)
+
+var devCmd = &cobra.Command{
+	Use:   "dev",
+	Short: "Development tools",
+	// Development tools run locally and need no API client
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var devMockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Run a fake Aivis Cloud API for offline development",
+	Long: `Run a fake Aivis Cloud API on a local address.
+
+Speech synthesis returns a generated tone (one beep per character, with pauses at
+punctuation) as WAV or FLAC, with billing headers and chunked streaming. Model
+search, users, payment and user dictionary endpoints are served from built-in
+fixtures, and state such as the credit balance is kept in memory until the server
+stops. Errors, latency and rate limiting can be injected with flags.
+
+Point the CLI at it with the base_url setting or the environment:
+
+  AIVIS_BASE_URL=http://127.0.0.1:8787 AIVIS_API_KEY=mock aivis-cloud-cli tts play "こんにちは"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		addr, _ := cmd.Flags().GetString("addr")
+		quiet, _ := cmd.Flags().GetBool("quiet")
+
+		options := mockapi.Options{}
+		options.APIKey, _ = cmd.Flags().GetString("api-key")
+		options.Credits, _ = cmd.Flags().GetFloat64("credits")
+		options.CreditsPerCharacter, _ = cmd.Flags().GetFloat64("credits-per-character")
+		options.Latency, _ = cmd.Flags().GetDuration("latency")
+		options.LatencyJitter, _ = cmd.Flags().GetDuration("latency-jitter")
+		options.ErrorRate, _ = cmd.Flags().GetFloat64("error-rate")
+		options.ErrorStatus, _ = cmd.Flags().GetInt("error-status")
+		options.RateLimit, _ = cmd.Flags().GetInt("rate-limit")
+		options.StreamChunkSize, _ = cmd.Flags().GetInt("chunk-size")
+		options.StreamChunkDelay, _ = cmd.Flags().GetDuration("chunk-delay")
+		options.Seed, _ = cmd.Flags().GetInt64("seed")
+		if options.ErrorRate < 0 || options.ErrorRate > 1 {
+			return fmt.Errorf("--error-rate must be between 0 and 1")
+		}
+		if !quiet {
+			options.Log = os.Stderr
+		}
+
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen on %s: %v", addr, err)
+		}
+		server := &http.Server{Handler: mockapi.New(options), ReadHeaderTimeout: 10 * time.Second}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		url := "http://" + listener.Addr().String()
+		fmt.Fprintf(os.Stderr, "Mock Aivis Cloud API listening on %s\n", url)
+		fmt.Fprintf(os.Stderr, "Use it with: AIVIS_BASE_URL=%s AIVIS_API_KEY=%s aivis-cloud-cli ...\n", url, mockAPIKeyHint(options.APIKey))
+
+		served := make(chan error, 1)
+		go func() { served <- server.Serve(listener) }()
+
+		select {
+		case err := <-served:
+			if !errors.Is(err, http.ErrServerClosed) {
+				return fmt.Errorf("mock server failed: %v", err)
+			}
+			return nil
+		case <-ctx.Done():
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("failed to shut down mock server: %v", err)
+		}
+		fmt.Fprintln(os.Stderr, "Mock server stopped")
+		return nil
+	},
+}
+
+// mockAPIKeyHint returns the key to show in the usage hint
+func mockAPIKeyHint(apiKey string) string {
+	if apiKey == "" {
+		return "mock"
+	}
+	return apiKey
+}
+
+func init() {
+	devMockServerCmd.Flags().String("addr", "127.0.0.1:8787", "Address to listen on")
+	devMockServerCmd.Flags().String("api-key", "", "Only accept this API key (default: accept any key)")
+	devMockServerCmd.Flags().Float64("credits", mockapi.DefaultCredits, "Starting credit balance")
+	devMockServerCmd.Flags().Float64("credits-per-character", mockapi.DefaultCreditsPerCharacter, "Credits charged per synthesized character")
+	devMockServerCmd.Flags().Duration("latency", 0, "Delay added to every response")
+	devMockServerCmd.Flags().Duration("latency-jitter", 0, "Random extra delay of up to this duration")
+	devMockServerCmd.Flags().Float64("error-rate", 0, "Fraction of requests (0-1) answered with --error-status")
+	devMockServerCmd.Flags().Int("error-status", mockapi.DefaultErrorStatus, "HTTP status of injected errors")
+	devMockServerCmd.Flags().Int("rate-limit", 0, "Requests allowed per minute before 429 (0 disables)")
+	devMockServerCmd.Flags().Int("chunk-size", mockapi.DefaultStreamChunkSize, "Size in bytes of streamed audio chunks")
+	devMockServerCmd.Flags().Duration("chunk-delay", 0, "Pause between streamed audio chunks")
+	devMockServerCmd.Flags().Int64("seed", 0, "Random seed for error injection and jitter (default: time-based)")
+	devMockServerCmd.Flags().Bool("quiet", false, "Do not log requests to stderr")
+
+	devCmd.AddCommand(devMockServerCmd)
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+)
+
+var dictionariesCmd = &cobra.Command{
+	Use:     "dictionaries",
+	Aliases: []string{"dict"},
+	Short:   "User dictionary management commands",
+	Long: `Manage user dictionaries, which fix how words are read.
+
+Each word maps a surface form to a katakana pronunciation with an accent type
+(the mora after which the pitch falls, 0 for flat) and a priority from 0 to 10.
+Use a dictionary with 'tts play/synthesize --user-dictionary <uuid>' or the
+user_dictionary_uuid of a voice profile.`,
+}
+
+var dictionariesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List user dictionaries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, _ := cmd.Flags().GetString("output")
+
+		ctx := context.Background()
+		response, err := aivisClient.ListUserDictionaries(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list user dictionaries: %v", err)
+		}
+
+		switch outputFormat {
+		case "json":
+			return printJSON(response)
+		case "table", "":
+		default:
+			return fmt.Errorf("unsupported output format: %s. Supported formats: json, table", outputFormat)
+		}
+
+		if len(response.UserDictionaries) == 0 {
+			fmt.Println("No user dictionaries found. Create one with 'dictionaries create <name>'.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "UUID\tName\tWords\tUpdated")
+		for _, dictionary := range response.UserDictionaries {
+			updated := ""
+			if !dictionary.UpdatedAt.IsZero() {
+				updated = dictionary.UpdatedAt.Format("2006-01-02 15:04")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", dictionary.UUID, dictionary.Name, dictionary.WordCount, updated)
+		}
+		return w.Flush()
+	},
+}
+
+var dictionariesShowCmd = &cobra.Command{
+	Use:   "show <dictionary-uuid>",
+	Short: "Show a user dictionary and its words",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, _ := cmd.Flags().GetString("output")
+
+		ctx := context.Background()
+		dictionary, err := aivisClient.GetUserDictionary(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get user dictionary: %v", err)
+		}
+
+		switch outputFormat {
+		case "json":
+			return printJSON(dictionary)
+		case "table", "":
+		default:
+			return fmt.Errorf("unsupported output format: %s. Supported formats: json, table", outputFormat)
+		}
+
+		fmt.Printf("UUID: %s\n", dictionary.UUID)
+		fmt.Printf("Name: %s\n", dictionary.Name)
+		if dictionary.Description != "" {
+			fmt.Printf("Description: %s\n", dictionary.Description)
+		}
+		fmt.Printf("Words: %d\n", len(dictionary.Words))
+		if len(dictionary.Words) == 0 {
+			return nil
+		}
+
+		fmt.Println()
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Word UUID\tSurface\tPronunciation\tAccent\tType\tPriority")
+		for _, word := range dictionary.Words {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%d\n",
+				word.UUID, word.Surface, word.Pronunciation, word.AccentType, word.WordType, word.Priority)
+		}
+		return w.Flush()
+	},
+}
+
+var dictionariesCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create an empty user dictionary",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		description, _ := cmd.Flags().GetString("description")
+
+		ctx := context.Background()
+		dictionary, err := aivisClient.CreateUserDictionary(ctx, args[0], description)
+		if err != nil {
+			return fmt.Errorf("failed to create user dictionary: %v", err)
+		}
+
+		fmt.Printf("Created user dictionary %q: %s\n", dictionary.Name, dictionary.UUID)
+		return nil
+	},
+}
+
+var dictionariesUpdateCmd = &cobra.Command{
+	Use:   "update <dictionary-uuid>",
+	Short: "Rename a user dictionary or change its description",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		update := &domain.UserDictionaryUpdate{}
+		if cmd.Flags().Changed("name") {
+			name, _ := cmd.Flags().GetString("name")
+			update.Name = &name
+		}
+		if cmd.Flags().Changed("description") {
+			description, _ := cmd.Flags().GetString("description")
+			update.Description = &description
+		}
+		if update.Name == nil && update.Description == nil {
+			return fmt.Errorf("nothing to update: specify --name or --description")
+		}
+
+		ctx := context.Background()
+		dictionary, err := aivisClient.UpdateUserDictionary(ctx, args[0], update)
+		if err != nil {
+			return fmt.Errorf("failed to update user dictionary: %v", err)
+		}
+
+		fmt.Printf("Updated user dictionary %q: %s\n", dictionary.Name, dictionary.UUID)
+		return nil
+	},
+}
+
+var dictionariesDeleteCmd = &cobra.Command{
+	Use:   "delete <dictionary-uuid>",
+	Short: "Delete a user dictionary",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		if err := aivisClient.DeleteUserDictionary(ctx, args[0]); err != nil {
+			return fmt.Errorf("failed to delete user dictionary: %v", err)
+		}
+
+		fmt.Printf("Deleted user dictionary %s\n", args[0])
+		return nil
+	},
+}
+
+var dictionariesWordsCmd = &cobra.Command{
+	Use:   "words",
+	Short: "Add, update or remove dictionary words",
+}
+
+var dictionariesWordsAddCmd = &cobra.Command{
+	Use:   "add <dictionary-uuid> <surface> <pronunciation>",
+	Short: "Add a word to a user dictionary",
+	Long: `Add a word to a user dictionary. The pronunciation is written in katakana
+(hiragana is converted). With --replace, an existing word with the same surface
+form is updated instead of reported as a duplicate.`,
+	Example: `  aivis-cloud-cli dictionaries words add <uuid> Aivis アイビス --accent 1
+  aivis-cloud-cli dictionaries words add <uuid> 紅葉 もみじ --word-type COMMON_NOUN --replace`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		accent, _ := cmd.Flags().GetInt("accent")
+		wordType, _ := cmd.Flags().GetString("word-type")
+		priority, _ := cmd.Flags().GetInt("priority")
+		replace, _ := cmd.Flags().GetBool("replace")
+
+		word := domain.Word{
+			Surface:       args[1],
+			Pronunciation: args[2],
+			AccentType:    accent,
+			WordType:      domain.WordType(strings.ToUpper(wordType)),
+			Priority:      priority,
+		}
+
+		ctx := context.Background()
+		if !replace {
+			added, err := aivisClient.AddDictionaryWord(ctx, args[0], word)
+			if err != nil {
+				return fmt.Errorf("failed to add word: %v", err)
+			}
+			fmt.Printf("Added %s (%s): %s\n", added.Surface, added.Pronunciation, added.UUID)
+			return nil
+		}
+
+		saved, added, err := aivisClient.SetDictionaryWord(ctx, args[0], word)
+		if err != nil {
+			return fmt.Errorf("failed to save word: %v", err)
+		}
+		action := "Updated"
+		if added {
+			action = "Added"
+		}
+		fmt.Printf("%s %s (%s): %s\n", action, saved.Surface, saved.Pronunciation, saved.UUID)
+		return nil
+	},
+}
+
+var dictionariesWordsUpdateCmd = &cobra.Command{
+	Use:   "update <dictionary-uuid> <word-uuid>",
+	Short: "Change a word in a user dictionary",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		flags := cmd.Flags()
+		update := &domain.WordUpdate{}
+		changed := false
+		if flags.Changed("surface") {
+			surface, _ := flags.GetString("surface")
+			update.Surface = &surface
+			changed = true
+		}
+		if flags.Changed("pronunciation") {
+			pronunciation, _ := flags.GetString("pronunciation")
+			update.Pronunciation = &pronunciation
+			changed = true
+		}
+		if flags.Changed("accent") {
+			accent, _ := flags.GetInt("accent")
+			update.AccentType = &accent
+			changed = true
+		}
+		if flags.Changed("word-type") {
+			value, _ := flags.GetString("word-type")
+			wordType := domain.WordType(strings.ToUpper(value))
+			update.WordType = &wordType
+			changed = true
+		}
+		if flags.Changed("priority") {
+			priority, _ := flags.GetInt("priority")
+			update.Priority = &priority
+			changed = true
+		}
+		if !changed {
+			return fmt.Errorf("nothing to update: specify --surface, --pronunciation, --accent, --word-type or --priority")
+		}
+
+		ctx := context.Background()
+		word, err := aivisClient.UpdateDictionaryWord(ctx, args[0], args[1], update)
+		if err != nil {
+			return fmt.Errorf("failed to update word: %v", err)
+		}
+
+		fmt.Printf("Updated %s (%s): %s\n", word.Surface, word.Pronunciation, word.UUID)
+		return nil
+	},
+}
+
+var dictionariesWordsRemoveCmd = &cobra.Command{
+	Use:   "remove <dictionary-uuid> <word-uuid>",
+	Short: "Remove a word from a user dictionary",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		if err := aivisClient.DeleteDictionaryWord(ctx, args[0], args[1]); err != nil {
+			return fmt.Errorf("failed to remove word: %v", err)
+		}
+
+		fmt.Printf("Removed word %s\n", args[1])
+		return nil
+	},
+}
+
+var dictionariesImportCmd = &cobra.Command{
+	Use:   "import <dictionary-uuid> <file.csv>",
+	Short: "Import words from a CSV file",
+	Long: `Import words from a CSV file (- for stdin) into a user dictionary.
+
+The columns are surface, pronunciation, accent_type, word_type and priority.
+A header row with these names may reorder them; word_type (default PROPER_NOUN)
+and priority (default 5) may be omitted. Words whose surface form already exists
+are updated. With --replace, words missing from the file are removed. Nothing is
+saved if any row is invalid.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		replace, _ := cmd.Flags().GetBool("replace")
+
+		var input io.Reader = os.Stdin
+		if args[1] != "-" {
+			file, err := os.Open(args[1])
+			if err != nil {
+				return fmt.Errorf("failed to open CSV file: %v", err)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		ctx := context.Background()
+		result, err := aivisClient.ImportDictionaryCSV(ctx, args[0], input, replace)
+		if err != nil {
+			return fmt.Errorf("failed to import words: %v", err)
+		}
+
+		fmt.Printf("Imported words: %d added, %d updated, %d removed (%d in dictionary)\n",
+			result.Added, result.Updated, result.Removed, result.Total)
+		return nil
+	},
+}
+
+var dictionariesExportCmd = &cobra.Command{
+	Use:   "export <dictionary-uuid> [file.csv]",
+	Short: "Export words to a CSV file",
+	Long:  "Export the words of a user dictionary as CSV, to stdout unless a file is given. The output can be edited and imported again.",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+		if len(args) == 1 || args[1] == "-" {
+			_, err := aivisClient.ExportDictionaryCSV(ctx, args[0], os.Stdout)
+			if err != nil {
+				return fmt.Errorf("failed to export words: %v", err)
+			}
+			return nil
+		}
+
+		file, err := os.Create(args[1])
+		if err != nil {
+			return fmt.Errorf("failed to create CSV file: %v", err)
+		}
+		count, err := aivisClient.ExportDictionaryCSV(ctx, args[0], file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(args[1])
+			return fmt.Errorf("failed to export words: %v", err)
+		}
+
+		fmt.Printf("Exported %d words to %s\n", count, args[1])
+		return nil
+	},
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func init() {
+	dictionariesListCmd.Flags().String("output", "table", "Output format: table, json")
+	dictionariesShowCmd.Flags().String("output", "table", "Output format: table, json")
+	dictionariesCreateCmd.Flags().String("description", "", "Dictionary description")
+	dictionariesUpdateCmd.Flags().String("name", "", "New dictionary name")
+	dictionariesUpdateCmd.Flags().String("description", "", "New dictionary description")
+
+	dictionariesWordsAddCmd.Flags().Int("accent", 0, "Accent type: the mora after which the pitch falls (0 for flat)")
+	dictionariesWordsAddCmd.Flags().String("word-type", "", "Word type: PROPER_NOUN (default), COMMON_NOUN, VERB, ADJECTIVE, SUFFIX")
+	dictionariesWordsAddCmd.Flags().Int("priority", domain.DefaultWordPriority, "Priority from 0 (lowest) to 10 (highest)")
+	dictionariesWordsAddCmd.Flags().Bool("replace", false, "Update the word with the same surface form if it exists")
+
+	dictionariesWordsUpdateCmd.Flags().String("surface", "", "Surface form")
+	dictionariesWordsUpdateCmd.Flags().String("pronunciation", "", "Pronunciation in katakana")
+	dictionariesWordsUpdateCmd.Flags().Int("accent", 0, "Accent type: the mora after which the pitch falls (0 for flat)")
+	dictionariesWordsUpdateCmd.Flags().String("word-type", "", "Word type: PROPER_NOUN, COMMON_NOUN, VERB, ADJECTIVE, SUFFIX")
+	dictionariesWordsUpdateCmd.Flags().Int("priority", 0, "Priority from 0 (lowest) to 10 (highest)")
+
+	dictionariesImportCmd.Flags().Bool("replace", false, "Remove words that are not in the file")
+
+	dictionariesWordsCmd.AddCommand(dictionariesWordsAddCmd)
+	dictionariesWordsCmd.AddCommand(dictionariesWordsUpdateCmd)
+	dictionariesWordsCmd.AddCommand(dictionariesWordsRemoveCmd)
+
+	dictionariesCmd.AddCommand(dictionariesListCmd)
+	dictionariesCmd.AddCommand(dictionariesShowCmd)
+	dictionariesCmd.AddCommand(dictionariesCreateCmd)
+	dictionariesCmd.AddCommand(dictionariesUpdateCmd)
+	dictionariesCmd.AddCommand(dictionariesDeleteCmd)
+	dictionariesCmd.AddCommand(dictionariesWordsCmd)
+	dictionariesCmd.AddCommand(dictionariesImportCmd)
+	dictionariesCmd.AddCommand(dictionariesExportCmd)
+}
@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/kajidog/aivis-cloud-cli/client"
 	"github.com/kajidog/aivis-cloud-cli/client/config"
@@ -21,6 +24,13 @@ var (
 	aivisClient *client.Client
 )
 
+// Retry overrides; unset flags fall back to the config
+var (
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "aivis-cloud-cli",
 	Short: "Aivis Cloud CLI - Text-to-speech synthesis and model management",
@@ -28,11 +38,10 @@ var rootCmd = &cobra.Command{
 Features include text-to-speech synthesis, audio playback, and model management.`,
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		// Skip client initialization for config commands
-		if cmd.Parent() != nil && cmd.Parent().Name() == "config" {
-			return nil
-		}
-		if cmd.Name() == "config" {
-			return nil
+		for c := cmd; c != nil; c = c.Parent() {
+			if c.Name() == "config" {
+				return nil
+			}
 		}
 		return initializeClient()
 	},
@@ -47,13 +56,20 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "INFO", "log level (DEBUG, INFO, WARN, ERROR)")
 	rootCmd.PersistentFlags().StringVar(&logOutput, "log-output", "stdout", "log output destination (stdout, stderr, or file path)")
 	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "log output format (text, json)")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", -1, "retries for rate-limited or failed requests, 0 disables (default from config, otherwise 2)")
+	rootCmd.PersistentFlags().DurationVar(&retryBaseDelay, "retry-base-delay", 0, "initial backoff delay, doubled after every retry (default from config, otherwise 500ms)")
+	rootCmd.PersistentFlags().DurationVar(&retryMaxDelay, "retry-max-delay", 0, "maximum backoff delay between retries (default from config, otherwise 30s)")
 
 	rootCmd.AddCommand(ttsCmd)
 	rootCmd.AddCommand(modelsCmd)
 	rootCmd.AddCommand(configCmd)
 	rootCmd.AddCommand(usersCmd)
 	rootCmd.AddCommand(paymentCmd)
+	rootCmd.AddCommand(accountCmd)
+	rootCmd.AddCommand(dictionariesCmd)
 	rootCmd.AddCommand(McpCmd)
+	rootCmd.AddCommand(daemonCmd)
+	rootCmd.AddCommand(devCmd)
 }
 
 func initConfig() {
@@ -92,6 +108,27 @@ func initializeClient() error {
 		cfg.HTTPTimeout = timeout
 	}
 
+	// Retry settings: explicit flags win over config file values
+	if maxRetries >= 0 {
+		cfg.MaxRetries = maxRetries
+	} else if viper.IsSet("max_retries") {
+		cfg.MaxRetries = viper.GetInt("max_retries")
+	}
+	if retryBaseDelay > 0 {
+		cfg.RetryBaseDelay = retryBaseDelay
+	} else if d := viper.GetDuration("retry_base_delay"); d > 0 {
+		cfg.RetryBaseDelay = d
+	}
+	if retryMaxDelay > 0 {
+		cfg.RetryMaxDelay = retryMaxDelay
+	} else if d := viper.GetDuration("retry_max_delay"); d > 0 {
+		cfg.RetryMaxDelay = d
+	}
+
+	if viper.IsSet("rate_limit_enabled") {
+		cfg.RateLimitEnabled = viper.GetBool("rate_limit_enabled")
+	}
+
 	// Configure logging
 	if verbose {
 		cfg.LogLevel = "DEBUG"
@@ -125,6 +162,69 @@ func initializeClient() error {
     if v := viper.GetString("history_store_path"); v != "" {
         cfg.HistoryStorePath = v
     }
+    if v := viper.GetString("history_backend"); v != "" {
+        cfg.HistoryBackend = v
+    }
+
+    // Synthesis cache settings
+    if viper.IsSet("cache_enabled") {
+        cfg.CacheEnabled = viper.GetBool("cache_enabled")
+    }
+    if viper.IsSet("cache_max_size_mb") {
+        if v := viper.GetInt64("cache_max_size_mb"); v >= 0 {
+            cfg.CacheMaxSize = v * 1024 * 1024
+        }
+    }
+    if d := viper.GetDuration("cache_max_age"); d > 0 {
+        cfg.CacheMaxAge = d
+    }
+    if v := viper.GetString("cache_path"); v != "" {
+        cfg.CachePath = v
+    }
+
+    // Playback daemon settings
+    if viper.IsSet("use_daemon") {
+        cfg.UseDaemon = viper.GetBool("use_daemon")
+    }
+    if v := viper.GetString("daemon_socket"); v != "" {
+        cfg.DaemonSocketPath = v
+    }
+
+    // Credit budget settings
+    if viper.IsSet("budget_daily_credits") {
+        cfg.BudgetDailyCredits = viper.GetFloat64("budget_daily_credits")
+    }
+    if viper.IsSet("budget_monthly_credits") {
+        cfg.BudgetMonthlyCredits = viper.GetFloat64("budget_monthly_credits")
+    }
+    if v := viper.GetString("budget_path"); v != "" {
+        cfg.BudgetPath = v
+    }
+
+    // Low-credit alert settings; alerts go to stderr unless configured
+    for _, v := range splitList(viper.GetString("credit_alert_thresholds")) {
+        if threshold, err := strconv.ParseFloat(v, 64); err == nil {
+            cfg.CreditAlertThresholds = append(cfg.CreditAlertThresholds, threshold)
+        }
+    }
+    cfg.CreditAlertNotifiers = []string{config.CreditNotifierStderr}
+    if notifiers := splitList(viper.GetString("credit_alert_notifiers")); len(notifiers) > 0 {
+        cfg.CreditAlertNotifiers = notifiers
+    }
+    if v := viper.GetString("credit_alert_webhook_url"); v != "" {
+        cfg.CreditAlertWebhookURL = v
+    }
+    if v := viper.GetString("credit_alert_command"); v != "" {
+        cfg.CreditAlertCommand = v
+    }
+    if v := viper.GetString("credit_state_path"); v != "" {
+        cfg.CreditStatePath = v
+    }
+
+    // Audio player backend
+    if v := viper.GetString("player_backend"); v != "" {
+        cfg.PlayerBackend = v
+    }
 
     // For MCP stdio mode, force log output to stderr to avoid protocol contamination
 	if isMCPStdioMode() {
@@ -137,6 +237,12 @@ func initializeClient() error {
 		return fmt.Errorf("failed to create client: %v", err)
 	}
 
+	// Ask before exceeding a credit budget when someone is there to answer;
+	// MCP clients and scripts get the budget error instead
+	if !isMCPStdioMode() && isInteractive() {
+		aivisClient.SetBudgetConfirm(confirmBudget)
+	}
+
 	// Share client with MCP package
 	SetClient(aivisClient)
 
@@ -164,8 +270,18 @@ func isMCPStdioMode() bool {
 	return true
 }
 
+// creditAlertFlushTimeout bounds how long the CLI waits at exit for
+// low-credit alerts still being delivered
+const creditAlertFlushTimeout = 15 * time.Second
+
 func main() {
-	if err := rootCmd.Execute(); err != nil {
+	err := rootCmd.Execute()
+	if aivisClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), creditAlertFlushTimeout)
+		aivisClient.FlushCreditAlerts(ctx)
+		cancel()
+	}
+	if err != nil {
 		os.Exit(1)
 	}
 }
@@ -24,6 +24,12 @@ func RegisterAllTools(server *mcp.Server) {
 	// Register configuration tools
 	RegisterConfigTools(server)
 
+	// Register user dictionary tools
+	RegisterDictionaryTools(server)
+
+	// Register credit budget tools
+	RegisterBudgetTools(server)
+
 	// Future tool categories can be added here:
 	// RegisterUserTools(server)
 	// RegisterPaymentTools(server)
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// GetBudgetStatusParams parameters for get_budget_status tool
+type GetBudgetStatusParams struct {
+	// Text to estimate the synthesis cost of, in addition to the status
+	Text string `json:"text,omitempty"`
+}
+
+// RegisterBudgetTools registers credit budget MCP tools
+func RegisterBudgetTools(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_budget_status",
+		Description: "Get the credits spent today and this month against the configured budgets. Syntheses that would exceed a budget are refused; pass text to estimate its cost beforehand.",
+	}, handleGetBudgetStatus)
+}
+
+func handleGetBudgetStatus(ctx context.Context, req *mcp.CallToolRequest, args GetBudgetStatusParams) (*mcp.CallToolResult, any, error) {
+	status, err := aivisClient.GetBudgetStatus(ctx)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to get budget status: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Credit budget status:\n\n")
+	for _, period := range []ttsDomain.BudgetPeriodStatus{status.Daily, status.Monthly} {
+		if period.Limited() {
+			sb.WriteString(fmt.Sprintf("%s: %s of %s credits spent, %s remaining (%d requests, resets %s)\n",
+				period.Period, formatCredits(period.Spent), formatCredits(period.Limit),
+				formatCredits(*period.Remaining), period.Requests, period.ResetsAt.Format("2006-01-02 15:04")))
+		} else {
+			sb.WriteString(fmt.Sprintf("%s: %s credits spent, no budget (%d requests)\n",
+				period.Period, formatCredits(period.Spent), period.Requests))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("Estimate rate: %s credits/character\n", formatCredits(status.CreditsPerCharacter)))
+
+	if args.Text != "" {
+		request := ttsDomain.NewTTSRequestBuilder("", args.Text).Build()
+		estimate, err := aivisClient.EstimateSynthesisCost(ctx, request)
+		if err != nil {
+			return &mcp.CallToolResult{
+				Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to estimate synthesis cost: %v", err)}},
+				IsError: true,
+			}, nil, nil
+		}
+		sb.WriteString(fmt.Sprintf("\nThe text (%d characters) is estimated at %s credits", estimate.Characters, formatCredits(estimate.Credits)))
+		if len(estimate.Exceeded) == 0 {
+			sb.WriteString(" and fits the budgets.\n")
+		} else {
+			sb.WriteString(fmt.Sprintf(" and would exceed the %s budget.\n", estimate.Exceeded[0].Period))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: sb.String()}},
+	}, nil, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ListUserDictionariesParams parameters for list_user_dictionaries tool
+type ListUserDictionariesParams struct {
+	// No parameters needed - returns all dictionaries of the account
+}
+
+// GetUserDictionaryParams parameters for get_user_dictionary tool
+type GetUserDictionaryParams struct {
+	DictionaryUUID string `json:"dictionary_uuid"`
+}
+
+// SetDictionaryWordParams parameters for set_dictionary_word tool
+type SetDictionaryWordParams struct {
+	DictionaryUUID string `json:"dictionary_uuid"`
+	Surface        string `json:"surface"`             // word as written in text
+	Pronunciation  string `json:"pronunciation"`       // reading in katakana (hiragana is converted)
+	AccentType     int    `json:"accent_type"`         // mora after which the pitch falls, 0 for flat
+	WordType       string `json:"word_type,omitempty"` // PROPER_NOUN (default), COMMON_NOUN, VERB, ADJECTIVE, SUFFIX
+	Priority       *int   `json:"priority,omitempty"`  // 0-10, default 5
+}
+
+// DeleteDictionaryWordParams parameters for delete_dictionary_word tool
+type DeleteDictionaryWordParams struct {
+	DictionaryUUID string `json:"dictionary_uuid"`
+	WordUUID       string `json:"word_uuid"`
+}
+
+// CreateUserDictionaryParams parameters for create_user_dictionary tool
+type CreateUserDictionaryParams struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// RegisterDictionaryTools registers user dictionary MCP tools
+func RegisterDictionaryTools(server *mcp.Server) {
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "list_user_dictionaries",
+		Description: "List the user dictionaries that adjust how words are read",
+	}, handleListUserDictionaries)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "get_user_dictionary",
+		Description: "Get a user dictionary with its words (surface, katakana pronunciation, accent type, priority)",
+	}, handleGetUserDictionary)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "create_user_dictionary",
+		Description: "Create an empty user dictionary",
+	}, handleCreateUserDictionary)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "set_dictionary_word",
+		Description: "Fix a mispronunciation: add a word to a user dictionary, or replace the reading of the word with the same surface. Pass the dictionary's UUID as user_dictionary_uuid to synthesize_speech to hear the result.",
+	}, handleSetDictionaryWord)
+
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "delete_dictionary_word",
+		Description: "Remove a word from a user dictionary",
+	}, handleDeleteDictionaryWord)
+}
+
+func dictionaryToolError(format string, args ...any) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf(format, args...)}},
+		IsError: true,
+	}
+}
+
+func handleListUserDictionaries(ctx context.Context, req *mcp.CallToolRequest, args ListUserDictionariesParams) (*mcp.CallToolResult, any, error) {
+	response, err := aivisClient.ListUserDictionaries(ctx)
+	if err != nil {
+		return dictionaryToolError("Failed to list user dictionaries: %v", err), nil, nil
+	}
+
+	var sb strings.Builder
+	if len(response.UserDictionaries) == 0 {
+		sb.WriteString("No user dictionaries found. Create one with create_user_dictionary.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Found %d user dictionaries:\n\n", len(response.UserDictionaries)))
+	}
+	for _, dictionary := range response.UserDictionaries {
+		sb.WriteString(fmt.Sprintf("- %s (UUID: %s, %d words)\n", dictionary.Name, dictionary.UUID, dictionary.WordCount))
+		if dictionary.Description != "" {
+			sb.WriteString(fmt.Sprintf("  %s\n", dictionary.Description))
+		}
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: sb.String()}},
+	}, nil, nil
+}
+
+func handleGetUserDictionary(ctx context.Context, req *mcp.CallToolRequest, args GetUserDictionaryParams) (*mcp.CallToolResult, any, error) {
+	dictionary, err := aivisClient.GetUserDictionary(ctx, args.DictionaryUUID)
+	if err != nil {
+		return dictionaryToolError("Failed to get user dictionary: %v", err), nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Dictionary: %s (UUID: %s)\n", dictionary.Name, dictionary.UUID))
+	if dictionary.Description != "" {
+		sb.WriteString(fmt.Sprintf("Description: %s\n", dictionary.Description))
+	}
+	sb.WriteString(fmt.Sprintf("Words: %d\n", len(dictionary.Words)))
+	for _, word := range dictionary.Words {
+		sb.WriteString(fmt.Sprintf("- %s → %s (accent %d, %s, priority %d, UUID: %s)\n",
+			word.Surface, word.Pronunciation, word.AccentType, word.WordType, word.Priority, word.UUID))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: sb.String()}},
+	}, nil, nil
+}
+
+func handleCreateUserDictionary(ctx context.Context, req *mcp.CallToolRequest, args CreateUserDictionaryParams) (*mcp.CallToolResult, any, error) {
+	dictionary, err := aivisClient.CreateUserDictionary(ctx, args.Name, args.Description)
+	if err != nil {
+		return dictionaryToolError("Failed to create user dictionary: %v", err), nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Created user dictionary %q (UUID: %s)", dictionary.Name, dictionary.UUID)}},
+	}, nil, nil
+}
+
+func handleSetDictionaryWord(ctx context.Context, req *mcp.CallToolRequest, args SetDictionaryWordParams) (*mcp.CallToolResult, any, error) {
+	word := domain.Word{
+		Surface:       args.Surface,
+		Pronunciation: args.Pronunciation,
+		AccentType:    args.AccentType,
+		WordType:      domain.WordType(strings.ToUpper(args.WordType)),
+		Priority:      domain.DefaultWordPriority,
+	}
+	if args.Priority != nil {
+		word.Priority = *args.Priority
+	}
+
+	saved, added, err := aivisClient.SetDictionaryWord(ctx, args.DictionaryUUID, word)
+	if err != nil {
+		return dictionaryToolError("Failed to save word: %v", err), nil, nil
+	}
+
+	action := "Updated"
+	if added {
+		action = "Added"
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("%s %s → %s (accent %d, priority %d, UUID: %s)",
+			action, saved.Surface, saved.Pronunciation, saved.AccentType, saved.Priority, saved.UUID)}},
+	}, nil, nil
+}
+
+func handleDeleteDictionaryWord(ctx context.Context, req *mcp.CallToolRequest, args DeleteDictionaryWordParams) (*mcp.CallToolResult, any, error) {
+	if err := aivisClient.DeleteDictionaryWord(ctx, args.DictionaryUUID, args.WordUUID); err != nil {
+		return dictionaryToolError("Failed to remove word: %v", err), nil, nil
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Removed word %s", args.WordUUID)}},
+	}, nil, nil
+}
@@ -21,6 +21,23 @@ type ListTTSHistoryParams struct {
 	SortOrder    string `json:"sort_order,omitempty"`    // Sort order: asc, desc (default: desc)
 }
 
+// SearchTTSHistoryParams parameters for search_tts_history tool
+type SearchTTSHistoryParams struct {
+	Query       string   `json:"query"`                   // Text to search for (required)
+	Fuzzy       bool     `json:"fuzzy,omitempty"`         // Include partial matches such as typos
+	MinScore    float64  `json:"min_score,omitempty"`     // Minimum relevance score 0-1 (fuzzy default: 0.5)
+	Limit       int      `json:"limit,omitempty"`         // Maximum number of results (default: 10, max: 100)
+	Offset      int      `json:"offset,omitempty"`        // Number of results to skip (default: 0)
+	ModelUUID   string   `json:"model_uuid,omitempty"`    // Filter by model UUID
+	SpeakerUUID string   `json:"speaker_uuid,omitempty"`  // Filter by speaker UUID
+	StyleID     *int     `json:"style_id,omitempty"`      // Filter by style ID
+	FileFormat  string   `json:"file_format,omitempty"`   // Filter by audio format: wav, mp3, flac, aac, opus
+	MinCredits  *float64 `json:"min_credits,omitempty"`   // Minimum credits used
+	MaxCredits  *float64 `json:"max_credits,omitempty"`   // Maximum credits used
+	MinFileSize *int64   `json:"min_file_size,omitempty"` // Minimum audio file size in bytes
+	MaxFileSize *int64   `json:"max_file_size,omitempty"` // Maximum audio file size in bytes
+}
+
 // GetTTSHistoryParams parameters for get_tts_history tool
 type GetTTSHistoryParams struct {
 	ID int `json:"id"` // History record ID (required)
@@ -51,6 +68,11 @@ func RegisterHistoryTools(server *mcp.Server) {
 		Description: "List TTS synthesis history records with pagination and filtering options",
 	}, handleListTTSHistory)
 
+	mcp.AddTool(server, &mcp.Tool{
+		Name:        "search_tts_history",
+		Description: "Search TTS history by text (Japanese-aware, optional fuzzy matching) and return records ranked by relevance score",
+	}, handleSearchTTSHistory)
+
 	mcp.AddTool(server, &mcp.Tool{
 		Name:        "get_tts_history",
 		Description: "Get detailed information about a specific TTS history record",
@@ -195,6 +217,78 @@ func handleListTTSHistory(ctx context.Context, req *mcp.CallToolRequest, args Li
 	}, nil, nil
 }
 
+func handleSearchTTSHistory(ctx context.Context, req *mcp.CallToolRequest, args SearchTTSHistoryParams) (*mcp.CallToolResult, any, error) {
+	if strings.TrimSpace(args.Query) == "" {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: "query is required"}},
+			IsError: true,
+		}, nil, nil
+	}
+	if args.Limit <= 0 {
+		args.Limit = 10
+	}
+	if args.Limit > 100 {
+		args.Limit = 100 // Prevent excessive results
+	}
+	if args.Offset < 0 {
+		args.Offset = 0
+	}
+
+	builder := aivisClient.NewTTSHistoryQuery(args.Query).
+		WithFuzzy(args.Fuzzy).
+		WithMinScore(args.MinScore).
+		WithLimit(args.Limit).
+		WithOffset(args.Offset).
+		WithCreditsRange(args.MinCredits, args.MaxCredits).
+		WithFileSizeRange(args.MinFileSize, args.MaxFileSize)
+	if args.ModelUUID != "" {
+		builder = builder.WithModelUUID(args.ModelUUID)
+	}
+	if args.SpeakerUUID != "" {
+		builder = builder.WithSpeakerUUID(args.SpeakerUUID)
+	}
+	if args.StyleID != nil {
+		builder = builder.WithStyleID(*args.StyleID)
+	}
+	if args.FileFormat != "" {
+		builder = builder.WithFileFormat(args.FileFormat)
+	}
+
+	response, err := aivisClient.SearchTTSHistory(ctx, builder.Build())
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("Failed to search TTS history: %v", err)}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	if len(response.Results) == 0 {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("No TTS history records match %q", args.Query)}},
+		}, nil, nil
+	}
+
+	// Format results in compact, token-optimized format
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("TTS History matches for %q (%d-%d of %d)\n\n",
+		args.Query, args.Offset+1, args.Offset+len(response.Results), response.Total))
+
+	for _, match := range response.Results {
+		history := match.History
+		result.WriteString(fmt.Sprintf("ID %d (score %.2f): %s\n", history.ID, match.Score, truncateText(history.Text, 50)))
+		result.WriteString(fmt.Sprintf("  Model: %s | Format: %s | Size: %s | Created: %s\n\n",
+			history.ModelUUID, history.FileFormat, formatFileSize(history.FileSizeBytes), history.CreatedAt.Format("01/02 15:04")))
+	}
+
+	if response.HasMore {
+		result.WriteString(fmt.Sprintf("Use offset=%d to see more results", args.Offset+args.Limit))
+	}
+
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: result.String()}},
+	}, nil, nil
+}
+
 func handleGetTTSHistory(ctx context.Context, req *mcp.CallToolRequest, args GetTTSHistoryParams) (*mcp.CallToolResult, any, error) {
 	if args.ID <= 0 {
 		return &mcp.CallToolResult{
@@ -219,6 +313,12 @@ func handleGetTTSHistory(ctx context.Context, req *mcp.CallToolRequest, args Get
 	result.WriteString(fmt.Sprintf("Text: %s\n", history.Text))
 	result.WriteString(fmt.Sprintf("Model UUID: %s\n", history.ModelUUID))
 	result.WriteString(fmt.Sprintf("Created: %s\n", history.CreatedAt.Format("2006-01-02 15:04:05")))
+	if history.ParentID != 0 {
+		result.WriteString(fmt.Sprintf("Regenerated From: #%d\n", history.ParentID))
+	}
+	if history.ScriptSession != "" {
+		result.WriteString(fmt.Sprintf("Script Session: %s\n", history.ScriptSession))
+	}
     // Prefer actual file size if available
     sizeBytes := history.FileSizeBytes
     if history.FilePath != "" {
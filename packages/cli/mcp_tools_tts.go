@@ -29,11 +29,15 @@ type SynthesizeSpeechParams struct {
 	Channels           string  `json:"channels,omitempty"`            // mono, stereo
 	PlaybackMode       string  `json:"playback_mode,omitempty"`       // immediate, queue, no_queue
 	WaitForEnd         bool    `json:"wait_for_end,omitempty"`        // wait until playback completes
+
+	Profile            string `json:"profile,omitempty"`              // voice profile, uses the active profile by default
+	UserDictionaryUUID string `json:"user_dictionary_uuid,omitempty"` // user dictionary adjusting readings
 }
 
 // PlayTextParams parameters for play_text tool (simplified version)
 type PlayTextParams struct {
 	Text         string `json:"text"`
+	Profile      string `json:"profile,omitempty"`       // voice profile, uses the active profile by default
 	PlaybackMode string `json:"playback_mode,omitempty"` // immediate, queue, no_queue
 	WaitForEnd   bool   `json:"wait_for_end,omitempty"`  // wait until playback completes
 }
@@ -42,7 +46,8 @@ type PlayTextParams struct {
 func RegisterTTSTools(server *mcp.Server) {
 	// Check if simplified mode is enabled (default settings are configured)
 	defaultModelUUID := viper.GetString("default_model_uuid")
-	useSimplifiedTools := viper.GetBool("use_simplified_tts_tools") && defaultModelUUID != ""
+	hasDefaultVoice := defaultModelUUID != "" || viper.GetString("active_profile") != ""
+	useSimplifiedTools := viper.GetBool("use_simplified_tts_tools") && hasDefaultVoice
 
 	if useSimplifiedTools {
 		// Register simplified text-only tool
@@ -68,8 +73,19 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 		}, nil, nil
 	}
 
-	// Use default model UUID from config if not provided
+	profileName, profile, err := mcpProfile(args.Profile)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	// Use the profile's or the default model UUID from config if not provided
 	modelUUID := args.ModelUUID
+	if modelUUID == "" {
+		modelUUID = profile.ModelUUID
+	}
 	if modelUUID == "" {
 		modelUUID = viper.GetString("default_model_uuid")
 		if modelUUID == "" {
@@ -86,10 +102,10 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 		request = request.WithSSML(true)
 	}
 
-	// Apply optional parameters with config defaults
+	// Apply optional parameters with profile and config defaults
 	volume := args.Volume
 	if volume == 0 {
-		volume = viper.GetFloat64("default_volume")
+		volume = mcpDefaultFloat(profile.Volume, "default_volume")
 	}
 	if volume > 0 {
 		request = request.WithVolume(volume)
@@ -97,7 +113,7 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 
 	rate := args.Rate
 	if rate == 0 {
-		rate = viper.GetFloat64("default_rate")
+		rate = mcpDefaultFloat(profile.SpeakingRate, "default_rate")
 	}
 	if rate > 0 {
 		request = request.WithSpeakingRate(rate)
@@ -105,7 +121,7 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 
 	pitch := args.Pitch
 	if pitch == 0 {
-		pitch = viper.GetFloat64("default_pitch")
+		pitch = mcpDefaultFloat(profile.Pitch, "default_pitch")
 	}
 	if pitch != 0 {
 		request = request.WithPitch(pitch)
@@ -128,6 +144,10 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 		request = request.WithTrailingSilence(args.TrailingSilence)
 	}
 
+	if args.UserDictionaryUUID != "" {
+		request = request.WithUserDictionary(args.UserDictionaryUUID)
+	}
+
 	// Set audio channels
 	if args.Channels != "" {
 		switch args.Channels {
@@ -138,8 +158,11 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 		}
 	}
 
-	// Set output format with config default
+	// Set output format with profile and config defaults
 	format := args.Format
+	if format == "" {
+		format = string(profile.OutputFormat)
+	}
     if format == "" {
         format = viper.GetString("default_format")
     }
@@ -158,8 +181,8 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 		}
 	}
 
-	// Create playback request with options
-	playbackReq := aivisClient.NewPlaybackRequest(request.Build())
+	// The profile fills in the voice settings given neither above nor in args
+	playbackReq := aivisClient.NewPlaybackRequest(profile.Apply(request.Build()))
 	
 	// Set playback mode (default to queue for MCP - best for AI conversations)
 	playbackMode := args.PlaybackMode
@@ -256,6 +279,9 @@ func handleSynthesizeSpeech(ctx context.Context, req *mcp.CallToolRequest, args
 	}
 	resultText += fmt.Sprintf("Text: %s\n", args.Text)
 	resultText += fmt.Sprintf("Model: %s\n", modelUUID)
+	if profileName != "" {
+		resultText += fmt.Sprintf("Profile: %s\n", profileName)
+	}
 	if args.SSML {
 		resultText += "SSML: enabled\n"
 	}
@@ -313,8 +339,19 @@ func handlePlayText(ctx context.Context, req *mcp.CallToolRequest, args PlayText
 		}, nil, nil
 	}
 
-	// Use default model UUID from config
-	modelUUID := viper.GetString("default_model_uuid")
+	_, profile, err := mcpProfile(args.Profile)
+	if err != nil {
+		return &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			IsError: true,
+		}, nil, nil
+	}
+
+	// Use the profile's model or the default model UUID from config
+	modelUUID := profile.ModelUUID
+	if modelUUID == "" {
+		modelUUID = viper.GetString("default_model_uuid")
+	}
 	if modelUUID == "" {
 		return &mcp.CallToolResult{
 			Content: []mcp.Content{&mcp.TextContent{Text: "Default model UUID is not configured"}},
@@ -325,17 +362,21 @@ func handlePlayText(ctx context.Context, req *mcp.CallToolRequest, args PlayText
 	// Create TTS request with defaults
 	request := aivisClient.NewTTSRequest(modelUUID, args.Text)
 	
-	// Apply default settings from config
-	if volume := viper.GetFloat64("default_volume"); volume > 0 {
+	// Apply default settings from the profile and config
+	if volume := mcpDefaultFloat(profile.Volume, "default_volume"); volume > 0 {
 		request = request.WithVolume(volume)
 	}
-	if rate := viper.GetFloat64("default_rate"); rate > 0 {
+	if rate := mcpDefaultFloat(profile.SpeakingRate, "default_rate"); rate > 0 {
 		request = request.WithSpeakingRate(rate)
 	}
-	if pitch := viper.GetFloat64("default_pitch"); pitch != 0 {
+	if pitch := mcpDefaultFloat(profile.Pitch, "default_pitch"); pitch != 0 {
 		request = request.WithPitch(pitch)
 	}
-	if format := viper.GetString("default_format"); format != "" {
+	format := string(profile.OutputFormat)
+	if format == "" {
+		format = viper.GetString("default_format")
+	}
+	if format != "" {
 		switch format {
 		case "wav":
 			request = request.WithOutputFormat(ttsDomain.OutputFormatWAV)
@@ -350,8 +391,8 @@ func handlePlayText(ctx context.Context, req *mcp.CallToolRequest, args PlayText
 		}
 	}
 	
-	// Create playback request with options
-	playbackReq := aivisClient.NewPlaybackRequest(request.Build())
+	// The profile fills in the voice settings given neither above nor in args
+	playbackReq := aivisClient.NewPlaybackRequest(profile.Apply(request.Build()))
 	
 	// Set playback mode (default to queue for MCP - best for AI conversations)
 	playbackMode := args.PlaybackMode
@@ -379,8 +420,7 @@ func handlePlayText(ctx context.Context, req *mcp.CallToolRequest, args PlayText
 	}
 	playbackReq = playbackReq.WithWaitForEnd(waitForEnd)
 	
-	// Get format from config for file naming
-    format := viper.GetString("default_format")
+	// Use the same format for file naming
     if format == "" {
         format = "mp3"
     }
@@ -469,3 +509,29 @@ func handlePlayText(ctx context.Context, req *mcp.CallToolRequest, args PlayText
 		Content: []mcp.Content{&mcp.TextContent{Text: resultText}},
 	}, nil, nil
 }
+
+// mcpProfile returns the named voice profile, or the active profile when name
+// is empty, with its name. Without a profile it returns an empty profile, so
+// callers can read its fields directly.
+func mcpProfile(name string) (string, *ttsDomain.VoiceProfile, error) {
+	if name == "" {
+		name = viper.GetString("active_profile")
+	}
+	profile, err := resolveProfile(name)
+	if err != nil {
+		return "", nil, err
+	}
+	if profile == nil {
+		return "", &ttsDomain.VoiceProfile{}, nil
+	}
+	return strings.ToLower(name), profile, nil
+}
+
+// mcpDefaultFloat returns the profile's value, or the config default when the
+// profile does not set one
+func mcpDefaultFloat(profileValue *float64, key string) float64 {
+	if profileValue != nil {
+		return *profileValue
+	}
+	return viper.GetFloat64(key)
+}
@@ -32,6 +32,36 @@ var modelsSearchCmd = &cobra.Command{
 		public, _ := cmd.Flags().GetBool("public")
 		outputFormat, _ := cmd.Flags().GetString("output")
 
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			if offset > 0 {
+				return fmt.Errorf("--offset cannot be used with --all")
+			}
+			// The filters combine into one search, paged to the end
+			builder := aivisClient.NewModelSearchRequest()
+			if len(args) > 0 {
+				builder = builder.WithQuery(args[0])
+			}
+			if author != "" {
+				builder = builder.WithAuthor(author)
+			}
+			if len(tags) > 0 {
+				builder = builder.WithTags(tags...)
+			}
+			if public || author != "" || len(tags) > 0 {
+				builder = builder.WithPublicOnly()
+			}
+			if limit > 0 {
+				builder = builder.WithPageSize(limit)
+			}
+			if sort != "" {
+				builder = builder.WithSortBy(sort)
+			}
+			if err := streamNDJSON(aivisClient.SearchModelsPager(builder.Build())); err != nil {
+				return fmt.Errorf("failed to search models: %v", err)
+			}
+			return nil
+		}
+
 		ctx := context.Background()
 		var response *domain.ModelSearchResponse
 		var err error
@@ -319,6 +349,7 @@ func init() {
 	modelsSearchCmd.Flags().String("sort", "", "Sort field (name, created_at, updated_at, download_count, rating)")
 	modelsSearchCmd.Flags().Bool("public", false, "Search only public models")
 	modelsSearchCmd.Flags().String("output", "table", "Output format: table, json")
+	addAllFlag(modelsSearchCmd)
 
 	// Models get command flags
 	modelsGetCmd.Flags().String("output", "table", "Output format: table, json")
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/pager"
+)
+
+// addAllFlag registers --all on a list command
+func addAllFlag(cmd *cobra.Command) {
+	cmd.Flags().Bool("all", false, "Fetch every page and stream the results as NDJSON (--limit sets the page size)")
+}
+
+// allPageSize returns the page size for --all: --limit when given, else the
+// pager's default. --offset makes no sense when listing everything.
+func allPageSize(cmd *cobra.Command) (int, error) {
+	if cmd.Flags().Changed("offset") {
+		return 0, fmt.Errorf("--offset cannot be used with --all")
+	}
+	if !cmd.Flags().Changed("limit") {
+		return 0, nil
+	}
+	limit, _ := cmd.Flags().GetInt("limit")
+	return limit, nil
+}
+
+// streamNDJSON writes every item of the pager to stdout as one JSON object
+// per line, fetching pages as it goes, until the items are exhausted or the
+// command is interrupted
+func streamNDJSON[T any](p *pager.Pager[T]) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	encoder := json.NewEncoder(os.Stdout)
+	for p.Next(ctx) {
+		if err := encoder.Encode(p.Item()); err != nil {
+			return err
+		}
+	}
+	return p.Err()
+}
@@ -26,6 +26,13 @@ var getSubscriptionsCmd = &cobra.Command{
 
 		client := aivisClient
 
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			pageSize, err := allPageSize(cmd)
+			cobra.CheckErr(err)
+			cobra.CheckErr(streamNDJSON(client.SubscriptionsPager(pageSize)))
+			return
+		}
+
 		ctx := context.Background()
 		subscriptions, err := client.GetSubscriptions(ctx, limit, offset)
 		if err != nil {
@@ -63,6 +70,16 @@ var getCreditTransactionsCmd = &cobra.Command{
 
 		client := aivisClient
 
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			pageSize, err := allPageSize(cmd)
+			cobra.CheckErr(err)
+			cobra.CheckErr(streamNDJSON(client.CreditTransactionsPager(
+				domain.TransactionType(transactionType),
+				domain.TransactionStatus(status),
+				startDate, endDate, pageSize)))
+			return
+		}
+
 		ctx := context.Background()
 		response, err := client.GetCreditTransactions(ctx, 
 			domain.TransactionType(transactionType), 
@@ -87,6 +104,13 @@ var getAPIKeysCmd = &cobra.Command{
 
 		client := aivisClient
 
+		if all, _ := cmd.Flags().GetBool("all"); all {
+			pageSize, err := allPageSize(cmd)
+			cobra.CheckErr(err)
+			cobra.CheckErr(streamNDJSON(client.APIKeysPager(pageSize)))
+			return
+		}
+
 		ctx := context.Background()
 		apiKeys, err := client.GetAPIKeys(ctx, limit, offset)
 		if err != nil {
@@ -173,6 +197,30 @@ var getUsageSummariesCmd = &cobra.Command{
 	},
 }
 
+var getRateLimitCmd = &cobra.Command{
+	Use:   "rate-limit",
+	Short: "Show API rate limit status",
+	Long:  "Show the request rate limit budget reported by the API. A lightweight request is made first so the limiter reflects current server headers.",
+	Run: func(cmd *cobra.Command, args []string) {
+		client := aivisClient
+
+		ctx := context.Background()
+		if _, err := client.GetMe(ctx); err != nil {
+			fmt.Printf("Error refreshing rate limit status: %v\n", err)
+			return
+		}
+
+		state := client.GetRateLimitState()
+		if !state.Known {
+			fmt.Println("No rate limit information was reported by the API")
+			return
+		}
+
+		output, _ := json.MarshalIndent(state, "", "  ")
+		fmt.Println(string(output))
+	},
+}
+
 func init() {
 	// Command registration is handled in main.go
 	
@@ -182,10 +230,12 @@ func init() {
 	paymentCmd.AddCommand(createAPIKeyCmd)
 	paymentCmd.AddCommand(deleteAPIKeyCmd)
 	paymentCmd.AddCommand(getUsageSummariesCmd)
+	paymentCmd.AddCommand(getRateLimitCmd)
 
 	// Pagination flags
 	getSubscriptionsCmd.Flags().IntP("limit", "l", 20, "Number of results to return")
 	getSubscriptionsCmd.Flags().IntP("offset", "o", 0, "Offset for pagination")
+	addAllFlag(getSubscriptionsCmd)
 
 	// Transaction filters
 	getCreditTransactionsCmd.Flags().String("type", "", "Filter by transaction type (credit, debit, refund)")
@@ -194,10 +244,12 @@ func init() {
 	getCreditTransactionsCmd.Flags().String("end-date", "", "End date (YYYY-MM-DD)")
 	getCreditTransactionsCmd.Flags().IntP("limit", "l", 20, "Number of results to return")
 	getCreditTransactionsCmd.Flags().IntP("offset", "o", 0, "Offset for pagination")
+	addAllFlag(getCreditTransactionsCmd)
 
 	// API keys pagination
 	getAPIKeysCmd.Flags().IntP("limit", "l", 20, "Number of results to return")
 	getAPIKeysCmd.Flags().IntP("offset", "o", 0, "Offset for pagination")
+	addAllFlag(getAPIKeysCmd)
 
 	// Usage stats filters
 	getUsageSummariesCmd.Flags().String("period", "month", "Period (day, week, month, year)")
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+var paymentBudgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Local credit budget commands",
+	Long: `Inspect the local credit budget.
+
+While budget_daily_credits or budget_monthly_credits is set (see 'config set'),
+every synthesis is recorded in a local ledger with the credits the API billed
+for it. Before a synthesis its cost is estimated from the text length, at the
+rate billed for recent syntheses, checked against the budgets and reserved in
+the ledger until it is billed, so that concurrent scripts cannot overrun a
+budget together. An interactive session asks before exceeding a budget;
+scripts and MCP clients get an error instead.`,
+}
+
+var paymentBudgetStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show credits spent today and this month against the budgets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, _ := cmd.Flags().GetString("output")
+
+		ctx := context.Background()
+		status, err := aivisClient.GetBudgetStatus(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get budget status: %v", err)
+		}
+
+		switch outputFormat {
+		case "json":
+			return printJSON(status)
+		case "table", "":
+		default:
+			return fmt.Errorf("unsupported output format: %s. Supported formats: json, table", outputFormat)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "Period\tSpent\tReserved\tLimit\tRemaining\tRequests\tResets")
+		for _, period := range []ttsDomain.BudgetPeriodStatus{status.Daily, status.Monthly} {
+			limit, remaining := "unlimited", "-"
+			if period.Limited() {
+				limit = formatCredits(period.Limit)
+				remaining = formatCredits(*period.Remaining)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n", period.Period, formatCredits(period.Spent), formatCredits(period.Reserved),
+				limit, remaining, period.Requests, period.ResetsAt.Format("2006-01-02 15:04"))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Println()
+		if status.CalibrationSamples > 0 {
+			fmt.Printf("Estimate rate: %s credits/character (from %d recent syntheses)\n",
+				formatCredits(status.CreditsPerCharacter), status.CalibrationSamples)
+		} else {
+			fmt.Printf("Estimate rate: %s credits/character (default, no syntheses recorded yet)\n",
+				formatCredits(status.CreditsPerCharacter))
+		}
+		fmt.Printf("Ledger: %s\n", status.LedgerPath)
+		return nil
+	},
+}
+
+var paymentBudgetEstimateCmd = &cobra.Command{
+	Use:   "estimate <text>",
+	Short: "Estimate the credits a synthesis of text would cost",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		ssml, _ := cmd.Flags().GetBool("ssml")
+
+		request := ttsDomain.NewTTSRequestBuilder("", args[0]).WithSSML(ssml).Build()
+		ctx := context.Background()
+		estimate, err := aivisClient.EstimateSynthesisCost(ctx, request)
+		if err != nil {
+			return fmt.Errorf("failed to estimate synthesis cost: %v", err)
+		}
+
+		switch outputFormat {
+		case "json":
+			return printJSON(estimate)
+		case "table", "":
+		default:
+			return fmt.Errorf("unsupported output format: %s. Supported formats: json, table", outputFormat)
+		}
+
+		fmt.Printf("Characters: %d\n", estimate.Characters)
+		fmt.Printf("Estimated credits: %s\n", formatCredits(estimate.Credits))
+		if len(estimate.Exceeded) == 0 {
+			fmt.Println("Within budget")
+			return nil
+		}
+		for _, period := range estimate.Exceeded {
+			fmt.Printf("Exceeds the %s budget: %s of %s credits spent\n",
+				period.Period, formatCredits(period.Spent), formatCredits(period.Limit))
+		}
+		return nil
+	},
+}
+
+// formatCredits formats a credit amount without trailing zeros
+func formatCredits(credits float64) string {
+	s := fmt.Sprintf("%.2f", credits)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+// isInteractive reports whether stdin is a terminal someone can answer on
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// confirmBudget asks on the terminal whether a synthesis may exceed a budget
+func confirmBudget(ctx context.Context, estimate *ttsDomain.BudgetEstimate) bool {
+	fmt.Fprintf(os.Stderr, "This synthesis of %d characters is estimated at %s credits.\n",
+		estimate.Characters, formatCredits(estimate.Credits))
+	for _, period := range estimate.Exceeded {
+		fmt.Fprintf(os.Stderr, "It exceeds the %s budget: %s of %s credits spent (resets %s).\n",
+			period.Period, formatCredits(period.Spent), formatCredits(period.Limit),
+			period.ResetsAt.Format(time.DateTime))
+	}
+	fmt.Fprint(os.Stderr, "Proceed anyway? [y/N]: ")
+
+	var response string
+	fmt.Scanln(&response)
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
+
+func init() {
+	paymentCmd.AddCommand(paymentBudgetCmd)
+	paymentBudgetCmd.AddCommand(paymentBudgetStatusCmd)
+	paymentBudgetCmd.AddCommand(paymentBudgetEstimateCmd)
+
+	paymentBudgetStatusCmd.Flags().String("output", "table", "Output format (table, json)")
+	paymentBudgetEstimateCmd.Flags().String("output", "table", "Output format (table, json)")
+	paymentBudgetEstimateCmd.Flags().Bool("ssml", false, "Count the text as SSML (markup is not billed)")
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kajidog/aivis-cloud-cli/client"
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+var paymentReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report credit usage from local history and the server",
+	Long: `Aggregate the local TTS history of a period by model, day, profile, project
+or tag, with the characters and credits of each group, and write it as a
+Markdown table, CSV or JSON.
+
+The report is joined with the usage the server reports for the period: a model
+report shows the server's requests and credits next to the local ones, which
+reveals usage from other machines or without history. Project and tag reports
+cover labeled local records only. Label syntheses with --project and --tag on
+'tts play', 'tts synthesize', 'tts batch' and 'tts script'.
+
+The period defaults to the current month.`,
+	Example: `  aivis-cloud-cli payment report
+  aivis-cloud-cli payment report --month 2026-05 --group-by day --format csv --output-file may.csv
+  aivis-cloud-cli payment report --group-by tag --project podcast --format json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		month, _ := cmd.Flags().GetString("month")
+		startDateStr, _ := cmd.Flags().GetString("start-date")
+		endDateStr, _ := cmd.Flags().GetString("end-date")
+		groupBy, _ := cmd.Flags().GetString("group-by")
+		project, _ := cmd.Flags().GetString("project")
+		tag, _ := cmd.Flags().GetString("tag")
+		format, _ := cmd.Flags().GetString("format")
+		outputFile, _ := cmd.Flags().GetString("output-file")
+
+		switch format {
+		case "markdown", "csv", "json":
+		default:
+			return fmt.Errorf("unsupported format: %s. Supported formats: markdown, csv, json", format)
+		}
+
+		start, end, err := reportPeriod(month, startDateStr, endDateStr)
+		if err != nil {
+			return err
+		}
+		options := ttsDomain.UsageReportOptions{
+			Start:   start,
+			End:     end,
+			GroupBy: ttsDomain.UsageGroupBy(groupBy),
+			Project: project,
+			Tag:     tag,
+		}
+		report, err := aivisClient.GenerateUsageReport(context.Background(), options)
+		if err != nil {
+			return fmt.Errorf("failed to generate usage report: %v", err)
+		}
+		if report.ServerError != "" {
+			fmt.Fprintf(os.Stderr, "Warning: server usage unavailable, reporting local history only: %s\n", report.ServerError)
+		}
+
+		var out io.Writer = os.Stdout
+		if outputFile != "" {
+			file, err := os.Create(outputFile)
+			if err != nil {
+				return fmt.Errorf("failed to create output file: %v", err)
+			}
+			defer file.Close()
+			out = file
+		}
+
+		switch format {
+		case "csv":
+			err = writeReportCSV(out, report)
+		case "json":
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			err = encoder.Encode(report)
+		default:
+			err = writeReportMarkdown(out, report)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write usage report: %v", err)
+		}
+		if outputFile != "" {
+			fmt.Fprintf(os.Stderr, "Usage report written to %s\n", outputFile)
+		}
+		return nil
+	},
+}
+
+// reportPeriod returns the local start and inclusive end of a report, from
+// --month or --start-date/--end-date; the current month when none is given
+func reportPeriod(month, startDate, endDate string) (time.Time, time.Time, error) {
+	if month != "" && (startDate != "" || endDate != "") {
+		return time.Time{}, time.Time{}, fmt.Errorf("--month cannot be combined with --start-date or --end-date")
+	}
+
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0)
+	if month != "" {
+		parsed, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --month %q (use YYYY-MM)", month)
+		}
+		start, end = parsed, parsed.AddDate(0, 1, 0)
+	}
+	if startDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", startDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --start-date %q (use YYYY-MM-DD)", startDate)
+		}
+		start = parsed
+	}
+	if endDate != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", endDate, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid --end-date %q (use YYYY-MM-DD)", endDate)
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("the report period ends before it starts")
+	}
+	return start, end.Add(-time.Nanosecond), nil
+}
+
+// reportColumns returns the header and the cells of the report rows. The
+// model name and server columns are only present when they have values.
+func reportColumns(report *client.UsageReportResult) ([]string, func(row ttsDomain.UsageReportRow) []string) {
+	byModel := report.GroupBy == ttsDomain.UsageGroupByModel
+	withServer := report.Total.ServerCredits != nil
+
+	header := []string{string(report.GroupBy)}
+	if byModel {
+		header = append(header, "model_name")
+	}
+	header = append(header, "requests", "characters", "credits", "unbilled_requests")
+	if withServer {
+		header = append(header, "server_requests", "server_credits")
+	}
+
+	cells := func(row ttsDomain.UsageReportRow) []string {
+		record := []string{row.Group}
+		if byModel {
+			record = append(record, row.ModelName)
+		}
+		record = append(record, strconv.Itoa(row.Requests), strconv.Itoa(row.Characters),
+			formatCredits(row.Credits), strconv.Itoa(row.UnbilledRequests))
+		if withServer {
+			serverRequests, serverCredits := "", ""
+			if row.ServerRequests != nil {
+				serverRequests = strconv.FormatInt(*row.ServerRequests, 10)
+			}
+			if row.ServerCredits != nil {
+				serverCredits = formatCredits(*row.ServerCredits)
+			}
+			record = append(record, serverRequests, serverCredits)
+		}
+		return record
+	}
+	return header, cells
+}
+
+// writeReportCSV writes one record per group, without a total, so that the
+// file can be summed in a spreadsheet
+func writeReportCSV(out io.Writer, report *client.UsageReportResult) error {
+	header, cells := reportColumns(report)
+	w := csv.NewWriter(out)
+	if err := w.Write(header); err != nil {
+		return err
+	}
+	for _, row := range report.Rows {
+		if err := w.Write(cells(row)); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+func writeReportMarkdown(out io.Writer, report *client.UsageReportResult) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Usage report %s to %s\n\n", report.Start.Format("2006-01-02"), report.End.Format("2006-01-02"))
+	var filters []string
+	if report.Project != "" {
+		filters = append(filters, "project "+report.Project)
+	}
+	if report.Tag != "" {
+		filters = append(filters, "tag "+report.Tag)
+	}
+	if len(filters) > 0 {
+		fmt.Fprintf(&b, "Filtered by %s.\n\n", strings.Join(filters, ", "))
+	}
+
+	header, cells := reportColumns(report)
+	escape := strings.NewReplacer("|", "\\|")
+	writeRow := func(record []string) {
+		for i := range record {
+			record[i] = escape.Replace(record[i])
+		}
+		fmt.Fprintf(&b, "| %s |\n", strings.Join(record, " | "))
+	}
+	writeRow(header)
+	separator := make([]string, len(header))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	writeRow(separator)
+	for _, row := range report.Rows {
+		writeRow(cells(row))
+	}
+	total := report.Total
+	total.Group = "**total**"
+	writeRow(cells(total))
+
+	if server := report.Server; server != nil {
+		fmt.Fprintf(&b, "\nServer usage for the period: %d requests, %s credits.\n", server.Requests, formatCredits(server.Credits))
+	} else if report.ServerError != "" {
+		fmt.Fprintf(&b, "\nServer usage unavailable: %s\n", report.ServerError)
+	}
+	if total.UnbilledRequests > 0 {
+		fmt.Fprintf(&b, "\n%d local records have no billing information and are missing from the credits.\n", total.UnbilledRequests)
+	}
+	_, err := io.WriteString(out, b.String())
+	return err
+}
+
+func init() {
+	paymentCmd.AddCommand(paymentReportCmd)
+	paymentReportCmd.Flags().String("month", "", "Report a calendar month (YYYY-MM, default: the current month)")
+	paymentReportCmd.Flags().String("start-date", "", "Start date (YYYY-MM-DD)")
+	paymentReportCmd.Flags().String("end-date", "", "End date, inclusive (YYYY-MM-DD)")
+	paymentReportCmd.Flags().String("group-by", "model", "Group by model, day, profile, project or tag")
+	paymentReportCmd.Flags().String("project", "", "Only report records labeled with this project")
+	paymentReportCmd.Flags().String("tag", "", "Only report records labeled with this tag")
+	paymentReportCmd.Flags().String("format", "markdown", "Output format: markdown, csv, json")
+	paymentReportCmd.Flags().String("output-file", "", "Write the report to this file instead of stdout")
+}
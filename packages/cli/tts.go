@@ -3,12 +3,16 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
 	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
 // Default model UUID when not specified
@@ -40,6 +44,151 @@ func (h *streamHandler) OnError(err error) {
 	fmt.Fprintf(os.Stderr, "Streaming error: %v\n", err)
 }
 
+// readTextFile returns the contents of --text-file ("-" reads stdin), or "" when unset
+func readTextFile(cmd *cobra.Command) (string, error) {
+	path, _ := cmd.Flags().GetString("text-file")
+	if path == "" {
+		return "", nil
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read text file: %v", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// selectedProfile returns the voice profile chosen with --profile, or the
+// active profile; nil when neither is set
+func selectedProfile(cmd *cobra.Command) (*ttsDomain.VoiceProfile, error) {
+	name, _ := cmd.Flags().GetString("profile")
+	return resolveProfile(name)
+}
+
+// applyProfile fills the fields that the flags left unset from the profile and
+// falls back to the default model when neither names one
+func applyProfile(profile *ttsDomain.VoiceProfile, request *ttsDomain.TTSRequest) *ttsDomain.TTSRequest {
+	request = profile.Apply(request)
+	if request.ModelUUID == "" {
+		request.ModelUUID = defaultModelUUID
+	}
+	return request
+}
+
+// withUserDictionaryFlag sets the user dictionary given with --user-dictionary
+func withUserDictionaryFlag(cmd *cobra.Command, request *ttsDomain.TTSRequest) *ttsDomain.TTSRequest {
+	if dictionaryUUID, _ := cmd.Flags().GetString("user-dictionary"); dictionaryUUID != "" {
+		request.UserDictionaryUUID = &dictionaryUUID
+	}
+	return request
+}
+
+// addHistoryLabelFlags registers --project and --tag
+func addHistoryLabelFlags(cmd *cobra.Command) {
+	cmd.Flags().String("project", "", "Project to label the history records with (see 'payment report')")
+	cmd.Flags().StringSlice("tag", nil, "Tag to label the history records with (repeatable or comma-separated)")
+}
+
+// withHistoryLabels labels the history records saved under the returned
+// context with --project, --tag and the name of the voice profile in use
+func withHistoryLabels(ctx context.Context, cmd *cobra.Command) context.Context {
+	labels := &ttsDomain.HistoryLabels{}
+	labels.Project, _ = cmd.Flags().GetString("project")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			labels.Tags = append(labels.Tags, tag)
+		}
+	}
+	if cmd.Flags().Lookup("profile") != nil {
+		labels.Profile, _ = cmd.Flags().GetString("profile")
+		if labels.Profile == "" {
+			labels.Profile = viper.GetString("active_profile")
+		}
+	}
+	return ttsDomain.WithHistoryLabels(ctx, labels)
+}
+
+// longTextOptions builds chunking options from the --chunk-silence and --concurrency flags
+func longTextOptions(cmd *cobra.Command) *ttsDomain.LongTextOptions {
+	options := ttsDomain.DefaultLongTextOptions()
+	if silence, _ := cmd.Flags().GetFloat64("chunk-silence"); silence >= 0 {
+		options.ChunkSilence = time.Duration(silence * float64(time.Second))
+	}
+	if concurrency, _ := cmd.Flags().GetInt("concurrency"); concurrency > 0 {
+		options.Concurrency = concurrency
+	}
+	options.OnProgress = func(completed, total int) {
+		fmt.Fprintf(os.Stderr, "Synthesized chunk %d/%d\n", completed, total)
+	}
+	return options
+}
+
+// audioEffects builds the post-processing pipeline from the synthesize flags
+func audioEffects(cmd *cobra.Command) (audio.Effects, error) {
+	var effects audio.Effects
+	if cmd.Flags().Changed("normalize") {
+		target, _ := cmd.Flags().GetFloat64("normalize")
+		if target > 0 || target < -70 {
+			return effects, fmt.Errorf("--normalize must be between -70 and 0 LUFS")
+		}
+		effects.NormalizeLUFS = &target
+	}
+	effects.TrimSilence, _ = cmd.Flags().GetBool("trim-silence")
+	effects.TrimThreshold, _ = cmd.Flags().GetFloat64("trim-threshold")
+	effects.TrimPadding, _ = cmd.Flags().GetDuration("trim-padding")
+	effects.GainDB, _ = cmd.Flags().GetFloat64("gain")
+	effects.SampleRate, _ = cmd.Flags().GetInt("resample")
+
+	switch channels, _ := cmd.Flags().GetString("convert-channels"); channels {
+	case "":
+	case "mono":
+		effects.Channels = 1
+	case "stereo":
+		effects.Channels = 2
+	default:
+		return effects, fmt.Errorf("invalid --convert-channels %q (use mono or stereo)", channels)
+	}
+	if effects.SampleRate < 0 || effects.TrimPadding < 0 {
+		return effects, fmt.Errorf("--resample and --trim-padding must not be negative")
+	}
+	return effects, nil
+}
+
+// addPlaybackEnvelopeFlags registers --start, --fade-in and --fade-out
+func addPlaybackEnvelopeFlags(cmd *cobra.Command) {
+	cmd.Flags().Duration("start", 0, "Start playback this far into the audio (e.g. 1.5s)")
+	cmd.Flags().Duration("fade-in", 0, "Fade the audio in over this duration")
+	cmd.Flags().Duration("fade-out", 0, "Fade the audio out over this duration, also when interrupted by an immediate playback")
+}
+
+// applyPlaybackEnvelope sets the start offset and fades from the flags
+// registered by addPlaybackEnvelopeFlags
+func applyPlaybackEnvelope(cmd *cobra.Command, builder *ttsDomain.PlaybackRequestBuilder) (*ttsDomain.PlaybackRequestBuilder, error) {
+	start, _ := cmd.Flags().GetDuration("start")
+	fadeIn, _ := cmd.Flags().GetDuration("fade-in")
+	fadeOut, _ := cmd.Flags().GetDuration("fade-out")
+	if start < 0 || fadeIn < 0 || fadeOut < 0 {
+		return nil, fmt.Errorf("--start, --fade-in and --fade-out must not be negative")
+	}
+	if start > 0 {
+		builder = builder.WithStartOffset(start)
+	}
+	if fadeIn > 0 {
+		builder = builder.WithFadeIn(fadeIn)
+	}
+	if fadeOut > 0 {
+		builder = builder.WithFadeOut(fadeOut)
+	}
+	return builder, nil
+}
+
 var ttsCmd = &cobra.Command{
 	Use:   "tts",
 	Short: "Text-to-speech operations",
@@ -53,7 +202,7 @@ var ttsPlayCmd = &cobra.Command{
 	Args:  cobra.RangeArgs(0, 2),
     RunE: func(cmd *cobra.Command, args []string) error {
 		text := ""
-		modelUUID := defaultModelUUID
+		modelUUID := ""
 		
 		// Get text from args or flag
 		if len(args) > 0 {
@@ -65,9 +214,14 @@ var ttsPlayCmd = &cobra.Command{
 		if flagText, _ := cmd.Flags().GetString("text"); flagText != "" {
 			text = flagText
 		}
+		if fileText, err := readTextFile(cmd); err != nil {
+			return err
+		} else if fileText != "" {
+			text = fileText
+		}
 		
 		if text == "" {
-			return fmt.Errorf("text is required (provide as argument or --text flag)")
+			return fmt.Errorf("text is required (provide as argument, --text or --text-file flag)")
 		}
 
 		// Check for model-uuid flag
@@ -114,18 +268,45 @@ var ttsPlayCmd = &cobra.Command{
 			request = request.WithTrailingSilence(trailingSilence)
 		}
 
-		ttsReq := request.Build()
+		profile, err := selectedProfile(cmd)
+		if err != nil {
+			return err
+		}
+		ttsReq := applyProfile(profile, withUserDictionaryFlag(cmd, request.Build()))
 
 		// Build playback request with WaitForEnd flag for synchronous playback
 		// Use no_queue mode for CLI - no need to stop previous playback (fresh process)
 		playbackBuilder := aivisClient.NewPlaybackRequest(ttsReq).
 			WithMode(ttsDomain.PlaybackModeNoQueue).
 			WithWaitForEnd(true)
+		// A running playback daemon owns the queue: enqueue there and return so
+		// that 'tts control' can act on the audio
+		daemonRunning := aivisClient.DaemonRunning()
+		if daemonRunning {
+			playbackBuilder = aivisClient.NewPlaybackRequest(ttsReq).
+				WithMode(ttsDomain.PlaybackModeQueue)
+		}
+		playbackBuilder, err = applyPlaybackEnvelope(cmd, playbackBuilder)
+		if err != nil {
+			return err
+		}
         playbackReq := playbackBuilder.Build()
 
-        ctx := context.Background()
+        ctx := withHistoryLabels(context.Background(), cmd)
         saveHistory, _ := cmd.Flags().GetBool("save-history")
-        if saveHistory {
+        if long, _ := cmd.Flags().GetBool("long"); long {
+            // Playback starts on the first chunk while the rest are synthesized
+            options := longTextOptions(cmd)
+            var err error
+            if saveHistory {
+                _, err = aivisClient.PlayLongTextWithHistory(ctx, playbackReq, options)
+            } else {
+                _, err = aivisClient.PlayLongText(ctx, playbackReq, options)
+            }
+            if err != nil {
+                return fmt.Errorf("failed to play long text: %v", err)
+            }
+        } else if saveHistory {
             if _, err := aivisClient.PlayRequestWithHistory(ctx, playbackReq); err != nil {
                 return fmt.Errorf("failed to play with history: %v", err)
             }
@@ -135,7 +316,9 @@ var ttsPlayCmd = &cobra.Command{
             }
         }
 
-		if verbose {
+		if daemonRunning {
+			fmt.Println("Queued on the playback daemon")
+		} else if verbose {
 			fmt.Fprintf(os.Stderr, "Successfully played text: %s\n", text)
 		}
 
@@ -146,12 +329,12 @@ var ttsPlayCmd = &cobra.Command{
 var ttsSynthesizeCmd = &cobra.Command{
 	Use:   "synthesize [text] [output-file] [model-uuid]",
 	Short: "Synthesize text to audio file",
-	Long:  "Convert text to speech and save to audio file. If output file is not specified, it will be auto-generated.",
+	Long:  "Convert text to speech and save to audio file. If output file is not specified, it will be auto-generated. WAV and FLAC output can be post-processed before it is saved with --normalize, --trim-silence, --gain, --resample and --convert-channels. With --subtitles, each sentence is synthesized separately and an SRT or WebVTT file (chosen by its extension) is written with the timing of every sentence.",
 	Args:  cobra.RangeArgs(0, 3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		text := ""
 		outputFile := ""
-		modelUUID := defaultModelUUID
+		modelUUID := ""
 		
 		// Get text and output from args or flags
 		if len(args) > 0 {
@@ -169,13 +352,37 @@ var ttsSynthesizeCmd = &cobra.Command{
 		if flagOutput, _ := cmd.Flags().GetString("output"); flagOutput != "" {
 			outputFile = flagOutput
 		}
+		if fileText, err := readTextFile(cmd); err != nil {
+			return err
+		} else if fileText != "" {
+			text = fileText
+		}
 		
 		if text == "" {
-			return fmt.Errorf("text is required (provide as argument or --text flag)")
+			return fmt.Errorf("text is required (provide as argument, --text or --text-file flag)")
+		}
+
+		long, _ := cmd.Flags().GetBool("long")
+		subtitlesFile, _ := cmd.Flags().GetString("subtitles")
+		if !long && subtitlesFile == "" && len(text) > ttsDomain.MaxTextLength {
+			return fmt.Errorf("text is longer than %d characters; use --long to split it into chunks", ttsDomain.MaxTextLength)
+		}
+		if subtitlesFile != "" {
+			if _, err := ttsDomain.GetSubtitleFormatFromFilePath(subtitlesFile); err != nil {
+				return err
+			}
 		}
 		
-		// Get format flag for filename generation
+		// Get format flag for filename generation; the profile's format
+		// replaces the default
 		format, _ := cmd.Flags().GetString("format")
+		profile, err := selectedProfile(cmd)
+		if err != nil {
+			return err
+		}
+		if profile != nil && profile.OutputFormat != "" && !cmd.Flags().Changed("format") {
+			format = string(profile.OutputFormat)
+		}
 		
 		// Auto-generate output filename if not specified
 		if outputFile == "" {
@@ -274,17 +481,68 @@ var ttsSynthesizeCmd = &cobra.Command{
 			request = request.WithOutputBitrate(bitrate)
 		}
 
-		ttsReq := request.Build()
+		ttsReq := applyProfile(profile, withUserDictionaryFlag(cmd, request.Build()))
 
-		ctx := context.Background()
+		effects, err := audioEffects(cmd)
+		if err != nil {
+			return err
+		}
+		if !effects.IsZero() && format != "wav" && format != "flac" {
+			return fmt.Errorf("audio processing supports only wav and flac output")
+		}
+
+		ctx := withHistoryLabels(context.Background(), cmd)
+
+		if subtitlesFile != "" {
+			if format != "wav" && format != "flac" {
+				return fmt.Errorf("--subtitles supports only wav and flac output")
+			}
+			if effects.TrimSilence {
+				return fmt.Errorf("--trim-silence cannot be used with --subtitles, as it would shift the cue timings")
+			}
+			result, err := aivisClient.SynthesizeWithSubtitlesToFile(ctx, ttsReq, longTextOptions(cmd), outputFile, effects)
+			if err != nil {
+				return fmt.Errorf("failed to synthesize with subtitles: %v", err)
+			}
+			if err := aivisClient.WriteSubtitlesFile(subtitlesFile, result.Cues); err != nil {
+				return fmt.Errorf("failed to save subtitles: %v", err)
+			}
+
+			fmt.Printf("Audio saved to: %s\n", outputFile)
+			fmt.Printf("Subtitles saved to: %s (%d cues, %s)\n", subtitlesFile, len(result.Cues), result.Duration.Round(time.Millisecond))
+			if result.HistoryID > 0 {
+				fmt.Printf("History saved with ID: %d\n", result.HistoryID)
+			}
+			return nil
+		}
+
+		if long {
+			if format != "wav" && format != "flac" {
+				return fmt.Errorf("--long supports only wav and flac output")
+			}
+			result, err := aivisClient.SynthesizeLongTextToFileWithEffects(ctx, ttsReq, longTextOptions(cmd), outputFile, effects)
+			if err != nil {
+				return fmt.Errorf("failed to synthesize long text: %v", err)
+			}
+
+			fmt.Printf("Audio saved to: %s\n", outputFile)
+			fmt.Printf("Chunks: %d, Duration: %s\n", result.Chunks, result.Duration.Round(time.Millisecond))
+			if result.HistoryID > 0 {
+				fmt.Printf("History saved with ID: %d\n", result.HistoryID)
+			}
+			return nil
+		}
 		
 		// Use the new history-aware method
-		response, err := aivisClient.SynthesizeToFileWithHistory(ctx, ttsReq, outputFile)
+		response, err := aivisClient.SynthesizeToFileWithEffects(ctx, ttsReq, outputFile, effects)
 		if err != nil {
 			return fmt.Errorf("failed to synthesize to file: %v", err)
 		}
 
 		fmt.Printf("Audio saved to: %s\n", outputFile)
+		if response.Cached {
+			fmt.Println("Served from synthesis cache (no credits used)")
+		}
 		
 		// Show history ID if available
 		if response.HistoryID > 0 {
@@ -301,7 +559,7 @@ var ttsStreamCmd = &cobra.Command{
 	Args:  cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		text := ""
-		modelUUID := defaultModelUUID
+		modelUUID := ""
 		
 		// Get text from args or flag
 		if len(args) > 0 {
@@ -324,7 +582,11 @@ var ttsStreamCmd = &cobra.Command{
 		}
 
 		// Build basic TTS request
-		request := aivisClient.NewTTSRequest(modelUUID, text).Build()
+		profile, err := selectedProfile(cmd)
+		if err != nil {
+			return err
+		}
+		request := applyProfile(profile, withUserDictionaryFlag(cmd, aivisClient.NewTTSRequest(modelUUID, text).Build()))
 
 		ctx := context.Background()
 		
@@ -342,7 +604,10 @@ var ttsStreamCmd = &cobra.Command{
 var ttsControlCmd = &cobra.Command{
 	Use:   "control [action]",
 	Short: "Control audio playback",
-	Long:  "Control ongoing audio playback (stop, pause, resume, status)",
+	Long: `Control ongoing audio playback (stop, pause, resume, status, clear).
+
+Playback only outlives the command that started it when the playback daemon is
+running ('daemon start'); the actions then apply to the daemon's player.`,
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		action := args[0]
@@ -410,6 +675,8 @@ func init() {
 	// TTS play command flags
 	ttsPlayCmd.Flags().String("text", "", "Text to synthesize")
 	ttsPlayCmd.Flags().String("model-uuid", "", "Voice model UUID (uses default if not specified)")
+	ttsPlayCmd.Flags().String("profile", "", "Voice profile to use (default: the active profile, see 'config profile')")
+	ttsPlayCmd.Flags().String("user-dictionary", "", "User dictionary UUID to adjust readings (see 'dictionaries')")
 	ttsPlayCmd.Flags().Float64("volume", 0, "Audio volume (0.0 to 2.0)")
 	ttsPlayCmd.Flags().Float64("rate", 0, "Speaking rate (0.5 to 2.0)")
 	ttsPlayCmd.Flags().Float64("pitch", 0, "Pitch adjustment (-1.0 to 1.0)")
@@ -422,6 +689,8 @@ func init() {
 	ttsSynthesizeCmd.Flags().String("text", "", "Text to synthesize")
 	ttsSynthesizeCmd.Flags().String("output", "", "Output file path (auto-generated if not specified)")
 	ttsSynthesizeCmd.Flags().String("model-uuid", "", "Voice model UUID (uses default if not specified)")
+	ttsSynthesizeCmd.Flags().String("profile", "", "Voice profile to use (default: the active profile, see 'config profile')")
+	ttsSynthesizeCmd.Flags().String("user-dictionary", "", "User dictionary UUID to adjust readings (see 'dictionaries')")
 	ttsSynthesizeCmd.Flags().Float64("volume", 0, "Audio volume (0.0 to 2.0)")
 	ttsSynthesizeCmd.Flags().Float64("rate", 0, "Speaking rate (0.5 to 2.0)")
 	ttsSynthesizeCmd.Flags().Float64("pitch", 0, "Pitch adjustment (-1.0 to 1.0)")
@@ -432,13 +701,35 @@ func init() {
 	ttsSynthesizeCmd.Flags().Float64("trailing-silence", 0, "Trailing silence duration in seconds (0.0 to 60.0)")
 	ttsSynthesizeCmd.Flags().Int("sampling-rate", 0, "Output sampling rate (8000, 11025, 12000, 16000, 22050, 24000, 44100, 48000)")
 	ttsSynthesizeCmd.Flags().Int("bitrate", 0, "Output bitrate in kbps (8 to 320, not applicable for wav/flac)")
+	ttsSynthesizeCmd.Flags().String("text-file", "", "Read text from a file (- for stdin)")
+	ttsSynthesizeCmd.Flags().Bool("long", false, "Split long text at sentence boundaries and join the audio (wav/flac only, no SSML)")
+	ttsSynthesizeCmd.Flags().Float64("chunk-silence", 0.3, "Silence between chunks in seconds (with --long or --subtitles)")
+	ttsSynthesizeCmd.Flags().Int("concurrency", 3, "Number of chunks synthesized in parallel (with --long or --subtitles)")
+	ttsSynthesizeCmd.Flags().String("subtitles", "", "Also write sentence timings to an .srt or .vtt subtitle file (wav/flac only, no SSML)")
+	ttsSynthesizeCmd.Flags().Float64("normalize", 0, "Normalize loudness to this many LUFS (--normalize alone uses -16; wav/flac only)")
+	ttsSynthesizeCmd.Flags().Lookup("normalize").NoOptDefVal = "-16"
+	ttsSynthesizeCmd.Flags().Bool("trim-silence", false, "Trim leading and trailing silence (wav/flac only)")
+	ttsSynthesizeCmd.Flags().Float64("trim-threshold", audio.DefaultTrimThreshold, "Level in dBFS below which audio counts as silence (with --trim-silence)")
+	ttsSynthesizeCmd.Flags().Duration("trim-padding", 0, "Silence to keep at each end (with --trim-silence)")
+	ttsSynthesizeCmd.Flags().Float64("gain", 0, "Amplify the audio by this many dB (wav/flac only)")
+	ttsSynthesizeCmd.Flags().Int("resample", 0, "Resample the audio to this rate in Hz after synthesis (wav/flac only)")
+	ttsSynthesizeCmd.Flags().String("convert-channels", "", "Convert the audio to mono or stereo after synthesis (wav/flac only)")
 
 	// TTS stream command flags
 	ttsStreamCmd.Flags().String("text", "", "Text to synthesize")
 	ttsStreamCmd.Flags().String("model-uuid", "", "Voice model UUID (uses default if not specified)")
+	ttsStreamCmd.Flags().String("profile", "", "Voice profile to use (default: the active profile, see 'config profile')")
+	ttsStreamCmd.Flags().String("user-dictionary", "", "User dictionary UUID to adjust readings (see 'dictionaries')")
 
     // tts play options
     ttsPlayCmd.Flags().Bool("save-history", true, "save playback to history while playing (use --save-history=false to disable)")
+	ttsPlayCmd.Flags().String("text-file", "", "Read text from a file (- for stdin)")
+	ttsPlayCmd.Flags().Bool("long", false, "Split long text into chunks; playback starts with the first chunk (no SSML)")
+	ttsPlayCmd.Flags().Float64("chunk-silence", 0.3, "Silence between chunks in seconds (with --long)")
+	ttsPlayCmd.Flags().Int("concurrency", 3, "Number of chunks synthesized in parallel (with --long)")
+	addPlaybackEnvelopeFlags(ttsPlayCmd)
+	addHistoryLabelFlags(ttsPlayCmd)
+	addHistoryLabelFlags(ttsSynthesizeCmd)
 
     // Add subcommands to tts command
     ttsCmd.AddCommand(ttsPlayCmd)
@@ -0,0 +1,781 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// batchRow is a single line of a batch manifest
+type batchRow struct {
+	Text               string   `json:"text" yaml:"text"`
+	ModelUUID          string   `json:"model_uuid,omitempty" yaml:"model_uuid,omitempty"`
+	SpeakerUUID        string   `json:"speaker_uuid,omitempty" yaml:"speaker_uuid,omitempty"`
+	StyleID            *int     `json:"style_id,omitempty" yaml:"style_id,omitempty"`
+	StyleName          string   `json:"style_name,omitempty" yaml:"style_name,omitempty"`
+	Output             string   `json:"output,omitempty" yaml:"output,omitempty"`
+	Format             string   `json:"format,omitempty" yaml:"format,omitempty"`
+	Volume             *float64 `json:"volume,omitempty" yaml:"volume,omitempty"`
+	Rate               *float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+	Pitch              *float64 `json:"pitch,omitempty" yaml:"pitch,omitempty"`
+	EmotionalIntensity *float64 `json:"emotional_intensity,omitempty" yaml:"emotional_intensity,omitempty"`
+	TempoDynamics      *float64 `json:"tempo_dynamics,omitempty" yaml:"tempo_dynamics,omitempty"`
+	LeadingSilence     *float64 `json:"leading_silence,omitempty" yaml:"leading_silence,omitempty"`
+	TrailingSilence    *float64 `json:"trailing_silence,omitempty" yaml:"trailing_silence,omitempty"`
+	SSML               *bool    `json:"ssml,omitempty" yaml:"ssml,omitempty"`
+
+	// line is the 1-based position of the row in the manifest
+	line int
+}
+
+// batchRowResult is the outcome of a single manifest row
+type batchRowResult struct {
+	Line        int      `json:"line"`
+	Text        string   `json:"text"`
+	Output      string   `json:"output"`
+	Status      string   `json:"status"` // succeeded, failed, skipped
+	Error       string   `json:"error,omitempty"`
+	CreditsUsed *float64 `json:"credits_used,omitempty"`
+	HistoryID   int      `json:"history_id,omitempty"`
+	ContentHash string   `json:"content_hash,omitempty"`
+}
+
+// batchReport summarizes a batch run
+type batchReport struct {
+	Manifest         string           `json:"manifest"`
+	Total            int              `json:"total"`
+	Succeeded        int              `json:"succeeded"`
+	Failed           int              `json:"failed"`
+	Skipped          int              `json:"skipped"`
+	CreditsUsed      float64          `json:"credits_used"`
+	CreditsRemaining string           `json:"credits_remaining,omitempty"`
+	Duration         string           `json:"duration"`
+	Rows             []batchRowResult `json:"rows"`
+}
+
+// batchState records completed rows so that interrupted runs can resume
+type batchState struct {
+	Entries map[string]batchStateEntry `json:"entries"`
+}
+
+type batchStateEntry struct {
+	RequestHash string    `json:"request_hash"`
+	ContentHash string    `json:"content_hash"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+var ttsBatchCmd = &cobra.Command{
+	Use:   "batch <manifest>",
+	Short: "Synthesize many lines from a manifest file",
+	Long: `Synthesize every row of a CSV, JSONL or YAML manifest concurrently.
+
+Each row may set: text (required), model_uuid, speaker_uuid, style_id, style_name,
+output, format, volume, rate, pitch, emotional_intensity, tempo_dynamics,
+leading_silence, trailing_silence, ssml. Settings a row leaves out are taken
+from --profile or the active voice profile.
+
+Rows must not share an output file; rows that do fail before anything is
+synthesized.
+
+Completed rows are recorded in a state file next to the manifest. On the next run,
+rows whose output file still matches the recorded content hash (and whose
+parameters are unchanged) are skipped. Interrupting a run with Ctrl-C stops
+dispatching rows, and the summary and report are still written.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifestPath := args[0]
+
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		format, _ := cmd.Flags().GetString("format")
+		modelUUID, _ := cmd.Flags().GetString("model-uuid")
+		statePath, _ := cmd.Flags().GetString("state")
+		reportPath, _ := cmd.Flags().GetString("report")
+		force, _ := cmd.Flags().GetBool("force")
+		saveHistory, _ := cmd.Flags().GetBool("save-history")
+
+		if concurrency <= 0 {
+			return fmt.Errorf("concurrency must be positive")
+		}
+		profile, err := selectedProfile(cmd)
+		if err != nil {
+			return err
+		}
+		if profile != nil {
+			if modelUUID == "" {
+				modelUUID = profile.ModelUUID
+			}
+			if profile.OutputFormat != "" && !cmd.Flags().Changed("format") {
+				format = string(profile.OutputFormat)
+			}
+		}
+		if modelUUID == "" {
+			modelUUID = viper.GetString("default_model_uuid")
+		}
+		if modelUUID == "" {
+			modelUUID = defaultModelUUID
+		}
+		if outputDir == "" {
+			outputDir = filepath.Dir(manifestPath)
+		}
+		if statePath == "" {
+			statePath = manifestPath + ".state.json"
+		}
+
+		rows, err := loadBatchManifest(manifestPath)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			fmt.Println("Manifest contains no rows.")
+			return nil
+		}
+
+		state, err := loadBatchState(statePath)
+		if err != nil {
+			return err
+		}
+
+		runner := &batchRunner{
+			defaultModel:  modelUUID,
+			defaultFormat: format,
+			profile:       profile,
+			outputDir:     outputDir,
+			saveHistory:   saveHistory,
+			force:         force,
+			state:         state,
+			statePath:     statePath,
+			total:         len(rows),
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		start := time.Now()
+		results := runner.run(withHistoryLabels(ctx, cmd), rows, concurrency)
+
+		report := &batchReport{
+			Manifest:         manifestPath,
+			Total:            len(rows),
+			CreditsRemaining: runner.creditsRemaining,
+			Duration:         time.Since(start).Round(time.Millisecond).String(),
+			Rows:             results,
+		}
+		for _, r := range results {
+			switch r.Status {
+			case "succeeded":
+				report.Succeeded++
+			case "failed":
+				report.Failed++
+			case "skipped":
+				report.Skipped++
+			}
+			if r.CreditsUsed != nil {
+				report.CreditsUsed += *r.CreditsUsed
+			}
+		}
+
+		fmt.Println()
+		fmt.Println("Batch Summary")
+		fmt.Println(strings.Repeat("=", 25))
+		fmt.Printf("Total Rows: %d\n", report.Total)
+		fmt.Printf("Succeeded: %d\n", report.Succeeded)
+		fmt.Printf("Skipped: %d\n", report.Skipped)
+		fmt.Printf("Failed: %d\n", report.Failed)
+		fmt.Printf("Credits Used: %.4f\n", report.CreditsUsed)
+		if report.CreditsRemaining != "" {
+			fmt.Printf("Credits Remaining: %s\n", report.CreditsRemaining)
+		}
+		fmt.Printf("Duration: %s\n", report.Duration)
+
+		if reportPath != "" {
+			data, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode report: %v", err)
+			}
+			if err := os.WriteFile(reportPath, data, 0644); err != nil {
+				return fmt.Errorf("failed to write report: %v", err)
+			}
+			fmt.Printf("Report saved to: %s\n", reportPath)
+		}
+
+		if report.Failed > 0 {
+			return fmt.Errorf("%d of %d rows failed", report.Failed, report.Total)
+		}
+		return nil
+	},
+}
+
+// batchRunner synthesizes manifest rows with a bounded worker pool
+type batchRunner struct {
+	defaultModel  string
+	defaultFormat string
+	profile       *ttsDomain.VoiceProfile
+	outputDir     string
+	saveHistory   bool
+	force         bool
+	statePath     string
+	total         int
+
+	// mu guards state and progress output
+	mu               sync.Mutex
+	state            *batchState
+	done             int
+	creditsRemaining string
+}
+
+// batchJob is a manifest row resolved into its request and output path
+type batchJob struct {
+	row        *batchRow
+	request    *ttsDomain.TTSRequest
+	outputPath string
+	err        error
+}
+
+// failed returns a failed result for the job
+func (j *batchJob) failed(err error) batchRowResult {
+	return batchRowResult{Line: j.row.line, Text: j.row.Text, Output: j.outputPath, Status: "failed", Error: err.Error()}
+}
+
+func (b *batchRunner) run(ctx context.Context, rows []*batchRow, concurrency int) []batchRowResult {
+	jobs := b.prepare(rows)
+	results := make([]batchRowResult, len(jobs))
+	queue := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = b.process(ctx, jobs[i])
+				b.reportProgress(&results[i])
+			}
+		}()
+	}
+
+	for i, job := range jobs {
+		if job.err != nil {
+			results[i] = job.failed(job.err)
+			b.reportProgress(&results[i])
+			continue
+		}
+		// Rows not yet dispatched when the run is interrupted are left undone
+		if ctx.Err() != nil {
+			results[i] = job.failed(fmt.Errorf("interrupted"))
+			continue
+		}
+		select {
+		case queue <- i:
+		case <-ctx.Done():
+			results[i] = job.failed(fmt.Errorf("interrupted"))
+		}
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// prepare builds the request of every row. Rows sharing an output path all
+// fail, since their workers would overwrite each other's file.
+func (b *batchRunner) prepare(rows []*batchRow) []*batchJob {
+	jobs := make([]*batchJob, len(rows))
+	byOutput := map[string][]*batchJob{}
+	for i, row := range rows {
+		job := &batchJob{row: row}
+		job.request, job.outputPath, job.err = b.buildRequest(row)
+		if job.err == nil {
+			byOutput[job.outputPath] = append(byOutput[job.outputPath], job)
+		}
+		jobs[i] = job
+	}
+
+	for _, shared := range byOutput {
+		if len(shared) < 2 {
+			continue
+		}
+		lines := make([]string, len(shared))
+		for i, job := range shared {
+			lines[i] = strconv.Itoa(job.row.line)
+		}
+		for _, job := range shared {
+			job.err = fmt.Errorf("output %s is shared by lines %s; give each row its own output", job.outputPath, strings.Join(lines, ", "))
+		}
+	}
+	return jobs
+}
+
+func (b *batchRunner) process(ctx context.Context, job *batchJob) batchRowResult {
+	row, request, outputPath := job.row, job.request, job.outputPath
+	result := batchRowResult{Line: row.line, Text: row.Text, Output: outputPath, Status: "failed"}
+
+	requestHash, err := hashTTSRequest(request)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if !b.force {
+		if contentHash, ok := b.completedHash(outputPath, requestHash); ok {
+			result.Status = "skipped"
+			result.ContentHash = contentHash
+			return result
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		result.Error = fmt.Sprintf("failed to create output directory: %v", err)
+		return result
+	}
+
+	response, err := aivisClient.Synthesize(ctx, request)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	contentHash, err := writeAudioFile(outputPath, response.AudioData)
+	response.AudioData.Close()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Status = "succeeded"
+	result.ContentHash = contentHash
+	if response.BillingInfo != nil {
+		if credits, err := strconv.ParseFloat(response.BillingInfo.CreditsUsed, 64); err == nil {
+			result.CreditsUsed = &credits
+		}
+	}
+
+	// The history store locks its own files, so workers save concurrently
+	if b.saveHistory {
+		history, err := aivisClient.SaveTTSHistory(ctx, request, outputPath, response.BillingInfo)
+		if err != nil {
+			if verbose {
+				fmt.Fprintf(os.Stderr, "Warning: failed to save history for line %d: %v\n", row.line, err)
+			}
+		} else if history != nil {
+			result.HistoryID = history.ID
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if response.BillingInfo != nil && response.BillingInfo.CreditsRemaining != "" {
+		b.creditsRemaining = response.BillingInfo.CreditsRemaining
+	}
+
+	b.state.Entries[outputPath] = batchStateEntry{
+		RequestHash: requestHash,
+		ContentHash: contentHash,
+		CompletedAt: time.Now(),
+	}
+	if err := saveBatchState(b.statePath, b.state); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to save batch state: %v\n", err)
+	}
+
+	return result
+}
+
+// completedHash returns the content hash when the row was already synthesized
+// with the same parameters and the output file is unchanged
+func (b *batchRunner) completedHash(outputPath, requestHash string) (string, bool) {
+	b.mu.Lock()
+	entry, ok := b.state.Entries[outputPath]
+	b.mu.Unlock()
+	if !ok || entry.RequestHash != requestHash {
+		return "", false
+	}
+
+	contentHash, err := hashFile(outputPath)
+	if err != nil || contentHash != entry.ContentHash {
+		return "", false
+	}
+	return contentHash, true
+}
+
+func (b *batchRunner) reportProgress(result *batchRowResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.done++
+	switch result.Status {
+	case "failed":
+		fmt.Printf("[%d/%d] line %d failed: %s\n", b.done, b.total, result.Line, result.Error)
+	case "skipped":
+		fmt.Printf("[%d/%d] line %d skipped (up to date): %s\n", b.done, b.total, result.Line, result.Output)
+	default:
+		fmt.Printf("[%d/%d] line %d saved: %s\n", b.done, b.total, result.Line, result.Output)
+	}
+}
+
+// buildRequest converts a manifest row into a TTS request and resolves its output path
+func (b *batchRunner) buildRequest(row *batchRow) (*ttsDomain.TTSRequest, string, error) {
+	if strings.TrimSpace(row.Text) == "" {
+		return nil, "", fmt.Errorf("text is required")
+	}
+
+	format := row.Format
+	if format == "" && row.Output != "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(row.Output)), ".")
+	}
+	if format == "" {
+		format = b.defaultFormat
+	}
+	outputFormat, ok := parseOutputFormat(format)
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported format: %s", format)
+	}
+
+	outputPath := row.Output
+	if outputPath == "" {
+		outputPath = fmt.Sprintf("line_%04d%s", row.line, ttsDomain.GetFileExtensionFromFormat(outputFormat))
+	}
+	if !filepath.IsAbs(outputPath) {
+		outputPath = filepath.Join(b.outputDir, outputPath)
+	}
+	if abs, err := filepath.Abs(outputPath); err == nil {
+		outputPath = abs
+	}
+
+	modelUUID := row.ModelUUID
+	if modelUUID == "" {
+		modelUUID = b.defaultModel
+	}
+
+	request := ttsDomain.NewTTSRequestBuilder(modelUUID, row.Text).WithOutputFormat(outputFormat)
+	if row.SpeakerUUID != "" {
+		request = request.WithSpeaker(row.SpeakerUUID)
+	}
+	if row.StyleID != nil {
+		request = request.WithStyleID(*row.StyleID)
+	}
+	if row.StyleName != "" {
+		request = request.WithStyleName(row.StyleName)
+	}
+	if row.Volume != nil {
+		request = request.WithVolume(*row.Volume)
+	}
+	if row.Rate != nil {
+		request = request.WithSpeakingRate(*row.Rate)
+	}
+	if row.Pitch != nil {
+		request = request.WithPitch(*row.Pitch)
+	}
+	if row.EmotionalIntensity != nil {
+		request = request.WithEmotionalIntensity(*row.EmotionalIntensity)
+	}
+	if row.TempoDynamics != nil {
+		request = request.WithTempoDynamics(*row.TempoDynamics)
+	}
+	if row.LeadingSilence != nil {
+		request = request.WithLeadingSilence(*row.LeadingSilence)
+	}
+	if row.TrailingSilence != nil {
+		request = request.WithTrailingSilence(*row.TrailingSilence)
+	}
+	if row.SSML != nil {
+		request = request.WithSSML(*row.SSML)
+	}
+
+	return b.profile.Apply(request.Build()), outputPath, nil
+}
+
+// parseOutputFormat maps a format name to an OutputFormat
+func parseOutputFormat(format string) (ttsDomain.OutputFormat, bool) {
+	switch strings.ToLower(format) {
+	case "wav":
+		return ttsDomain.OutputFormatWAV, true
+	case "flac":
+		return ttsDomain.OutputFormatFLAC, true
+	case "mp3":
+		return ttsDomain.OutputFormatMP3, true
+	case "aac":
+		return ttsDomain.OutputFormatAAC, true
+	case "opus":
+		return ttsDomain.OutputFormatOpus, true
+	}
+	return "", false
+}
+
+// writeAudioFile writes audio to a temporary file, renames it into place and
+// returns the SHA-256 of the content
+func writeAudioFile(path string, audio io.Reader) (string, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".batch-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	hasher := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hasher), audio); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write audio data: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write audio data: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to move audio file into place: %w", err)
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func hashTTSRequest(request *ttsDomain.TTSRequest) (string, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode request: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func loadBatchState(path string) (*batchState, error) {
+	state := &batchState{Entries: map[string]batchStateEntry{}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch state: %v", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("failed to parse batch state %s: %v", path, err)
+	}
+	if state.Entries == nil {
+		state.Entries = map[string]batchStateEntry{}
+	}
+	return state, nil
+}
+
+func saveBatchState(path string, state *batchState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadBatchManifest parses a CSV, JSONL or YAML manifest based on its extension
+func loadBatchManifest(path string) ([]*batchRow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %v", err)
+	}
+	defer f.Close()
+
+	var rows []*batchRow
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		rows, err = parseCSVManifest(f)
+	case ".jsonl", ".ndjson":
+		rows, err = parseJSONLManifest(f)
+	case ".yaml", ".yml":
+		rows, err = parseYAMLManifest(f)
+	default:
+		return nil, fmt.Errorf("unsupported manifest type %q (use .csv, .jsonl or .yaml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse manifest: %v", err)
+	}
+	return rows, nil
+}
+
+func parseJSONLManifest(r io.Reader) ([]*batchRow, error) {
+	var rows []*batchRow
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		row := &batchRow{}
+		if err := json.Unmarshal([]byte(text), row); err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		row.line = line
+		rows = append(rows, row)
+	}
+	return rows, scanner.Err()
+}
+
+func parseYAMLManifest(r io.Reader) ([]*batchRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accept either a top-level list or a mapping with a "rows" key
+	var rows []*batchRow
+	if err := yaml.Unmarshal(data, &rows); err != nil {
+		var doc struct {
+			Rows []*batchRow `yaml:"rows"`
+		}
+		if err2 := yaml.Unmarshal(data, &doc); err2 != nil {
+			return nil, err
+		}
+		rows = doc.Rows
+	}
+	for i, row := range rows {
+		row.line = i + 1
+	}
+	return rows, nil
+}
+
+func parseCSVManifest(r io.Reader) ([]*batchRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %v", err)
+	}
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var rows []*batchRow
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+
+		row := &batchRow{line: line}
+		for i, value := range record {
+			if i >= len(header) {
+				break
+			}
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			if err := setBatchField(row, header[i], value); err != nil {
+				return nil, fmt.Errorf("line %d, column %s: %v", line, header[i], err)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// setBatchField assigns a CSV cell to the matching row field
+func setBatchField(row *batchRow, column, value string) error {
+	parseFloat := func() (*float64, error) {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected number")
+		}
+		return &f, nil
+	}
+
+	var err error
+	switch column {
+	case "text":
+		row.Text = value
+	case "model_uuid":
+		row.ModelUUID = value
+	case "speaker_uuid":
+		row.SpeakerUUID = value
+	case "style_id":
+		id, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return fmt.Errorf("expected integer")
+		}
+		row.StyleID = &id
+	case "style_name":
+		row.StyleName = value
+	case "output":
+		row.Output = value
+	case "format":
+		row.Format = value
+	case "volume":
+		row.Volume, err = parseFloat()
+	case "rate":
+		row.Rate, err = parseFloat()
+	case "pitch":
+		row.Pitch, err = parseFloat()
+	case "emotional_intensity":
+		row.EmotionalIntensity, err = parseFloat()
+	case "tempo_dynamics":
+		row.TempoDynamics, err = parseFloat()
+	case "leading_silence":
+		row.LeadingSilence, err = parseFloat()
+	case "trailing_silence":
+		row.TrailingSilence, err = parseFloat()
+	case "ssml":
+		b, convErr := strconv.ParseBool(value)
+		if convErr != nil {
+			return fmt.Errorf("expected boolean")
+		}
+		row.SSML = &b
+	default:
+		// Unknown columns are ignored so manifests can carry notes
+	}
+	return err
+}
+
+func init() {
+	ttsBatchCmd.Flags().Int("concurrency", 4, "Number of rows synthesized in parallel")
+	ttsBatchCmd.Flags().String("output-dir", "", "Directory for relative output paths (default: manifest directory)")
+	ttsBatchCmd.Flags().String("format", "wav", "Default output format: wav, flac, mp3, aac, opus")
+	ttsBatchCmd.Flags().String("model-uuid", "", "Default voice model UUID for rows without model_uuid")
+	ttsBatchCmd.Flags().String("profile", "", "Voice profile for settings the rows leave out (default: the active profile)")
+	ttsBatchCmd.Flags().String("state", "", "Resume state file (default: <manifest>.state.json)")
+	ttsBatchCmd.Flags().String("report", "", "Write a JSON summary report to this path")
+	ttsBatchCmd.Flags().Bool("force", false, "Re-synthesize rows even if their output is up to date")
+	ttsBatchCmd.Flags().Bool("save-history", true, "Record each synthesized row in TTS history")
+	addHistoryLabelFlags(ttsBatchCmd)
+
+	ttsCmd.AddCommand(ttsBatchCmd)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+func TestParseCSVManifest(t *testing.T) {
+	manifest := " Text , STYLE_ID,notes,rate, output\n" +
+		"hello,3,ignored,1.2,a.wav\n" +
+		"\"comma, inside\",,,,\n"
+	rows, err := parseCSVManifest(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("parseCSVManifest() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	first := rows[0]
+	if first.line != 2 || first.Text != "hello" || first.StyleID == nil || *first.StyleID != 3 || *first.Rate != 1.2 || first.Output != "a.wav" {
+		t.Errorf("first row = %+v", first)
+	}
+	second := rows[1]
+	if second.line != 3 || second.Text != "comma, inside" || second.StyleID != nil || second.Rate != nil || second.Output != "" {
+		t.Errorf("empty cells must be left unset, got %+v", second)
+	}
+
+	if _, err := parseCSVManifest(strings.NewReader("text,volume\nhello,loud\n")); err == nil || !strings.Contains(err.Error(), "line 2, column volume") {
+		t.Errorf("invalid cell error = %v", err)
+	}
+	if _, err := parseCSVManifest(strings.NewReader("")); err == nil {
+		t.Error("a manifest without a header must fail")
+	}
+}
+
+func TestParseJSONLManifest(t *testing.T) {
+	manifest := `{"text": "one", "style_name": "Happy"}
+
+# a comment
+{"text": "two", "ssml": true, "pitch": -0.5}
+`
+	rows, err := parseJSONLManifest(strings.NewReader(manifest))
+	if err != nil {
+		t.Fatalf("parseJSONLManifest() error = %v", err)
+	}
+	if len(rows) != 2 || rows[0].line != 1 || rows[0].StyleName != "Happy" {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[1].line != 4 || !*rows[1].SSML || *rows[1].Pitch != -0.5 {
+		t.Errorf("second row = %+v", rows[1])
+	}
+
+	if _, err := parseJSONLManifest(strings.NewReader("{\"text\": \"ok\"}\n{broken\n")); err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("invalid line error = %v", err)
+	}
+}
+
+func TestParseYAMLManifest(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"list":    "- text: one\n  format: mp3\n- text: two\n  volume: 1.5\n",
+		"mapping": "rows:\n  - text: one\n    format: mp3\n  - text: two\n    volume: 1.5\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			rows, err := parseYAMLManifest(strings.NewReader(manifest))
+			if err != nil {
+				t.Fatalf("parseYAMLManifest() error = %v", err)
+			}
+			if len(rows) != 2 || rows[0].Text != "one" || rows[0].Format != "mp3" || rows[1].line != 2 || *rows[1].Volume != 1.5 {
+				t.Errorf("rows = %+v", rows)
+			}
+		})
+	}
+
+	if _, err := parseYAMLManifest(strings.NewReader("text: [unclosed\n")); err == nil {
+		t.Error("invalid YAML must fail")
+	}
+}
+
+func TestSetBatchField(t *testing.T) {
+	tests := []struct {
+		column, value string
+		check         func(*batchRow) bool
+		wantErr       string
+	}{
+		{column: "model_uuid", value: "m", check: func(r *batchRow) bool { return r.ModelUUID == "m" }},
+		{column: "speaker_uuid", value: "s", check: func(r *batchRow) bool { return r.SpeakerUUID == "s" }},
+		{column: "style_id", value: "7", check: func(r *batchRow) bool { return *r.StyleID == 7 }},
+		{column: "style_id", value: "1.5", wantErr: "expected integer"},
+		{column: "format", value: "flac", check: func(r *batchRow) bool { return r.Format == "flac" }},
+		{column: "emotional_intensity", value: "1.1", check: func(r *batchRow) bool { return *r.EmotionalIntensity == 1.1 }},
+		{column: "tempo_dynamics", value: "0.8", check: func(r *batchRow) bool { return *r.TempoDynamics == 0.8 }},
+		{column: "leading_silence", value: "0.2", check: func(r *batchRow) bool { return *r.LeadingSilence == 0.2 }},
+		{column: "trailing_silence", value: "x", wantErr: "expected number"},
+		{column: "ssml", value: "true", check: func(r *batchRow) bool { return *r.SSML }},
+		{column: "ssml", value: "maybe", wantErr: "expected boolean"},
+		{column: "comment", value: "anything", check: func(r *batchRow) bool { return *r == batchRow{} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.column+"="+tt.value, func(t *testing.T) {
+			row := &batchRow{}
+			err := setBatchField(row, tt.column, tt.value)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("setBatchField() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("setBatchField() error = %v", err)
+			}
+			if !tt.check(row) {
+				t.Errorf("setBatchField() row = %+v", row)
+			}
+		})
+	}
+}
+
+func TestBatchRunnerCompletedHash(t *testing.T) {
+	dir := t.TempDir()
+	output := filepath.Join(dir, "line.wav")
+	contentHash, err := writeAudioFile(output, strings.NewReader("audio"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner := &batchRunner{state: &batchState{Entries: map[string]batchStateEntry{
+		output: {RequestHash: "request", ContentHash: contentHash},
+	}}}
+
+	if got, ok := runner.completedHash(output, "request"); !ok || got != contentHash {
+		t.Errorf("unchanged row: completedHash() = %q, %v, want a skip", got, ok)
+	}
+	if _, ok := runner.completedHash(output, "changed"); ok {
+		t.Error("a row with changed parameters must be redone")
+	}
+	if _, ok := runner.completedHash(filepath.Join(dir, "other.wav"), "request"); ok {
+		t.Error("a row without a state entry must be done")
+	}
+
+	if err := os.WriteFile(output, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := runner.completedHash(output, "request"); ok {
+		t.Error("a row whose output was edited must be redone")
+	}
+	if err := os.Remove(output); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := runner.completedHash(output, "request"); ok {
+		t.Error("a row whose output was deleted must be redone")
+	}
+}
+
+func TestBatchRunnerRejectsSharedOutputs(t *testing.T) {
+	dir := t.TempDir()
+	runner := &batchRunner{defaultModel: "model", defaultFormat: "wav", outputDir: dir}
+	rows := []*batchRow{
+		{line: 1, Text: "one", Output: "same.wav"},
+		{line: 2, Text: "two"},
+		{line: 3, Text: "three", Output: filepath.Join(dir, "same.wav")},
+		{line: 4, Text: "four", Output: "line_0002.wav"},
+		{line: 5, Text: ""},
+	}
+	jobs := runner.prepare(rows)
+
+	for _, i := range []int{0, 2} {
+		if jobs[i].err == nil || !strings.Contains(jobs[i].err.Error(), "shared by lines 1, 3") {
+			t.Errorf("line %d error = %v, want a shared output error", rows[i].line, jobs[i].err)
+		}
+	}
+	// A generated name can collide with an explicit one
+	for _, i := range []int{1, 3} {
+		if jobs[i].err == nil || !strings.Contains(jobs[i].err.Error(), "shared by lines 2, 4") {
+			t.Errorf("line %d error = %v, want a shared output error", rows[i].line, jobs[i].err)
+		}
+	}
+	if jobs[4].err == nil || jobs[4].err.Error() != "text is required" {
+		t.Errorf("line 5 error = %v", jobs[4].err)
+	}
+
+	jobs = runner.prepare([]*batchRow{{line: 1, Text: "one"}, {line: 2, Text: "two", Format: "mp3"}})
+	for _, job := range jobs {
+		if job.err != nil || job.request.ModelUUID != "model" {
+			t.Errorf("line %d: %+v, %v", job.row.line, job.request, job.err)
+		}
+	}
+	if *jobs[1].request.OutputFormat != ttsDomain.OutputFormatMP3 || filepath.Base(jobs[1].outputPath) != "line_0002.mp3" {
+		t.Errorf("line 2 = %s, %v", jobs[1].outputPath, *jobs[1].request.OutputFormat)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var ttsCacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the local synthesis cache",
+	Long: `Manage the local synthesis cache.
+
+When cache_enabled is true, repeated synthesis requests with the same model,
+speaker, style, text and parameters are served from disk without using credits.
+Use 'config set cache_max_size_mb' and 'config set cache_max_age' to bound it.`,
+}
+
+var ttsCacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show synthesis cache statistics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stats, err := aivisClient.GetCacheStats(context.Background())
+		if err != nil {
+			return fmt.Errorf("failed to get cache stats: %v", err)
+		}
+
+		enabled := "disabled"
+		if aivisClient.GetConfig().CacheEnabled {
+			enabled = "enabled"
+		}
+		maxSize := "unlimited"
+		if stats.MaxSize > 0 {
+			maxSize = formatFileSize(stats.MaxSize)
+		}
+		maxAge := "never"
+		if stats.MaxAge != "" {
+			maxAge = stats.MaxAge
+		}
+
+		fmt.Println("Synthesis Cache Statistics")
+		fmt.Println(strings.Repeat("=", 26))
+		fmt.Printf("Status: %s\n", enabled)
+		fmt.Printf("Path: %s\n", stats.Path)
+		fmt.Printf("Entries: %d\n", stats.Entries)
+		fmt.Printf("Total Size: %s (limit: %s)\n", formatFileSize(stats.TotalSize), maxSize)
+		fmt.Printf("Expire After: %s\n", maxAge)
+		fmt.Printf("Hits: %d, Misses: %d (hit rate %.1f%%)\n", stats.Hits, stats.Misses, stats.HitRate()*100)
+		if stats.OldestEntry != nil {
+			fmt.Printf("Oldest Entry: %s\n", stats.OldestEntry.Format(time.RFC3339))
+			fmt.Printf("Newest Entry: %s\n", stats.NewestEntry.Format(time.RFC3339))
+		}
+		return nil
+	},
+}
+
+var ttsCacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached audio",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			fmt.Print("Are you sure you want to delete all cached audio? [y/N]: ")
+			var response string
+			fmt.Scanln(&response)
+
+			if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+				fmt.Println("Clear cancelled.")
+				return nil
+			}
+		}
+
+		if err := aivisClient.ClearCache(context.Background()); err != nil {
+			return fmt.Errorf("failed to clear cache: %v", err)
+		}
+		fmt.Println("Synthesis cache cleared.")
+		return nil
+	},
+}
+
+var ttsCachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired and least recently used cache entries",
+	Long: `Remove entries older than cache_max_age, then evict the least recently used
+entries until the cache fits in cache_max_size_mb (or --max-size-mb).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		maxSizeMB, _ := cmd.Flags().GetInt64("max-size-mb")
+		if maxSizeMB < 0 {
+			return fmt.Errorf("--max-size-mb must not be negative")
+		}
+
+		result, err := aivisClient.PruneCache(context.Background(), maxSizeMB*1024*1024)
+		if err != nil {
+			return fmt.Errorf("failed to prune cache: %v", err)
+		}
+		fmt.Printf("Removed %d cache entries, freed %s.\n", result.RemovedEntries, formatFileSize(result.FreedBytes))
+		return nil
+	},
+}
+
+func init() {
+	ttsCacheClearCmd.Flags().Bool("force", false, "Skip confirmation prompt")
+	ttsCachePruneCmd.Flags().Int64("max-size-mb", 0, "Size to shrink the cache to in MB (default from cache_max_size_mb)")
+
+	ttsCacheCmd.AddCommand(ttsCacheStatsCmd)
+	ttsCacheCmd.AddCommand(ttsCacheClearCmd)
+	ttsCacheCmd.AddCommand(ttsCachePruneCmd)
+	ttsCmd.AddCommand(ttsCacheCmd)
+}
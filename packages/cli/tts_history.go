@@ -31,6 +31,9 @@ var ttsHistoryListCmd = &cobra.Command{
 		offset, _ := cmd.Flags().GetInt("offset")
 		modelUUID, _ := cmd.Flags().GetString("model-uuid")
 		textContains, _ := cmd.Flags().GetString("text-contains")
+		session, _ := cmd.Flags().GetString("session")
+		project, _ := cmd.Flags().GetString("project")
+		tag, _ := cmd.Flags().GetString("tag")
 		
 		// Build search request
 		searchBuilder := aivisClient.NewTTSHistorySearchRequest().
@@ -43,6 +46,15 @@ var ttsHistoryListCmd = &cobra.Command{
 		if textContains != "" {
 			searchBuilder = searchBuilder.WithTextContains(textContains)
 		}
+		if session != "" {
+			searchBuilder = searchBuilder.WithScriptSession(session)
+		}
+		if project != "" {
+			searchBuilder = searchBuilder.WithProject(project)
+		}
+		if tag != "" {
+			searchBuilder = searchBuilder.WithTag(tag)
+		}
 		
 		searchRequest := searchBuilder.Build()
 		
@@ -132,6 +144,21 @@ var ttsHistoryShowCmd = &cobra.Command{
 		fmt.Printf("Text: %s\n", history.Text)
 		fmt.Printf("Model UUID: %s\n", history.ModelUUID)
 		fmt.Printf("Created: %s\n", history.CreatedAt.Format("2006-01-02 15:04:05"))
+		if history.ParentID != 0 {
+			fmt.Printf("Regenerated From: #%d\n", history.ParentID)
+		}
+		if history.ScriptSession != "" {
+			fmt.Printf("Script Session: %s\n", history.ScriptSession)
+		}
+		if history.Project != "" {
+			fmt.Printf("Project: %s\n", history.Project)
+		}
+		if history.Profile != "" {
+			fmt.Printf("Profile: %s\n", history.Profile)
+		}
+		if len(history.Tags) > 0 {
+			fmt.Printf("Tags: %s\n", strings.Join(history.Tags, ", "))
+		}
 		fmt.Printf("File Path: %s\n", history.FilePath)
 		fmt.Printf("File Format: %s\n", history.FileFormat)
         // Prefer actual file size if available
@@ -234,6 +261,10 @@ var ttsHistoryPlayCmd = &cobra.Command{
 			playbackBuilder = playbackBuilder.WithMode(ttsDomain.PlaybackModeNoQueue)
 		}
 		
+		playbackBuilder, err = applyPlaybackEnvelope(cmd, playbackBuilder)
+		if err != nil {
+			return err
+		}
 		playbackOptions := playbackBuilder.Build()
 		
 		// Play history
@@ -432,11 +463,15 @@ func init() {
 	ttsHistoryListCmd.Flags().Int("offset", 0, "Number of records to skip")
 	ttsHistoryListCmd.Flags().String("model-uuid", "", "Filter by model UUID")
 	ttsHistoryListCmd.Flags().String("text-contains", "", "Filter by text content")
+	ttsHistoryListCmd.Flags().String("session", "", "Filter by script session (see tts script)")
+	ttsHistoryListCmd.Flags().String("project", "", "Filter by project label")
+	ttsHistoryListCmd.Flags().String("tag", "", "Filter by tag")
 	
 	// History play command flags
 	ttsHistoryPlayCmd.Flags().Float64("volume", 0, "Playback volume (0.0 to 1.0)")
 	ttsHistoryPlayCmd.Flags().String("mode", "immediate", "Playback mode: immediate, queue, no_queue")
 	ttsHistoryPlayCmd.Flags().Bool("wait", true, "Wait for playback to complete")
+	addPlaybackEnvelopeFlags(ttsHistoryPlayCmd)
 	
 	// History delete command flags
 	ttsHistoryDeleteCmd.Flags().Bool("force", false, "Skip confirmation prompt")
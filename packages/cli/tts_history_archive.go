@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/spf13/cobra"
+)
+
+var ttsHistoryExportCmd = &cobra.Command{
+	Use:   "export <archive>",
+	Short: "Export TTS history to a zip or tar archive",
+	Long: `Export TTS history records with their audio files and original request
+parameters to a portable archive. The format follows the file extension
+(.zip, .tar, .tar.gz/.tgz) unless --format is given. Without filters all
+records are exported.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		fromID, _ := cmd.Flags().GetInt("from-id")
+		toID, _ := cmd.Flags().GetInt("to-id")
+		modelUUID, _ := cmd.Flags().GetString("model-uuid")
+		startDateStr, _ := cmd.Flags().GetString("start-date")
+		endDateStr, _ := cmd.Flags().GetString("end-date")
+
+		options := &ttsDomain.TTSHistoryExportOptions{
+			Format: ttsDomain.HistoryArchiveFormat(format),
+			FromID: fromID,
+			ToID:   toID,
+		}
+		if modelUUID != "" {
+			options.ModelUUID = &modelUUID
+		}
+		if startDateStr != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", startDateStr, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --start-date %q (use YYYY-MM-DD)", startDateStr)
+			}
+			options.StartDate = &parsed
+		}
+		if endDateStr != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", endDateStr, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid --end-date %q (use YYYY-MM-DD)", endDateStr)
+			}
+			// Include the whole end day
+			endOfDay := parsed.AddDate(0, 0, 1).Add(-time.Nanosecond)
+			options.EndDate = &endOfDay
+		}
+
+		result, err := aivisClient.ExportTTSHistory(context.Background(), args[0], options)
+		if err != nil {
+			return fmt.Errorf("failed to export TTS history: %v", err)
+		}
+
+		fmt.Printf("Exported %d TTS history records to %s\n", result.Records, result.Path)
+		if len(result.MissingAudio) > 0 {
+			fmt.Printf("Warning: audio files were missing for %d records (IDs %v); only their metadata was exported.\n",
+				len(result.MissingAudio), result.MissingAudio)
+		}
+		return nil
+	},
+}
+
+var ttsHistoryImportCmd = &cobra.Command{
+	Use:   "import <archive>",
+	Short: "Import TTS history from an exported archive",
+	Long: `Merge TTS history records from an archive created by 'tts history export'
+into the local history. Imported records get new IDs; records that already
+exist locally (same internal UUID) are skipped.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		verbose, _ := cmd.Flags().GetBool("verbose")
+
+		result, err := aivisClient.ImportTTSHistory(context.Background(), args[0])
+		if result != nil && result.Imported > 0 && err != nil {
+			fmt.Printf("Imported %d records before the error.\n", result.Imported)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to import TTS history: %v", err)
+		}
+
+		fmt.Printf("Imported %d TTS history records (%d duplicates skipped).\n", result.Imported, result.Duplicates)
+		if verbose && len(result.IDMap) > 0 {
+			oldIDs := make([]int, 0, len(result.IDMap))
+			for oldID := range result.IDMap {
+				oldIDs = append(oldIDs, oldID)
+			}
+			sort.Ints(oldIDs)
+			for _, oldID := range oldIDs {
+				fmt.Printf("  #%d -> #%d\n", oldID, result.IDMap[oldID])
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	ttsHistoryExportCmd.Flags().String("format", "", "Archive format: zip, tar, tar.gz (default from file extension)")
+	ttsHistoryExportCmd.Flags().Int("from-id", 0, "Export records with ID >= this value")
+	ttsHistoryExportCmd.Flags().Int("to-id", 0, "Export records with ID <= this value")
+	ttsHistoryExportCmd.Flags().String("model-uuid", "", "Export only records of this model")
+	ttsHistoryExportCmd.Flags().String("start-date", "", "Export records created on or after this date (YYYY-MM-DD)")
+	ttsHistoryExportCmd.Flags().String("end-date", "", "Export records created on or before this date (YYYY-MM-DD)")
+
+	ttsHistoryImportCmd.Flags().BoolP("verbose", "v", false, "Show how archive IDs map to new local IDs")
+
+	ttsHistoryCmd.AddCommand(ttsHistoryExportCmd)
+	ttsHistoryCmd.AddCommand(ttsHistoryImportCmd)
+}
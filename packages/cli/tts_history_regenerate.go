@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/spf13/cobra"
+)
+
+var ttsHistoryRegenerateCmd = &cobra.Command{
+	Use:   "regenerate <id>",
+	Short: "Re-synthesize a TTS history record with different parameters",
+	Long: `Re-submit the request stored in a TTS history record, optionally with a
+different model, speaker, style, speaking rate, pitch or format. The result is
+saved as a new history record linked to the original (its parent).
+
+Switching the model drops the stored speaker and style unless they are given
+too. --profile applies the voice and format of a voice profile; the other
+flags take precedence over it. The active profile is not applied, so that a
+plain regeneration keeps the original voice. Use --compare to play the
+original and the new version back to back.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid history ID: %s", args[0])
+		}
+
+		styleName, _ := cmd.Flags().GetString("style-name")
+		if cmd.Flags().Changed("style-id") && styleName != "" {
+			return fmt.Errorf("--style-id and --style-name cannot be used together")
+		}
+
+		overrides := &ttsDomain.TTSRequestOverrides{}
+		if name, _ := cmd.Flags().GetString("profile"); name != "" {
+			profile, err := getProfile(name)
+			if err != nil {
+				return err
+			}
+			overrides = profile.Overrides()
+			if profile.OutputFormat != "" {
+				format := profile.OutputFormat
+				overrides.OutputFormat = &format
+			}
+		}
+		if v, _ := cmd.Flags().GetString("model-uuid"); v != "" {
+			// The profile's speaker and style belong to the profile's model
+			if overrides.ModelUUID != nil && *overrides.ModelUUID != v {
+				overrides.SpeakerUUID, overrides.StyleID, overrides.StyleName = nil, nil, nil
+			}
+			overrides.ModelUUID = &v
+		}
+		if v, _ := cmd.Flags().GetString("speaker-uuid"); v != "" {
+			overrides.SpeakerUUID = &v
+		}
+		if cmd.Flags().Changed("style-id") {
+			v, _ := cmd.Flags().GetInt("style-id")
+			overrides.StyleID, overrides.StyleName = &v, nil
+		}
+		if styleName != "" {
+			overrides.StyleID, overrides.StyleName = nil, &styleName
+		}
+		for flag, target := range map[string]**float64{
+			"rate":                &overrides.SpeakingRate,
+			"pitch":               &overrides.Pitch,
+			"volume":              &overrides.Volume,
+			"emotional-intensity": &overrides.EmotionalIntensity,
+			"tempo-dynamics":      &overrides.TempoDynamics,
+		} {
+			if cmd.Flags().Changed(flag) {
+				v, _ := cmd.Flags().GetFloat64(flag)
+				*target = &v
+			}
+		}
+		if v, _ := cmd.Flags().GetString("format"); v != "" {
+			format, ok := parseOutputFormat(v)
+			if !ok {
+				return fmt.Errorf("invalid format: %s (use wav, flac, mp3, aac, opus)", v)
+			}
+			overrides.OutputFormat = &format
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		history, err := aivisClient.RegenerateFromHistory(ctx, id, overrides)
+		if err != nil {
+			return fmt.Errorf("failed to regenerate TTS history #%d: %v", id, err)
+		}
+
+		fmt.Printf("Regenerated #%d as TTS history record #%d\n", id, history.ID)
+		fmt.Printf("File: %s (%s)\n", history.FilePath, formatFileSize(history.FileSizeBytes))
+		if history.Credits != nil {
+			fmt.Printf("Credits Used: %.4f\n", *history.Credits)
+		}
+
+		if compare, _ := cmd.Flags().GetBool("compare"); compare {
+			gap, _ := cmd.Flags().GetDuration("gap")
+			return playHistoryComparison(ctx, []int{id, history.ID}, gap)
+		}
+		return nil
+	},
+}
+
+var ttsHistoryCompareCmd = &cobra.Command{
+	Use:   "compare <id> [other-id]",
+	Short: "A/B play two TTS history records",
+	Long: `Play two TTS history records back to back. With a single ID, the record is
+compared with the record it was regenerated from.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ids := make([]int, 0, 2)
+		for _, arg := range args {
+			id, err := strconv.Atoi(arg)
+			if err != nil {
+				return fmt.Errorf("invalid history ID: %s", arg)
+			}
+			ids = append(ids, id)
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		if len(ids) == 1 {
+			history, err := aivisClient.GetTTSHistory(ctx, ids[0])
+			if err != nil {
+				return fmt.Errorf("failed to get TTS history: %v", err)
+			}
+			if history.ParentID == 0 {
+				return fmt.Errorf("record #%d was not regenerated from another record; pass a second ID", ids[0])
+			}
+			ids = []int{history.ParentID, ids[0]}
+		}
+
+		gap, _ := cmd.Flags().GetDuration("gap")
+		return playHistoryComparison(ctx, ids, gap)
+	},
+}
+
+// defaultCompareGap is the pause between versions when A/B playing
+const defaultCompareGap = 700 * time.Millisecond
+
+// playHistoryComparison plays records labelled A, B, ... waiting for each to finish
+func playHistoryComparison(ctx context.Context, ids []int, gap time.Duration) error {
+	err := aivisClient.PlayTTSHistorySequence(ctx, ids, gap, func(index int, history *ttsDomain.TTSHistory) {
+		fmt.Printf("[%c] Playing #%d: %s\n", 'A'+index, history.ID, truncateText(history.Text, 40))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to play TTS history: %v", err)
+	}
+	return nil
+}
+
+func init() {
+	ttsHistoryRegenerateCmd.Flags().String("model-uuid", "", "Use a different voice model")
+	ttsHistoryRegenerateCmd.Flags().String("profile", "", "Use the voice and format of a voice profile (see 'config profile')")
+	ttsHistoryRegenerateCmd.Flags().String("speaker-uuid", "", "Use a different speaker")
+	ttsHistoryRegenerateCmd.Flags().Int("style-id", 0, "Use a different style ID (0 to 31)")
+	ttsHistoryRegenerateCmd.Flags().String("style-name", "", "Use a different style name")
+	ttsHistoryRegenerateCmd.Flags().Float64("rate", 0, "Speaking rate (0.5 to 2.0)")
+	ttsHistoryRegenerateCmd.Flags().Float64("pitch", 0, "Pitch adjustment (-1.0 to 1.0)")
+	ttsHistoryRegenerateCmd.Flags().Float64("volume", 0, "Audio volume (0.0 to 2.0)")
+	ttsHistoryRegenerateCmd.Flags().Float64("emotional-intensity", 0, "Emotional intensity (0.0 to 2.0)")
+	ttsHistoryRegenerateCmd.Flags().Float64("tempo-dynamics", 0, "Tempo dynamics (0.0 to 2.0)")
+	ttsHistoryRegenerateCmd.Flags().String("format", "", "Output format: wav, flac, mp3, aac, opus")
+	ttsHistoryRegenerateCmd.Flags().Bool("compare", false, "Play the original and the regenerated audio back to back")
+	ttsHistoryRegenerateCmd.Flags().Duration("gap", defaultCompareGap, "Pause between the two versions (with --compare)")
+
+	ttsHistoryCompareCmd.Flags().Duration("gap", defaultCompareGap, "Pause between the two versions")
+
+	ttsHistoryCmd.AddCommand(ttsHistoryRegenerateCmd)
+	ttsHistoryCmd.AddCommand(ttsHistoryCompareCmd)
+}
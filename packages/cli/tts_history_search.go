@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var ttsHistorySearchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Search TTS history by text",
+	Long: `Search TTS history records by text and list them best match first.
+
+Matching ignores case, full-width/half-width differences and katakana/hiragana
+differences. Separate terms with spaces to require all of them. With --fuzzy,
+records that only partially match a term (typos, missing characters) are
+included and ranked by character n-gram overlap.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		limit, _ := cmd.Flags().GetInt("limit")
+		offset, _ := cmd.Flags().GetInt("offset")
+		fuzzy, _ := cmd.Flags().GetBool("fuzzy")
+		minScore, _ := cmd.Flags().GetFloat64("min-score")
+
+		builder := aivisClient.NewTTSHistoryQuery(strings.Join(args, " ")).
+			WithLimit(limit).
+			WithOffset(offset).
+			WithFuzzy(fuzzy).
+			WithMinScore(minScore)
+
+		if v, _ := cmd.Flags().GetString("model-uuid"); v != "" {
+			builder = builder.WithModelUUID(v)
+		}
+		if v, _ := cmd.Flags().GetString("speaker-uuid"); v != "" {
+			builder = builder.WithSpeakerUUID(v)
+		}
+		if cmd.Flags().Changed("style-id") {
+			v, _ := cmd.Flags().GetInt("style-id")
+			builder = builder.WithStyleID(v)
+		}
+		if v, _ := cmd.Flags().GetString("style-name"); v != "" {
+			builder = builder.WithStyleName(v)
+		}
+		if v, _ := cmd.Flags().GetString("file-format"); v != "" {
+			builder = builder.WithFileFormat(v)
+		}
+
+		query := builder.Build()
+		if cmd.Flags().Changed("min-credits") {
+			v, _ := cmd.Flags().GetFloat64("min-credits")
+			query.MinCredits = &v
+		}
+		if cmd.Flags().Changed("max-credits") {
+			v, _ := cmd.Flags().GetFloat64("max-credits")
+			query.MaxCredits = &v
+		}
+		if cmd.Flags().Changed("min-size-kb") {
+			v, _ := cmd.Flags().GetInt64("min-size-kb")
+			v *= 1024
+			query.MinFileSize = &v
+		}
+		if cmd.Flags().Changed("max-size-kb") {
+			v, _ := cmd.Flags().GetInt64("max-size-kb")
+			v *= 1024
+			query.MaxFileSize = &v
+		}
+
+		response, err := aivisClient.SearchTTSHistory(context.Background(), query)
+		if err != nil {
+			return fmt.Errorf("failed to search TTS history: %v", err)
+		}
+
+		if len(response.Results) == 0 {
+			fmt.Println("No matching TTS history records found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tScore\tText\tFormat\tSize\tCreated")
+		for _, result := range response.Results {
+			history := result.History
+			fmt.Fprintf(w, "%d\t%.2f\t%s\t%s\t%s\t%s\n",
+				history.ID, result.Score, truncateText(history.Text, 30), history.FileFormat,
+				formatFileSize(history.FileSizeBytes), history.CreatedAt.Format("01/02 15:04"))
+		}
+		w.Flush()
+
+		fmt.Printf("\nShowing %d-%d of %d matches",
+			response.Offset+1,
+			response.Offset+len(response.Results),
+			response.Total)
+		if response.HasMore {
+			fmt.Printf(" (use --offset %d to see more)", response.Offset+response.Limit)
+		}
+		fmt.Println()
+
+		return nil
+	},
+}
+
+// truncateText shortens s to at most max characters, counting runes so
+// Japanese text is not cut mid-character
+func truncateText(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-3]) + "..."
+}
+
+func init() {
+	ttsHistorySearchCmd.Flags().Int("limit", 10, "Maximum number of results to display")
+	ttsHistorySearchCmd.Flags().Int("offset", 0, "Number of results to skip")
+	ttsHistorySearchCmd.Flags().Bool("fuzzy", false, "Include partial matches (typos, missing characters)")
+	ttsHistorySearchCmd.Flags().Float64("min-score", 0, "Minimum relevance score between 0 and 1 (fuzzy default: 0.5)")
+	ttsHistorySearchCmd.Flags().String("model-uuid", "", "Filter by model UUID")
+	ttsHistorySearchCmd.Flags().String("speaker-uuid", "", "Filter by speaker UUID")
+	ttsHistorySearchCmd.Flags().Int("style-id", 0, "Filter by style ID")
+	ttsHistorySearchCmd.Flags().String("style-name", "", "Filter by style name")
+	ttsHistorySearchCmd.Flags().String("file-format", "", "Filter by audio format (wav, mp3, flac, aac, opus)")
+	ttsHistorySearchCmd.Flags().Float64("min-credits", 0, "Only records that used at least this many credits")
+	ttsHistorySearchCmd.Flags().Float64("max-credits", 0, "Only records that used at most this many credits")
+	ttsHistorySearchCmd.Flags().Int64("min-size-kb", 0, "Only audio files of at least this size in KB")
+	ttsHistorySearchCmd.Flags().Int64("max-size-kb", 0, "Only audio files of at most this size in KB")
+
+	ttsHistoryCmd.AddCommand(ttsHistorySearchCmd)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/spf13/cobra"
+)
+
+var ttsScriptCmd = &cobra.Command{
+	Use:   "script <file> [output-file]",
+	Short: "Render a multi-speaker script into a single audio file",
+	Long: `Render a dialogue script into a single WAV or FLAC file, plus a JSON manifest
+with the timing, request and history ID of every line.
+
+Script format (one entry per line, # starts a comment):
+
+  @voice alice model=<model-uuid> speaker=<speaker-uuid> style=0
+  @voice bob model=<model-uuid> style=Happy rate=1.1
+  [alice] おはよう！
+  [bob pitch=0.1 intensity=1.2] おはよう。今日もいい天気だね。
+  @pause 1.5s
+  [alice] そうだね。
+
+@voice defines an alias; an alias the script does not define refers to the
+voice profile of that name (see 'config profile'). Lines are spoken as
+[alias] text, with optional per-line overrides inside the brackets. Parameters: model, speaker, style
+(ID or name), rate, intensity, tempo, pitch, volume. @pause replaces the gap
+between two lines with the given silence (e.g. 800ms, 1.5s or 2).
+
+Each line is saved to history under a common script session; list it with
+'tts history list --session <session>'.`,
+	Example: `  aivis-cloud-cli tts script dialogue.txt dialogue.wav
+  aivis-cloud-cli tts script dialogue.txt --format flac --gap 0.5 --manifest lines.json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		scriptPath := args[0]
+		var source io.Reader = os.Stdin
+		if scriptPath != "-" {
+			file, err := os.Open(scriptPath)
+			if err != nil {
+				return fmt.Errorf("failed to open script: %v", err)
+			}
+			defer file.Close()
+			source = file
+		}
+		script, err := aivisClient.ParseScript(source)
+		if err != nil {
+			return fmt.Errorf("invalid script: %v", err)
+		}
+
+		format, _ := cmd.Flags().GetString("format")
+		options := ttsDomain.DefaultScriptOptions()
+		switch format {
+		case "wav":
+			options.OutputFormat = ttsDomain.OutputFormatWAV
+		case "flac":
+			options.OutputFormat = ttsDomain.OutputFormatFLAC
+		default:
+			return fmt.Errorf("invalid --format %q (scripts support only wav and flac)", format)
+		}
+		if gap, _ := cmd.Flags().GetFloat64("gap"); gap >= 0 {
+			options.Gap = time.Duration(gap * float64(time.Second))
+		}
+		if concurrency, _ := cmd.Flags().GetInt("concurrency"); concurrency > 0 {
+			options.Concurrency = concurrency
+		}
+		options.ResolveVoice = func(alias string) (*ttsDomain.TTSRequestOverrides, error) {
+			profiles, err := loadProfiles()
+			if err != nil {
+				return nil, err
+			}
+			if profile, ok := profiles[strings.ToLower(alias)]; ok {
+				return profile.Overrides(), nil
+			}
+			return nil, nil
+		}
+		options.OnProgress = func(completed, total int) {
+			fmt.Fprintf(os.Stderr, "Synthesized line %d/%d\n", completed, total)
+		}
+
+		outputFile, _ := cmd.Flags().GetString("output")
+		if len(args) > 1 {
+			outputFile = args[1]
+		}
+		if outputFile == "" {
+			base := "script"
+			if scriptPath != "-" {
+				base = strings.TrimSuffix(filepath.Base(scriptPath), filepath.Ext(scriptPath))
+			}
+			outputFile = base + ttsDomain.GetFileExtensionFromFormat(options.OutputFormat)
+		}
+		manifestFile, _ := cmd.Flags().GetString("manifest")
+		if manifestFile == "" {
+			manifestFile = strings.TrimSuffix(outputFile, filepath.Ext(outputFile)) + ".json"
+		}
+
+		ctx := withHistoryLabels(context.Background(), cmd)
+		var result *ttsDomain.ScriptResult
+		if saveHistory, _ := cmd.Flags().GetBool("save-history"); saveHistory {
+			result, err = aivisClient.RenderScriptToFile(ctx, script, options, outputFile)
+		} else {
+			result, err = renderScriptWithoutHistory(ctx, script, options, outputFile)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to render script: %v", err)
+		}
+
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode manifest: %v", err)
+		}
+		if err := os.WriteFile(manifestFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write manifest: %v", err)
+		}
+
+		fmt.Printf("Audio saved to: %s\n", outputFile)
+		fmt.Printf("Manifest saved to: %s\n", manifestFile)
+		fmt.Printf("Lines: %d, Duration: %s\n", len(result.Lines), result.Duration.Round(time.Millisecond))
+		if result.Session != "" {
+			fmt.Printf("History script session: %s\n", result.Session)
+		}
+		return nil
+	},
+}
+
+func renderScriptWithoutHistory(ctx context.Context, script *ttsDomain.Script, options *ttsDomain.ScriptOptions, outputFile string) (*ttsDomain.ScriptResult, error) {
+	file, err := os.Create(outputFile)
+	if err != nil {
+		return nil, err
+	}
+	result, err := aivisClient.RenderScript(ctx, script, options, file)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(outputFile)
+		return nil, err
+	}
+	return result, nil
+}
+
+func init() {
+	ttsScriptCmd.Flags().String("output", "", "Output file path (default: script name with the format's extension)")
+	ttsScriptCmd.Flags().String("format", "wav", "Output format: wav, flac")
+	ttsScriptCmd.Flags().Float64("gap", 0.3, "Silence between lines in seconds, unless a @pause is given")
+	ttsScriptCmd.Flags().Int("concurrency", 3, "Number of lines synthesized in parallel")
+	ttsScriptCmd.Flags().String("manifest", "", "Per-line JSON manifest path (default: output path with .json)")
+	ttsScriptCmd.Flags().Bool("save-history", true, "Record each line in TTS history under a script session")
+	addHistoryLabelFlags(ttsScriptCmd)
+
+	ttsCmd.AddCommand(ttsScriptCmd)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/ssml"
+	"github.com/spf13/cobra"
+)
+
+var ttsSSMLCmd = &cobra.Command{
+	Use:   "ssml",
+	Short: "SSML authoring tools",
+	Long: `SSML authoring tools.
+
+With --ssml, text may use these elements: <speak> (optional root), <break time="500ms"/>
+or <break strength="weak"/>, <prosody rate="..." pitch="..." volume="...">,
+<emphasis level="...">, <sub alias="..."> and <phoneme ph="...">. Requests with SSML
+enabled are checked for malformed markup before they are sent; anything outside
+this subset is left for the API to accept or reject.`,
+	// Validation runs locally and needs no API client
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return nil
+	},
+}
+
+var ttsSSMLValidateCmd = &cobra.Command{
+	Use:   "validate <file>",
+	Short: "Check SSML markup without synthesizing it",
+	Long:  "Check that a file (- for stdin) contains well-formed SSML, reporting each problem with its line and column. Elements, attributes and values outside the documented subset are reported as warnings, which fail the check only with --strict.",
+	Example: `  aivis-cloud-cli tts ssml validate speech.ssml
+  echo '<prosody rate="fast">はやい</prosody>' | aivis-cloud-cli tts ssml validate -`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+		var data []byte
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read SSML: %v", err)
+		}
+
+		strict, _ := cmd.Flags().GetBool("strict")
+		problems := ssml.Check(string(data))
+		failed := 0
+		for _, e := range problems {
+			severity := "error"
+			if e.Warning {
+				severity = "warning"
+			}
+			if !e.Warning || strict {
+				failed++
+			}
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s: %s\n", path, e.Line, e.Column, severity, e.Message)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%s: %d SSML problem(s) found", path, failed)
+		}
+		if len(problems) > 0 {
+			fmt.Printf("%s: well-formed SSML with %d warning(s)\n", path, len(problems))
+			return nil
+		}
+		fmt.Printf("%s: valid SSML\n", path)
+		return nil
+	},
+}
+
+func init() {
+	ttsSSMLValidateCmd.Flags().Bool("strict", false, "Treat markup outside the documented subset as an error")
+
+	ttsSSMLCmd.AddCommand(ttsSSMLValidateCmd)
+	ttsCmd.AddCommand(ttsSSMLCmd)
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
+
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	usersDomain "github.com/kajidog/aivis-cloud-cli/client/users/domain"
 )
 
 var usersCmd = &cobra.Command{
@@ -17,6 +20,11 @@ var usersCmd = &cobra.Command{
 var getUserMeCmd = &cobra.Command{
 	Use:   "me",
 	Short: "Get your account information",
+	Long: `Get your account information.
+
+With --credits, show the credit balance of the account next to the remaining
+credits last reported by a synthesis response, which is what low-credit alerts
+(credit_alert_thresholds) are raised from.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		client := aivisClient
 
@@ -27,6 +35,14 @@ var getUserMeCmd = &cobra.Command{
 			return
 		}
 
+		if credits, _ := cmd.Flags().GetBool("credits"); credits {
+			outputFormat, _ := cmd.Flags().GetString("output")
+			if err := printCredits(ctx, userProfile.CreditBalance, outputFormat); err != nil {
+				fmt.Printf("Error showing credits: %v\n", err)
+			}
+			return
+		}
+
 		output, _ := json.MarshalIndent(userProfile, "", "  ")
 		fmt.Println(string(output))
 	},
@@ -52,8 +68,56 @@ var getUserByHandleCmd = &cobra.Command{
 	},
 }
 
+// creditsView combines the account balance with the last observed header value
+type creditsView struct {
+	CreditBalance *usersDomain.CreditBalance       `json:"credit_balance,omitempty"`
+	LastObserved  *paymentDomain.CreditObservation `json:"last_observed,omitempty"`
+}
+
+// printCredits shows the account credit balance and the remaining credits
+// last reported by a synthesis response
+func printCredits(ctx context.Context, balance *usersDomain.CreditBalance, outputFormat string) error {
+	view := creditsView{CreditBalance: balance}
+	last, err := aivisClient.GetLastObservedCredits(ctx)
+	if err != nil {
+		return err
+	}
+	view.LastObserved = last
+
+	switch outputFormat {
+	case "json":
+		return printJSON(view)
+	case "table", "":
+	default:
+		return fmt.Errorf("unsupported output format: %s. Supported formats: json, table", outputFormat)
+	}
+
+	fmt.Printf("Credit balance: %s\n", describeCreditBalance(balance))
+	if last == nil {
+		fmt.Println("Last observed remaining: none yet (reported with each synthesis)")
+		return nil
+	}
+	fmt.Printf("Last observed remaining: %s (%s)\n", formatCredits(last.Remaining), last.ObservedAt.Local().Format("2006-01-02 15:04:05"))
+	return nil
+}
+
+// describeCreditBalance formats a balance with its breakdown, when reported
+func describeCreditBalance(balance *usersDomain.CreditBalance) string {
+	if balance == nil {
+		return "not reported"
+	}
+	if !balance.HasBreakdown() {
+		return formatCredits(balance.Total)
+	}
+	return fmt.Sprintf("%s (paid %s, free %s, bonus %s)", formatCredits(balance.Total),
+		formatCredits(balance.Paid), formatCredits(balance.Free), formatCredits(balance.Bonus))
+}
+
 func init() {
 	// Command registration is handled in main.go
 	usersCmd.AddCommand(getUserMeCmd)
 	usersCmd.AddCommand(getUserByHandleCmd)
+
+	getUserMeCmd.Flags().Bool("credits", false, "Show the credit balance and the remaining credits last reported by a synthesis")
+	getUserMeCmd.Flags().String("output", "table", "Output format with --credits (table, json)")
 }
\ No newline at end of file
@@ -0,0 +1,153 @@
+package audio
+
+import (
+	"io"
+	"time"
+)
+
+// Envelope selects where playback starts and how the audio fades in and out
+type Envelope struct {
+	StartOffset time.Duration `json:"start_offset,omitempty"`
+	FadeIn      time.Duration `json:"fade_in,omitempty"`
+	FadeOut     time.Duration `json:"fade_out,omitempty"`
+}
+
+// IsZero reports whether the envelope leaves the audio unchanged
+func (e Envelope) IsZero() bool {
+	return e.StartOffset <= 0 && e.FadeIn <= 0 && e.FadeOut <= 0
+}
+
+// envelopeStream applies an Envelope to another stream
+type envelopeStream struct {
+	src    Stream
+	format Format
+
+	skip    int64 // bytes still to discard for the start offset
+	fadeIn  int64 // frames
+	fadeOut int64 // frames
+	emitted int64 // frames returned so far
+
+	held []byte // frames held back until it is known whether they are the last ones
+	out  []byte // processed bytes not yet returned
+	err  error  // source error, reported once held frames are out
+}
+
+// ApplyEnvelope returns s without its first e.StartOffset, with linear fades
+// over the first e.FadeIn and the last e.FadeOut. The end of the stream need
+// not be known in advance: the last e.FadeOut of audio is held back until the
+// source reports EOF.
+func ApplyEnvelope(s Stream, e Envelope) Stream {
+	if e.IsZero() {
+		return s
+	}
+	format := s.Format()
+	return &envelopeStream{
+		src:     s,
+		format:  format,
+		skip:    durationToFrames(e.StartOffset, format.SampleRate) * int64(format.BlockAlign()),
+		fadeIn:  durationToFrames(e.FadeIn, format.SampleRate),
+		fadeOut: durationToFrames(e.FadeOut, format.SampleRate),
+	}
+}
+
+func (s *envelopeStream) Format() Format {
+	return s.format
+}
+
+func (s *envelopeStream) TotalFrames() int64 {
+	total := s.src.TotalFrames()
+	if total < 0 {
+		return -1
+	}
+	return max(total-s.skip/int64(s.format.BlockAlign()), 0)
+}
+
+func (s *envelopeStream) Read(p []byte) (int, error) {
+	for len(s.out) == 0 {
+		if s.err != nil {
+			return 0, s.err
+		}
+		s.fill()
+	}
+	n := copy(p, s.out)
+	s.out = s.out[n:]
+	return n, nil
+}
+
+// fill reads from the source and moves every frame that cannot be among the
+// last fadeOut frames to s.out
+func (s *envelopeStream) fill() {
+	if s.skip > 0 {
+		n, err := io.CopyN(io.Discard, s.src, s.skip)
+		s.skip -= n
+		if err != nil {
+			s.err = err
+			return
+		}
+	}
+
+	frameSize := int64(s.format.BlockAlign())
+	chunk := make([]byte, 4096*frameSize)
+	n, err := io.ReadAtLeast(s.src, chunk, int(frameSize))
+	s.held = append(s.held, chunk[:n]...)
+
+	if err != nil {
+		// Everything held is the end of the stream
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		s.err = err
+		whole := int64(len(s.held)) / frameSize * frameSize
+		s.emit(s.held[:whole], true)
+		s.held = nil
+		return
+	}
+
+	ready := int64(len(s.held)) - s.fadeOut*frameSize
+	ready -= ready % frameSize
+	if ready > 0 {
+		s.emit(s.held[:ready], false)
+		s.held = append(s.held[:0], s.held[ready:]...)
+	}
+}
+
+// emit applies the fades to frames and queues them for Read. When last is
+// set, frames are the final frames of the stream.
+func (s *envelopeStream) emit(frames []byte, last bool) {
+	frameSize := s.format.BlockAlign()
+	count := int64(len(frames) / frameSize)
+	out := make([]byte, len(frames))
+	copy(out, frames)
+
+	for i := int64(0); i < count; i++ {
+		gain := 1.0
+		if pos := s.emitted + i; pos < s.fadeIn {
+			gain = float64(pos) / float64(s.fadeIn)
+		}
+		if last && s.fadeOut > 0 {
+			if remaining := count - i; remaining <= s.fadeOut {
+				gain *= float64(remaining-1) / float64(s.fadeOut)
+			}
+		}
+		if gain != 1 {
+			scaleFrame(out[i*int64(frameSize):(i+1)*int64(frameSize)], s.format.BitsPerSample/8, gain)
+		}
+	}
+	s.emitted += count
+	s.out = out
+}
+
+// scaleFrame multiplies the little-endian samples in frame by gain
+func scaleFrame(frame []byte, bytesPerSample int, gain float64) {
+	for i := 0; i+bytesPerSample <= len(frame); i += bytesPerSample {
+		if bytesPerSample == 2 {
+			v := int16(uint16(frame[i]) | uint16(frame[i+1])<<8)
+			scaled := int16(float64(v) * gain)
+			frame[i], frame[i+1] = byte(scaled), byte(scaled>>8)
+			continue
+		}
+		v := int32(frame[i]) | int32(frame[i+1])<<8 | int32(int8(frame[i+2]))<<16
+		scaled := int32(float64(v) * gain)
+		frame[i], frame[i+1], frame[i+2] = byte(scaled), byte(scaled>>8), byte(scaled>>16)
+	}
+}
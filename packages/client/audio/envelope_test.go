@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestApplyEnvelope(t *testing.T) {
+	format := Format{SampleRate: 1000, Channels: 2, BitsPerSample: 16}
+	samples := make([]int32, 2*1000)
+	for i := range samples {
+		samples[i] = 1000
+	}
+	buf := &Buffer{Format: format, Data: encodePCM(format, samples)}
+	envelope := Envelope{StartOffset: 100 * time.Millisecond, FadeIn: 100 * time.Millisecond, FadeOut: 200 * time.Millisecond}
+
+	var wav, streamed bytes.Buffer
+	if err := buf.WriteWAV(&wav); err != nil {
+		t.Fatal(err)
+	}
+	WriteStreamingWAVHeader(&streamed, format)
+	streamed.Write(buf.Data)
+
+	for name, data := range map[string][]byte{"known_length": wav.Bytes(), "streamed": streamed.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewStream(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			shaped := ApplyEnvelope(s, envelope)
+			if name == "known_length" && shaped.TotalFrames() != 900 {
+				t.Errorf("TotalFrames() = %d, want 900", shaped.TotalFrames())
+			}
+			pcm, err := io.ReadAll(shaped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pcm) != 900*4 {
+				t.Fatalf("got %d frames, want 900", len(pcm)/4)
+			}
+			frame := func(i int) int16 { return int16(binary.LittleEndian.Uint16(pcm[4*i:])) }
+			for i, want := range map[int]int16{0: 0, 50: 500, 100: 1000, 500: 1000, 700: 995, 800: 495, 899: 0} {
+				if got := frame(i); got != want {
+					t.Errorf("frame %d = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplyEnvelopeEdgeCases(t *testing.T) {
+	format := Format{SampleRate: 1000, Channels: 1, BitsPerSample: 24}
+	buf := &Buffer{Format: format, Data: encodePCM(format, []int32{-40000, 40000, -40000, 40000})}
+	var wav bytes.Buffer
+	buf.WriteWAV(&wav)
+
+	// A zero envelope returns the stream unchanged
+	s, _ := NewStream(bytes.NewReader(wav.Bytes()))
+	if ApplyEnvelope(s, Envelope{}) != s {
+		t.Error("zero envelope must not wrap the stream")
+	}
+
+	// Starting past the end yields no audio
+	s, _ = NewStream(bytes.NewReader(wav.Bytes()))
+	if pcm, err := io.ReadAll(ApplyEnvelope(s, Envelope{StartOffset: time.Second})); err != nil || len(pcm) != 0 {
+		t.Errorf("offset past end = %d bytes, %v", len(pcm), err)
+	}
+
+	// A fade longer than the stream still ends in silence, keeping signs
+	s, _ = NewStream(bytes.NewReader(wav.Bytes()))
+	pcm, err := io.ReadAll(ApplyEnvelope(s, Envelope{FadeOut: 8 * time.Millisecond}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := encodePCM(format, []int32{-15000, 10000, -5000, 0})
+	if !bytes.Equal(pcm, want) {
+		t.Errorf("faded = %v, want %v", pcm, want)
+	}
+}
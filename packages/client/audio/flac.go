@@ -0,0 +1,279 @@
+package audio
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"io"
+)
+
+// flacBlockSize is the number of frames per FLAC frame
+const flacBlockSize = 4096
+
+// WriteFLAC encodes the buffer as a FLAC stream.
+// Each subframe uses the cheapest of the fixed linear predictors (orders 0-4)
+// with Rice-coded residuals, falling back to verbatim samples.
+func (b *Buffer) WriteFLAC(w io.Writer) error {
+	format := b.Format
+	if err := format.Validate(); err != nil {
+		return err
+	}
+
+	samples := b.samples()
+	totalFrames := b.Frames()
+
+	var out bytes.Buffer
+	out.WriteString("fLaC")
+	writeFLACStreamInfo(&out, format, uint64(totalFrames), md5.Sum(b.Data[:totalFrames*format.BlockAlign()]))
+
+	channel := make([]int32, flacBlockSize)
+	frameNumber := uint64(0)
+	for start := 0; start < totalFrames; start += flacBlockSize {
+		blockSize := flacBlockSize
+		if start+blockSize > totalFrames {
+			blockSize = totalFrames - start
+		}
+
+		bw := &bitWriter{}
+
+		// Frame header: sync code, fixed block size, sizes from STREAMINFO
+		bw.write(0x3FFE, 14)
+		bw.write(0, 1) // reserved
+		bw.write(0, 1) // fixed blocking strategy
+		bw.write(0x7, 4)
+		bw.write(0x0, 4)
+		bw.write(uint64(format.Channels-1), 4) // independent channels
+		bw.write(0x0, 3)
+		bw.write(0, 1) // reserved
+		writeFLACUTF8(bw, frameNumber)
+		bw.write(uint64(blockSize-1), 16)
+		bw.write(uint64(crc8(bw.bytes())), 8)
+
+		for ch := 0; ch < format.Channels; ch++ {
+			for i := 0; i < blockSize; i++ {
+				channel[i] = samples[(start+i)*format.Channels+ch]
+			}
+			writeFLACSubframe(bw, channel[:blockSize], format.BitsPerSample)
+		}
+
+		bw.align()
+		frame := bw.bytes()
+		crc := crc16(frame)
+		out.Write(frame)
+		out.WriteByte(byte(crc >> 8))
+		out.WriteByte(byte(crc))
+
+		frameNumber++
+	}
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// samples decodes the interleaved PCM data into signed integers
+func (b *Buffer) samples() []int32 {
+	bytesPerSample := b.Format.BitsPerSample / 8
+	count := len(b.Data) / bytesPerSample
+	samples := make([]int32, count)
+	for i := 0; i < count; i++ {
+		p := b.Data[i*bytesPerSample:]
+		switch bytesPerSample {
+		case 2:
+			samples[i] = int32(int16(binary.LittleEndian.Uint16(p)))
+		case 3:
+			v := int32(p[0]) | int32(p[1])<<8 | int32(p[2])<<16
+			samples[i] = v << 8 >> 8 // sign extend
+		}
+	}
+	return samples
+}
+
+func writeFLACStreamInfo(out *bytes.Buffer, format Format, totalFrames uint64, sum [16]byte) {
+	bw := &bitWriter{}
+	bw.write(1, 1)   // last metadata block
+	bw.write(0, 7)   // STREAMINFO
+	bw.write(34, 24) // block length
+	bw.write(flacBlockSize, 16)
+	bw.write(flacBlockSize, 16)
+	bw.write(0, 24) // minimum frame size unknown
+	bw.write(0, 24) // maximum frame size unknown
+	bw.write(uint64(format.SampleRate), 20)
+	bw.write(uint64(format.Channels-1), 3)
+	bw.write(uint64(format.BitsPerSample-1), 5)
+	bw.write(totalFrames, 36)
+	out.Write(bw.bytes())
+	out.Write(sum[:])
+}
+
+// writeFLACUTF8 writes the frame number using FLAC's extended UTF-8 coding
+func writeFLACUTF8(bw *bitWriter, v uint64) {
+	if v < 0x80 {
+		bw.write(v, 8)
+		return
+	}
+	n := 2
+	for v >= 1<<(5*n+1) {
+		n++
+	}
+	lead := uint64(0xFF<<(8-n)) & 0xFF
+	bw.write(lead|(v>>(6*(n-1))), 8)
+	for i := n - 2; i >= 0; i-- {
+		bw.write(0x80|((v>>(6*i))&0x3F), 8)
+	}
+}
+
+// writeFLACSubframe chooses the smallest encoding for one channel of a block
+func writeFLACSubframe(bw *bitWriter, samples []int32, bps int) {
+	verbatimBits := len(samples) * bps
+
+	bestOrder, bestParam, bestBits := -1, 0, verbatimBits
+	residuals := make([]int32, len(samples))
+	for order := 0; order <= 4 && order < len(samples); order++ {
+		fixedResiduals(samples, order, residuals)
+		param, bits := riceParameter(residuals[order:])
+		if bits < 0 {
+			continue
+		}
+		bits += order*bps + 2 + 4 + 4 // warm-up, coding method, partition order, parameter
+		if bits < bestBits {
+			bestOrder, bestParam, bestBits = order, param, bits
+		}
+	}
+
+	bw.write(0, 1) // zero padding
+	if bestOrder < 0 {
+		bw.write(0x01, 6) // VERBATIM
+		bw.write(0, 1)    // no wasted bits
+		for _, s := range samples {
+			bw.writeSigned(s, bps)
+		}
+		return
+	}
+
+	bw.write(uint64(0x08|bestOrder), 6) // FIXED
+	bw.write(0, 1)
+	for i := 0; i < bestOrder; i++ {
+		bw.writeSigned(samples[i], bps)
+	}
+
+	fixedResiduals(samples, bestOrder, residuals)
+	bw.write(0, 2) // Rice coding with 4-bit parameters
+	bw.write(0, 4) // single partition
+	bw.write(uint64(bestParam), 4)
+	for _, r := range residuals[bestOrder:] {
+		u := zigzag(r)
+		q := u >> bestParam
+		for ; q >= 32; q -= 32 {
+			bw.write(0, 32)
+		}
+		bw.write(1, int(q)+1)
+		bw.write(uint64(u)&(1<<bestParam-1), bestParam)
+	}
+}
+
+// fixedResiduals computes the residuals of FLAC's fixed predictor of the given order
+func fixedResiduals(s []int32, order int, out []int32) {
+	for i := order; i < len(s); i++ {
+		switch order {
+		case 0:
+			out[i] = s[i]
+		case 1:
+			out[i] = s[i] - s[i-1]
+		case 2:
+			out[i] = s[i] - 2*s[i-1] + s[i-2]
+		case 3:
+			out[i] = s[i] - 3*s[i-1] + 3*s[i-2] - s[i-3]
+		case 4:
+			out[i] = s[i] - 4*s[i-1] + 6*s[i-2] - 4*s[i-3] + s[i-4]
+		}
+	}
+}
+
+// riceParameter returns the cheapest Rice parameter and the resulting bit count,
+// or -1 bits when no 4-bit parameter fits
+func riceParameter(residuals []int32) (int, int) {
+	bestParam, bestBits := 0, -1
+	for k := 0; k < 15; k++ {
+		bits := 0
+		for _, r := range residuals {
+			bits += int(zigzag(r)>>k) + 1 + k
+		}
+		if bestBits < 0 || bits < bestBits {
+			bestParam, bestBits = k, bits
+		}
+	}
+	return bestParam, bestBits
+}
+
+func zigzag(v int32) uint32 {
+	return uint32(v<<1) ^ uint32(v>>31)
+}
+
+// bitWriter accumulates a big-endian bit stream
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits int
+}
+
+func (w *bitWriter) write(v uint64, n int) {
+	for n > 0 {
+		take := n
+		if take > 32 {
+			take = 32
+		}
+		n -= take
+		w.acc = w.acc<<take | (v>>n)&(1<<take-1)
+		w.nbits += take
+		for w.nbits >= 8 {
+			w.nbits -= 8
+			w.buf = append(w.buf, byte(w.acc>>w.nbits))
+		}
+	}
+}
+
+func (w *bitWriter) writeSigned(v int32, n int) {
+	w.write(uint64(uint32(v))&(1<<n-1), n)
+}
+
+// align pads the stream with zero bits up to the next byte boundary
+func (w *bitWriter) align() {
+	if w.nbits > 0 {
+		w.write(0, 8-w.nbits)
+	}
+}
+
+// bytes returns the completed bytes written so far
+func (w *bitWriter) bytes() []byte {
+	return w.buf
+}
+
+func crc8(data []byte) uint8 {
+	var crc uint8
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
@@ -0,0 +1,624 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFLAC is returned when the input is not a FLAC stream
+var ErrNotFLAC = errors.New("not a FLAC file")
+
+// flacStream decodes FLAC frames on demand
+type flacStream struct {
+	br     *flacBitReader
+	format Format
+	info   flacStreamInfo
+
+	pcm  []byte // decoded samples of the current frame not yet read
+	done bool
+}
+
+// flacStreamInfo holds the STREAMINFO fields the decoder needs
+type flacStreamInfo struct {
+	sampleRate    int
+	channels      int
+	bitsPerSample int
+	totalFrames   uint64
+}
+
+// DecodeFLAC reads a FLAC stream into memory
+func DecodeFLAC(r io.Reader) (*Buffer, error) {
+	s, err := NewFLACStream(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Buffer{Format: s.Format(), Data: data}, nil
+}
+
+// NewFLACStream parses the FLAC metadata from r and returns a stream that
+// decodes audio frames as they are read. A leading ID3v2 tag is skipped.
+func NewFLACStream(r io.Reader) (Stream, error) {
+	br := &flacBitReader{r: bufio.NewReader(r)}
+
+	var marker [4]byte
+	if err := br.readBytes(marker[:]); err != nil {
+		return nil, ErrNotFLAC
+	}
+	if string(marker[:3]) == "ID3" {
+		var header [6]byte
+		if err := br.readBytes(header[:]); err != nil {
+			return nil, ErrNotFLAC
+		}
+		// Syncsafe size: 7 bits per byte
+		size := int64(header[2])<<21 | int64(header[3])<<14 | int64(header[4])<<7 | int64(header[5])
+		if err := br.skip(size); err != nil {
+			return nil, ErrNotFLAC
+		}
+		if err := br.readBytes(marker[:]); err != nil {
+			return nil, ErrNotFLAC
+		}
+	}
+	if string(marker[:]) != "fLaC" {
+		return nil, ErrNotFLAC
+	}
+
+	s := &flacStream{br: br}
+	haveInfo := false
+	for last := false; !last; {
+		var header [4]byte
+		if err := br.readBytes(header[:]); err != nil {
+			return nil, fmt.Errorf("truncated FLAC metadata: %w", err)
+		}
+		last = header[0]&0x80 != 0
+		blockType := header[0] & 0x7F
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		if blockType != 0 {
+			if err := br.skip(length); err != nil {
+				return nil, fmt.Errorf("truncated FLAC metadata: %w", err)
+			}
+			continue
+		}
+		if length < 34 {
+			return nil, fmt.Errorf("invalid FLAC STREAMINFO block")
+		}
+		block := make([]byte, length)
+		if err := br.readBytes(block); err != nil {
+			return nil, fmt.Errorf("truncated FLAC metadata: %w", err)
+		}
+		packed := binary.BigEndian.Uint64(block[10:18])
+		s.info = flacStreamInfo{
+			sampleRate:    int(packed >> 44),
+			channels:      int(packed>>41&0x07) + 1,
+			bitsPerSample: int(packed>>36&0x1F) + 1,
+			totalFrames:   packed & (1<<36 - 1),
+		}
+		haveInfo = true
+	}
+	if !haveInfo {
+		return nil, fmt.Errorf("FLAC stream has no STREAMINFO block")
+	}
+
+	s.format = Format{
+		SampleRate:    s.info.sampleRate,
+		Channels:      s.info.channels,
+		BitsPerSample: s.info.bitsPerSample,
+	}
+	if err := s.format.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *flacStream) Format() Format {
+	return s.format
+}
+
+func (s *flacStream) TotalFrames() int64 {
+	if s.info.totalFrames == 0 {
+		return -1
+	}
+	return int64(s.info.totalFrames)
+}
+
+func (s *flacStream) Read(p []byte) (int, error) {
+	for len(s.pcm) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.decodeFrame(); err != nil {
+			if err == io.EOF {
+				s.done = true
+				continue
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, s.pcm)
+	s.pcm = s.pcm[n:]
+	return n, nil
+}
+
+// flacBlockSizes maps the frame header block size codes 1-5 and 8-15
+var flacBlockSizes = [16]int{0, 192, 576, 1152, 2304, 4608, 0, 0, 256, 512, 1024, 2048, 4096, 8192, 16384, 32768}
+
+// flacSampleRates maps the frame header sample rate codes 1-11
+var flacSampleRates = [12]int{0, 88200, 176400, 192000, 8000, 16000, 22050, 24000, 32000, 44100, 48000, 96000}
+
+// flacSampleSizes maps the frame header sample size codes; 0 means "from STREAMINFO"
+var flacSampleSizes = [8]int{0, 8, 12, 0, 16, 20, 24, 32}
+
+// Channel assignments other than independent channels
+const (
+	flacLeftSide  = 8
+	flacSideRight = 9
+	flacMidSide   = 10
+)
+
+// decodeFrame decodes the next audio frame into s.pcm, returning io.EOF at
+// the clean end of the stream
+func (s *flacStream) decodeFrame() error {
+	br := s.br
+	br.startFrame()
+
+	sync, err := br.read(14)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF && br.frameLen() == 0 {
+			return io.EOF
+		}
+		return err
+	}
+	if sync != 0x3FFE {
+		return fmt.Errorf("invalid FLAC frame sync")
+	}
+	if _, err := br.read(2); err != nil { // reserved, blocking strategy
+		return err
+	}
+	blockCode, _ := br.read(4)
+	rateCode, _ := br.read(4)
+	assignment, _ := br.read(4)
+	sizeCode, _ := br.read(3)
+	if _, err := br.read(1); err != nil {
+		return err
+	}
+	if err := br.skipUTF8(); err != nil {
+		return err
+	}
+
+	blockSize := flacBlockSizes[blockCode]
+	switch blockCode {
+	case 0:
+		return fmt.Errorf("invalid FLAC block size code")
+	case 6:
+		v, err := br.read(8)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	case 7:
+		v, err := br.read(16)
+		if err != nil {
+			return err
+		}
+		blockSize = int(v) + 1
+	}
+	switch rateCode {
+	case 12:
+		_, err = br.read(8)
+	case 13, 14:
+		_, err = br.read(16)
+	case 15:
+		return fmt.Errorf("invalid FLAC sample rate code")
+	}
+	if err != nil {
+		return err
+	}
+
+	bps := s.info.bitsPerSample
+	if sizeCode != 0 {
+		bps = flacSampleSizes[sizeCode]
+		if bps == 0 {
+			return fmt.Errorf("invalid FLAC sample size code")
+		}
+	}
+	if bps != s.info.bitsPerSample {
+		return fmt.Errorf("FLAC frame sample size %d differs from the stream's %d", bps, s.info.bitsPerSample)
+	}
+
+	channels := int(assignment) + 1
+	if assignment >= flacLeftSide {
+		if assignment > flacMidSide {
+			return fmt.Errorf("invalid FLAC channel assignment")
+		}
+		channels = 2
+	}
+	if channels != s.info.channels {
+		return fmt.Errorf("FLAC frame has %d channels, the stream %d", channels, s.info.channels)
+	}
+
+	headerCRC := crc8(br.frameBytes())
+	if v, err := br.read(8); err != nil {
+		return err
+	} else if uint8(v) != headerCRC {
+		return fmt.Errorf("FLAC frame header CRC mismatch")
+	}
+
+	samples := make([][]int32, channels)
+	for ch := range samples {
+		// The side channel needs one extra bit
+		subBPS := bps
+		if (assignment == flacLeftSide || assignment == flacMidSide) && ch == 1 ||
+			assignment == flacSideRight && ch == 0 {
+			subBPS++
+		}
+		samples[ch] = make([]int32, blockSize)
+		if err := br.decodeSubframe(samples[ch], subBPS); err != nil {
+			return err
+		}
+	}
+
+	br.align()
+	frameCRC := crc16(br.frameBytes())
+	if v, err := br.read(16); err != nil {
+		return err
+	} else if uint16(v) != frameCRC {
+		return fmt.Errorf("FLAC frame CRC mismatch")
+	}
+
+	switch assignment {
+	case flacLeftSide:
+		for i, side := range samples[1] {
+			samples[1][i] = samples[0][i] - side
+		}
+	case flacSideRight:
+		for i, side := range samples[0] {
+			samples[0][i] = side + samples[1][i]
+		}
+	case flacMidSide:
+		for i := range samples[0] {
+			mid, side := samples[0][i], samples[1][i]
+			mid = mid<<1 | side&1
+			samples[0][i] = (mid + side) >> 1
+			samples[1][i] = (mid - side) >> 1
+		}
+	}
+
+	bytesPerSample := bps / 8
+	out := make([]byte, 0, blockSize*channels*bytesPerSample)
+	for i := 0; i < blockSize; i++ {
+		for ch := range samples {
+			v := samples[ch][i]
+			out = append(out, byte(v), byte(v>>8))
+			if bytesPerSample == 3 {
+				out = append(out, byte(v>>16))
+			}
+		}
+	}
+	s.pcm = out
+	return nil
+}
+
+// decodeSubframe decodes one channel of a frame into out
+func (br *flacBitReader) decodeSubframe(out []int32, bps int) error {
+	header, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	if header&0x80 != 0 {
+		return fmt.Errorf("invalid FLAC subframe padding")
+	}
+	kind := int(header >> 1 & 0x3F)
+
+	wasted := 0
+	if header&1 != 0 {
+		n, err := br.readUnary()
+		if err != nil {
+			return err
+		}
+		wasted = int(n) + 1
+		bps -= wasted
+		if bps <= 0 {
+			return fmt.Errorf("invalid FLAC wasted bits")
+		}
+	}
+
+	switch {
+	case kind == 0: // CONSTANT
+		v, err := br.readSigned(bps)
+		if err != nil {
+			return err
+		}
+		for i := range out {
+			out[i] = v
+		}
+	case kind == 1: // VERBATIM
+		for i := range out {
+			if out[i], err = br.readSigned(bps); err != nil {
+				return err
+			}
+		}
+	case kind >= 8 && kind <= 12: // FIXED
+		order := kind - 8
+		if err := br.decodeFixed(out, bps, order); err != nil {
+			return err
+		}
+	case kind >= 32: // LPC
+		order := kind - 31
+		if err := br.decodeLPC(out, bps, order); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("reserved FLAC subframe type %d", kind)
+	}
+
+	if wasted > 0 {
+		for i := range out {
+			out[i] <<= uint(wasted)
+		}
+	}
+	return nil
+}
+
+// decodeFixed decodes a subframe using one of the fixed polynomial predictors
+func (br *flacBitReader) decodeFixed(out []int32, bps, order int) error {
+	if order > len(out) {
+		return fmt.Errorf("FLAC predictor order exceeds block size")
+	}
+	var err error
+	for i := 0; i < order; i++ {
+		if out[i], err = br.readSigned(bps); err != nil {
+			return err
+		}
+	}
+	if err := br.decodeResidual(out, order); err != nil {
+		return err
+	}
+	for i := order; i < len(out); i++ {
+		switch order {
+		case 1:
+			out[i] += out[i-1]
+		case 2:
+			out[i] += 2*out[i-1] - out[i-2]
+		case 3:
+			out[i] += 3*out[i-1] - 3*out[i-2] + out[i-3]
+		case 4:
+			out[i] += 4*out[i-1] - 6*out[i-2] + 4*out[i-3] - out[i-4]
+		}
+	}
+	return nil
+}
+
+// decodeLPC decodes a subframe using a quantized linear predictor
+func (br *flacBitReader) decodeLPC(out []int32, bps, order int) error {
+	if order > len(out) {
+		return fmt.Errorf("FLAC predictor order exceeds block size")
+	}
+	var err error
+	for i := 0; i < order; i++ {
+		if out[i], err = br.readSigned(bps); err != nil {
+			return err
+		}
+	}
+	precision, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	if precision == 0x0F {
+		return fmt.Errorf("invalid FLAC LPC coefficient precision")
+	}
+	shift, err := br.readSigned(5)
+	if err != nil {
+		return err
+	}
+	if shift < 0 {
+		return fmt.Errorf("negative FLAC LPC shift")
+	}
+	coefs := make([]int32, order)
+	for i := range coefs {
+		if coefs[i], err = br.readSigned(int(precision) + 1); err != nil {
+			return err
+		}
+	}
+	if err := br.decodeResidual(out, order); err != nil {
+		return err
+	}
+	for i := order; i < len(out); i++ {
+		var sum int64
+		for j, c := range coefs {
+			sum += int64(c) * int64(out[i-1-j])
+		}
+		out[i] += int32(sum >> uint(shift))
+	}
+	return nil
+}
+
+// decodeResidual reads the Rice coded residuals of a predicted subframe into
+// out[order:]
+func (br *flacBitReader) decodeResidual(out []int32, order int) error {
+	method, err := br.read(2)
+	if err != nil {
+		return err
+	}
+	paramBits, escape := 4, uint64(0x0F)
+	switch method {
+	case 0:
+	case 1:
+		paramBits, escape = 5, 0x1F
+	default:
+		return fmt.Errorf("reserved FLAC residual coding method")
+	}
+
+	partitionOrder, err := br.read(4)
+	if err != nil {
+		return err
+	}
+	partitions := 1 << partitionOrder
+	if len(out)%partitions != 0 || len(out)>>partitionOrder < order {
+		return fmt.Errorf("invalid FLAC residual partition order")
+	}
+	partitionSize := len(out) >> partitionOrder
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		end := (p + 1) * partitionSize
+		param, err := br.read(paramBits)
+		if err != nil {
+			return err
+		}
+		if param == escape {
+			n, err := br.read(5)
+			if err != nil {
+				return err
+			}
+			for ; i < end; i++ {
+				if n == 0 {
+					out[i] = 0
+				} else if out[i], err = br.readSigned(int(n)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		for ; i < end; i++ {
+			q, err := br.readUnary()
+			if err != nil {
+				return err
+			}
+			low, err := br.read(int(param))
+			if err != nil {
+				return err
+			}
+			u := uint32(q<<param | low)
+			out[i] = int32(u>>1) ^ -int32(u&1)
+		}
+	}
+	return nil
+}
+
+// flacBitReader reads a big-endian bit stream and remembers the bytes of the
+// current frame for CRC checks
+type flacBitReader struct {
+	r     *bufio.Reader
+	cur   byte
+	nbits int // unread bits left in cur
+	frame []byte
+}
+
+// startFrame begins recording the bytes of a new frame
+func (br *flacBitReader) startFrame() {
+	br.frame = br.frame[:0]
+}
+
+// frameBytes returns the bytes consumed since startFrame
+func (br *flacBitReader) frameBytes() []byte {
+	return br.frame
+}
+
+func (br *flacBitReader) frameLen() int {
+	return len(br.frame)
+}
+
+func (br *flacBitReader) nextByte() error {
+	b, err := br.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	br.cur = b
+	br.nbits = 8
+	br.frame = append(br.frame, b)
+	return nil
+}
+
+// read returns the next n (at most 64) bits
+func (br *flacBitReader) read(n int) (uint64, error) {
+	var v uint64
+	for n > 0 {
+		if br.nbits == 0 {
+			if err := br.nextByte(); err != nil {
+				return 0, err
+			}
+		}
+		take := n
+		if take > br.nbits {
+			take = br.nbits
+		}
+		shift := br.nbits - take
+		v = v<<uint(take) | uint64(br.cur>>uint(shift))&(1<<uint(take)-1)
+		br.nbits -= take
+		n -= take
+	}
+	return v, nil
+}
+
+// readSigned returns the next n bits as a two's complement number
+func (br *flacBitReader) readSigned(n int) (int32, error) {
+	v, err := br.read(n)
+	if err != nil {
+		return 0, err
+	}
+	return int32(int64(v<<(64-uint(n))) >> (64 - uint(n))), nil
+}
+
+// readUnary counts zero bits up to the next one bit
+func (br *flacBitReader) readUnary() (uint64, error) {
+	var n uint64
+	for {
+		if br.nbits == 0 {
+			if err := br.nextByte(); err != nil {
+				return 0, err
+			}
+		}
+		// Fast path over whole zero bytes
+		if br.nbits == 8 && br.cur == 0 {
+			n += 8
+			br.nbits = 0
+			continue
+		}
+		br.nbits--
+		if br.cur>>uint(br.nbits)&1 == 1 {
+			return n, nil
+		}
+		n++
+	}
+}
+
+// skipUTF8 skips the UTF-8 style coded frame or sample number
+func (br *flacBitReader) skipUTF8() error {
+	lead, err := br.read(8)
+	if err != nil {
+		return err
+	}
+	for mask := uint64(0x40); lead&0x80 != 0 && lead&mask != 0; mask >>= 1 {
+		if _, err := br.read(8); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// align discards the bits left in the current byte
+func (br *flacBitReader) align() {
+	br.nbits = 0
+}
+
+// readBytes reads whole bytes outside of frames
+func (br *flacBitReader) readBytes(p []byte) error {
+	_, err := io.ReadFull(br.r, p)
+	return err
+}
+
+// skip discards n whole bytes outside of frames
+func (br *flacBitReader) skip(n int64) error {
+	_, err := io.CopyN(io.Discard, br.r, n)
+	return err
+}
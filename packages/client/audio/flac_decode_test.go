@@ -0,0 +1,213 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeFLACRoundTrip(t *testing.T) {
+	for _, bps := range []int{16, 24} {
+		for _, channels := range []int{1, 2} {
+			t.Run(fmt.Sprintf("%dbit_%dch", bps, channels), func(t *testing.T) {
+				format := Format{SampleRate: 24000, Channels: channels, BitsPerSample: bps}
+				buf := &Buffer{Format: format, Data: encodePCM(format, testSignal(format, 2*flacBlockSize+123))}
+
+				var out bytes.Buffer
+				if err := buf.WriteFLAC(&out); err != nil {
+					t.Fatalf("WriteFLAC() error = %v", err)
+				}
+				// Decode byte by byte to exercise reads split across frames
+				decoded, err := DecodeFLAC(iotest.OneByteReader(&out))
+				if err != nil {
+					t.Fatalf("DecodeFLAC() error = %v", err)
+				}
+				if decoded.Format != format {
+					t.Errorf("format = %+v, want %+v", decoded.Format, format)
+				}
+				if !bytes.Equal(decoded.Data, buf.Data) {
+					t.Error("decoded PCM differs from input")
+				}
+			})
+		}
+	}
+}
+
+// TestDecodeFLACFeatures decodes a hand-built stream using encoder features
+// WriteFLAC does not produce: an ID3 tag, extra metadata, mid/side stereo,
+// LPC prediction, partitioned and escaped residuals and wasted bits.
+func TestDecodeFLACFeatures(t *testing.T) {
+	const blockSize = 16
+	left := make([]int32, blockSize)
+	right := make([]int32, blockSize)
+	mid := make([]int32, blockSize)
+	for i := range left {
+		left[i] = int32(1000 + 3*i*i + 5*(i%3))
+		right[i] = left[i] - 4
+		mid[i] = (left[i] + right[i]) >> 1
+	}
+
+	var out bytes.Buffer
+	out.Write([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 10})
+	out.Write(make([]byte, 10))
+	out.WriteString("fLaC")
+	var info bytes.Buffer
+	writeFLACStreamInfo(&info, Format{SampleRate: 8000, Channels: 2, BitsPerSample: 16}, blockSize, [16]byte{})
+	meta := info.Bytes()
+	meta[0] &^= 0x80 // not the last block
+	out.Write(meta)
+	out.Write([]byte{0x81, 0, 0, 5}) // last block: PADDING
+	out.Write(make([]byte, 5))
+
+	bw := &bitWriter{}
+	bw.write(0x3FFE, 14)
+	bw.write(0, 2)
+	bw.write(6, 4)  // 8-bit block size follows
+	bw.write(4, 4)  // 8000 Hz
+	bw.write(10, 4) // mid/side
+	bw.write(4, 3)  // 16 bit
+	bw.write(0, 1)
+	bw.write(0, 8) // frame number
+	bw.write(blockSize-1, 8)
+	bw.write(uint64(crc8(bw.bytes())), 8)
+
+	// Mid: LPC order 2, predicting 2*s[i-1] - s[i-2]
+	bw.write(0x40|1<<1, 8)
+	bw.writeSigned(mid[0], 16)
+	bw.writeSigned(mid[1], 16)
+	bw.write(3, 4) // 4 bit coefficients
+	bw.writeSigned(0, 5)
+	bw.writeSigned(2, 4)
+	bw.writeSigned(-1, 4)
+	bw.write(0, 2) // Rice, 4 bit parameters
+	bw.write(1, 4) // two partitions
+	bw.write(3, 4)
+	for i := 2; i < blockSize; i++ {
+		residual := mid[i] - 2*mid[i-1] + mid[i-2]
+		if i == blockSize/2 {
+			bw.write(0x0F, 4) // escaped partition with raw 16 bit residuals
+			bw.write(16, 5)
+		}
+		if i < blockSize/2 {
+			u := uint64(zigzag(residual))
+			bw.write(1, int(u>>3)+1)
+			bw.write(u&7, 3)
+		} else {
+			bw.writeSigned(residual, 16)
+		}
+	}
+
+	// Side: CONSTANT 4 = 1 with two wasted bits
+	bw.write(0x01, 8)
+	bw.write(1, 2)
+	bw.writeSigned(1, 15)
+
+	bw.align()
+	bw.write(uint64(crc16(bw.bytes())), 16)
+	out.Write(bw.bytes())
+
+	decoded, err := DecodeFLAC(&out)
+	if err != nil {
+		t.Fatalf("DecodeFLAC() error = %v", err)
+	}
+	want := make([]int32, 0, 2*blockSize)
+	for i := range left {
+		want = append(want, left[i], right[i])
+	}
+	if !bytes.Equal(decoded.Data, encodePCM(decoded.Format, want)) {
+		t.Error("decoded PCM differs from input")
+	}
+}
+
+func TestDecodeFLACCorruption(t *testing.T) {
+	format := Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+	buf := &Buffer{Format: format, Data: encodePCM(format, testSignal(format, 500))}
+	var out bytes.Buffer
+	if err := buf.WriteFLAC(&out); err != nil {
+		t.Fatal(err)
+	}
+	data := out.Bytes()
+	data[len(data)-10] ^= 0x10
+
+	if _, err := DecodeFLAC(bytes.NewReader(data)); err == nil {
+		t.Error("expected a CRC error for a corrupted frame")
+	}
+	if _, err := DecodeFLAC(bytes.NewReader([]byte("RIFF...."))); err != ErrNotFLAC {
+		t.Errorf("error = %v, want ErrNotFLAC", err)
+	}
+}
+
+func TestNewStream(t *testing.T) {
+	format := Format{SampleRate: 24000, Channels: 2, BitsPerSample: 16}
+	buf := &Buffer{Format: format, Data: encodePCM(format, testSignal(format, 300))}
+
+	var wav, streamed, flac bytes.Buffer
+	if err := buf.WriteWAV(&wav); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteStreamingWAVHeader(&streamed, format); err != nil {
+		t.Fatal(err)
+	}
+	streamed.Write(buf.Data)
+	if err := buf.WriteFLAC(&flac); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, data := range map[string][]byte{"wav": wav.Bytes(), "streamed_wav": streamed.Bytes(), "flac": flac.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			s, err := NewStream(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("NewStream() error = %v", err)
+			}
+			if s.Format() != format {
+				t.Errorf("format = %+v, want %+v", s.Format(), format)
+			}
+			wantFrames := int64(300)
+			if name == "streamed_wav" {
+				wantFrames = -1
+			}
+			if s.TotalFrames() != wantFrames {
+				t.Errorf("TotalFrames() = %d, want %d", s.TotalFrames(), wantFrames)
+			}
+			pcm, err := io.ReadAll(s)
+			if err != nil {
+				t.Fatalf("read error = %v", err)
+			}
+			if !bytes.Equal(pcm, buf.Data) {
+				t.Error("decoded PCM differs from input")
+			}
+		})
+	}
+}
+
+func TestNewStreamUnsupported(t *testing.T) {
+	// AAC in ADTS with an ID3 tag; the tag must not be mistaken for FLAC,
+	// nor the frame sync for MP3
+	aac := append([]byte{'I', 'D', '3', 3, 0, 0, 0, 0, 0, 4, 0, 0, 0, 0}, 0xFF, 0xF1, 0x50, 0x80)
+	for _, data := range [][]byte{aac, {0xFF, 0xF1, 0x50, 0x80}, []byte("Og")} {
+		if _, err := NewStream(bytes.NewReader(data)); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("NewStream(%q) error = %v, want ErrUnsupportedFormat", data, err)
+		}
+	}
+
+	// The input stays readable for another decoder
+	r := bufio.NewReaderSize(bytes.NewReader(aac), 64*1024)
+	if _, err := NewStream(r); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatal(err)
+	}
+	if rest, _ := io.ReadAll(r); !bytes.Equal(rest, aac) {
+		t.Error("NewStream consumed input it could not decode")
+	}
+
+	var header [44]byte
+	copy(header[:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], 36)
+	if _, err := NewStream(bytes.NewReader(header[:])); err != ErrNotWAV {
+		t.Errorf("broken WAV error = %v, want ErrNotWAV", err)
+	}
+}
@@ -0,0 +1,227 @@
+package audio
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCRC(t *testing.T) {
+	check := []byte("123456789")
+	if got := crc8(check); got != 0xF4 {
+		t.Errorf("crc8 = %#x, want 0xf4", got)
+	}
+	if got := crc16(check); got != 0xFEE8 {
+		t.Errorf("crc16 = %#x, want 0xfee8", got)
+	}
+}
+
+func TestWriteFLACRoundTrip(t *testing.T) {
+	for _, bps := range []int{16, 24} {
+		for _, channels := range []int{1, 2} {
+			t.Run(fmt.Sprintf("%dbit_%dch", bps, channels), func(t *testing.T) {
+				format := Format{SampleRate: 24000, Channels: channels, BitsPerSample: bps}
+				want := testSignal(format, 2*flacBlockSize+123)
+				buf := &Buffer{Format: format, Data: encodePCM(format, want)}
+
+				var out bytes.Buffer
+				if err := buf.WriteFLAC(&out); err != nil {
+					t.Fatalf("WriteFLAC() error = %v", err)
+				}
+				if out.Len() >= len(buf.Data) {
+					t.Errorf("FLAC output (%d bytes) is not smaller than PCM (%d bytes)", out.Len(), len(buf.Data))
+				}
+
+				info, got, err := decodeTestFLAC(out.Bytes())
+				if err != nil {
+					t.Fatalf("decode error = %v", err)
+				}
+				if info.format != format {
+					t.Errorf("STREAMINFO format = %+v, want %+v", info.format, format)
+				}
+				if info.totalFrames != uint64(buf.Frames()) {
+					t.Errorf("STREAMINFO total samples = %d, want %d", info.totalFrames, buf.Frames())
+				}
+				if info.md5 != md5.Sum(buf.Data) {
+					t.Error("STREAMINFO MD5 does not match PCM data")
+				}
+				if len(got) != len(want) {
+					t.Fatalf("decoded %d samples, want %d", len(got), len(want))
+				}
+				for i := range want {
+					if got[i] != want[i] {
+						t.Fatalf("sample %d = %d, want %d", i, got[i], want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+// testSignal returns a tone followed by noise, so both FIXED and VERBATIM subframes are exercised
+func testSignal(format Format, frames int) []int32 {
+	amplitude := float64(int(1)<<(format.BitsPerSample-2)) - 1
+	rng := rand.New(rand.NewSource(1))
+	samples := make([]int32, 0, frames*format.Channels)
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < format.Channels; ch++ {
+			var v int32
+			if i < flacBlockSize+100 {
+				v = int32(amplitude * math.Sin(float64(i)*0.02*float64(ch+1)))
+			} else {
+				v = int32(rng.Int63n(int64(2*amplitude))) - int32(amplitude)
+			}
+			samples = append(samples, v)
+		}
+	}
+	return samples
+}
+
+func encodePCM(format Format, samples []int32) []byte {
+	var buf bytes.Buffer
+	for _, v := range samples {
+		switch format.BitsPerSample {
+		case 16:
+			binary.Write(&buf, binary.LittleEndian, int16(v))
+		case 24:
+			buf.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16)})
+		}
+	}
+	return buf.Bytes()
+}
+
+type testStreamInfo struct {
+	format      Format
+	totalFrames uint64
+	md5         [16]byte
+}
+
+// decodeTestFLAC is a minimal decoder for the subset of FLAC produced by WriteFLAC
+func decodeTestFLAC(data []byte) (*testStreamInfo, []int32, error) {
+	if string(data[:4]) != "fLaC" {
+		return nil, nil, fmt.Errorf("missing fLaC marker")
+	}
+	r := &bitReader{data: data[4:]}
+	if r.read(1) != 1 || r.read(7) != 0 || r.read(24) != 34 {
+		return nil, nil, fmt.Errorf("unexpected metadata block header")
+	}
+	r.read(16 + 16 + 24 + 24)
+	info := &testStreamInfo{}
+	info.format.SampleRate = int(r.read(20))
+	info.format.Channels = int(r.read(3)) + 1
+	info.format.BitsPerSample = int(r.read(5)) + 1
+	info.totalFrames = r.read(36)
+	for i := range info.md5 {
+		info.md5[i] = byte(r.read(8))
+	}
+
+	bps := info.format.BitsPerSample
+	var samples []int32
+	for r.pos/8 < len(r.data) {
+		frameStart := r.pos / 8
+		if r.read(14) != 0x3FFE {
+			return nil, nil, fmt.Errorf("lost frame sync at byte %d", frameStart)
+		}
+		r.read(1 + 1 + 4 + 4)
+		if channels := int(r.read(4)) + 1; channels != info.format.Channels {
+			return nil, nil, fmt.Errorf("frame channel count %d", channels)
+		}
+		r.read(3 + 1)
+		// UTF-8 coded frame number
+		lead := r.read(8)
+		for mask := uint64(0x40); lead&0x80 != 0 && lead&mask != 0; mask >>= 1 {
+			r.read(8)
+		}
+		blockSize := int(r.read(16)) + 1
+		if crc := crc8(r.data[frameStart : r.pos/8]); uint64(crc) != r.read(8) {
+			return nil, nil, fmt.Errorf("frame header CRC mismatch")
+		}
+
+		channels := make([][]int32, info.format.Channels)
+		for ch := range channels {
+			channels[ch] = make([]int32, blockSize)
+			r.read(1)
+			kind := r.read(6)
+			r.read(1)
+			switch {
+			case kind == 0x01:
+				for i := range channels[ch] {
+					channels[ch][i] = r.readSigned(bps)
+				}
+			case kind&0x38 == 0x08:
+				order := int(kind & 0x07)
+				for i := 0; i < order; i++ {
+					channels[ch][i] = r.readSigned(bps)
+				}
+				r.read(2 + 4)
+				param := int(r.read(4))
+				residuals := make([]int32, blockSize)
+				for i := order; i < blockSize; i++ {
+					q := uint64(0)
+					for r.read(1) == 0 {
+						q++
+					}
+					u := uint32(q<<param | r.read(param))
+					residuals[i] = int32(u>>1) ^ -int32(u&1)
+				}
+				restoreFixed(channels[ch], order, residuals)
+			default:
+				return nil, nil, fmt.Errorf("unexpected subframe type %#x", kind)
+			}
+		}
+
+		if r.pos%8 != 0 {
+			r.read(8 - r.pos%8)
+		}
+		if crc := crc16(r.data[frameStart : r.pos/8]); uint64(crc) != r.read(16) {
+			return nil, nil, fmt.Errorf("frame CRC mismatch")
+		}
+
+		for i := 0; i < blockSize; i++ {
+			for ch := range channels {
+				samples = append(samples, channels[ch][i])
+			}
+		}
+	}
+	return info, samples, nil
+}
+
+func restoreFixed(s []int32, order int, res []int32) {
+	for i := order; i < len(s); i++ {
+		switch order {
+		case 0:
+			s[i] = res[i]
+		case 1:
+			s[i] = res[i] + s[i-1]
+		case 2:
+			s[i] = res[i] + 2*s[i-1] - s[i-2]
+		case 3:
+			s[i] = res[i] + 3*s[i-1] - 3*s[i-2] + s[i-3]
+		case 4:
+			s[i] = res[i] + 4*s[i-1] - 6*s[i-2] + 4*s[i-3] - s[i-4]
+		}
+	}
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *bitReader) read(n int) uint64 {
+	var v uint64
+	for i := 0; i < n; i++ {
+		bit := (r.data[r.pos/8] >> (7 - uint(r.pos%8))) & 1
+		v = v<<1 | uint64(bit)
+		r.pos++
+	}
+	return v
+}
+
+func (r *bitReader) readSigned(n int) int32 {
+	return int32(int64(r.read(n)<<(64-n)) >> (64 - n))
+}
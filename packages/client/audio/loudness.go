@@ -0,0 +1,164 @@
+package audio
+
+import (
+	"math"
+)
+
+// Loudness measurement per ITU-R BS.1770-4 (EBU R 128)
+const (
+	loudnessBlock        = 0.4 // gating block length in seconds
+	loudnessStep         = 0.1 // gating block step (75% overlap)
+	loudnessAbsoluteGate = -70 // LUFS
+	loudnessRelativeGate = -10 // LU below the absolutely gated loudness
+	loudnessOffset       = -0.691
+	normalizePeakCeiling = -1.0 // dBFS that Normalize keeps peaks below
+)
+
+// Loudness returns the integrated loudness of the audio in LUFS, measured with
+// K-weighting and gating as specified by ITU-R BS.1770. All channels are
+// weighted equally. Silent audio returns negative infinity.
+func (b *Buffer) Loudness() float64 {
+	channels := b.Format.Channels
+	samples := b.floats()
+	frames := len(samples) / channels
+	if frames == 0 {
+		return math.Inf(-1)
+	}
+
+	// Mean square of each K-weighted channel over each gating block
+	rate := float64(b.Format.SampleRate)
+	blockFrames := int(loudnessBlock * rate)
+	stepFrames := int(loudnessStep * rate)
+	if frames < blockFrames {
+		// Shorter than one block: measure the whole buffer as one
+		blockFrames, stepFrames = frames, frames
+	}
+	blocks := (frames-blockFrames)/stepFrames + 1
+	power := make([]float64, blocks)
+
+	weighted := make([]float64, frames)
+	for c := 0; c < channels; c++ {
+		filter := newKWeighting(rate)
+		for i := 0; i < frames; i++ {
+			weighted[i] = filter.process(samples[i*channels+c])
+		}
+		// Sum squares per step so that overlapping blocks share the work
+		steps := make([]float64, frames/stepFrames+1)
+		for i, v := range weighted {
+			steps[i/stepFrames] += v * v
+		}
+		stepsPerBlock := blockFrames / stepFrames
+		for j := range power {
+			var sum float64
+			for k := j; k < j+stepsPerBlock; k++ {
+				sum += steps[k]
+			}
+			power[j] += sum / float64(blockFrames)
+		}
+	}
+
+	gated := func(threshold float64) (float64, int) {
+		var sum float64
+		var count int
+		for _, p := range power {
+			if powerToLUFS(p) > threshold {
+				sum += p
+				count++
+			}
+		}
+		return sum, count
+	}
+	sum, count := gated(loudnessAbsoluteGate)
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	relative := powerToLUFS(sum/float64(count)) + loudnessRelativeGate
+	sum, count = gated(max(relative, loudnessAbsoluteGate))
+	if count == 0 {
+		return math.Inf(-1)
+	}
+	return powerToLUFS(sum / float64(count))
+}
+
+// Normalize returns the audio with its gain adjusted so that its integrated
+// loudness is targetLUFS. The gain is reduced where needed to keep sample
+// peaks below -1 dBFS, so a loud target may not be reached. Silent audio is
+// returned unchanged.
+func (b *Buffer) Normalize(targetLUFS float64) *Buffer {
+	loudness := b.Loudness()
+	if math.IsInf(loudness, -1) {
+		return b
+	}
+	gain := targetLUFS - loudness
+
+	var peak float64
+	for _, v := range b.floats() {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	if peak > 0 {
+		peakDB := 20 * math.Log10(peak)
+		gain = math.Min(gain, normalizePeakCeiling-peakDB)
+	}
+	return b.Gain(gain)
+}
+
+func powerToLUFS(power float64) float64 {
+	if power <= 0 {
+		return math.Inf(-1)
+	}
+	return loudnessOffset + 10*math.Log10(power)
+}
+
+// biquad is a second order IIR filter in direct form I
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// kWeighting is the BS.1770 K-weighting filter: a high shelf modelling the
+// head followed by a high pass
+type kWeighting struct {
+	shelf, highPass biquad
+}
+
+// newKWeighting derives the K-weighting filter for any sample rate from its
+// analog prototype; at 48 kHz it matches the coefficients in BS.1770
+func newKWeighting(rate float64) *kWeighting {
+	// High shelf, +4 dB above about 1.5 kHz
+	f0, gain, q := 1681.974450955533, 3.999843853973347, 0.7071752369554196
+	k := math.Tan(math.Pi * f0 / rate)
+	vh := math.Pow(10, gain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/q + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/q + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/q + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+
+	// High pass at about 38 Hz
+	f0, q = 38.13547087602444, 0.5003270373238773
+	k = math.Tan(math.Pi * f0 / rate)
+	a0 = 1 + k/q + k*k
+	highPass := biquad{
+		b0: 1,
+		b1: -2,
+		b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/q + k*k) / a0,
+	}
+	return &kWeighting{shelf: shelf, highPass: highPass}
+}
+
+func (k *kWeighting) process(x float64) float64 {
+	return k.highPass.process(k.shelf.process(x))
+}
@@ -0,0 +1,88 @@
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// sineBuffer returns a sine of the given frequency and peak amplitude, the
+// same in every channel
+func sineBuffer(format Format, frequency, amplitude float64, duration time.Duration) *Buffer {
+	frames := int(durationToFrames(duration, format.SampleRate))
+	samples := make([]float64, frames*format.Channels)
+	for i := 0; i < frames; i++ {
+		v := amplitude * math.Sin(2*math.Pi*frequency*float64(i)/float64(format.SampleRate))
+		for c := 0; c < format.Channels; c++ {
+			samples[i*format.Channels+c] = v
+		}
+	}
+	return &Buffer{Format: format, Data: encodeFloats(format, samples)}
+}
+
+func TestLoudness(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   float64
+	}{
+		// BS.1770: a full scale 1 kHz sine in one channel reads -3.01 LUFS
+		{"mono 48k", Format{SampleRate: 48000, Channels: 1, BitsPerSample: 16}, -3.01},
+		{"mono 44.1k", Format{SampleRate: 44100, Channels: 1, BitsPerSample: 24}, -3.01},
+		{"mono 24k", Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}, -3.01},
+		// The same signal in two channels is twice the power
+		{"stereo 48k", Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sineBuffer(tt.format, 1000, 0.999, 2*time.Second).Loudness()
+			if math.Abs(got-tt.want) > 0.05 {
+				t.Errorf("Loudness() = %.3f LUFS, want %.2f", got, tt.want)
+			}
+		})
+	}
+
+	silence := &Buffer{Format: Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}, Data: make([]byte, 48000)}
+	if got := silence.Loudness(); !math.IsInf(got, -1) {
+		t.Errorf("Loudness() of silence = %v, want -Inf", got)
+	}
+}
+
+func TestLoudnessGating(t *testing.T) {
+	format := Format{SampleRate: 48000, Channels: 1, BitsPerSample: 16}
+	tone := sineBuffer(format, 1000, 0.1, 5*time.Second)
+	// Long silence between the tones must not lower the measured loudness;
+	// only the few blocks straddling the edges count partly
+	gapped, err := Concat(0, tone, &Buffer{Format: format, Data: Silence(format, 5*time.Second)}, tone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := gapped.Loudness() - tone.Loudness(); math.Abs(diff) > 0.25 {
+		t.Errorf("silence changed loudness by %.2f LU", diff)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	format := Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+	quiet := sineBuffer(format, 440, 0.05, 2*time.Second)
+
+	normalized := quiet.Normalize(-16)
+	if got := normalized.Loudness(); math.Abs(got+16) > 0.05 {
+		t.Errorf("Loudness() after Normalize(-16) = %.3f", got)
+	}
+
+	// A target that would clip is limited to a -1 dBFS peak
+	loud := quiet.Normalize(0)
+	var peak float64
+	for _, v := range loud.floats() {
+		peak = math.Max(peak, math.Abs(v))
+	}
+	if peakDB := 20 * math.Log10(peak); math.Abs(peakDB+1) > 0.05 {
+		t.Errorf("peak after Normalize(0) = %.2f dBFS, want -1", peakDB)
+	}
+
+	silence := &Buffer{Format: format, Data: make([]byte, 4800)}
+	if silence.Normalize(-16) != silence {
+		t.Error("silent audio must be returned unchanged")
+	}
+}
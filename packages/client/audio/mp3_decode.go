@@ -0,0 +1,1046 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrNotMP3 is returned when the input is not an MPEG Layer III stream
+var ErrNotMP3 = errors.New("not an MP3 file")
+
+// mp3DecoderDelay is the delay in samples of the synthesis filterbanks,
+// which the LAME gapless information leaves for the decoder to remove
+const mp3DecoderDelay = 529
+
+// mp3MaxReservoir bounds the main data kept for the bit reservoir; MPEG-1
+// frames may reach back 511 bytes
+const mp3MaxReservoir = 511
+
+// Header modes
+const (
+	mp3ModeJoint = 1
+	mp3ModeMono  = 3
+)
+
+// mp3Stream decodes MPEG-1, 2 and 2.5 Layer III frames on demand
+type mp3Stream struct {
+	r      *bufio.Reader
+	first  mp3Header // the layout every later frame must have
+	format Format
+	total  int64 // length after gapless trimming, -1 when unknown
+	skip   int64 // frames of encoder and decoder delay still to drop
+	left   int64 // frames still to produce, -1 when unknown
+
+	pending   []byte // the first audio frame, read while probing
+	frame     []byte
+	reservoir []byte // main data of past frames, for the bit reservoir
+	mainData  []byte
+
+	scalefacs [2]mp3Scalefactors
+	overlap   [2][576]float64 // second halves of the last IMDCT outputs
+	synth     [2]mp3Synthesis
+	samples   [2][1152]float64
+
+	out  []byte
+	pcm  []byte // decoded samples of the current frame not yet read
+	done bool
+}
+
+// mp3Header holds the fields of a Layer III frame header
+type mp3Header struct {
+	version    int // 0 for MPEG-1, 1 for MPEG-2, 2 for MPEG-2.5
+	crc        bool
+	bitrate    int // kbit/s
+	sampleRate int
+	rateIndex  int // into mp3BandTables
+	padding    bool
+	mode       int
+	modeExt    int
+}
+
+// mp3SideInfo holds the side information of a frame
+type mp3SideInfo struct {
+	mainDataBegin int
+	scfsi         [2][4]bool
+	granules      [2][2]mp3Granule // by granule and channel
+}
+
+// mp3Granule holds the side information of one channel of a granule
+type mp3Granule struct {
+	part23Length     int
+	bigValues        int
+	globalGain       int
+	scalefacCompress int
+	windowSwitching  bool
+	blockType        int
+	mixedBlock       bool
+	tableSelect      [3]int
+	subblockGain     [3]int
+	region0Count     int
+	region1Count     int
+	preflag          bool
+	scalefacScale    bool
+	count1Table      int
+}
+
+// mp3Scalefactors holds the scalefactors of a channel. They are kept across
+// granules because MPEG-1 may reuse those of the first granule.
+type mp3Scalefactors struct {
+	long  [22]int
+	short [13][3]int
+
+	// For the intensity stereo of MPEG-2: the largest value each scalefactor
+	// could take, which marks a band as not intensity coded, and the scale
+	intensityLong  [22]int
+	intensityShort [13]int
+	intensityScale int
+}
+
+// DecodeMP3 reads an MP3 stream into memory
+func DecodeMP3(r io.Reader) (*Buffer, error) {
+	s, err := NewMP3Stream(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(s)
+	if err != nil {
+		return nil, err
+	}
+	return &Buffer{Format: s.Format(), Data: data}, nil
+}
+
+// NewMP3Stream finds the first Layer III frame in r and returns a stream that
+// decodes frames as they are read, to 16-bit PCM. Leading ID3v2 tags are
+// skipped, and a Xing, Info or VBRI header sets the stream length, trimmed of
+// the encoder delay and padding when the LAME extension records them.
+func NewMP3Stream(r io.Reader) (Stream, error) {
+	s := &mp3Stream{r: bufio.NewReader(r), total: -1, left: -1}
+	for {
+		header, err := s.r.Peek(10)
+		if err != nil || string(header[:3]) != "ID3" {
+			break
+		}
+		// Syncsafe size: 7 bits per byte; a footer adds another 10 bytes
+		size := int(header[6])<<21 | int(header[7])<<14 | int(header[8])<<7 | int(header[9])
+		size += 10
+		if header[5]&0x10 != 0 {
+			size += 10
+		}
+		if _, err := s.r.Discard(size); err != nil {
+			return nil, ErrNotMP3
+		}
+	}
+
+	h, frame, err := s.readFrame()
+	if err != nil {
+		if err == io.EOF {
+			return nil, ErrNotMP3
+		}
+		return nil, err
+	}
+	s.first = h
+	s.format = Format{SampleRate: h.sampleRate, Channels: h.channels(), BitsPerSample: 16}
+	if !s.readInfoFrame(h, frame) {
+		s.pending = append([]byte(nil), frame...)
+	}
+	return s, nil
+}
+
+func (s *mp3Stream) Format() Format {
+	return s.format
+}
+
+func (s *mp3Stream) TotalFrames() int64 {
+	return s.total
+}
+
+func (s *mp3Stream) Read(p []byte) (int, error) {
+	for len(s.pcm) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.decodeFrame(); err != nil {
+			if err == io.EOF {
+				s.done = true
+				continue
+			}
+			return 0, err
+		}
+	}
+	n := copy(p, s.pcm)
+	s.pcm = s.pcm[n:]
+	return n, nil
+}
+
+// parseMP3Header parses a Layer III frame header, reporting false for
+// anything else, free format frames included
+func parseMP3Header(b []byte) (mp3Header, bool) {
+	var h mp3Header
+	if len(b) < 4 || b[0] != 0xFF || b[1]&0xE0 != 0xE0 || b[1]>>1&3 != 1 {
+		return h, false
+	}
+	switch b[1] >> 3 & 3 {
+	case 3:
+		h.version = 0
+	case 2:
+		h.version = 1
+	case 0:
+		h.version = 2
+	default:
+		return h, false
+	}
+	bitrateIndex, rateIndex := int(b[2]>>4), int(b[2]>>2&3)
+	if bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return h, false
+	}
+	lsf := 0
+	if h.version > 0 {
+		lsf = 1
+	}
+	h.crc = b[1]&1 == 0
+	h.bitrate = mp3Bitrates[lsf][bitrateIndex]
+	h.sampleRate = mp3SampleRates[h.version][rateIndex]
+	h.rateIndex = h.version*3 + rateIndex
+	h.padding = b[2]>>1&1 == 1
+	h.mode = int(b[3] >> 6)
+	h.modeExt = int(b[3] >> 4 & 3)
+	return h, true
+}
+
+// frameSize returns the length of the frame in bytes, header included
+func (h mp3Header) frameSize() int {
+	size := 144000 * h.bitrate / h.sampleRate
+	if h.version > 0 {
+		size = 72000 * h.bitrate / h.sampleRate
+	}
+	if h.padding {
+		size++
+	}
+	return size
+}
+
+func (h mp3Header) channels() int {
+	if h.mode == mp3ModeMono {
+		return 1
+	}
+	return 2
+}
+
+// granules returns the number of granules of 576 samples in a frame
+func (h mp3Header) granules() int {
+	if h.version == 0 {
+		return 2
+	}
+	return 1
+}
+
+// sideInfoOffset returns where the side information starts in the frame
+func (h mp3Header) sideInfoOffset() int {
+	if h.crc {
+		return 6
+	}
+	return 4
+}
+
+func (h mp3Header) sideInfoSize() int {
+	switch {
+	case h.version == 0 && h.channels() == 1:
+		return 17
+	case h.version == 0:
+		return 32
+	case h.channels() == 1:
+		return 9
+	default:
+		return 17
+	}
+}
+
+// readFrame reads the next frame, skipping anything between frames that does
+// not start one with the layout of the first. It returns io.EOF at the end of
+// the input, a truncated last frame included.
+func (s *mp3Stream) readFrame() (mp3Header, []byte, error) {
+	for {
+		b, err := s.r.Peek(4)
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return mp3Header{}, nil, io.EOF
+			}
+			return mp3Header{}, nil, err
+		}
+		h, ok := parseMP3Header(b)
+		if ok && (s.first.sampleRate == 0 || h.sampleRate == s.first.sampleRate &&
+			h.version == s.first.version && h.channels() == s.first.channels()) {
+			size := h.frameSize()
+			if cap(s.frame) < size {
+				s.frame = make([]byte, size)
+			}
+			s.frame = s.frame[:size]
+			if _, err := io.ReadFull(s.r, s.frame); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return mp3Header{}, nil, io.EOF
+				}
+				return mp3Header{}, nil, err
+			}
+			if size >= h.sideInfoOffset()+h.sideInfoSize() {
+				return h, s.frame, nil
+			}
+			continue
+		}
+		if _, err := s.r.Discard(1); err != nil {
+			return mp3Header{}, nil, err
+		}
+	}
+}
+
+// readInfoFrame reports whether frame is a Xing, Info or VBRI header rather
+// than audio, taking the stream length and gapless trimming from it
+func (s *mp3Stream) readInfoFrame(h mp3Header, frame []byte) bool {
+	samplesPerFrame := int64(576 * h.granules())
+	at := h.sideInfoOffset() + h.sideInfoSize()
+	if len(frame) >= at+8 && (string(frame[at:at+4]) == "Xing" || string(frame[at:at+4]) == "Info") {
+		flags := binary.BigEndian.Uint32(frame[at+4:])
+		at += 8
+		frames := int64(-1)
+		if flags&1 != 0 && len(frame) >= at+4 {
+			frames = int64(binary.BigEndian.Uint32(frame[at:]))
+			at += 4
+		}
+		if flags&2 != 0 {
+			at += 4
+		}
+		if flags&4 != 0 {
+			at += 100
+		}
+		if flags&8 != 0 {
+			at += 4
+		}
+		if frames < 0 {
+			return true
+		}
+		s.total = frames * samplesPerFrame
+		// The LAME extension, also written by FFmpeg, records the encoder
+		// delay and padding in 12 bits each
+		if len(frame) >= at+24 {
+			switch string(frame[at : at+4]) {
+			case "LAME", "Lavc", "Lavf":
+				d := frame[at+21 : at+24]
+				delay := int64(d[0])<<4 | int64(d[1])>>4
+				padding := int64(d[1]&0x0F)<<8 | int64(d[2])
+				if trimmed := s.total - delay - padding; trimmed >= 0 {
+					s.total = trimmed
+					s.skip = delay + mp3DecoderDelay
+				}
+			}
+		}
+		s.left = s.total
+		return true
+	}
+
+	at = 4 + 32
+	if len(frame) >= at+18 && string(frame[at:at+4]) == "VBRI" {
+		s.total = int64(binary.BigEndian.Uint32(frame[at+14:])) * samplesPerFrame
+		s.left = s.total
+		return true
+	}
+	return false
+}
+
+// decodeFrame decodes the next frame into s.pcm, returning io.EOF at the end
+// of the stream
+func (s *mp3Stream) decodeFrame() error {
+	if s.left == 0 {
+		return io.EOF
+	}
+	var (
+		h     mp3Header
+		frame []byte
+	)
+	if s.pending != nil {
+		h, _ = parseMP3Header(s.pending)
+		frame, s.pending = s.pending, nil
+	} else {
+		var err error
+		if h, frame, err = s.readFrame(); err != nil {
+			return err
+		}
+	}
+
+	offset := h.sideInfoOffset()
+	side, err := parseMP3SideInfo(h, frame[offset:offset+h.sideInfoSize()])
+	if err != nil {
+		return err
+	}
+	main := frame[offset+h.sideInfoSize():]
+
+	// The main data of the frame may begin in earlier frames. Until enough
+	// of them have been seen, as after a cut, the frame decodes as silence.
+	var data []byte
+	if side.mainDataBegin <= len(s.reservoir) {
+		data = append(s.mainData[:0], s.reservoir[len(s.reservoir)-side.mainDataBegin:]...)
+		data = append(data, main...)
+		s.mainData = data
+	}
+	s.reservoir = append(s.reservoir, main...)
+	if extra := len(s.reservoir) - mp3MaxReservoir; extra > 0 {
+		s.reservoir = append(s.reservoir[:0], s.reservoir[extra:]...)
+	}
+
+	channels := h.channels()
+	br := &mp3BitReader{data: data}
+	for gr := 0; gr < h.granules(); gr++ {
+		var xr [2][576]float64
+		if data != nil {
+			for ch := 0; ch < channels; ch++ {
+				s.decodeChannel(h, &side, gr, ch, br, &xr[ch])
+			}
+		}
+		if h.mode == mp3ModeJoint {
+			s.jointStereo(h, &side.granules[gr][1], &xr)
+		}
+		for ch := 0; ch < channels; ch++ {
+			s.hybrid(h, &side.granules[gr][ch], ch, &xr[ch])
+			s.synth[ch].synthesize(&xr[ch], s.samples[ch][gr*576:])
+		}
+	}
+	s.emit(576*h.granules(), channels)
+	return nil
+}
+
+// parseMP3SideInfo parses the side information following the frame header
+func parseMP3SideInfo(h mp3Header, data []byte) (mp3SideInfo, error) {
+	var side mp3SideInfo
+	br := &mp3BitReader{data: data}
+	channels := h.channels()
+	if h.version == 0 {
+		side.mainDataBegin = br.read(9)
+		br.read(7 - 2*channels) // private bits
+		for ch := 0; ch < channels; ch++ {
+			for band := range side.scfsi[ch] {
+				side.scfsi[ch][band] = br.read(1) == 1
+			}
+		}
+	} else {
+		side.mainDataBegin = br.read(8)
+		br.read(channels) // private bits
+	}
+
+	for gr := 0; gr < h.granules(); gr++ {
+		for ch := 0; ch < channels; ch++ {
+			g := &side.granules[gr][ch]
+			g.part23Length = br.read(12)
+			g.bigValues = br.read(9)
+			g.globalGain = br.read(8)
+			if h.version == 0 {
+				g.scalefacCompress = br.read(4)
+			} else {
+				g.scalefacCompress = br.read(9)
+			}
+			g.windowSwitching = br.read(1) == 1
+			if g.windowSwitching {
+				g.blockType = br.read(2)
+				if g.blockType == 0 {
+					return side, fmt.Errorf("invalid MP3 block type")
+				}
+				g.mixedBlock = br.read(1) == 1
+				for i := 0; i < 2; i++ {
+					g.tableSelect[i] = br.read(5)
+				}
+				for w := range g.subblockGain {
+					g.subblockGain[w] = br.read(3)
+				}
+			} else {
+				for i := range g.tableSelect {
+					g.tableSelect[i] = br.read(5)
+				}
+				g.region0Count = br.read(4)
+				g.region1Count = br.read(3)
+			}
+			if h.version == 0 {
+				g.preflag = br.read(1) == 1
+			}
+			g.scalefacScale = br.read(1) == 1
+			g.count1Table = br.read(1)
+		}
+	}
+	return side, nil
+}
+
+// decodeChannel reads the scalefactors and spectral values of one channel of
+// a granule from the main data and dequantizes them into xr
+func (s *mp3Stream) decodeChannel(h mp3Header, side *mp3SideInfo, gr, ch int, br *mp3BitReader, xr *[576]float64) {
+	g := &side.granules[gr][ch]
+	start := br.pos
+	if h.version == 0 {
+		s.readScalefactors(g, &s.scalefacs[ch], gr, side.scfsi[ch], br)
+	} else {
+		intensity := ch == 1 && h.mode == mp3ModeJoint && h.modeExt&1 != 0
+		s.readLSFScalefactors(g, &s.scalefacs[ch], intensity, br)
+	}
+
+	var values [576]int
+	readMP3Spectrum(h, g, br, start+g.part23Length, &values)
+	br.pos = start + g.part23Length
+	requantize(h, g, &s.scalefacs[ch], &values, xr)
+}
+
+// readScalefactors reads the MPEG-1 scalefactors of a channel
+func (s *mp3Stream) readScalefactors(g *mp3Granule, sf *mp3Scalefactors, gr int, scfsi [4]bool, br *mp3BitReader) {
+	slen1 := mp3ScalefacLengths[0][g.scalefacCompress]
+	slen2 := mp3ScalefacLengths[1][g.scalefacCompress]
+	if g.blockType == 2 {
+		sfb := 0
+		if g.mixedBlock {
+			for ; sfb < 8; sfb++ {
+				sf.long[sfb] = br.read(slen1)
+			}
+			sfb = 3
+		}
+		for ; sfb < 12; sfb++ {
+			n := slen1
+			if sfb >= 6 {
+				n = slen2
+			}
+			for w := 0; w < 3; w++ {
+				sf.short[sfb][w] = br.read(n)
+			}
+		}
+		return
+	}
+
+	// The second granule may share groups of bands with the first
+	groups := [5]int{0, 6, 11, 16, 21}
+	for group := 0; group < 4; group++ {
+		if gr == 1 && scfsi[group] {
+			continue
+		}
+		n := slen1
+		if group >= 2 {
+			n = slen2
+		}
+		for sfb := groups[group]; sfb < groups[group+1]; sfb++ {
+			sf.long[sfb] = br.read(n)
+		}
+	}
+}
+
+// readLSFScalefactors reads the MPEG-2 scalefactors of a channel, whose
+// layout depends on scalefac_compress and, for the right channel of
+// intensity stereo, differs from that of the left
+func (s *mp3Stream) readLSFScalefactors(g *mp3Granule, sf *mp3Scalefactors, intensity bool, br *mp3BitReader) {
+	var (
+		slen  [4]int
+		table int
+	)
+	compress := g.scalefacCompress
+	if !intensity {
+		switch {
+		case compress < 400:
+			slen = [4]int{(compress >> 4) / 5, (compress >> 4) % 5, compress & 15 >> 2, compress & 3}
+		case compress < 500:
+			compress -= 400
+			slen = [4]int{(compress >> 2) / 5, (compress >> 2) % 5, compress & 3, 0}
+			table = 1
+		default:
+			compress -= 500
+			slen = [4]int{compress / 3, compress % 3, 0, 0}
+			table = 2
+			g.preflag = true
+		}
+	} else {
+		sf.intensityScale = compress & 1
+		compress >>= 1
+		switch {
+		case compress < 180:
+			slen = [4]int{compress / 36, compress % 36 / 6, compress % 36 % 6, 0}
+			table = 3
+		case compress < 244:
+			compress -= 180
+			slen = [4]int{compress & 63 >> 4, compress & 15 >> 2, compress & 3, 0}
+			table = 4
+		default:
+			compress -= 244
+			slen = [4]int{compress / 3, compress % 3, 0, 0}
+			table = 5
+		}
+	}
+
+	block := 0
+	if g.blockType == 2 {
+		block = 1
+		if g.mixedBlock {
+			block = 2
+		}
+	}
+	var values, limits [39]int
+	n := 0
+	for part, count := range mp3LSFBandCounts[table][block] {
+		for i := 0; i < count; i++ {
+			values[n] = br.read(slen[part])
+			limits[n] = 1<<uint(slen[part]) - 1
+			n++
+		}
+	}
+
+	i := 0
+	if g.blockType != 2 {
+		for sfb := 0; sfb < n; sfb++ {
+			sf.long[sfb], sf.intensityLong[sfb] = values[sfb], limits[sfb]
+		}
+		return
+	}
+	sfb := 0
+	if g.mixedBlock {
+		for ; sfb < 6; sfb++ {
+			sf.long[sfb], sf.intensityLong[sfb] = values[i], limits[i]
+			i++
+		}
+		sfb = 3
+	}
+	for ; i < n; sfb++ {
+		for w := 0; w < 3; w++ {
+			sf.short[sfb][w] = values[i]
+			i++
+		}
+		sf.intensityShort[sfb] = limits[i-1]
+	}
+}
+
+// readMP3Spectrum reads the Huffman coded spectral values of a channel, which
+// end at bit end of the main data
+func readMP3Spectrum(h mp3Header, g *mp3Granule, br *mp3BitReader, end int, values *[576]int) {
+	bands := &mp3BandTables[h.rateIndex]
+	bigEnd := min(g.bigValues*2, 576)
+	region1, region2 := 576, 576
+	switch {
+	case g.windowSwitching && g.blockType == 2 && h.rateIndex == 8:
+		region1 = 72
+	case g.windowSwitching && g.blockType == 2:
+		region1 = 36
+	case g.windowSwitching:
+		region1 = bands.long[8]
+	default:
+		region1 = bands.long[min(g.region0Count+1, 22)]
+		region2 = bands.long[min(g.region0Count+g.region1Count+2, 22)]
+	}
+
+	for i := 0; i < bigEnd; i += 2 {
+		region := 0
+		if i >= region2 {
+			region = 2
+		} else if i >= region1 {
+			region = 1
+		}
+		sel := mp3TableSelect[g.tableSelect[region]]
+		if sel.table == 0 {
+			continue
+		}
+		v := mp3Trees[sel.table].decode(br)
+		wrap := mp3HuffmanCodes[sel.table].wrap
+		values[i] = readMP3Value(br, v/wrap, sel.linbits)
+		values[i+1] = readMP3Value(br, v%wrap, sel.linbits)
+	}
+
+	// Quadruples of values up to 1 fill the rest of the part, up to a last
+	// one that may overrun it and is dropped
+	for i := bigEnd; i+4 <= 576 && br.pos < end; i += 4 {
+		var v int
+		if g.count1Table == 0 {
+			v = mp3Count1Tree.decode(br)
+		} else {
+			v = 15 - br.read(4)
+		}
+		quad := [4]int{v >> 3 & 1, v >> 2 & 1, v >> 1 & 1, v & 1}
+		for k := range quad {
+			if quad[k] != 0 && br.read(1) == 1 {
+				quad[k] = -1
+			}
+		}
+		if br.pos > end {
+			break
+		}
+		copy(values[i:i+4], quad[:])
+	}
+}
+
+// readMP3Value completes a Huffman decoded value with its linbits and sign
+func readMP3Value(br *mp3BitReader, v, linbits int) int {
+	if linbits > 0 && v == 15 {
+		v += br.read(linbits)
+	}
+	if v != 0 && br.read(1) == 1 {
+		return -v
+	}
+	return v
+}
+
+// requantize scales the spectral values of a channel by its global gain,
+// subblock gains and scalefactors
+func requantize(h mp3Header, g *mp3Granule, sf *mp3Scalefactors, values *[576]int, xr *[576]float64) {
+	bands := &mp3BandTables[h.rateIndex]
+	gain := float64(g.globalGain-210) / 4
+	multiplier := 0.5
+	if g.scalefacScale {
+		multiplier = 1
+	}
+	dequantize := func(start, end int, exponent float64) {
+		scale := math.Exp2(exponent)
+		for i := start; i < end; i++ {
+			if v := values[i]; v < 0 {
+				xr[i] = -mp3Pow43[-v] * scale
+			} else {
+				xr[i] = mp3Pow43[v] * scale
+			}
+		}
+	}
+
+	longEnd := 576
+	if g.blockType == 2 {
+		longEnd = 0
+		if g.mixedBlock {
+			longEnd = 36
+		}
+	}
+	for sfb := 0; sfb < 22 && bands.long[sfb] < longEnd; sfb++ {
+		scalefac := sf.long[sfb]
+		if g.preflag {
+			scalefac += mp3Pretab[sfb]
+		}
+		dequantize(bands.long[sfb], min(bands.long[sfb+1], longEnd), gain-multiplier*float64(scalefac))
+	}
+	if g.blockType != 2 {
+		return
+	}
+
+	sfb := 0
+	if g.mixedBlock {
+		sfb = 3
+	}
+	for ; sfb < 13; sfb++ {
+		width := bands.short[sfb+1] - bands.short[sfb]
+		for w := 0; w < 3; w++ {
+			start := 3*bands.short[sfb] + w*width
+			exponent := gain - 2*float64(g.subblockGain[w]) - multiplier*float64(sf.short[sfb][w])
+			dequantize(start, start+width, exponent)
+		}
+	}
+}
+
+// jointStereo restores left and right from mid/side and intensity coded
+// channels; g is the side information of the right channel
+func (s *mp3Stream) jointStereo(h mp3Header, g *mp3Granule, xr *[2][576]float64) {
+	midSide := h.modeExt&2 != 0
+	plain := func(start, end int) {
+		if !midSide {
+			return
+		}
+		for i := start; i < end; i++ {
+			m, d := xr[0][i], xr[1][i]
+			xr[0][i] = (m + d) * math.Sqrt2 / 2
+			xr[1][i] = (m - d) * math.Sqrt2 / 2
+		}
+	}
+	if h.modeExt&1 == 0 {
+		plain(0, 576)
+		return
+	}
+
+	// Intensity stereo codes the bands above the last nonzero value of the
+	// right channel as the left channel and a position; the largest position
+	// marks a band coded as plain stereo
+	sf := &s.scalefacs[1]
+	zero := func(start, end int) bool {
+		for i := start; i < end; i++ {
+			if xr[1][i] != 0 {
+				return false
+			}
+		}
+		return true
+	}
+	intensity := func(start, end, position, illegal int) {
+		if h.version == 0 {
+			illegal = 7
+		}
+		if position == illegal {
+			plain(start, end)
+			return
+		}
+		kl, kr := intensityGains(h, position, sf.intensityScale)
+		for i := start; i < end; i++ {
+			v := xr[0][i]
+			xr[0][i] = v * kl
+			xr[1][i] = v * kr
+		}
+	}
+	// longBands handles the long blocks below line end; bands past 20 take
+	// the position of band 20
+	longBands := func(end int) {
+		bands := &mp3BandTables[h.rateIndex].long
+		count := 0
+		for count < 22 && bands[count] < end {
+			count++
+		}
+		bound := count
+		for bound > 0 && zero(bands[bound-1], min(bands[bound], end)) {
+			bound--
+		}
+		plain(0, bands[bound])
+		for sfb := bound; sfb < count; sfb++ {
+			ref := min(sfb, 20)
+			intensity(bands[sfb], min(bands[sfb+1], end), sf.long[ref], sf.intensityLong[ref])
+		}
+	}
+
+	if g.blockType != 2 {
+		longBands(576)
+		return
+	}
+
+	bands := &mp3BandTables[h.rateIndex].short
+	first := 0
+	if g.mixedBlock {
+		first = 3
+	}
+	allZero := true
+	for w := 0; w < 3; w++ {
+		line := func(sfb int) int {
+			return 3*bands[sfb] + w*(bands[sfb+1]-bands[sfb])
+		}
+		bound := 13
+		for bound > first && zero(line(bound-1), line(bound-1)+bands[bound]-bands[bound-1]) {
+			bound--
+		}
+		if bound > first {
+			allZero = false
+		}
+		for sfb := first; sfb < 13; sfb++ {
+			start, end := line(sfb), line(sfb)+bands[sfb+1]-bands[sfb]
+			if sfb < bound {
+				plain(start, end)
+				continue
+			}
+			ref := min(sfb, 11)
+			intensity(start, end, sf.short[ref][w], sf.intensityShort[ref])
+		}
+	}
+	if g.mixedBlock {
+		if allZero {
+			longBands(36)
+		} else {
+			plain(0, 36)
+		}
+	}
+}
+
+// intensityGains returns the gains of the left and right channel for an
+// intensity stereo position
+func intensityGains(h mp3Header, position, scale int) (float64, float64) {
+	if h.version == 0 {
+		sin, cos := math.Sincos(float64(position) * math.Pi / 12)
+		return sin / (sin + cos), cos / (sin + cos)
+	}
+	ratio := math.Pow(2, -0.25)
+	if scale == 1 {
+		ratio = math.Sqrt2 / 2
+	}
+	if position%2 == 1 {
+		return math.Pow(ratio, float64((position+1)/2)), 1
+	}
+	return 1, math.Pow(ratio, float64(position/2))
+}
+
+// hybrid turns the spectrum of a channel into 18 samples of each of the 32
+// subbands, at xr[subband*18+t]: short blocks are reordered, aliasing is
+// reduced and the IMDCT output is overlapped with that of the last granule
+func (s *mp3Stream) hybrid(h mp3Header, g *mp3Granule, ch int, xr *[576]float64) {
+	short := g.blockType == 2
+	if short {
+		bands := &mp3BandTables[h.rateIndex].short
+		reordered := *xr
+		sfb := 0
+		if g.mixedBlock {
+			sfb = 3
+		}
+		for ; sfb < 13; sfb++ {
+			start, width := 3*bands[sfb], bands[sfb+1]-bands[sfb]
+			for w := 0; w < 3; w++ {
+				for k := 0; k < width; k++ {
+					reordered[start+3*k+w] = xr[start+w*width+k]
+				}
+			}
+		}
+		*xr = reordered
+	}
+
+	aliased := 32
+	if short {
+		aliased = 0
+		if g.mixedBlock {
+			aliased = 2
+		}
+	}
+	for sb := 1; sb < aliased; sb++ {
+		for i := 0; i < 8; i++ {
+			lo, hi := 18*sb-1-i, 18*sb+i
+			a, b := xr[lo], xr[hi]
+			xr[lo] = a*mp3AliasCS[i] - b*mp3AliasCA[i]
+			xr[hi] = b*mp3AliasCS[i] + a*mp3AliasCA[i]
+		}
+	}
+
+	for sb := 0; sb < 32; sb++ {
+		lines := xr[sb*18 : sb*18+18]
+		var y [36]float64
+		if short && (!g.mixedBlock || sb >= 2) {
+			for w := 0; w < 3; w++ {
+				for i := 0; i < 12; i++ {
+					sum := 0.0
+					for k := 0; k < 6; k++ {
+						sum += lines[3*k+w] * mp3ShortCos[i][k]
+					}
+					y[6+6*w+i] += sum * mp3BlockWindow[2][i]
+				}
+			}
+		} else {
+			window := &mp3BlockWindow[g.blockType]
+			if short {
+				// The two lower subbands of mixed blocks are long
+				window = &mp3BlockWindow[0]
+			}
+			for i := 0; i < 36; i++ {
+				sum := 0.0
+				for k := 0; k < 18; k++ {
+					sum += lines[k] * mp3LongCos[i][k]
+				}
+				y[i] = sum * window[i]
+			}
+		}
+
+		overlap := s.overlap[ch][sb*18 : sb*18+18]
+		for t := 0; t < 18; t++ {
+			v := y[t] + overlap[t]
+			overlap[t] = y[t+18]
+			// Odd subbands are frequency inverted
+			if sb%2 == 1 && t%2 == 1 {
+				v = -v
+			}
+			lines[t] = v
+		}
+	}
+}
+
+// emit converts the n decoded samples of each channel to PCM in s.pcm,
+// dropping the delay at the start and the padding at the end
+func (s *mp3Stream) emit(n, channels int) {
+	start := 0
+	if s.skip > 0 {
+		start = int(min(s.skip, int64(n)))
+		s.skip -= int64(start)
+	}
+	end := n
+	if s.left >= 0 {
+		end = start + int(min(s.left, int64(n-start)))
+		s.left -= int64(end - start)
+	}
+
+	out := s.out[:0]
+	for i := start; i < end; i++ {
+		for ch := 0; ch < channels; ch++ {
+			v := math.Round(s.samples[ch][i] * 32768)
+			v = max(-32768, min(32767, v))
+			out = binary.LittleEndian.AppendUint16(out, uint16(int16(v)))
+		}
+	}
+	s.out, s.pcm = out, out
+}
+
+// mp3Synthesis is the polyphase synthesis filterbank of one channel
+type mp3Synthesis struct {
+	v      [1024]float64
+	offset int // where v starts in the ring
+}
+
+// synthesize turns the 18 samples of each subband in xr into 576 samples
+func (f *mp3Synthesis) synthesize(xr *[576]float64, out []float64) {
+	for t := 0; t < 18; t++ {
+		f.offset = (f.offset - 64) & 1023
+		for i := 0; i < 64; i++ {
+			sum := 0.0
+			for k := 0; k < 32; k++ {
+				sum += mp3SynthCos[i][k] * xr[k*18+t]
+			}
+			f.v[(f.offset+i)&1023] = sum
+		}
+		for j := 0; j < 32; j++ {
+			sum := 0.0
+			for i := 0; i < 8; i++ {
+				sum += f.v[(f.offset+128*i+j)&1023] * mp3Window[64*i+j]
+				sum += f.v[(f.offset+128*i+96+j)&1023] * mp3Window[64*i+32+j]
+			}
+			out[t*32+j] = sum
+		}
+	}
+}
+
+// mp3HuffmanTree decodes a Huffman code bit by bit. Each node holds its two
+// children: node indices, or for leaves the value index negated minus one.
+type mp3HuffmanTree [][2]int32
+
+func newMP3HuffmanTree(code mp3HuffmanCode) mp3HuffmanTree {
+	tree := mp3HuffmanTree{{}}
+	for value, length := range code.lengths {
+		node := 0
+		for bit := int(length) - 1; bit >= 0; bit-- {
+			b := code.codes[value] >> uint(bit) & 1
+			if bit == 0 {
+				tree[node][b] = -int32(value) - 1
+				break
+			}
+			if tree[node][b] <= 0 {
+				tree = append(tree, [2]int32{})
+				tree[node][b] = int32(len(tree) - 1)
+			}
+			node = int(tree[node][b])
+		}
+	}
+	return tree
+}
+
+// decode reads one code, returning 0 for a code the table lacks
+func (t mp3HuffmanTree) decode(br *mp3BitReader) int {
+	node := 0
+	for {
+		next := t[node][br.read(1)]
+		if next < 0 {
+			return int(-next - 1)
+		}
+		if next == 0 {
+			return 0
+		}
+		node = int(next)
+	}
+}
+
+// mp3BitReader reads a big-endian bit stream from memory, reading zeros past
+// its end
+type mp3BitReader struct {
+	data []byte
+	pos  int // in bits
+}
+
+// read returns the next n bits
+func (br *mp3BitReader) read(n int) int {
+	v := 0
+	for ; n > 0; n-- {
+		bit := 0
+		if i := br.pos >> 3; i < len(br.data) {
+			bit = int(br.data[i]>>uint(7-br.pos&7)) & 1
+		}
+		v = v<<1 | bit
+		br.pos++
+	}
+	return v
+}
@@ -0,0 +1,850 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestMP3HuffmanCodesComplete(t *testing.T) {
+	codes := map[string]mp3HuffmanCode{"count1": mp3Count1Code}
+	for i, code := range mp3HuffmanCodes {
+		if code.codes != nil {
+			codes[fmt.Sprint(i)] = code
+		}
+	}
+	for name, code := range codes {
+		tree := newMP3HuffmanTree(code)
+		// A complete prefix code fills the code space exactly
+		var space uint64
+		for _, length := range code.lengths {
+			space += 1 << (32 - uint(length))
+		}
+		if space != 1<<32 {
+			t.Errorf("table %s: code space %d, want %d", name, space, uint64(1)<<32)
+		}
+		for value := range code.lengths {
+			w := &mp3TestBitWriter{}
+			w.write(int(code.codes[value]), int(code.lengths[value]))
+			br := &mp3BitReader{data: w.data}
+			if got := tree.decode(br); got != value || br.pos != int(code.lengths[value]) {
+				t.Errorf("table %s: value %d decodes as %d after %d bits", name, value, got, br.pos)
+			}
+		}
+	}
+}
+
+func TestDecodeMP3RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		config mp3TestConfig
+	}{
+		{"mpeg1_joint_stereo", mp3TestConfig{sampleRate: 44100, channels: 2, bitrate: 320, mode: mp3ModeJoint, modeExt: 2, scfsi: true, preflag: true, crc: true}},
+		{"mpeg1_mono_block_switching", mp3TestConfig{sampleRate: 32000, channels: 1, bitrate: 256, blockTypes: []int{0, 1, 2, 2, 2, 3}}},
+		{"mpeg1_intensity_stereo", mp3TestConfig{sampleRate: 48000, channels: 2, bitrate: 320, mode: mp3ModeJoint, modeExt: 3, intensityBand: 12}},
+		{"mpeg2_stereo", mp3TestConfig{sampleRate: 22050, channels: 2, bitrate: 160}},
+		{"mpeg2_mono_block_switching", mp3TestConfig{sampleRate: 24000, channels: 1, bitrate: 128, blockTypes: []int{0, 1, 2, 2, 3, 0}}},
+		{"mpeg2_intensity_stereo", mp3TestConfig{sampleRate: 16000, channels: 2, bitrate: 160, mode: mp3ModeJoint, modeExt: 1, intensityBand: 10}},
+		{"mpeg25_mono", mp3TestConfig{sampleRate: 8000, channels: 1, bitrate: 64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := mp3TestSignal(tt.config, 5000)
+			data := encodeMP3ForTest(t, tt.config, input, true)
+
+			s, err := NewStream(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("NewStream() error = %v", err)
+			}
+			want := Format{SampleRate: tt.config.sampleRate, Channels: tt.config.channels, BitsPerSample: 16}
+			if s.Format() != want {
+				t.Errorf("format = %+v, want %+v", s.Format(), want)
+			}
+			if s.TotalFrames() != int64(len(input[0])) {
+				t.Errorf("TotalFrames() = %d, want %d", s.TotalFrames(), len(input[0]))
+			}
+			decoded, err := DecodeMP3(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("DecodeMP3() error = %v", err)
+			}
+			if frames := len(decoded.Data) / 2 / tt.config.channels; frames != len(input[0]) {
+				t.Fatalf("decoded %d frames, want %d", frames, len(input[0]))
+			}
+			for ch := range input {
+				if snr := mp3TestSNR(input[ch], decoded.Data, ch, tt.config.channels); snr < 35 {
+					t.Errorf("channel %d: SNR = %.1f dB, want at least 35", ch, snr)
+				}
+			}
+		})
+	}
+}
+
+func TestNewStreamMP3(t *testing.T) {
+	config := mp3TestConfig{sampleRate: 44100, channels: 1, bitrate: 128}
+	input := mp3TestSignal(config, 3000)
+	frames := encodeMP3ForTest(t, config, input, false)
+
+	// Without an Info frame the length is unknown and the delay stays in.
+	// 128 kb/s frames at 44.1 kHz take 417 bytes.
+	tagged := append([]byte{'I', 'D', '3', 4, 0, 0, 0, 0, 0, 6, 'T', 'I', 'T', '2', 0, 0}, frames...)
+	for name, data := range map[string][]byte{"frames": frames, "id3": tagged} {
+		s, err := NewStream(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: NewStream() error = %v", name, err)
+		}
+		if s.TotalFrames() != -1 {
+			t.Errorf("%s: TotalFrames() = %d, want -1", name, s.TotalFrames())
+		}
+		decoded, err := readAllPCM(s)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, want := len(decoded)/2, len(frames)/417*1152; got != want {
+			t.Errorf("%s: decoded %d frames, want %d", name, got, want)
+		}
+		if snr := mp3TestSNR(input[0], decoded[2*mp3TestDelay:], 0, 1); snr < 35 {
+			t.Errorf("%s: SNR = %.1f dB, want at least 35", name, snr)
+		}
+	}
+
+	// Junk between frames is skipped
+	size := len(frames) / 417 / 3 * 417
+	junk := append(append(append([]byte(nil), frames[:size]...), 0xFF, 0x00, 'x'), frames[size:]...)
+	s, err := NewStream(bytes.NewReader(junk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readAllPCM(s); err != nil {
+		t.Errorf("decoding with junk between frames: %v", err)
+	}
+
+	// Layer II is not decoded
+	layer2 := []byte{0xFF, 0xFD, 0x90, 0x00}
+	if _, err := NewStream(bytes.NewReader(layer2)); err != ErrUnsupportedFormat {
+		t.Errorf("Layer II error = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func readAllPCM(s Stream) ([]byte, error) {
+	var out bytes.Buffer
+	_, err := out.ReadFrom(s)
+	return out.Bytes(), err
+}
+
+// mp3TestDelay is the delay of the test encoder and the decoder together
+const mp3TestDelay = 1057
+
+// mp3TestSNR compares a channel of interleaved 16-bit PCM with the input
+func mp3TestSNR(input []float64, pcm []byte, ch, channels int) float64 {
+	var signal, noise float64
+	for i, want := range input {
+		at := 2 * (i*channels + ch)
+		if at+2 > len(pcm) {
+			break
+		}
+		got := float64(int16(binary.LittleEndian.Uint16(pcm[at:]))) / 32768
+		signal += want * want
+		noise += (got - want) * (got - want)
+	}
+	return 10 * math.Log10(signal/noise)
+}
+
+// mp3TestSignal returns a few tones per channel. Above the lowest they are
+// the same in both channels when those are intensity coded.
+func mp3TestSignal(config mp3TestConfig, frames int) [][]float64 {
+	signal := make([][]float64, config.channels)
+	for ch := range signal {
+		signal[ch] = make([]float64, frames)
+		phase := float64(ch)
+		if config.intensityBand > 0 {
+			phase = 0
+		}
+		for i := range signal[ch] {
+			// In cycles per sample
+			x := 2 * math.Pi * float64(i)
+			signal[ch][i] = 0.3*math.Sin(x*(0.011+0.004*float64(ch))) +
+				0.2*math.Sin(x*0.07+phase) +
+				0.1*math.Sin(x*0.23)
+		}
+	}
+	return signal
+}
+
+// mp3TestConfig selects the features the test encoder uses
+type mp3TestConfig struct {
+	sampleRate    int
+	channels      int
+	bitrate       int
+	mode          int // header mode; stereo when 0 for two channels
+	modeExt       int
+	blockTypes    []int // cycled through by granule; long blocks when empty
+	intensityBand int   // first long band coded as intensity stereo
+	scfsi         bool  // share MPEG-1 scalefactors between granules
+	preflag       bool
+	crc           bool
+}
+
+// encodeMP3ForTest is a minimal Layer III encoder: it quantizes each
+// granule finely enough to pass the round trip, with no psychoacoustics.
+// With info it starts with an Info frame carrying the LAME gapless fields.
+func encodeMP3ForTest(t *testing.T, config mp3TestConfig, input [][]float64, info bool) []byte {
+	t.Helper()
+	e := &mp3TestEncoder{config: config}
+	h := mp3Header{bitrate: config.bitrate, sampleRate: config.sampleRate, mode: config.mode, modeExt: config.modeExt, crc: config.crc}
+	for version, rates := range mp3SampleRates {
+		for i, rate := range rates {
+			if rate == config.sampleRate {
+				h.version, h.rateIndex = version, version*3+i
+			}
+		}
+	}
+	if config.channels == 1 {
+		h.mode = mp3ModeMono
+	}
+	e.header = h
+
+	samplesPerFrame := 576 * h.granules()
+	frames := (len(input[0]) + mp3TestDelay + samplesPerFrame - 1) / samplesPerFrame
+	padded := make([][]float64, config.channels)
+	for ch := range padded {
+		padded[ch] = make([]float64, frames*samplesPerFrame)
+		copy(padded[ch], input[ch])
+	}
+
+	var out bytes.Buffer
+	if info {
+		frame := make([]byte, h.frameSize())
+		e.writeHeader(frame)
+		at := h.sideInfoOffset() + h.sideInfoSize()
+		copy(frame[at:], "Info")
+		binary.BigEndian.PutUint32(frame[at+4:], 1)
+		binary.BigEndian.PutUint32(frame[at+8:], uint32(frames))
+		copy(frame[at+12:], "LAME3.100")
+		delay := mp3TestDelay - mp3DecoderDelay
+		padding := frames*samplesPerFrame - delay - len(input[0])
+		frame[at+12+21] = byte(delay >> 4)
+		frame[at+12+22] = byte(delay<<4 | padding>>8)
+		frame[at+12+23] = byte(padding)
+		out.Write(frame)
+	}
+
+	// Main data goes to a stream of its own, from which each frame carries
+	// the bytes after its side information
+	var (
+		mainData []byte
+		starts   []int
+	)
+	maxBegin := 511
+	if h.version > 0 {
+		maxBegin = 255
+	}
+	slot := h.frameSize() - h.sideInfoOffset() - h.sideInfoSize()
+	// Leave room for the scalefactors, within the 12 bits of part2_3_length
+	e.budget = min(slot*8/(h.granules()*h.channels()), 4095) - 150
+	sides := make([][]byte, frames)
+	for f := 0; f < frames; f++ {
+		side, data := e.encodeFrame(padded, f*samplesPerFrame)
+		slotStart := f * slot
+		start := max(len(mainData), slotStart-maxBegin)
+		if start+len(data) > slotStart+slot {
+			t.Fatalf("frame %d needs %d bytes, has %d", f, len(data), slotStart+slot-start)
+		}
+		for len(mainData) < start {
+			mainData = append(mainData, 0)
+		}
+		mainData = append(mainData, data...)
+		starts = append(starts, slotStart-start)
+		sides[f] = side
+	}
+	for len(mainData) < frames*slot {
+		mainData = append(mainData, 0)
+	}
+	for f := 0; f < frames; f++ {
+		frame := make([]byte, h.sideInfoOffset(), h.frameSize())
+		e.writeHeader(frame)
+		side := sides[f]
+		// main_data_begin leads the side information
+		w := &mp3TestBitWriter{}
+		if h.version == 0 {
+			w.write(starts[f], 9)
+		} else {
+			w.write(starts[f], 8)
+		}
+		w.append(side, e.sideBits)
+		frame = append(frame, w.data...)
+		frame = append(frame, mainData[f*slot:(f+1)*slot]...)
+		out.Write(frame)
+	}
+	return out.Bytes()
+}
+
+// mp3TestEncoder holds the filterbank state of the test encoder
+type mp3TestEncoder struct {
+	config   mp3TestConfig
+	header   mp3Header
+	analysis [2][512]float64
+	previous [2][32][18]float64 // subband samples of the last granule
+	granule  int
+	budget   int // bits for the spectrum of a channel in a granule
+	sideBits int
+}
+
+func (e *mp3TestEncoder) writeHeader(frame []byte) {
+	h := e.header
+	versionBits := [3]byte{3, 2, 0}[h.version]
+	bitrateIndex := 0
+	for i, rate := range mp3Bitrates[min(h.version, 1)] {
+		if rate == h.bitrate {
+			bitrateIndex = i
+		}
+	}
+	frame[0] = 0xFF
+	frame[1] = 0xE0 | versionBits<<3 | 1<<1
+	if !h.crc {
+		frame[1] |= 1
+	}
+	frame[2] = byte(bitrateIndex<<4 | h.rateIndex%3<<2)
+	frame[3] = byte(h.mode<<6 | h.modeExt<<4)
+}
+
+// encodeFrame encodes the frame starting at sample offset, returning its
+// side information after main_data_begin and its main data
+func (e *mp3TestEncoder) encodeFrame(input [][]float64, offset int) ([]byte, []byte) {
+	h := e.header
+	channels := h.channels()
+	var (
+		side     mp3SideInfo
+		scale    [2][2]mp3Scalefactors
+		values   [2][2][576]int
+		compress [2][2]int
+	)
+	for gr := 0; gr < h.granules(); gr++ {
+		blockType := 0
+		if len(e.config.blockTypes) > 0 {
+			blockType = e.config.blockTypes[e.granule%len(e.config.blockTypes)]
+		}
+		e.granule++
+
+		var xr [2][576]float64
+		for ch := 0; ch < channels; ch++ {
+			e.transform(input[ch][offset+gr*576:], ch, blockType, &xr[ch])
+		}
+		intensity := h.mode == mp3ModeJoint && h.modeExt&1 != 0
+		bands := &mp3BandTables[h.rateIndex].long
+		for i := 0; i < 576; i++ {
+			l, r := xr[0][i], xr[1][i]
+			switch {
+			case intensity && i >= bands[e.config.intensityBand] && h.version == 0:
+				// Equal channels: the left carries the sum, halved again
+				// by the position
+				xr[0][i], xr[1][i] = l+r, 0
+			case intensity && i >= bands[e.config.intensityBand]:
+				xr[0][i], xr[1][i] = l, 0
+			case h.mode == mp3ModeJoint && h.modeExt&2 != 0:
+				xr[0][i], xr[1][i] = (l+r)*math.Sqrt2/2, (l-r)*math.Sqrt2/2
+			}
+		}
+
+		for ch := 0; ch < channels; ch++ {
+			g := &side.granules[gr][ch]
+			g.blockType = blockType
+			g.windowSwitching = blockType != 0
+			g.preflag = e.config.preflag && blockType == 0 && h.version == 0
+			g.scalefacScale = ch == 1
+			if blockType == 2 {
+				g.subblockGain = [3]int{0, 1, 2}
+			}
+			sf := &scale[gr][ch]
+			compress[gr][ch] = e.scalefactors(g, sf, ch, intensity && ch == 1)
+			g.scalefacCompress = compress[gr][ch]
+			e.quantize(g, sf, &xr[ch], &values[gr][ch], intensity && ch == 1)
+		}
+	}
+
+	// Side information, without main_data_begin
+	w := &mp3TestBitWriter{}
+	if h.version == 0 {
+		w.write(0, 7-2*channels)
+		for ch := 0; ch < channels; ch++ {
+			for band := 0; band < 4; band++ {
+				if e.config.scfsi {
+					w.write(1, 1)
+				} else {
+					w.write(0, 1)
+				}
+			}
+		}
+	} else {
+		w.write(0, channels)
+	}
+	main := &mp3TestBitWriter{}
+	for gr := 0; gr < h.granules(); gr++ {
+		for ch := 0; ch < channels; ch++ {
+			g := &side.granules[gr][ch]
+			start := main.n
+			e.writeScalefactors(main, g, &scale[gr][ch], gr, ch)
+			writeMP3TestSpectrum(main, h, g, &values[gr][ch])
+			g.part23Length = main.n - start
+
+			w.write(g.part23Length, 12)
+			w.write(g.bigValues, 9)
+			w.write(g.globalGain, 8)
+			if h.version == 0 {
+				w.write(g.scalefacCompress, 4)
+			} else {
+				w.write(g.scalefacCompress, 9)
+			}
+			if g.windowSwitching {
+				w.write(1, 1)
+				w.write(g.blockType, 2)
+				w.write(0, 1)
+				w.write(g.tableSelect[0], 5)
+				w.write(g.tableSelect[1], 5)
+				for _, gain := range g.subblockGain {
+					w.write(gain, 3)
+				}
+			} else {
+				w.write(0, 1)
+				for _, sel := range g.tableSelect {
+					w.write(sel, 5)
+				}
+				w.write(g.region0Count, 4)
+				w.write(g.region1Count, 3)
+			}
+			if h.version == 0 {
+				w.write(boolBit(g.preflag), 1)
+			}
+			w.write(boolBit(g.scalefacScale), 1)
+			w.write(g.count1Table, 1)
+		}
+	}
+	e.sideBits = w.n
+	return w.data, main.data
+}
+
+// transform runs the analysis filterbank and the MDCT over a granule of one
+// channel, producing its spectrum in the order of the bitstream
+func (e *mp3TestEncoder) transform(input []float64, ch, blockType int, xr *[576]float64) {
+	var current [32][18]float64
+	x := &e.analysis[ch]
+	for t := 0; t < 18; t++ {
+		copy(x[32:], x[:480])
+		for i := 0; i < 32; i++ {
+			x[i] = input[t*32+31-i]
+		}
+		var y [64]float64
+		for i := range y {
+			for j := 0; j < 8; j++ {
+				y[i] += x[i+64*j] * mp3Window[i+64*j] / 32
+			}
+		}
+		for sb := 0; sb < 32; sb++ {
+			sum := 0.0
+			for i, v := range y {
+				sum += math.Cos(float64((2*sb+1)*(i-16))*math.Pi/64) * v
+			}
+			if sb%2 == 1 && t%2 == 1 {
+				sum = -sum
+			}
+			current[sb][t] = sum
+		}
+	}
+
+	short := &mp3BandTables[e.header.rateIndex].short
+	for sb := 0; sb < 32; sb++ {
+		var z [36]float64
+		copy(z[:18], e.previous[ch][sb][:])
+		copy(z[18:], current[sb][:])
+		if blockType != 2 {
+			for k := 0; k < 18; k++ {
+				sum := 0.0
+				for n, v := range z {
+					sum += mp3BlockWindow[blockType][n] * v * mp3LongCos[n][k]
+				}
+				xr[sb*18+k] = sum * 2 / 18
+			}
+			continue
+		}
+		for w := 0; w < 3; w++ {
+			for k := 0; k < 6; k++ {
+				sum := 0.0
+				for n := 0; n < 12; n++ {
+					sum += mp3BlockWindow[2][n] * z[6+6*w+n] * mp3ShortCos[n][k]
+				}
+				line := sb*6 + k
+				sfb := 0
+				for short[sfb+1] <= line {
+					sfb++
+				}
+				width := short[sfb+1] - short[sfb]
+				xr[3*short[sfb]+w*width+line-short[sfb]] = sum * 2 / 6
+			}
+		}
+	}
+	e.previous[ch] = current
+
+	if blockType != 2 {
+		for sb := 1; sb < 32; sb++ {
+			for i := 0; i < 8; i++ {
+				lo, hi := 18*sb-1-i, 18*sb+i
+				a, b := xr[lo], xr[hi]
+				xr[lo] = a*mp3AliasCS[i] + b*mp3AliasCA[i]
+				xr[hi] = b*mp3AliasCS[i] - a*mp3AliasCA[i]
+			}
+		}
+	}
+}
+
+// scalefactors picks arbitrary scalefactors for a channel and returns the
+// matching scalefac_compress. The right channel of intensity stereo carries
+// the position of equal channels in the intensity bands instead.
+func (e *mp3TestEncoder) scalefactors(g *mp3Granule, sf *mp3Scalefactors, ch int, intensity bool) int {
+	h := e.header
+	bands := e.config.intensityBand
+	if h.version == 0 {
+		for sfb := 0; sfb < 21; sfb++ {
+			sf.long[sfb] = (sfb*5 + ch) % 8
+			// Below the intensity bands the largest position, 7, marks
+			// plain stereo; tan(3π/12) = 1 makes equal channels
+			if intensity && sfb < bands {
+				sf.long[sfb] = 7
+			} else if intensity {
+				sf.long[sfb] = 3
+			}
+		}
+		for sfb := 0; sfb < 12; sfb++ {
+			for w := 0; w < 3; w++ {
+				sf.short[sfb][w] = (sfb + 2*w) % 8
+			}
+		}
+		return 15 // slen 4 and 3
+	}
+
+	if intensity {
+		// slen 2, 2 and 2: 3 marks plain stereo and 0 equal channels
+		for sfb := 0; sfb < 21; sfb++ {
+			sf.long[sfb] = 3
+			if sfb >= bands {
+				sf.long[sfb] = 0
+			}
+		}
+		return (2*36 + 2*6 + 2) << 1
+	}
+	for sfb := 0; sfb < 21; sfb++ {
+		sf.long[sfb] = (sfb*5 + ch) % 4
+	}
+	for sfb := 0; sfb < 12; sfb++ {
+		for w := 0; w < 3; w++ {
+			sf.short[sfb][w] = (sfb + 2*w) % 4
+		}
+	}
+	return (3*5+3)<<4 | 2<<2 | 2 // slen 3, 3, 2 and 2
+}
+
+func (e *mp3TestEncoder) writeScalefactors(w *mp3TestBitWriter, g *mp3Granule, sf *mp3Scalefactors, gr, ch int) {
+	h := e.header
+	if h.version == 0 {
+		slen1 := mp3ScalefacLengths[0][g.scalefacCompress]
+		slen2 := mp3ScalefacLengths[1][g.scalefacCompress]
+		if g.blockType == 2 {
+			for sfb := 0; sfb < 12; sfb++ {
+				for win := 0; win < 3; win++ {
+					if sfb < 6 {
+						w.write(sf.short[sfb][win], slen1)
+					} else {
+						w.write(sf.short[sfb][win], slen2)
+					}
+				}
+			}
+			return
+		}
+		if gr == 1 && e.config.scfsi {
+			return
+		}
+		for sfb := 0; sfb < 21; sfb++ {
+			if sfb < 11 {
+				w.write(sf.long[sfb], slen1)
+			} else {
+				w.write(sf.long[sfb], slen2)
+			}
+		}
+		return
+	}
+
+	intensity := ch == 1 && h.mode == mp3ModeJoint && h.modeExt&1 != 0
+	var slen, counts [4]int
+	if intensity {
+		slen = [4]int{2, 2, 2, 0}
+		counts = mp3LSFBandCounts[3][0]
+	} else {
+		slen = [4]int{3, 3, 2, 2}
+		counts = mp3LSFBandCounts[0][0]
+		if g.blockType == 2 {
+			counts = mp3LSFBandCounts[0][1]
+		}
+	}
+	i := 0
+	for part, count := range counts {
+		for k := 0; k < count; k++ {
+			if g.blockType == 2 {
+				w.write(sf.short[i/3][i%3], slen[part])
+			} else {
+				w.write(sf.long[i], slen[part])
+			}
+			i++
+		}
+	}
+}
+
+// quantize picks the finest global gain whose spectrum fits the budget
+func (e *mp3TestEncoder) quantize(g *mp3Granule, sf *mp3Scalefactors, xr *[576]float64, values *[576]int, intensity bool) {
+	h := e.header
+	bands := &mp3BandTables[h.rateIndex]
+	// The scale of each line apart from the global gain
+	var exponent [576]float64
+	multiplier := 0.5
+	if g.scalefacScale {
+		multiplier = 1
+	}
+	if g.blockType == 2 {
+		for sfb := 0; sfb < 13; sfb++ {
+			width := bands.short[sfb+1] - bands.short[sfb]
+			for w := 0; w < 3; w++ {
+				start := 3*bands.short[sfb] + w*width
+				for i := start; i < start+width; i++ {
+					exponent[i] = -2*float64(g.subblockGain[w]) - multiplier*float64(sf.short[sfb][w])
+				}
+			}
+		}
+	} else {
+		for sfb := 0; sfb < 22; sfb++ {
+			scalefac := sf.long[sfb]
+			if g.preflag {
+				scalefac += mp3Pretab[sfb]
+			}
+			for i := bands.long[sfb]; i < bands.long[sfb+1]; i++ {
+				exponent[i] = -multiplier * float64(scalefac)
+			}
+		}
+	}
+
+	spectrum := func(gain int) bool {
+		for i, v := range xr {
+			q := math.Pow(math.Abs(v)/math.Exp2(float64(gain-210)/4+exponent[i]), 0.75)
+			if q > 1000 {
+				return false
+			}
+			values[i] = int(math.Round(q))
+			if v < 0 {
+				values[i] = -values[i]
+			}
+		}
+		planMP3TestSpectrum(h, g, values)
+		w := &mp3TestBitWriter{}
+		writeMP3TestSpectrum(w, h, g, values)
+		return w.n <= e.budget
+	}
+	lo, hi := 0, 255
+	for lo < hi {
+		if gain := (lo + hi) / 2; spectrum(gain) {
+			hi = gain
+		} else {
+			lo = gain + 1
+		}
+	}
+	g.globalGain = lo
+	spectrum(lo)
+}
+
+// mp3TestTableLimit is the largest value each table_select can code
+func mp3TestTableLimit(sel int) int {
+	table := mp3TableSelect[sel]
+	if table.linbits > 0 {
+		return 15 + 1<<uint(table.linbits) - 1
+	}
+	return mp3HuffmanCodes[table.table].wrap - 1
+}
+
+// planMP3TestSpectrum sets the regions of a spectrum and picks the cheapest
+// table for each
+func planMP3TestSpectrum(h mp3Header, g *mp3Granule, values *[576]int) {
+	last, lastBig := 0, 0
+	for i, v := range values {
+		if v != 0 {
+			last = i + 1
+		}
+		if v > 1 || v < -1 {
+			lastBig = i + 1
+		}
+	}
+	bigEnd := (lastBig + 1) &^ 1
+	if bigEnd+(last-bigEnd+3)&^3 > 576 {
+		bigEnd = (last + 1) &^ 1
+	}
+	g.bigValues = bigEnd / 2
+
+	bands := &mp3BandTables[h.rateIndex]
+	regions := [4]int{0, 0, 0, bigEnd}
+	if g.windowSwitching {
+		regions[1] = min(36, bigEnd)
+		if g.blockType != 2 {
+			regions[1] = min(bands.long[8], bigEnd)
+		}
+		regions[2] = bigEnd
+	} else {
+		g.region0Count, g.region1Count = 7, 5
+		regions[1] = min(bands.long[8], bigEnd)
+		regions[2] = min(bands.long[14], bigEnd)
+	}
+	for r := 0; r < 3; r++ {
+		best, bestBits := 0, math.MaxInt
+		for sel := 0; sel < 32; sel++ {
+			table := mp3TableSelect[sel]
+			if sel == 4 || sel == 14 || sel == 0 && regions[r] < regions[r+1] {
+				continue
+			}
+			bits, ok := 0, true
+			for i := regions[r]; i < regions[r+1]; i++ {
+				v := values[i]
+				if v < 0 {
+					v = -v
+				}
+				if v > mp3TestTableLimit(sel) {
+					ok = false
+					break
+				}
+			}
+			if !ok {
+				continue
+			}
+			for i := regions[r]; i < regions[r+1] && sel != 0; i += 2 {
+				w := &mp3TestBitWriter{}
+				writeMP3TestPair(w, table.table, table.linbits, values[i], values[i+1])
+				bits += w.n
+			}
+			if bits < bestBits {
+				best, bestBits = sel, bits
+			}
+		}
+		g.tableSelect[r] = best
+	}
+
+	var bitsA, bitsB int
+	for i := bigEnd; i < last; i += 4 {
+		index := 0
+		for k := 0; k < 4; k++ {
+			if values[i+k] != 0 {
+				index |= 8 >> uint(k)
+			}
+		}
+		bitsA += int(mp3Count1Code.lengths[index])
+		bitsB += 4
+	}
+	if bitsB < bitsA {
+		g.count1Table = 1
+	}
+}
+
+func writeMP3TestSpectrum(w *mp3TestBitWriter, h mp3Header, g *mp3Granule, values *[576]int) {
+	bigEnd := g.bigValues * 2
+	bands := &mp3BandTables[h.rateIndex]
+	region1, region2 := bands.long[min(g.region0Count+1, 22)], bands.long[min(g.region0Count+g.region1Count+2, 22)]
+	if g.windowSwitching {
+		region1, region2 = 36, 576
+		if g.blockType != 2 {
+			region1 = bands.long[8]
+		}
+	}
+	for i := 0; i < bigEnd; i += 2 {
+		region := 0
+		if i >= region2 {
+			region = 2
+		} else if i >= region1 {
+			region = 1
+		}
+		table := mp3TableSelect[g.tableSelect[region]]
+		if table.table != 0 {
+			writeMP3TestPair(w, table.table, table.linbits, values[i], values[i+1])
+		}
+	}
+	last := 0
+	for i, v := range values {
+		if v != 0 {
+			last = i + 1
+		}
+	}
+	for i := bigEnd; i < last; i += 4 {
+		index := 0
+		for k := 0; k < 4; k++ {
+			if values[i+k] != 0 {
+				index |= 8 >> uint(k)
+			}
+		}
+		if g.count1Table == 0 {
+			w.write(int(mp3Count1Code.codes[index]), int(mp3Count1Code.lengths[index]))
+		} else {
+			w.write(15-index, 4)
+		}
+		for k := 0; k < 4; k++ {
+			if values[i+k] != 0 {
+				w.write(boolBit(values[i+k] < 0), 1)
+			}
+		}
+	}
+}
+
+func writeMP3TestPair(w *mp3TestBitWriter, table, linbits, x, y int) {
+	code := mp3HuffmanCodes[table]
+	ax, ay := min(abs(x), 15), min(abs(y), 15)
+	index := ax*code.wrap + ay
+	w.write(int(code.codes[index]), int(code.lengths[index]))
+	for _, v := range []int{x, y} {
+		if linbits > 0 && abs(v) >= 15 {
+			w.write(abs(v)-15, linbits)
+		}
+		if v != 0 {
+			w.write(boolBit(v < 0), 1)
+		}
+	}
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func boolBit(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// mp3TestBitWriter writes a big-endian bit stream
+type mp3TestBitWriter struct {
+	data []byte
+	n    int // bits written
+}
+
+func (w *mp3TestBitWriter) write(v, bits int) {
+	for i := bits - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		if v>>uint(i)&1 == 1 {
+			w.data[len(w.data)-1] |= 0x80 >> uint(w.n%8)
+		}
+		w.n++
+	}
+}
+
+// append writes the first bits of data
+func (w *mp3TestBitWriter) append(data []byte, bits int) {
+	for i := 0; i < bits; i++ {
+		w.write(int(data[i/8]>>uint(7-i%8)&1), 1)
+	}
+}
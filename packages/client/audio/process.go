@@ -0,0 +1,289 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultTrimThreshold is the level in dBFS below which audio counts as
+// silence for TrimSilence
+const DefaultTrimThreshold = -50.0
+
+// Effects is a post-processing pipeline for synthesized audio. Zero fields
+// are skipped; the steps run in the order of the fields, so loudness is
+// normalized last and measured on the final audio.
+type Effects struct {
+	TrimSilence   bool          `json:"trim_silence,omitempty"`
+	TrimThreshold float64       `json:"trim_threshold,omitempty"` // dBFS, DefaultTrimThreshold if zero
+	TrimPadding   time.Duration `json:"trim_padding,omitempty"`   // silence kept at each end after trimming
+	Channels      int           `json:"channels,omitempty"`
+	SampleRate    int           `json:"sample_rate,omitempty"`
+	GainDB        float64       `json:"gain_db,omitempty"`
+	NormalizeLUFS *float64      `json:"normalize_lufs,omitempty"` // target integrated loudness
+}
+
+// IsZero reports whether the effects leave the audio unchanged
+func (e Effects) IsZero() bool {
+	return !e.TrimSilence && e.Channels == 0 && e.SampleRate == 0 && e.GainDB == 0 && e.NormalizeLUFS == nil
+}
+
+// Apply runs the effects on b and returns the processed audio
+func (e Effects) Apply(b *Buffer) (*Buffer, error) {
+	var err error
+	if e.TrimSilence {
+		threshold := e.TrimThreshold
+		if threshold == 0 {
+			threshold = DefaultTrimThreshold
+		}
+		b = b.TrimSilence(threshold, e.TrimPadding)
+	}
+	if e.Channels != 0 {
+		if b, err = b.ConvertChannels(e.Channels); err != nil {
+			return nil, err
+		}
+	}
+	if e.SampleRate != 0 {
+		if b, err = b.Resample(e.SampleRate); err != nil {
+			return nil, err
+		}
+	}
+	if e.GainDB != 0 {
+		b = b.Gain(e.GainDB)
+	}
+	if e.NormalizeLUFS != nil {
+		b = b.Normalize(*e.NormalizeLUFS)
+	}
+	return b, nil
+}
+
+// Decode reads a WAV or FLAC file into memory. isFLAC reports which of the
+// two it was.
+func Decode(r io.Reader) (b *Buffer, isFLAC bool, err error) {
+	stream, err := NewStream(r)
+	if err != nil {
+		return nil, false, err
+	}
+	_, isFLAC = stream.(*flacStream)
+	data, err := io.ReadAll(stream)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decode audio: %w", err)
+	}
+	return &Buffer{Format: stream.Format(), Data: data}, isFLAC, nil
+}
+
+// ProcessFile applies effects to the WAV or FLAC file at path, keeping its
+// container. The file is replaced atomically, so a hard link to the original
+// audio keeps the original.
+func ProcessFile(path string, effects Effects) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read audio file: %w", err)
+	}
+	buffer, isFLAC, err := Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if buffer, err = effects.Apply(buffer); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".audio-*"+filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if isFLAC {
+		err = buffer.WriteFLAC(tmp)
+	} else {
+		err = buffer.WriteWAV(tmp)
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write processed audio: %w", err)
+	}
+	if info, err := os.Stat(path); err == nil {
+		os.Chmod(tmp.Name(), info.Mode().Perm())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace audio file: %w", err)
+	}
+	return nil
+}
+
+// Gain returns the audio amplified by db decibels. Samples that would exceed
+// full scale are clipped.
+func (b *Buffer) Gain(db float64) *Buffer {
+	gain := dbToAmplitude(db)
+	samples := b.floats()
+	for i := range samples {
+		samples[i] *= gain
+	}
+	return &Buffer{Format: b.Format, Data: encodeFloats(b.Format, samples)}
+}
+
+// ConvertChannels returns the audio with the given number of channels. Mono
+// is copied to every channel and multichannel audio is averaged down to mono;
+// other conversions are not supported.
+func (b *Buffer) ConvertChannels(channels int) (*Buffer, error) {
+	from := b.Format.Channels
+	if channels == from {
+		return b, nil
+	}
+	format := b.Format
+	format.Channels = channels
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+
+	samples := b.floats()
+	frames := len(samples) / from
+	out := make([]float64, frames*channels)
+	switch {
+	case from == 1:
+		for i := 0; i < frames; i++ {
+			for c := 0; c < channels; c++ {
+				out[i*channels+c] = samples[i]
+			}
+		}
+	case channels == 1:
+		for i := 0; i < frames; i++ {
+			var sum float64
+			for c := 0; c < from; c++ {
+				sum += samples[i*from+c]
+			}
+			out[i] = sum / float64(from)
+		}
+	default:
+		return nil, fmt.Errorf("cannot convert %d channels to %d", from, channels)
+	}
+	return &Buffer{Format: format, Data: encodeFloats(format, out)}, nil
+}
+
+// TrimSilence returns the audio without leading and trailing frames whose
+// samples all stay below thresholdDB (dBFS), keeping up to padding of silence
+// at each end. Audio that is silent throughout is trimmed to nothing.
+func (b *Buffer) TrimSilence(thresholdDB float64, padding time.Duration) *Buffer {
+	start, end := b.AudibleRange(thresholdDB)
+	if start == end {
+		return &Buffer{Format: b.Format}
+	}
+
+	pad := int(durationToFrames(padding, b.Format.SampleRate))
+	start = max(start-pad, 0)
+	end = min(end+pad, b.Frames())
+	align := b.Format.BlockAlign()
+	return &Buffer{Format: b.Format, Data: append([]byte(nil), b.Data[start*align:end*align]...)}
+}
+
+// AudibleRange returns the frames [start, end) between the first and the last
+// frame with a sample at or above thresholdDB (dBFS). Both are zero when the
+// audio is silent throughout.
+func (b *Buffer) AudibleRange(thresholdDB float64) (start, end int) {
+	threshold := dbToAmplitude(thresholdDB)
+	samples := b.floats()
+	channels := b.Format.Channels
+	frames := len(samples) / channels
+
+	loud := func(frame int) bool {
+		for c := 0; c < channels; c++ {
+			if math.Abs(samples[frame*channels+c]) >= threshold {
+				return true
+			}
+		}
+		return false
+	}
+	start, end = 0, frames
+	for start < end && !loud(start) {
+		start++
+	}
+	for end > start && !loud(end-1) {
+		end--
+	}
+	if start == end {
+		return 0, 0
+	}
+	return start, end
+}
+
+// Concat joins buffers of the same format, overlapping consecutive buffers by
+// crossfade with an equal-power crossfade. The overlap is shortened where a
+// buffer is shorter than crossfade.
+func Concat(crossfade time.Duration, buffers ...*Buffer) (*Buffer, error) {
+	if len(buffers) == 0 {
+		return nil, fmt.Errorf("nothing to concatenate")
+	}
+	format := buffers[0].Format
+	channels := format.Channels
+	fade := int(durationToFrames(crossfade, format.SampleRate))
+
+	out := buffers[0].floats()
+	for i, next := range buffers[1:] {
+		if next.Format != format {
+			return nil, fmt.Errorf("cannot concatenate %s audio with %s audio (buffer %d)", next.Format, format, i+2)
+		}
+		samples := next.floats()
+		overlap := min(fade, len(out)/channels, len(samples)/channels)
+		tail := out[len(out)-overlap*channels:]
+		for f := 0; f < overlap; f++ {
+			// Equal power: the gains' squares sum to one across the overlap
+			t := (float64(f) + 0.5) / float64(overlap)
+			fadeOut, fadeIn := math.Cos(t*math.Pi/2), math.Sin(t*math.Pi/2)
+			for c := 0; c < channels; c++ {
+				tail[f*channels+c] = tail[f*channels+c]*fadeOut + samples[f*channels+c]*fadeIn
+			}
+		}
+		out = append(out, samples[overlap*channels:]...)
+	}
+	return &Buffer{Format: format, Data: encodeFloats(format, out)}, nil
+}
+
+// floats returns the samples scaled to the range [-1, 1)
+func (b *Buffer) floats() []float64 {
+	scale := fullScale(b.Format)
+	samples := b.samples()
+	out := make([]float64, len(samples))
+	for i, v := range samples {
+		out[i] = float64(v) / scale
+	}
+	return out
+}
+
+// encodeFloats encodes samples in the range [-1, 1) as PCM in format,
+// clipping samples outside the range
+func encodeFloats(format Format, samples []float64) []byte {
+	scale := fullScale(format)
+	bytesPerSample := format.BitsPerSample / 8
+	out := make([]byte, len(samples)*bytesPerSample)
+	for i, v := range samples {
+		s := int32(math.Max(math.Min(math.Round(v*scale), scale-1), -scale))
+		p := out[i*bytesPerSample:]
+		p[0], p[1] = byte(s), byte(s>>8)
+		if bytesPerSample == 3 {
+			p[2] = byte(s >> 16)
+		}
+	}
+	return out
+}
+
+// fullScale returns the magnitude of the most negative sample in format
+func fullScale(format Format) float64 {
+	return float64(int64(1) << (format.BitsPerSample - 1))
+}
+
+func dbToAmplitude(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+func durationToFrames(d time.Duration, sampleRate int) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64(d) * int64(sampleRate) / int64(time.Second)
+}
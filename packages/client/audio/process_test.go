@@ -0,0 +1,215 @@
+package audio
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGain(t *testing.T) {
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	b := &Buffer{Format: format, Data: encodePCM(format, []int32{1000, -1000, 20000, -20000})}
+
+	got := b.Gain(-6.0206).samples() // half amplitude
+	want := []int32{500, -500, 10000, -10000}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Gain(-6) sample %d = %d, want %d", i, got[i], want[i])
+		}
+	}
+
+	// Samples beyond full scale are clipped
+	got = b.Gain(12).samples()
+	if got[2] != 32767 || got[3] != -32768 {
+		t.Errorf("Gain(12) = %v, want clipped peaks", got)
+	}
+}
+
+func TestConvertChannels(t *testing.T) {
+	mono := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	stereo := Format{SampleRate: 8000, Channels: 2, BitsPerSample: 16}
+
+	up, err := (&Buffer{Format: mono, Data: encodePCM(mono, []int32{100, -200})}).ConvertChannels(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up.Format != stereo || !bytes.Equal(up.Data, encodePCM(stereo, []int32{100, 100, -200, -200})) {
+		t.Errorf("mono to stereo = %v %v", up.Format, up.samples())
+	}
+
+	down, err := (&Buffer{Format: stereo, Data: encodePCM(stereo, []int32{100, 300, -200, 0})}).ConvertChannels(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if down.Format != mono || !bytes.Equal(down.Data, encodePCM(mono, []int32{200, -100})) {
+		t.Errorf("stereo to mono = %v %v", down.Format, down.samples())
+	}
+
+	if _, err := up.ConvertChannels(3); err == nil {
+		t.Error("expected an error converting stereo to three channels")
+	}
+}
+
+func TestTrimSilence(t *testing.T) {
+	format := Format{SampleRate: 1000, Channels: 2, BitsPerSample: 16}
+	samples := make([]int32, 2*100)
+	for i := 40; i < 60; i++ {
+		samples[2*i+1] = 5000 // right channel only
+	}
+	samples[2*10] = 3 // below -50 dBFS
+	b := &Buffer{Format: format, Data: encodePCM(format, samples)}
+
+	trimmed := b.TrimSilence(DefaultTrimThreshold, 0)
+	if trimmed.Frames() != 20 || !bytes.Equal(trimmed.Data, b.Data[40*4:60*4]) {
+		t.Errorf("TrimSilence() kept %d frames, want frames 40 to 60", trimmed.Frames())
+	}
+
+	padded := b.TrimSilence(DefaultTrimThreshold, 50*time.Millisecond)
+	if padded.Frames() != 20+40+40 {
+		t.Errorf("padding is limited by the audio: got %d frames, want 100", padded.Frames())
+	}
+	padded = b.TrimSilence(DefaultTrimThreshold, 5*time.Millisecond)
+	if padded.Frames() != 30 {
+		t.Errorf("TrimSilence() with 5ms padding kept %d frames, want 30", padded.Frames())
+	}
+
+	if silent := (&Buffer{Format: format, Data: make([]byte, 400)}).TrimSilence(DefaultTrimThreshold, 0); silent.Frames() != 0 {
+		t.Errorf("silent audio kept %d frames", silent.Frames())
+	}
+}
+
+func TestConcat(t *testing.T) {
+	format := Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}
+	constant := func(value int32, frames int) *Buffer {
+		samples := make([]int32, frames)
+		for i := range samples {
+			samples[i] = value
+		}
+		return &Buffer{Format: format, Data: encodePCM(format, samples)}
+	}
+
+	joined, err := Concat(100*time.Millisecond, constant(1000, 300), constant(1000, 300))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if joined.Frames() != 500 {
+		t.Fatalf("joined %d frames, want 500", joined.Frames())
+	}
+	// The equal-power crossfade peaks at sqrt(2) in the middle of the overlap
+	samples := joined.samples()
+	if samples[199] != 1000 || samples[400] != 1000 || samples[250] < 1410 || samples[250] > 1415 {
+		t.Errorf("samples around the crossfade = %d %d %d", samples[199], samples[250], samples[400])
+	}
+
+	// Without a crossfade, buffers are appended
+	joined, _ = Concat(0, constant(1, 3), constant(2, 2))
+	if got := joined.samples(); len(got) != 5 || got[2] != 1 || got[3] != 2 {
+		t.Errorf("Concat(0) = %v", got)
+	}
+	// The overlap cannot exceed the shorter buffer
+	if joined, _ = Concat(time.Second, constant(1000, 300), constant(1000, 50)); joined.Frames() != 300 {
+		t.Errorf("Concat() with a long crossfade = %d frames, want 300", joined.Frames())
+	}
+
+	other := &Buffer{Format: Format{SampleRate: 2000, Channels: 1, BitsPerSample: 16}}
+	if _, err := Concat(0, constant(1, 1), other); err == nil {
+		t.Error("expected an error for mismatched formats")
+	}
+}
+
+func TestResample(t *testing.T) {
+	for _, rate := range []int{48000, 44100, 16000} {
+		in := sineBuffer(Format{SampleRate: 24000, Channels: 2, BitsPerSample: 16}, 1000, 0.5, 500*time.Millisecond)
+		out, err := in.Resample(rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out.Format.SampleRate != rate || out.Frames() != rate/2 {
+			t.Fatalf("Resample(%d) = %s, %d frames", rate, out.Format, out.Frames())
+		}
+		want := sineBuffer(out.Format, 1000, 0.5, 500*time.Millisecond).floats()
+		got := out.floats()
+		// Compare away from the edges, where the kernel runs out of input
+		var worst float64
+		for i := len(got) / 10; i < len(got)*9/10; i++ {
+			worst = math.Max(worst, math.Abs(got[i]-want[i]))
+		}
+		if worst > 0.002 {
+			t.Errorf("Resample(%d) differs from the ideal sine by up to %.4f", rate, worst)
+		}
+	}
+
+	// Downsampling removes content above the new Nyquist frequency. The
+	// abrupt start and end of the tone are broadband, so look in between.
+	high := sineBuffer(Format{SampleRate: 48000, Channels: 1, BitsPerSample: 16}, 10000, 0.5, 500*time.Millisecond)
+	out, _ := high.Resample(16000)
+	var power float64
+	middle := out.floats()[1000:7000]
+	for _, v := range middle {
+		power += v * v
+	}
+	if level := 10 * math.Log10(power/float64(len(middle))); level > -80 {
+		t.Errorf("a 10 kHz tone resampled to 16 kHz is still at %.1f dBFS", level)
+	}
+}
+
+func TestProcessFile(t *testing.T) {
+	format := Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+	tone := sineBuffer(format, 440, 0.1, time.Second)
+	silence := &Buffer{Format: format, Data: Silence(format, 300*time.Millisecond)}
+	padded, _ := Concat(0, silence, tone, silence)
+
+	target := -20.0
+	effects := Effects{TrimSilence: true, Channels: 2, SampleRate: 48000, NormalizeLUFS: &target}
+	for _, ext := range []string{".wav", ".flac"} {
+		t.Run(ext, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "speech"+ext)
+			var data bytes.Buffer
+			if ext == ".flac" {
+				padded.WriteFLAC(&data)
+			} else {
+				padded.WriteWAV(&data)
+			}
+			if err := os.WriteFile(path, data.Bytes(), 0644); err != nil {
+				t.Fatal(err)
+			}
+			// A hard link keeps the original audio
+			link := path + ".orig"
+			if err := os.Link(path, link); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := ProcessFile(path, effects); err != nil {
+				t.Fatalf("ProcessFile() error = %v", err)
+			}
+			file, _ := os.Open(path)
+			defer file.Close()
+			out, isFLAC, err := Decode(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if isFLAC != (ext == ".flac") {
+				t.Errorf("container changed: isFLAC = %v", isFLAC)
+			}
+			if out.Format != (Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16}) {
+				t.Errorf("format = %s", out.Format)
+			}
+			if d := out.Duration(); d < 990*time.Millisecond || d > 1010*time.Millisecond {
+				t.Errorf("duration = %v, want the 1s tone without silence", d)
+			}
+			if loudness := out.Loudness(); math.Abs(loudness-target) > 0.1 {
+				t.Errorf("loudness = %.2f, want %.0f", loudness, target)
+			}
+			if original, _ := os.ReadFile(link); !bytes.Equal(original, data.Bytes()) {
+				t.Error("the linked original was modified")
+			}
+		})
+	}
+
+	if err := ProcessFile(filepath.Join(t.TempDir(), "missing.wav"), effects); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
@@ -0,0 +1,76 @@
+package audio
+
+import (
+	"math"
+)
+
+const (
+	// resampleZeroCrossings is the number of sinc zero crossings on each side
+	// of the interpolation kernel; more is sharper but slower
+	resampleZeroCrossings = 16
+
+	// resampleRolloff places the filter cutoff just below the Nyquist
+	// frequency, so that the transition band is attenuated before aliasing
+	resampleRolloff = 0.9
+)
+
+// Resample returns the audio converted to sampleRate with band-limited
+// (windowed sinc) interpolation. When downsampling, content above the new
+// Nyquist frequency is filtered out.
+func (b *Buffer) Resample(sampleRate int) (*Buffer, error) {
+	if sampleRate == b.Format.SampleRate {
+		return b, nil
+	}
+	format := b.Format
+	format.SampleRate = sampleRate
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+
+	channels := b.Format.Channels
+	samples := b.floats()
+	inFrames := len(samples) / channels
+	ratio := float64(sampleRate) / float64(b.Format.SampleRate)
+	outFrames := int(math.Round(float64(inFrames) * ratio))
+
+	// The kernel is stretched when downsampling so it also low-passes
+	cutoff := math.Min(1, ratio) * resampleRolloff
+	halfWidth := float64(resampleZeroCrossings) / cutoff
+
+	out := make([]float64, outFrames*channels)
+	weights := make([]float64, 0, int(2*halfWidth)+2)
+	for n := 0; n < outFrames; n++ {
+		center := float64(n) / ratio
+		first := max(int(math.Ceil(center-halfWidth)), 0)
+		last := min(int(math.Floor(center+halfWidth)), inFrames-1)
+
+		weights = weights[:0]
+		for k := first; k <= last; k++ {
+			x := center - float64(k)
+			weights = append(weights, cutoff*sinc(cutoff*x)*blackman(x/halfWidth))
+		}
+		for c := 0; c < channels; c++ {
+			var sum float64
+			for i, w := range weights {
+				sum += w * samples[(first+i)*channels+c]
+			}
+			out[n*channels+c] = sum
+		}
+	}
+	return &Buffer{Format: format, Data: encodeFloats(format, out)}, nil
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// blackman is a Blackman window over [-1, 1]
+func blackman(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return 0.42 + 0.5*math.Cos(math.Pi*x) + 0.08*math.Cos(2*math.Pi*x)
+}
@@ -0,0 +1,180 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsupportedFormat is returned by NewStream for encodings other than WAV,
+// FLAC and MP3. AAC and Opus are not decoded by this package; players hand
+// them to an external decoder instead.
+var ErrUnsupportedFormat = errors.New("unsupported audio format (only WAV, FLAC and MP3 can be decoded)")
+
+// Stream is PCM audio decoded progressively from an encoded source, so that
+// playback can start before the whole input has arrived
+type Stream interface {
+	io.Reader
+
+	// Format returns the format of the PCM data produced by Read
+	Format() Format
+
+	// TotalFrames returns the length of the stream in frames, or -1 when it
+	// is not known in advance
+	TotalFrames() int64
+}
+
+// sniffBufferSize bounds how far NewStream looks ahead for the format
+const sniffBufferSize = 64 * 1024
+
+// NewStream detects whether r holds WAV, FLAC or MP3 data by its leading
+// bytes and returns a decoder producing interleaved little-endian PCM. When r
+// is a *bufio.Reader of at least 64 KiB, detection only peeks, so after
+// ErrUnsupportedFormat the same reader can be handed to another decoder.
+func NewStream(r io.Reader) (Stream, error) {
+	br := bufio.NewReaderSize(r, sniffBufferSize)
+	magic, err := br.Peek(4)
+	if err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, err
+	}
+	switch {
+	case bytes.Equal(magic, []byte("RIFF")):
+		return NewWAVStream(br)
+	case bytes.Equal(magic, []byte("fLaC")):
+		return NewFLACStream(br)
+	case bytes.Equal(magic[:3], []byte("ID3")):
+		// Both FLAC and MP3 files may start with an ID3v2 tag
+		header, err := br.Peek(10)
+		if err != nil {
+			return nil, ErrUnsupportedFormat
+		}
+		end := 10 + (int(header[6])<<21 | int(header[7])<<14 | int(header[8])<<7 | int(header[9]))
+		if end+4 > sniffBufferSize {
+			return nil, ErrUnsupportedFormat
+		}
+		data, err := br.Peek(end + 4)
+		if err != nil {
+			return nil, ErrUnsupportedFormat
+		}
+		if string(data[end:]) == "fLaC" {
+			return NewFLACStream(br)
+		}
+		if _, ok := parseMP3Header(data[end:]); ok {
+			return NewMP3Stream(br)
+		}
+		return nil, ErrUnsupportedFormat
+	default:
+		// MPEG audio has no magic, only a frame sync; Layer I and II and
+		// AAC in ADTS share it but are not decoded
+		if _, ok := parseMP3Header(magic); ok {
+			return NewMP3Stream(br)
+		}
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// wavStream reads the data chunk of a WAV file as it arrives
+type wavStream struct {
+	r         io.Reader
+	format    Format
+	remaining int64 // -1 when the data size is open (streamed WAV)
+	total     int64
+}
+
+// NewWAVStream parses the WAV header from r and returns a stream of the
+// samples in its data chunk. Streamed WAVs with open sizes are read to EOF.
+func NewWAVStream(r io.Reader) (Stream, error) {
+	var riff [12]byte
+	if _, err := io.ReadFull(r, riff[:]); err != nil {
+		return nil, ErrNotWAV
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return nil, ErrNotWAV
+	}
+
+	var (
+		format  Format
+		haveFmt bool
+		chunk   [8]byte
+	)
+	for {
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return nil, fmt.Errorf("WAV file has no data chunk")
+		}
+		id := string(chunk[0:4])
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+
+		switch id {
+		case "fmt ":
+			if size < 16 || size > 1024 {
+				return nil, fmt.Errorf("invalid WAV fmt chunk")
+			}
+			body := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, body); err != nil {
+				return nil, fmt.Errorf("invalid WAV fmt chunk")
+			}
+			tag := binary.LittleEndian.Uint16(body[0:2])
+			if tag != wavFormatPCM && tag != wavFormatExtensible {
+				return nil, fmt.Errorf("unsupported WAV encoding: %d", tag)
+			}
+			format = Format{
+				Channels:      int(binary.LittleEndian.Uint16(body[2:4])),
+				SampleRate:    int(binary.LittleEndian.Uint32(body[4:8])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(body[14:16])),
+			}
+			if err := format.Validate(); err != nil {
+				return nil, err
+			}
+			haveFmt = true
+		case "data":
+			if !haveFmt {
+				return nil, fmt.Errorf("WAV file has no fmt chunk")
+			}
+			remaining := size
+			if size == streamingDataSize || size == 0 {
+				remaining = -1
+			}
+			return &wavStream{r: r, format: format, remaining: remaining, total: size}, nil
+		default:
+			// Chunks are word aligned
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return nil, fmt.Errorf("WAV file has no data chunk")
+			}
+		}
+	}
+}
+
+func (s *wavStream) Format() Format {
+	return s.format
+}
+
+func (s *wavStream) TotalFrames() int64 {
+	if s.remaining < 0 {
+		return -1
+	}
+	return s.total / int64(s.format.BlockAlign())
+}
+
+func (s *wavStream) Read(p []byte) (int, error) {
+	if s.remaining == 0 {
+		return 0, io.EOF
+	}
+	if s.remaining > 0 && int64(len(p)) > s.remaining {
+		p = p[:s.remaining]
+	}
+	n, err := s.r.Read(p)
+	if s.remaining > 0 {
+		s.remaining -= int64(n)
+	}
+	if err == io.EOF && s.remaining > 0 {
+		// A truncated file still plays what is there
+		s.remaining = 0
+	}
+	return n, err
+}
@@ -0,0 +1,215 @@
+// Package audio provides PCM audio helpers for synthesized speech:
+// WAV and FLAC decoding and encoding, MP3 decoding, streaming decoders for
+// playback, silence generation and post-processing effects (loudness
+// normalization, silence trimming, resampling, channel conversion, gain and
+// crossfaded concatenation).
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Format describes interleaved little-endian PCM audio
+type Format struct {
+	SampleRate    int `json:"sample_rate"`
+	Channels      int `json:"channels"`
+	BitsPerSample int `json:"bits_per_sample"`
+}
+
+// BlockAlign returns the number of bytes in one frame (one sample per channel)
+func (f Format) BlockAlign() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+// ByteRate returns the number of bytes per second of audio
+func (f Format) ByteRate() int {
+	return f.SampleRate * f.BlockAlign()
+}
+
+// String returns a short human readable description of the format
+func (f Format) String() string {
+	return fmt.Sprintf("%d Hz, %d ch, %d bit", f.SampleRate, f.Channels, f.BitsPerSample)
+}
+
+// Validate checks that the format can be processed by this package
+func (f Format) Validate() error {
+	if f.SampleRate <= 0 {
+		return fmt.Errorf("invalid sample rate: %d", f.SampleRate)
+	}
+	if f.Channels < 1 || f.Channels > 8 {
+		return fmt.Errorf("invalid channel count: %d", f.Channels)
+	}
+	if f.BitsPerSample != 16 && f.BitsPerSample != 24 {
+		return fmt.Errorf("unsupported bits per sample: %d (only 16 and 24 bit PCM are supported)", f.BitsPerSample)
+	}
+	return nil
+}
+
+// Buffer holds PCM audio data in memory
+type Buffer struct {
+	Format Format
+	Data   []byte // Interleaved little-endian PCM samples
+}
+
+// Frames returns the number of sample frames in the buffer
+func (b *Buffer) Frames() int {
+	align := b.Format.BlockAlign()
+	if align == 0 {
+		return 0
+	}
+	return len(b.Data) / align
+}
+
+// Duration returns the playback duration of the buffer
+func (b *Buffer) Duration() time.Duration {
+	if b.Format.SampleRate == 0 {
+		return 0
+	}
+	return time.Duration(b.Frames()) * time.Second / time.Duration(b.Format.SampleRate)
+}
+
+// ErrNotWAV is returned when the input is not a RIFF/WAVE stream
+var ErrNotWAV = errors.New("not a WAV file")
+
+const (
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+
+	// streamingDataSize is written as the data chunk size when the final length is unknown
+	streamingDataSize = 0xFFFFFFFF
+)
+
+// DecodeWAV reads a PCM WAV stream into memory
+func DecodeWAV(r io.Reader) (*Buffer, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WAV data: %w", err)
+	}
+	return DecodeWAVBytes(data)
+}
+
+// DecodeWAVBytes parses a PCM WAV file held in memory
+func DecodeWAVBytes(data []byte) (*Buffer, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, ErrNotWAV
+	}
+
+	var (
+		format   Format
+		haveFmt  bool
+		pcm      []byte
+		haveData bool
+		offset   = 12
+	)
+
+	for offset+8 <= len(data) {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+
+		// Streamed WAVs may carry a placeholder size; clamp to what is available
+		if body+size > len(data) {
+			size = len(data) - body
+		}
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("invalid WAV fmt chunk")
+			}
+			tag := binary.LittleEndian.Uint16(data[body : body+2])
+			if tag != wavFormatPCM && tag != wavFormatExtensible {
+				return nil, fmt.Errorf("unsupported WAV encoding: %d", tag)
+			}
+			format = Format{
+				Channels:      int(binary.LittleEndian.Uint16(data[body+2 : body+4])),
+				SampleRate:    int(binary.LittleEndian.Uint32(data[body+4 : body+8])),
+				BitsPerSample: int(binary.LittleEndian.Uint16(data[body+14 : body+16])),
+			}
+			haveFmt = true
+		case "data":
+			pcm = data[body : body+size]
+			haveData = true
+		}
+
+		// Chunks are word aligned
+		offset = body + size + size%2
+		if haveData {
+			break
+		}
+	}
+
+	if !haveFmt {
+		return nil, fmt.Errorf("WAV file has no fmt chunk")
+	}
+	if !haveData {
+		return nil, fmt.Errorf("WAV file has no data chunk")
+	}
+	if err := format.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Drop a trailing partial frame, if any
+	pcm = pcm[:len(pcm)-len(pcm)%format.BlockAlign()]
+
+	return &Buffer{Format: format, Data: pcm}, nil
+}
+
+// WriteWAV encodes the buffer as a canonical 44-byte header PCM WAV file
+func (b *Buffer) WriteWAV(w io.Writer) error {
+	if err := WriteWAVHeader(w, b.Format, uint32(len(b.Data))); err != nil {
+		return err
+	}
+	_, err := w.Write(b.Data)
+	return err
+}
+
+// WriteWAVHeader writes a PCM WAV header for dataSize bytes of sample data
+func WriteWAVHeader(w io.Writer, format Format, dataSize uint32) error {
+	if err := format.Validate(); err != nil {
+		return err
+	}
+
+	riffSize := uint32(streamingDataSize)
+	if dataSize != streamingDataSize {
+		riffSize = 36 + dataSize
+	}
+
+	var header bytes.Buffer
+	header.WriteString("RIFF")
+	binary.Write(&header, binary.LittleEndian, riffSize)
+	header.WriteString("WAVE")
+	header.WriteString("fmt ")
+	binary.Write(&header, binary.LittleEndian, uint32(16))
+	binary.Write(&header, binary.LittleEndian, uint16(wavFormatPCM))
+	binary.Write(&header, binary.LittleEndian, uint16(format.Channels))
+	binary.Write(&header, binary.LittleEndian, uint32(format.SampleRate))
+	binary.Write(&header, binary.LittleEndian, uint32(format.ByteRate()))
+	binary.Write(&header, binary.LittleEndian, uint16(format.BlockAlign()))
+	binary.Write(&header, binary.LittleEndian, uint16(format.BitsPerSample))
+	header.WriteString("data")
+	binary.Write(&header, binary.LittleEndian, dataSize)
+
+	_, err := w.Write(header.Bytes())
+	return err
+}
+
+// WriteStreamingWAVHeader writes a WAV header whose sizes are left open,
+// for piping audio to players before the total length is known
+func WriteStreamingWAVHeader(w io.Writer, format Format) error {
+	return WriteWAVHeader(w, format, streamingDataSize)
+}
+
+// Silence returns PCM data of the given duration containing only silence
+func Silence(format Format, duration time.Duration) []byte {
+	if duration <= 0 {
+		return nil
+	}
+	frames := int(duration * time.Duration(format.SampleRate) / time.Second)
+	return make([]byte, frames*format.BlockAlign())
+}
@@ -0,0 +1,68 @@
+package audio
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWAVRoundTrip(t *testing.T) {
+	format := Format{SampleRate: 44100, Channels: 2, BitsPerSample: 16}
+	buf := &Buffer{Format: format, Data: encodePCM(format, testSignal(format, 1000))}
+
+	var out bytes.Buffer
+	if err := buf.WriteWAV(&out); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+	if out.Len() != 44+len(buf.Data) {
+		t.Errorf("WAV size = %d, want %d", out.Len(), 44+len(buf.Data))
+	}
+
+	decoded, err := DecodeWAVBytes(out.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeWAVBytes() error = %v", err)
+	}
+	if decoded.Format != format {
+		t.Errorf("format = %+v, want %+v", decoded.Format, format)
+	}
+	if !bytes.Equal(decoded.Data, buf.Data) {
+		t.Error("decoded PCM differs from input")
+	}
+}
+
+func TestDecodeWAVStreamingHeader(t *testing.T) {
+	format := Format{SampleRate: 24000, Channels: 1, BitsPerSample: 16}
+
+	var out bytes.Buffer
+	if err := WriteStreamingWAVHeader(&out, format); err != nil {
+		t.Fatalf("WriteStreamingWAVHeader() error = %v", err)
+	}
+	out.Write(make([]byte, 4801)) // 2400 frames plus a partial frame
+
+	decoded, err := DecodeWAVBytes(out.Bytes())
+	if err != nil {
+		t.Fatalf("DecodeWAVBytes() error = %v", err)
+	}
+	if decoded.Frames() != 2400 {
+		t.Errorf("Frames() = %d, want 2400", decoded.Frames())
+	}
+	if decoded.Duration() != 100*time.Millisecond {
+		t.Errorf("Duration() = %v, want 100ms", decoded.Duration())
+	}
+}
+
+func TestDecodeWAVRejectsOtherFormats(t *testing.T) {
+	if _, err := DecodeWAVBytes([]byte("ID3\x03not a wav file")); err != ErrNotWAV {
+		t.Errorf("error = %v, want ErrNotWAV", err)
+	}
+}
+
+func TestSilence(t *testing.T) {
+	format := Format{SampleRate: 48000, Channels: 2, BitsPerSample: 24}
+	if got := len(Silence(format, 250*time.Millisecond)); got != 12000*6 {
+		t.Errorf("len(Silence) = %d, want %d", got, 12000*6)
+	}
+	if Silence(format, 0) != nil {
+		t.Error("Silence(0) should be empty")
+	}
+}
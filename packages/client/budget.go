@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	ttsInfra "github.com/kajidog/aivis-cloud-cli/client/tts/infrastructure"
+	ttsUsecase "github.com/kajidog/aivis-cloud-cli/client/tts/usecase"
+)
+
+// newBudgetGuard creates the credit budget guard over the configured ledger.
+// Without a ledger location spending cannot be tracked, which is only an
+// error when a budget is configured.
+func newBudgetGuard(cfg *config.Config) (*ttsUsecase.BudgetGuard, error) {
+	path, err := cfg.GetBudgetPath()
+	if err != nil {
+		if cfg.BudgetDailyCredits > 0 || cfg.BudgetMonthlyCredits > 0 {
+			return nil, fmt.Errorf("failed to locate budget ledger: %w", err)
+		}
+		return nil, nil
+	}
+	repository := ttsInfra.NewFileBudgetRepository(path)
+	return ttsUsecase.NewBudgetGuard(repository, cfg.BudgetDailyCredits, cfg.BudgetMonthlyCredits), nil
+}
+
+// GetBudgetStatus returns the credits spent today and this month against the
+// configured budgets, as recorded in the local ledger
+func (c *Client) GetBudgetStatus(ctx context.Context) (*ttsDomain.BudgetStatus, error) {
+	if c.budget == nil {
+		return nil, fmt.Errorf("credit budget tracking is unavailable")
+	}
+	return c.budget.Status(ctx)
+}
+
+// EstimateSynthesisCost estimates the credits a synthesis of request will
+// cost, from its length and the rate billed for recent syntheses, and lists
+// the budgets it would exceed
+func (c *Client) EstimateSynthesisCost(ctx context.Context, request *ttsDomain.TTSRequest) (*ttsDomain.BudgetEstimate, error) {
+	if c.budget == nil {
+		return nil, fmt.Errorf("credit budget tracking is unavailable")
+	}
+	useSSML := request.UseSSML != nil && *request.UseSSML
+	return c.budget.Estimate(ctx, ttsUsecase.CountCharacters(request.Text, useSSML))
+}
+
+// SetBudgetConfirm sets the function asked whether a synthesis that would
+// exceed a credit budget may proceed. Without one (the default) such
+// syntheses fail with a *ttsDomain.BudgetExceededError.
+func (c *Client) SetBudgetConfirm(confirm ttsDomain.BudgetConfirmFunc) {
+	c.budgetConfirm = confirm
+	if c.budget != nil {
+		c.budget.SetConfirm(confirm)
+	}
+}
+
+// approveBudget checks the whole text of an operation that synthesizes it in
+// several requests (long text, scripts) against the budget once, and returns
+// a context under which the individual requests are not checked again. The
+// estimate stays reserved until release is called, which callers defer
+// unless the playback daemon takes the reservation over.
+func (c *Client) approveBudget(ctx context.Context, texts []string, useSSML bool) (context.Context, func(), error) {
+	if c.budget == nil {
+		return ctx, func() {}, nil
+	}
+	characters := 0
+	for _, text := range texts {
+		characters += ttsUsecase.CountCharacters(text, useSSML)
+	}
+	return c.budget.Approve(ctx, characters)
+}
+
+// approveRequestBudget is approveBudget for the text of a single request
+func (c *Client) approveRequestBudget(ctx context.Context, request *ttsDomain.TTSRequest) (context.Context, func(), error) {
+	if request == nil {
+		return ctx, func() {}, nil
+	}
+	return c.approveBudget(ctx, []string{request.Text}, request.UseSSML != nil && *request.UseSSML)
+}
+
+// approvePlaybackBudget is approveRequestBudget for a playback request
+func (c *Client) approvePlaybackBudget(ctx context.Context, request *ttsDomain.PlaybackRequest) (context.Context, func(), error) {
+	if request == nil {
+		return ctx, func() {}, nil
+	}
+	return c.approveRequestBudget(ctx, request.TTSRequest)
+}
+
+// reservePlaybackBudget checks a single playback request against the budget
+// and returns a context carrying its reservation. The request may be
+// synthesized after the call returns, from the playback queue or by the
+// playback daemon; its synthesis settles the reservation, so there is
+// nothing for the caller to release.
+func (c *Client) reservePlaybackBudget(ctx context.Context, request *ttsDomain.PlaybackRequest) (context.Context, error) {
+	if c.budget == nil || request == nil || request.TTSRequest == nil {
+		return ctx, nil
+	}
+	useSSML := request.TTSRequest.UseSSML != nil && *request.TTSRequest.UseSSML
+	return c.budget.ReserveRequest(ctx, ttsUsecase.CountCharacters(request.TTSRequest.Text, useSSML))
+}
+
+// releasePlaybackBudget drops the reservation of a playback request that
+// failed before its synthesis settled it. A settled reservation is already
+// gone, so this is harmless after a synthesis that failed late.
+func (c *Client) releasePlaybackBudget(ctx context.Context) {
+	if c.budget == nil || ttsDomain.BudgetApproved(ctx) {
+		return
+	}
+	c.budget.Release(context.Background(), ttsDomain.BudgetReservationID(ctx))
+}
+
+// approveScriptBudget is approveBudget for the spoken lines of a script
+func (c *Client) approveScriptBudget(ctx context.Context, script *ttsDomain.Script) (context.Context, func(), error) {
+	if script == nil {
+		return ctx, func() {}, nil
+	}
+	var texts []string
+	for _, line := range script.Lines {
+		if !line.IsPause() {
+			texts = append(texts, line.Text)
+		}
+	}
+	return c.approveBudget(ctx, texts, false)
+}
@@ -11,9 +11,13 @@ import (
     "strconv"
     "time"
 
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
 	"github.com/kajidog/aivis-cloud-cli/client/common/http"
 	"github.com/kajidog/aivis-cloud-cli/client/common/logger"
 	"github.com/kajidog/aivis-cloud-cli/client/config"
+	dictionariesDomain "github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+	dictionariesInfra "github.com/kajidog/aivis-cloud-cli/client/dictionaries/infrastructure"
+	dictionariesUsecase "github.com/kajidog/aivis-cloud-cli/client/dictionaries/usecase"
 	"github.com/kajidog/aivis-cloud-cli/client/models/domain"
 	modelsInfra "github.com/kajidog/aivis-cloud-cli/client/models/infrastructure"
 	modelsUsecase "github.com/kajidog/aivis-cloud-cli/client/models/usecase"
@@ -35,10 +39,29 @@ type Client struct {
 	httpClient     *http.Client
 	ttsService     *ttsUsecase.TTSSynthesizer
 	historyManager *ttsUsecase.TTSHistoryManager
+	cache          ttsDomain.TTSCache
 	modelsService  *modelsUsecase.ModelSearcher
 	playerService  *ttsUsecase.AudioPlayerServiceAdapter
 	usersService   *usersUsecase.UserUsecase
 	paymentService *paymentUsecase.PaymentUsecase
+
+	dictionariesService *dictionariesUsecase.UserDictionaryUsecase
+
+	// budget checks and records the credits spent on synthesis; nil when the
+	// ledger has no location. budgetConfirm survives configuration updates.
+	budget        *ttsUsecase.BudgetGuard
+	budgetConfirm ttsDomain.BudgetConfirmFunc
+
+	// credits follows the remaining credits reported by the API and raises
+	// low-credit alerts; nil when its state has no location.
+	// creditNotifiers survive configuration updates.
+	credits         *paymentUsecase.CreditMonitor
+	creditNotifiers []paymentDomain.CreditNotifier
+
+	// daemon forwards playback to a running playback daemon; nil when
+	// forwarding is disabled or this process is the daemon
+	daemon        *ttsInfra.DaemonClient
+	servingDaemon bool
 }
 
 // New creates a new Aivis Cloud API client with the provided API key
@@ -79,11 +102,25 @@ func NewWithConfig(cfg *config.Config) (*Client, error) {
 
 	httpClient := http.NewClient(cfg)
 
+	budget, err := newBudgetGuard(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	credits, err := newCreditMonitor(cfg, clientLogger, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize repositories
-	ttsRepo := ttsInfra.NewTTSAPIRepository(httpClient)
+	ttsRepo, cache, err := newTTSRepository(cfg, httpClient, budget)
+	if err != nil {
+		return nil, err
+	}
 	modelsRepo := modelsInfra.NewModelAPIRepository(httpClient)
 	usersRepo := usersInfra.NewUserAPI(httpClient)
 	paymentRepo := paymentInfra.NewPaymentAPI(httpClient)
+	dictionariesRepo := dictionariesInfra.NewUserDictionaryAPI(httpClient)
 	
 	// Initialize history repository if history is enabled
 	var historyRepo ttsDomain.TTSHistoryRepository
@@ -92,7 +129,10 @@ func NewWithConfig(cfg *config.Config) (*Client, error) {
 		if err != nil {
 			return nil, err
 		}
-		historyRepo = ttsInfra.NewFileHistoryRepository(historyStorePath)
+		historyRepo, err = newHistoryRepository(cfg.HistoryBackend, historyStorePath)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Initialize use cases
@@ -100,6 +140,7 @@ func NewWithConfig(cfg *config.Config) (*Client, error) {
 	modelsService := modelsUsecase.NewModelSearcher(modelsRepo)
 	usersService := usersUsecase.NewUserUsecase(usersRepo)
 	paymentService := paymentUsecase.NewPaymentUsecase(paymentRepo)
+	dictionariesService := dictionariesUsecase.NewUserDictionaryUsecase(dictionariesRepo)
 	
 	// Initialize audio player with configuration
 	playbackConfig := ttsDomain.DefaultPlaybackConfig()
@@ -114,7 +155,7 @@ func NewWithConfig(cfg *config.Config) (*Client, error) {
 		}
 	}
 	
-	audioPlayer := ttsInfra.NewOSCommandAudioPlayerWithLogger(playbackConfig, clientLogger)
+	audioPlayer := newAudioPlayer(cfg, playbackConfig, clientLogger)
 	
 	// Initialize global audio player service singleton
 	globalPlayerService := ttsUsecase.GetGlobalAudioPlayerService()
@@ -128,7 +169,7 @@ func NewWithConfig(cfg *config.Config) (*Client, error) {
     // Provide factory for creating independent players (for no_queue concurrent playback)
     globalPlayerService.SetNewPlayerFactory(func() ttsDomain.AudioPlayer {
         // Use same playback config and logger
-        return ttsInfra.NewOSCommandAudioPlayerWithLogger(playbackConfig, clientLogger)
+        return newAudioPlayer(cfg, playbackConfig, clientLogger)
     })
 	
 	// Create adapter to maintain compatibility with existing interface
@@ -138,19 +179,76 @@ func NewWithConfig(cfg *config.Config) (*Client, error) {
 	var historyManager *ttsUsecase.TTSHistoryManager
 	if cfg.HistoryEnabled && historyRepo != nil {
 		historyManager = ttsUsecase.NewTTSHistoryManager(historyRepo, ttsRepo, audioPlayer, cfg)
+		if cfg.CacheEnabled {
+			historyManager.SetCache(cache)
+		}
 	}
 
-	return &Client{
+	client := &Client{
 		config:         cfg,
 		logger:         clientLogger,
 		httpClient:     httpClient,
 		ttsService:     ttsService,
 		historyManager: historyManager,
+		cache:          cache,
 		modelsService:  modelsService,
 		playerService:  playerService,
 		usersService:   usersService,
 		paymentService: paymentService,
-	}, nil
+		daemon:         newDaemonClient(cfg),
+
+		dictionariesService: dictionariesService,
+		budget:              budget,
+		credits:             credits,
+	}
+	httpClient.SetHeaderObserver(client.observeCredits)
+	return client, nil
+}
+
+// newTTSRepository creates the TTS API repository, guarded by the credit
+// budget and placed behind the synthesis cache when it is enabled, so that
+// cache hits cost nothing. The cache is returned even when disabled so that
+// existing entries can still be inspected and removed.
+func newTTSRepository(cfg *config.Config, httpClient *http.Client, budget *ttsUsecase.BudgetGuard) (ttsDomain.TTSRepository, ttsDomain.TTSCache, error) {
+	var apiRepo ttsDomain.TTSRepository = ttsInfra.NewTTSAPIRepository(httpClient)
+	if budget != nil {
+		apiRepo = budget.Wrap(apiRepo)
+	}
+
+	cachePath, err := cfg.GetCachePath()
+	if err != nil {
+		if cfg.CacheEnabled {
+			return nil, nil, err
+		}
+		return apiRepo, nil, nil
+	}
+	cache := ttsInfra.NewCachingTTSRepository(apiRepo, cachePath, cfg.CacheMaxSize, cfg.CacheMaxAge)
+	if !cfg.CacheEnabled {
+		return apiRepo, cache, nil
+	}
+	return cache, cache, nil
+}
+
+// newHistoryRepository opens the history store for the configured backend
+func newHistoryRepository(backend, storePath string) (ttsDomain.TTSHistoryRepository, error) {
+	switch backend {
+	case config.HistoryBackendSQLite:
+		return ttsInfra.NewSQLiteHistoryRepository(storePath)
+	default:
+		return ttsInfra.NewFileHistoryRepository(storePath), nil
+	}
+}
+
+// newAudioPlayer creates the audio player for the configured backend. The
+// native player hands formats it cannot decode to the OS command player.
+func newAudioPlayer(cfg *config.Config, playbackConfig *ttsDomain.PlaybackConfig, log logger.Logger) ttsDomain.AudioPlayer {
+	commandPlayer := ttsInfra.NewOSCommandAudioPlayerWithLogger(playbackConfig, log)
+	if cfg.PlayerBackend != config.PlayerBackendNative {
+		return commandPlayer
+	}
+	player := ttsInfra.NewNativeAudioPlayer(playbackConfig, ttsInfra.NewCommandSink(), log)
+	player.SetFallback(commandPlayer)
+	return player
 }
 
 // TTS Service Methods
@@ -182,6 +280,13 @@ func (c *Client) SynthesizeToFile(ctx context.Context, request *ttsDomain.TTSReq
 
 // SynthesizeToFileWithHistory performs text-to-speech synthesis, writes to a file, and saves to history
 func (c *Client) SynthesizeToFileWithHistory(ctx context.Context, request *ttsDomain.TTSRequest, filePath string) (*ttsDomain.TTSResponse, error) {
+	return c.SynthesizeToFileWithEffects(ctx, request, filePath, audio.Effects{})
+}
+
+// SynthesizeToFileWithEffects is SynthesizeToFileWithHistory with audio
+// post-processing applied to the file before it is saved to history. Effects
+// require WAV or FLAC output.
+func (c *Client) SynthesizeToFileWithEffects(ctx context.Context, request *ttsDomain.TTSRequest, filePath string, effects audio.Effects) (*ttsDomain.TTSResponse, error) {
 	if err := c.ttsService.ValidateRequest(request); err != nil {
 		return nil, err
 	}
@@ -202,9 +307,17 @@ func (c *Client) SynthesizeToFileWithHistory(ctx context.Context, request *ttsDo
 	
 	// Write audio data to file
 	_, err = io.Copy(file, response.AudioData)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to write audio data to file: %w", err)
 	}
+	if !effects.IsZero() {
+		if err := audio.ProcessFile(filePath, effects); err != nil {
+			return nil, fmt.Errorf("failed to process audio: %w", err)
+		}
+	}
 	
 	// Save to history if history manager is available
 	if c.historyManager != nil && c.config.HistoryEnabled {
@@ -365,6 +478,165 @@ func (c *Client) SynthesizeStream(ctx context.Context, request *ttsDomain.TTSReq
 	return c.ttsService.SynthesizeStream(ctx, request, handler)
 }
 
+// SynthesizeLongText synthesizes text beyond the per-request length limit.
+// The text is split at sentence boundaries, chunks are synthesized in parallel
+// and the result is written to writer as a single WAV or FLAC file.
+func (c *Client) SynthesizeLongText(ctx context.Context, request *ttsDomain.TTSRequest, options *ttsDomain.LongTextOptions, writer io.Writer) (*ttsDomain.LongTextResult, error) {
+	ctx, release, err := c.approveRequestBudget(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.ttsService.SynthesizeLong(ctx, request, options, writer)
+}
+
+// SynthesizeLongTextToFileWithHistory synthesizes long text to a file and saves it to history
+func (c *Client) SynthesizeLongTextToFileWithHistory(ctx context.Context, request *ttsDomain.TTSRequest, options *ttsDomain.LongTextOptions, filePath string) (*ttsDomain.LongTextResult, error) {
+	return c.SynthesizeLongTextToFileWithEffects(ctx, request, options, filePath, audio.Effects{})
+}
+
+// SynthesizeLongTextToFileWithEffects is SynthesizeLongTextToFileWithHistory
+// with audio post-processing applied to the joined file before it is saved to
+// history
+func (c *Client) SynthesizeLongTextToFileWithEffects(ctx context.Context, request *ttsDomain.TTSRequest, options *ttsDomain.LongTextOptions, filePath string, effects audio.Effects) (*ttsDomain.LongTextResult, error) {
+	ctx, release, err := c.approveRequestBudget(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	result, err := c.ttsService.SynthesizeLong(ctx, request, options, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write audio data to file: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return nil, err
+	}
+	if !effects.IsZero() {
+		if err := audio.ProcessFile(filePath, effects); err != nil {
+			return nil, fmt.Errorf("failed to process audio: %w", err)
+		}
+	}
+
+	if c.historyManager != nil && c.config.HistoryEnabled {
+		history, err := c.SaveTTSHistory(ctx, request, filePath, result.BillingInfo)
+		if err != nil {
+			// Log error but don't fail the operation
+			c.logger.Warn("Failed to save TTS history: " + err.Error())
+		} else if history != nil {
+			result.HistoryID = history.ID
+		}
+	}
+
+	return result, nil
+}
+
+// SynthesizeWithSubtitles synthesizes text one sentence at a time, writes the
+// joined WAV or FLAC audio to writer and returns the timing of every sentence
+// in result.Cues
+func (c *Client) SynthesizeWithSubtitles(ctx context.Context, request *ttsDomain.TTSRequest, options *ttsDomain.LongTextOptions, writer io.Writer) (*ttsDomain.LongTextResult, error) {
+	return c.SynthesizeLongText(ctx, request, subtitleOptions(options), writer)
+}
+
+// SynthesizeWithSubtitlesToFile is SynthesizeWithSubtitles for a file that is
+// post-processed with effects and saved to history. Effects must not change
+// the timing of the audio (e.g. trimming silence).
+func (c *Client) SynthesizeWithSubtitlesToFile(ctx context.Context, request *ttsDomain.TTSRequest, options *ttsDomain.LongTextOptions, filePath string, effects audio.Effects) (*ttsDomain.LongTextResult, error) {
+	return c.SynthesizeLongTextToFileWithEffects(ctx, request, subtitleOptions(options), filePath, effects)
+}
+
+// WriteSubtitlesFile writes cues to an SRT or WebVTT file, chosen by the file extension
+func (c *Client) WriteSubtitlesFile(filePath string, cues []ttsDomain.SubtitleCue) error {
+	format, err := ttsDomain.GetSubtitleFormatFromFilePath(filePath)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create subtitle file: %w", err)
+	}
+	err = ttsUsecase.WriteSubtitles(file, format, cues)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write subtitle file: %w", err)
+	}
+	return nil
+}
+
+func subtitleOptions(options *ttsDomain.LongTextOptions) *ttsDomain.LongTextOptions {
+	if options == nil {
+		options = ttsDomain.DefaultLongTextOptions()
+	}
+	withSentences := *options
+	withSentences.SentencePerChunk = true
+	return &withSentences
+}
+
+// ParseScript reads a multi-speaker dialogue script (see ttsDomain.Script)
+func (c *Client) ParseScript(r io.Reader) (*ttsDomain.Script, error) {
+	return ttsUsecase.ParseScript(r)
+}
+
+// RenderScript synthesizes every line of script and writes the dialogue as a
+// single WAV or FLAC track to writer. The result is the per-line manifest.
+func (c *Client) RenderScript(ctx context.Context, script *ttsDomain.Script, options *ttsDomain.ScriptOptions, writer io.Writer) (*ttsDomain.ScriptResult, error) {
+	ctx, release, err := c.approveScriptBudget(ctx, script)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.ttsService.RenderScript(ctx, script, options, writer)
+}
+
+// RenderScriptToFile renders script to a file and saves each line to history,
+// grouped under a script session
+func (c *Client) RenderScriptToFile(ctx context.Context, script *ttsDomain.Script, options *ttsDomain.ScriptOptions, filePath string) (*ttsDomain.ScriptResult, error) {
+	ctx, release, err := c.approveScriptBudget(ctx, script)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	file, err := os.Create(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	result, err := c.ttsService.RenderScript(ctx, script, options, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write audio data to file: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(filePath)
+		return nil, err
+	}
+
+	if c.historyManager != nil && c.config.HistoryEnabled {
+		if err := c.historyManager.SaveScriptHistory(ctx, result); err != nil {
+			// Log error but don't fail the operation
+			c.logger.Warn("Failed to save script history: " + err.Error())
+		}
+	}
+	return result, nil
+}
+
+// SynthesizeLongTextStream synthesizes long text as one continuous WAV stream,
+// delivering each chunk to the handler as soon as it is ready
+func (c *Client) SynthesizeLongTextStream(ctx context.Context, request *ttsDomain.TTSRequest, options *ttsDomain.LongTextOptions, handler ttsDomain.TTSStreamHandler) (*ttsDomain.LongTextResult, error) {
+	ctx, release, err := c.approveRequestBudget(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return c.ttsService.SynthesizeLongStream(ctx, request, options, handler)
+}
+
 // Models Service Methods
 
 // SearchModels searches for available models
@@ -493,7 +765,7 @@ func (c *Client) PlayStreamWithHistory(ctx context.Context, request *ttsDomain.P
     }
 
     // Detect streaming playback capability (stdin or progressive)
-    streamingPlayback := detectStreamingPlayback(outFmt)
+    streamingPlayback := c.DetectStreamingPlayback(outFmt)
 
     response := &ttsDomain.TTSResponse{
         HistoryID:          historyID,
@@ -541,6 +813,10 @@ func detectStreamingPlayback(format ttsDomain.OutputFormat) bool {
 
 // DetectStreamingPlayback exposes playback streaming capability detection
 func (c *Client) DetectStreamingPlayback(format ttsDomain.OutputFormat) bool {
+    // The native player decodes WAV and FLAC progressively
+    if c.config.PlayerBackend == config.PlayerBackendNative && (format == ttsDomain.OutputFormatWAV || format == ttsDomain.OutputFormatFLAC) {
+        return true
+    }
     return detectStreamingPlayback(format)
 }
 
@@ -653,23 +929,89 @@ func (h *historyOnlyStreamHandler) OnError(err error) {
 }
 
 func (c *Client) PlayRequest(ctx context.Context, request *ttsDomain.PlaybackRequest) error {
-    return c.playerService.PlayRequest(ctx, request)
+    if err := checkPlaybackEnvelope(request); err != nil {
+        return err
+    }
+    // The request may be synthesized after this returns; the reservation
+    // goes with it to the daemon or onto the queue
+    ctx, err := c.reservePlaybackBudget(ctx, request)
+    if err != nil {
+        return err
+    }
+    params := &ttsDomain.DaemonPlayParams{Request: request, BudgetReservation: ttsDomain.BudgetReservationID(ctx)}
+    if forwarded, err := c.forwardToDaemon(ctx, ttsDomain.DaemonMethodPlay, params, nil); forwarded {
+        return err
+    }
+    c.applyPlayerDefaults(request)
+    if err := c.playerService.PlayRequest(ctx, request); err != nil {
+        c.releasePlaybackBudget(ctx)
+        return err
+    }
+    return nil
+}
+
+// applyPlayerDefaults asks for WAV when the request sets no format and the
+// audio has to be decoded in process: with the native player, or when the
+// request sets a start offset or fades
+func (c *Client) applyPlayerDefaults(request *ttsDomain.PlaybackRequest) {
+	if request == nil || request.TTSRequest == nil {
+		return
+	}
+	if c.config.PlayerBackend != config.PlayerBackendNative && request.Envelope().IsZero() {
+		return
+	}
+	if request.TTSRequest.OutputFormat == nil {
+		format := ttsDomain.OutputFormatWAV
+		request.TTSRequest.OutputFormat = &format
+	}
+}
+
+// checkPlaybackEnvelope refuses a start offset or fades on a request for a
+// format that cannot be decoded in process to apply them. Without a format
+// such requests are synthesized as WAV.
+func checkPlaybackEnvelope(request *ttsDomain.PlaybackRequest) error {
+	if request == nil || request.TTSRequest == nil || request.TTSRequest.OutputFormat == nil || request.Envelope().IsZero() {
+		return nil
+	}
+	switch format := *request.TTSRequest.OutputFormat; format {
+	case ttsDomain.OutputFormatWAV, ttsDomain.OutputFormatFLAC, ttsDomain.OutputFormatMP3:
+		return nil
+	default:
+		return fmt.Errorf("start offset and fades need WAV, FLAC or MP3 audio, not %s", format)
+	}
 }
 
 // PlayRequestWithHistory plays audio with concurrent history saving.
 // It auto-generates a history file path under the configured history store.
 func (c *Client) PlayRequestWithHistory(ctx context.Context, request *ttsDomain.PlaybackRequest) (*ttsDomain.TTSResponse, error) {
+    if err := checkPlaybackEnvelope(request); err != nil {
+        return nil, err
+    }
+    ctx, err := c.reservePlaybackBudget(ctx, request)
+    if err != nil {
+        return nil, err
+    }
+    params := &ttsDomain.DaemonPlayParams{Request: request, SaveHistory: true, BudgetReservation: ttsDomain.BudgetReservationID(ctx), HistoryLabels: ttsDomain.HistoryLabelsFrom(ctx)}
+    var result ttsDomain.DaemonPlayResult
+    if forwarded, err := c.forwardToDaemon(ctx, ttsDomain.DaemonMethodPlay, params, &result); forwarded {
+        return result.Response, err
+    }
+
     if c.historyManager == nil || !c.config.HistoryEnabled {
+        c.releasePlaybackBudget(ctx)
         return nil, fmt.Errorf("history is disabled or not configured")
     }
+    c.applyPlayerDefaults(request)
 
     // Determine history store directory
     storePath, err := c.config.GetHistoryStorePath()
     if err != nil {
+        c.releasePlaybackBudget(ctx)
         return nil, fmt.Errorf("failed to get history store path: %w", err)
     }
     audioDir := filepath.Join(storePath, "audio")
     if err := os.MkdirAll(audioDir, 0755); err != nil {
+        c.releasePlaybackBudget(ctx)
         return nil, fmt.Errorf("failed to create audio directory: %w", err)
     }
 
@@ -697,41 +1039,156 @@ func (c *Client) PlayRequestWithHistory(ctx context.Context, request *ttsDomain.
     // Use single-pass streaming synthesis with concurrent playback and file writing
     resp, err := c.PlayStreamWithHistory(ctx, request, filePath)
     if err != nil {
+        c.releasePlaybackBudget(ctx)
         return nil, err
     }
     return resp, nil
 }
 
+// PlayLongText plays text beyond the per-request length limit. Playback starts
+// as soon as the first chunk is synthesized; the remaining chunks follow seamlessly.
+func (c *Client) PlayLongText(ctx context.Context, request *ttsDomain.PlaybackRequest, options *ttsDomain.LongTextOptions) (*ttsDomain.LongTextResult, error) {
+	ctx, release, err := c.approvePlaybackBudget(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	// Forwarded, the daemon releases the reservation once it has
+	// synthesized the text
+	if result, forwarded, err := c.forwardLongText(ctx, request, options, false); forwarded {
+		return result, err
+	}
+	defer release()
+	return c.playerService.PlayLongRequest(ctx, request, options, "")
+}
+
+// PlayLongTextWithHistory plays long text and saves the joined audio to history as WAV
+func (c *Client) PlayLongTextWithHistory(ctx context.Context, request *ttsDomain.PlaybackRequest, options *ttsDomain.LongTextOptions) (*ttsDomain.LongTextResult, error) {
+	ctx, release, err := c.approvePlaybackBudget(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	// Forwarded, the daemon releases the reservation once it has
+	// synthesized the text
+	if result, forwarded, err := c.forwardLongText(ctx, request, options, true); forwarded {
+		return result, err
+	}
+	defer release()
+
+	if c.historyManager == nil || !c.config.HistoryEnabled {
+		return nil, fmt.Errorf("history is disabled or not configured")
+	}
+
+	storePath, err := c.config.GetHistoryStorePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history store path: %w", err)
+	}
+	audioDir := filepath.Join(storePath, "audio")
+	if err := os.MkdirAll(audioDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audio directory: %w", err)
+	}
+	timestamp := time.Now().Format("20060102_150405")
+	filePath := filepath.Join(audioDir, fmt.Sprintf("tts_%s.wav", timestamp))
+
+	result, err := c.playerService.PlayLongRequest(ctx, request, options, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The recorded audio is always WAV, whatever format the request asked for
+	historyRequest := *request.TTSRequest
+	wav := ttsDomain.OutputFormatWAV
+	historyRequest.OutputFormat = &wav
+
+	history, err := c.SaveTTSHistory(ctx, &historyRequest, filePath, result.BillingInfo)
+	if err != nil {
+		c.logger.Warn("Failed to save TTS history metadata", logger.String("error", err.Error()))
+	} else if history != nil {
+		result.HistoryID = history.ID
+	}
+	return result, nil
+}
+
+// forwardLongText forwards long-text playback to the running daemon, if any.
+// The options' progress callback cannot cross the socket and is not called.
+// Callers have approved the budget for the whole text; the daemon takes
+// their reservation over.
+func (c *Client) forwardLongText(ctx context.Context, request *ttsDomain.PlaybackRequest, options *ttsDomain.LongTextOptions, saveHistory bool) (*ttsDomain.LongTextResult, bool, error) {
+	if options == nil {
+		options = ttsDomain.DefaultLongTextOptions()
+	}
+	params := &ttsDomain.DaemonPlayParams{Request: request, SaveHistory: saveHistory, LongText: options, BudgetApproved: true, BudgetReservation: ttsDomain.BudgetReservationID(ctx)}
+	if saveHistory {
+		params.HistoryLabels = ttsDomain.HistoryLabelsFrom(ctx)
+	}
+	var result ttsDomain.DaemonPlayResult
+	forwarded, err := c.forwardToDaemon(ctx, ttsDomain.DaemonMethodPlay, params, &result)
+	return result.LongText, forwarded, err
+}
+
 // StopPlayback stops current playback and clears queue
 func (c *Client) StopPlayback() error {
+	if forwarded, err := c.forwardToDaemon(context.Background(), ttsDomain.DaemonMethodStop, nil, nil); forwarded {
+		return err
+	}
 	return c.playerService.Stop()
 }
 
 // PausePlayback pauses current playback
 func (c *Client) PausePlayback() error {
+	if forwarded, err := c.forwardToDaemon(context.Background(), ttsDomain.DaemonMethodPause, nil, nil); forwarded {
+		return err
+	}
 	return c.playerService.Pause()
 }
 
 // ResumePlayback resumes paused playback
 func (c *Client) ResumePlayback() error {
+	if forwarded, err := c.forwardToDaemon(context.Background(), ttsDomain.DaemonMethodResume, nil, nil); forwarded {
+		return err
+	}
 	return c.playerService.Resume()
 }
 
 // SetPlaybackVolume sets playback volume (0.0 to 1.0)
 func (c *Client) SetPlaybackVolume(volume float64) error {
+	params := &ttsDomain.DaemonVolumeParams{Volume: volume}
+	if forwarded, err := c.forwardToDaemon(context.Background(), ttsDomain.DaemonMethodSetVolume, params, nil); forwarded {
+		return err
+	}
 	return c.playerService.SetVolume(volume)
 }
 
 // GetPlaybackStatus returns current playback status
 func (c *Client) GetPlaybackStatus() ttsDomain.PlaybackInfo {
+	var status ttsDomain.DaemonStatus
+	forwarded, err := c.forwardToDaemon(context.Background(), ttsDomain.DaemonMethodStatus, nil, &status)
+	if forwarded && err == nil {
+		return status.Playback
+	}
+	if err != nil {
+		c.logger.Warn("Failed to get playback status from daemon", logger.String("error", err.Error()))
+	}
 	return c.playerService.GetStatus()
 }
 
 // ClearPlaybackQueue clears all items from the playback queue
 func (c *Client) ClearPlaybackQueue() {
+	forwarded, err := c.forwardToDaemon(context.Background(), ttsDomain.DaemonMethodClearQueue, nil, nil)
+	if forwarded {
+		if err != nil {
+			c.logger.Warn("Failed to clear daemon playback queue", logger.String("error", err.Error()))
+		}
+		return
+	}
 	c.playerService.ClearQueue()
 }
 
+// GetRateLimitState returns the client-side view of the API rate limit budget,
+// as last reported by the X-Aivis-RateLimit-* response headers
+func (c *Client) GetRateLimitState() http.RateLimitState {
+	return c.httpClient.RateLimitState()
+}
+
 // Configuration Methods
 
 // GetConfig returns the current configuration
@@ -777,18 +1234,40 @@ func (c *Client) UpdateConfig(cfg *config.Config) error {
 	)
 	
 	c.httpClient = http.NewClient(cfg)
+
+	budget, err := newBudgetGuard(cfg)
+	if err != nil {
+		return err
+	}
+	if budget != nil {
+		budget.SetConfirm(c.budgetConfirm)
+	}
+	c.budget = budget
+
+	credits, err := newCreditMonitor(cfg, c.logger, c.creditNotifiers)
+	if err != nil {
+		return err
+	}
+	c.credits = credits
+	c.httpClient.SetHeaderObserver(c.observeCredits)
 	
 	// Reinitialize repositories with new HTTP client
-	ttsRepo := ttsInfra.NewTTSAPIRepository(c.httpClient)
+	ttsRepo, cache, err := newTTSRepository(cfg, c.httpClient, budget)
+	if err != nil {
+		return err
+	}
+	c.cache = cache
 	modelsRepo := modelsInfra.NewModelAPIRepository(c.httpClient)
 	usersRepo := usersInfra.NewUserAPI(c.httpClient)
 	paymentRepo := paymentInfra.NewPaymentAPI(c.httpClient)
+	dictionariesRepo := dictionariesInfra.NewUserDictionaryAPI(c.httpClient)
 	
 	// Reinitialize services
 	c.ttsService = ttsUsecase.NewTTSSynthesizer(ttsRepo)
 	c.modelsService = modelsUsecase.NewModelSearcher(modelsRepo)
 	c.usersService = usersUsecase.NewUserUsecase(usersRepo)
 	c.paymentService = paymentUsecase.NewPaymentUsecase(paymentRepo)
+	c.dictionariesService = dictionariesUsecase.NewUserDictionaryUsecase(dictionariesRepo)
 	
 	// Reinitialize history repository and manager if history is enabled
 	var historyRepo ttsDomain.TTSHistoryRepository
@@ -797,7 +1276,10 @@ func (c *Client) UpdateConfig(cfg *config.Config) error {
 		if err != nil {
 			return err
 		}
-		historyRepo = ttsInfra.NewFileHistoryRepository(historyStorePath)
+		historyRepo, err = newHistoryRepository(cfg.HistoryBackend, historyStorePath)
+		if err != nil {
+			return err
+		}
 	}
 	
 	// Reinitialize audio player service with configuration
@@ -813,7 +1295,7 @@ func (c *Client) UpdateConfig(cfg *config.Config) error {
 		}
 	}
 	
-	audioPlayer := ttsInfra.NewOSCommandAudioPlayerWithLogger(playbackConfig, c.logger)
+	audioPlayer := newAudioPlayer(cfg, playbackConfig, c.logger)
 	
 	// Use global audio player service singleton
 	globalPlayerService := ttsUsecase.GetGlobalAudioPlayerService()
@@ -824,6 +1306,9 @@ func (c *Client) UpdateConfig(cfg *config.Config) error {
 	}
 	
 	globalPlayerService.InitializeWithLogger(c.ttsService, audioPlayer, playerConfig, c.logger)
+	globalPlayerService.SetNewPlayerFactory(func() ttsDomain.AudioPlayer {
+		return newAudioPlayer(cfg, playbackConfig, c.logger)
+	})
 	
 	// Create adapter to maintain compatibility with existing interface
 	c.playerService = ttsUsecase.NewAudioPlayerServiceAdapter(globalPlayerService)
@@ -831,9 +1316,16 @@ func (c *Client) UpdateConfig(cfg *config.Config) error {
 	// Reinitialize history manager if history is enabled
 	if cfg.HistoryEnabled && historyRepo != nil {
 		c.historyManager = ttsUsecase.NewTTSHistoryManager(historyRepo, ttsRepo, audioPlayer, cfg)
+		if cfg.CacheEnabled {
+			c.historyManager.SetCache(cache)
+		}
 	} else {
 		c.historyManager = nil
 	}
+
+	if !c.servingDaemon {
+		c.daemon = newDaemonClient(cfg)
+	}
 	
 	return nil
 }
@@ -882,6 +1374,66 @@ func (c *Client) GetUsageSummaries(ctx context.Context, period string, startDate
 	return c.paymentService.GetUsageSummaries(ctx, period, startDate, endDate, modelID)
 }
 
+// User Dictionary Methods
+
+// ListUserDictionaries retrieves the user dictionaries of the account
+func (c *Client) ListUserDictionaries(ctx context.Context) (*dictionariesDomain.UserDictionaryListResponse, error) {
+	return c.dictionariesService.ListDictionaries(ctx)
+}
+
+// GetUserDictionary retrieves a user dictionary with its words
+func (c *Client) GetUserDictionary(ctx context.Context, dictionaryUUID string) (*dictionariesDomain.UserDictionary, error) {
+	return c.dictionariesService.GetDictionary(ctx, dictionaryUUID)
+}
+
+// CreateUserDictionary creates an empty user dictionary
+func (c *Client) CreateUserDictionary(ctx context.Context, name, description string) (*dictionariesDomain.UserDictionary, error) {
+	return c.dictionariesService.CreateDictionary(ctx, name, description)
+}
+
+// UpdateUserDictionary changes the name or description of a user dictionary
+func (c *Client) UpdateUserDictionary(ctx context.Context, dictionaryUUID string, update *dictionariesDomain.UserDictionaryUpdate) (*dictionariesDomain.UserDictionary, error) {
+	return c.dictionariesService.UpdateDictionary(ctx, dictionaryUUID, update)
+}
+
+// DeleteUserDictionary deletes a user dictionary
+func (c *Client) DeleteUserDictionary(ctx context.Context, dictionaryUUID string) error {
+	return c.dictionariesService.DeleteDictionary(ctx, dictionaryUUID)
+}
+
+// AddDictionaryWord adds a word to a user dictionary
+func (c *Client) AddDictionaryWord(ctx context.Context, dictionaryUUID string, word dictionariesDomain.Word) (*dictionariesDomain.Word, error) {
+	return c.dictionariesService.AddWord(ctx, dictionaryUUID, word)
+}
+
+// SetDictionaryWord adds a word to a user dictionary or replaces the word
+// with the same surface form, reporting whether it was added
+func (c *Client) SetDictionaryWord(ctx context.Context, dictionaryUUID string, word dictionariesDomain.Word) (*dictionariesDomain.Word, bool, error) {
+	return c.dictionariesService.SetWord(ctx, dictionaryUUID, word)
+}
+
+// UpdateDictionaryWord changes the given fields of a dictionary word
+func (c *Client) UpdateDictionaryWord(ctx context.Context, dictionaryUUID, wordUUID string, update *dictionariesDomain.WordUpdate) (*dictionariesDomain.Word, error) {
+	return c.dictionariesService.UpdateWord(ctx, dictionaryUUID, wordUUID, update)
+}
+
+// DeleteDictionaryWord removes a word from a user dictionary
+func (c *Client) DeleteDictionaryWord(ctx context.Context, dictionaryUUID, wordUUID string) error {
+	return c.dictionariesService.DeleteWord(ctx, dictionaryUUID, wordUUID)
+}
+
+// ImportDictionaryCSV merges the words of a CSV file into a user dictionary;
+// with replace, words missing from the file are removed
+func (c *Client) ImportDictionaryCSV(ctx context.Context, dictionaryUUID string, r io.Reader, replace bool) (*dictionariesDomain.WordImportResult, error) {
+	return c.dictionariesService.ImportWordsCSV(ctx, dictionaryUUID, r, replace)
+}
+
+// ExportDictionaryCSV writes the words of a user dictionary as CSV and
+// returns the number of words written
+func (c *Client) ExportDictionaryCSV(ctx context.Context, dictionaryUUID string, w io.Writer) (int, error) {
+	return c.dictionariesService.ExportWordsCSV(ctx, dictionaryUUID, w)
+}
+
 // TTS History Management Methods
 
 // GetTTSHistory retrieves a specific TTS history record by ID
@@ -892,6 +1444,23 @@ func (c *Client) GetTTSHistory(ctx context.Context, id int) (*ttsDomain.TTSHisto
 	return c.historyManager.GetHistory(ctx, id)
 }
 
+// SaveTTSHistory records an already written audio file in TTS history.
+// Credits are taken from billingInfo when available.
+func (c *Client) SaveTTSHistory(ctx context.Context, request *ttsDomain.TTSRequest, filePath string, billingInfo *http.BillingInfo) (*ttsDomain.TTSHistory, error) {
+	if c.historyManager == nil || !c.config.HistoryEnabled {
+		return nil, fmt.Errorf("history management is disabled")
+	}
+
+	var credits *float64
+	if billingInfo != nil && billingInfo.CreditsUsed != "" {
+		if creditsUsed, err := strconv.ParseFloat(billingInfo.CreditsUsed, 64); err == nil {
+			credits = &creditsUsed
+		}
+	}
+
+	return c.historyManager.SaveHistory(ctx, request, filePath, credits)
+}
+
 // ListTTSHistory lists TTS history records with pagination and filtering
 func (c *Client) ListTTSHistory(ctx context.Context, request *ttsDomain.TTSHistorySearchRequest) (*ttsDomain.TTSHistoryListResponse, error) {
 	if c.historyManager == nil {
@@ -900,14 +1469,47 @@ func (c *Client) ListTTSHistory(ctx context.Context, request *ttsDomain.TTSHisto
 	return c.historyManager.ListHistory(ctx, request)
 }
 
+// SearchTTSHistory ranks TTS history records by how well their text matches
+// the query, with optional fuzzy matching and filters
+func (c *Client) SearchTTSHistory(ctx context.Context, query *ttsDomain.TTSHistoryQuery) (*ttsDomain.TTSHistorySearchResponse, error) {
+	if c.historyManager == nil {
+		return nil, fmt.Errorf("history management is disabled")
+	}
+	return c.historyManager.SearchHistory(ctx, query)
+}
+
 // PlayTTSHistory replays audio from TTS history
 func (c *Client) PlayTTSHistory(ctx context.Context, id int, playbackOptions *ttsDomain.PlaybackRequest) error {
+	params := &ttsDomain.DaemonPlayHistoryParams{ID: id, Options: playbackOptions}
+	if forwarded, err := c.forwardToDaemon(ctx, ttsDomain.DaemonMethodPlayHistory, params, nil); forwarded {
+		return err
+	}
 	if c.historyManager == nil {
 		return fmt.Errorf("history management is disabled")
 	}
 	return c.historyManager.PlayHistory(ctx, id, playbackOptions)
 }
 
+// RegenerateFromHistory re-submits the request stored in a history record with
+// optional overrides (model, speaker, style, rate, pitch, format, ...) and
+// saves the result as a new record whose ParentID references the original
+func (c *Client) RegenerateFromHistory(ctx context.Context, id int, overrides *ttsDomain.TTSRequestOverrides) (*ttsDomain.TTSHistory, error) {
+	if c.historyManager == nil {
+		return nil, fmt.Errorf("history management is disabled")
+	}
+	return c.historyManager.RegenerateHistory(ctx, id, overrides)
+}
+
+// PlayTTSHistorySequence plays history records one after another with gap in
+// between, e.g. to A/B compare an original with its regenerated version.
+// onStart, if not nil, is called before each record starts playing.
+func (c *Client) PlayTTSHistorySequence(ctx context.Context, ids []int, gap time.Duration, onStart func(index int, history *ttsDomain.TTSHistory)) error {
+	if c.historyManager == nil {
+		return fmt.Errorf("history management is disabled")
+	}
+	return c.historyManager.PlayHistorySequence(ctx, ids, gap, onStart)
+}
+
 // DeleteTTSHistory removes a TTS history record
 func (c *Client) DeleteTTSHistory(ctx context.Context, id int) error {
 	if c.historyManager == nil {
@@ -932,7 +1534,57 @@ func (c *Client) GetTTSHistoryStats(ctx context.Context) (*ttsDomain.TTSHistoryS
 	return c.historyManager.GetHistoryStats(ctx)
 }
 
+// ExportTTSHistory writes the selected history records and their audio files
+// to a zip or tar archive at path, e.g. for backups or moving to another machine
+func (c *Client) ExportTTSHistory(ctx context.Context, path string, options *ttsDomain.TTSHistoryExportOptions) (*ttsDomain.TTSHistoryExportResult, error) {
+	if c.historyManager == nil {
+		return nil, fmt.Errorf("history management is disabled")
+	}
+	return c.historyManager.ExportHistory(ctx, path, options)
+}
+
+// ImportTTSHistory merges a history archive created by ExportTTSHistory into
+// the local history, assigning new IDs and skipping records already present
+func (c *Client) ImportTTSHistory(ctx context.Context, path string) (*ttsDomain.TTSHistoryImportResult, error) {
+	if c.historyManager == nil {
+		return nil, fmt.Errorf("history management is disabled")
+	}
+	return c.historyManager.ImportHistory(ctx, path)
+}
+
+// Synthesis Cache Methods
+
+// GetCacheStats retrieves statistics about the local synthesis cache
+func (c *Client) GetCacheStats(ctx context.Context) (*ttsDomain.TTSCacheStats, error) {
+	if c.cache == nil {
+		return nil, fmt.Errorf("synthesis cache is unavailable")
+	}
+	return c.cache.Stats(ctx)
+}
+
+// ClearCache removes all audio from the local synthesis cache
+func (c *Client) ClearCache(ctx context.Context) error {
+	if c.cache == nil {
+		return fmt.Errorf("synthesis cache is unavailable")
+	}
+	return c.cache.Clear(ctx)
+}
+
+// PruneCache removes expired cache entries and evicts the least recently used
+// ones until the cache fits in maxSize bytes (0 uses the configured limit)
+func (c *Client) PruneCache(ctx context.Context, maxSize int64) (*ttsDomain.TTSCachePruneResult, error) {
+	if c.cache == nil {
+		return nil, fmt.Errorf("synthesis cache is unavailable")
+	}
+	return c.cache.Prune(ctx, maxSize)
+}
+
 // NewTTSHistorySearchRequest creates a new TTS history search request builder
 func (c *Client) NewTTSHistorySearchRequest() *ttsDomain.TTSHistorySearchRequestBuilder {
 	return ttsDomain.NewTTSHistorySearchRequest()
 }
+
+// NewTTSHistoryQuery creates a new TTS history full-text query builder
+func (c *Client) NewTTSHistoryQuery(query string) *ttsDomain.TTSHistoryQueryBuilder {
+	return ttsDomain.NewTTSHistoryQuery(query)
+}
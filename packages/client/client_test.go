@@ -6,8 +6,13 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/kajidog/aivis-cloud-cli/client/config"
 	paymentdomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
@@ -243,6 +248,37 @@ func TestSynthesize(t *testing.T) {
 	}
 }
 
+func TestSynthesizeWritesNoBudgetLedgerByDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("X-Aivis-Character-Count", "5")
+		w.Header().Set("X-Aivis-Credits-Used", "2.5")
+		w.Header().Set("X-Aivis-Credits-Remaining", "97.5")
+		w.Write([]byte("fake-audio-data"))
+	}
+	client, teardown := setupTestClient(t, handler)
+	defer teardown()
+
+	resp, err := client.Synthesize(context.Background(), &ttsdomain.TTSRequest{ModelUUID: "test-model-uuid", Text: "hello"})
+	if err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+	resp.AudioData.Close()
+
+	// Without budgets there is no ledger to keep, but the last observed
+	// balance is still recorded for `users me --credits`
+	if _, err := os.Stat(filepath.Join(home, ".aivis-cli", "budget.json")); !os.IsNotExist(err) {
+		t.Errorf("budget.json was written (stat error %v)", err)
+	}
+	last, err := client.GetLastObservedCredits(context.Background())
+	if err != nil || last == nil || last.Remaining != 97.5 {
+		t.Errorf("GetLastObservedCredits() = %+v, %v, want 97.5 remaining", last, err)
+	}
+}
+
 func TestGetMe(t *testing.T) {
 	handler := func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/v1/users/me" {
@@ -463,3 +499,264 @@ func TestHTTPErrorHandling(t *testing.T) {
 		})
 	}
 }
+
+// setupRetryTestClient creates a client with fast retry backoff for retry tests.
+func setupRetryTestClient(t *testing.T, maxRetries int, handler http.HandlerFunc) (*Client, func()) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+
+	cfg := config.NewConfig("test_api_key").
+		WithMaxRetries(maxRetries).
+		WithRetryBackoff(time.Millisecond, 10*time.Millisecond)
+	cfg.BaseURL = server.URL
+
+	client, err := NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	return client, server.Close
+}
+
+// TestRetryPolicy tests automatic retries for transient failures
+func TestRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxRetries   int
+		failures     int
+		failStatus   int
+		headers      map[string]string
+		call         func(*Client) error
+		wantErr      bool
+		wantAttempts int32
+	}{
+		{
+			name:       "GET recovers after 503",
+			maxRetries: 2,
+			failures:   2,
+			failStatus: 503,
+			call: func(c *Client) error {
+				_, err := c.GetMe(context.Background())
+				return err
+			},
+			wantAttempts: 3,
+		},
+		{
+			name:       "GET gives up after max retries",
+			maxRetries: 1,
+			failures:   5,
+			failStatus: 500,
+			call: func(c *Client) error {
+				_, err := c.GetMe(context.Background())
+				return err
+			},
+			wantErr:      true,
+			wantAttempts: 2,
+		},
+		{
+			name:       "429 honors Retry-After",
+			maxRetries: 1,
+			failures:   1,
+			failStatus: 429,
+			headers:    map[string]string{"Retry-After": "0"},
+			call: func(c *Client) error {
+				_, err := c.GetMe(context.Background())
+				return err
+			},
+			wantAttempts: 2,
+		},
+		{
+			name:       "429 honors rate limit reset header",
+			maxRetries: 1,
+			failures:   1,
+			failStatus: 429,
+			headers:    map[string]string{"X-Aivis-RateLimit-Requests-Reset": "0"},
+			call: func(c *Client) error {
+				_, err := c.GetMe(context.Background())
+				return err
+			},
+			wantAttempts: 2,
+		},
+		{
+			name:       "client errors are not retried",
+			maxRetries: 3,
+			failures:   5,
+			failStatus: 401,
+			call: func(c *Client) error {
+				_, err := c.GetMe(context.Background())
+				return err
+			},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:       "non-idempotent POST is not retried",
+			maxRetries: 3,
+			failures:   5,
+			failStatus: 503,
+			call: func(c *Client) error {
+				_, err := c.CreateAPIKey(context.Background(), "key")
+				return err
+			},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+		{
+			name:       "synthesis is retried before streaming starts",
+			maxRetries: 2,
+			failures:   1,
+			failStatus: 502,
+			call: func(c *Client) error {
+				resp, err := c.Synthesize(context.Background(), &ttsdomain.TTSRequest{ModelUUID: "m", Text: "hello"})
+				if err == nil {
+					resp.AudioData.Close()
+				}
+				return err
+			},
+			wantAttempts: 2,
+		},
+		{
+			name:       "retries disabled",
+			maxRetries: 0,
+			failures:   1,
+			failStatus: 503,
+			call: func(c *Client) error {
+				_, err := c.GetMe(context.Background())
+				return err
+			},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				n := atomic.AddInt32(&attempts, 1)
+				if int(n) <= tt.failures {
+					for k, v := range tt.headers {
+						w.Header().Set(k, v)
+					}
+					w.WriteHeader(tt.failStatus)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id": "test-user-id"}`))
+			}
+
+			client, teardown := setupRetryTestClient(t, tt.maxRetries, handler)
+			defer teardown()
+
+			err := tt.call(client)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(&attempts); got != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, got)
+			}
+		})
+	}
+}
+
+// TestRateLimiter tests that requests are delayed once the advertised budget is exhausted
+func TestRateLimiter(t *testing.T) {
+	var mu sync.Mutex
+	var requestTimes []time.Time
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requestTimes = append(requestTimes, time.Now())
+		mu.Unlock()
+
+		w.Header().Set("X-Aivis-RateLimit-Requests-Limit", "5")
+		w.Header().Set("X-Aivis-RateLimit-Requests-Remaining", "0")
+		w.Header().Set("X-Aivis-RateLimit-Requests-Reset", "0.3")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "test-user-id"}`))
+	}
+
+	client, teardown := setupTestClient(t, handler)
+	defer teardown()
+
+	if state := client.GetRateLimitState(); state.Known {
+		t.Fatalf("Expected unknown rate limit state before any request, got %+v", state)
+	}
+
+	if _, err := client.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe() error = %v", err)
+	}
+
+	state := client.GetRateLimitState()
+	if !state.Known || state.Limit != 5 || state.Remaining != 0 || !state.Throttled {
+		t.Errorf("Unexpected rate limit state: %+v", state)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetMe(context.Background()); err != nil {
+				t.Errorf("GetMe() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(requestTimes) != 3 {
+		t.Fatalf("Expected 3 requests, got %d", len(requestTimes))
+	}
+	for _, at := range requestTimes[1:] {
+		if gap := at.Sub(requestTimes[0]); gap < 250*time.Millisecond {
+			t.Errorf("Expected request to be delayed until reset, got gap %v", gap)
+		}
+	}
+}
+
+// TestRateLimiterCancellation tests that a throttled request honors context cancellation
+func TestRateLimiterCancellation(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Aivis-RateLimit-Requests-Limit", "5")
+		w.Header().Set("X-Aivis-RateLimit-Requests-Remaining", "0")
+		w.Header().Set("X-Aivis-RateLimit-Requests-Reset", "60")
+		w.Write([]byte(`{}`))
+	}
+
+	client, teardown := setupTestClient(t, handler)
+	defer teardown()
+
+	if _, err := client.GetMe(context.Background()); err != nil {
+		t.Fatalf("GetMe() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.GetMe(ctx); err == nil {
+		t.Error("Expected throttled request to fail when context expires")
+	}
+}
+
+// TestPlayRequestRefusesEnvelopeForUndecodableFormat tests that a start
+// offset or fades fail up front for formats that cannot be shaped
+func TestPlayRequestRefusesEnvelopeForUndecodableFormat(t *testing.T) {
+	var calls int32
+	client, teardown := setupTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	})
+	defer teardown()
+
+	tts := ttsdomain.NewTTSRequestBuilder("model", "こんにちは").WithOutputFormat(ttsdomain.OutputFormatAAC).Build()
+	request := ttsdomain.NewPlaybackRequest(tts).WithFadeOut(time.Second).Build()
+	if err := client.PlayRequest(context.Background(), request); err == nil || !strings.Contains(err.Error(), "WAV, FLAC or MP3") {
+		t.Errorf("PlayRequest() error = %v, want the format refused", err)
+	}
+	if _, err := client.PlayRequestWithHistory(context.Background(), request); err == nil {
+		t.Error("PlayRequestWithHistory() must refuse the format too")
+	}
+	if atomic.LoadInt32(&calls) != 0 {
+		t.Errorf("API called %d times, want none", calls)
+	}
+}
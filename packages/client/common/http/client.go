@@ -18,16 +18,41 @@ import (
 type Client struct {
 	config     *config.Config
 	httpClient *http.Client
+
+	// rateLimiter paces requests by the rate limit headers; nil when disabled
+	rateLimiter *RateLimiter
+
+	// headerObserver is called with the headers of every API response
+	headerObserver func(http.Header)
 }
 
 // NewClient creates a new HTTP client
 func NewClient(cfg *config.Config) *Client {
-	return &Client{
+	client := &Client{
 		config: cfg,
 		httpClient: &http.Client{
 			Timeout: cfg.HTTPTimeout,
 		},
 	}
+	if cfg.RateLimitEnabled {
+		client.rateLimiter = NewRateLimiter()
+	}
+	return client
+}
+
+// RateLimitState returns the last rate limit budget observed from the API
+func (c *Client) RateLimitState() RateLimitState {
+	if c.rateLimiter == nil {
+		return RateLimitState{}
+	}
+	return c.rateLimiter.State()
+}
+
+// SetHeaderObserver sets a function called with the headers of every API
+// response, e.g. to follow the credit balance they report. It must be set
+// before the client is used.
+func (c *Client) SetHeaderObserver(observer func(http.Header)) {
+	c.headerObserver = observer
 }
 
 // Request represents an HTTP request
@@ -37,6 +62,11 @@ type Request struct {
 	Body    interface{}
 	Query   url.Values
 	Headers map[string]string
+
+	// Retry marks a non-GET request as safe to retry. Retries only happen
+	// before a successful response is returned, so streaming bodies are
+	// never replayed once the caller has started reading them.
+	Retry bool
 }
 
 // Response represents an HTTP response
@@ -48,14 +78,9 @@ type Response struct {
 
 // Do executes an HTTP request
 func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
-	httpReq, err := c.buildHTTPRequest(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to build HTTP request: %w", err)
-	}
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.send(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+		return nil, err
 	}
 
 	// Check for API errors based on status code
@@ -97,6 +122,62 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 	}, nil
 }
 
+// send executes the request and returns the last response, whatever its
+// status. Failed attempts are retried with exponential backoff when the
+// request is retryable (see Request.Retry) and the failure is transient: a
+// transport error, 429 or a 5xx gateway/server error.
+func (c *Client) send(ctx context.Context, req *Request) (*http.Response, error) {
+	maxAttempts := 1
+	if c.isRetryable(req) {
+		maxAttempts += c.config.MaxRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		httpReq, err := c.buildHTTPRequest(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build HTTP request: %w", err)
+		}
+
+		lastAttempt := attempt+1 >= maxAttempts
+
+		if c.rateLimiter != nil {
+			if err := c.rateLimiter.Wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			if lastAttempt || ctx.Err() != nil {
+				return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+			}
+			if waitErr := sleepContext(ctx, c.backoffDelay(attempt, nil)); waitErr != nil {
+				return nil, fmt.Errorf("failed to execute HTTP request: %w", err)
+			}
+			continue
+		}
+
+		if c.rateLimiter != nil {
+			c.rateLimiter.Update(resp.Header, resp.StatusCode)
+		}
+		if c.headerObserver != nil {
+			c.headerObserver(resp.Header)
+		}
+
+		if !lastAttempt && isRetryableStatus(resp.StatusCode) {
+			delay := c.backoffDelay(attempt, resp.Header)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			if err := sleepContext(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		return resp, nil
+	}
+}
+
 // DoJSON executes an HTTP request and unmarshals JSON response
 func (c *Client) DoJSON(ctx context.Context, req *Request, result interface{}) error {
 	resp, err := c.Do(ctx, req)
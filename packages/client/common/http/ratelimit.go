@@ -0,0 +1,150 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimitState is a snapshot of the client-side rate limiter
+type RateLimitState struct {
+	// Known is false until a response carrying rate limit headers has been observed
+	Known     bool      `json:"known"`
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"reset_at,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+	// Throttled is true when requests are currently being delayed until ResetAt
+	Throttled bool `json:"throttled"`
+}
+
+// RateLimiter is a token bucket seeded from the X-Aivis-RateLimit-* response
+// headers. The server's view is authoritative: every response overwrites the
+// local bucket, and in between responses each request consumes one token so
+// that concurrent callers sharing a Client do not overshoot the budget.
+type RateLimiter struct {
+	mu        sync.Mutex
+	known     bool
+	limit     int
+	remaining int
+	resetAt   time.Time
+	updatedAt time.Time
+	now       func() time.Time
+}
+
+// NewRateLimiter creates an empty rate limiter that never delays until seeded
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{now: time.Now}
+}
+
+// Wait blocks until a request may be sent and reserves a token for it
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := l.now()
+		l.refill(now)
+
+		if !l.known || l.remaining > 0 || l.resetAt.IsZero() {
+			if l.known && l.remaining > 0 {
+				l.remaining--
+			}
+			l.mu.Unlock()
+			return nil
+		}
+
+		delay := l.resetAt.Sub(now)
+		l.mu.Unlock()
+
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// refill restores the bucket once the reset time has passed. Caller must hold mu.
+func (l *RateLimiter) refill(now time.Time) {
+	if !l.known || l.resetAt.IsZero() || now.Before(l.resetAt) {
+		return
+	}
+	if l.limit > 0 {
+		l.remaining = l.limit
+	} else {
+		// Without a known limit we cannot size the bucket; stop throttling
+		l.known = false
+	}
+	l.resetAt = time.Time{}
+}
+
+// Update seeds the bucket from response headers. A 429 response empties the
+// bucket even when the server omits the remaining count.
+func (l *RateLimiter) Update(headers http.Header, statusCode int) {
+	limit, hasLimit := parseHeaderInt(headers, "X-Aivis-RateLimit-Requests-Limit")
+	remaining, hasRemaining := parseHeaderInt(headers, "X-Aivis-RateLimit-Requests-Remaining")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	reset, hasReset := time.Duration(0), false
+	if v := strings.TrimSpace(headers.Get("X-Aivis-RateLimit-Requests-Reset")); v != "" {
+		reset, hasReset = parseResetValue(v, now)
+	}
+
+	if statusCode == http.StatusTooManyRequests {
+		remaining, hasRemaining = 0, true
+		if !hasReset {
+			reset, hasReset = retryAfterHint(headers, now)
+		}
+	}
+
+	if !hasLimit && !hasRemaining {
+		return
+	}
+
+	l.known = true
+	l.updatedAt = now
+	if hasLimit {
+		l.limit = limit
+	}
+	if hasRemaining {
+		l.remaining = remaining
+	} else if hasLimit {
+		l.remaining = limit
+	}
+	if hasReset {
+		l.resetAt = now.Add(reset)
+	}
+}
+
+// State returns a snapshot of the limiter
+func (l *RateLimiter) State() RateLimitState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+
+	return RateLimitState{
+		Known:     l.known,
+		Limit:     l.limit,
+		Remaining: l.remaining,
+		ResetAt:   l.resetAt,
+		UpdatedAt: l.updatedAt,
+		Throttled: l.known && l.remaining <= 0 && now.Before(l.resetAt),
+	}
+}
+
+func parseHeaderInt(headers http.Header, key string) (int, bool) {
+	v := strings.TrimSpace(headers.Get(key))
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return n, true
+}
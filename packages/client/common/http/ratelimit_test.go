@@ -0,0 +1,92 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newTestRateLimiter(now *time.Time) *RateLimiter {
+	l := NewRateLimiter()
+	l.now = func() time.Time { return *now }
+	return l
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers http.Header
+		status  int
+		want    RateLimitState
+	}{
+		{name: "no headers", status: 200},
+		{
+			name:    "limit and remaining",
+			headers: http.Header{"X-Aivis-Ratelimit-Requests-Limit": {"10"}, "X-Aivis-Ratelimit-Requests-Remaining": {"4"}, "X-Aivis-Ratelimit-Requests-Reset": {"30"}},
+			status:  200,
+			want:    RateLimitState{Known: true, Limit: 10, Remaining: 4, ResetAt: now.Add(30 * time.Second), UpdatedAt: now},
+		},
+		{
+			name:    "limit only fills the bucket",
+			headers: http.Header{"X-Aivis-Ratelimit-Requests-Limit": {"10"}},
+			status:  200,
+			want:    RateLimitState{Known: true, Limit: 10, Remaining: 10, UpdatedAt: now},
+		},
+		{
+			name:    "429 empties the bucket until Retry-After",
+			headers: http.Header{"Retry-After": {"5"}},
+			status:  429,
+			want:    RateLimitState{Known: true, ResetAt: now.Add(5 * time.Second), UpdatedAt: now, Throttled: true},
+		},
+		{
+			name:    "invalid counts ignored",
+			headers: http.Header{"X-Aivis-Ratelimit-Requests-Limit": {"many"}, "X-Aivis-Ratelimit-Requests-Remaining": {"-1"}},
+			status:  200,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newTestRateLimiter(&now)
+			l.Update(tt.headers, tt.status)
+			if got := l.State(); got != tt.want {
+				t.Errorf("State() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newTestRateLimiter(&now)
+	l.Update(http.Header{
+		"X-Aivis-Ratelimit-Requests-Limit":     {"3"},
+		"X-Aivis-Ratelimit-Requests-Remaining": {"1"},
+		"X-Aivis-Ratelimit-Requests-Reset":     {"60"},
+	}, 200)
+
+	// The last token is handed out without waiting
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if state := l.State(); state.Remaining != 0 || !state.Throttled {
+		t.Fatalf("State() = %+v, want an exhausted bucket", state)
+	}
+
+	// An exhausted bucket waits for the reset and honors cancellation
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait() on an exhausted bucket error = %v, want DeadlineExceeded", err)
+	}
+
+	// After the reset the bucket is refilled to the limit
+	now = now.Add(time.Minute)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() after reset error = %v", err)
+	}
+	if state := l.State(); state.Remaining != 2 || state.Throttled {
+		t.Errorf("State() after reset = %+v, want 2 remaining", state)
+	}
+}
@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isRetryable reports whether a request may be attempted more than once
+func (c *Client) isRetryable(req *Request) bool {
+	if c.config.MaxRetries <= 0 {
+		return false
+	}
+	switch strings.ToUpper(req.Method) {
+	case "", http.MethodGet, http.MethodHead:
+		return true
+	}
+	return req.Retry
+}
+
+// isRetryableStatus reports whether an HTTP status indicates a transient failure
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoffDelay returns the delay before the next attempt.
+// Server hints (Retry-After, X-Aivis-RateLimit-Requests-Reset) take precedence
+// over exponential backoff; both are capped at RetryMaxDelay.
+func (c *Client) backoffDelay(attempt int, headers http.Header) time.Duration {
+	maxDelay := c.config.RetryMaxDelay
+
+	if hint, ok := retryAfterHint(headers, time.Now()); ok {
+		if maxDelay > 0 && hint > maxDelay {
+			return maxDelay
+		}
+		return hint
+	}
+
+	delay := c.config.RetryBaseDelay
+	for i := 0; i < attempt && (maxDelay <= 0 || delay < maxDelay); i++ {
+		delay *= 2
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Equal jitter: keep half of the delay and randomize the other half
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// retryAfterHint extracts the server-requested wait time from response headers
+func retryAfterHint(headers http.Header, now time.Time) (time.Duration, bool) {
+	if headers == nil {
+		return 0, false
+	}
+
+	if v := strings.TrimSpace(headers.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return nonNegative(t.Sub(now)), true
+		}
+	}
+
+	if v := strings.TrimSpace(headers.Get("X-Aivis-RateLimit-Requests-Reset")); v != "" {
+		if d, ok := parseResetValue(v, now); ok {
+			return d, true
+		}
+	}
+
+	return 0, false
+}
+
+// parseResetValue interprets a rate limit reset header, which may be expressed
+// as seconds until reset, a Unix timestamp, or an RFC 3339 timestamp
+func parseResetValue(v string, now time.Time) (time.Duration, bool) {
+	if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 {
+		// Values this large can only be absolute Unix timestamps
+		if f > 1e9 {
+			return nonNegative(time.Unix(0, int64(f*float64(time.Second))).Sub(now)), true
+		}
+		return time.Duration(f * float64(time.Second)), true
+	}
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return nonNegative(t.Sub(now)), true
+	}
+	return 0, false
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// sleepContext waits for the given duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
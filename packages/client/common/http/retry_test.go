@@ -0,0 +1,110 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+)
+
+func newRetryTestClient(maxRetries int) *Client {
+	cfg := config.NewConfig("test").WithRetryBackoff(100*time.Millisecond, 2*time.Second)
+	cfg.MaxRetries = maxRetries
+	return NewClient(cfg)
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		req        Request
+		want       bool
+	}{
+		{name: "GET", maxRetries: 2, req: Request{Method: http.MethodGet}, want: true},
+		{name: "default method", maxRetries: 2, req: Request{}, want: true},
+		{name: "lowercase head", maxRetries: 2, req: Request{Method: "head"}, want: true},
+		{name: "POST", maxRetries: 2, req: Request{Method: http.MethodPost}},
+		{name: "POST marked retryable", maxRetries: 2, req: Request{Method: http.MethodPost, Retry: true}, want: true},
+		{name: "DELETE", maxRetries: 2, req: Request{Method: http.MethodDelete}},
+		{name: "retries disabled", maxRetries: 0, req: Request{Method: http.MethodGet}},
+		{name: "retries disabled for marked POST", maxRetries: 0, req: Request{Method: http.MethodPost, Retry: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newRetryTestClient(tt.maxRetries).isRetryable(&tt.req); got != tt.want {
+				t.Errorf("isRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	for status, want := range map[int]bool{
+		200: false, 400: false, 401: false, 402: false, 404: false, 422: false,
+		429: true, 500: true, 502: true, 503: true, 504: true, 501: false,
+	} {
+		if got := isRetryableStatus(status); got != want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	tests := []struct {
+		name     string
+		attempt  int
+		headers  http.Header
+		min, max time.Duration
+	}{
+		// Equal jitter keeps between half and all of the exponential delay
+		{name: "first attempt", attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "third attempt", attempt: 2, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		{name: "exponential delay capped", attempt: 10, min: time.Second, max: 2 * time.Second},
+		{name: "Retry-After seconds", headers: http.Header{"Retry-After": {"1"}}, min: time.Second, max: time.Second},
+		{name: "Retry-After zero", attempt: 3, headers: http.Header{"Retry-After": {"0"}}, min: 0, max: 0},
+		{name: "Retry-After date capped", headers: http.Header{"Retry-After": {future}}, min: 2 * time.Second, max: 2 * time.Second},
+		{name: "reset seconds", headers: http.Header{"X-Aivis-Ratelimit-Requests-Reset": {"1.5"}}, min: 1500 * time.Millisecond, max: 1500 * time.Millisecond},
+		{name: "Retry-After wins over reset", headers: http.Header{"Retry-After": {"1"}, "X-Aivis-Ratelimit-Requests-Reset": {"0"}}, min: time.Second, max: time.Second},
+		{name: "invalid Retry-After falls back", headers: http.Header{"Retry-After": {"soon"}}, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+	}
+	client := newRetryTestClient(2)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 20; i++ {
+				if got := client.backoffDelay(tt.attempt, tt.headers); got < tt.min || got > tt.max {
+					t.Fatalf("backoffDelay() = %v, want within [%v, %v]", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryAfterHint(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		headers http.Header
+		want    time.Duration
+		ok      bool
+	}{
+		{name: "no headers"},
+		{name: "seconds", headers: http.Header{"Retry-After": {"7"}}, want: 7 * time.Second, ok: true},
+		{name: "HTTP date", headers: http.Header{"Retry-After": {"Thu, 01 Jan 2026 12:00:30 GMT"}}, want: 30 * time.Second, ok: true},
+		{name: "past HTTP date", headers: http.Header{"Retry-After": {"Thu, 01 Jan 2026 11:00:00 GMT"}}, want: 0, ok: true},
+		{name: "negative seconds ignored", headers: http.Header{"Retry-After": {"-1"}}},
+		{name: "reset seconds", headers: http.Header{"X-Aivis-Ratelimit-Requests-Reset": {"2"}}, want: 2 * time.Second, ok: true},
+		{name: "reset Unix timestamp", headers: http.Header{"X-Aivis-Ratelimit-Requests-Reset": {"1767268810"}}, want: 10 * time.Second, ok: true},
+		{name: "reset RFC 3339", headers: http.Header{"X-Aivis-Ratelimit-Requests-Reset": {"2026-01-01T12:01:00Z"}}, want: time.Minute, ok: true},
+		{name: "invalid reset", headers: http.Header{"X-Aivis-Ratelimit-Requests-Reset": {"later"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfterHint(tt.headers, now)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("retryAfterHint() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package pager iterates over the items of paginated list endpoints, fetching
+// pages lazily until the endpoint reports no more.
+package pager
+
+import "context"
+
+// DefaultPageSize is the page size used when none is given
+const DefaultPageSize = 50
+
+// FetchFunc fetches up to limit items starting at offset, reporting whether
+// the endpoint has more after them
+type FetchFunc[T any] func(ctx context.Context, offset, limit int) (items []T, hasMore bool, err error)
+
+// Pager iterates over the items of a paginated endpoint. Use it like a
+// bufio.Scanner:
+//
+//	for p.Next(ctx) {
+//		item := p.Item()
+//	}
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+//
+// or range over All with Go 1.23 or later. A Pager is not safe for
+// concurrent use.
+type Pager[T any] struct {
+	fetch    FetchFunc[T]
+	pageSize int
+
+	page   []T
+	index  int
+	offset int
+	done   bool
+	item   T
+	err    error
+}
+
+// New creates a pager fetching pageSize items at a time (DefaultPageSize when
+// pageSize is not positive)
+func New[T any](pageSize int, fetch FetchFunc[T]) *Pager[T] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	return &Pager[T]{fetch: fetch, pageSize: pageSize}
+}
+
+// Next advances to the next item, fetching the next page when the current
+// one is used up. It returns false when the items are exhausted, ctx is done
+// or a fetch failed; Err tells which.
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+	for p.index >= len(p.page) {
+		if p.done {
+			return false
+		}
+		items, hasMore, err := p.fetch(ctx, p.offset, p.pageSize)
+		if err != nil {
+			p.err = err
+			return false
+		}
+		p.page, p.index = items, 0
+		p.offset += len(items)
+		// An empty page ends the iteration even if the endpoint claims more,
+		// which would otherwise be fetched forever
+		p.done = !hasMore || len(items) == 0
+	}
+	p.item = p.page[p.index]
+	p.index++
+	return true
+}
+
+// Item returns the item Next advanced to
+func (p *Pager[T]) Item() T {
+	return p.item
+}
+
+// Err returns the error that ended the iteration, or nil when the items were
+// exhausted
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All returns an iterator over the remaining items, compatible with
+// iter.Seq2[T, error]. An error is yielded once, with the zero item, and ends
+// the iteration.
+func (p *Pager[T]) All(ctx context.Context) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		for p.Next(ctx) {
+			if !yield(p.Item(), nil) {
+				return
+			}
+		}
+		if err := p.Err(); err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// Collect fetches all the remaining items
+func (p *Pager[T]) Collect(ctx context.Context) ([]T, error) {
+	var items []T
+	for p.Next(ctx) {
+		items = append(items, p.Item())
+	}
+	return items, p.Err()
+}
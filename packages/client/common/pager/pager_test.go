@@ -0,0 +1,105 @@
+package pager
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// sliceFetch pages through items, counting the fetches
+func sliceFetch(items []int, fetches *int) FetchFunc[int] {
+	return func(ctx context.Context, offset, limit int) ([]int, bool, error) {
+		*fetches++
+		start := min(offset, len(items))
+		end := min(start+limit, len(items))
+		return items[start:end], end < len(items), nil
+	}
+}
+
+func TestPagerCollect(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5, 6, 7}
+	fetches := 0
+	got, err := New(3, sliceFetch(items, &fetches)).Collect(context.Background())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(got) != len(items) || got[0] != 1 || got[6] != 7 {
+		t.Errorf("Collect() = %v, want %v", got, items)
+	}
+	if fetches != 3 {
+		t.Errorf("fetched %d pages, want 3", fetches)
+	}
+
+	fetches = 0
+	got, _ = New(0, sliceFetch(nil, &fetches)).Collect(context.Background())
+	if len(got) != 0 || fetches != 1 {
+		t.Errorf("empty endpoint: %v after %d fetches", got, fetches)
+	}
+}
+
+func TestPagerIsLazy(t *testing.T) {
+	fetches := 0
+	p := New(2, sliceFetch([]int{1, 2, 3, 4, 5}, &fetches))
+	var got []int
+	p.All(context.Background())(func(item int, err error) bool {
+		got = append(got, item)
+		return len(got) < 3
+	})
+	if len(got) != 3 || fetches != 2 {
+		t.Errorf("stopping after 3 items: got %v after %d fetches, want 2 fetches", got, fetches)
+	}
+}
+
+func TestPagerErrors(t *testing.T) {
+	failure := errors.New("server down")
+	calls := 0
+	p := New(2, func(ctx context.Context, offset, limit int) ([]int, bool, error) {
+		calls++
+		if offset > 0 {
+			return nil, false, failure
+		}
+		return []int{1, 2}, true, nil
+	})
+	var items []int
+	var yielded error
+	p.All(context.Background())(func(item int, err error) bool {
+		if err != nil {
+			yielded = err
+			return false
+		}
+		items = append(items, item)
+		return true
+	})
+	if len(items) != 2 || !errors.Is(yielded, failure) || !errors.Is(p.Err(), failure) {
+		t.Errorf("got %v, yielded %v, Err() = %v", items, yielded, p.Err())
+	}
+	if p.Next(context.Background()) || calls != 2 {
+		t.Errorf("Next() after an error fetched again (%d calls)", calls)
+	}
+
+	// A page that claims more but is empty ends the iteration
+	calls = 0
+	p = New(2, func(ctx context.Context, offset, limit int) ([]int, bool, error) {
+		calls++
+		return nil, true, nil
+	})
+	if p.Next(context.Background()) || p.Err() != nil || calls != 1 {
+		t.Errorf("empty page: Err() = %v after %d calls", p.Err(), calls)
+	}
+}
+
+func TestPagerCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fetches := 0
+	p := New(2, sliceFetch([]int{1, 2, 3, 4}, &fetches))
+	if !p.Next(ctx) {
+		t.Fatal("Next() = false before cancellation")
+	}
+	cancel()
+	if p.Next(ctx) || !errors.Is(p.Err(), context.Canceled) {
+		t.Errorf("Next() after cancellation: Err() = %v", p.Err())
+	}
+	if fetches != 1 {
+		t.Errorf("fetched %d pages, want 1", fetches)
+	}
+}
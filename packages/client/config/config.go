@@ -43,8 +43,102 @@ type Config struct {
 	
 	// HistoryStorePath sets the directory path for storing history data
 	HistoryStorePath string
+
+	// HistoryBackend selects the history storage: "file" (JSON metadata) or "sqlite"
+	HistoryBackend string
+
+	// Retry settings
+	// MaxRetries sets how many times a failed retryable request is retried (0 disables retries)
+	MaxRetries int
+
+	// RetryBaseDelay is the initial backoff delay, doubled after every attempt
+	RetryBaseDelay time.Duration
+
+	// RetryMaxDelay caps the backoff delay, including server-provided Retry-After hints
+	RetryMaxDelay time.Duration
+
+	// RateLimitEnabled delays requests client-side once the X-Aivis-RateLimit budget is exhausted
+	RateLimitEnabled bool
+
+	// Synthesis cache settings
+	// CacheEnabled serves repeated synthesis requests from a local cache instead of the API
+	CacheEnabled bool
+
+	// CacheMaxSize bounds the total size of cached audio in bytes (0 means unbounded)
+	CacheMaxSize int64
+
+	// CacheMaxAge expires cached audio after this duration (0 means never)
+	CacheMaxAge time.Duration
+
+	// CachePath sets the directory path for storing cached audio
+	CachePath string
+
+	// Playback daemon settings
+	// UseDaemon forwards playback calls to a running playback daemon
+	UseDaemon bool
+
+	// DaemonSocketPath sets the Unix socket the playback daemon listens on
+	DaemonSocketPath string
+
+	// PlayerBackend selects the audio player: "command" (OS audio commands) or
+	// "native" (in-process WAV/FLAC/MP3 decoding with real pause, live
+	// volume, exact position and fades). The native player does not decode
+	// AAC or Opus: audio in those formats, such as an explicit --format aac,
+	// is handed to the command player without those controls. Playback
+	// requests without a format ask for WAV.
+	PlayerBackend string
+
+	// Credit budget settings
+	// BudgetDailyCredits refuses syntheses that would spend more credits today (0 means unlimited)
+	BudgetDailyCredits float64
+
+	// BudgetMonthlyCredits refuses syntheses that would spend more credits this month (0 means unlimited)
+	BudgetMonthlyCredits float64
+
+	// BudgetPath sets the file of the local spending ledger, which is only
+	// written while a budget is set
+	BudgetPath string
+
+	// Low-credit alert settings
+	// CreditAlertThresholds alerts when the remaining credits reported by the
+	// API fall to or below one of these values (none disables alerts)
+	CreditAlertThresholds []float64
+
+	// CreditAlertNotifiers selects how alerts are delivered: "stderr", "log",
+	// "webhook" (CreditAlertWebhookURL) and "command" (CreditAlertCommand)
+	CreditAlertNotifiers []string
+
+	// CreditAlertWebhookURL receives alerts as JSON POST requests
+	CreditAlertWebhookURL string
+
+	// CreditAlertCommand is run through the shell for every alert, e.g. to
+	// show a desktop notification
+	CreditAlertCommand string
+
+	// CreditStatePath sets the file recording the last observed credit balance
+	CreditStatePath string
 }
 
+// History storage backends
+const (
+	HistoryBackendFile   = "file"
+	HistoryBackendSQLite = "sqlite"
+)
+
+// Audio player backends
+const (
+	PlayerBackendCommand = "command"
+	PlayerBackendNative  = "native"
+)
+
+// Low-credit alert notifiers
+const (
+	CreditNotifierStderr  = "stderr"
+	CreditNotifierLog     = "log"
+	CreditNotifierWebhook = "webhook"
+	CreditNotifierCommand = "command"
+)
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
@@ -58,6 +152,26 @@ func DefaultConfig() *Config {
 		HistoryEnabled:      true,
 		HistoryMaxCount:     100,
 		HistoryStorePath:    "", // Will be set to default user directory
+		HistoryBackend:      HistoryBackendFile,
+
+		MaxRetries:       2,
+		RetryBaseDelay:   500 * time.Millisecond,
+		RetryMaxDelay:    30 * time.Second,
+		RateLimitEnabled: true,
+
+		CacheEnabled: false,
+		CacheMaxSize: 512 * 1024 * 1024,
+		CacheMaxAge:  30 * 24 * time.Hour,
+		CachePath:    "", // Will be set to default user directory
+
+		UseDaemon:        true,
+		DaemonSocketPath: "", // Will be set to default user directory
+		PlayerBackend:    PlayerBackendCommand,
+
+		BudgetPath: "", // Will be set to default user directory
+
+		CreditAlertNotifiers: []string{CreditNotifierLog},
+		CreditStatePath:      "", // Will be set to default user directory
 	}
 }
 
@@ -128,10 +242,121 @@ func (c *Config) WithHistoryStorePath(path string) *Config {
 	return c
 }
 
+// WithHistoryBackend selects the history storage backend (file or sqlite)
+func (c *Config) WithHistoryBackend(backend string) *Config {
+	c.HistoryBackend = backend
+	return c
+}
+
+// WithMaxRetries sets the maximum number of retries for retryable requests
+func (c *Config) WithMaxRetries(maxRetries int) *Config {
+	c.MaxRetries = maxRetries
+	return c
+}
+
+// WithRetryBackoff sets the base and maximum backoff delays used between retries
+func (c *Config) WithRetryBackoff(baseDelay, maxDelay time.Duration) *Config {
+	c.RetryBaseDelay = baseDelay
+	c.RetryMaxDelay = maxDelay
+	return c
+}
+
+// WithRateLimitEnabled enables or disables the client-side rate limiter
+func (c *Config) WithRateLimitEnabled(enabled bool) *Config {
+	c.RateLimitEnabled = enabled
+	return c
+}
+
+// WithCache enables the synthesis cache with the given size (bytes) and age limits
+func (c *Config) WithCache(maxSize int64, maxAge time.Duration) *Config {
+	c.CacheEnabled = true
+	c.CacheMaxSize = maxSize
+	c.CacheMaxAge = maxAge
+	return c
+}
+
+// WithCachePath sets the directory path for storing cached audio
+func (c *Config) WithCachePath(path string) *Config {
+	c.CachePath = path
+	return c
+}
+
+// WithUseDaemon enables or disables forwarding playback to a running daemon
+func (c *Config) WithUseDaemon(enabled bool) *Config {
+	c.UseDaemon = enabled
+	return c
+}
+
+// WithDaemonSocketPath sets the Unix socket path of the playback daemon
+func (c *Config) WithDaemonSocketPath(path string) *Config {
+	c.DaemonSocketPath = path
+	return c
+}
+
+// WithPlayerBackend selects the audio player backend (command or native)
+func (c *Config) WithPlayerBackend(backend string) *Config {
+	c.PlayerBackend = backend
+	return c
+}
+
+// WithBudget sets the daily and monthly credit budgets (0 leaves a period unlimited)
+func (c *Config) WithBudget(dailyCredits, monthlyCredits float64) *Config {
+	c.BudgetDailyCredits = dailyCredits
+	c.BudgetMonthlyCredits = monthlyCredits
+	return c
+}
+
+// WithBudgetPath sets the file of the local spending ledger
+func (c *Config) WithBudgetPath(path string) *Config {
+	c.BudgetPath = path
+	return c
+}
+
+// WithCreditAlerts alerts through the given notifiers when the remaining
+// credits fall to or below any of the thresholds
+func (c *Config) WithCreditAlerts(thresholds []float64, notifiers ...string) *Config {
+	c.CreditAlertThresholds = thresholds
+	if len(notifiers) > 0 {
+		c.CreditAlertNotifiers = notifiers
+	}
+	return c
+}
+
+// WithCreditStatePath sets the file recording the last observed credit balance
+func (c *Config) WithCreditStatePath(path string) *Config {
+	c.CreditStatePath = path
+	return c
+}
+
 // GetHistoryStorePath returns the full path for history storage
 func (c *Config) GetHistoryStorePath() (string, error) {
-	if c.HistoryStorePath != "" {
-		p := c.HistoryStorePath
+	return resolveStorePath(c.HistoryStorePath, "history")
+}
+
+// GetCachePath returns the full path for the synthesis cache
+func (c *Config) GetCachePath() (string, error) {
+	return resolveStorePath(c.CachePath, "cache")
+}
+
+// GetDaemonSocketPath returns the full path of the playback daemon socket
+func (c *Config) GetDaemonSocketPath() (string, error) {
+	return resolveStorePath(c.DaemonSocketPath, "daemon.sock")
+}
+
+// GetBudgetPath returns the full path of the spending ledger
+func (c *Config) GetBudgetPath() (string, error) {
+	return resolveStorePath(c.BudgetPath, "budget.json")
+}
+
+// GetCreditStatePath returns the full path of the last observed credit balance
+func (c *Config) GetCreditStatePath() (string, error) {
+	return resolveStorePath(c.CreditStatePath, "credits.json")
+}
+
+// resolveStorePath expands a configured directory, defaulting to ~/.aivis-cli/<name>
+func resolveStorePath(path, name string) (string, error) {
+	if path != "" {
+		p := path
 		// Expand environment variables
 		p = os.ExpandEnv(p)
 		// Expand leading ~ to user home
@@ -156,7 +381,7 @@ func (c *Config) GetHistoryStorePath() (string, error) {
 		return "", err
 	}
 	
-	return filepath.Join(homeDir, ".aivis-cli", "history"), nil
+	return filepath.Join(homeDir, ".aivis-cli", name), nil
 }
 
 // GetLogWriter returns the appropriate writer for log output
@@ -190,6 +415,54 @@ func (c *Config) Validate() error {
 	if c.HistoryEnabled && c.HistoryMaxCount <= 0 {
 		return &ValidationError{Field: "HistoryMaxCount", Message: "History max count must be positive when history is enabled"}
 	}
+	switch c.HistoryBackend {
+	case "", HistoryBackendFile, HistoryBackendSQLite:
+	default:
+		return &ValidationError{Field: "HistoryBackend", Message: "History backend must be file or sqlite"}
+	}
+	switch c.PlayerBackend {
+	case "", PlayerBackendCommand, PlayerBackendNative:
+	default:
+		return &ValidationError{Field: "PlayerBackend", Message: "Player backend must be command or native"}
+	}
+	if c.MaxRetries < 0 {
+		return &ValidationError{Field: "MaxRetries", Message: "Max retries must not be negative"}
+	}
+	if c.MaxRetries > 0 && (c.RetryBaseDelay < 0 || c.RetryMaxDelay < c.RetryBaseDelay) {
+		return &ValidationError{Field: "RetryMaxDelay", Message: "Retry max delay must be greater than or equal to retry base delay"}
+	}
+	if c.CacheMaxSize < 0 {
+		return &ValidationError{Field: "CacheMaxSize", Message: "Cache max size must not be negative"}
+	}
+	if c.CacheMaxAge < 0 {
+		return &ValidationError{Field: "CacheMaxAge", Message: "Cache max age must not be negative"}
+	}
+	if c.BudgetDailyCredits < 0 {
+		return &ValidationError{Field: "BudgetDailyCredits", Message: "Daily credit budget must not be negative"}
+	}
+	if c.BudgetMonthlyCredits < 0 {
+		return &ValidationError{Field: "BudgetMonthlyCredits", Message: "Monthly credit budget must not be negative"}
+	}
+	for _, threshold := range c.CreditAlertThresholds {
+		if threshold < 0 {
+			return &ValidationError{Field: "CreditAlertThresholds", Message: "Credit alert thresholds must not be negative"}
+		}
+	}
+	for _, notifier := range c.CreditAlertNotifiers {
+		switch notifier {
+		case CreditNotifierStderr, CreditNotifierLog:
+		case CreditNotifierWebhook:
+			if c.CreditAlertWebhookURL == "" {
+				return &ValidationError{Field: "CreditAlertWebhookURL", Message: "Credit alert webhook URL is required by the webhook notifier"}
+			}
+		case CreditNotifierCommand:
+			if c.CreditAlertCommand == "" {
+				return &ValidationError{Field: "CreditAlertCommand", Message: "Credit alert command is required by the command notifier"}
+			}
+		default:
+			return &ValidationError{Field: "CreditAlertNotifiers", Message: "Credit alert notifiers must be stderr, log, webhook or command"}
+		}
+	}
 	return nil
 }
 
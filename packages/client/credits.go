@@ -0,0 +1,97 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/logger"
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	paymentInfra "github.com/kajidog/aivis-cloud-cli/client/payment/infrastructure"
+	paymentUsecase "github.com/kajidog/aivis-cloud-cli/client/payment/usecase"
+)
+
+// newCreditMonitor creates the monitor following the remaining credits
+// reported by the API, with the notifiers selected in cfg. Without a state
+// location the balance cannot be followed, which is only an error when
+// alerts are configured.
+func newCreditMonitor(cfg *config.Config, log logger.Logger, extra []paymentDomain.CreditNotifier) (*paymentUsecase.CreditMonitor, error) {
+	path, err := cfg.GetCreditStatePath()
+	if err != nil {
+		if len(cfg.CreditAlertThresholds) > 0 {
+			return nil, fmt.Errorf("failed to locate credit state: %w", err)
+		}
+		return nil, nil
+	}
+
+	monitor := paymentUsecase.NewCreditMonitor(paymentInfra.NewFileCreditStateRepository(path), cfg.CreditAlertThresholds)
+	monitor.SetErrorHandler(func(err error) {
+		log.Warn("Failed to deliver credit alert", logger.Error(err))
+	})
+	for _, name := range cfg.CreditAlertNotifiers {
+		switch name {
+		case config.CreditNotifierStderr:
+			monitor.AddNotifier(paymentInfra.NewStderrCreditNotifier())
+		case config.CreditNotifierLog:
+			monitor.AddNotifier(paymentInfra.NewLogCreditNotifier(log))
+		case config.CreditNotifierWebhook:
+			monitor.AddNotifier(paymentInfra.NewWebhookCreditNotifier(cfg.CreditAlertWebhookURL))
+		case config.CreditNotifierCommand:
+			monitor.AddNotifier(paymentInfra.NewCommandCreditNotifier(cfg.CreditAlertCommand))
+		}
+	}
+	for _, notifier := range extra {
+		monitor.AddNotifier(notifier)
+	}
+	return monitor, nil
+}
+
+// observeCredits follows the X-Aivis-Credits-Remaining header of API
+// responses. Alerts are a side channel delivered in the background: failures
+// are logged, never returned to the request that carried the header.
+func (c *Client) observeCredits(headers nethttp.Header) {
+	monitor := c.credits
+	value := strings.TrimSpace(headers.Get("X-Aivis-Credits-Remaining"))
+	if monitor == nil || value == "" {
+		return
+	}
+	remaining, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return
+	}
+	if _, err := monitor.Observe(context.Background(), remaining); err != nil {
+		c.logger.Warn("Failed to process remaining credits", logger.Error(err))
+	}
+}
+
+// AddCreditNotifier adds a notifier that receives low-credit alerts in
+// addition to those selected by Config.CreditAlertNotifiers
+func (c *Client) AddCreditNotifier(notifier paymentDomain.CreditNotifier) {
+	c.creditNotifiers = append(c.creditNotifiers, notifier)
+	if c.credits != nil {
+		c.credits.AddNotifier(notifier)
+	}
+}
+
+// FlushCreditAlerts waits until the low-credit alerts raised so far are
+// delivered, or ctx is done. Call it before exiting so that the alerts of
+// the last requests are not lost.
+func (c *Client) FlushCreditAlerts(ctx context.Context) error {
+	if c.credits == nil {
+		return nil
+	}
+	return c.credits.Flush(ctx)
+}
+
+// GetLastObservedCredits returns the remaining credits reported by the most
+// recent synthesis of any process sharing the credit state, or nil when none
+// has been observed
+func (c *Client) GetLastObservedCredits(ctx context.Context) (*paymentDomain.CreditObservation, error) {
+	if c.credits == nil {
+		return nil, fmt.Errorf("credit tracking is unavailable")
+	}
+	return c.credits.Last(ctx)
+}
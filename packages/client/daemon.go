@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/logger"
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	ttsInfra "github.com/kajidog/aivis-cloud-cli/client/tts/infrastructure"
+)
+
+// ErrDaemonNotRunning is returned by daemon calls when no daemon is listening
+var ErrDaemonNotRunning = ttsInfra.ErrDaemonNotRunning
+
+// ServeDaemon runs the playback daemon on the configured socket until ctx is
+// cancelled or a shutdown call arrives. The daemon owns this client's player
+// and queue; other processes reach them through the playback methods, which
+// forward to the daemon while it is running. Playback started through the
+// daemon outlives the call that started it.
+func (c *Client) ServeDaemon(ctx context.Context) error {
+	socketPath, err := c.config.GetDaemonSocketPath()
+	if err != nil {
+		return fmt.Errorf("failed to get daemon socket path: %w", err)
+	}
+
+	// The daemon plays locally; forwarding to itself would loop
+	c.daemon = nil
+	c.servingDaemon = true
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	status := &ttsDomain.DaemonStatus{
+		PID:        os.Getpid(),
+		SocketPath: socketPath,
+		StartedAt:  time.Now(),
+	}
+
+	server := ttsInfra.NewDaemonServer(socketPath, c.logger)
+	server.Handle(ttsDomain.DaemonMethodPlay, func(_ context.Context, params json.RawMessage) (interface{}, error) {
+		var p ttsDomain.DaemonPlayParams
+		if err := ttsInfra.DecodeDaemonParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Request == nil || p.Request.TTSRequest == nil {
+			return nil, &ttsInfra.DaemonError{Code: ttsInfra.DaemonErrInvalidParams, Message: "request is required"}
+		}
+		// Queued playback keeps using the context after the call returns
+		playCtx := ctx
+		if p.BudgetApproved {
+			playCtx = ttsDomain.WithBudgetApproval(playCtx)
+		}
+		playCtx = ttsDomain.WithBudgetReservation(playCtx, p.BudgetReservation)
+		playCtx = ttsDomain.WithHistoryLabels(playCtx, p.HistoryLabels)
+		// An approval is held until the text is synthesized, which it is by
+		// the time playLocal returns; a single request's reservation is
+		// settled by its synthesis, possibly later from the queue
+		if p.BudgetApproved && p.BudgetReservation != "" && c.budget != nil {
+			defer c.budget.Release(context.Background(), p.BudgetReservation)
+		}
+		return c.playLocal(playCtx, &p)
+	})
+	server.Handle(ttsDomain.DaemonMethodPlayHistory, func(_ context.Context, params json.RawMessage) (interface{}, error) {
+		var p ttsDomain.DaemonPlayHistoryParams
+		if err := ttsInfra.DecodeDaemonParams(params, &p); err != nil {
+			return nil, err
+		}
+		if c.historyManager == nil {
+			return nil, fmt.Errorf("history management is disabled")
+		}
+		return nil, c.historyManager.PlayHistory(ctx, p.ID, p.Options)
+	})
+	server.Handle(ttsDomain.DaemonMethodStop, func(context.Context, json.RawMessage) (interface{}, error) {
+		return nil, c.playerService.Stop()
+	})
+	server.Handle(ttsDomain.DaemonMethodPause, func(context.Context, json.RawMessage) (interface{}, error) {
+		return nil, c.playerService.Pause()
+	})
+	server.Handle(ttsDomain.DaemonMethodResume, func(context.Context, json.RawMessage) (interface{}, error) {
+		return nil, c.playerService.Resume()
+	})
+	server.Handle(ttsDomain.DaemonMethodSetVolume, func(_ context.Context, params json.RawMessage) (interface{}, error) {
+		var p ttsDomain.DaemonVolumeParams
+		if err := ttsInfra.DecodeDaemonParams(params, &p); err != nil {
+			return nil, err
+		}
+		return nil, c.playerService.SetVolume(p.Volume)
+	})
+	server.Handle(ttsDomain.DaemonMethodClearQueue, func(context.Context, json.RawMessage) (interface{}, error) {
+		c.playerService.ClearQueue()
+		return nil, nil
+	})
+	server.Handle(ttsDomain.DaemonMethodStatus, func(context.Context, json.RawMessage) (interface{}, error) {
+		current := *status
+		current.Playback = c.playerService.GetStatus()
+		return &current, nil
+	})
+	server.Handle(ttsDomain.DaemonMethodShutdown, func(context.Context, json.RawMessage) (interface{}, error) {
+		c.logger.Info("Playback daemon shutdown requested")
+		// Let the response go out before the listener closes
+		time.AfterFunc(100*time.Millisecond, cancel)
+		return nil, nil
+	})
+
+	err = server.Serve(ctx)
+	if stopErr := c.playerService.Stop(); stopErr != nil {
+		c.logger.Warn("Failed to stop playback", logger.Error(stopErr))
+	}
+	return err
+}
+
+// playLocal plays a daemon play request with this process's player
+func (c *Client) playLocal(ctx context.Context, p *ttsDomain.DaemonPlayParams) (*ttsDomain.DaemonPlayResult, error) {
+	switch {
+	case p.LongText != nil && p.SaveHistory:
+		result, err := c.PlayLongTextWithHistory(ctx, p.Request, p.LongText)
+		return &ttsDomain.DaemonPlayResult{LongText: result}, err
+	case p.LongText != nil:
+		result, err := c.PlayLongText(ctx, p.Request, p.LongText)
+		return &ttsDomain.DaemonPlayResult{LongText: result}, err
+	case p.SaveHistory:
+		response, err := c.PlayRequestWithHistory(ctx, p.Request)
+		return &ttsDomain.DaemonPlayResult{Response: response}, err
+	default:
+		return &ttsDomain.DaemonPlayResult{}, c.PlayRequest(ctx, p.Request)
+	}
+}
+
+// DaemonRunning reports whether playback calls are forwarded to a running
+// playback daemon
+func (c *Client) DaemonRunning() bool {
+	return c.daemon != nil && c.daemon.Ping()
+}
+
+// GetDaemonStatus returns the status of the running playback daemon, or
+// ErrDaemonNotRunning
+func (c *Client) GetDaemonStatus(ctx context.Context) (*ttsDomain.DaemonStatus, error) {
+	var status ttsDomain.DaemonStatus
+	if err := c.daemonClient().Call(ctx, ttsDomain.DaemonMethodStatus, nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// StopDaemon asks the running playback daemon to stop playback and exit
+func (c *Client) StopDaemon(ctx context.Context) error {
+	return c.daemonClient().Call(ctx, ttsDomain.DaemonMethodShutdown, nil, nil)
+}
+
+// daemonClient returns a client for the configured socket, even when
+// forwarding is disabled, for managing the daemon itself
+func (c *Client) daemonClient() *ttsInfra.DaemonClient {
+	if c.daemon != nil {
+		return c.daemon
+	}
+	socketPath, err := c.config.GetDaemonSocketPath()
+	if err != nil {
+		socketPath = ""
+	}
+	return ttsInfra.NewDaemonClient(socketPath)
+}
+
+// forwardToDaemon sends a playback call to the running daemon. It reports
+// false when forwarding is disabled or no daemon is listening, in which case
+// the caller plays in this process.
+func (c *Client) forwardToDaemon(ctx context.Context, method string, params, result interface{}) (bool, error) {
+	if c.daemon == nil {
+		return false, nil
+	}
+	err := c.daemon.Call(ctx, method, params, result)
+	if errors.Is(err, ttsInfra.ErrDaemonNotRunning) {
+		return false, nil
+	}
+	return true, err
+}
+
+// newDaemonClient returns the daemon client used for forwarding, or nil when
+// forwarding is disabled
+func newDaemonClient(cfg *config.Config) *ttsInfra.DaemonClient {
+	if !cfg.UseDaemon {
+		return nil
+	}
+	socketPath, err := cfg.GetDaemonSocketPath()
+	if err != nil {
+		return nil
+	}
+	return ttsInfra.NewDaemonClient(socketPath)
+}
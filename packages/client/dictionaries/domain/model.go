@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+// UserDictionary is a user dictionary that adjusts how words are read.
+// Requests use it through TTSRequest.UserDictionaryUUID.
+type UserDictionary struct {
+	UUID        string    `json:"uuid"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	WordCount   int       `json:"word_count"`
+	Words       []Word    `json:"word_properties,omitempty"` // Only returned for a single dictionary
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Word is a dictionary entry: how a surface form is pronounced
+type Word struct {
+	UUID          string   `json:"uuid"`
+	Surface       string   `json:"surface"`
+	Pronunciation string   `json:"pronunciation"` // Katakana reading
+	AccentType    int      `json:"accent_type"`   // Mora after which the pitch falls, 0 for flat
+	WordType      WordType `json:"word_type,omitempty"`
+	Priority      int      `json:"priority"` // 0 (lowest) to 10 (highest)
+}
+
+// WordType is the part of speech of a word
+type WordType string
+
+const (
+	WordTypeProperNoun WordType = "PROPER_NOUN"
+	WordTypeCommonNoun WordType = "COMMON_NOUN"
+	WordTypeVerb       WordType = "VERB"
+	WordTypeAdjective  WordType = "ADJECTIVE"
+	WordTypeSuffix     WordType = "SUFFIX"
+)
+
+// Word priority bounds and default
+const (
+	MinWordPriority     = 0
+	MaxWordPriority     = 10
+	DefaultWordPriority = 5
+)
+
+// UserDictionaryListResponse is the list of the user's dictionaries
+type UserDictionaryListResponse struct {
+	UserDictionaries []UserDictionary `json:"user_dictionaries"`
+	Total            int              `json:"total"`
+}
+
+// UserDictionaryUpdate holds the dictionary fields to change; nil fields are kept
+type UserDictionaryUpdate struct {
+	Name        *string
+	Description *string
+}
+
+// WordUpdate holds the word fields to change; nil fields are kept
+type WordUpdate struct {
+	Surface       *string
+	Pronunciation *string
+	AccentType    *int
+	WordType      *WordType
+	Priority      *int
+}
+
+// WordImportResult summarizes a CSV import
+type WordImportResult struct {
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+	Total   int `json:"total"` // Words in the dictionary after the import
+}
@@ -0,0 +1,11 @@
+package domain
+
+import "context"
+
+type UserDictionaryRepository interface {
+	ListDictionaries(ctx context.Context) (*UserDictionaryListResponse, error)
+	GetDictionary(ctx context.Context, uuid string) (*UserDictionary, error)
+	// PutDictionary creates the dictionary with its UUID, or replaces it with all its words
+	PutDictionary(ctx context.Context, dictionary *UserDictionary) (*UserDictionary, error)
+	DeleteDictionary(ctx context.Context, uuid string) error
+}
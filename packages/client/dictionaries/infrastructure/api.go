@@ -0,0 +1,84 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+)
+
+type UserDictionaryAPI struct {
+	client http.HTTPClient
+}
+
+func NewUserDictionaryAPI(client http.HTTPClient) *UserDictionaryAPI {
+	return &UserDictionaryAPI{
+		client: client,
+	}
+}
+
+func (a *UserDictionaryAPI) ListDictionaries(ctx context.Context) (*domain.UserDictionaryListResponse, error) {
+	var response domain.UserDictionaryListResponse
+	err := a.client.Get(ctx, "/v1/user-dictionaries", nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user dictionaries: %w", err)
+	}
+
+	return &response, nil
+}
+
+func (a *UserDictionaryAPI) GetDictionary(ctx context.Context, uuid string) (*domain.UserDictionary, error) {
+	endpoint := fmt.Sprintf("/v1/user-dictionaries/%s", url.PathEscape(uuid))
+
+	var response domain.UserDictionary
+	err := a.client.Get(ctx, endpoint, nil, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user dictionary %s: %w", uuid, err)
+	}
+
+	return &response, nil
+}
+
+func (a *UserDictionaryAPI) PutDictionary(ctx context.Context, dictionary *domain.UserDictionary) (*domain.UserDictionary, error) {
+	endpoint := fmt.Sprintf("/v1/user-dictionaries/%s", url.PathEscape(dictionary.UUID))
+
+	body := struct {
+		Name        string        `json:"name"`
+		Description string        `json:"description"`
+		Words       []domain.Word `json:"word_properties"`
+	}{
+		Name:        dictionary.Name,
+		Description: dictionary.Description,
+		Words:       dictionary.Words,
+	}
+	if body.Words == nil {
+		body.Words = []domain.Word{}
+	}
+
+	var response domain.UserDictionary
+	err := a.client.Put(ctx, endpoint, body, &response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save user dictionary %s: %w", dictionary.UUID, err)
+	}
+
+	// The API may answer without a body
+	if response.UUID == "" {
+		saved := *dictionary
+		saved.WordCount = len(dictionary.Words)
+		return &saved, nil
+	}
+	return &response, nil
+}
+
+func (a *UserDictionaryAPI) DeleteDictionary(ctx context.Context, uuid string) error {
+	endpoint := fmt.Sprintf("/v1/user-dictionaries/%s", url.PathEscape(uuid))
+
+	err := a.client.Delete(ctx, endpoint, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete user dictionary %s: %w", uuid, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+)
+
+// UserDictionaryUsecase manages user dictionaries and their words. The API
+// stores a dictionary with all its words, so word changes read the
+// dictionary, modify it and write it back.
+type UserDictionaryUsecase struct {
+	repo domain.UserDictionaryRepository
+}
+
+func NewUserDictionaryUsecase(repo domain.UserDictionaryRepository) *UserDictionaryUsecase {
+	return &UserDictionaryUsecase{
+		repo: repo,
+	}
+}
+
+func (u *UserDictionaryUsecase) ListDictionaries(ctx context.Context) (*domain.UserDictionaryListResponse, error) {
+	return u.repo.ListDictionaries(ctx)
+}
+
+func (u *UserDictionaryUsecase) GetDictionary(ctx context.Context, dictionaryUUID string) (*domain.UserDictionary, error) {
+	if dictionaryUUID == "" {
+		return nil, fmt.Errorf("dictionary UUID is required")
+	}
+	return u.repo.GetDictionary(ctx, dictionaryUUID)
+}
+
+// CreateDictionary creates an empty dictionary
+func (u *UserDictionaryUsecase) CreateDictionary(ctx context.Context, name, description string) (*domain.UserDictionary, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("dictionary name is required")
+	}
+
+	return u.repo.PutDictionary(ctx, &domain.UserDictionary{
+		UUID:        uuid.New().String(),
+		Name:        name,
+		Description: description,
+	})
+}
+
+// UpdateDictionary changes the name or description of a dictionary, keeping its words
+func (u *UserDictionaryUsecase) UpdateDictionary(ctx context.Context, dictionaryUUID string, update *domain.UserDictionaryUpdate) (*domain.UserDictionary, error) {
+	dictionary, err := u.GetDictionary(ctx, dictionaryUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		name := strings.TrimSpace(*update.Name)
+		if name == "" {
+			return nil, fmt.Errorf("dictionary name must not be empty")
+		}
+		dictionary.Name = name
+	}
+	if update.Description != nil {
+		dictionary.Description = *update.Description
+	}
+	return u.repo.PutDictionary(ctx, dictionary)
+}
+
+func (u *UserDictionaryUsecase) DeleteDictionary(ctx context.Context, dictionaryUUID string) error {
+	if dictionaryUUID == "" {
+		return fmt.Errorf("dictionary UUID is required")
+	}
+	return u.repo.DeleteDictionary(ctx, dictionaryUUID)
+}
+
+// AddWord adds a word to a dictionary. A word with the same surface form
+// must not exist yet; use SetWord to replace it.
+func (u *UserDictionaryUsecase) AddWord(ctx context.Context, dictionaryUUID string, word domain.Word) (*domain.Word, error) {
+	dictionary, err := u.GetDictionary(ctx, dictionaryUUID)
+	if err != nil {
+		return nil, err
+	}
+	if i := findWordBySurface(dictionary.Words, word.Surface); i >= 0 {
+		return nil, fmt.Errorf("word %q already exists (%s)", word.Surface, dictionary.Words[i].UUID)
+	}
+
+	added, _, err := u.setWord(ctx, dictionary, word)
+	return added, err
+}
+
+// SetWord adds a word, or replaces the reading of the word with the same
+// surface form. It reports whether the word was added.
+func (u *UserDictionaryUsecase) SetWord(ctx context.Context, dictionaryUUID string, word domain.Word) (*domain.Word, bool, error) {
+	dictionary, err := u.GetDictionary(ctx, dictionaryUUID)
+	if err != nil {
+		return nil, false, err
+	}
+	return u.setWord(ctx, dictionary, word)
+}
+
+func (u *UserDictionaryUsecase) setWord(ctx context.Context, dictionary *domain.UserDictionary, word domain.Word) (*domain.Word, bool, error) {
+	word, err := normalizeWord(word)
+	if err != nil {
+		return nil, false, err
+	}
+
+	added := true
+	if i := findWordBySurface(dictionary.Words, word.Surface); i >= 0 {
+		word.UUID = dictionary.Words[i].UUID
+		dictionary.Words[i] = word
+		added = false
+	} else {
+		word.UUID = uuid.New().String()
+		dictionary.Words = append(dictionary.Words, word)
+	}
+
+	if _, err := u.repo.PutDictionary(ctx, dictionary); err != nil {
+		return nil, false, err
+	}
+	return &word, added, nil
+}
+
+// UpdateWord changes the given fields of a word
+func (u *UserDictionaryUsecase) UpdateWord(ctx context.Context, dictionaryUUID, wordUUID string, update *domain.WordUpdate) (*domain.Word, error) {
+	dictionary, err := u.GetDictionary(ctx, dictionaryUUID)
+	if err != nil {
+		return nil, err
+	}
+	i := findWordByUUID(dictionary.Words, wordUUID)
+	if i < 0 {
+		return nil, fmt.Errorf("word %s not found in dictionary %s", wordUUID, dictionaryUUID)
+	}
+
+	word := dictionary.Words[i]
+	if update.Surface != nil {
+		word.Surface = *update.Surface
+	}
+	if update.Pronunciation != nil {
+		word.Pronunciation = *update.Pronunciation
+	}
+	if update.AccentType != nil {
+		word.AccentType = *update.AccentType
+	}
+	if update.WordType != nil {
+		word.WordType = *update.WordType
+	}
+	if update.Priority != nil {
+		word.Priority = *update.Priority
+	}
+	if word, err = normalizeWord(word); err != nil {
+		return nil, err
+	}
+	if j := findWordBySurface(dictionary.Words, word.Surface); j >= 0 && j != i {
+		return nil, fmt.Errorf("word %q already exists (%s)", word.Surface, dictionary.Words[j].UUID)
+	}
+
+	dictionary.Words[i] = word
+	if _, err := u.repo.PutDictionary(ctx, dictionary); err != nil {
+		return nil, err
+	}
+	return &word, nil
+}
+
+func (u *UserDictionaryUsecase) DeleteWord(ctx context.Context, dictionaryUUID, wordUUID string) error {
+	dictionary, err := u.GetDictionary(ctx, dictionaryUUID)
+	if err != nil {
+		return err
+	}
+	i := findWordByUUID(dictionary.Words, wordUUID)
+	if i < 0 {
+		return fmt.Errorf("word %s not found in dictionary %s", wordUUID, dictionaryUUID)
+	}
+
+	dictionary.Words = append(dictionary.Words[:i], dictionary.Words[i+1:]...)
+	_, err = u.repo.PutDictionary(ctx, dictionary)
+	return err
+}
+
+var wordCSVHeader = []string{"surface", "pronunciation", "accent_type", "word_type", "priority"}
+
+// ImportWordsCSV adds the words of a CSV file to a dictionary, replacing the
+// reading of words whose surface form already exists. With replace, words
+// missing from the file are removed. The columns are surface, pronunciation,
+// accent_type, word_type and priority; a header row naming them may reorder
+// them, and the last two may be omitted.
+func (u *UserDictionaryUsecase) ImportWordsCSV(ctx context.Context, dictionaryUUID string, r io.Reader, replace bool) (*domain.WordImportResult, error) {
+	words, err := parseWordsCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	dictionary, err := u.GetDictionary(ctx, dictionaryUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.WordImportResult{}
+	existing := dictionary.Words
+	if replace {
+		dictionary.Words = nil
+	}
+	for _, word := range words {
+		if i := findWordBySurface(dictionary.Words, word.Surface); i >= 0 {
+			word.UUID = dictionary.Words[i].UUID
+			dictionary.Words[i] = word
+			result.Updated++
+			continue
+		}
+		if i := findWordBySurface(existing, word.Surface); replace && i >= 0 {
+			word.UUID = existing[i].UUID
+			result.Updated++
+		} else {
+			word.UUID = uuid.New().String()
+			result.Added++
+		}
+		dictionary.Words = append(dictionary.Words, word)
+	}
+	if replace {
+		result.Removed = len(existing) - result.Updated
+	}
+	result.Total = len(dictionary.Words)
+
+	if _, err := u.repo.PutDictionary(ctx, dictionary); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ExportWordsCSV writes the words of a dictionary as CSV with a header row,
+// in the format read by ImportWordsCSV. It returns the number of words.
+func (u *UserDictionaryUsecase) ExportWordsCSV(ctx context.Context, dictionaryUUID string, w io.Writer) (int, error) {
+	dictionary, err := u.GetDictionary(ctx, dictionaryUUID)
+	if err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(wordCSVHeader); err != nil {
+		return 0, err
+	}
+	for _, word := range dictionary.Words {
+		record := []string{word.Surface, word.Pronunciation, strconv.Itoa(word.AccentType), string(word.WordType), strconv.Itoa(word.Priority)}
+		if err := writer.Write(record); err != nil {
+			return 0, err
+		}
+	}
+	writer.Flush()
+	return len(dictionary.Words), writer.Error()
+}
+
+func parseWordsCSV(r io.Reader) ([]domain.Word, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV: %w", err)
+	}
+
+	// Spreadsheet exports often start with a byte order mark
+	if len(records) > 0 {
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+	}
+
+	columns := wordCSVHeader
+	start := 0
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), "surface") {
+		columns = make([]string, len(records[0]))
+		for i, name := range records[0] {
+			columns[i] = strings.ToLower(strings.TrimSpace(name))
+		}
+		start = 1
+	}
+
+	var words []domain.Word
+	seen := make(map[string]int)
+	for n, record := range records[start:] {
+		line := n + start + 1
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		word := domain.Word{Priority: domain.DefaultWordPriority}
+		for i, value := range record {
+			if i >= len(columns) {
+				return nil, fmt.Errorf("line %d: too many fields", line)
+			}
+			value = strings.TrimSpace(value)
+			var convErr error
+			switch columns[i] {
+			case "surface":
+				word.Surface = value
+			case "pronunciation":
+				word.Pronunciation = value
+			case "accent_type":
+				word.AccentType, convErr = strconv.Atoi(value)
+			case "word_type":
+				word.WordType = domain.WordType(strings.ToUpper(value))
+			case "priority":
+				if value != "" {
+					word.Priority, convErr = strconv.Atoi(value)
+				}
+			default:
+				return nil, fmt.Errorf("line %d: unknown column %q", line, columns[i])
+			}
+			if convErr != nil {
+				return nil, fmt.Errorf("line %d: invalid %s %q", line, columns[i], value)
+			}
+		}
+		if len(record) < 3 {
+			return nil, fmt.Errorf("line %d: expected at least surface, pronunciation and accent_type", line)
+		}
+
+		word, err := normalizeWord(word)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if previous, ok := seen[word.Surface]; ok {
+			return nil, fmt.Errorf("line %d: %q is already defined on line %d", line, word.Surface, previous)
+		}
+		seen[word.Surface] = line
+		words = append(words, word)
+	}
+	return words, nil
+}
+
+// normalizeWord validates a word, converting a hiragana pronunciation to
+// katakana and filling in the default word type
+func normalizeWord(word domain.Word) (domain.Word, error) {
+	word.Surface = strings.TrimSpace(word.Surface)
+	if word.Surface == "" {
+		return word, fmt.Errorf("surface is required")
+	}
+
+	pronunciation := []rune(strings.TrimSpace(word.Pronunciation))
+	if len(pronunciation) == 0 {
+		return word, fmt.Errorf("pronunciation is required")
+	}
+	for i, r := range pronunciation {
+		if r >= 'ぁ' && r <= 'ゖ' {
+			pronunciation[i] = r + ('ァ' - 'ぁ')
+		} else if !(r >= 'ァ' && r <= 'ヶ') && r != 'ー' {
+			return word, fmt.Errorf("pronunciation %q must be written in katakana", word.Pronunciation)
+		}
+	}
+	word.Pronunciation = string(pronunciation)
+
+	if moras := MoraCount(word.Pronunciation); word.AccentType < 0 || word.AccentType > moras {
+		return word, fmt.Errorf("accent type must be between 0 and %d, the number of morae in %s", moras, word.Pronunciation)
+	}
+	if word.Priority < domain.MinWordPriority || word.Priority > domain.MaxWordPriority {
+		return word, fmt.Errorf("priority must be between %d and %d", domain.MinWordPriority, domain.MaxWordPriority)
+	}
+
+	switch word.WordType {
+	case "":
+		word.WordType = domain.WordTypeProperNoun
+	case domain.WordTypeProperNoun, domain.WordTypeCommonNoun, domain.WordTypeVerb, domain.WordTypeAdjective, domain.WordTypeSuffix:
+	default:
+		return word, fmt.Errorf("unknown word type %q (use PROPER_NOUN, COMMON_NOUN, VERB, ADJECTIVE or SUFFIX)", word.WordType)
+	}
+	return word, nil
+}
+
+// MoraCount returns the number of morae in a katakana reading; small vowels
+// and ャュョヮ join the preceding mora
+func MoraCount(pronunciation string) int {
+	count := utf8.RuneCountInString(pronunciation)
+	for _, r := range pronunciation {
+		switch r {
+		case 'ァ', 'ィ', 'ゥ', 'ェ', 'ォ', 'ャ', 'ュ', 'ョ', 'ヮ':
+			count--
+		}
+	}
+	return count
+}
+
+func findWordBySurface(words []domain.Word, surface string) int {
+	surface = strings.TrimSpace(surface)
+	for i, word := range words {
+		if word.Surface == surface {
+			return i
+		}
+	}
+	return -1
+}
+
+func findWordByUUID(words []domain.Word, wordUUID string) int {
+	for i, word := range words {
+		if word.UUID == wordUUID {
+			return i
+		}
+	}
+	return -1
+}
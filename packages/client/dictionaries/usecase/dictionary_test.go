@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+)
+
+// memoryDictionaryRepo keeps dictionaries in memory, copying them on the way
+// in and out like the API does
+type memoryDictionaryRepo struct {
+	dictionaries map[string]domain.UserDictionary
+	puts         int
+}
+
+func newMemoryDictionaryRepo(dictionaries ...domain.UserDictionary) *memoryDictionaryRepo {
+	r := &memoryDictionaryRepo{dictionaries: make(map[string]domain.UserDictionary)}
+	for _, d := range dictionaries {
+		r.dictionaries[d.UUID] = d
+	}
+	return r
+}
+
+func (r *memoryDictionaryRepo) ListDictionaries(ctx context.Context) (*domain.UserDictionaryListResponse, error) {
+	response := &domain.UserDictionaryListResponse{}
+	for _, d := range r.dictionaries {
+		response.UserDictionaries = append(response.UserDictionaries, d)
+	}
+	response.Total = len(response.UserDictionaries)
+	return response, nil
+}
+
+func (r *memoryDictionaryRepo) GetDictionary(ctx context.Context, uuid string) (*domain.UserDictionary, error) {
+	d, ok := r.dictionaries[uuid]
+	if !ok {
+		return nil, context.Canceled
+	}
+	d.Words = append([]domain.Word(nil), d.Words...)
+	return &d, nil
+}
+
+func (r *memoryDictionaryRepo) PutDictionary(ctx context.Context, dictionary *domain.UserDictionary) (*domain.UserDictionary, error) {
+	saved := *dictionary
+	saved.Words = append([]domain.Word(nil), dictionary.Words...)
+	saved.WordCount = len(saved.Words)
+	r.dictionaries[saved.UUID] = saved
+	r.puts++
+	return &saved, nil
+}
+
+func (r *memoryDictionaryRepo) DeleteDictionary(ctx context.Context, uuid string) error {
+	delete(r.dictionaries, uuid)
+	return nil
+}
+
+func TestMoraCount(t *testing.T) {
+	tests := map[string]int{
+		"アイビス":   4,
+		"キャット":   3,
+		"ティーシャツ": 4,
+		"ウォッチ":   3,
+	}
+	for in, want := range tests {
+		if got := MoraCount(in); got != want {
+			t.Errorf("MoraCount(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestNormalizeWord(t *testing.T) {
+	word, err := normalizeWord(domain.Word{Surface: " Aivis ", Pronunciation: "あいびす", AccentType: 1, Priority: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if word.Surface != "Aivis" || word.Pronunciation != "アイビス" || word.WordType != domain.WordTypeProperNoun {
+		t.Errorf("unexpected normalized word: %+v", word)
+	}
+
+	invalid := map[string]domain.Word{
+		"surface":       {Pronunciation: "アイ"},
+		"pronunciation": {Surface: "Aivis", Pronunciation: "aivis"},
+		"accent":        {Surface: "Aivis", Pronunciation: "アイビス", AccentType: 5},
+		"priority":      {Surface: "Aivis", Pronunciation: "アイビス", Priority: 11},
+		"word type":     {Surface: "Aivis", Pronunciation: "アイビス", WordType: "NOUN"},
+	}
+	for name, w := range invalid {
+		if _, err := normalizeWord(w); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestWordCRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryDictionaryRepo()
+	u := NewUserDictionaryUsecase(repo)
+
+	dictionary, err := u.CreateDictionary(ctx, "Names", "")
+	if err != nil {
+		t.Fatalf("CreateDictionary: %v", err)
+	}
+
+	word, err := u.AddWord(ctx, dictionary.UUID, domain.Word{Surface: "Aivis", Pronunciation: "アイビス", AccentType: 1, Priority: 5})
+	if err != nil {
+		t.Fatalf("AddWord: %v", err)
+	}
+	if word.UUID == "" {
+		t.Error("expected the added word to get a UUID")
+	}
+	if _, err := u.AddWord(ctx, dictionary.UUID, domain.Word{Surface: "Aivis", Pronunciation: "エイビス"}); err == nil {
+		t.Error("expected AddWord to reject a duplicate surface")
+	}
+
+	replaced, added, err := u.SetWord(ctx, dictionary.UUID, domain.Word{Surface: "Aivis", Pronunciation: "エイビス", AccentType: 1, Priority: 5})
+	if err != nil || added {
+		t.Fatalf("SetWord: added=%v err=%v", added, err)
+	}
+	if replaced.UUID != word.UUID {
+		t.Errorf("SetWord should keep the word UUID, got %s want %s", replaced.UUID, word.UUID)
+	}
+
+	priority := 8
+	updated, err := u.UpdateWord(ctx, dictionary.UUID, word.UUID, &domain.WordUpdate{Priority: &priority})
+	if err != nil {
+		t.Fatalf("UpdateWord: %v", err)
+	}
+	if updated.Priority != 8 || updated.Pronunciation != "エイビス" {
+		t.Errorf("unexpected updated word: %+v", updated)
+	}
+
+	if err := u.DeleteWord(ctx, dictionary.UUID, word.UUID); err != nil {
+		t.Fatalf("DeleteWord: %v", err)
+	}
+	got, _ := u.GetDictionary(ctx, dictionary.UUID)
+	if len(got.Words) != 0 {
+		t.Errorf("expected no words after delete, got %d", len(got.Words))
+	}
+}
+
+func TestImportExportWordsCSV(t *testing.T) {
+	ctx := context.Background()
+	repo := newMemoryDictionaryRepo(domain.UserDictionary{
+		UUID: "dict",
+		Name: "Names",
+		Words: []domain.Word{
+			{UUID: "w1", Surface: "Aivis", Pronunciation: "アイビス", AccentType: 1, WordType: domain.WordTypeProperNoun, Priority: 5},
+			{UUID: "w2", Surface: "Cloud", Pronunciation: "クラウド", AccentType: 2, WordType: domain.WordTypeCommonNoun, Priority: 5},
+		},
+	})
+	u := NewUserDictionaryUsecase(repo)
+
+	input := "surface,pronunciation,accent_type,priority\nAivis,えいびす,1,7\nKajidog,カジドッグ,0\n"
+	result, err := u.ImportWordsCSV(ctx, "dict", strings.NewReader(input), false)
+	if err != nil {
+		t.Fatalf("ImportWordsCSV: %v", err)
+	}
+	if *result != (domain.WordImportResult{Added: 1, Updated: 1, Total: 3}) {
+		t.Errorf("unexpected merge result: %+v", result)
+	}
+	got, _ := u.GetDictionary(ctx, "dict")
+	if got.Words[0].UUID != "w1" || got.Words[0].Pronunciation != "エイビス" || got.Words[0].Priority != 7 {
+		t.Errorf("expected Aivis to be updated in place, got %+v", got.Words[0])
+	}
+	if got.Words[2].Priority != domain.DefaultWordPriority {
+		t.Errorf("expected the default priority for a missing column, got %d", got.Words[2].Priority)
+	}
+
+	result, err = u.ImportWordsCSV(ctx, "dict", strings.NewReader("Kajidog,カジドッグ,1\n"), true)
+	if err != nil {
+		t.Fatalf("ImportWordsCSV replace: %v", err)
+	}
+	if *result != (domain.WordImportResult{Updated: 1, Removed: 2, Total: 1}) {
+		t.Errorf("unexpected replace result: %+v", result)
+	}
+
+	var buf bytes.Buffer
+	n, err := u.ExportWordsCSV(ctx, "dict", &buf)
+	if err != nil || n != 1 {
+		t.Fatalf("ExportWordsCSV: n=%d err=%v", n, err)
+	}
+	want := "surface,pronunciation,accent_type,word_type,priority\nKajidog,カジドッグ,1,PROPER_NOUN,5\n"
+	if buf.String() != want {
+		t.Errorf("unexpected export:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// A failed import leaves the dictionary untouched
+	puts := repo.puts
+	if _, err := u.ImportWordsCSV(ctx, "dict", strings.NewReader("Aivis,アイビス,1\nBad,bad,0\n"), false); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error naming line 2, got %v", err)
+	}
+	if repo.puts != puts {
+		t.Error("expected no write after a failed import")
+	}
+}
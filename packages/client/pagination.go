@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/pager"
+	modelsDomain "github.com/kajidog/aivis-cloud-cli/client/models/domain"
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+)
+
+// maxModelPageSize is the largest page size the model search accepts
+const maxModelPageSize = 100
+
+// SubscriptionsPager iterates over all subscriptions, fetching pageSize at a
+// time (pager.DefaultPageSize when not positive)
+func (c *Client) SubscriptionsPager(pageSize int) *pager.Pager[paymentDomain.Subscription] {
+	return pager.New(pageSize, func(ctx context.Context, offset, limit int) ([]paymentDomain.Subscription, bool, error) {
+		response, err := c.paymentService.GetSubscriptions(ctx, limit, offset)
+		if err != nil {
+			return nil, false, err
+		}
+		return response.Subscriptions, response.HasMore, nil
+	})
+}
+
+// CreditTransactionsPager iterates over all credit transactions matching the
+// filters, fetching pageSize at a time
+func (c *Client) CreditTransactionsPager(transactionType paymentDomain.TransactionType, status paymentDomain.TransactionStatus, startDate, endDate *time.Time, pageSize int) *pager.Pager[paymentDomain.CreditTransaction] {
+	return pager.New(pageSize, func(ctx context.Context, offset, limit int) ([]paymentDomain.CreditTransaction, bool, error) {
+		response, err := c.paymentService.GetCreditTransactions(ctx, transactionType, status, startDate, endDate, limit, offset)
+		if err != nil {
+			return nil, false, err
+		}
+		return response.Transactions, response.HasMore, nil
+	})
+}
+
+// APIKeysPager iterates over all API keys, fetching pageSize at a time
+func (c *Client) APIKeysPager(pageSize int) *pager.Pager[paymentDomain.APIKey] {
+	return pager.New(pageSize, func(ctx context.Context, offset, limit int) ([]paymentDomain.APIKey, bool, error) {
+		response, err := c.paymentService.GetAPIKeys(ctx, limit, offset)
+		if err != nil {
+			return nil, false, err
+		}
+		return response.APIKeys, response.HasMore, nil
+	})
+}
+
+// SearchModelsPager iterates over all models matching the search, from the
+// request's page (the first when unset), fetching its page size at a time
+func (c *Client) SearchModelsPager(request *modelsDomain.ModelSearchRequest) *pager.Pager[modelsDomain.Model] {
+	search := *request
+	pageSize := pager.DefaultPageSize
+	if search.PageSize != nil {
+		pageSize = min(*search.PageSize, maxModelPageSize)
+	}
+	page := 1
+	if search.Page != nil {
+		page = *search.Page
+	}
+	return pager.New(pageSize, func(ctx context.Context, offset, limit int) ([]modelsDomain.Model, bool, error) {
+		// The search is paged by number, so the pager's offset is not needed
+		current := page
+		search.Page, search.PageSize = &current, &limit
+		response, err := c.modelsService.SearchModels(ctx, &search)
+		if err != nil {
+			return nil, false, err
+		}
+		page++
+		return response.Models, response.Pagination.HasNext, nil
+	})
+}
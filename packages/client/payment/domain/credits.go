@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// CreditObservation is a remaining credit balance reported by the API in the
+// X-Aivis-Credits-Remaining header of a response
+type CreditObservation struct {
+	Remaining  float64   `json:"remaining"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// CreditAlert is the event emitted when the remaining credits fall to or
+// below an alert threshold
+type CreditAlert struct {
+	// Threshold is the lowest threshold crossed by the observation
+	Threshold float64 `json:"threshold"`
+	Remaining float64 `json:"remaining"`
+	// Previous is the balance observed before, nil on the first observation
+	Previous   *float64  `json:"previous,omitempty"`
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// Message describes the alert in a sentence
+func (a *CreditAlert) Message() string {
+	return fmt.Sprintf("Aivis Cloud credits are low: %g remaining (alert threshold %g)", a.Remaining, a.Threshold)
+}
+
+// CreditNotifier delivers credit alerts, e.g. to a terminal, a log or a webhook
+type CreditNotifier interface {
+	Notify(ctx context.Context, alert *CreditAlert) error
+}
+
+// CreditNotifierFunc adapts a function to CreditNotifier
+type CreditNotifierFunc func(ctx context.Context, alert *CreditAlert) error
+
+// Notify calls f
+func (f CreditNotifierFunc) Notify(ctx context.Context, alert *CreditAlert) error {
+	return f(ctx, alert)
+}
+
+// CreditStateRepository persists the last observed credit balance, so that
+// thresholds crossed between processes are noticed and the balance can be
+// shown without a request
+type CreditStateRepository interface {
+	// Last returns the last observation, or nil when none was recorded
+	Last(ctx context.Context) (*CreditObservation, error)
+	Save(ctx context.Context, observation *CreditObservation) error
+}
@@ -0,0 +1,139 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/logger"
+	"github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+)
+
+// notifyTimeout bounds webhook requests and commands, so that a hanging one
+// does not hold up the alerts queued after it
+const notifyTimeout = 10 * time.Second
+
+// WriterCreditNotifier prints alerts as warnings to a writer such as stderr
+type WriterCreditNotifier struct {
+	writer io.Writer
+}
+
+// NewWriterCreditNotifier creates a notifier printing to writer
+func NewWriterCreditNotifier(writer io.Writer) *WriterCreditNotifier {
+	return &WriterCreditNotifier{writer: writer}
+}
+
+// NewStderrCreditNotifier creates a notifier printing to stderr
+func NewStderrCreditNotifier() *WriterCreditNotifier {
+	return NewWriterCreditNotifier(os.Stderr)
+}
+
+// Notify prints the alert
+func (n *WriterCreditNotifier) Notify(ctx context.Context, alert *domain.CreditAlert) error {
+	_, err := fmt.Fprintf(n.writer, "Warning: %s\n", alert.Message())
+	return err
+}
+
+// LogCreditNotifier writes alerts as warning log entries
+type LogCreditNotifier struct {
+	logger logger.Logger
+}
+
+// NewLogCreditNotifier creates a notifier logging to logger
+func NewLogCreditNotifier(logger logger.Logger) *LogCreditNotifier {
+	return &LogCreditNotifier{logger: logger}
+}
+
+// Notify logs the alert
+func (n *LogCreditNotifier) Notify(ctx context.Context, alert *domain.CreditAlert) error {
+	n.logger.Warn("Remaining credits fell below alert threshold",
+		logger.Float64("credits_remaining", alert.Remaining),
+		logger.Float64("threshold", alert.Threshold),
+	)
+	return nil
+}
+
+// WebhookCreditNotifier posts alerts as JSON to a URL
+type WebhookCreditNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookCreditNotifier creates a notifier posting to url
+func NewWebhookCreditNotifier(url string) *WebhookCreditNotifier {
+	return &WebhookCreditNotifier{url: url, client: &http.Client{Timeout: notifyTimeout}}
+}
+
+// webhookPayload is the body posted for an alert
+type webhookPayload struct {
+	Event   string `json:"event"`
+	Message string `json:"message"`
+	*domain.CreditAlert
+}
+
+// Notify posts the alert and fails on a non-2xx response
+func (n *WebhookCreditNotifier) Notify(ctx context.Context, alert *domain.CreditAlert) error {
+	body, err := json.Marshal(webhookPayload{Event: "credits_low", Message: alert.Message(), CreditAlert: alert})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create credit alert webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post credit alert webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("credit alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// CommandCreditNotifier runs a shell command for every alert, e.g.
+// notify-send or osascript for a desktop notification. The alert is passed in
+// the AIVIS_CREDITS_REMAINING, AIVIS_CREDITS_THRESHOLD and
+// AIVIS_CREDITS_MESSAGE environment variables.
+type CommandCreditNotifier struct {
+	command string
+}
+
+// NewCommandCreditNotifier creates a notifier running command
+func NewCommandCreditNotifier(command string) *CommandCreditNotifier {
+	return &CommandCreditNotifier{command: command}
+}
+
+// Notify runs the command and waits for it to exit
+func (n *CommandCreditNotifier) Notify(ctx context.Context, alert *domain.CreditAlert) error {
+	ctx, cancel := context.WithTimeout(ctx, notifyTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", n.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", n.command)
+	}
+	cmd.Env = append(os.Environ(),
+		"AIVIS_CREDITS_REMAINING="+strconv.FormatFloat(alert.Remaining, 'f', -1, 64),
+		"AIVIS_CREDITS_THRESHOLD="+strconv.FormatFloat(alert.Threshold, 'f', -1, 64),
+		"AIVIS_CREDITS_MESSAGE="+alert.Message(),
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("credit alert command failed: %w: %s", err, bytes.TrimSpace(output))
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+)
+
+func testAlert() *domain.CreditAlert {
+	previous := 120.0
+	return &domain.CreditAlert{Threshold: 100, Remaining: 80, Previous: &previous, ObservedAt: time.Now()}
+}
+
+func TestWebhookCreditNotifier(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected request %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+	}))
+	defer server.Close()
+
+	if err := NewWebhookCreditNotifier(server.URL).Notify(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if payload["event"] != "credits_low" || payload["remaining"] != 80.0 || payload["threshold"] != 100.0 || payload["previous"] != 120.0 {
+		t.Errorf("unexpected payload %v", payload)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := NewWebhookCreditNotifier(failing.URL).Notify(context.Background(), testAlert()); err == nil {
+		t.Error("expected a failing webhook to return an error")
+	}
+}
+
+func TestCommandCreditNotifier(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	out := filepath.Join(t.TempDir(), "alert.txt")
+	notifier := NewCommandCreditNotifier(`echo "$AIVIS_CREDITS_REMAINING/$AIVIS_CREDITS_THRESHOLD" > ` + out)
+	if err := notifier.Notify(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil || strings.TrimSpace(string(data)) != "80/100" {
+		t.Errorf("command wrote %q, %v", data, err)
+	}
+
+	if err := NewCommandCreditNotifier("exit 3").Notify(context.Background(), testAlert()); err == nil {
+		t.Error("expected a failing command to return an error")
+	}
+}
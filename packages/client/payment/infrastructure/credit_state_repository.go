@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+)
+
+// FileCreditStateRepository implements CreditStateRepository with a JSON file
+// holding the last observation
+type FileCreditStateRepository struct {
+	path string
+}
+
+// NewFileCreditStateRepository creates a credit state stored at path
+func NewFileCreditStateRepository(path string) *FileCreditStateRepository {
+	return &FileCreditStateRepository{path: path}
+}
+
+// Last reads the last observation; a missing file means none was recorded
+func (r *FileCreditStateRepository) Last(ctx context.Context) (*domain.CreditObservation, error) {
+	data, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credit state: %w", err)
+	}
+	var observation domain.CreditObservation
+	if err := json.Unmarshal(data, &observation); err != nil {
+		return nil, fmt.Errorf("credit state %s is corrupt: %w", r.path, err)
+	}
+	return &observation, nil
+}
+
+// Save replaces the last observation. The file is replaced atomically so
+// that concurrent processes never read a partial write.
+func (r *FileCreditStateRepository) Save(ctx context.Context, observation *domain.CreditObservation) error {
+	data, err := json.MarshalIndent(observation, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(r.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(r.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write credit state: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credit state: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credit state: %w", err)
+	}
+	return os.Rename(tmp.Name(), r.path)
+}
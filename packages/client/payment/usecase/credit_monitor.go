@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+)
+
+// alertQueueSize is the number of alerts waiting for delivery beyond which
+// new alerts are dropped
+const alertQueueSize = 16
+
+// CreditMonitor follows the remaining credit balance reported by the API and
+// notifies its notifiers when the balance falls to or below a threshold. An
+// alert fires once per crossing: it fires again only after the balance has
+// risen above the threshold (e.g. after a top-up) and fallen again.
+//
+// Alerts are delivered in the background, so that a slow notifier never
+// holds up the request that observed the balance. Call Flush before exiting
+// to deliver the pending ones.
+type CreditMonitor struct {
+	repository domain.CreditStateRepository
+	thresholds []float64
+	now        func() time.Time
+
+	mu        sync.Mutex
+	notifiers []domain.CreditNotifier
+	onError   func(error)
+
+	queue     chan *domain.CreditAlert
+	startOnce sync.Once
+
+	pendingMu sync.Mutex
+	pending   int
+	idle      chan struct{}
+}
+
+// NewCreditMonitor creates a monitor that records observations in repository
+// and alerts at the given thresholds
+func NewCreditMonitor(repository domain.CreditStateRepository, thresholds []float64) *CreditMonitor {
+	sorted := append([]float64(nil), thresholds...)
+	sort.Float64s(sorted)
+	return &CreditMonitor{
+		repository: repository,
+		thresholds: sorted,
+		now:        time.Now,
+		queue:      make(chan *domain.CreditAlert, alertQueueSize),
+	}
+}
+
+// SetErrorHandler sets the function receiving the errors of notifiers, which
+// run in the background. Without one they are discarded.
+func (m *CreditMonitor) SetErrorHandler(onError func(error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onError = onError
+}
+
+// AddNotifier adds a notifier that receives every alert
+func (m *CreditMonitor) AddNotifier(notifier domain.CreditNotifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifiers = append(m.notifiers, notifier)
+}
+
+// Last returns the last observed balance, or nil when none was observed
+func (m *CreditMonitor) Last(ctx context.Context) (*domain.CreditObservation, error) {
+	return m.repository.Last(ctx)
+}
+
+// Observe records a remaining balance and returns the alert it raised, if
+// any. The alert is queued for delivery; Observe does not wait for the
+// notifiers. When the queue is full the alert is dropped and reported in the
+// error.
+func (m *CreditMonitor) Observe(ctx context.Context, remaining float64) (*domain.CreditAlert, error) {
+	alert, notify, err := m.record(ctx, remaining)
+	if alert == nil || !notify || err != nil {
+		return alert, err
+	}
+
+	m.startOnce.Do(func() { go m.deliver() })
+	m.addPending()
+	select {
+	case m.queue <- alert:
+		return alert, nil
+	default:
+		m.donePending()
+		return alert, fmt.Errorf("credit alert queue is full, dropped alert for threshold %v", alert.Threshold)
+	}
+}
+
+// record saves the observation and returns the alert it raises, and whether
+// there are notifiers to deliver it to
+func (m *CreditMonitor) record(ctx context.Context, remaining float64) (*domain.CreditAlert, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	previous, err := m.repository.Last(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	observation := &domain.CreditObservation{Remaining: remaining, ObservedAt: m.now()}
+	if err := m.repository.Save(ctx, observation); err != nil {
+		return nil, false, err
+	}
+	return m.crossed(previous, observation), len(m.notifiers) > 0, nil
+}
+
+// deliver sends the queued alerts to the notifiers, one alert at a time.
+// Notifier failures go to the error handler; the other notifiers are still
+// called.
+func (m *CreditMonitor) deliver() {
+	for alert := range m.queue {
+		m.mu.Lock()
+		notifiers := append([]domain.CreditNotifier(nil), m.notifiers...)
+		onError := m.onError
+		m.mu.Unlock()
+
+		for _, notifier := range notifiers {
+			if err := notifier.Notify(context.Background(), alert); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+		m.donePending()
+	}
+}
+
+func (m *CreditMonitor) addPending() {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+	if m.pending == 0 {
+		m.idle = make(chan struct{})
+	}
+	m.pending++
+}
+
+func (m *CreditMonitor) donePending() {
+	m.pendingMu.Lock()
+	defer m.pendingMu.Unlock()
+	m.pending--
+	if m.pending == 0 {
+		close(m.idle)
+	}
+}
+
+// Flush waits until the queued alerts are delivered, or ctx is done
+func (m *CreditMonitor) Flush(ctx context.Context) error {
+	m.pendingMu.Lock()
+	if m.pending == 0 {
+		m.pendingMu.Unlock()
+		return nil
+	}
+	idle := m.idle
+	m.pendingMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// crossed returns the alert for the lowest threshold the balance fell to
+// since the previous observation
+func (m *CreditMonitor) crossed(previous, current *domain.CreditObservation) *domain.CreditAlert {
+	for _, threshold := range m.thresholds {
+		if current.Remaining > threshold {
+			continue
+		}
+		if previous != nil && previous.Remaining <= threshold {
+			// Already below this threshold, and so below every higher one
+			return nil
+		}
+		alert := &domain.CreditAlert{
+			Threshold:  threshold,
+			Remaining:  current.Remaining,
+			ObservedAt: current.ObservedAt,
+		}
+		if previous != nil {
+			alert.Previous = &previous.Remaining
+		}
+		return alert
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	"github.com/kajidog/aivis-cloud-cli/client/payment/infrastructure"
+)
+
+func newTestCreditMonitor(t *testing.T, thresholds ...float64) *CreditMonitor {
+	t.Helper()
+	repository := infrastructure.NewFileCreditStateRepository(filepath.Join(t.TempDir(), "credits.json"))
+	return NewCreditMonitor(repository, thresholds)
+}
+
+func TestCreditMonitorCrossed(t *testing.T) {
+	monitor := newTestCreditMonitor(t, 100, 10, 50)
+	observation := func(remaining float64) *domain.CreditObservation {
+		return &domain.CreditObservation{Remaining: remaining}
+	}
+	tests := []struct {
+		name      string
+		previous  *domain.CreditObservation
+		remaining float64
+		threshold float64 // 0 for no alert
+	}{
+		{name: "first observation above all", remaining: 150},
+		{name: "first observation below one", remaining: 80, threshold: 100},
+		{name: "first observation below several alerts the lowest", remaining: 5, threshold: 10},
+		{name: "falls to a threshold", previous: observation(120), remaining: 100, threshold: 100},
+		{name: "falls past several", previous: observation(120), remaining: 30, threshold: 50},
+		{name: "stays above", previous: observation(120), remaining: 101},
+		{name: "stays below", previous: observation(90), remaining: 60},
+		{name: "falls further below the same threshold", previous: observation(40), remaining: 20},
+		{name: "falls to the next threshold", previous: observation(40), remaining: 10, threshold: 10},
+		{name: "rises", previous: observation(20), remaining: 200},
+		{name: "top-up then fall again", previous: observation(200), remaining: 90, threshold: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			alert := monitor.crossed(tt.previous, observation(tt.remaining))
+			switch {
+			case tt.threshold == 0 && alert != nil:
+				t.Errorf("crossed() = %+v, want no alert", alert)
+			case tt.threshold != 0 && (alert == nil || alert.Threshold != tt.threshold || alert.Remaining != tt.remaining):
+				t.Errorf("crossed() = %+v, want an alert at %v", alert, tt.threshold)
+			case alert != nil && (alert.Previous == nil) != (tt.previous == nil):
+				t.Errorf("crossed() previous = %v, want %v", alert.Previous, tt.previous)
+			}
+		})
+	}
+
+	if alert := newTestCreditMonitor(t).crossed(nil, observation(0)); alert != nil {
+		t.Errorf("crossed() without thresholds = %+v", alert)
+	}
+}
+
+func TestCreditMonitorDeliversInBackground(t *testing.T) {
+	monitor := newTestCreditMonitor(t, 50)
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var delivered []*domain.CreditAlert
+	monitor.AddNotifier(domain.CreditNotifierFunc(func(ctx context.Context, alert *domain.CreditAlert) error {
+		<-release
+		mu.Lock()
+		delivered = append(delivered, alert)
+		mu.Unlock()
+		return errors.New("webhook down")
+	}))
+	var failures []error
+	monitor.SetErrorHandler(func(err error) { failures = append(failures, err) })
+
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		monitor.Observe(ctx, 100)
+		if alert, err := monitor.Observe(ctx, 40); alert == nil || err != nil {
+			t.Errorf("Observe() = %+v, %v, want an alert", alert, err)
+		}
+		// The observation is recorded before the alert is delivered
+		if last, _ := monitor.Last(ctx); last == nil || last.Remaining != 40 {
+			t.Errorf("Last() = %+v, want 40", last)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Observe() waited for a blocked notifier")
+	}
+
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := monitor.Flush(short); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Flush() with a blocked notifier = %v, want a deadline error", err)
+	}
+
+	close(release)
+	if err := monitor.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(delivered) != 1 || delivered[0].Threshold != 50 || len(failures) != 1 {
+		t.Errorf("delivered %+v with failures %v, want one alert and one failure", delivered, failures)
+	}
+}
+
+func TestCreditMonitorDropsWhenQueueIsFull(t *testing.T) {
+	monitor := newTestCreditMonitor(t, 50)
+	release := make(chan struct{})
+	defer close(release)
+	monitor.AddNotifier(domain.CreditNotifierFunc(func(ctx context.Context, alert *domain.CreditAlert) error {
+		<-release
+		return nil
+	}))
+
+	// Each rise and fall raises an alert; one is being delivered and
+	// alertQueueSize wait, the rest are dropped
+	ctx := context.Background()
+	var dropped int
+	for i := 0; i < alertQueueSize+5; i++ {
+		monitor.Observe(ctx, 100)
+		if _, err := monitor.Observe(ctx, 40); err != nil {
+			dropped++
+		}
+	}
+	if dropped == 0 || dropped > 5 {
+		t.Errorf("dropped %d alerts, want between 1 and 5", dropped)
+	}
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// UsageReportResult is a usage report and, when the server usage of the
+// period could not be fetched, why. The report then covers local history
+// only.
+type UsageReportResult struct {
+	*ttsDomain.UsageReport
+	ServerError string `json:"server_error,omitempty"`
+}
+
+// GenerateUsageReport aggregates the local history of a period by model,
+// day, profile, project or tag, joined with the usage the server reports for
+// the period. The server usage is best effort: when it cannot be fetched the
+// report covers local history only.
+func (c *Client) GenerateUsageReport(ctx context.Context, options ttsDomain.UsageReportOptions) (*UsageReportResult, error) {
+	if c.historyManager == nil {
+		return nil, fmt.Errorf("history management is disabled")
+	}
+	if options.Start.IsZero() || options.End.IsZero() {
+		return nil, fmt.Errorf("report period start and end are required")
+	}
+	if options.End.Before(options.Start) {
+		return nil, fmt.Errorf("report period ends before it starts")
+	}
+
+	result := &UsageReportResult{}
+	var server *ttsDomain.ServerUsage
+	summary, err := c.paymentService.GetUsageSummaries(ctx, "", &options.Start, &options.End, "")
+	if err != nil {
+		result.ServerError = err.Error()
+	} else {
+		server = serverUsage(summary)
+	}
+
+	report, err := c.historyManager.UsageReport(ctx, options, server)
+	if err != nil {
+		return nil, err
+	}
+	result.UsageReport = report
+	return result, nil
+}
+
+// serverUsage converts a payment usage summary for the tts usage report
+func serverUsage(summary *paymentDomain.UsageSummary) *ttsDomain.ServerUsage {
+	usage := &ttsDomain.ServerUsage{
+		Requests: summary.TTSRequests,
+		Credits:  float64(summary.UsedCredits),
+	}
+	for _, model := range summary.BreakdownByModel {
+		usage.Models = append(usage.Models, ttsDomain.ServerModelUsage{
+			ModelUUID: model.ModelID,
+			ModelName: model.ModelName,
+			Requests:  model.Requests,
+			Credits:   float64(model.Credits),
+		})
+	}
+	return usage
+}
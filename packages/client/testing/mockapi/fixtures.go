@@ -0,0 +1,186 @@
+package mockapi
+
+import (
+	"time"
+
+	modelsDomain "github.com/kajidog/aivis-cloud-cli/client/models/domain"
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	usersDomain "github.com/kajidog/aivis-cloud-cli/client/users/domain"
+)
+
+// Fixture identifiers, usable in tests
+const (
+	// DefaultModelUUID is the model the CLI uses when none is configured
+	DefaultModelUUID = "a59cb814-0083-4369-8542-f51a29e72af7"
+	// MultiSpeakerModelUUID is a model with two speakers
+	MultiSpeakerModelUUID = "b1e0c2a4-6f3d-4c8e-9a57-2d41f0e8c913"
+	// NarratorModelUUID is a single-style narration model
+	NarratorModelUUID = "c7d5a9e2-1b84-4f60-8e3a-95b2c4d7f028"
+
+	// UserHandle is the handle of the authenticated user
+	UserHandle = "mock-user"
+)
+
+// fixtureTime is the creation time of the fixtures, fixed so that responses
+// are reproducible
+var fixtureTime = time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+
+func style(name string, localID int, isDefault bool) modelsDomain.Style {
+	return modelsDomain.Style{Name: name, LocalID: localID, IsDefault: isDefault}
+}
+
+func (s *Server) loadFixtures() {
+	author := &modelsDomain.User{
+		Handle:        "mock-studio",
+		Name:          "Mock Studio",
+		Description:   "Voice models served by the mock API",
+		AccountType:   "User",
+		AccountStatus: "Active",
+	}
+
+	s.models = []modelsDomain.Model{
+		{
+			UUID:               DefaultModelUUID,
+			Name:               "Mock Voice",
+			Description:        "The default model, with emotional styles",
+			Category:           "ExtractedFromRealPerson",
+			VoiceTimbre:        "YouthfulFemale",
+			Visibility:         "Public",
+			TotalDownloadCount: 12000,
+			LikeCount:          850,
+			CreatedAt:          fixtureTime,
+			UpdatedAt:          fixtureTime.AddDate(0, 3, 0),
+			User:               author,
+			Tags:               []modelsDomain.Tag{{Name: "女性"}, {Name: "感情表現"}},
+			Speakers: []modelsDomain.Speaker{{
+				UUID:               "e3f2a1b0-7c6d-4e5f-8a9b-0c1d2e3f4a5b",
+				Name:               "Mock Voice",
+				SupportedLanguages: []string{"ja"},
+				LocalID:            0,
+				IsDefault:          true,
+				Styles: []modelsDomain.Style{
+					style("ノーマル", 0, true),
+					style("喜び", 1, false),
+					style("悲しみ", 2, false),
+					style("怒り", 3, false),
+				},
+			}},
+			Author:   author.Name,
+			Language: "ja",
+			IsPublic: true,
+			Rating:   4.6,
+		},
+		{
+			UUID:               MultiSpeakerModelUUID,
+			Name:               "Mock Duo",
+			Description:        "A model with two speakers for dialogue",
+			Category:           "Others",
+			VoiceTimbre:        "Neutral",
+			Visibility:         "Public",
+			TotalDownloadCount: 4300,
+			LikeCount:          310,
+			CreatedAt:          fixtureTime.AddDate(0, 1, 0),
+			UpdatedAt:          fixtureTime.AddDate(0, 5, 0),
+			User:               author,
+			Tags:               []modelsDomain.Tag{{Name: "男性"}, {Name: "女性"}, {Name: "対話"}},
+			Speakers: []modelsDomain.Speaker{
+				{
+					UUID:               "0a1b2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d",
+					Name:               "Aoi",
+					SupportedLanguages: []string{"ja"},
+					LocalID:            0,
+					IsDefault:          true,
+					Styles:             []modelsDomain.Style{style("ノーマル", 0, true), style("ささやき", 1, false)},
+				},
+				{
+					UUID:               "5d6e7f80-9a1b-4c2d-8e3f-4a5b6c7d8e9f",
+					Name:               "Ren",
+					SupportedLanguages: []string{"ja"},
+					LocalID:            1,
+					Styles:             []modelsDomain.Style{style("ノーマル", 0, true), style("元気", 1, false)},
+				},
+			},
+			Author:   author.Name,
+			Language: "ja",
+			IsPublic: true,
+			Rating:   4.2,
+		},
+		{
+			UUID:               NarratorModelUUID,
+			Name:               "Mock Narrator",
+			Description:        "A calm voice for narration and long texts",
+			Category:           "ExtractedFromRealPerson",
+			VoiceTimbre:        "AdultMale",
+			Visibility:         "Public",
+			TotalDownloadCount: 950,
+			LikeCount:          72,
+			CreatedAt:          fixtureTime.AddDate(0, 2, 0),
+			UpdatedAt:          fixtureTime.AddDate(0, 2, 0),
+			User:               author,
+			Tags:               []modelsDomain.Tag{{Name: "男性"}, {Name: "ナレーション"}},
+			Speakers: []modelsDomain.Speaker{{
+				UUID:               "9f8e7d6c-5b4a-4392-8170-6e5d4c3b2a19",
+				Name:               "Narrator",
+				SupportedLanguages: []string{"ja"},
+				LocalID:            0,
+				IsDefault:          true,
+				Styles:             []modelsDomain.Style{style("ノーマル", 0, true)},
+			}},
+			Author:   author.Name,
+			Language: "ja",
+			IsPublic: true,
+			Rating:   3.9,
+		},
+	}
+
+	s.user = usersDomain.UserMe{
+		User: usersDomain.User{
+			ID:         "user_000001",
+			Handle:     UserHandle,
+			Name:       "Mock User",
+			Email:      "mock-user@example.com",
+			IsVerified: true,
+			IsActive:   true,
+			CreatedAt:  fixtureTime,
+			UpdatedAt:  fixtureTime,
+		},
+		Settings: &usersDomain.UserSettings{Language: "ja", Timezone: "Asia/Tokyo"},
+	}
+
+	now := time.Now().UTC()
+	periodStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	s.subscription = paymentDomain.Subscription{
+		ID:                 "sub_000001",
+		PlanID:             "plan_standard",
+		PlanName:           "Standard",
+		Status:             paymentDomain.SubscriptionStatusActive,
+		CurrentPeriodStart: periodStart,
+		CurrentPeriodEnd:   periodStart.AddDate(0, 1, 0),
+		Amount:             1980,
+		Currency:           "JPY",
+		CreatedAt:          fixtureTime,
+		UpdatedAt:          periodStart,
+	}
+
+	credits := int64(s.options.Credits)
+	s.transactions = []paymentDomain.CreditTransaction{{
+		ID:          "txn_000000",
+		Type:        paymentDomain.TransactionTypeCredit,
+		Status:      paymentDomain.TransactionStatusCompleted,
+		Amount:      s.options.Credits,
+		Currency:    "JPY",
+		Credits:     &credits,
+		Description: "Credit purchase",
+		CreatedAt:   periodStart,
+		UpdatedAt:   periodStart,
+	}}
+
+	s.apiKeys = []paymentDomain.APIKey{{
+		ID:         "key_000001",
+		Name:       "default",
+		KeyPreview: "aivis_****mock",
+		IsActive:   true,
+		CreatedAt:  fixtureTime,
+		UpdatedAt:  fixtureTime,
+	}}
+}
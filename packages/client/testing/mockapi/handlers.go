@@ -0,0 +1,462 @@
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	dictionariesDomain "github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+	modelsDomain "github.com/kajidog/aivis-cloud-cli/client/models/domain"
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	usersDomain "github.com/kajidog/aivis-cloud-cli/client/users/domain"
+)
+
+// handleModels serves search, model and speaker requests; rest is the path
+// after /v1/aivm-models/
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request, rest string) {
+	if rest == "search" {
+		s.handleModelSearch(w, r)
+		return
+	}
+
+	modelUUID, sub, _ := strings.Cut(rest, "/")
+	model := s.findModel(modelUUID)
+	if model == nil {
+		writeError(w, http.StatusNotFound, "Specified model UUID not found")
+		return
+	}
+	switch sub {
+	case "":
+		writeJSON(w, http.StatusOK, model)
+	case "speakers":
+		writeJSON(w, http.StatusOK, model.Speakers)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) handleModelSearch(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	keyword := strings.ToLower(query.Get("q"))
+	author := strings.ToLower(query.Get("author"))
+	tags := query["tags"]
+
+	matched := []modelsDomain.Model{}
+	for _, model := range s.models {
+		if keyword != "" && !strings.Contains(strings.ToLower(model.Name+" "+model.Description), keyword) {
+			continue
+		}
+		if author != "" && strings.ToLower(model.User.Handle) != author && strings.ToLower(model.User.Name) != author {
+			continue
+		}
+		if !hasTags(model, tags) {
+			continue
+		}
+		matched = append(matched, model)
+	}
+
+	sortModels(matched, query.Get("sort"), query.Get("sort_order"))
+	start, end, _, _ := pageBounds(r, len(matched), 10)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"total":       len(matched),
+		"aivm_models": matched[start:end],
+	})
+}
+
+func hasTags(model modelsDomain.Model, tags []string) bool {
+	for _, want := range tags {
+		found := false
+		for _, tag := range model.Tags {
+			if strings.EqualFold(tag.Name, want) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sortModels orders models by a sort field; unknown fields keep the fixture order
+func sortModels(models []modelsDomain.Model, field, order string) {
+	var less func(a, b modelsDomain.Model) bool
+	switch field {
+	case "name":
+		less = func(a, b modelsDomain.Model) bool { return a.Name < b.Name }
+	case "created_at":
+		less = func(a, b modelsDomain.Model) bool { return a.CreatedAt.After(b.CreatedAt) }
+	case "updated_at":
+		less = func(a, b modelsDomain.Model) bool { return a.UpdatedAt.After(b.UpdatedAt) }
+	case "download", "download_count":
+		less = func(a, b modelsDomain.Model) bool { return a.TotalDownloadCount > b.TotalDownloadCount }
+	case "like", "like_count":
+		less = func(a, b modelsDomain.Model) bool { return a.LikeCount > b.LikeCount }
+	case "rating":
+		less = func(a, b modelsDomain.Model) bool { return a.Rating > b.Rating }
+	default:
+		return
+	}
+	sort.SliceStable(models, func(i, j int) bool {
+		if order == "asc" {
+			return less(models[j], models[i])
+		}
+		return less(models[i], models[j])
+	})
+}
+
+func (s *Server) findModel(modelUUID string) *modelsDomain.Model {
+	for i := range s.models {
+		if s.models[i].UUID == modelUUID {
+			return &s.models[i]
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleMe(w http.ResponseWriter) {
+	s.mu.Lock()
+	me := s.user
+	me.CreditBalance = &usersDomain.CreditBalance{Total: s.credits}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, me)
+}
+
+func (s *Server) handleUser(w http.ResponseWriter, handle string) {
+	if handle == s.user.Handle {
+		writeJSON(w, http.StatusOK, s.user.User)
+		return
+	}
+	for _, model := range s.models {
+		if model.User != nil && model.User.Handle == handle {
+			writeJSON(w, http.StatusOK, usersDomain.User{
+				ID:        "user_" + handle,
+				Handle:    handle,
+				Name:      model.User.Name,
+				IsActive:  true,
+				CreatedAt: fixtureTime,
+				UpdatedAt: fixtureTime,
+			})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "User not found")
+}
+
+// handlePayment serves the payment endpoints; rest is the path after /v1/payment/
+func (s *Server) handlePayment(w http.ResponseWriter, r *http.Request, rest string, body []byte) {
+	switch {
+	case rest == "subscriptions":
+		s.route(w, r, map[string]func(){http.MethodGet: func() { s.handleSubscriptions(w, r) }})
+	case rest == "credit-transactions":
+		s.route(w, r, map[string]func(){http.MethodGet: func() { s.handleTransactions(w, r) }})
+	case rest == "api-keys":
+		s.route(w, r, map[string]func(){
+			http.MethodGet:  func() { s.handleAPIKeys(w, r) },
+			http.MethodPost: func() { s.handleCreateAPIKey(w, body) },
+		})
+	case strings.HasPrefix(rest, "api-keys/"):
+		s.route(w, r, map[string]func(){http.MethodDelete: func() { s.handleDeleteAPIKey(w, strings.TrimPrefix(rest, "api-keys/")) }})
+	case rest == "usage-summaries":
+		s.route(w, r, map[string]func(){http.MethodGet: func() { s.handleUsage(w, r) }})
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+func (s *Server) handleSubscriptions(w http.ResponseWriter, r *http.Request) {
+	subscriptions := []paymentDomain.Subscription{s.subscription}
+	start, end, limit, offset := pageBounds(r, len(subscriptions), 20)
+	writeJSON(w, http.StatusOK, paymentDomain.SubscriptionListResponse{
+		Subscriptions: subscriptions[start:end],
+		Total:         len(subscriptions),
+		Limit:         limit,
+		Offset:        offset,
+		HasMore:       end < len(subscriptions),
+	})
+}
+
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	startDate, startErr := parseDate(query.Get("start_date"))
+	endDate, endErr := parseDate(query.Get("end_date"))
+	if startErr != nil || endErr != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Dates must be formatted as YYYY-MM-DD")
+		return
+	}
+
+	s.mu.Lock()
+	var matched []paymentDomain.CreditTransaction
+	for i := len(s.transactions) - 1; i >= 0; i-- {
+		t := s.transactions[i]
+		if v := query.Get("type"); v != "" && string(t.Type) != v {
+			continue
+		}
+		if v := query.Get("status"); v != "" && string(t.Status) != v {
+			continue
+		}
+		if !inRange(t.CreatedAt, startDate, endDate) {
+			continue
+		}
+		matched = append(matched, t)
+	}
+	s.mu.Unlock()
+
+	start, end, limit, offset := pageBounds(r, len(matched), 20)
+	writeJSON(w, http.StatusOK, paymentDomain.CreditTransactionListResponse{
+		Transactions: append([]paymentDomain.CreditTransaction{}, matched[start:end]...),
+		Total:        len(matched),
+		Limit:        limit,
+		Offset:       offset,
+		HasMore:      end < len(matched),
+	})
+}
+
+func (s *Server) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	keys := append([]paymentDomain.APIKey{}, s.apiKeys...)
+	s.mu.Unlock()
+
+	start, end, limit, offset := pageBounds(r, len(keys), 20)
+	writeJSON(w, http.StatusOK, paymentDomain.APIKeyListResponse{
+		APIKeys: keys[start:end],
+		Total:   len(keys),
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: end < len(keys),
+	})
+}
+
+func (s *Server) handleCreateAPIKey(w http.ResponseWriter, body []byte) {
+	var request paymentDomain.APIKeyCreateRequest
+	if err := json.Unmarshal(body, &request); err != nil || strings.TrimSpace(request.Name) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "name is required")
+		return
+	}
+
+	secret := strings.ReplaceAll(uuid.New().String(), "-", "")
+	now := time.Now().UTC()
+	key := paymentDomain.APIKey{
+		ID:         s.newID("key"),
+		Name:       request.Name,
+		KeyPreview: "aivis_****" + secret[len(secret)-4:],
+		IsActive:   true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	s.mu.Lock()
+	s.apiKeys = append(s.apiKeys, key)
+	s.mu.Unlock()
+
+	key.Key = "aivis_" + secret
+	writeJSON(w, http.StatusCreated, key)
+}
+
+func (s *Server) handleDeleteAPIKey(w http.ResponseWriter, keyID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, key := range s.apiKeys {
+		if key.ID == keyID {
+			s.apiKeys = append(s.apiKeys[:i], s.apiKeys[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "API key not found")
+}
+
+func (s *Server) handleUsage(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	period := query.Get("period")
+	if period == "" {
+		period = "month"
+	}
+	startDate, startErr := parseDate(query.Get("start_date"))
+	endDate, endErr := parseDate(query.Get("end_date"))
+	if startErr != nil || endErr != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Dates must be formatted as YYYY-MM-DD")
+		return
+	}
+	if startDate == nil {
+		now := time.Now().UTC()
+		var since time.Time
+		switch period {
+		case "day":
+			since = now.AddDate(0, 0, -1)
+		case "week":
+			since = now.AddDate(0, 0, -7)
+		case "month":
+			since = now.AddDate(0, -1, 0)
+		case "year":
+			since = now.AddDate(-1, 0, 0)
+		default:
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("unsupported period %q", period))
+			return
+		}
+		startDate = &since
+	}
+
+	summary := paymentDomain.UsageSummary{Period: period}
+	byModel := make(map[string]*paymentDomain.UsageByModel)
+	var order []string
+
+	s.mu.Lock()
+	summary.TotalCredits = int64(s.options.Credits)
+	for _, record := range s.syntheses {
+		if !inRange(record.At, startDate, endDate) {
+			continue
+		}
+		if v := query.Get("model_id"); v != "" && record.ModelUUID != v {
+			continue
+		}
+		minutes := record.Duration.Minutes()
+		summary.TTSRequests++
+		summary.UsedCredits += int64(record.Credits + 0.5)
+		summary.AudioMinutes += minutes
+
+		usage, ok := byModel[record.ModelUUID]
+		if !ok {
+			usage = &paymentDomain.UsageByModel{ModelID: record.ModelUUID}
+			if model := s.findModel(record.ModelUUID); model != nil {
+				usage.ModelName = model.Name
+			}
+			byModel[record.ModelUUID] = usage
+			order = append(order, record.ModelUUID)
+		}
+		usage.Requests++
+		usage.Credits += int64(record.Credits + 0.5)
+		usage.AudioMinutes += minutes
+	}
+	s.mu.Unlock()
+
+	for _, modelUUID := range order {
+		summary.BreakdownByModel = append(summary.BreakdownByModel, *byModel[modelUUID])
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
+
+func parseDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// inRange reports whether t falls on or between the given dates; the end
+// date is inclusive
+func inRange(t time.Time, start, end *time.Time) bool {
+	if start != nil && t.Before(*start) {
+		return false
+	}
+	if end != nil && !t.Before(end.AddDate(0, 0, 1)) {
+		return false
+	}
+	return true
+}
+
+// handleDictionaries serves the user dictionary endpoints; rest is the
+// dictionary UUID or empty for the list
+func (s *Server) handleDictionaries(w http.ResponseWriter, r *http.Request, rest string, body []byte) {
+	if rest == "" {
+		s.route(w, r, map[string]func(){http.MethodGet: func() { s.handleListDictionaries(w) }})
+		return
+	}
+	s.route(w, r, map[string]func(){
+		http.MethodGet:    func() { s.handleGetDictionary(w, rest) },
+		http.MethodPut:    func() { s.handlePutDictionary(w, rest, body) },
+		http.MethodDelete: func() { s.handleDeleteDictionary(w, rest) },
+	})
+}
+
+func (s *Server) handleListDictionaries(w http.ResponseWriter) {
+	s.mu.Lock()
+	response := dictionariesDomain.UserDictionaryListResponse{UserDictionaries: []dictionariesDomain.UserDictionary{}}
+	for _, dictionary := range s.dictionaries {
+		dictionary.WordCount = len(dictionary.Words)
+		dictionary.Words = nil
+		response.UserDictionaries = append(response.UserDictionaries, dictionary)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(response.UserDictionaries, func(i, j int) bool {
+		return response.UserDictionaries[i].CreatedAt.Before(response.UserDictionaries[j].CreatedAt)
+	})
+	response.Total = len(response.UserDictionaries)
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleGetDictionary(w http.ResponseWriter, dictionaryUUID string) {
+	s.mu.Lock()
+	dictionary, ok := s.dictionaries[dictionaryUUID]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "User dictionary not found")
+		return
+	}
+	if dictionary.Words == nil {
+		dictionary.Words = []dictionariesDomain.Word{}
+	}
+	dictionary.WordCount = len(dictionary.Words)
+	writeJSON(w, http.StatusOK, dictionary)
+}
+
+func (s *Server) handlePutDictionary(w http.ResponseWriter, dictionaryUUID string, body []byte) {
+	var request struct {
+		Name        string                    `json:"name"`
+		Description string                    `json:"description"`
+		Words       []dictionariesDomain.Word `json:"word_properties"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Invalid request body")
+		return
+	}
+	if strings.TrimSpace(request.Name) == "" {
+		writeError(w, http.StatusUnprocessableEntity, "name is required")
+		return
+	}
+	for i, word := range request.Words {
+		if word.Surface == "" || word.Pronunciation == "" {
+			writeError(w, http.StatusUnprocessableEntity, fmt.Sprintf("word_properties[%d]: surface and pronunciation are required", i))
+			return
+		}
+		if word.UUID == "" {
+			request.Words[i].UUID = uuid.New().String()
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC()
+	dictionary, exists := s.dictionaries[dictionaryUUID]
+	if !exists {
+		dictionary = dictionariesDomain.UserDictionary{UUID: dictionaryUUID, CreatedAt: now}
+	}
+	dictionary.Name = request.Name
+	dictionary.Description = request.Description
+	dictionary.Words = request.Words
+	dictionary.WordCount = len(request.Words)
+	dictionary.UpdatedAt = now
+	s.dictionaries[dictionaryUUID] = dictionary
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteDictionary(w http.ResponseWriter, dictionaryUUID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.dictionaries[dictionaryUUID]; !ok {
+		writeError(w, http.StatusNotFound, "User dictionary not found")
+		return
+	}
+	delete(s.dictionaries, dictionaryUUID)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,368 @@
+// Package mockapi implements a fake Aivis Cloud API for offline development
+// and end-to-end tests. It serves speech synthesis (a generated tone per
+// character, with pauses at punctuation, as WAV or FLAC with billing headers
+// and chunked streaming), model search, users, payment and user dictionary
+// endpoints from in-memory fixtures, and can inject errors, latency and rate
+// limiting. Point config.BaseURL at the server's URL to use it.
+package mockapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	dictionariesDomain "github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+	modelsDomain "github.com/kajidog/aivis-cloud-cli/client/models/domain"
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	usersDomain "github.com/kajidog/aivis-cloud-cli/client/users/domain"
+)
+
+// Defaults for zero Options fields
+const (
+	DefaultCredits             = 10000.0
+	DefaultCreditsPerCharacter = 0.5
+	DefaultErrorStatus         = http.StatusServiceUnavailable
+	DefaultStreamChunkSize     = 8192
+)
+
+// Options configures a mock server
+type Options struct {
+	// APIKey is the only accepted bearer token; when empty any key is accepted
+	APIKey string
+
+	// Credits is the starting credit balance
+	Credits float64
+	// CreditsPerCharacter is charged for each synthesized character
+	CreditsPerCharacter float64
+
+	// Latency delays every response, plus a random extra of up to LatencyJitter
+	Latency       time.Duration
+	LatencyJitter time.Duration
+
+	// ErrorRate is the fraction (0 to 1) of requests answered with ErrorStatus
+	ErrorRate   float64
+	ErrorStatus int
+
+	// RateLimit is the number of requests allowed per minute; requests over
+	// it get 429. Zero disables the limit and its response headers.
+	RateLimit int
+
+	// Synthesized audio is written in chunks of StreamChunkSize bytes with a
+	// pause of StreamChunkDelay between them
+	StreamChunkSize  int
+	StreamChunkDelay time.Duration
+
+	// Seed makes error injection and latency jitter reproducible
+	Seed int64
+
+	// Log, when set, receives one line per request
+	Log io.Writer
+}
+
+// RecordedRequest is a request received by the server
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+	Status int
+}
+
+// Server is the mock API. It is an http.Handler and safe for concurrent use.
+type Server struct {
+	options Options
+
+	mu           sync.Mutex
+	random       *rand.Rand
+	credits      float64
+	requests     []RecordedRequest
+	failures     []failure
+	windowStart  time.Time
+	windowCount  int
+	nextID       int
+	models       []modelsDomain.Model
+	user         usersDomain.UserMe
+	subscription paymentDomain.Subscription
+	transactions []paymentDomain.CreditTransaction
+	apiKeys      []paymentDomain.APIKey
+	syntheses    []synthesisRecord
+	dictionaries map[string]dictionariesDomain.UserDictionary
+}
+
+// failure is an error queued with FailNext
+type failure struct {
+	pathPrefix string
+	status     int
+	remaining  int
+}
+
+// New creates a mock server with the given options
+func New(options Options) *Server {
+	if options.Credits == 0 {
+		options.Credits = DefaultCredits
+	}
+	if options.CreditsPerCharacter == 0 {
+		options.CreditsPerCharacter = DefaultCreditsPerCharacter
+	}
+	if options.ErrorStatus == 0 {
+		options.ErrorStatus = DefaultErrorStatus
+	}
+	if options.StreamChunkSize <= 0 {
+		options.StreamChunkSize = DefaultStreamChunkSize
+	}
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	s := &Server{
+		options:      options,
+		random:       rand.New(rand.NewSource(seed)),
+		credits:      options.Credits,
+		dictionaries: make(map[string]dictionariesDomain.UserDictionary),
+	}
+	s.loadFixtures()
+	return s
+}
+
+// NewTestServer starts a mock server for a test and closes it when the test
+// ends. Point config.BaseURL at the returned httptest.Server's URL.
+func NewTestServer(t testing.TB, options Options) (*Server, *httptest.Server) {
+	t.Helper()
+	s := New(options)
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, server
+}
+
+// FailNext answers the next count requests whose path starts with
+// pathPrefix ("" for any path) with the given status
+func (s *Server) FailNext(pathPrefix string, status, count int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, failure{pathPrefix: pathPrefix, status: status, remaining: count})
+}
+
+// Credits returns the current credit balance
+func (s *Server) Credits() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.credits
+}
+
+// SetCredits changes the credit balance, e.g. to test insufficient credits
+func (s *Server) SetCredits(credits float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.credits = credits
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedRequest(nil), s.requests...)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	started := time.Now()
+	body, _ := io.ReadAll(r.Body)
+	r.Body.Close()
+
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	s.serve(recorder, r, body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, RecordedRequest{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.RawQuery,
+		Body:   body,
+		Status: recorder.status,
+	})
+	s.mu.Unlock()
+
+	if s.options.Log != nil {
+		fmt.Fprintf(s.options.Log, "%s %s %s %d %s\n",
+			started.Format("15:04:05"), r.Method, r.URL.RequestURI(), recorder.status, time.Since(started).Round(time.Millisecond))
+	}
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, body []byte) {
+	w.Header().Set("X-Request-ID", s.newID("req"))
+
+	if delay := s.delay(); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "API key is required or invalid")
+		return
+	}
+	if !s.allow(w) {
+		writeError(w, http.StatusTooManyRequests, "Rate limit exceeded")
+		return
+	}
+	if status := s.injectedFailure(r.URL.Path); status != 0 {
+		writeError(w, status, fmt.Sprintf("Injected error %d", status))
+		return
+	}
+
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	switch {
+	case path == "/v1/tts/synthesize":
+		s.route(w, r, map[string]func(){http.MethodPost: func() { s.handleSynthesize(w, r, body) }})
+	case strings.HasPrefix(path, "/v1/aivm-models/"):
+		s.route(w, r, map[string]func(){http.MethodGet: func() { s.handleModels(w, r, strings.TrimPrefix(path, "/v1/aivm-models/")) }})
+	case path == "/v1/users/me":
+		s.route(w, r, map[string]func(){http.MethodGet: func() { s.handleMe(w) }})
+	case strings.HasPrefix(path, "/v1/users/"):
+		s.route(w, r, map[string]func(){http.MethodGet: func() { s.handleUser(w, strings.TrimPrefix(path, "/v1/users/")) }})
+	case strings.HasPrefix(path, "/v1/payment/"):
+		s.handlePayment(w, r, strings.TrimPrefix(path, "/v1/payment/"), body)
+	case path == "/v1/user-dictionaries" || strings.HasPrefix(path, "/v1/user-dictionaries/"):
+		s.handleDictionaries(w, r, strings.TrimPrefix(strings.TrimPrefix(path, "/v1/user-dictionaries"), "/"), body)
+	default:
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+}
+
+// route calls the handler registered for the request method
+func (s *Server) route(w http.ResponseWriter, r *http.Request, handlers map[string]func()) {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		writeError(w, http.StatusMethodNotAllowed, "Method Not Allowed")
+		return
+	}
+	handler()
+}
+
+func (s *Server) delay() time.Duration {
+	delay := s.options.Latency
+	if s.options.LatencyJitter > 0 {
+		s.mu.Lock()
+		delay += time.Duration(s.random.Int63n(int64(s.options.LatencyJitter)))
+		s.mu.Unlock()
+	}
+	return delay
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || strings.TrimSpace(key) == "" {
+		return false
+	}
+	return s.options.APIKey == "" || key == s.options.APIKey
+}
+
+// allow counts the request against the rate limit window and sets the rate
+// limit headers, reporting whether the request is within the limit
+func (s *Server) allow(w http.ResponseWriter) bool {
+	if s.options.RateLimit <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.windowStart) >= time.Minute {
+		s.windowStart = now
+		s.windowCount = 0
+	}
+	s.windowCount++
+
+	remaining := max(s.options.RateLimit-s.windowCount, 0)
+	reset := int(time.Minute-now.Sub(s.windowStart)+time.Second-1) / int(time.Second)
+	w.Header().Set("X-Aivis-RateLimit-Requests-Limit", strconv.Itoa(s.options.RateLimit))
+	w.Header().Set("X-Aivis-RateLimit-Requests-Remaining", strconv.Itoa(remaining))
+	w.Header().Set("X-Aivis-RateLimit-Requests-Reset", strconv.Itoa(reset))
+	if s.windowCount > s.options.RateLimit {
+		w.Header().Set("Retry-After", strconv.Itoa(reset))
+		return false
+	}
+	return true
+}
+
+// injectedFailure returns the status of a queued or random failure for the
+// path, or 0
+func (s *Server) injectedFailure(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.failures {
+		f := &s.failures[i]
+		if f.remaining > 0 && strings.HasPrefix(path, f.pathPrefix) {
+			f.remaining--
+			return f.status
+		}
+	}
+	if s.options.ErrorRate > 0 && s.random.Float64() < s.options.ErrorRate {
+		return s.options.ErrorStatus
+	}
+	return 0
+}
+
+// newID returns a unique identifier with the given prefix
+func (s *Server) newID(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return fmt.Sprintf("%s_%06d", prefix, s.nextID)
+}
+
+// statusRecorder remembers the status written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError answers in the {"detail": "..."} form used by the API
+func writeError(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]string{"detail": detail})
+}
+
+// pageBounds returns the slice bounds for the limit and offset query
+// parameters over total items
+func pageBounds(r *http.Request, total, defaultLimit int) (start, end, limit, offset int) {
+	limit, offset = defaultLimit, 0
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = v
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && v > 0 {
+		offset = v
+	}
+	start = min(offset, total)
+	end = min(start+limit, total)
+	return start, end, limit, offset
+}
@@ -0,0 +1,310 @@
+package mockapi_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client"
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/common/errors"
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+	dictionariesDomain "github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	"github.com/kajidog/aivis-cloud-cli/client/testing/mockapi"
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+func newClient(t *testing.T, options mockapi.Options) (*client.Client, *mockapi.Server) {
+	t.Helper()
+	return newClientWithConfig(t, options, nil)
+}
+
+// newClientWithConfig is newClient with configure applied to the client configuration
+func newClientWithConfig(t *testing.T, options mockapi.Options, configure func(*config.Config)) (*client.Client, *mockapi.Server) {
+	t.Helper()
+	mock, server := mockapi.NewTestServer(t, options)
+
+	cfg := config.NewConfig("mock-key")
+	cfg.BaseURL = server.URL
+	cfg.RetryBaseDelay = time.Millisecond
+	cfg.BudgetPath = filepath.Join(t.TempDir(), "budget.json")
+	cfg.CreditStatePath = filepath.Join(t.TempDir(), "credits.json")
+	if configure != nil {
+		configure(cfg)
+	}
+	c, err := client.NewWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c, mock
+}
+
+func synthesize(t *testing.T, c *client.Client, request *ttsDomain.TTSRequest) (*audio.Buffer, *ttsDomain.TTSResponse) {
+	t.Helper()
+	response, err := c.Synthesize(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	defer response.AudioData.Close()
+
+	data, err := io.ReadAll(response.AudioData)
+	if err != nil {
+		t.Fatalf("failed to read audio: %v", err)
+	}
+	buffer, _, err := audio.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("failed to decode audio: %v", err)
+	}
+	return buffer, response
+}
+
+func TestSynthesize(t *testing.T) {
+	c, mock := newClient(t, mockapi.Options{Credits: 100, CreditsPerCharacter: 1, StreamChunkSize: 1024})
+
+	request := c.NewTTSRequest(mockapi.DefaultModelUUID, "こんにちは。").WithSpeakingRate(2.0).Build()
+	buffer, response := synthesize(t, c, request)
+
+	// 5 characters and a sentence pause at double speed, plus 100ms at each end
+	want := mockapi.DefaultEdgeSilence*2 + (5*mockapi.CharacterDuration+mockapi.SentencePause)/2
+	if got := buffer.Duration(); got < want-time.Millisecond || got > want+time.Millisecond {
+		t.Errorf("duration = %v, want %v", got, want)
+	}
+	if buffer.Format.SampleRate != 44100 || buffer.Format.Channels != 1 {
+		t.Errorf("unexpected format %v", buffer.Format)
+	}
+
+	billing := response.BillingInfo
+	if billing.CharacterCount != "6" || billing.CreditsUsed != "6" || billing.CreditsRemaining != "94" {
+		t.Errorf("unexpected billing info %+v", billing)
+	}
+	if mock.Credits() != 94 {
+		t.Errorf("credits = %v, want 94", mock.Credits())
+	}
+
+	flac := c.NewTTSRequest(mockapi.DefaultModelUUID, "あ").
+		WithOutputFormat(ttsDomain.OutputFormatFLAC).
+		WithOutputChannels(ttsDomain.AudioChannelsStereo).
+		WithOutputSamplingRate(24000).
+		Build()
+	buffer, _ = synthesize(t, c, flac)
+	if buffer.Format.SampleRate != 24000 || buffer.Format.Channels != 2 {
+		t.Errorf("unexpected FLAC format %v", buffer.Format)
+	}
+}
+
+func TestSynthesizeErrors(t *testing.T) {
+	c, mock := newClient(t, mockapi.Options{Credits: 3, CreditsPerCharacter: 1})
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		request *ttsDomain.TTSRequest
+		status  int
+	}{
+		"unknown model":      {c.NewTTSRequest("unknown", "あ").Build(), http.StatusNotFound},
+		"unknown style":      {c.NewTTSRequest(mockapi.DefaultModelUUID, "あ").WithStyleName("存在しない").Build(), http.StatusUnprocessableEntity},
+		"out of credits":     {c.NewTTSRequest(mockapi.DefaultModelUUID, "あいうえお").Build(), http.StatusPaymentRequired},
+		"unknown dictionary": {c.NewTTSRequest(mockapi.DefaultModelUUID, "あ").WithUserDictionary("unknown").Build(), http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		_, err := c.Synthesize(ctx, tt.request)
+		apiErr, ok := errors.IsAPIError(err)
+		if !ok || apiErr.StatusCode != tt.status {
+			t.Errorf("%s: got %v, want status %d", name, err, tt.status)
+		}
+	}
+	if mock.Credits() != 3 {
+		t.Errorf("failed requests should not be charged, credits = %v", mock.Credits())
+	}
+}
+
+func TestFailNext(t *testing.T) {
+	c, mock := newClient(t, mockapi.Options{})
+	ctx := context.Background()
+
+	// A transient failure is retried by the client
+	mock.FailNext("/v1/tts/synthesize", http.StatusServiceUnavailable, 1)
+	synthesize(t, c, c.NewTTSRequest(mockapi.DefaultModelUUID, "リトライ").Build())
+
+	mock.FailNext("/v1/users", http.StatusUnauthorized, 1)
+	if _, err := c.GetMe(ctx); err == nil {
+		t.Error("expected the injected error")
+	}
+	if _, err := c.GetMe(ctx); err != nil {
+		t.Errorf("expected the failure to be used up: %v", err)
+	}
+
+	var statuses []int
+	for _, request := range mock.Requests() {
+		statuses = append(statuses, request.Status)
+	}
+	want := []int{503, 200, 401, 200}
+	if len(statuses) != len(want) {
+		t.Fatalf("statuses = %v, want %v", statuses, want)
+	}
+	for i := range want {
+		if statuses[i] != want[i] {
+			t.Fatalf("statuses = %v, want %v", statuses, want)
+		}
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	c, _ := newClient(t, mockapi.Options{RateLimit: 2})
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetMe(ctx); err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+	}
+	if state := c.GetRateLimitState(); !state.Known || state.Limit != 2 {
+		t.Errorf("unexpected rate limit state %+v", state)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetMe(ctx); err == nil {
+		t.Error("expected the third request to be limited")
+	}
+}
+
+func TestModelsUsersAndPayment(t *testing.T) {
+	c, _ := newClient(t, mockapi.Options{Credits: 500, CreditsPerCharacter: 1})
+	ctx := context.Background()
+
+	search := c.NewModelSearchRequest().WithQuery("duo").Build()
+	models, err := c.SearchModels(ctx, search)
+	if err != nil || len(models.Models) != 1 || models.Models[0].UUID != mockapi.MultiSpeakerModelUUID {
+		t.Fatalf("SearchModels: %+v, %v", models, err)
+	}
+	speakers, err := c.GetModelSpeakers(ctx, mockapi.MultiSpeakerModelUUID)
+	if err != nil || len(speakers) != 2 {
+		t.Fatalf("GetModelSpeakers: %+v, %v", speakers, err)
+	}
+
+	synthesize(t, c, c.NewTTSRequest(mockapi.MultiSpeakerModelUUID, "テスト").WithSpeaker(speakers[1].UUID).Build())
+
+	me, err := c.GetMe(ctx)
+	if err != nil || me.Handle != mockapi.UserHandle || me.CreditBalance == nil || me.CreditBalance.Total != 497 {
+		t.Errorf("GetMe: %+v, %v", me, err)
+	}
+	if _, err := c.GetUserByHandle(ctx, "nobody"); err == nil {
+		t.Error("expected an unknown handle to fail")
+	}
+
+	transactions, err := c.GetCreditTransactions(ctx, paymentDomain.TransactionTypeDebit, "", nil, nil, 10, 0)
+	if err != nil || transactions.Total != 1 || transactions.Transactions[0].Amount != 3 {
+		t.Errorf("GetCreditTransactions: %+v, %v", transactions, err)
+	}
+	usage, err := c.GetUsageSummaries(ctx, "month", nil, nil, "")
+	if err != nil || usage.TTSRequests != 1 || usage.UsedCredits != 3 || len(usage.BreakdownByModel) != 1 {
+		t.Errorf("GetUsageSummaries: %+v, %v", usage, err)
+	}
+
+	key, err := c.CreateAPIKey(ctx, "ci")
+	if err != nil || !strings.HasPrefix(key.Key, "aivis_") {
+		t.Fatalf("CreateAPIKey: %+v, %v", key, err)
+	}
+	if err := c.DeleteAPIKey(ctx, key.ID); err != nil {
+		t.Errorf("DeleteAPIKey: %v", err)
+	}
+	keys, err := c.GetAPIKeys(ctx, 10, 0)
+	if err != nil || keys.Total != 1 {
+		t.Errorf("GetAPIKeys: %+v, %v", keys, err)
+	}
+}
+
+func TestLowCreditAlert(t *testing.T) {
+	c, _ := newClientWithConfig(t, mockapi.Options{Credits: 100, CreditsPerCharacter: 1}, func(cfg *config.Config) {
+		cfg.CreditAlertThresholds = []float64{90, 50}
+		cfg.CreditAlertNotifiers = nil
+	})
+	var alerts []*paymentDomain.CreditAlert
+	c.AddCreditNotifier(paymentDomain.CreditNotifierFunc(func(ctx context.Context, alert *paymentDomain.CreditAlert) error {
+		alerts = append(alerts, alert)
+		return nil
+	}))
+
+	for _, text := range []string{"あいうえお", "あいうえおかきくけこ", "あ", strings.Repeat("あ", 40)} {
+		synthesize(t, c, c.NewTTSRequest(mockapi.DefaultModelUUID, text).Build())
+	}
+
+	if err := c.FlushCreditAlerts(context.Background()); err != nil {
+		t.Fatalf("FlushCreditAlerts: %v", err)
+	}
+	// 95 left, then 85 crosses 90, 84 is still below it and 44 crosses 50
+	if len(alerts) != 2 {
+		t.Fatalf("got %d alerts, want 2", len(alerts))
+	}
+	if alerts[0].Threshold != 90 || alerts[0].Remaining != 85 || alerts[0].Previous == nil || *alerts[0].Previous != 95 {
+		t.Errorf("unexpected first alert %+v", alerts[0])
+	}
+	if alerts[1].Threshold != 50 || alerts[1].Remaining != 44 {
+		t.Errorf("unexpected second alert %+v", alerts[1])
+	}
+
+	last, err := c.GetLastObservedCredits(context.Background())
+	if err != nil || last == nil || last.Remaining != 44 {
+		t.Errorf("GetLastObservedCredits: %+v, %v", last, err)
+	}
+}
+
+func TestUserDictionaryChangesReading(t *testing.T) {
+	c, _ := newClient(t, mockapi.Options{})
+	ctx := context.Background()
+
+	dictionary, err := c.CreateUserDictionary(ctx, "names", "")
+	if err != nil {
+		t.Fatalf("CreateUserDictionary: %v", err)
+	}
+	if _, err := c.AddDictionaryWord(ctx, dictionary.UUID, dictionariesDomain.Word{Surface: "AI", Pronunciation: "エーアイ", AccentType: 1, Priority: 5}); err != nil {
+		t.Fatalf("AddDictionaryWord: %v", err)
+	}
+
+	plain, _ := synthesize(t, c, c.NewTTSRequest(mockapi.DefaultModelUUID, "AI").Build())
+	read, _ := synthesize(t, c, c.NewTTSRequest(mockapi.DefaultModelUUID, "AI").WithUserDictionary(dictionary.UUID).Build())
+	if diff := read.Duration() - plain.Duration(); diff != 2*mockapi.CharacterDuration {
+		t.Errorf("dictionary reading should add two characters, got %v", diff)
+	}
+}
+
+func TestPagers(t *testing.T) {
+	c, _ := newClient(t, mockapi.Options{})
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if _, err := c.CreateAPIKey(ctx, name); err != nil {
+			t.Fatalf("CreateAPIKey: %v", err)
+		}
+	}
+	keys, err := c.APIKeysPager(2).Collect(ctx)
+	if err != nil || len(keys) != 5 {
+		t.Errorf("APIKeysPager: %d keys, %v; want the fixture key and 4 created", len(keys), err)
+	}
+
+	all, err := c.SearchModels(ctx, c.NewModelSearchRequest().WithPageSize(100).Build())
+	if err != nil {
+		t.Fatalf("SearchModels: %v", err)
+	}
+	models, err := c.SearchModelsPager(c.NewModelSearchRequest().WithPageSize(1).Build()).Collect(ctx)
+	if err != nil || len(models) != len(all.Models) || len(models) < 2 {
+		t.Fatalf("SearchModelsPager: %d models, %v; want %d", len(models), err, len(all.Models))
+	}
+	for i := range models {
+		if models[i].UUID != all.Models[i].UUID {
+			t.Errorf("model %d = %s, want %s", i, models[i].UUID, all.Models[i].UUID)
+		}
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := c.SubscriptionsPager(0).Collect(cancelled); err != context.Canceled {
+		t.Errorf("SubscriptionsPager with a cancelled context: %v", err)
+	}
+}
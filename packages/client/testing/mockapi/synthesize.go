@@ -0,0 +1,326 @@
+package mockapi
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	dictionariesDomain "github.com/kajidog/aivis-cloud-cli/client/dictionaries/domain"
+	modelsDomain "github.com/kajidog/aivis-cloud-cli/client/models/domain"
+	paymentDomain "github.com/kajidog/aivis-cloud-cli/client/payment/domain"
+	ttsDomain "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	ttsUsecase "github.com/kajidog/aivis-cloud-cli/client/tts/usecase"
+)
+
+// Timing of the generated speech at speaking rate 1.0
+const (
+	CharacterDuration       = 120 * time.Millisecond
+	SentencePause           = 350 * time.Millisecond
+	CommaPause              = 150 * time.Millisecond
+	DefaultEdgeSilence      = 100 * time.Millisecond
+	DefaultLineBreakSilence = 400 * time.Millisecond
+
+	defaultSamplingRate = 44100
+	toneFrequency       = 220.0
+	toneAmplitude       = 0.3
+	toneFade            = 10 * time.Millisecond
+)
+
+var ssmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// synthesisRecord is a billed synthesis, for usage summaries
+type synthesisRecord struct {
+	ModelUUID string
+	Credits   float64
+	Duration  time.Duration
+	At        time.Time
+}
+
+// segment is a stretch of tone or silence
+type segment struct {
+	tone     bool
+	duration time.Duration
+}
+
+func (s *Server) handleSynthesize(w http.ResponseWriter, r *http.Request, body []byte) {
+	var request ttsDomain.TTSRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "Invalid request body")
+		return
+	}
+	if err := ttsUsecase.NewTTSSynthesizer(nil).ValidateRequest(&request); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	model := s.findModel(request.ModelUUID)
+	if model == nil {
+		writeError(w, http.StatusNotFound, "Specified model UUID not found")
+		return
+	}
+	if err := checkVoice(model, &request); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	text := request.Text
+	if request.UseSSML != nil && *request.UseSSML {
+		text = ssmlTagPattern.ReplaceAllString(text, "")
+	}
+	characters := len([]rune(text))
+
+	spoken := text
+	if request.UserDictionaryUUID != nil {
+		s.mu.Lock()
+		dictionary, ok := s.dictionaries[*request.UserDictionaryUUID]
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusNotFound, "User dictionary not found")
+			return
+		}
+		spoken = applyDictionary(spoken, dictionary.Words)
+	}
+
+	format := ttsDomain.OutputFormatWAV
+	if request.OutputFormat != nil {
+		format = *request.OutputFormat
+	}
+	buffer := renderSpeech(spoken, &request)
+	var encoded bytes.Buffer
+	var err error
+	if format == ttsDomain.OutputFormatFLAC {
+		err = buffer.WriteFLAC(&encoded)
+	} else {
+		// Lossy formats cannot be encoded without external codecs; their
+		// requests get WAV data, which players that probe the stream accept
+		err = buffer.WriteWAV(&encoded)
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Sprintf("failed to encode audio: %v", err))
+		return
+	}
+
+	credits := float64(characters) * s.options.CreditsPerCharacter
+	remaining, ok := s.charge(model.UUID, credits, buffer.Duration())
+	if !ok {
+		writeError(w, http.StatusPaymentRequired, "Credit balance is insufficient")
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", contentType(format))
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, header.Get("X-Request-ID"), format))
+	header.Set("X-Aivis-Billing-Mode", "PayAsYouGo")
+	header.Set("X-Aivis-Character-Count", strconv.Itoa(characters))
+	header.Set("X-Aivis-Credits-Used", strconv.FormatFloat(credits, 'f', -1, 64))
+	header.Set("X-Aivis-Credits-Remaining", strconv.FormatFloat(remaining, 'f', -1, 64))
+	w.WriteHeader(http.StatusOK)
+
+	s.stream(w, r, encoded.Bytes())
+}
+
+// checkVoice checks that the requested speaker and style exist in the model
+func checkVoice(model *modelsDomain.Model, request *ttsDomain.TTSRequest) error {
+	speaker := &model.Speakers[0]
+	if request.SpeakerUUID != nil {
+		speaker = nil
+		for i := range model.Speakers {
+			if model.Speakers[i].UUID == *request.SpeakerUUID {
+				speaker = &model.Speakers[i]
+			}
+		}
+		if speaker == nil {
+			return fmt.Errorf("speaker %s not found in model %s", *request.SpeakerUUID, model.UUID)
+		}
+	}
+
+	for _, style := range speaker.Styles {
+		if (request.StyleID == nil || style.LocalID == *request.StyleID) &&
+			(request.StyleName == nil || style.Name == *request.StyleName) {
+			return nil
+		}
+	}
+	if request.StyleID != nil {
+		return fmt.Errorf("style ID %d not found for speaker %s", *request.StyleID, speaker.Name)
+	}
+	return fmt.Errorf("style %q not found for speaker %s", *request.StyleName, speaker.Name)
+}
+
+// applyDictionary replaces surface forms with their pronunciation, higher
+// priorities and longer surfaces first
+func applyDictionary(text string, words []dictionariesDomain.Word) string {
+	words = append([]dictionariesDomain.Word(nil), words...)
+	sort.SliceStable(words, func(i, j int) bool {
+		if words[i].Priority != words[j].Priority {
+			return words[i].Priority > words[j].Priority
+		}
+		return len(words[i].Surface) > len(words[j].Surface)
+	})
+	for _, word := range words {
+		text = strings.ReplaceAll(text, word.Surface, word.Pronunciation)
+	}
+	return text
+}
+
+// charge debits credits for a synthesis, returning the remaining balance and
+// false when the balance is insufficient
+func (s *Server) charge(modelUUID string, credits float64, duration time.Duration) (float64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if credits > s.credits {
+		return s.credits, false
+	}
+	s.credits -= credits
+
+	now := time.Now().UTC()
+	s.nextID++
+	used := int64(math.Ceil(credits))
+	s.transactions = append(s.transactions, paymentDomain.CreditTransaction{
+		ID:          fmt.Sprintf("txn_%06d", s.nextID),
+		Type:        paymentDomain.TransactionTypeDebit,
+		Status:      paymentDomain.TransactionStatusCompleted,
+		Amount:      credits,
+		Currency:    "JPY",
+		Credits:     &used,
+		Description: "Speech synthesis",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	s.syntheses = append(s.syntheses, synthesisRecord{ModelUUID: modelUUID, Credits: credits, Duration: duration, At: now})
+	return s.credits, true
+}
+
+// stream writes data in chunks, flushing each so that clients receive the
+// audio progressively
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, data []byte) {
+	flusher, _ := w.(http.Flusher)
+	for len(data) > 0 {
+		n := min(s.options.StreamChunkSize, len(data))
+		if _, err := w.Write(data[:n]); err != nil {
+			return
+		}
+		data = data[n:]
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if len(data) > 0 && s.options.StreamChunkDelay > 0 {
+			select {
+			case <-time.After(s.options.StreamChunkDelay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
+
+func contentType(format ttsDomain.OutputFormat) string {
+	switch format {
+	case ttsDomain.OutputFormatFLAC:
+		return "audio/flac"
+	case ttsDomain.OutputFormatMP3:
+		return "audio/mpeg"
+	case ttsDomain.OutputFormatAAC:
+		return "audio/aac"
+	case ttsDomain.OutputFormatOpus:
+		return "audio/ogg"
+	default:
+		return "audio/wav"
+	}
+}
+
+// speechSegments splits text into tone for each character and pauses at
+// punctuation and line breaks, scaled by the speaking rate
+func speechSegments(text string, request *ttsDomain.TTSRequest) []segment {
+	rate := 1.0
+	if request.SpeakingRate != nil {
+		rate = *request.SpeakingRate
+	}
+	scale := func(d time.Duration) time.Duration { return time.Duration(float64(d) / rate) }
+	seconds := func(value *float64, fallback time.Duration) time.Duration {
+		if value == nil {
+			return fallback
+		}
+		return time.Duration(*value * float64(time.Second))
+	}
+
+	segments := []segment{{duration: seconds(request.LeadingSilenceSeconds, DefaultEdgeSilence)}}
+	add := func(tone bool, d time.Duration) {
+		last := &segments[len(segments)-1]
+		if last.tone == tone {
+			last.duration += d
+			return
+		}
+		segments = append(segments, segment{tone: tone, duration: d})
+	}
+	for _, r := range text {
+		switch {
+		case r == '\n':
+			add(false, seconds(request.LineBreakSilenceSeconds, DefaultLineBreakSilence))
+		case strings.ContainsRune("。．.！!？?", r):
+			add(false, scale(SentencePause))
+		case strings.ContainsRune("、，,", r):
+			add(false, scale(CommaPause))
+		case unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r):
+		default:
+			add(true, scale(CharacterDuration))
+		}
+	}
+	add(false, seconds(request.TrailingSilenceSeconds, DefaultEdgeSilence))
+	return segments
+}
+
+// renderSpeech generates 16-bit PCM for the text: a sine tone while
+// characters are spoken, silence for pauses. Pitch shifts the tone by up to
+// an octave and volume scales it.
+func renderSpeech(text string, request *ttsDomain.TTSRequest) *audio.Buffer {
+	format := audio.Format{SampleRate: defaultSamplingRate, Channels: 1, BitsPerSample: 16}
+	if request.OutputSamplingRate != nil {
+		format.SampleRate = *request.OutputSamplingRate
+	}
+	if request.OutputAudioChannels != nil && *request.OutputAudioChannels == ttsDomain.AudioChannelsStereo {
+		format.Channels = 2
+	}
+
+	frequency := toneFrequency
+	if request.Pitch != nil {
+		frequency *= math.Pow(2, *request.Pitch)
+	}
+	amplitude := toneAmplitude
+	if request.Volume != nil {
+		amplitude *= *request.Volume
+	}
+	amplitude = math.Min(amplitude, 1)
+
+	var data bytes.Buffer
+	sample := make([]byte, 2)
+	fadeFrames := int(toneFade * time.Duration(format.SampleRate) / time.Second)
+	phase := 0.0
+	for _, seg := range speechSegments(text, request) {
+		frames := int(seg.duration * time.Duration(format.SampleRate) / time.Second)
+		if !seg.tone {
+			data.Write(audio.Silence(format, seg.duration))
+			continue
+		}
+		for i := 0; i < frames; i++ {
+			envelope := math.Min(1, float64(min(i, frames-1-i))/float64(max(fadeFrames, 1)))
+			value := amplitude * envelope * math.Sin(phase)
+			phase += 2 * math.Pi * frequency / float64(format.SampleRate)
+			binary.LittleEndian.PutUint16(sample, uint16(int16(value*math.MaxInt16)))
+			for c := 0; c < format.Channels; c++ {
+				data.Write(sample)
+			}
+		}
+	}
+	return &audio.Buffer{Format: format, Data: data.Bytes()}
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// BudgetPeriod is a period over which credit spending is limited
+type BudgetPeriod string
+
+const (
+	// BudgetPeriodDaily resets at local midnight
+	BudgetPeriodDaily BudgetPeriod = "daily"
+	// BudgetPeriodMonthly resets on the first day of the local month
+	BudgetPeriodMonthly BudgetPeriod = "monthly"
+)
+
+// BudgetSpend is one synthesis recorded in the local spending ledger
+type BudgetSpend struct {
+	At        time.Time `json:"at"`
+	ModelUUID string    `json:"model_uuid,omitempty"`
+
+	// Characters and Credits are the values billed by the API
+	// (X-Aivis-Character-Count and X-Aivis-Credits-Used)
+	Characters int     `json:"characters"`
+	Credits    float64 `json:"credits"`
+
+	// Estimated is set when the response carried no billing headers and
+	// Credits holds the pre-flight estimate instead
+	Estimated bool `json:"estimated,omitempty"`
+}
+
+// BudgetReservation holds the estimated cost of a synthesis that passed the
+// budget check but is not billed yet, so that concurrent checks count it.
+// A reservation left behind by a process that died lapses at ExpiresAt.
+type BudgetReservation struct {
+	ID        string    `json:"id"`
+	At        time.Time `json:"at"`
+	Credits   float64   `json:"credits"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// BudgetLedger is the local spending ledger: billed syntheses and the
+// reservations of those in progress
+type BudgetLedger struct {
+	Spends       []BudgetSpend       `json:"spends"`
+	Reservations []BudgetReservation `json:"reservations,omitempty"`
+}
+
+// BudgetPeriodStatus is the spending of the current budget period
+type BudgetPeriodStatus struct {
+	Period BudgetPeriod `json:"period"`
+	// Limit is the configured budget in credits, 0 when unlimited
+	Limit float64 `json:"limit"`
+	Spent float64 `json:"spent"`
+	// Reserved is held by syntheses in progress
+	Reserved float64 `json:"reserved,omitempty"`
+	// Remaining is nil when the period is unlimited
+	Remaining *float64  `json:"remaining,omitempty"`
+	Requests  int       `json:"requests"`
+	Start     time.Time `json:"start"`
+	ResetsAt  time.Time `json:"resets_at"`
+}
+
+// Limited reports whether a budget is configured for the period
+func (s *BudgetPeriodStatus) Limited() bool {
+	return s.Limit > 0
+}
+
+// BudgetStatus describes local spending against the configured budgets
+type BudgetStatus struct {
+	Daily   BudgetPeriodStatus `json:"daily"`
+	Monthly BudgetPeriodStatus `json:"monthly"`
+
+	// CreditsPerCharacter is the rate used to estimate the cost of a request,
+	// calibrated from CalibrationSamples billed syntheses. Without samples it
+	// is DefaultCreditsPerCharacter.
+	CreditsPerCharacter float64 `json:"credits_per_character"`
+	CalibrationSamples  int     `json:"calibration_samples"`
+
+	LedgerPath string `json:"ledger_path,omitempty"`
+}
+
+// BudgetEstimate is the pre-flight cost estimate of a synthesis
+type BudgetEstimate struct {
+	Characters int     `json:"characters"`
+	Credits    float64 `json:"credits"`
+	// Exceeded lists the periods whose budget the synthesis would exceed
+	Exceeded []BudgetPeriodStatus `json:"exceeded,omitempty"`
+}
+
+// DefaultCreditsPerCharacter is the estimate used before any synthesis has
+// been billed. It errs on the high side so that an uncalibrated estimate
+// stops a runaway script early rather than late.
+const DefaultCreditsPerCharacter = 1.0
+
+// BudgetConfirmFunc is asked whether a synthesis that would exceed a budget
+// may proceed. Without one, such syntheses are refused.
+type BudgetConfirmFunc func(ctx context.Context, estimate *BudgetEstimate) bool
+
+// BudgetExceededError is returned when a synthesis is refused because it
+// would exceed a credit budget
+type BudgetExceededError struct {
+	Period    BudgetPeriod `json:"period"`
+	Limit     float64      `json:"limit"`
+	Spent     float64      `json:"spent"`
+	Reserved  float64      `json:"reserved,omitempty"`
+	Estimated float64      `json:"estimated"`
+	ResetsAt  time.Time    `json:"resets_at"`
+}
+
+func (e *BudgetExceededError) Error() string {
+	spent := fmt.Sprintf("%.2f of %.2f credits spent", e.Spent, e.Limit)
+	if e.Reserved > 0 {
+		spent += fmt.Sprintf(" (%.2f more reserved by syntheses in progress)", e.Reserved)
+	}
+	return fmt.Sprintf("%s credit budget exceeded: %s and the request needs about %.2f more (resets %s)",
+		e.Period, spent, e.Estimated, e.ResetsAt.Format("2006-01-02 15:04"))
+}
+
+// BudgetRepository stores the local spending ledger
+type BudgetRepository interface {
+	// Ledger returns the ledger, spends oldest first
+	Ledger(ctx context.Context) (*BudgetLedger, error)
+
+	// Update runs fn on the ledger while holding it exclusively, so that a
+	// check and the reservation it leads to are atomic across processes.
+	// The ledger is saved only when fn returns nil, and that error is
+	// returned otherwise.
+	Update(ctx context.Context, fn func(ledger *BudgetLedger) error) error
+
+	// Path returns where the ledger is stored
+	Path() string
+}
+
+type budgetApprovalKey struct{}
+
+// WithBudgetApproval marks syntheses made with the returned context as
+// already approved, so they are recorded but not checked against the budget.
+// It is used after a pre-flight check of a whole long text or script, and by
+// the playback daemon for requests checked by the forwarding process.
+func WithBudgetApproval(ctx context.Context) context.Context {
+	return context.WithValue(ctx, budgetApprovalKey{}, true)
+}
+
+// BudgetApproved reports whether ctx carries a budget approval
+func BudgetApproved(ctx context.Context) bool {
+	approved, _ := ctx.Value(budgetApprovalKey{}).(bool)
+	return approved
+}
+
+type budgetReservationKey struct{}
+
+// WithBudgetReservation attaches the ID of a budget reservation to ctx. Under
+// an approval, the syntheses made with ctx draw the reservation down and its
+// owner releases the rest once they are done. Otherwise the reservation was
+// made for the next synthesis made with ctx, which replaces it with the
+// billed credits or releases it when it fails. It carries the reservation of
+// a forwarded request to the playback daemon and onto the playback queue.
+func WithBudgetReservation(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, budgetReservationKey{}, id)
+}
+
+// BudgetReservationID returns the reservation ID ctx carries, or ""
+func BudgetReservationID(ctx context.Context) string {
+	id, _ := ctx.Value(budgetReservationKey{}).(string)
+	return id
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TTSCacheStats represents statistics about the local synthesis cache
+type TTSCacheStats struct {
+	Path        string     `json:"path"`
+	Entries     int        `json:"entries"`
+	TotalSize   int64      `json:"total_size"`
+	MaxSize     int64      `json:"max_size"`          // 0 means unbounded
+	MaxAge      string     `json:"max_age,omitempty"` // Empty means entries never expire
+	Hits        int64      `json:"hits"`
+	Misses      int64      `json:"misses"`
+	OldestEntry *time.Time `json:"oldest_entry,omitempty"`
+	NewestEntry *time.Time `json:"newest_entry,omitempty"`
+}
+
+// HitRate returns the share of lookups served from the cache
+func (s *TTSCacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// TTSCachePruneResult describes the entries removed by a prune
+type TTSCachePruneResult struct {
+	RemovedEntries int   `json:"removed_entries"`
+	FreedBytes     int64 `json:"freed_bytes"`
+}
+
+// TTSCache defines the maintenance operations of the local synthesis cache
+type TTSCache interface {
+	// Stats returns usage statistics
+	Stats(ctx context.Context) (*TTSCacheStats, error)
+
+	// Clear removes all cached audio
+	Clear(ctx context.Context) error
+
+	// Prune removes expired entries, then least recently used entries until the
+	// cache fits in maxSize bytes (0 keeps the configured limit)
+	Prune(ctx context.Context, maxSize int64) (*TTSCachePruneResult, error)
+
+	// ShareAudio replaces the file at filePath with a hard link to the cached audio
+	// for request, so history and cache do not store the same audio twice. It
+	// reports whether the file is now shared.
+	ShareAudio(ctx context.Context, request *TTSRequest, filePath string) (bool, error)
+}
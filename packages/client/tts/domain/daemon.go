@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// Playback daemon JSON-RPC methods
+const (
+	DaemonMethodPlay        = "play"
+	DaemonMethodPlayHistory = "play_history"
+	DaemonMethodStop        = "stop"
+	DaemonMethodPause       = "pause"
+	DaemonMethodResume      = "resume"
+	DaemonMethodSetVolume   = "set_volume"
+	DaemonMethodClearQueue  = "clear_queue"
+	DaemonMethodStatus      = "status"
+	DaemonMethodShutdown    = "shutdown"
+)
+
+// DaemonPlayParams are the parameters of the play method
+type DaemonPlayParams struct {
+	Request     *PlaybackRequest `json:"request"`
+	SaveHistory bool             `json:"save_history,omitempty"`
+	// LongText plays the request in chunks like PlayLongText when set
+	LongText *LongTextOptions `json:"long_text,omitempty"`
+	// BudgetApproved is set when the forwarding process has checked the
+	// request against its credit budget, so the daemon does not check again
+	BudgetApproved bool `json:"budget_approved,omitempty"`
+	// BudgetReservation is the reservation the forwarding process made for
+	// the request. The daemon bills the synthesis against it and, for an
+	// approval, releases what is left once the synthesis is done.
+	BudgetReservation string `json:"budget_reservation,omitempty"`
+	// HistoryLabels label the history records the daemon saves
+	HistoryLabels *HistoryLabels `json:"history_labels,omitempty"`
+}
+
+// DaemonPlayResult is the result of the play method. Exactly one of the
+// fields is set, depending on whether the request was played as long text.
+type DaemonPlayResult struct {
+	Response *TTSResponse    `json:"response,omitempty"`
+	LongText *LongTextResult `json:"long_text,omitempty"`
+}
+
+// DaemonPlayHistoryParams are the parameters of the play_history method
+type DaemonPlayHistoryParams struct {
+	ID      int              `json:"id"`
+	Options *PlaybackRequest `json:"options,omitempty"`
+}
+
+// DaemonVolumeParams are the parameters of the set_volume method
+type DaemonVolumeParams struct {
+	Volume float64 `json:"volume"`
+}
+
+// DaemonStatus describes a running playback daemon
+type DaemonStatus struct {
+	PID        int          `json:"pid"`
+	SocketPath string       `json:"socket_path"`
+	StartedAt  time.Time    `json:"started_at"`
+	Playback   PlaybackInfo `json:"playback"`
+}
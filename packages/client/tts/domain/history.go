@@ -28,6 +28,67 @@ type TTSHistory struct {
 	
 	// Optional billing info if available
 	Credits *float64 `json:"credits,omitempty"`
+	
+	// ID of the record this one was regenerated from, 0 if none
+	ParentID int `json:"parent_id,omitempty"`
+	
+	// Session shared by the lines of one rendered script, empty if none
+	ScriptSession string `json:"script_session,omitempty"`
+	
+	// Optional labels for usage reports (see HistoryLabels)
+	Project string   `json:"project,omitempty"`
+	Profile string   `json:"profile,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// HasTag reports whether the record is labeled with tag
+func (h *TTSHistory) HasTag(tag string) bool {
+	for _, t := range h.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// HistoryLabels label the history records of syntheses for usage reports:
+// the project they were made for, the voice profile used and free-form tags
+type HistoryLabels struct {
+	Project string   `json:"project,omitempty"`
+	Profile string   `json:"profile,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+// IsZero reports whether no label is set
+func (l *HistoryLabels) IsZero() bool {
+	return l == nil || (l.Project == "" && l.Profile == "" && len(l.Tags) == 0)
+}
+
+type historyLabelsKey struct{}
+
+// WithHistoryLabels labels the history records saved for syntheses made with
+// the returned context
+func WithHistoryLabels(ctx context.Context, labels *HistoryLabels) context.Context {
+	if labels.IsZero() {
+		return ctx
+	}
+	return context.WithValue(ctx, historyLabelsKey{}, labels)
+}
+
+// HistoryLabelsFrom returns the labels carried by ctx, or nil
+func HistoryLabelsFrom(ctx context.Context) *HistoryLabels {
+	labels, _ := ctx.Value(historyLabelsKey{}).(*HistoryLabels)
+	return labels
+}
+
+// Apply copies the labels onto a history record
+func (l *HistoryLabels) Apply(history *TTSHistory) {
+	if l.IsZero() {
+		return
+	}
+	history.Project = l.Project
+	history.Profile = l.Profile
+	history.Tags = append([]string(nil), l.Tags...)
 }
 
 // TTSHistorySearchRequest represents search criteria for TTS history
@@ -39,6 +100,9 @@ type TTSHistorySearchRequest struct {
 	// Filters
 	ModelUUID    *string    `json:"model_uuid,omitempty"`
 	TextContains *string    `json:"text_contains,omitempty"`
+	ScriptSession *string   `json:"script_session,omitempty"`
+	Project      *string    `json:"project,omitempty"`
+	Tag          *string    `json:"tag,omitempty"`
 	StartDate    *time.Time `json:"start_date,omitempty"`
 	EndDate      *time.Time `json:"end_date,omitempty"`
 	
@@ -158,6 +222,24 @@ func (b *TTSHistorySearchRequestBuilder) WithTextContains(text string) *TTSHisto
 	return b
 }
 
+// WithScriptSession filters by the script session the records were rendered in
+func (b *TTSHistorySearchRequestBuilder) WithScriptSession(session string) *TTSHistorySearchRequestBuilder {
+	b.request.ScriptSession = &session
+	return b
+}
+
+// WithProject filters by the project the records are labeled with
+func (b *TTSHistorySearchRequestBuilder) WithProject(project string) *TTSHistorySearchRequestBuilder {
+	b.request.Project = &project
+	return b
+}
+
+// WithTag filters to the records labeled with tag
+func (b *TTSHistorySearchRequestBuilder) WithTag(tag string) *TTSHistorySearchRequestBuilder {
+	b.request.Tag = &tag
+	return b
+}
+
 // WithDateRange sets the date range filter
 func (b *TTSHistorySearchRequestBuilder) WithDateRange(start, end time.Time) *TTSHistorySearchRequestBuilder {
 	b.request.StartDate = &start
@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+// HistoryArchiveVersion is the manifest version written by exports
+const HistoryArchiveVersion = 1
+
+// HistoryArchiveManifestName is the manifest's path inside an archive
+const HistoryArchiveManifestName = "manifest.json"
+
+// HistoryArchiveFormat represents the container format of a history archive
+type HistoryArchiveFormat string
+
+const (
+	HistoryArchiveFormatZip   HistoryArchiveFormat = "zip"
+	HistoryArchiveFormatTar   HistoryArchiveFormat = "tar"
+	HistoryArchiveFormatTarGz HistoryArchiveFormat = "tar.gz"
+)
+
+// TTSHistoryExportOptions selects the records written to a history archive.
+// Zero values select everything.
+type TTSHistoryExportOptions struct {
+	// Format of the archive; empty derives it from the file extension
+	Format HistoryArchiveFormat `json:"format,omitempty"`
+
+	// Inclusive ID range; 0 leaves a bound open
+	FromID int `json:"from_id,omitempty"`
+	ToID   int `json:"to_id,omitempty"`
+
+	ModelUUID *string    `json:"model_uuid,omitempty"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+	EndDate   *time.Time `json:"end_date,omitempty"`
+}
+
+// TTSHistoryManifest describes the records in a history archive. Records keep
+// their original TTSRequest so they can be replayed or regenerated elsewhere.
+type TTSHistoryManifest struct {
+	Version    int                        `json:"version"`
+	ExportedAt time.Time                  `json:"exported_at"`
+	Records    []*TTSHistoryArchiveRecord `json:"records"`
+}
+
+// TTSHistoryArchiveRecord is a history record as stored in an archive.
+// History.FilePath is cleared; AudioFile is the audio's path in the archive,
+// empty when the audio file no longer existed at export time.
+type TTSHistoryArchiveRecord struct {
+	History   *TTSHistory `json:"history"`
+	AudioFile string      `json:"audio_file,omitempty"`
+}
+
+// TTSHistoryExportResult summarizes an export
+type TTSHistoryExportResult struct {
+	Path    string `json:"path"`
+	Records int    `json:"records"`
+
+	// MissingAudio lists records exported without audio because the file was gone
+	MissingAudio []int `json:"missing_audio,omitempty"`
+}
+
+// TTSHistoryImportResult summarizes an import
+type TTSHistoryImportResult struct {
+	Imported int `json:"imported"`
+
+	// Duplicates counts records skipped because their InternalUUID already exists
+	Duplicates int `json:"duplicates"`
+
+	// IDMap maps IDs in the archive to the IDs assigned locally
+	IDMap map[int]int `json:"id_map"`
+}
@@ -0,0 +1,66 @@
+package domain
+
+// TTSRequestOverrides replaces parameters of a stored request when a history
+// record is regenerated. Nil fields keep the stored value.
+type TTSRequestOverrides struct {
+	ModelUUID   *string `json:"model_uuid,omitempty"`
+	SpeakerUUID *string `json:"speaker_uuid,omitempty"`
+	StyleID     *int    `json:"style_id,omitempty"`
+	StyleName   *string `json:"style_name,omitempty"`
+
+	SpeakingRate       *float64 `json:"speaking_rate,omitempty"`
+	EmotionalIntensity *float64 `json:"emotional_intensity,omitempty"`
+	TempoDynamics      *float64 `json:"tempo_dynamics,omitempty"`
+	Pitch              *float64 `json:"pitch,omitempty"`
+	Volume             *float64 `json:"volume,omitempty"`
+
+	OutputFormat *OutputFormat `json:"output_format,omitempty"`
+}
+
+// Apply returns a copy of request with the overrides applied; request itself
+// is not modified. Speakers and styles belong to a model, so switching the
+// model drops the stored speaker and style unless they are overridden too.
+// Setting a style ID clears the stored style name and vice versa.
+func (o *TTSRequestOverrides) Apply(request *TTSRequest) *TTSRequest {
+	result := *request
+	if o == nil {
+		return &result
+	}
+
+	if o.ModelUUID != nil && *o.ModelUUID != request.ModelUUID {
+		result.ModelUUID = *o.ModelUUID
+		result.SpeakerUUID = nil
+		result.StyleID = nil
+		result.StyleName = nil
+	}
+	if o.SpeakerUUID != nil {
+		result.SpeakerUUID = o.SpeakerUUID
+	}
+	if o.StyleID != nil {
+		result.StyleID = o.StyleID
+		result.StyleName = nil
+	}
+	if o.StyleName != nil {
+		result.StyleName = o.StyleName
+		result.StyleID = nil
+	}
+	if o.SpeakingRate != nil {
+		result.SpeakingRate = o.SpeakingRate
+	}
+	if o.EmotionalIntensity != nil {
+		result.EmotionalIntensity = o.EmotionalIntensity
+	}
+	if o.TempoDynamics != nil {
+		result.TempoDynamics = o.TempoDynamics
+	}
+	if o.Pitch != nil {
+		result.Pitch = o.Pitch
+	}
+	if o.Volume != nil {
+		result.Volume = o.Volume
+	}
+	if o.OutputFormat != nil {
+		result.OutputFormat = o.OutputFormat
+	}
+	return &result
+}
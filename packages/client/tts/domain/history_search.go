@@ -0,0 +1,152 @@
+package domain
+
+import "time"
+
+// DefaultFuzzyMinScore is the lowest score a fuzzy match may have to be returned
+const DefaultFuzzyMinScore = 0.5
+
+// TTSHistoryQuery represents a ranked full-text search over history text.
+// Text is normalized before matching (case, full-width ASCII and katakana are
+// folded) and compared using character n-grams, so Japanese text without
+// spaces is searchable. Whitespace separates terms that must all match.
+type TTSHistoryQuery struct {
+	// Query is the text to search for (required)
+	Query string `json:"query"`
+
+	// Fuzzy also accepts terms that only partially match, e.g. typos or
+	// missing characters, scored by n-gram overlap
+	Fuzzy bool `json:"fuzzy,omitempty"`
+
+	// MinScore drops results scoring below it (0..1). Fuzzy searches default
+	// to DefaultFuzzyMinScore.
+	MinScore float64 `json:"min_score,omitempty"`
+
+	// Pagination
+	Limit  int `json:"limit,omitempty"`
+	Offset int `json:"offset,omitempty"`
+
+	// Filters
+	ModelUUID   *string    `json:"model_uuid,omitempty"`
+	SpeakerUUID *string    `json:"speaker_uuid,omitempty"`
+	StyleID     *int       `json:"style_id,omitempty"`
+	StyleName   *string    `json:"style_name,omitempty"`
+	FileFormat  *string    `json:"file_format,omitempty"`
+	MinCredits  *float64   `json:"min_credits,omitempty"`
+	MaxCredits  *float64   `json:"max_credits,omitempty"`
+	MinFileSize *int64     `json:"min_file_size,omitempty"`
+	MaxFileSize *int64     `json:"max_file_size,omitempty"`
+	StartDate   *time.Time `json:"start_date,omitempty"`
+	EndDate     *time.Time `json:"end_date,omitempty"`
+}
+
+// TTSHistorySearchResult is a history record with its relevance score
+type TTSHistorySearchResult struct {
+	History *TTSHistory `json:"history"`
+
+	// Score is the relevance between 0 and 1; 1 means the text equals the query
+	Score float64 `json:"score"`
+}
+
+// TTSHistorySearchResponse represents ranked search results, best match first
+type TTSHistorySearchResponse struct {
+	Results []*TTSHistorySearchResult `json:"results"`
+	Total   int                       `json:"total"`
+	Limit   int                       `json:"limit"`
+	Offset  int                       `json:"offset"`
+	HasMore bool                      `json:"has_more"`
+}
+
+// NewTTSHistoryQuery creates a new history full-text query builder
+func NewTTSHistoryQuery(query string) *TTSHistoryQueryBuilder {
+	return &TTSHistoryQueryBuilder{
+		query: &TTSHistoryQuery{
+			Query: query,
+			Limit: 10, // Default limit
+		},
+	}
+}
+
+// TTSHistoryQueryBuilder helps build history full-text queries
+type TTSHistoryQueryBuilder struct {
+	query *TTSHistoryQuery
+}
+
+// WithFuzzy enables fuzzy matching
+func (b *TTSHistoryQueryBuilder) WithFuzzy(fuzzy bool) *TTSHistoryQueryBuilder {
+	b.query.Fuzzy = fuzzy
+	return b
+}
+
+// WithMinScore sets the lowest score to return
+func (b *TTSHistoryQueryBuilder) WithMinScore(score float64) *TTSHistoryQueryBuilder {
+	b.query.MinScore = score
+	return b
+}
+
+// WithLimit sets the maximum number of results to return
+func (b *TTSHistoryQueryBuilder) WithLimit(limit int) *TTSHistoryQueryBuilder {
+	b.query.Limit = limit
+	return b
+}
+
+// WithOffset sets the number of results to skip
+func (b *TTSHistoryQueryBuilder) WithOffset(offset int) *TTSHistoryQueryBuilder {
+	b.query.Offset = offset
+	return b
+}
+
+// WithModelUUID filters by model UUID
+func (b *TTSHistoryQueryBuilder) WithModelUUID(modelUUID string) *TTSHistoryQueryBuilder {
+	b.query.ModelUUID = &modelUUID
+	return b
+}
+
+// WithSpeakerUUID filters by speaker UUID
+func (b *TTSHistoryQueryBuilder) WithSpeakerUUID(speakerUUID string) *TTSHistoryQueryBuilder {
+	b.query.SpeakerUUID = &speakerUUID
+	return b
+}
+
+// WithStyleID filters by style ID
+func (b *TTSHistoryQueryBuilder) WithStyleID(styleID int) *TTSHistoryQueryBuilder {
+	b.query.StyleID = &styleID
+	return b
+}
+
+// WithStyleName filters by style name
+func (b *TTSHistoryQueryBuilder) WithStyleName(styleName string) *TTSHistoryQueryBuilder {
+	b.query.StyleName = &styleName
+	return b
+}
+
+// WithFileFormat filters by audio file format (wav, mp3, ...)
+func (b *TTSHistoryQueryBuilder) WithFileFormat(format string) *TTSHistoryQueryBuilder {
+	b.query.FileFormat = &format
+	return b
+}
+
+// WithCreditsRange filters by credits used; nil leaves a bound open
+func (b *TTSHistoryQueryBuilder) WithCreditsRange(min, max *float64) *TTSHistoryQueryBuilder {
+	b.query.MinCredits = min
+	b.query.MaxCredits = max
+	return b
+}
+
+// WithFileSizeRange filters by audio file size in bytes; nil leaves a bound open
+func (b *TTSHistoryQueryBuilder) WithFileSizeRange(min, max *int64) *TTSHistoryQueryBuilder {
+	b.query.MinFileSize = min
+	b.query.MaxFileSize = max
+	return b
+}
+
+// WithDateRange sets the date range filter
+func (b *TTSHistoryQueryBuilder) WithDateRange(start, end time.Time) *TTSHistoryQueryBuilder {
+	b.query.StartDate = &start
+	b.query.EndDate = &end
+	return b
+}
+
+// Build returns the constructed query
+func (b *TTSHistoryQueryBuilder) Build() *TTSHistoryQuery {
+	return b.query
+}
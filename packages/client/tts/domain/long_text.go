@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+)
+
+// MaxTextLength is the longest text accepted by a single synthesis request
+const MaxTextLength = 3000
+
+// LongTextOptions configures synthesis of text longer than MaxTextLength
+type LongTextOptions struct {
+	// MaxChunkLength is the maximum length of each chunk (default: MaxTextLength)
+	MaxChunkLength int `json:"max_chunk_length,omitempty"`
+
+	// Concurrency is the number of chunks synthesized in parallel (default: 3)
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// ChunkSilence is the silence inserted between consecutive chunks
+	ChunkSilence time.Duration `json:"chunk_silence,omitempty"`
+
+	// SentencePerChunk synthesizes every sentence separately instead of packing
+	// sentences up to MaxChunkLength, so that each subtitle cue is one sentence
+	SentencePerChunk bool `json:"sentence_per_chunk,omitempty"`
+
+	// OnProgress is called after each chunk has been written, in order
+	OnProgress func(completed, total int) `json:"-"`
+}
+
+// DefaultLongTextOptions returns the default long-text options
+func DefaultLongTextOptions() *LongTextOptions {
+	return &LongTextOptions{
+		MaxChunkLength: MaxTextLength,
+		Concurrency:    3,
+		ChunkSilence:   300 * time.Millisecond,
+	}
+}
+
+// LongTextResult describes the audio produced from a long text
+type LongTextResult struct {
+	Chunks      int               `json:"chunks"`
+	Format      audio.Format      `json:"format"`
+	Duration    time.Duration     `json:"duration"`
+	BillingInfo *http.BillingInfo `json:"billing_info,omitempty"` // Aggregated over all chunks
+	HistoryID   int               `json:"history_id,omitempty"`
+	Cues        []SubtitleCue     `json:"cues,omitempty"` // Timing of each chunk's text in the audio
+}
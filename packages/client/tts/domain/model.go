@@ -74,6 +74,7 @@ type TTSResponse struct {
     BillingInfo *http.BillingInfo `json:"billing_info,omitempty"`
     FileName    string            `json:"filename,omitempty"`
     HistoryID   int               `json:"history_id,omitempty"` // Sequential ID for history management
+    Cached      bool              `json:"cached,omitempty"`     // Served from the local synthesis cache
     // Playback characteristics (authoritative, computed by client)
     StreamingSynthesis bool          `json:"streaming_synthesis,omitempty"`
     StreamingPlayback  bool          `json:"streaming_playback,omitempty"`
@@ -4,6 +4,8 @@ import (
 	"context"
 	"io"
 	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
 )
 
 // PlaybackMode represents different audio playback strategies
@@ -36,6 +38,21 @@ type PlaybackRequest struct {
 	WaitForEnd   *bool         `json:"wait_for_end,omitempty"`  // Wait for playback completion
 }
 
+// Envelope returns the start offset and fades requested for playback
+func (r *PlaybackRequest) Envelope() audio.Envelope {
+	var envelope audio.Envelope
+	if r.StartOffset != nil {
+		envelope.StartOffset = *r.StartOffset
+	}
+	if r.FadeInDuration != nil {
+		envelope.FadeIn = *r.FadeInDuration
+	}
+	if r.FadeOutDuration != nil {
+		envelope.FadeOut = *r.FadeOutDuration
+	}
+	return envelope
+}
+
 // PlaybackConfig represents configuration for audio playback
 type PlaybackConfig struct {
 	DefaultMode     PlaybackMode  `json:"default_mode"`
@@ -93,6 +110,35 @@ type AudioPlayer interface {
 	Close() error
 }
 
+// AudioSink receives the PCM produced by an in-process player, such as a
+// sound device, a file or nothing at all
+type AudioSink interface {
+	// Open prepares the sink for PCM in the given format
+	Open(format audio.Format) error
+
+	// Write outputs interleaved little-endian PCM, blocking while the sink is full
+	Write(pcm []byte) (int, error)
+
+	// Drain blocks until everything written has been played
+	Drain() error
+
+	// Close releases the sink, discarding anything not yet played
+	Close() error
+}
+
+// EnvelopePlayer is implemented by players that can start part way into an
+// item and fade it in and out
+type EnvelopePlayer interface {
+	AudioPlayer
+
+	// PlayWithEnvelope starts playback like Play, shaped by envelope
+	PlayWithEnvelope(ctx context.Context, audioData io.Reader, format OutputFormat, envelope audio.Envelope) error
+
+	// Interrupt stops current playback, fading it out over the fade-out of
+	// its envelope when it has one
+	Interrupt() error
+}
+
 // PlaybackRequestBuilder helps build playback requests with method chaining
 type PlaybackRequestBuilder struct {
 	request *PlaybackRequest
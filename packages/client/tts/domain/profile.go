@@ -0,0 +1,134 @@
+package domain
+
+import "fmt"
+
+// VoiceProfile is a named set of request defaults: a voice (model, speaker
+// and style), its voice parameters, output format and user dictionary.
+// Every field is optional.
+type VoiceProfile struct {
+	ModelUUID   string `json:"model_uuid,omitempty" yaml:"model_uuid,omitempty" mapstructure:"model_uuid"`
+	SpeakerUUID string `json:"speaker_uuid,omitempty" yaml:"speaker_uuid,omitempty" mapstructure:"speaker_uuid"`
+	StyleID     *int   `json:"style_id,omitempty" yaml:"style_id,omitempty" mapstructure:"style_id"`
+	StyleName   string `json:"style_name,omitempty" yaml:"style_name,omitempty" mapstructure:"style_name"`
+
+	SpeakingRate       *float64 `json:"speaking_rate,omitempty" yaml:"speaking_rate,omitempty" mapstructure:"speaking_rate"`
+	EmotionalIntensity *float64 `json:"emotional_intensity,omitempty" yaml:"emotional_intensity,omitempty" mapstructure:"emotional_intensity"`
+	TempoDynamics      *float64 `json:"tempo_dynamics,omitempty" yaml:"tempo_dynamics,omitempty" mapstructure:"tempo_dynamics"`
+	Pitch              *float64 `json:"pitch,omitempty" yaml:"pitch,omitempty" mapstructure:"pitch"`
+	Volume             *float64 `json:"volume,omitempty" yaml:"volume,omitempty" mapstructure:"volume"`
+
+	OutputFormat       OutputFormat `json:"output_format,omitempty" yaml:"output_format,omitempty" mapstructure:"output_format"`
+	UserDictionaryUUID string       `json:"user_dictionary_uuid,omitempty" yaml:"user_dictionary_uuid,omitempty" mapstructure:"user_dictionary_uuid"`
+}
+
+// Validate checks the profile's values against the ranges the API accepts
+func (p *VoiceProfile) Validate() error {
+	if p.StyleID != nil && p.StyleName != "" {
+		return fmt.Errorf("style ID and style name cannot be specified simultaneously")
+	}
+	if p.StyleID != nil && (*p.StyleID < 0 || *p.StyleID > 31) {
+		return fmt.Errorf("style ID must be between 0 and 31")
+	}
+	for _, param := range []struct {
+		name     string
+		value    *float64
+		min, max float64
+	}{
+		{"speaking rate", p.SpeakingRate, 0.5, 2.0},
+		{"emotional intensity", p.EmotionalIntensity, 0.0, 2.0},
+		{"tempo dynamics", p.TempoDynamics, 0.0, 2.0},
+		{"pitch", p.Pitch, -1.0, 1.0},
+		{"volume", p.Volume, 0.0, 2.0},
+	} {
+		if param.value != nil && (*param.value < param.min || *param.value > param.max) {
+			return fmt.Errorf("%s must be between %.1f and %.1f", param.name, param.min, param.max)
+		}
+	}
+	switch p.OutputFormat {
+	case "", OutputFormatWAV, OutputFormatFLAC, OutputFormatMP3, OutputFormatAAC, OutputFormatOpus:
+	default:
+		return fmt.Errorf("unsupported output format %q (use wav, flac, mp3, aac or opus)", p.OutputFormat)
+	}
+	return nil
+}
+
+// Apply returns a copy of request with the profile filling every field the
+// request leaves unset; request itself is not modified. Speakers and styles
+// belong to a model, so they are only filled in when the request uses the
+// profile's model, and the style only when the request has none.
+func (p *VoiceProfile) Apply(request *TTSRequest) *TTSRequest {
+	result := *request
+	if p == nil {
+		return &result
+	}
+
+	if result.ModelUUID == "" {
+		result.ModelUUID = p.ModelUUID
+	}
+	if p.ModelUUID == "" || result.ModelUUID == p.ModelUUID {
+		if result.SpeakerUUID == nil && p.SpeakerUUID != "" {
+			speaker := p.SpeakerUUID
+			result.SpeakerUUID = &speaker
+		}
+		if result.StyleID == nil && result.StyleName == nil {
+			if p.StyleID != nil {
+				result.StyleID = p.StyleID
+			} else if p.StyleName != "" {
+				style := p.StyleName
+				result.StyleName = &style
+			}
+		}
+	}
+
+	if result.SpeakingRate == nil {
+		result.SpeakingRate = p.SpeakingRate
+	}
+	if result.EmotionalIntensity == nil {
+		result.EmotionalIntensity = p.EmotionalIntensity
+	}
+	if result.TempoDynamics == nil {
+		result.TempoDynamics = p.TempoDynamics
+	}
+	if result.Pitch == nil {
+		result.Pitch = p.Pitch
+	}
+	if result.Volume == nil {
+		result.Volume = p.Volume
+	}
+	if result.OutputFormat == nil && p.OutputFormat != "" {
+		format := p.OutputFormat
+		result.OutputFormat = &format
+	}
+	if result.UserDictionaryUUID == nil && p.UserDictionaryUUID != "" {
+		dictionary := p.UserDictionaryUUID
+		result.UserDictionaryUUID = &dictionary
+	}
+	return &result
+}
+
+// Overrides returns the voice of the profile as request overrides, e.g. to
+// use a profile as a script voice. The output format and user dictionary
+// are not included.
+func (p *VoiceProfile) Overrides() *TTSRequestOverrides {
+	overrides := &TTSRequestOverrides{
+		StyleID:            p.StyleID,
+		SpeakingRate:       p.SpeakingRate,
+		EmotionalIntensity: p.EmotionalIntensity,
+		TempoDynamics:      p.TempoDynamics,
+		Pitch:              p.Pitch,
+		Volume:             p.Volume,
+	}
+	if p.ModelUUID != "" {
+		model := p.ModelUUID
+		overrides.ModelUUID = &model
+	}
+	if p.SpeakerUUID != "" {
+		speaker := p.SpeakerUUID
+		overrides.SpeakerUUID = &speaker
+	}
+	if p.StyleID == nil && p.StyleName != "" {
+		style := p.StyleName
+		overrides.StyleName = &style
+	}
+	return overrides
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVoiceProfileApply(t *testing.T) {
+	style, rate, pitch := 3, 1.2, 0.2
+	profile := &VoiceProfile{
+		ModelUUID:          "model-a",
+		SpeakerUUID:        "speaker-a",
+		StyleID:            &style,
+		SpeakingRate:       &rate,
+		Pitch:              &pitch,
+		OutputFormat:       OutputFormatMP3,
+		UserDictionaryUUID: "dictionary",
+	}
+
+	got := profile.Apply(NewTTSRequestBuilder("", "text").WithPitch(-0.5).Build())
+	if got.ModelUUID != "model-a" || *got.SpeakerUUID != "speaker-a" || *got.StyleID != 3 || *got.SpeakingRate != 1.2 {
+		t.Errorf("Apply() = %+v, want the profile's voice", got)
+	}
+	if *got.Pitch != -0.5 {
+		t.Errorf("Pitch = %v, the request's own value must win", *got.Pitch)
+	}
+	if *got.OutputFormat != OutputFormatMP3 || *got.UserDictionaryUUID != "dictionary" {
+		t.Errorf("Apply() = %+v, want the profile's format and dictionary", got)
+	}
+
+	// Speakers and styles of another model are not carried over
+	other := profile.Apply(NewTTSRequestBuilder("model-b", "text").Build())
+	if other.ModelUUID != "model-b" || other.SpeakerUUID != nil || other.StyleID != nil || *other.SpeakingRate != 1.2 {
+		t.Errorf("Apply() to another model = %+v", other)
+	}
+
+	// A style given by name keeps the profile's style ID out
+	named := profile.Apply(NewTTSRequestBuilder("model-a", "text").WithStyleName("Happy").Build())
+	if named.StyleID != nil || *named.StyleName != "Happy" {
+		t.Errorf("Apply() with a style name = %+v", named)
+	}
+
+	if copied := (*VoiceProfile)(nil).Apply(got); copied == got || copied.Text != "text" {
+		t.Error("a nil profile must return a copy")
+	}
+
+	overrides := profile.Overrides()
+	if *overrides.ModelUUID != "model-a" || *overrides.StyleID != 3 || overrides.OutputFormat != nil {
+		t.Errorf("Overrides() = %+v", overrides)
+	}
+}
+
+func TestVoiceProfileValidate(t *testing.T) {
+	style, high, low := 40, 2.5, -2.0
+	for _, tt := range []struct {
+		profile VoiceProfile
+		want    string
+	}{
+		{VoiceProfile{StyleID: &style}, "style ID must be between 0 and 31"},
+		{VoiceProfile{SpeakingRate: &high}, "speaking rate must be between 0.5 and 2.0"},
+		{VoiceProfile{Pitch: &low}, "pitch must be between -1.0 and 1.0"},
+		{VoiceProfile{OutputFormat: "ogg"}, `unsupported output format "ogg"`},
+	} {
+		if err := tt.profile.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%+v) error = %v, want %q", tt.profile, err, tt.want)
+		}
+	}
+
+	rate := 1.5
+	if err := (&VoiceProfile{ModelUUID: "m", StyleName: "Happy", SpeakingRate: &rate}).Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+)
+
+// Script is a dialogue between voices, rendered into a single audio track.
+// The text format is line based:
+//
+//	# comment
+//	@voice alice model=<model-uuid> speaker=<speaker-uuid> style=0
+//	@voice bob model=<model-uuid> style=Happy rate=1.1
+//	[alice] おはよう！
+//	[bob pitch=0.1] おはよう。今日もいい天気だね。
+//	@pause 1.5s
+//
+// @voice defines an alias; [alias key=value ...] speaks a line with optional
+// per-line overrides; @pause inserts silence instead of the usual gap.
+type Script struct {
+	Voices map[string]*TTSRequestOverrides `json:"voices,omitempty"`
+	Lines  []ScriptLine                    `json:"lines"`
+}
+
+// ScriptLine is one entry of a script: either a spoken line or a pause
+type ScriptLine struct {
+	// Number is the line number in the script source
+	Number int `json:"number"`
+
+	// Voice is the alias of the speaking voice, empty for pauses
+	Voice     string               `json:"voice,omitempty"`
+	Text      string               `json:"text,omitempty"`
+	Overrides *TTSRequestOverrides `json:"overrides,omitempty"`
+
+	// Pause is the silence inserted by a pause directive
+	Pause time.Duration `json:"pause,omitempty"`
+}
+
+// IsPause reports whether the line is a pause directive
+func (l *ScriptLine) IsPause() bool {
+	return l.Voice == ""
+}
+
+// ScriptOptions configures script rendering
+type ScriptOptions struct {
+	// Gap is the silence between consecutive lines that have no pause directive between them
+	Gap time.Duration `json:"gap,omitempty"`
+
+	// Concurrency is the number of lines synthesized in parallel (default: 3)
+	Concurrency int `json:"concurrency,omitempty"`
+
+	// OutputFormat is the format of the assembled track, wav or flac (default: wav)
+	OutputFormat OutputFormat `json:"output_format,omitempty"`
+
+	// ResolveVoice looks up aliases that the script does not define itself
+	ResolveVoice func(alias string) (*TTSRequestOverrides, error) `json:"-"`
+
+	// OnProgress is called after each line has been assembled, in order
+	OnProgress func(completed, total int) `json:"-"`
+}
+
+// DefaultScriptOptions returns the default script options
+func DefaultScriptOptions() *ScriptOptions {
+	return &ScriptOptions{
+		Gap:          300 * time.Millisecond,
+		Concurrency:  3,
+		OutputFormat: OutputFormatWAV,
+	}
+}
+
+// ScriptLineResult describes where a spoken line ended up in the assembled track
+type ScriptLineResult struct {
+	Number    int           `json:"number"` // Line number in the script source
+	Voice     string        `json:"voice"`
+	Text      string        `json:"text"`
+	Start     time.Duration `json:"start"`
+	End       time.Duration `json:"end"`
+	Request   *TTSRequest   `json:"request"`
+	HistoryID int           `json:"history_id,omitempty"`
+
+	BillingInfo *http.BillingInfo `json:"billing_info,omitempty"`
+
+	// Audio is the synthesized line before it was converted to the track format
+	Audio *audio.Buffer `json:"-"`
+}
+
+// ScriptResult is the manifest of a rendered script
+type ScriptResult struct {
+	Session     string              `json:"session,omitempty"` // History script session of the lines
+	Format      audio.Format        `json:"format"`
+	Duration    time.Duration       `json:"duration"`
+	Lines       []*ScriptLineResult `json:"lines"`
+	BillingInfo *http.BillingInfo   `json:"billing_info,omitempty"` // Aggregated over all lines
+}
@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SubtitleCue is the time span during which a piece of text is spoken
+type SubtitleCue struct {
+	Index int           `json:"index"` // 1-based position of the cue
+	Start time.Duration `json:"start"`
+	End   time.Duration `json:"end"`
+	Text  string        `json:"text"`
+}
+
+// SubtitleFormat represents a subtitle file format
+type SubtitleFormat string
+
+const (
+	SubtitleFormatSRT SubtitleFormat = "srt"
+	SubtitleFormatVTT SubtitleFormat = "vtt"
+)
+
+// GetSubtitleFormatFromFilePath determines the subtitle format from the file extension
+func GetSubtitleFormatFromFilePath(filePath string) (SubtitleFormat, error) {
+	switch ext := strings.ToLower(filepath.Ext(filePath)); ext {
+	case ".srt":
+		return SubtitleFormatSRT, nil
+	case ".vtt":
+		return SubtitleFormatVTT, nil
+	default:
+		return "", fmt.Errorf("unsupported subtitle file extension %q (use .srt or .vtt)", ext)
+	}
+}
@@ -0,0 +1,78 @@
+package domain
+
+import "time"
+
+// UsageGroupBy selects how a usage report groups history records
+type UsageGroupBy string
+
+const (
+	UsageGroupByModel   UsageGroupBy = "model"
+	UsageGroupByDay     UsageGroupBy = "day"
+	UsageGroupByProfile UsageGroupBy = "profile"
+	UsageGroupByProject UsageGroupBy = "project"
+	// UsageGroupByTag counts a record once in the group of each of its tags
+	UsageGroupByTag UsageGroupBy = "tag"
+)
+
+// UsageGroupUnlabeled is the group of records without the grouped label
+const UsageGroupUnlabeled = "(none)"
+
+// UsageReportOptions select the records and the grouping of a usage report
+type UsageReportOptions struct {
+	// Start and End bound the creation time of the records, inclusive
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	GroupBy UsageGroupBy `json:"group_by"`
+
+	// Project and Tag restrict the report to labeled records, when set
+	Project string `json:"project,omitempty"`
+	Tag     string `json:"tag,omitempty"`
+}
+
+// UsageReportRow is the usage of one group
+type UsageReportRow struct {
+	Group string `json:"group"`
+	// ModelName names the model of a model group, when known
+	ModelName string `json:"model_name,omitempty"`
+
+	// Local history records of the group
+	Requests   int     `json:"requests"`
+	Characters int     `json:"characters"`
+	Credits    float64 `json:"credits"`
+	// UnbilledRequests counts records without billing information, whose
+	// credits are missing from Credits
+	UnbilledRequests int `json:"unbilled_requests,omitempty"`
+
+	// Usage reported by the server for a model group, when joined
+	ServerRequests *int64   `json:"server_requests,omitempty"`
+	ServerCredits  *float64 `json:"server_credits,omitempty"`
+}
+
+// ServerUsage is the usage the server reports for the period of a report
+type ServerUsage struct {
+	Requests int64              `json:"requests"`
+	Credits  float64            `json:"credits"`
+	Models   []ServerModelUsage `json:"models,omitempty"`
+}
+
+// ServerModelUsage is the usage the server reports for one model
+type ServerModelUsage struct {
+	ModelUUID string  `json:"model_uuid"`
+	ModelName string  `json:"model_name,omitempty"`
+	Requests  int64   `json:"requests"`
+	Credits   float64 `json:"credits"`
+}
+
+// UsageReport aggregates local history, and optionally server usage, over a
+// period
+type UsageReport struct {
+	UsageReportOptions
+	Rows []UsageReportRow `json:"rows"`
+	// Total counts every record once, whatever the grouping
+	Total UsageReportRow `json:"total"`
+	// Server is the account-wide usage reported by the server, nil when it
+	// could not be fetched. Its per-model numbers are joined into the rows of
+	// a model report without a project or tag filter.
+	Server *ServerUsage `json:"server,omitempty"`
+}
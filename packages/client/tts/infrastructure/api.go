@@ -26,6 +26,7 @@ func (r *TTSAPIRepository) Synthesize(ctx context.Context, request *domain.TTSRe
 		Method: "POST",
 		Path:   "/v1/tts/synthesize",
 		Body:   request,
+		Retry:  true, // Safe until the audio stream is handed to the caller
 	}
 
 	resp, err := r.httpClient.DoStream(ctx, httpReq)
@@ -49,6 +50,7 @@ func (r *TTSAPIRepository) SynthesizeStream(ctx context.Context, request *domain
 		Method: "POST",
 		Path:   "/v1/tts/synthesize",
 		Body:   request,
+		Retry:  true, // Safe until the audio stream is handed to the caller
 	}
 
 	resp, err := r.httpClient.DoStream(ctx, httpReq)
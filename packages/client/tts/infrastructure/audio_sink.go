@@ -0,0 +1,255 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"sync"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+)
+
+// NullSink discards PCM. The native player still paces playback in real
+// time, so position, pause and volume behave as with a sound device.
+type NullSink struct {
+	mu      sync.Mutex
+	written int64
+}
+
+// NewNullSink creates a sink that discards its input
+func NewNullSink() *NullSink {
+	return &NullSink{}
+}
+
+// Open resets the byte count for a new playback
+func (s *NullSink) Open(format audio.Format) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written = 0
+	return nil
+}
+
+// Write discards pcm
+func (s *NullSink) Write(pcm []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.written += int64(len(pcm))
+	return len(pcm), nil
+}
+
+// Drain returns immediately
+func (s *NullSink) Drain() error {
+	return nil
+}
+
+// Close does nothing
+func (s *NullSink) Close() error {
+	return nil
+}
+
+// Written returns the number of PCM bytes written since the last Open
+func (s *NullSink) Written() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.written
+}
+
+// FileSink writes the PCM of each playback to a WAV file, replacing the
+// previous playback's file
+type FileSink struct {
+	path   string
+	mu     sync.Mutex
+	file   *os.File
+	format audio.Format
+	size   int64
+}
+
+// NewFileSink creates a sink that records playback to the WAV file at path
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+// Open creates the WAV file and writes a provisional header
+func (s *FileSink) Open(format audio.Format) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, err := os.Create(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to create audio file: %w", err)
+	}
+	if err := audio.WriteStreamingWAVHeader(file, format); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write WAV header: %w", err)
+	}
+	s.file = file
+	s.format = format
+	s.size = 0
+	return nil
+}
+
+// Write appends pcm to the WAV data chunk
+func (s *FileSink) Write(pcm []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return 0, os.ErrClosed
+	}
+	n, err := s.file.Write(pcm)
+	s.size += int64(n)
+	return n, err
+}
+
+// Drain returns immediately; written data is already in the file
+func (s *FileSink) Drain() error {
+	return nil
+}
+
+// Close fills in the final sizes in the WAV header and closes the file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	file := s.file
+	s.file = nil
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to finalize WAV header: %w", err)
+	}
+	if err := audio.WriteWAVHeader(file, s.format, uint32(s.size)); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to finalize WAV header: %w", err)
+	}
+	return file.Close()
+}
+
+// CommandSink streams raw PCM to the standard input of an OS audio command
+// (pacat, aplay, SoX play or ffplay, whichever is found first)
+type CommandSink struct {
+	mu      sync.Mutex
+	stdin   io.WriteCloser
+	process *os.Process
+	exited  chan struct{}
+	waitErr error
+}
+
+// NewCommandSink creates a sink that plays through an OS audio command
+func NewCommandSink() *CommandSink {
+	return &CommandSink{}
+}
+
+// rawPCMCommand returns a command line that plays raw little-endian PCM in the
+// given format from standard input
+func rawPCMCommand(format audio.Format) (string, []string, error) {
+	rate := strconv.Itoa(format.SampleRate)
+	channels := strconv.Itoa(format.Channels)
+	bits := strconv.Itoa(format.BitsPerSample)
+
+	candidates := []string{"ffplay"}
+	switch runtime.GOOS {
+	case "linux":
+		candidates = []string{"pacat", "aplay", "play", "ffplay"}
+	case "darwin":
+		candidates = []string{"play", "ffplay"}
+	}
+	for _, name := range candidates {
+		if _, err := exec.LookPath(name); err != nil {
+			continue
+		}
+		switch name {
+		case "pacat":
+			return name, []string{"--raw", "--format=s" + bits + "le", "--rate=" + rate, "--channels=" + channels}, nil
+		case "aplay":
+			sampleFormat := "S16_LE"
+			if format.BitsPerSample == 24 {
+				sampleFormat = "S24_3LE"
+			}
+			return name, []string{"-q", "-t", "raw", "-f", sampleFormat, "-r", rate, "-c", channels}, nil
+		case "play":
+			return name, []string{"-q", "-t", "raw", "-e", "signed-integer", "-L", "-b", bits, "-r", rate, "-c", channels, "-"}, nil
+		case "ffplay":
+			return name, []string{"-loglevel", "error", "-nodisp", "-autoexit", "-f", "s" + bits + "le", "-ar", rate, "-ac", channels, "-i", "-"}, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no raw PCM audio output command found (tried %v)", candidates)
+}
+
+// Open starts the audio command for the given format
+func (s *CommandSink) Open(format audio.Format) error {
+	command, args, err := rawPCMCommand(format)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(command, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open audio command input: %w", err)
+	}
+	// Keep stdout clean for MCP stdio mode
+	cmd.Stdout = nil
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start %s: %w", command, err)
+	}
+
+	exited := make(chan struct{})
+	s.mu.Lock()
+	s.stdin = stdin
+	s.process = cmd.Process
+	s.exited = exited
+	s.waitErr = nil
+	s.mu.Unlock()
+	go func() {
+		err := cmd.Wait()
+		s.mu.Lock()
+		s.waitErr = err
+		s.mu.Unlock()
+		close(exited)
+	}()
+	return nil
+}
+
+// Write sends pcm to the audio command, blocking while its pipe is full
+func (s *CommandSink) Write(pcm []byte) (int, error) {
+	s.mu.Lock()
+	stdin := s.stdin
+	s.mu.Unlock()
+	if stdin == nil {
+		return 0, os.ErrClosed
+	}
+	return stdin.Write(pcm)
+}
+
+// Drain closes the command's input and waits for it to finish playing
+func (s *CommandSink) Drain() error {
+	s.mu.Lock()
+	stdin, exited := s.stdin, s.exited
+	s.mu.Unlock()
+	if stdin == nil {
+		return nil
+	}
+	stdin.Close()
+	<-exited
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waitErr
+}
+
+// Close stops the audio command at once
+func (s *CommandSink) Close() error {
+	s.mu.Lock()
+	stdin, process, exited := s.stdin, s.process, s.exited
+	s.stdin, s.process = nil, nil
+	s.mu.Unlock()
+	if stdin == nil {
+		return nil
+	}
+	stdin.Close()
+	process.Kill()
+	<-exited
+	return nil
+}
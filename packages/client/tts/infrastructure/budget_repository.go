@@ -0,0 +1,118 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// budgetRetention is how long spends are kept in the ledger: long enough to
+// cover the current month and to calibrate estimates
+const budgetRetention = 62 * 24 * time.Hour
+
+// FileBudgetRepository implements BudgetRepository with a JSON file. Writes
+// run under an advisory lock so that concurrent processes (e.g. a script and
+// the MCP server) add to the same ledger.
+type FileBudgetRepository struct {
+	path string
+	now  func() time.Time
+}
+
+// NewFileBudgetRepository creates a ledger stored at path
+func NewFileBudgetRepository(path string) *FileBudgetRepository {
+	return &FileBudgetRepository{path: path, now: time.Now}
+}
+
+// Path returns the ledger file path
+func (r *FileBudgetRepository) Path() string {
+	return r.path
+}
+
+// Ledger returns the ledger, spends oldest first
+func (r *FileBudgetRepository) Ledger(ctx context.Context) (*domain.BudgetLedger, error) {
+	var ledger *domain.BudgetLedger
+	err := r.withLock(false, func() (err error) {
+		ledger, err = r.load()
+		return err
+	})
+	return ledger, err
+}
+
+// Update runs fn on the ledger under the exclusive lock and saves it when fn
+// succeeds. Spends older than the retention period and lapsed reservations
+// are dropped.
+func (r *FileBudgetRepository) Update(ctx context.Context, fn func(ledger *domain.BudgetLedger) error) error {
+	return r.withLock(true, func() error {
+		ledger, err := r.load()
+		if err != nil {
+			return err
+		}
+		if err := fn(ledger); err != nil {
+			return err
+		}
+
+		now := r.now()
+		cutoff := now.Add(-budgetRetention)
+		spends := ledger.Spends[:0]
+		for _, s := range ledger.Spends {
+			if !s.At.Before(cutoff) {
+				spends = append(spends, s)
+			}
+		}
+		ledger.Spends = spends
+		reservations := ledger.Reservations[:0]
+		for _, reservation := range ledger.Reservations {
+			if reservation.ExpiresAt.After(now) {
+				reservations = append(reservations, reservation)
+			}
+		}
+		ledger.Reservations = reservations
+		return r.save(ledger)
+	})
+}
+
+func (r *FileBudgetRepository) withLock(exclusive bool, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(r.path+".lock", exclusive)
+	if err != nil {
+		return fmt.Errorf("failed to lock budget ledger: %w", err)
+	}
+	defer unlock()
+	return fn()
+}
+
+// load reads the ledger; a missing file is an empty ledger
+func (r *FileBudgetRepository) load() (*domain.BudgetLedger, error) {
+	ledger := &domain.BudgetLedger{}
+	data, err := os.ReadFile(r.path)
+	if os.IsNotExist(err) {
+		return ledger, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget ledger: %w", err)
+	}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, fmt.Errorf("budget ledger %s is corrupt: %w", r.path, err)
+	}
+	return ledger, nil
+}
+
+// save writes the ledger atomically
+func (r *FileBudgetRepository) save(ledger *domain.BudgetLedger) error {
+	data, err := json.MarshalIndent(ledger, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write budget ledger: %w", err)
+	}
+	return os.Rename(tmp, r.path)
+}
@@ -0,0 +1,602 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// cacheKeyVersion is mixed into every key so a change to the canonical form
+// invalidates old entries instead of serving mismatched audio
+const cacheKeyVersion = "tts-cache-v1"
+
+// CacheKey returns the content address of a request: the SHA-256 of its
+// canonical JSON form, which covers the model, speaker, style, text and every
+// voice and output parameter
+func CacheKey(request *domain.TTSRequest) (string, error) {
+	canonical := *request
+	// Japanese is the only language the API accepts, so an unset language is equivalent
+	if canonical.Language == nil {
+		language := domain.LanguageJapanese
+		canonical.Language = &language
+	}
+
+	data, err := json.Marshal(&canonical)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(cacheKeyVersion+"\n"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// staleTempAge is how long a temporary download file may go unmodified
+// before Prune treats it as left behind by an interrupted process. Files of
+// downloads in progress, in this process or another, are written to
+// continuously and stay younger.
+const staleTempAge = time.Hour
+
+// errCorruptIndex is returned when index.json cannot be parsed
+var errCorruptIndex = errors.New("cache index is corrupt")
+
+// cacheEntry describes one cached audio blob
+type cacheEntry struct {
+	File           string    `json:"file"` // Relative to the blobs directory
+	Size           int64     `json:"size"`
+	SHA256         string    `json:"sha256,omitempty"` // Of the blob; empty in entries of older versions
+	FileName       string    `json:"filename,omitempty"`
+	ModelUUID      string    `json:"model_uuid"`
+	CreatedAt      time.Time `json:"created_at"`
+	LastAccessedAt time.Time `json:"last_accessed_at"`
+	Hits           int64     `json:"hits"`
+}
+
+// cacheIndex is the on-disk index of the cache
+type cacheIndex struct {
+	Hits    int64                  `json:"hits"`
+	Misses  int64                  `json:"misses"`
+	Entries map[string]*cacheEntry `json:"entries"`
+}
+
+// CachingTTSRepository serves repeated synthesis requests from disk and
+// forwards everything else to the wrapped repository. The index is only
+// read and written under an exclusive lock on index.lock, so processes
+// sharing the cache do not lose each other's updates.
+type CachingTTSRepository struct {
+	next     domain.TTSRepository
+	basePath string
+	maxSize  int64
+	maxAge   time.Duration
+	now      func() time.Time
+	mu       sync.Mutex
+}
+
+// NewCachingTTSRepository creates a cache in basePath in front of next.
+// maxSize bounds the total size of cached audio in bytes and maxAge the age of
+// an entry; zero disables the respective limit.
+func NewCachingTTSRepository(next domain.TTSRepository, basePath string, maxSize int64, maxAge time.Duration) *CachingTTSRepository {
+	return &CachingTTSRepository{
+		next:     next,
+		basePath: basePath,
+		maxSize:  maxSize,
+		maxAge:   maxAge,
+		now:      time.Now,
+	}
+}
+
+// Synthesize returns cached audio for a previously seen request, or performs the
+// synthesis and caches the audio once it has been read completely
+func (r *CachingTTSRepository) Synthesize(ctx context.Context, request *domain.TTSRequest) (*domain.TTSResponse, error) {
+	key, err := CacheKey(request)
+	if err != nil {
+		return r.next.Synthesize(ctx, request)
+	}
+
+	if response := r.lookup(key); response != nil {
+		return response, nil
+	}
+
+	response, err := r.next.Synthesize(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	response.AudioData = r.record(key, request, response.FileName, response.AudioData)
+	return response, nil
+}
+
+// SynthesizeStream returns cached audio for a previously seen request, or streams
+// the synthesis and caches the audio once the stream has been read completely
+func (r *CachingTTSRepository) SynthesizeStream(ctx context.Context, request *domain.TTSRequest) (io.ReadCloser, error) {
+	key, err := CacheKey(request)
+	if err != nil {
+		return r.next.SynthesizeStream(ctx, request)
+	}
+
+	if response := r.lookup(key); response != nil {
+		return response.AudioData, nil
+	}
+
+	stream, err := r.next.SynthesizeStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return r.record(key, request, "", stream), nil
+}
+
+// Stats returns usage statistics of the cache
+func (r *CachingTTSRepository) Stats(ctx context.Context) (*domain.TTSCacheStats, error) {
+	var index *cacheIndex
+	err := r.withIndex(func(loaded *cacheIndex) error {
+		index = loaded
+		return errSkipSave
+	})
+	if err != nil {
+		return nil, err
+	}
+	stats := &domain.TTSCacheStats{
+		Path:    r.basePath,
+		Entries: len(index.Entries),
+		MaxSize: r.maxSize,
+		Hits:    index.Hits,
+		Misses:  index.Misses,
+	}
+	if r.maxAge > 0 {
+		stats.MaxAge = r.maxAge.String()
+	}
+	for _, entry := range index.Entries {
+		stats.TotalSize += entry.Size
+		created := entry.CreatedAt
+		if stats.OldestEntry == nil || created.Before(*stats.OldestEntry) {
+			stats.OldestEntry = &created
+		}
+		if stats.NewestEntry == nil || created.After(*stats.NewestEntry) {
+			stats.NewestEntry = &created
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes all cached audio and resets the statistics
+func (r *CachingTTSRepository) Clear(ctx context.Context) error {
+	return r.withLock(func() error {
+		for _, dir := range []string{r.blobsDir(), r.tmpDir()} {
+			if err := os.RemoveAll(dir); err != nil {
+				return err
+			}
+		}
+		if err := os.Remove(r.indexPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+}
+
+// Prune removes expired entries, then least recently used entries until the
+// cache fits in maxSize bytes (0 keeps the configured limit)
+func (r *CachingTTSRepository) Prune(ctx context.Context, maxSize int64) (*domain.TTSCachePruneResult, error) {
+	if maxSize <= 0 {
+		maxSize = r.maxSize
+	}
+	var result *domain.TTSCachePruneResult
+	err := r.withIndex(func(index *cacheIndex) error {
+		result = r.evict(index, maxSize)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	r.removeStaleTemp()
+	return result, nil
+}
+
+// removeStaleTemp removes the leftovers of interrupted downloads, which are
+// never referenced again. Downloads still in progress keep their files.
+func (r *CachingTTSRepository) removeStaleTemp() {
+	entries, err := os.ReadDir(r.tmpDir())
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || r.now().Sub(info.ModTime()) < staleTempAge {
+			continue
+		}
+		os.Remove(filepath.Join(r.tmpDir(), entry.Name()))
+	}
+}
+
+// ShareAudio replaces the file at filePath with a hard link to the cached audio
+// for request. Files on another volume or with different content (compared by
+// SHA-256, since post-processing may keep the size) are left alone.
+func (r *CachingTTSRepository) ShareAudio(ctx context.Context, request *domain.TTSRequest, filePath string) (bool, error) {
+	key, err := CacheKey(request)
+	if err != nil {
+		return false, err
+	}
+
+	var entry *cacheEntry
+	err = r.withIndex(func(index *cacheIndex) error {
+		entry = index.Entries[key]
+		return errSkipSave
+	})
+	if err != nil {
+		return false, err
+	}
+	if entry == nil {
+		return false, nil
+	}
+	blobPath := filepath.Join(r.blobsDir(), entry.File)
+	blobInfo, err := os.Stat(blobPath)
+	if err != nil {
+		return false, nil
+	}
+	fileInfo, err := os.Stat(filePath)
+	if err != nil {
+		return false, err
+	}
+	if os.SameFile(blobInfo, fileInfo) {
+		return true, nil
+	}
+	if blobInfo.Size() != fileInfo.Size() {
+		return false, nil
+	}
+	blobSum := entry.SHA256
+	if blobSum == "" {
+		if blobSum, err = fileSHA256(blobPath); err != nil {
+			return false, nil
+		}
+	}
+	fileSum, err := fileSHA256(filePath)
+	if err != nil {
+		return false, err
+	}
+	if fileSum != blobSum {
+		return false, nil
+	}
+
+	linkPath := filePath + ".link"
+	os.Remove(linkPath)
+	if err := os.Link(blobPath, linkPath); err != nil {
+		return false, nil
+	}
+	if err := os.Rename(linkPath, filePath); err != nil {
+		os.Remove(linkPath)
+		return false, err
+	}
+	return true, nil
+}
+
+// lookup opens the cached audio for key and records the hit or miss. A cache
+// that cannot be read is bypassed.
+func (r *CachingTTSRepository) lookup(key string) *domain.TTSResponse {
+	var response *domain.TTSResponse
+	r.withIndex(func(index *cacheIndex) error {
+		entry, ok := index.Entries[key]
+		if ok && r.expired(entry) {
+			r.remove(index, key)
+			ok = false
+		}
+		if !ok {
+			index.Misses++
+			return nil
+		}
+
+		file, err := os.Open(filepath.Join(r.blobsDir(), entry.File))
+		if err != nil {
+			delete(index.Entries, key)
+			index.Misses++
+			return nil
+		}
+
+		entry.Hits++
+		entry.LastAccessedAt = r.now()
+		index.Hits++
+
+		response = &domain.TTSResponse{
+			AudioData:   file,
+			BillingInfo: &http.BillingInfo{CreditsUsed: "0"},
+			FileName:    entry.FileName,
+			Cached:      true,
+		}
+		return nil
+	})
+	return response
+}
+
+// record wraps audio so that it is copied into the cache while being read
+func (r *CachingTTSRepository) record(key string, request *domain.TTSRequest, fileName string, audio io.ReadCloser) io.ReadCloser {
+	if err := os.MkdirAll(r.tmpDir(), 0755); err != nil {
+		return audio
+	}
+	tmp, err := os.CreateTemp(r.tmpDir(), key+"-*")
+	if err != nil {
+		return audio
+	}
+
+	format := domain.OutputFormatWAV
+	if request.OutputFormat != nil {
+		format = *request.OutputFormat
+	}
+	return &cacheRecorder{
+		ReadCloser: audio,
+		tmp:        tmp,
+		hash:       sha256.New(),
+		commit: func(tmpPath string, size int64, sum string) {
+			r.commit(key, tmpPath, &cacheEntry{
+				File:      filepath.Join(key[:2], key+domain.GetFileExtensionFromFormat(format)),
+				Size:      size,
+				SHA256:    sum,
+				FileName:  fileName,
+				ModelUUID: request.ModelUUID,
+			})
+		},
+	}
+}
+
+// commit moves a completely downloaded blob into place and enforces the limits
+func (r *CachingTTSRepository) commit(key, tmpPath string, entry *cacheEntry) {
+	err := r.withIndex(func(index *cacheIndex) error {
+		blobPath := filepath.Join(r.blobsDir(), entry.File)
+		if err := os.MkdirAll(filepath.Dir(blobPath), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(tmpPath, blobPath); err != nil {
+			return err
+		}
+
+		entry.CreatedAt = r.now()
+		entry.LastAccessedAt = entry.CreatedAt
+		index.Entries[key] = entry
+		r.evict(index, r.maxSize)
+		return nil
+	})
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+}
+
+// evict removes expired entries and then the least recently used ones until
+// the total size is within maxSize
+func (r *CachingTTSRepository) evict(index *cacheIndex, maxSize int64) *domain.TTSCachePruneResult {
+	result := &domain.TTSCachePruneResult{}
+	drop := func(key string) {
+		result.RemovedEntries++
+		result.FreedBytes += index.Entries[key].Size
+		r.remove(index, key)
+	}
+
+	var total int64
+	keys := make([]string, 0, len(index.Entries))
+	for key, entry := range index.Entries {
+		if r.expired(entry) {
+			drop(key)
+			continue
+		}
+		total += entry.Size
+		keys = append(keys, key)
+	}
+	if maxSize <= 0 || total <= maxSize {
+		return result
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return index.Entries[keys[i]].LastAccessedAt.Before(index.Entries[keys[j]].LastAccessedAt)
+	})
+	for _, key := range keys {
+		if total <= maxSize {
+			break
+		}
+		total -= index.Entries[key].Size
+		drop(key)
+	}
+	return result
+}
+
+func (r *CachingTTSRepository) expired(entry *cacheEntry) bool {
+	return r.maxAge > 0 && r.now().Sub(entry.CreatedAt) > r.maxAge
+}
+
+func (r *CachingTTSRepository) remove(index *cacheIndex, key string) {
+	if entry, ok := index.Entries[key]; ok {
+		os.Remove(filepath.Join(r.blobsDir(), entry.File))
+		delete(index.Entries, key)
+	}
+}
+
+func (r *CachingTTSRepository) blobsDir() string  { return filepath.Join(r.basePath, "blobs") }
+func (r *CachingTTSRepository) tmpDir() string    { return filepath.Join(r.basePath, "tmp") }
+func (r *CachingTTSRepository) indexPath() string { return filepath.Join(r.basePath, "index.json") }
+func (r *CachingTTSRepository) lockPath() string  { return filepath.Join(r.basePath, "index.lock") }
+
+// errSkipSave ends a withIndex callback that only read the index
+var errSkipSave = errors.New("index unchanged")
+
+// withLock runs fn holding the in-process mutex and the exclusive lock on
+// the cache shared with other processes
+func (r *CachingTTSRepository) withLock(fn func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.basePath, 0755); err != nil {
+		return err
+	}
+	unlock, err := lockFile(r.lockPath(), true)
+	if err != nil {
+		return fmt.Errorf("failed to lock TTS cache: %w", err)
+	}
+	defer unlock()
+	return fn()
+}
+
+// withIndex loads the index under the lock, runs fn on it and saves it
+// unless fn returns an error; errSkipSave leaves it unchanged without
+// failing. A corrupt index is rebuilt first.
+func (r *CachingTTSRepository) withIndex(fn func(index *cacheIndex) error) error {
+	return r.withLock(func() error {
+		index, err := r.loadIndex()
+		if errors.Is(err, errCorruptIndex) {
+			index, err = r.recoverIndex()
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(index); err != nil {
+			if errors.Is(err, errSkipSave) {
+				return nil
+			}
+			return err
+		}
+		return r.saveIndex(index)
+	})
+}
+
+// loadIndex reads the index; a missing index is an empty cache
+func (r *CachingTTSRepository) loadIndex() (*cacheIndex, error) {
+	index := &cacheIndex{}
+	data, err := os.ReadFile(r.indexPath())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read cache index: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, index); err != nil {
+			return nil, fmt.Errorf("%w: %v", errCorruptIndex, err)
+		}
+	}
+	if index.Entries == nil {
+		index.Entries = make(map[string]*cacheEntry)
+	}
+	return index, nil
+}
+
+// recoverIndex rebuilds the entries from the blobs after index.json could not
+// be parsed, so that they are served and pruned again instead of orphaned.
+// Blobs are named after their key; their modification time stands in for the
+// creation and access times, and the statistics start over. The corrupt file
+// is kept next to the index as index.json.corrupt-<unix time>.
+func (r *CachingTTSRepository) recoverIndex() (*cacheIndex, error) {
+	corruptPath := fmt.Sprintf("%s.corrupt-%d", r.indexPath(), r.now().Unix())
+	if err := os.Rename(r.indexPath(), corruptPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to set aside corrupt cache index: %w", err)
+	}
+
+	index := &cacheIndex{Entries: make(map[string]*cacheEntry)}
+	dirs, err := os.ReadDir(r.blobsDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read cache blobs: %w", err)
+	}
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		blobs, err := os.ReadDir(filepath.Join(r.blobsDir(), dir.Name()))
+		if err != nil {
+			continue
+		}
+		for _, blob := range blobs {
+			key := strings.TrimSuffix(blob.Name(), filepath.Ext(blob.Name()))
+			info, err := blob.Info()
+			if err != nil || blob.IsDir() || len(key) != sha256.Size*2 || !strings.HasPrefix(key, dir.Name()) {
+				continue
+			}
+			index.Entries[key] = &cacheEntry{
+				File:           filepath.Join(dir.Name(), blob.Name()),
+				Size:           info.Size(),
+				CreatedAt:      info.ModTime(),
+				LastAccessedAt: info.ModTime(),
+			}
+		}
+	}
+
+	if err := r.saveIndex(index); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+// saveIndex writes the index atomically so a crash never leaves it truncated
+func (r *CachingTTSRepository) saveIndex(index *cacheIndex) error {
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(r.indexPath(), data)
+}
+
+// cacheRecorder copies audio into a temporary file while it is read and hands
+// the file and its SHA-256 to commit once the stream reached EOF. Streams that
+// are closed early or fail are discarded.
+type cacheRecorder struct {
+	io.ReadCloser
+	tmp    *os.File
+	hash   hash.Hash
+	size   int64
+	failed bool
+	commit func(tmpPath string, size int64, sum string)
+}
+
+func (c *cacheRecorder) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if c.tmp == nil {
+		return n, err
+	}
+	if n > 0 && !c.failed {
+		if _, werr := c.tmp.Write(p[:n]); werr != nil {
+			c.failed = true
+		}
+		c.hash.Write(p[:n])
+		c.size += int64(n)
+	}
+	switch {
+	case err == io.EOF:
+		c.finish(!c.failed && c.size > 0)
+	case err != nil:
+		c.finish(false)
+	}
+	return n, err
+}
+
+func (c *cacheRecorder) Close() error {
+	c.finish(false)
+	return c.ReadCloser.Close()
+}
+
+func (c *cacheRecorder) finish(complete bool) {
+	if c.tmp == nil {
+		return
+	}
+	tmpPath := c.tmp.Name()
+	closeErr := c.tmp.Close()
+	c.tmp = nil
+	if complete && closeErr == nil {
+		c.commit(tmpPath, c.size, hex.EncodeToString(c.hash.Sum(nil)))
+		return
+	}
+	os.Remove(tmpPath)
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,360 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// countingTTSRepo returns the request text as audio and counts API calls
+type countingTTSRepo struct {
+	calls int
+}
+
+func (r *countingTTSRepo) Synthesize(ctx context.Context, request *domain.TTSRequest) (*domain.TTSResponse, error) {
+	r.calls++
+	return &domain.TTSResponse{
+		AudioData:   io.NopCloser(strings.NewReader("audio:" + request.Text)),
+		BillingInfo: &http.BillingInfo{CreditsUsed: "1"},
+	}, nil
+}
+
+func (r *countingTTSRepo) SynthesizeStream(ctx context.Context, request *domain.TTSRequest) (io.ReadCloser, error) {
+	r.calls++
+	return io.NopCloser(strings.NewReader("audio:" + request.Text)), nil
+}
+
+func readAll(t *testing.T, response *domain.TTSResponse) string {
+	t.Helper()
+	defer response.AudioData.Close()
+	data, err := io.ReadAll(response.AudioData)
+	if err != nil {
+		t.Fatalf("read audio: %v", err)
+	}
+	return string(data)
+}
+
+func TestCacheKey(t *testing.T) {
+	base := func() *domain.TTSRequestBuilder {
+		return domain.NewTTSRequestBuilder("model", "こんにちは").WithOutputFormat(domain.OutputFormatMP3)
+	}
+	key := func(request *domain.TTSRequest) string {
+		k, err := CacheKey(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	if key(base().Build()) != key(base().Build()) {
+		t.Error("identical requests must share a key")
+	}
+	if key(base().Build()) != key(base().WithLanguage(domain.LanguageJapanese).Build()) {
+		t.Error("an unset language must match the default language")
+	}
+	for name, request := range map[string]*domain.TTSRequest{
+		"text":    domain.NewTTSRequestBuilder("model", "こんばんは").WithOutputFormat(domain.OutputFormatMP3).Build(),
+		"model":   domain.NewTTSRequestBuilder("other", "こんにちは").WithOutputFormat(domain.OutputFormatMP3).Build(),
+		"format":  base().WithOutputFormat(domain.OutputFormatWAV).Build(),
+		"style":   base().WithStyleID(1).Build(),
+		"speaker": base().WithSpeaker("speaker").Build(),
+		"rate":    base().WithSpeakingRate(1.2).Build(),
+		"pitch":   base().WithPitch(0.1).Build(),
+	} {
+		if key(request) == key(base().Build()) {
+			t.Errorf("changing %s must change the key", name)
+		}
+	}
+}
+
+func TestCachingTTSRepositoryServesHits(t *testing.T) {
+	next := &countingTTSRepo{}
+	cache := NewCachingTTSRepository(next, t.TempDir(), 0, 0)
+	ctx := context.Background()
+	request := domain.NewTTSRequestBuilder("model", "hello").Build()
+
+	first, err := cache.Synthesize(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Cached || readAll(t, first) != "audio:hello" {
+		t.Fatal("first request must come from the API")
+	}
+
+	second, err := cache.Synthesize(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !second.Cached || readAll(t, second) != "audio:hello" {
+		t.Error("second request must be served from the cache")
+	}
+	if second.BillingInfo == nil || second.BillingInfo.CreditsUsed != "0" {
+		t.Errorf("cache hits must not report credits, got %+v", second.BillingInfo)
+	}
+
+	stream, err := cache.SynthesizeStream(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream.Close()
+
+	if next.calls != 1 {
+		t.Errorf("API called %d times, want 1", next.calls)
+	}
+	stats, _ := cache.Stats(ctx)
+	if stats.Entries != 1 || stats.Hits != 2 || stats.Misses != 1 || stats.TotalSize != int64(len("audio:hello")) {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func TestCachingTTSRepositorySkipsIncompleteReads(t *testing.T) {
+	next := &countingTTSRepo{}
+	cache := NewCachingTTSRepository(next, t.TempDir(), 0, 0)
+	ctx := context.Background()
+	request := domain.NewTTSRequestBuilder("model", "interrupted").Build()
+
+	response, _ := cache.Synthesize(ctx, request)
+	response.AudioData.Read(make([]byte, 3))
+	response.AudioData.Close()
+
+	again, _ := cache.Synthesize(ctx, request)
+	if again.Cached {
+		t.Error("a partially read response must not be cached")
+	}
+	readAll(t, again)
+}
+
+func TestCachingTTSRepositoryEviction(t *testing.T) {
+	next := &countingTTSRepo{}
+	dir := t.TempDir()
+	// Each entry is 7 bytes ("audio:" plus one character)
+	cache := NewCachingTTSRepository(next, dir, 15, time.Hour)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	synthesize := func(text string) *domain.TTSResponse {
+		response, err := cache.Synthesize(ctx, domain.NewTTSRequestBuilder("model", text).Build())
+		if err != nil {
+			t.Fatal(err)
+		}
+		readAll(t, response)
+		return response
+	}
+
+	for _, text := range []string{"a", "b"} {
+		synthesize(text)
+		now = now.Add(time.Minute)
+	}
+	synthesize("a") // a is now more recently used than b
+	now = now.Add(time.Minute)
+	synthesize("c")
+
+	stats, _ := cache.Stats(ctx)
+	if stats.Entries != 2 || stats.TotalSize > 15 {
+		t.Fatalf("Stats() = %+v, want 2 entries within 15 bytes", stats)
+	}
+	if !synthesize("a").Cached {
+		t.Error("recently used entry a was evicted")
+	}
+	if synthesize("b").Cached {
+		t.Error("least recently used entry b was kept")
+	}
+
+	now = now.Add(2 * time.Hour)
+	result, err := cache.Prune(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.RemovedEntries != 2 {
+		t.Errorf("Prune() removed %d entries, want 2 expired entries", result.RemovedEntries)
+	}
+	blobs, _ := filepath.Glob(filepath.Join(dir, "blobs", "*", "*"))
+	if len(blobs) != 0 {
+		t.Errorf("expired blobs left on disk: %v", blobs)
+	}
+}
+
+func TestCachingTTSRepositoryShareAudio(t *testing.T) {
+	cache := NewCachingTTSRepository(&countingTTSRepo{}, t.TempDir(), 0, 0)
+	ctx := context.Background()
+	request := domain.NewTTSRequestBuilder("model", "shared").Build()
+
+	response, _ := cache.Synthesize(ctx, request)
+	historyFile := filepath.Join(t.TempDir(), "1.wav")
+	data := readAll(t, response)
+	if err := os.WriteFile(historyFile, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	shared, err := cache.ShareAudio(ctx, request, historyFile)
+	if err != nil || !shared {
+		t.Fatalf("ShareAudio() = %v, %v", shared, err)
+	}
+	blobs, _ := filepath.Glob(filepath.Join(cache.blobsDir(), "*", "*"))
+	blobInfo, _ := os.Stat(blobs[0])
+	fileInfo, _ := os.Stat(historyFile)
+	if !os.SameFile(blobInfo, fileInfo) {
+		t.Error("history file is not linked to the cached blob")
+	}
+
+	// The history file must survive the cache being cleared
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := os.ReadFile(historyFile); err != nil || string(got) != data {
+		t.Errorf("history file after Clear() = %q, %v", got, err)
+	}
+
+	other := domain.NewTTSRequestBuilder("model", "not cached").Build()
+	if shared, _ := cache.ShareAudio(ctx, other, historyFile); shared {
+		t.Error("uncached requests must not be shared")
+	}
+}
+
+func TestCachingTTSRepositoryShareAudioComparesContent(t *testing.T) {
+	cache := NewCachingTTSRepository(&countingTTSRepo{}, t.TempDir(), 0, 0)
+	ctx := context.Background()
+	request := domain.NewTTSRequestBuilder("model", "processed").Build()
+
+	response, _ := cache.Synthesize(ctx, request)
+	data := []byte(readAll(t, response))
+	// Same size, different content, as after gain or normalization
+	processed := append([]byte(nil), data...)
+	processed[len(processed)-1] ^= 0xff
+	historyFile := filepath.Join(t.TempDir(), "1.wav")
+	if err := os.WriteFile(historyFile, processed, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if shared, err := cache.ShareAudio(ctx, request, historyFile); shared || err != nil {
+		t.Fatalf("ShareAudio() of a same-size file with other content = %v, %v", shared, err)
+	}
+	if got, _ := os.ReadFile(historyFile); string(got) != string(processed) {
+		t.Error("history file with different content was replaced")
+	}
+
+	// Entries recorded before hashes were stored are compared by hashing the blob
+	err := cache.withIndex(func(index *cacheIndex) error {
+		for _, entry := range index.Entries {
+			entry.SHA256 = ""
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shared, _ := cache.ShareAudio(ctx, request, historyFile); shared {
+		t.Error("legacy entry shared with a file of other content")
+	}
+	if err := os.WriteFile(historyFile, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if shared, err := cache.ShareAudio(ctx, request, historyFile); !shared || err != nil {
+		t.Errorf("ShareAudio() of identical content with a legacy entry = %v, %v", shared, err)
+	}
+}
+
+func TestCachingTTSRepositorySharedBetweenProcesses(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// Each repository stands in for a process: only the file lock is shared
+			cache := NewCachingTTSRepository(&countingTTSRepo{}, dir, 0, 0)
+			response, err := cache.Synthesize(ctx, domain.NewTTSRequestBuilder("model", fmt.Sprint(i)).Build())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			io.Copy(io.Discard, response.AudioData)
+			response.AudioData.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	stats, err := NewCachingTTSRepository(&countingTTSRepo{}, dir, 0, 0).Stats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 20 || stats.Misses != 20 {
+		t.Errorf("Stats() = %+v, want 20 entries and misses", stats)
+	}
+}
+
+func TestCachingTTSRepositoryRecoversCorruptIndex(t *testing.T) {
+	dir := t.TempDir()
+	next := &countingTTSRepo{}
+	cache := NewCachingTTSRepository(next, dir, 0, 0)
+	ctx := context.Background()
+	request := domain.NewTTSRequestBuilder("model", "hello").Build()
+
+	for _, text := range []string{"hello", "world"} {
+		response, _ := cache.Synthesize(ctx, domain.NewTTSRequestBuilder("model", text).Build())
+		readAll(t, response)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), []byte(`{"entries": {`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := cache.Synthesize(ctx, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !response.Cached || readAll(t, response) != "audio:hello" {
+		t.Error("blobs must still be served after the index is rebuilt")
+	}
+	if next.calls != 2 {
+		t.Errorf("API called %d times, want 2", next.calls)
+	}
+	if stats, _ := cache.Stats(ctx); stats.Entries != 2 || stats.TotalSize != int64(len("audio:hello")+len("audio:world")) {
+		t.Errorf("Stats() after recovery = %+v, want both blobs", stats)
+	}
+	if corrupt, _ := filepath.Glob(filepath.Join(dir, "index.json.corrupt-*")); len(corrupt) != 1 {
+		t.Errorf("corrupt index not kept aside: %v", corrupt)
+	}
+}
+
+func TestCachingTTSRepositoryPruneKeepsDownloadsInProgress(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCachingTTSRepository(&countingTTSRepo{}, dir, 0, 0)
+	ctx := context.Background()
+
+	// A download in progress, possibly in another process
+	response, err := cache.Synthesize(ctx, domain.NewTTSRequestBuilder("model", "in progress").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The leftover of a download interrupted long ago
+	stale := filepath.Join(dir, "tmp", "stale")
+	if err := os.WriteFile(stale, []byte("audio"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleTempAge)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cache.Prune(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("stale temporary file was kept")
+	}
+	readAll(t, response)
+	if stats, _ := cache.Stats(ctx); stats.Entries != 1 {
+		t.Errorf("download in progress during Prune was not cached: %+v", stats)
+	}
+}
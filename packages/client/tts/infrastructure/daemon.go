@@ -0,0 +1,311 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/logger"
+)
+
+// JSON-RPC 2.0 error codes used by the playback daemon
+const (
+	DaemonErrParse          = -32700
+	DaemonErrInvalidRequest = -32600
+	DaemonErrMethodNotFound = -32601
+	DaemonErrInvalidParams  = -32602
+	DaemonErrInternal       = -32603
+	// DaemonErrServer is returned when a method itself fails
+	DaemonErrServer = -32000
+)
+
+// daemonDialTimeout bounds how long a client waits to connect to the socket
+const daemonDialTimeout = time.Second
+
+// ErrDaemonNotRunning is returned when nothing listens on the daemon socket
+var ErrDaemonNotRunning = errors.New("playback daemon is not running")
+
+// DaemonMethodFunc handles one JSON-RPC method. The returned value is encoded
+// as the result; params is null when the caller sent none.
+type DaemonMethodFunc func(ctx context.Context, params json.RawMessage) (interface{}, error)
+
+// DaemonError is a JSON-RPC error object
+type DaemonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *DaemonError) Error() string {
+	return e.Message
+}
+
+// daemonRequest is a JSON-RPC 2.0 request, one per line
+type daemonRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// daemonResponse is a JSON-RPC 2.0 response, one per line
+type daemonResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *DaemonError    `json:"error,omitempty"`
+}
+
+// DaemonServer serves newline-delimited JSON-RPC 2.0 calls on a Unix domain socket
+type DaemonServer struct {
+	socketPath string
+	logger     logger.Logger
+
+	mu       sync.RWMutex
+	methods  map[string]DaemonMethodFunc
+	listener net.Listener
+	conns    sync.WaitGroup
+}
+
+// NewDaemonServer creates a server for the given socket path
+func NewDaemonServer(socketPath string, log logger.Logger) *DaemonServer {
+	if log == nil {
+		log = logger.NewNoop()
+	}
+	return &DaemonServer{
+		socketPath: socketPath,
+		logger:     log,
+		methods:    make(map[string]DaemonMethodFunc),
+	}
+}
+
+// Handle registers the handler for a method
+func (s *DaemonServer) Handle(method string, fn DaemonMethodFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods[method] = fn
+}
+
+// Serve listens on the socket and handles connections until ctx is cancelled.
+// A socket file left behind by a daemon that died is replaced; a socket that
+// still answers means another daemon is running and Serve fails. The socket
+// is removed again on return.
+func (s *DaemonServer) Serve(ctx context.Context) error {
+	if err := os.MkdirAll(filepath.Dir(s.socketPath), 0700); err != nil {
+		return fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	if _, err := os.Stat(s.socketPath); err == nil {
+		if NewDaemonClient(s.socketPath).Ping() {
+			return fmt.Errorf("a playback daemon is already listening on %s", s.socketPath)
+		}
+		if err := os.Remove(s.socketPath); err != nil {
+			return fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	// Only the owner may control playback
+	listener, err := listenDaemonSocket(s.socketPath)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.socketPath, err)
+	}
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+	s.logger.Info("Playback daemon listening", logger.String("socket", s.socketPath))
+
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			s.logger.Warn("Failed to accept daemon connection", logger.Error(err))
+			continue
+		}
+		s.conns.Add(1)
+		go func() {
+			defer s.conns.Done()
+			s.serveConn(ctx, conn)
+		}()
+	}
+
+	s.conns.Wait()
+	os.Remove(s.socketPath)
+	s.logger.Info("Playback daemon stopped")
+	return nil
+}
+
+// serveConn answers requests on one connection until the peer disconnects
+func (s *DaemonServer) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	encoder := json.NewEncoder(conn)
+	for scanner.Scan() {
+		response := s.dispatch(ctx, scanner.Bytes())
+		if err := encoder.Encode(response); err != nil {
+			s.logger.Debug("Failed to write daemon response", logger.Error(err))
+			return
+		}
+	}
+}
+
+// dispatch decodes one request line and runs its method
+func (s *DaemonServer) dispatch(ctx context.Context, line []byte) *daemonResponse {
+	var request daemonRequest
+	if err := json.Unmarshal(line, &request); err != nil {
+		return &daemonResponse{JSONRPC: "2.0", Error: &DaemonError{Code: DaemonErrParse, Message: "parse error: " + err.Error()}}
+	}
+	response := &daemonResponse{JSONRPC: "2.0", ID: request.ID}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		response.Error = &DaemonError{Code: DaemonErrInvalidRequest, Message: "invalid request"}
+		return response
+	}
+
+	s.mu.RLock()
+	fn, ok := s.methods[request.Method]
+	s.mu.RUnlock()
+	if !ok {
+		response.Error = &DaemonError{Code: DaemonErrMethodNotFound, Message: "method not found: " + request.Method}
+		return response
+	}
+
+	s.logger.Debug("Daemon call", logger.String("method", request.Method))
+	result, err := fn(ctx, request.Params)
+	if err != nil {
+		var rpcErr *DaemonError
+		if errors.As(err, &rpcErr) {
+			response.Error = rpcErr
+		} else {
+			response.Error = &DaemonError{Code: DaemonErrServer, Message: err.Error()}
+		}
+		return response
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		response.Error = &DaemonError{Code: DaemonErrInternal, Message: "failed to encode result: " + err.Error()}
+		return response
+	}
+	response.Result = data
+	return response
+}
+
+// DecodeDaemonParams unmarshals method params, reporting failures as invalid params
+func DecodeDaemonParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return &DaemonError{Code: DaemonErrInvalidParams, Message: "params are required"}
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &DaemonError{Code: DaemonErrInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// DaemonClient calls a playback daemon. Every call uses its own connection,
+// so a client is safe for concurrent use.
+type DaemonClient struct {
+	socketPath string
+	nextID     int64
+}
+
+// NewDaemonClient creates a client for the daemon listening on socketPath
+func NewDaemonClient(socketPath string) *DaemonClient {
+	return &DaemonClient{socketPath: socketPath}
+}
+
+// SocketPath returns the socket the client connects to
+func (c *DaemonClient) SocketPath() string {
+	return c.socketPath
+}
+
+// Ping reports whether a daemon accepts connections on the socket
+func (c *DaemonClient) Ping() bool {
+	conn, err := net.DialTimeout("unix", c.socketPath, daemonDialTimeout)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// Call invokes method with params and decodes the result into result, which
+// may be nil to discard it. Cancelling ctx abandons the call.
+func (c *DaemonClient) Call(ctx context.Context, method string, params, result interface{}) error {
+	request := daemonRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddInt64(&c.nextID, 1),
+		Method:  method,
+	}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode params: %w", err)
+		}
+		request.Params = data
+	}
+
+	dialer := net.Dialer{Timeout: daemonDialTimeout}
+	conn, err := dialer.DialContext(ctx, "unix", c.socketPath)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDaemonNotRunning, err)
+	}
+	defer conn.Close()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+
+	if err := json.NewEncoder(conn).Encode(&request); err != nil {
+		return c.callError(ctx, "send", err)
+	}
+	var response daemonResponse
+	if err := json.NewDecoder(conn).Decode(&response); err != nil {
+		return c.callError(ctx, "read", err)
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if result != nil && len(response.Result) > 0 {
+		if err := json.Unmarshal(response.Result, result); err != nil {
+			return fmt.Errorf("failed to decode daemon result: %w", err)
+		}
+	}
+	return nil
+}
+
+// callError prefers the context error over the connection error it caused
+func (c *DaemonClient) callError(ctx context.Context, op string, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return fmt.Errorf("failed to %s daemon request: %w", op, err)
+}
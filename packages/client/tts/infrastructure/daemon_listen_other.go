@@ -0,0 +1,22 @@
+//go:build !unix
+
+package infrastructure
+
+import (
+	"net"
+	"os"
+)
+
+// listenDaemonSocket listens on path. Without a umask there is no window in
+// which the socket is more permissive than the mode set here.
+func listenDaemonSocket(path string) (net.Listener, error) {
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
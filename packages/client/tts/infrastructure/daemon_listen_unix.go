@@ -0,0 +1,39 @@
+//go:build unix
+
+package infrastructure
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+)
+
+// listenDaemonSocket listens on path with a socket only the owner can use.
+// The socket is bound inside a fresh 0700 directory, restricted to 0600 and
+// only then renamed into place, so it is never reachable with the
+// permissions the umask would give it.
+func listenDaemonSocket(path string) (net.Listener, error) {
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".daemon-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	tmpPath := filepath.Join(dir, "daemon.sock")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	// The socket is renamed away, so there is nothing to unlink on close
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmpPath, 0600); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
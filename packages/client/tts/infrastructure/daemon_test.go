@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// startTestDaemon serves s until the test ends and waits for the socket to answer
+func startTestDaemon(t *testing.T, s *DaemonServer, socketPath string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- s.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		if err := <-served; err != nil {
+			t.Errorf("Serve() error = %v", err)
+		}
+	})
+
+	client := NewDaemonClient(socketPath)
+	for deadline := time.Now().Add(5 * time.Second); !client.Ping(); {
+		if time.Now().After(deadline) {
+			t.Fatal("daemon did not start listening")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDaemonServerCalls(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+	server := NewDaemonServer(socketPath, nil)
+
+	volume := 0.0
+	server.Handle(domain.DaemonMethodSetVolume, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		var p domain.DaemonVolumeParams
+		if err := DecodeDaemonParams(params, &p); err != nil {
+			return nil, err
+		}
+		volume = p.Volume
+		return nil, nil
+	})
+	server.Handle(domain.DaemonMethodStatus, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return &domain.DaemonStatus{PID: 42, Playback: domain.PlaybackInfo{Status: domain.PlaybackStatusPlaying, Volume: volume}}, nil
+	})
+	server.Handle(domain.DaemonMethodStop, func(ctx context.Context, params json.RawMessage) (interface{}, error) {
+		return nil, fmt.Errorf("nothing is playing")
+	})
+	startTestDaemon(t, server, socketPath)
+
+	ctx := context.Background()
+	client := NewDaemonClient(socketPath)
+	if err := client.Call(ctx, domain.DaemonMethodSetVolume, &domain.DaemonVolumeParams{Volume: 0.4}, nil); err != nil {
+		t.Fatalf("set_volume error = %v", err)
+	}
+	var status domain.DaemonStatus
+	if err := client.Call(ctx, domain.DaemonMethodStatus, nil, &status); err != nil {
+		t.Fatalf("status error = %v", err)
+	}
+	if status.PID != 42 || status.Playback.Status != domain.PlaybackStatusPlaying || status.Playback.Volume != 0.4 {
+		t.Errorf("status = %+v", status)
+	}
+
+	var rpcErr *DaemonError
+	if err := client.Call(ctx, domain.DaemonMethodStop, nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != DaemonErrServer || rpcErr.Message != "nothing is playing" {
+		t.Errorf("method error = %v, want server error", err)
+	}
+	if err := client.Call(ctx, domain.DaemonMethodSetVolume, nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != DaemonErrInvalidParams {
+		t.Errorf("missing params error = %v, want invalid params", err)
+	}
+	if err := client.Call(ctx, "bogus", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != DaemonErrMethodNotFound {
+		t.Errorf("unknown method error = %v, want method not found", err)
+	}
+
+	// Several requests may share a connection, one JSON object per line
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintln(conn, `{"jsonrpc":"2.0","id":1,"method":"status"}`)
+	fmt.Fprintln(conn, `not json`)
+	decoder := json.NewDecoder(conn)
+	for i, wantErr := range []bool{false, true} {
+		var response daemonResponse
+		if err := decoder.Decode(&response); err != nil {
+			t.Fatalf("response %d: %v", i, err)
+		}
+		if (response.Error != nil) != wantErr {
+			t.Errorf("response %d error = %v, want error %v", i, response.Error, wantErr)
+		}
+	}
+}
+
+func TestDaemonServerSocketHandling(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "daemon.sock")
+
+	if err := NewDaemonClient(socketPath).Call(context.Background(), domain.DaemonMethodStatus, nil, nil); !errors.Is(err, ErrDaemonNotRunning) {
+		t.Errorf("call without daemon error = %v, want ErrDaemonNotRunning", err)
+	}
+
+	// A socket left behind by a crashed daemon is replaced
+	stale, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	stale.Close()
+
+	startTestDaemon(t, NewDaemonServer(socketPath, nil), socketPath)
+	if info, err := os.Stat(socketPath); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("socket mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	// The private directory the socket was bound in is gone
+	if entries, err := os.ReadDir(filepath.Dir(socketPath)); err != nil || len(entries) != 1 {
+		t.Errorf("socket directory entries = %v, %v; want only the socket", entries, err)
+	}
+
+	// A live daemon is not replaced
+	if err := NewDaemonServer(socketPath, nil).Serve(context.Background()); err == nil {
+		t.Error("a second daemon must not take over a live socket")
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package infrastructure
+
+// lockFile is a no-op on platforms without advisory file locks; writes are
+// still atomic, but concurrent processes may lose updates.
+func lockFile(path string, exclusive bool) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package infrastructure
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an advisory flock on path, creating it if needed, and blocks
+// until the lock is granted. The returned function releases it.
+func lockFile(path string, exclusive bool) (func(), error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err = syscall.Flock(int(file.Fd()), how)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+		file.Close()
+	}, nil
+}
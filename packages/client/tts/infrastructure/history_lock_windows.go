@@ -0,0 +1,34 @@
+//go:build windows
+
+package infrastructure
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile takes a LockFileEx lock on path, creating it if needed, and blocks
+// until the lock is granted. The returned function releases it.
+func lockFile(path string, exclusive bool) (func(), error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags uint32
+	if exclusive {
+		flags = windows.LOCKFILE_EXCLUSIVE_LOCK
+	}
+	handle := windows.Handle(file.Fd())
+	overlapped := new(windows.Overlapped)
+	if err := windows.LockFileEx(handle, flags, 0, 1, 0, overlapped); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return func() {
+		windows.UnlockFileEx(handle, 0, 1, 0, overlapped)
+		file.Close()
+	}, nil
+}
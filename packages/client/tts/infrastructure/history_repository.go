@@ -3,6 +3,7 @@ package infrastructure
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"os"
@@ -16,7 +17,13 @@ import (
 	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
 )
 
-// FileHistoryRepository implements TTSHistoryRepository using local file system
+// errCorruptMetadata reports that metadata.json exists but cannot be parsed
+var errCorruptMetadata = errors.New("history metadata is corrupt")
+
+// FileHistoryRepository implements TTSHistoryRepository using local file system.
+// Every read-modify-write cycle runs under an advisory lock on the store
+// directory, so several processes (e.g. `tts play` and the MCP server) can
+// share one store, and files are replaced atomically via rename.
 type FileHistoryRepository struct {
 	basePath string
 }
@@ -54,64 +61,173 @@ func (r *FileHistoryRepository) getCounterPath() string {
 	return filepath.Join(r.basePath, "counter.json")
 }
 
+// getLockPath returns the path to the lock file guarding the store
+func (r *FileHistoryRepository) getLockPath() string {
+	return filepath.Join(r.basePath, ".lock")
+}
+
+// withLock runs fn while holding the store lock. Writers take it exclusively;
+// readers share it so they never observe a half-finished cycle.
+func (r *FileHistoryRepository) withLock(exclusive bool, fn func() error) error {
+	if err := r.ensureDirectories(); err != nil {
+		return err
+	}
+
+	unlock, err := lockFile(r.getLockPath(), exclusive)
+	if err != nil {
+		return fmt.Errorf("failed to lock history store: %w", err)
+	}
+	defer unlock()
+
+	return fn()
+}
+
+// readMetadata loads the metadata under a shared lock. A corrupt metadata file
+// is rebuilt under an exclusive lock first.
+func (r *FileHistoryRepository) readMetadata() (*historyMetadata, error) {
+	var metadata *historyMetadata
+	err := r.withLock(false, func() (err error) {
+		metadata, err = r.loadMetadata()
+		return err
+	})
+	if errors.Is(err, errCorruptMetadata) {
+		err = r.withLock(true, func() (err error) {
+			metadata, err = r.loadOrRecoverMetadata()
+			return err
+		})
+	}
+	return metadata, err
+}
+
 // loadMetadata loads the metadata from file
 func (r *FileHistoryRepository) loadMetadata() (*historyMetadata, error) {
 	metadataPath := r.getMetadataPath()
-	
+
+	data, err := os.ReadFile(metadataPath)
 	// If file doesn't exist, return empty metadata
-	if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
+	if os.IsNotExist(err) {
 		return &historyMetadata{Records: []*domain.TTSHistory{}}, nil
 	}
-	
-	data, err := os.ReadFile(metadataPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read metadata file: %w", err)
 	}
 	
 	var metadata historyMetadata
 	if err := json.Unmarshal(data, &metadata); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		return nil, fmt.Errorf("%w: %v", errCorruptMetadata, err)
 	}
 	
 	return &metadata, nil
 }
 
+// loadOrRecoverMetadata loads the metadata, rebuilding it from the audio
+// directory when it is corrupt. The caller must hold the exclusive lock.
+func (r *FileHistoryRepository) loadOrRecoverMetadata() (*historyMetadata, error) {
+	metadata, err := r.loadMetadata()
+	if errors.Is(err, errCorruptMetadata) {
+		return r.recoverMetadata()
+	}
+	return metadata, err
+}
+
+// recoverMetadata rebuilds the records from the files in audio/ after
+// metadata.json could not be parsed. Files named after their ID keep it; other
+// files get new IDs in modification order. Request details cannot be recovered.
+// The corrupt file is kept next to the store as metadata.json.corrupt-<unix time>.
+func (r *FileHistoryRepository) recoverMetadata() (*historyMetadata, error) {
+	metadataPath := r.getMetadataPath()
+	corruptPath := fmt.Sprintf("%s.corrupt-%d", metadataPath, time.Now().Unix())
+	if err := os.Rename(metadataPath, corruptPath); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to set aside corrupt metadata: %w", err)
+	}
+
+	audioDir := filepath.Join(r.basePath, "audio")
+	entries, err := os.ReadDir(audioDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read audio directory: %w", err)
+	}
+
+	metadata := &historyMetadata{Records: []*domain.TTSHistory{}}
+	var unnumbered []*domain.TTSHistory
+	used := make(map[int]bool)
+	maxID := 0
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+
+		filePath := filepath.Join(audioDir, entry.Name())
+		record := &domain.TTSHistory{
+			InternalUUID:  uuid.New().String(),
+			FilePath:      filePath,
+			FileFormat:    domain.GetFormatFromFilePath(filePath),
+			FileSizeBytes: info.Size(),
+			CreatedAt:     info.ModTime(),
+		}
+
+		stem := strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		if id, err := strconv.Atoi(stem); err == nil && id > 0 && !used[id] {
+			record.ID = id
+			used[id] = true
+			if id > maxID {
+				maxID = id
+			}
+			metadata.Records = append(metadata.Records, record)
+		} else {
+			unnumbered = append(unnumbered, record)
+		}
+	}
+
+	sort.Slice(unnumbered, func(i, j int) bool {
+		return unnumbered[i].CreatedAt.Before(unnumbered[j].CreatedAt)
+	})
+	for _, record := range unnumbered {
+		maxID++
+		record.ID = maxID
+		metadata.Records = append(metadata.Records, record)
+	}
+	sort.Slice(metadata.Records, func(i, j int) bool {
+		return metadata.Records[i].ID < metadata.Records[j].ID
+	})
+
+	if err := r.saveMetadata(metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
 // saveMetadata saves the metadata to file
 func (r *FileHistoryRepository) saveMetadata(metadata *historyMetadata) error {
-	if err := r.ensureDirectories(); err != nil {
-		return err
-	}
-	
 	data, err := json.MarshalIndent(metadata, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
-	
-	metadataPath := r.getMetadataPath()
-	if err := os.WriteFile(metadataPath, data, 0644); err != nil {
+
+	if err := writeFileAtomic(r.getMetadataPath(), data); err != nil {
 		return fmt.Errorf("failed to write metadata file: %w", err)
 	}
 	
 	return nil
 }
 
-// loadCounter loads the ID counter from file
+// loadCounter loads the ID counter from file. A missing or unreadable counter
+// starts from ID 1; allocateID never hands out an ID below the existing records.
 func (r *FileHistoryRepository) loadCounter() (*idCounter, error) {
-	counterPath := r.getCounterPath()
-	
-	// If file doesn't exist, start from ID 1
-	if _, err := os.Stat(counterPath); os.IsNotExist(err) {
+	data, err := os.ReadFile(r.getCounterPath())
+	if os.IsNotExist(err) {
 		return &idCounter{NextID: 1}, nil
 	}
-	
-	data, err := os.ReadFile(counterPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read counter file: %w", err)
 	}
 	
 	var counter idCounter
-	if err := json.Unmarshal(data, &counter); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal counter: %w", err)
+	if err := json.Unmarshal(data, &counter); err != nil || counter.NextID < 1 {
+		return &idCounter{NextID: 1}, nil
 	}
 	
 	return &counter, nil
@@ -119,33 +235,69 @@ func (r *FileHistoryRepository) loadCounter() (*idCounter, error) {
 
 // saveCounter saves the ID counter to file
 func (r *FileHistoryRepository) saveCounter(counter *idCounter) error {
-	if err := r.ensureDirectories(); err != nil {
-		return err
-	}
-	
 	data, err := json.MarshalIndent(counter, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal counter: %w", err)
 	}
-	
-	counterPath := r.getCounterPath()
-	if err := os.WriteFile(counterPath, data, 0644); err != nil {
+
+	if err := writeFileAtomic(r.getCounterPath(), data); err != nil {
 		return fmt.Errorf("failed to write counter file: %w", err)
 	}
 	
 	return nil
 }
 
-// GetNextID returns the next available sequential ID
-func (r *FileHistoryRepository) GetNextID(ctx context.Context) (int, error) {
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers see either the old or the new content
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// allocateID reserves the next sequential ID. The caller must hold the
+// exclusive lock. IDs already used by records are skipped even if the counter
+// lags behind, e.g. after metadata recovery.
+func (r *FileHistoryRepository) allocateID(metadata *historyMetadata) (int, error) {
 	counter, err := r.loadCounter()
 	if err != nil {
 		return 0, err
 	}
 	
 	nextID := counter.NextID
-	counter.NextID++
-	
+	for _, record := range metadata.Records {
+		if record.ID >= nextID {
+			nextID = record.ID + 1
+		}
+	}
+	counter.NextID = nextID + 1
+
 	if err := r.saveCounter(counter); err != nil {
 		return 0, err
 	}
@@ -153,10 +305,44 @@ func (r *FileHistoryRepository) GetNextID(ctx context.Context) (int, error) {
 	return nextID, nil
 }
 
+// GetNextID returns the next available sequential ID
+func (r *FileHistoryRepository) GetNextID(ctx context.Context) (int, error) {
+	var nextID int
+	err := r.withLock(true, func() error {
+		metadata, err := r.loadOrRecoverMetadata()
+		if err != nil {
+			return err
+		}
+		nextID, err = r.allocateID(metadata)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return nextID, nil
+}
+
 // Save stores a new history record and returns the assigned ID
 func (r *FileHistoryRepository) Save(ctx context.Context, history *domain.TTSHistory) (int, error) {
+	var id int
+	err := r.withLock(true, func() (err error) {
+		id, err = r.save(history)
+		return err
+	})
+	return id, err
+}
+
+// save stores a new history record. The caller must hold the exclusive lock.
+func (r *FileHistoryRepository) save(history *domain.TTSHistory) (int, error) {
+	// Load existing metadata
+	metadata, err := r.loadOrRecoverMetadata()
+	if err != nil {
+		return 0, err
+	}
+	
 	if history.ID == 0 {
-		id, err := r.GetNextID(ctx)
+		id, err := r.allocateID(metadata)
 		if err != nil {
 			return 0, err
 		}
@@ -173,12 +359,6 @@ func (r *FileHistoryRepository) Save(ctx context.Context, history *domain.TTSHis
 		history.CreatedAt = time.Now()
 	}
 	
-	// Load existing metadata
-	metadata, err := r.loadMetadata()
-	if err != nil {
-		return 0, err
-	}
-	
 	// Add new record
 	metadata.Records = append(metadata.Records, history)
 	
@@ -192,7 +372,7 @@ func (r *FileHistoryRepository) Save(ctx context.Context, history *domain.TTSHis
 
 // GetByID retrieves a history record by its sequential ID
 func (r *FileHistoryRepository) GetByID(ctx context.Context, id int) (*domain.TTSHistory, error) {
-	metadata, err := r.loadMetadata()
+	metadata, err := r.readMetadata()
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +388,7 @@ func (r *FileHistoryRepository) GetByID(ctx context.Context, id int) (*domain.TT
 
 // List retrieves history records based on search criteria
 func (r *FileHistoryRepository) List(ctx context.Context, request *domain.TTSHistorySearchRequest) (*domain.TTSHistoryListResponse, error) {
-	metadata, err := r.loadMetadata()
+	metadata, err := r.readMetadata()
 	if err != nil {
 		return nil, err
 	}
@@ -253,6 +433,19 @@ func (r *FileHistoryRepository) filterRecords(records []*domain.TTSHistory, requ
 		if request.ModelUUID != nil && record.ModelUUID != *request.ModelUUID {
 			continue
 		}
+
+		// Filter by script session
+		if request.ScriptSession != nil && record.ScriptSession != *request.ScriptSession {
+			continue
+		}
+
+		// Filter by labels
+		if request.Project != nil && record.Project != *request.Project {
+			continue
+		}
+		if request.Tag != nil && !record.HasTag(*request.Tag) {
+			continue
+		}
 		
 		// Filter by text content
 		if request.TextContains != nil {
@@ -310,7 +503,14 @@ func (r *FileHistoryRepository) sortRecords(records []*domain.TTSHistory, reques
 
 // Delete removes a history record by ID (including associated files)
 func (r *FileHistoryRepository) Delete(ctx context.Context, id int) error {
-	metadata, err := r.loadMetadata()
+	return r.withLock(true, func() error {
+		return r.deleteRecord(id)
+	})
+}
+
+// deleteRecord removes a record and its file. The caller must hold the exclusive lock.
+func (r *FileHistoryRepository) deleteRecord(id int) error {
+	metadata, err := r.loadOrRecoverMetadata()
 	if err != nil {
 		return err
 	}
@@ -349,12 +549,19 @@ func (r *FileHistoryRepository) DeleteMultiple(ctx context.Context, ids []int) e
 		return nil
 	}
 	
+	return r.withLock(true, func() error {
+		return r.deleteMultiple(ids)
+	})
+}
+
+// deleteMultiple removes records and their files. The caller must hold the exclusive lock.
+func (r *FileHistoryRepository) deleteMultiple(ids []int) error {
 	idMap := make(map[int]bool)
 	for _, id := range ids {
 		idMap[id] = true
 	}
 	
-	metadata, err := r.loadMetadata()
+	metadata, err := r.loadOrRecoverMetadata()
 	if err != nil {
 		return err
 	}
@@ -386,11 +593,12 @@ func (r *FileHistoryRepository) DeleteMultiple(ctx context.Context, ids []int) e
 
 // Clear removes all history records
 func (r *FileHistoryRepository) Clear(ctx context.Context) error {
-	metadata, err := r.loadMetadata()
-	if err != nil {
-		return err
-	}
-	
+	return r.withLock(true, r.clearAll)
+}
+
+// clearAll removes all records and files. The caller must hold the exclusive lock.
+// The metadata is not loaded, so a corrupt store can still be cleared.
+func (r *FileHistoryRepository) clearAll() error {
 	// Delete all audio files
 	audioDir := filepath.Join(r.basePath, "audio")
 	if err := filepath.WalkDir(audioDir, func(path string, d fs.DirEntry, err error) error {
@@ -408,8 +616,7 @@ func (r *FileHistoryRepository) Clear(ctx context.Context) error {
 	}
 	
 	// Clear metadata
-	metadata.Records = []*domain.TTSHistory{}
-	if err := r.saveMetadata(metadata); err != nil {
+	if err := r.saveMetadata(&historyMetadata{Records: []*domain.TTSHistory{}}); err != nil {
 		return err
 	}
 	
@@ -420,7 +627,7 @@ func (r *FileHistoryRepository) Clear(ctx context.Context) error {
 
 // Count returns the total number of history records
 func (r *FileHistoryRepository) Count(ctx context.Context) (int, error) {
-	metadata, err := r.loadMetadata()
+	metadata, err := r.readMetadata()
 	if err != nil {
 		return 0, err
 	}
@@ -430,7 +637,14 @@ func (r *FileHistoryRepository) Count(ctx context.Context) (int, error) {
 
 // Cleanup removes old records based on configuration
 func (r *FileHistoryRepository) Cleanup(ctx context.Context, maxCount int, maxAge *time.Duration) error {
-	metadata, err := r.loadMetadata()
+	return r.withLock(true, func() error {
+		return r.cleanup(maxCount, maxAge)
+	})
+}
+
+// cleanup removes old records. The caller must hold the exclusive lock.
+func (r *FileHistoryRepository) cleanup(maxCount int, maxAge *time.Duration) error {
+	metadata, err := r.loadOrRecoverMetadata()
 	if err != nil {
 		return err
 	}
@@ -474,7 +688,7 @@ func (r *FileHistoryRepository) Cleanup(ctx context.Context, maxCount int, maxAg
 	
 	// Delete marked records
 	if len(toDelete) > 0 {
-		return r.DeleteMultiple(ctx, toDelete)
+		return r.deleteMultiple(toDelete)
 	}
 	
 	return nil
@@ -0,0 +1,187 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+const (
+	historyWriterDirEnv   = "AIVIS_TEST_HISTORY_WRITER_DIR"
+	historyWriterCountEnv = "AIVIS_TEST_HISTORY_WRITER_COUNT"
+)
+
+// TestHelperHistoryWriter is not a real test: it is the body of the writer
+// processes spawned by TestFileHistoryRepositoryMultiProcessWriters.
+func TestHelperHistoryWriter(t *testing.T) {
+	dir := os.Getenv(historyWriterDirEnv)
+	if dir == "" {
+		t.Skip("helper process only")
+	}
+	count, _ := strconv.Atoi(os.Getenv(historyWriterCountEnv))
+
+	repo := NewFileHistoryRepository(dir)
+	for i := 0; i < count; i++ {
+		text := fmt.Sprintf("pid %d #%d", os.Getpid(), i)
+		if _, err := repo.Save(context.Background(), testHistory(text, "m", time.Now())); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+}
+
+func assertUniqueIDs(t *testing.T, repo *FileHistoryRepository, want int) {
+	t.Helper()
+	ctx := context.Background()
+
+	response, err := repo.List(ctx, domain.NewTTSHistorySearchRequest().WithLimit(want+10).Build())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if response.Total != want {
+		t.Errorf("Total = %d, want %d (records were lost)", response.Total, want)
+	}
+	seen := make(map[int]bool)
+	for _, h := range response.Histories {
+		if seen[h.ID] {
+			t.Errorf("ID %d was assigned twice", h.ID)
+		}
+		seen[h.ID] = true
+	}
+	if next, _ := repo.GetNextID(ctx); next != want+1 {
+		t.Errorf("GetNextID() = %d, want %d", next, want+1)
+	}
+}
+
+func TestFileHistoryRepositoryConcurrentSaves(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	const writers, perWriter = 4, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*perWriter)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Separate instances share nothing but the directory
+			repo := NewFileHistoryRepository(dir)
+			for i := 0; i < perWriter; i++ {
+				if _, err := repo.Save(ctx, testHistory("text", "m", time.Now())); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent Save() error = %v", err)
+	}
+
+	assertUniqueIDs(t, NewFileHistoryRepository(dir), writers*perWriter)
+}
+
+func TestFileHistoryRepositoryMultiProcessWriters(t *testing.T) {
+	if testing.Short() {
+		t.Skip("spawns writer processes")
+	}
+	dir := t.TempDir()
+
+	const processes, perProcess = 4, 15
+	cmds := make([]*exec.Cmd, processes)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHelperHistoryWriter$")
+		cmd.Env = append(os.Environ(),
+			historyWriterDirEnv+"="+dir,
+			historyWriterCountEnv+"="+strconv.Itoa(perProcess),
+		)
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("start writer: %v", err)
+		}
+		cmds[i] = cmd
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("writer process failed: %v", err)
+		}
+	}
+
+	assertUniqueIDs(t, NewFileHistoryRepository(dir), processes*perProcess)
+}
+
+func TestFileHistoryRepositoryAtomicWrites(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewFileHistoryRepository(dir)
+	ctx := context.Background()
+
+	for _, text := range []string{"a", "b", "c"} {
+		if _, err := repo.Save(ctx, testHistory(text, "m", time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	repo.Delete(ctx, 2)
+
+	leftovers, _ := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+	if count, _ := repo.Count(ctx); count != 2 {
+		t.Errorf("Count() = %d, want 2", count)
+	}
+}
+
+func TestFileHistoryRepositoryRecoversCorruptMetadata(t *testing.T) {
+	dir := t.TempDir()
+	repo := NewFileHistoryRepository(dir)
+	ctx := context.Background()
+
+	audioDir := filepath.Join(dir, "audio")
+	os.MkdirAll(audioDir, 0755)
+	old := time.Now().Add(-time.Hour)
+	for name, modTime := range map[string]time.Time{
+		"3.mp3":             old,
+		"7.wav":             old,
+		"tts_20250101.flac": old.Add(time.Minute),
+		"tts_20250102.wav":  old.Add(2 * time.Minute),
+	} {
+		path := filepath.Join(audioDir, name)
+		os.WriteFile(path, []byte(name), 0644)
+		os.Chtimes(path, modTime, modTime)
+	}
+	os.WriteFile(filepath.Join(dir, "metadata.json"), []byte(`{"records": [{"id": 3,`), 0644)
+	os.WriteFile(filepath.Join(dir, "counter.json"), []byte(`garbage`), 0644)
+
+	response, err := repo.List(ctx, domain.NewTTSHistorySearchRequest().WithSorting("id", "asc").Build())
+	if err != nil {
+		t.Fatalf("List() after corruption error = %v", err)
+	}
+	if got := idsOf(response); !equalInts(got, []int{3, 7, 8, 9}) {
+		t.Fatalf("recovered IDs = %v, want [3 7 8 9]", got)
+	}
+	for _, h := range response.Histories {
+		if h.InternalUUID == "" || h.FileSizeBytes == 0 {
+			t.Errorf("incomplete recovered record %+v", h)
+		}
+	}
+	if got := response.Histories[2]; filepath.Base(got.FilePath) != "tts_20250101.flac" || got.FileFormat != "flac" {
+		t.Errorf("record 8 = %s (%s), want the older unnumbered file", got.FilePath, got.FileFormat)
+	}
+
+	corrupt, _ := filepath.Glob(filepath.Join(dir, "metadata.json.corrupt-*"))
+	if len(corrupt) != 1 {
+		t.Errorf("corrupt metadata must be kept aside, found %v", corrupt)
+	}
+
+	// The counter was unreadable too; new IDs must continue after the recovered records
+	if id, err := repo.Save(ctx, testHistory("new", "m", time.Now())); err != nil || id != 10 {
+		t.Errorf("Save() after recovery = %d, %v, want 10", id, err)
+	}
+}
@@ -0,0 +1,592 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/common/logger"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// Native player timing
+const (
+	// nativePeriod is the amount of audio rendered per sink write
+	nativePeriod = 20 * time.Millisecond
+
+	// nativeLead bounds how far sink writes run ahead of the playback clock,
+	// which keeps pause, stop and volume changes responsive
+	nativeLead = 100 * time.Millisecond
+
+	// nativeRamp is the length of the gain ramp applied on volume changes,
+	// pause, resume and stop; short enough to sound instant, long enough not to click
+	nativeRamp = 15 * time.Millisecond
+)
+
+// NativeAudioPlayer implements AudioPlayer by decoding WAV, FLAC and MP3 in
+// process and writing the PCM to an AudioSink. Unlike OSCommandAudioPlayer it really
+// pauses, applies volume changes to the playing item and reports the exact
+// position. Formats it cannot decode are handed to an optional fallback player.
+type NativeAudioPlayer struct {
+	config   *domain.PlaybackConfig
+	logger   logger.Logger
+	sink     domain.AudioSink
+	fallback domain.AudioPlayer
+
+	mu          sync.Mutex
+	cond        *sync.Cond
+	status      domain.PlaybackStatus
+	volume      float64
+	queueLength int
+	currentText string
+	current     *nativePlayback
+	useFallback bool // the current item is played by the fallback player
+}
+
+// nativePlayback is the state of one Play call
+type nativePlayback struct {
+	format    audio.Format
+	envelope  audio.Envelope
+	offset    int64 // frames skipped by the start offset
+	total     int64 // length in frames after the offset, -1 until known
+	written   int64 // frames handed to the sink
+	started   time.Time
+	pausedAt  time.Time
+	pausedFor time.Duration
+	paused    bool
+	stopping  bool
+	stopFade  time.Duration // fade-out applied when stopping, nativeRamp if zero
+	draining  bool
+	source    *readAhead
+	done      chan struct{}
+}
+
+// NewNativeAudioPlayer creates an in-process audio player writing to sink
+func NewNativeAudioPlayer(config *domain.PlaybackConfig, sink domain.AudioSink, log logger.Logger) *NativeAudioPlayer {
+	if config == nil {
+		config = domain.DefaultPlaybackConfig()
+	}
+	if log == nil {
+		log = logger.NewNoop()
+	}
+
+	p := &NativeAudioPlayer{
+		config: config,
+		logger: log,
+		sink:   sink,
+		status: domain.PlaybackStatusIdle,
+		volume: clampVolume(config.DefaultVolume),
+	}
+	p.cond = sync.NewCond(&p.mu)
+	return p
+}
+
+// SetFallback sets the player used for formats that cannot be decoded in
+// process (AAC and Opus)
+func (p *NativeAudioPlayer) SetFallback(player domain.AudioPlayer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.fallback = player
+}
+
+// Play starts playback of the given audio stream, stopping the current item
+func (p *NativeAudioPlayer) Play(ctx context.Context, audioData io.Reader, format domain.OutputFormat) error {
+	return p.PlayWithEnvelope(ctx, audioData, format, audio.Envelope{})
+}
+
+// PlayWithEnvelope starts playback of the given audio stream from the
+// envelope's start offset with its fades, stopping the current item. Formats
+// handed to the fallback player cannot be shaped, so an envelope is refused
+// for them.
+func (p *NativeAudioPlayer) PlayWithEnvelope(ctx context.Context, audioData io.Reader, format domain.OutputFormat, envelope audio.Envelope) error {
+	if err := p.Stop(); err != nil {
+		p.logger.Warn("Failed to stop previous playback", logger.Error(err))
+	}
+
+	source := newReadAhead(audioData)
+	input := bufio.NewReaderSize(source, 64*1024)
+	stream, err := audio.NewStream(input)
+	if err != nil {
+		p.mu.Lock()
+		fallback := p.fallback
+		p.mu.Unlock()
+		if errors.Is(err, audio.ErrUnsupportedFormat) && !envelope.IsZero() {
+			source.Close()
+			return errEnvelopeFormat(format)
+		}
+		if errors.Is(err, audio.ErrUnsupportedFormat) && fallback != nil {
+			p.logger.Info("Audio format is not decodable in process, using the command player without pause, live volume or position",
+				logger.String("format", string(format)),
+			)
+			p.mu.Lock()
+			p.useFallback = true
+			p.status = domain.PlaybackStatusPlaying
+			p.mu.Unlock()
+			return fallback.Play(ctx, input, format)
+		}
+		source.Close()
+		return fmt.Errorf("failed to decode %s audio: %w", format, err)
+	}
+
+	if err := p.sink.Open(stream.Format()); err != nil {
+		source.Close()
+		return fmt.Errorf("failed to open audio output: %w", err)
+	}
+
+	total := stream.TotalFrames()
+	stream = audio.ApplyEnvelope(stream, envelope)
+	playback := &nativePlayback{
+		format:   stream.Format(),
+		envelope: envelope,
+		offset:   durationToFrames(envelope.StartOffset, stream.Format().SampleRate),
+		total:    stream.TotalFrames(),
+		started:  time.Now(),
+		source:   source,
+		done:     make(chan struct{}),
+	}
+	if total >= 0 {
+		playback.offset = min(playback.offset, total)
+	}
+	p.mu.Lock()
+	p.current = playback
+	p.useFallback = false
+	p.status = domain.PlaybackStatusPlaying
+	p.mu.Unlock()
+
+	p.logger.Debug("Starting native audio playback",
+		logger.String("format", stream.Format().String()),
+		logger.Int64("frames", playback.total),
+	)
+	go p.run(ctx, playback, stream)
+	return nil
+}
+
+// run decodes the stream into the sink until it ends or is stopped
+func (p *NativeAudioPlayer) run(ctx context.Context, pb *nativePlayback, stream audio.Stream) {
+	defer close(pb.done)
+	defer pb.source.Close()
+
+	format := pb.format
+	frameSize := format.BlockAlign()
+	periodFrames := durationToFrames(nativePeriod, format.SampleRate)
+	leadFrames := durationToFrames(nativeLead, format.SampleRate)
+	step := 1 / (float64(format.SampleRate) * nativeRamp.Seconds())
+	fadingOut := false
+	buf := make([]byte, periodFrames*int64(frameSize))
+
+	p.mu.Lock()
+	gain := p.volume
+	p.mu.Unlock()
+
+	var err error
+	completed := false
+	for {
+		p.mu.Lock()
+		if ctx.Err() != nil {
+			pb.stopping = true
+		}
+		// Pausing ramps down first, then waits without writing
+		for pb.paused && gain == 0 && !pb.stopping {
+			p.cond.Wait()
+		}
+		target := p.volume
+		if pb.paused || pb.stopping {
+			target = 0
+		}
+		if pb.stopping && pb.stopFade > 0 && !fadingOut {
+			// Interrupted: fade out from the current gain over the item's fade-out
+			fadingOut = true
+			step = gain / (float64(format.SampleRate) * pb.stopFade.Seconds())
+		}
+		stopped := pb.stopping && gain == 0
+		p.mu.Unlock()
+		if stopped {
+			break
+		}
+
+		n, readErr := io.ReadFull(stream, buf)
+		n -= n % frameSize
+		if n > 0 {
+			gain = applyGain(buf[:n], format, gain, target, step)
+			p.pace(pb, leadFrames)
+			if _, err = p.sink.Write(buf[:n]); err != nil {
+				break
+			}
+			p.mu.Lock()
+			pb.written += int64(n / frameSize)
+			p.mu.Unlock()
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			completed = true
+			break
+		}
+		if readErr != nil {
+			err = readErr
+			break
+		}
+	}
+
+	if completed {
+		p.mu.Lock()
+		pb.draining = true
+		if pb.total < 0 {
+			pb.total = pb.written
+		}
+		p.mu.Unlock()
+		err = p.sink.Drain()
+		p.pace(pb, 0)
+	} else if fadingOut {
+		// Let the fade-out play before the sink is closed
+		p.pace(pb, 0)
+	}
+	if closeErr := p.sink.Close(); err == nil {
+		err = closeErr
+	}
+
+	p.mu.Lock()
+	if p.current == pb {
+		p.current = nil
+		if pb.stopping || err != nil {
+			p.status = domain.PlaybackStatusStopped
+		} else {
+			p.status = domain.PlaybackStatusIdle
+		}
+	}
+	played := framesToDuration(pb.written, format.SampleRate)
+	stopping := pb.stopping
+	p.mu.Unlock()
+
+	if err != nil && !stopping {
+		p.logger.Warn("Native audio playback failed", logger.Error(err))
+		return
+	}
+	p.logger.Info("Audio playback completed (native)",
+		logger.Duration("played", played),
+		logger.Bool("stopped", stopping),
+	)
+}
+
+// pace blocks until the playback clock is no more than lead frames behind
+// the frames written, or playback is paused or stopped
+func (p *NativeAudioPlayer) pace(pb *nativePlayback, lead int64) {
+	for {
+		p.mu.Lock()
+		ahead := pb.written - pb.clockFrames(time.Now())
+		// An interrupt's fade-out keeps playing in real time
+		interrupted := pb.paused || (pb.stopping && pb.stopFade == 0)
+		p.mu.Unlock()
+		if ahead <= lead || interrupted {
+			return
+		}
+		wait := framesToDuration(ahead-lead, pb.format.SampleRate)
+		if wait > nativePeriod {
+			wait = nativePeriod
+		}
+		time.Sleep(wait)
+	}
+}
+
+// clockFrames returns how many frames should have played by now, excluding
+// time spent paused. Callers hold the player lock.
+func (pb *nativePlayback) clockFrames(now time.Time) int64 {
+	elapsed := now.Sub(pb.started) - pb.pausedFor
+	if pb.paused {
+		elapsed -= now.Sub(pb.pausedAt)
+	}
+	if elapsed < 0 {
+		return 0
+	}
+	return durationToFrames(elapsed, pb.format.SampleRate)
+}
+
+// Stop stops current playback after a short fade-out
+func (p *NativeAudioPlayer) Stop() error {
+	return p.stop(false)
+}
+
+// Interrupt stops current playback, fading it out over the fade-out of its
+// envelope, or as Stop does when it has none
+func (p *NativeAudioPlayer) Interrupt() error {
+	return p.stop(true)
+}
+
+// stop stops current playback, over the item's fade-out when fade is set
+func (p *NativeAudioPlayer) stop(fade bool) error {
+	p.mu.Lock()
+	pb := p.current
+	fallback := p.fallback
+	useFallback := p.useFallback
+	p.useFallback = false
+	p.status = domain.PlaybackStatusStopped
+	draining := false
+	var wait time.Duration
+	if pb != nil {
+		pb.stopping = true
+		if fade && !pb.paused {
+			pb.stopFade = pb.envelope.FadeOut
+		}
+		wait = pb.stopFade
+		draining = pb.draining
+		p.cond.Broadcast()
+	}
+	p.mu.Unlock()
+
+	if draining {
+		// Cut off audio still buffered in the sink
+		p.sink.Close()
+	}
+	if pb != nil {
+		select {
+		case <-pb.done:
+		case <-time.After(2*nativeLead + wait):
+			// The decoder is waiting for input that has not arrived yet
+			pb.source.Close()
+			<-pb.done
+		}
+	}
+	if useFallback && fallback != nil {
+		return fallback.Stop()
+	}
+	return nil
+}
+
+// Pause pauses current playback after a short fade-out
+func (p *NativeAudioPlayer) Pause() error {
+	p.mu.Lock()
+	if p.useFallback && p.fallback != nil {
+		fallback := p.fallback
+		p.mu.Unlock()
+		return fallback.Pause()
+	}
+	defer p.mu.Unlock()
+
+	if pb := p.current; pb != nil && !pb.paused && !pb.stopping && !pb.draining {
+		pb.paused = true
+		pb.pausedAt = time.Now()
+		p.status = domain.PlaybackStatusPaused
+		p.cond.Broadcast()
+	}
+	return nil
+}
+
+// Resume resumes paused playback with a short fade-in
+func (p *NativeAudioPlayer) Resume() error {
+	p.mu.Lock()
+	if p.useFallback && p.fallback != nil {
+		fallback := p.fallback
+		p.mu.Unlock()
+		return fallback.Resume()
+	}
+	defer p.mu.Unlock()
+
+	if pb := p.current; pb != nil && pb.paused {
+		pb.paused = false
+		pb.pausedFor += time.Since(pb.pausedAt)
+		p.status = domain.PlaybackStatusPlaying
+		p.cond.Broadcast()
+	}
+	return nil
+}
+
+// SetVolume sets the playback volume (0.0 to 1.0); the playing item ramps to
+// the new volume
+func (p *NativeAudioPlayer) SetVolume(volume float64) error {
+	p.mu.Lock()
+	p.volume = clampVolume(volume)
+	fallback := p.fallback
+	p.mu.Unlock()
+
+	if fallback != nil {
+		return fallback.SetVolume(volume)
+	}
+	return nil
+}
+
+// GetStatus returns current playback status
+func (p *NativeAudioPlayer) GetStatus() domain.PlaybackInfo {
+	p.mu.Lock()
+	info := domain.PlaybackInfo{
+		Status:      p.status,
+		QueueLength: p.queueLength,
+		CurrentText: p.currentText,
+		Volume:      p.volume,
+	}
+	if pb := p.current; pb != nil {
+		rate := pb.format.SampleRate
+		info.Position = framesToDuration(pb.offset+min(pb.written, pb.clockFrames(time.Now())), rate)
+		if pb.total >= 0 {
+			info.Duration = framesToDuration(pb.offset+pb.total, rate)
+		}
+	}
+	fallback := p.fallback
+	useFallback := p.useFallback
+	p.mu.Unlock()
+
+	if useFallback && fallback != nil {
+		status := fallback.GetStatus()
+		info.Status = status.Status
+		info.Duration = status.Duration
+		info.Position = status.Position
+	}
+	return info
+}
+
+// IsPlaying returns true while an item is playing or paused. A paused item
+// still holds the player, so queued items wait for it.
+func (p *NativeAudioPlayer) IsPlaying() bool {
+	p.mu.Lock()
+	if p.useFallback && p.fallback != nil {
+		fallback := p.fallback
+		p.mu.Unlock()
+		return fallback.IsPlaying()
+	}
+	defer p.mu.Unlock()
+	return p.current != nil && !p.current.stopping
+}
+
+// Close closes the audio player and releases resources
+func (p *NativeAudioPlayer) Close() error {
+	err := p.Stop()
+	p.mu.Lock()
+	fallback := p.fallback
+	p.mu.Unlock()
+	if fallback != nil {
+		if closeErr := fallback.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// SetCurrentText sets the current text being played (for status reporting)
+func (p *NativeAudioPlayer) SetCurrentText(text string) {
+	p.mu.Lock()
+	p.currentText = text
+	fallback := p.fallback
+	p.mu.Unlock()
+	if setter, ok := fallback.(interface{ SetCurrentText(string) }); ok {
+		setter.SetCurrentText(text)
+	}
+}
+
+// SetQueueLength sets the current queue length (for status reporting)
+func (p *NativeAudioPlayer) SetQueueLength(length int) {
+	p.mu.Lock()
+	p.queueLength = length
+	fallback := p.fallback
+	p.mu.Unlock()
+	if setter, ok := fallback.(interface{ SetQueueLength(int) }); ok {
+		setter.SetQueueLength(length)
+	}
+}
+
+// applyGain scales the samples in pcm by a gain that moves from gain toward
+// target by at most step per frame, and returns the gain reached
+func applyGain(pcm []byte, format audio.Format, gain, target, step float64) float64 {
+	if gain == 1 && target == 1 {
+		return gain
+	}
+	bytesPerSample := format.BitsPerSample / 8
+	frameSize := format.BlockAlign()
+	for frame := 0; frame+frameSize <= len(pcm); frame += frameSize {
+		if gain < target {
+			gain = min(gain+step, target)
+		} else if gain > target {
+			gain = max(gain-step, target)
+		}
+		for i := frame; i < frame+frameSize; i += bytesPerSample {
+			if bytesPerSample == 2 {
+				v := int16(binary.LittleEndian.Uint16(pcm[i:]))
+				binary.LittleEndian.PutUint16(pcm[i:], uint16(int16(float64(v)*gain)))
+				continue
+			}
+			v := int32(pcm[i]) | int32(pcm[i+1])<<8 | int32(int8(pcm[i+2]))<<16
+			scaled := int32(float64(v) * gain)
+			pcm[i], pcm[i+1], pcm[i+2] = byte(scaled), byte(scaled>>8), byte(scaled>>16)
+		}
+	}
+	return gain
+}
+
+// clampVolume limits volume to the 0.0 to 1.0 range
+func clampVolume(volume float64) float64 {
+	return min(max(volume, 0), 1)
+}
+
+func durationToFrames(d time.Duration, sampleRate int) int64 {
+	return int64(d) * int64(sampleRate) / int64(time.Second)
+}
+
+func framesToDuration(frames int64, sampleRate int) time.Duration {
+	return time.Duration(frames * int64(time.Second) / int64(sampleRate))
+}
+
+// readAhead drains a source into memory as fast as it arrives, so that
+// streaming synthesis is not held back by real-time playback. After Close the
+// rest of the source is discarded.
+type readAhead struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    []byte
+	err    error
+	closed bool
+}
+
+func newReadAhead(r io.Reader) *readAhead {
+	ra := &readAhead{}
+	ra.cond = sync.NewCond(&ra.mu)
+	go ra.fill(r)
+	return ra
+}
+
+func (ra *readAhead) fill(r io.Reader) {
+	chunk := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(chunk)
+		ra.mu.Lock()
+		if n > 0 && !ra.closed {
+			ra.buf = append(ra.buf, chunk[:n]...)
+		}
+		if err != nil {
+			ra.err = err
+		}
+		ra.cond.Broadcast()
+		ra.mu.Unlock()
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (ra *readAhead) Read(p []byte) (int, error) {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	for len(ra.buf) == 0 && ra.err == nil && !ra.closed {
+		ra.cond.Wait()
+	}
+	if ra.closed {
+		return 0, io.ErrClosedPipe
+	}
+	if len(ra.buf) > 0 {
+		n := copy(p, ra.buf)
+		ra.buf = ra.buf[n:]
+		return n, nil
+	}
+	return 0, ra.err
+}
+
+// Close drops buffered data; the source keeps being drained in the background
+func (ra *readAhead) Close() error {
+	ra.mu.Lock()
+	defer ra.mu.Unlock()
+	ra.closed = true
+	ra.buf = nil
+	ra.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,245 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// testWAV returns a WAV file holding a ramp of the given length and its PCM
+func testWAV(t *testing.T, format audio.Format, duration time.Duration) ([]byte, []byte) {
+	t.Helper()
+	frames := int(durationToFrames(duration, format.SampleRate))
+	pcm := make([]byte, frames*format.BlockAlign())
+	for i := 0; i < frames*format.Channels; i++ {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(i%20000-10000)))
+	}
+	var out bytes.Buffer
+	if err := (&audio.Buffer{Format: format, Data: pcm}).WriteWAV(&out); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes(), pcm
+}
+
+// waitIdle waits until the player has finished its item
+func waitIdle(t *testing.T, p domain.AudioPlayer) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); p.IsPlaying(); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("playback did not finish")
+		}
+	}
+}
+
+func TestNativeAudioPlayerFileSink(t *testing.T) {
+	format := audio.Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	wav, pcm := testWAV(t, format, 200*time.Millisecond)
+	path := filepath.Join(t.TempDir(), "out.wav")
+	player := NewNativeAudioPlayer(nil, NewFileSink(path), nil)
+
+	if err := player.Play(context.Background(), bytes.NewReader(wav), domain.OutputFormatWAV); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if status := player.GetStatus(); status.Status != domain.PlaybackStatusPlaying || status.Duration != 200*time.Millisecond {
+		t.Errorf("status while playing = %+v", status)
+	}
+	start := time.Now()
+	waitIdle(t, player)
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("playback took %v, want real time", elapsed)
+	}
+	if status := player.GetStatus().Status; status != domain.PlaybackStatusIdle {
+		t.Errorf("status after playback = %s, want idle", status)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recorded, err := audio.DecodeWAVBytes(data)
+	if err != nil {
+		t.Fatalf("recorded file: %v", err)
+	}
+	if recorded.Format != format || !bytes.Equal(recorded.Data, pcm) {
+		t.Error("recorded PCM differs from input at full volume")
+	}
+
+	// A lower volume scales the samples
+	player.SetVolume(0.5)
+	if err := player.Play(context.Background(), bytes.NewReader(wav), domain.OutputFormatWAV); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	waitIdle(t, player)
+	data, _ = os.ReadFile(path)
+	recorded, err = audio.DecodeWAVBytes(data)
+	if err != nil {
+		t.Fatalf("recorded file: %v", err)
+	}
+	for i := 0; i+1 < len(pcm); i += 2 {
+		want := int16(float64(int16(binary.LittleEndian.Uint16(pcm[i:]))) * 0.5)
+		if got := int16(binary.LittleEndian.Uint16(recorded.Data[i:])); got != want {
+			t.Fatalf("sample %d = %d, want %d", i/2, got, want)
+		}
+	}
+}
+
+func TestNativeAudioPlayerPauseResumeStop(t *testing.T) {
+	format := audio.Format{SampleRate: 8000, Channels: 2, BitsPerSample: 16}
+	wav, _ := testWAV(t, format, 2*time.Second)
+	player := NewNativeAudioPlayer(nil, NewNullSink(), nil)
+
+	if err := player.Play(context.Background(), bytes.NewReader(wav), domain.OutputFormatWAV); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	time.Sleep(150 * time.Millisecond)
+
+	if err := player.Pause(); err != nil {
+		t.Fatalf("Pause() error = %v", err)
+	}
+	paused := player.GetStatus()
+	if paused.Status != domain.PlaybackStatusPaused || paused.Position < 100*time.Millisecond {
+		t.Fatalf("status after pause = %+v", paused)
+	}
+	if !player.IsPlaying() {
+		t.Error("a paused item must keep the player busy")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if position := player.GetStatus().Position; position != paused.Position {
+		t.Errorf("position moved while paused: %v -> %v", paused.Position, position)
+	}
+
+	if err := player.Resume(); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	resumed := player.GetStatus()
+	if resumed.Status != domain.PlaybackStatusPlaying || resumed.Position <= paused.Position {
+		t.Errorf("status after resume = %+v", resumed)
+	}
+	if resumed.Position > paused.Position+150*time.Millisecond {
+		t.Errorf("position %v counted time spent paused", resumed.Position)
+	}
+
+	start := time.Now()
+	if err := player.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Stop() took %v", elapsed)
+	}
+	if player.IsPlaying() || player.GetStatus().Status != domain.PlaybackStatusStopped {
+		t.Errorf("status after stop = %+v", player.GetStatus())
+	}
+}
+
+func TestNativeAudioPlayerEnvelope(t *testing.T) {
+	format := audio.Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	wav, pcm := testWAV(t, format, 300*time.Millisecond)
+	path := filepath.Join(t.TempDir(), "out.wav")
+	player := NewNativeAudioPlayer(nil, NewFileSink(path), nil)
+
+	envelope := audio.Envelope{StartOffset: 100 * time.Millisecond, FadeIn: 50 * time.Millisecond}
+	if err := player.PlayWithEnvelope(context.Background(), bytes.NewReader(wav), domain.OutputFormatWAV, envelope); err != nil {
+		t.Fatalf("PlayWithEnvelope() error = %v", err)
+	}
+	if status := player.GetStatus(); status.Duration != 300*time.Millisecond || status.Position < 100*time.Millisecond {
+		t.Errorf("status while playing = %+v, want position from the offset", status)
+	}
+	waitIdle(t, player)
+
+	data, _ := os.ReadFile(path)
+	recorded, err := audio.DecodeWAVBytes(data)
+	if err != nil {
+		t.Fatalf("recorded file: %v", err)
+	}
+	if recorded.Duration() != 200*time.Millisecond {
+		t.Fatalf("recorded %v, want 200ms after the offset", recorded.Duration())
+	}
+	if first := binary.LittleEndian.Uint16(recorded.Data); first != 0 {
+		t.Errorf("first sample = %d, want silence at the start of the fade-in", int16(first))
+	}
+	// Past the fade-in the audio is the input from the offset on
+	fadeBytes, offsetBytes := 400*2, 800*2
+	if !bytes.Equal(recorded.Data[fadeBytes:], pcm[offsetBytes+fadeBytes:]) {
+		t.Error("audio after the fade-in differs from the input")
+	}
+}
+
+func TestNativeAudioPlayerInterrupt(t *testing.T) {
+	format := audio.Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	wav, _ := testWAV(t, format, 2*time.Second)
+	player := NewNativeAudioPlayer(nil, NewNullSink(), nil)
+
+	envelope := audio.Envelope{FadeOut: 300 * time.Millisecond}
+	if err := player.PlayWithEnvelope(context.Background(), bytes.NewReader(wav), domain.OutputFormatWAV, envelope); err != nil {
+		t.Fatalf("PlayWithEnvelope() error = %v", err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	start := time.Now()
+	if err := player.Interrupt(); err != nil {
+		t.Fatalf("Interrupt() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Interrupt() took %v, want about the 300ms fade-out", elapsed)
+	}
+	if player.IsPlaying() || player.GetStatus().Status != domain.PlaybackStatusStopped {
+		t.Errorf("status after interrupt = %+v", player.GetStatus())
+	}
+}
+
+// recordingPlayer is a fallback player that records what it was given
+type recordingPlayer struct {
+	OSCommandAudioPlayer
+	data   []byte
+	format domain.OutputFormat
+}
+
+func (r *recordingPlayer) Play(ctx context.Context, audioData io.Reader, format domain.OutputFormat) error {
+	data, err := io.ReadAll(audioData)
+	r.data, r.format = data, format
+	return err
+}
+
+func TestNativeAudioPlayerFallback(t *testing.T) {
+	player := NewNativeAudioPlayer(nil, NewNullSink(), nil)
+	aac := bytes.Repeat([]byte{0xFF, 0xF1, 0x50, 0x80, 0x02, 0x1F, 0xFC}, 100)
+
+	if err := player.Play(context.Background(), bytes.NewReader(aac), domain.OutputFormatAAC); err == nil {
+		t.Error("expected an error for AAC without a fallback player")
+	}
+
+	fallback := &recordingPlayer{}
+	player.SetFallback(fallback)
+	if err := player.Play(context.Background(), bytes.NewReader(aac), domain.OutputFormatAAC); err != nil {
+		t.Fatalf("Play() error = %v", err)
+	}
+	if fallback.format != domain.OutputFormatAAC || !bytes.Equal(fallback.data, aac) {
+		t.Errorf("fallback got %d bytes of %s, want the complete AAC", len(fallback.data), fallback.format)
+	}
+}
+
+func TestNativeAudioPlayerRefusesEnvelopeForFallback(t *testing.T) {
+	player := NewNativeAudioPlayer(nil, NewNullSink(), nil)
+	fallback := &recordingPlayer{}
+	player.SetFallback(fallback)
+	aac := bytes.Repeat([]byte{0xFF, 0xF1, 0x50, 0x80, 0x02, 0x1F, 0xFC}, 100)
+
+	envelope := audio.Envelope{StartOffset: time.Second}
+	err := player.PlayWithEnvelope(context.Background(), bytes.NewReader(aac), domain.OutputFormatAAC, envelope)
+	if !errors.Is(err, audio.ErrUnsupportedFormat) {
+		t.Errorf("PlayWithEnvelope() of AAC error = %v, want ErrUnsupportedFormat", err)
+	}
+	if fallback.data != nil {
+		t.Error("the fallback played the audio without its envelope")
+	}
+}
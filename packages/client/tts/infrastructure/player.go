@@ -28,6 +28,9 @@ type OSCommandAudioPlayer struct {
 	volume        float64
 	startTime     time.Time
 	estimatedDuration time.Duration
+
+	// feed writes the current item when it was played with a fade-out
+	feed *commandFeed
 }
 
 // NewOSCommandAudioPlayer creates a new OS command-based audio player
@@ -218,6 +221,7 @@ func (p *OSCommandAudioPlayer) Play(ctx context.Context, audioData io.Reader, fo
         p.currentProc.Kill()
         p.currentProc = nil
     }
+    p.feed = nil
 
     // Prefer stdin streaming when supported by platform/player to avoid growing-file truncation
     if cmdName, args, ok := p.getStreamingCommand(format); ok {
@@ -486,6 +490,7 @@ func (p *OSCommandAudioPlayer) Stop() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	
+	p.feed = nil
 	if p.currentProc != nil {
 		err := p.currentProc.Kill()
 		// Wait a moment for process to actually terminate
@@ -0,0 +1,205 @@
+package infrastructure
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// commandFeedLead bounds how far shaped audio with a fade-out is written ahead
+// of the playback clock, so that an interruption's fade-out is heard promptly
+// instead of after everything the command has already buffered
+const commandFeedLead = 200 * time.Millisecond
+
+// errEnvelopeFormat is returned for a start offset or fades on audio that
+// cannot be decoded in process to apply them
+func errEnvelopeFormat(format domain.OutputFormat) error {
+	return fmt.Errorf("start offset and fades need WAV, FLAC or MP3 audio, not %s: %w", format, audio.ErrUnsupportedFormat)
+}
+
+// PlayWithEnvelope starts playback from the envelope's start offset with its
+// fades. WAV, FLAC and MP3 are decoded and played as shaped WAV; other
+// formats cannot be shaped without a decoder and are refused. A fade-out is also
+// applied when the item is interrupted, which needs a command reading the
+// audio from stdin; without one, a fade-out is refused too.
+func (p *OSCommandAudioPlayer) PlayWithEnvelope(ctx context.Context, audioData io.Reader, format domain.OutputFormat, envelope audio.Envelope) error {
+	if envelope.IsZero() {
+		return p.Play(ctx, audioData, format)
+	}
+	if envelope.FadeOut > 0 {
+		if _, _, ok := p.getStreamingCommand(domain.OutputFormatWAV); !ok {
+			return fmt.Errorf("a fade-out needs an audio command that reads from stdin (ffplay, SoX play or afplay) or the native player")
+		}
+	}
+
+	input := bufio.NewReaderSize(audioData, 64*1024)
+	stream, err := audio.NewStream(input)
+	if errors.Is(err, audio.ErrUnsupportedFormat) {
+		return errEnvelopeFormat(format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode %s audio: %w", format, err)
+	}
+
+	shaped := audio.ApplyEnvelope(stream, envelope)
+	pipeReader, pipeWriter := io.Pipe()
+	var feed *commandFeed
+	if envelope.FadeOut > 0 {
+		feed = newCommandFeed(shaped, envelope.FadeOut)
+		go func() {
+			pipeWriter.CloseWithError(feed.writeTo(pipeWriter))
+		}()
+	} else {
+		go func() {
+			pipeWriter.CloseWithError(writeStreamWAV(pipeWriter, shaped))
+		}()
+	}
+	if err := p.Play(ctx, pipeReader, domain.OutputFormatWAV); err != nil {
+		pipeReader.Close()
+		return err
+	}
+
+	p.mu.Lock()
+	p.feed = feed
+	p.mu.Unlock()
+	return nil
+}
+
+// Interrupt stops current playback. An item played with a fade-out is faded
+// out first: the gain of the audio not yet written to the command is ramped
+// down over the fade-out, the command is left to play what it has, and then
+// stopped.
+func (p *OSCommandAudioPlayer) Interrupt() error {
+	p.mu.Lock()
+	feed := p.feed
+	p.feed = nil
+	p.mu.Unlock()
+
+	if feed != nil {
+		feed.fadeOut()
+	}
+	return p.Stop()
+}
+
+// commandFeed writes shaped audio to a playback command as WAV in real time,
+// so that it can still be faded out when interrupted
+type commandFeed struct {
+	stream   audio.Stream
+	duration time.Duration // of the fade-out on interruption
+
+	mu      sync.Mutex
+	started time.Time
+	written int64 // frames written to the command
+	fading  bool
+	done    chan struct{} // closed once writing has ended
+}
+
+func newCommandFeed(stream audio.Stream, fadeOut time.Duration) *commandFeed {
+	return &commandFeed{stream: stream, duration: fadeOut, done: make(chan struct{})}
+}
+
+// writeTo writes the stream to w as WAV until it ends, the write fails or
+// a fade-out requested by fadeOut has reached silence
+func (f *commandFeed) writeTo(w io.Writer) error {
+	defer close(f.done)
+
+	format := f.stream.Format()
+	if err := audio.WriteStreamingWAVHeader(w, format); err != nil {
+		return err
+	}
+
+	frameSize := format.BlockAlign()
+	leadFrames := durationToFrames(commandFeedLead, format.SampleRate)
+	buf := make([]byte, durationToFrames(nativePeriod, format.SampleRate)*int64(frameSize))
+	gain, step := 1.0, 0.0
+	for {
+		f.mu.Lock()
+		if f.started.IsZero() {
+			f.started = time.Now()
+		}
+		fading := f.fading
+		ahead := f.written - durationToFrames(time.Since(f.started), format.SampleRate)
+		f.mu.Unlock()
+
+		if fading && step == 0 {
+			step = 1 / (float64(format.SampleRate) * f.duration.Seconds())
+		}
+		if fading && gain == 0 {
+			return nil
+		}
+		if ahead > leadFrames {
+			time.Sleep(min(framesToDuration(ahead-leadFrames, format.SampleRate), nativePeriod))
+			continue
+		}
+
+		n, readErr := io.ReadFull(f.stream, buf)
+		n -= n % frameSize
+		if n > 0 {
+			if fading {
+				gain = applyGain(buf[:n], format, gain, 0, step)
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return err
+			}
+			f.mu.Lock()
+			f.written += int64(n / frameSize)
+			f.mu.Unlock()
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// fadeOut ramps the rest of the audio down to silence over the fade-out and
+// returns once the command has had the time to play it
+func (f *commandFeed) fadeOut() {
+	f.mu.Lock()
+	f.fading = true
+	f.mu.Unlock()
+
+	select {
+	case <-f.done:
+	case <-time.After(f.duration + commandFeedLead + time.Second):
+		return
+	}
+
+	f.mu.Lock()
+	started, written := f.started, f.written
+	f.mu.Unlock()
+	if started.IsZero() {
+		return
+	}
+	// Let the command catch up with what it was given, the ramp included
+	remaining := framesToDuration(written, f.stream.Format().SampleRate) - time.Since(started)
+	if remaining > 0 {
+		time.Sleep(remaining)
+	}
+}
+
+// writeStreamWAV writes stream to w as a WAV file, with exact sizes in the
+// header when the stream length is known
+func writeStreamWAV(w io.Writer, stream audio.Stream) error {
+	format := stream.Format()
+	var err error
+	if total := stream.TotalFrames(); total >= 0 {
+		err = audio.WriteWAVHeader(w, format, uint32(total*int64(format.BlockAlign())))
+	} else {
+		err = audio.WriteStreamingWAVHeader(w, format)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, stream)
+	return err
+}
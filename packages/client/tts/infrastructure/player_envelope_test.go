@@ -0,0 +1,58 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+)
+
+func TestCommandFeedFadesOutWhenInterrupted(t *testing.T) {
+	format := audio.Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	frames := int(durationToFrames(5*time.Second, format.SampleRate))
+	pcm := make([]byte, frames*format.BlockAlign())
+	for i := 0; i < frames; i++ {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(int16(10000)))
+	}
+	var wav bytes.Buffer
+	if err := (&audio.Buffer{Format: format, Data: pcm}).WriteWAV(&wav); err != nil {
+		t.Fatal(err)
+	}
+	stream, err := audio.NewStream(&wav)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feed := newCommandFeed(stream, 100*time.Millisecond)
+	var out bytes.Buffer
+	written := make(chan error, 1)
+	go func() { written <- feed.writeTo(&out) }()
+
+	time.Sleep(300 * time.Millisecond)
+	start := time.Now()
+	feed.fadeOut()
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("fadeOut() returned after %v, want about the fade-out and the lead", elapsed)
+	}
+	if err := <-written; err != nil {
+		t.Fatalf("writeTo() error = %v", err)
+	}
+
+	played, err := audio.NewStream(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(played)
+	if len(data) == 0 || len(data) >= len(pcm) {
+		t.Fatalf("wrote %d bytes of %d, want the audio cut short", len(data), len(pcm))
+	}
+	if first := int16(binary.LittleEndian.Uint16(data)); first != 10000 {
+		t.Errorf("first sample = %d, want the audio unchanged before the interruption", first)
+	}
+	if last := int16(binary.LittleEndian.Uint16(data[len(data)-2:])); last > 100 {
+		t.Errorf("last sample = %d, want the audio faded to silence", last)
+	}
+}
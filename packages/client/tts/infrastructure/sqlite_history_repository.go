@@ -0,0 +1,571 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+
+	_ "modernc.org/sqlite" // Pure-Go SQLite driver, registered as "sqlite"
+)
+
+// sqliteHistorySchema creates the history tables. history_fts is a trigram
+// full-text index over the text column, kept in sync by triggers, so substring
+// searches do not scan every record (Japanese text has no word boundaries).
+const sqliteHistorySchema = `
+CREATE TABLE IF NOT EXISTS history (
+	id              INTEGER PRIMARY KEY,
+	internal_uuid   TEXT NOT NULL,
+	request         TEXT NOT NULL,
+	file_path       TEXT NOT NULL,
+	file_format     TEXT NOT NULL,
+	file_size_bytes INTEGER NOT NULL,
+	created_at      INTEGER NOT NULL,
+	text            TEXT NOT NULL,
+	model_uuid      TEXT NOT NULL,
+	credits         REAL
+);
+CREATE INDEX IF NOT EXISTS idx_history_model_uuid ON history (model_uuid, created_at);
+CREATE INDEX IF NOT EXISTS idx_history_created_at ON history (created_at);
+
+CREATE TABLE IF NOT EXISTS id_counter (
+	name    TEXT PRIMARY KEY,
+	next_id INTEGER NOT NULL
+);
+INSERT OR IGNORE INTO id_counter (name, next_id) VALUES ('history', 1);
+
+CREATE VIRTUAL TABLE IF NOT EXISTS history_fts USING fts5 (
+	text, content='history', content_rowid='id', tokenize='trigram'
+);
+CREATE TRIGGER IF NOT EXISTS history_fts_insert AFTER INSERT ON history BEGIN
+	INSERT INTO history_fts (rowid, text) VALUES (new.id, new.text);
+END;
+CREATE TRIGGER IF NOT EXISTS history_fts_delete AFTER DELETE ON history BEGIN
+	INSERT INTO history_fts (history_fts, rowid, text) VALUES ('delete', old.id, old.text);
+END;
+CREATE TRIGGER IF NOT EXISTS history_fts_update AFTER UPDATE OF text ON history BEGIN
+	INSERT INTO history_fts (history_fts, rowid, text) VALUES ('delete', old.id, old.text);
+	INSERT INTO history_fts (rowid, text) VALUES (new.id, new.text);
+END;
+`
+
+// sqliteHistoryMigrations evolve the schema created by sqliteHistorySchema.
+// Migration i brings PRAGMA user_version from i to i+1; append only.
+var sqliteHistoryMigrations = []string{
+	`ALTER TABLE history ADD COLUMN parent_id INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE history ADD COLUMN script_session TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE history ADD COLUMN project TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE history ADD COLUMN profile TEXT NOT NULL DEFAULT ''`,
+	// A JSON array, queried with json_each
+	`ALTER TABLE history ADD COLUMN tags TEXT NOT NULL DEFAULT '[]'`,
+}
+
+const historyColumns = `id, internal_uuid, request, file_path, file_format, file_size_bytes, created_at, text, model_uuid, credits, parent_id, script_session, project, profile, tags`
+
+// SQLiteHistoryRepository implements TTSHistoryRepository on an embedded SQLite
+// database, which is safe to share between concurrent CLI and MCP processes
+type SQLiteHistoryRepository struct {
+	basePath string
+	db       *sql.DB
+}
+
+// NewSQLiteHistoryRepository opens (or creates) history.db in basePath. Records
+// of an existing file-based store in the same directory are migrated once.
+func NewSQLiteHistoryRepository(basePath string) (*SQLiteHistoryRepository, error) {
+	if err := os.MkdirAll(filepath.Join(basePath, "audio"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// WAL lets readers proceed while another process writes; writers wait on
+	// the busy timeout instead of failing with SQLITE_BUSY
+	dsn := (&url.URL{
+		Scheme:   "file",
+		Path:     filepath.ToSlash(filepath.Join(basePath, "history.db")),
+		RawQuery: "_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate",
+	}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+
+	r := &SQLiteHistoryRepository{basePath: basePath, db: db}
+	if _, err := db.Exec(sqliteHistorySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	if err := r.migrateSchema(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to upgrade history database: %w", err)
+	}
+	if _, err := r.MigrateFromFileStore(context.Background()); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// Close closes the underlying database
+func (r *SQLiteHistoryRepository) Close() error {
+	return r.db.Close()
+}
+
+// migrateSchema applies the migrations newer than the database's user_version
+func (r *SQLiteHistoryRepository) migrateSchema(ctx context.Context) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		var version int
+		if err := tx.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version); err != nil {
+			return err
+		}
+		for ; version < len(sqliteHistoryMigrations); version++ {
+			if _, err := tx.ExecContext(ctx, sqliteHistoryMigrations[version]); err != nil {
+				return err
+			}
+		}
+		// PRAGMA does not accept bound parameters
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, version))
+		return err
+	})
+}
+
+// MigrateFromFileStore imports metadata.json and counter.json written by
+// FileHistoryRepository in the same directory and renames them with a
+// .migrated suffix, so the import runs only once. Records keep their IDs
+// unless the ID is already taken in the database. It returns the number of
+// imported records.
+func (r *SQLiteHistoryRepository) MigrateFromFileStore(ctx context.Context) (int, error) {
+	fileRepo := NewFileHistoryRepository(r.basePath)
+	metadataPath := fileRepo.getMetadataPath()
+	counterPath := fileRepo.getCounterPath()
+	if _, err := os.Stat(metadataPath); os.IsNotExist(err) {
+		return 0, nil
+	}
+
+	// Hold the file store lock so a process still on the file backend cannot
+	// write records that would be lost by the rename below
+	var imported int
+	err := fileRepo.withLock(true, func() error {
+		metadata, err := fileRepo.loadOrRecoverMetadata()
+		if err != nil {
+			return fmt.Errorf("failed to read file history for migration: %w", err)
+		}
+		counter, err := fileRepo.loadCounter()
+		if err != nil {
+			return fmt.Errorf("failed to read file history for migration: %w", err)
+		}
+
+		err = r.withTx(ctx, func(tx *sql.Tx) error {
+			for _, record := range metadata.Records {
+				var exists bool
+				if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM history WHERE id = ?)`, record.ID).Scan(&exists); err != nil {
+					return err
+				}
+				if exists || record.ID <= 0 {
+					record.ID = 0
+				}
+				if err := r.insert(ctx, tx, record); err != nil {
+					return err
+				}
+			}
+			_, err := tx.ExecContext(ctx, `UPDATE id_counter SET next_id = MAX(next_id, ?) WHERE name = 'history'`, counter.NextID)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to migrate file history: %w", err)
+		}
+
+		for _, path := range []string{metadataPath, counterPath} {
+			if err := os.Rename(path, path+".migrated"); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to retire migrated file %s: %w", path, err)
+			}
+		}
+		imported = len(metadata.Records)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return imported, nil
+}
+
+// withTx runs fn in a write transaction
+func (r *SQLiteHistoryRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// allocateID reserves the next sequential ID inside tx
+func (r *SQLiteHistoryRepository) allocateID(ctx context.Context, tx *sql.Tx) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx,
+		`UPDATE id_counter SET next_id = next_id + 1 WHERE name = 'history' RETURNING next_id - 1`).Scan(&id)
+	return id, err
+}
+
+// insert stores history inside tx, allocating an ID when it has none
+func (r *SQLiteHistoryRepository) insert(ctx context.Context, tx *sql.Tx, history *domain.TTSHistory) error {
+	if history.ID == 0 {
+		id, err := r.allocateID(ctx, tx)
+		if err != nil {
+			return err
+		}
+		history.ID = id
+	} else if _, err := tx.ExecContext(ctx,
+		`UPDATE id_counter SET next_id = MAX(next_id, ? + 1) WHERE name = 'history'`, history.ID); err != nil {
+		return err
+	}
+
+	if history.InternalUUID == "" {
+		history.InternalUUID = uuid.New().String()
+	}
+	if history.CreatedAt.IsZero() {
+		history.CreatedAt = time.Now()
+	}
+
+	request, err := json.Marshal(history.Request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	tags := []string{}
+	if history.Tags != nil {
+		tags = history.Tags
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO history (`+historyColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		history.ID, history.InternalUUID, string(request), history.FilePath, history.FileFormat,
+		history.FileSizeBytes, history.CreatedAt.UnixNano(), history.Text, history.ModelUUID, history.Credits, history.ParentID, history.ScriptSession,
+		history.Project, history.Profile, string(tagsJSON))
+	return err
+}
+
+// GetNextID reserves and returns the next available sequential ID
+func (r *SQLiteHistoryRepository) GetNextID(ctx context.Context) (int, error) {
+	var id int
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		id, err = r.allocateID(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate history ID: %w", err)
+	}
+	return id, nil
+}
+
+// Save stores a new history record and returns the assigned ID
+func (r *SQLiteHistoryRepository) Save(ctx context.Context, history *domain.TTSHistory) (int, error) {
+	if err := r.withTx(ctx, func(tx *sql.Tx) error {
+		return r.insert(ctx, tx, history)
+	}); err != nil {
+		return 0, fmt.Errorf("failed to save history record: %w", err)
+	}
+	return history.ID, nil
+}
+
+// GetByID retrieves a history record by its sequential ID
+func (r *SQLiteHistoryRepository) GetByID(ctx context.Context, id int) (*domain.TTSHistory, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+historyColumns+` FROM history WHERE id = ?`, id)
+	history, err := scanHistory(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("history record with ID %d not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history record: %w", err)
+	}
+	return history, nil
+}
+
+// List retrieves history records based on search criteria
+func (r *SQLiteHistoryRepository) List(ctx context.Context, request *domain.TTSHistorySearchRequest) (*domain.TTSHistoryListResponse, error) {
+	where, args := historyFilter(request)
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM history`+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count history records: %w", err)
+	}
+
+	offset := request.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	limit := request.Limit
+	if limit < 0 {
+		limit = 0
+	}
+
+	query := `SELECT ` + historyColumns + ` FROM history` + where + historyOrder(request) + ` LIMIT ? OFFSET ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit, offset)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history records: %w", err)
+	}
+	defer rows.Close()
+
+	var histories []*domain.TTSHistory
+	for rows.Next() {
+		history, err := scanHistory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history record: %w", err)
+		}
+		histories = append(histories, history)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list history records: %w", err)
+	}
+
+	return &domain.TTSHistoryListResponse{
+		Histories: histories,
+		Total:     total,
+		Limit:     request.Limit,
+		Offset:    request.Offset,
+		HasMore:   offset+len(histories) < total,
+	}, nil
+}
+
+// historyFilter translates search criteria into a WHERE clause
+func historyFilter(request *domain.TTSHistorySearchRequest) (string, []any) {
+	var conditions []string
+	var args []any
+
+	if request.ModelUUID != nil {
+		conditions = append(conditions, `model_uuid = ?`)
+		args = append(args, *request.ModelUUID)
+	}
+	if request.ScriptSession != nil {
+		conditions = append(conditions, `script_session = ?`)
+		args = append(args, *request.ScriptSession)
+	}
+	if request.Project != nil {
+		conditions = append(conditions, `project = ?`)
+		args = append(args, *request.Project)
+	}
+	if request.Tag != nil {
+		conditions = append(conditions, `EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)`)
+		args = append(args, *request.Tag)
+	}
+	if request.TextContains != nil && *request.TextContains != "" {
+		text := *request.TextContains
+		// The trigram index answers LIKE queries of three or more characters;
+		// shorter or wildcard-containing terms fall back to a scan
+		if utf8.RuneCountInString(text) >= 3 && !strings.ContainsAny(text, `%_\`) {
+			conditions = append(conditions, `id IN (SELECT rowid FROM history_fts WHERE text LIKE ?)`)
+			args = append(args, "%"+text+"%")
+		} else {
+			conditions = append(conditions, `text LIKE ? ESCAPE '\'`)
+			args = append(args, "%"+escapeLike(text)+"%")
+		}
+	}
+	if request.StartDate != nil {
+		conditions = append(conditions, `created_at >= ?`)
+		args = append(args, request.StartDate.UnixNano())
+	}
+	if request.EndDate != nil {
+		conditions = append(conditions, `created_at <= ?`)
+		args = append(args, request.EndDate.UnixNano())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return ` WHERE ` + strings.Join(conditions, ` AND `), args
+}
+
+// historyOrder translates the sort options into an ORDER BY clause
+func historyOrder(request *domain.TTSHistorySearchRequest) string {
+	column := "id"
+	switch request.SortBy {
+	case "created_at":
+		column = "created_at"
+	case "text":
+		column = "text COLLATE NOCASE"
+	}
+
+	direction := "DESC"
+	if request.SortOrder == "asc" {
+		direction = "ASC"
+	}
+	return ` ORDER BY ` + column + ` ` + direction + `, id ` + direction
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanHistory(row rowScanner) (*domain.TTSHistory, error) {
+	var history domain.TTSHistory
+	var request string
+	var createdAt int64
+	var credits sql.NullFloat64
+	var tags string
+
+	if err := row.Scan(&history.ID, &history.InternalUUID, &request, &history.FilePath, &history.FileFormat,
+		&history.FileSizeBytes, &createdAt, &history.Text, &history.ModelUUID, &credits, &history.ParentID, &history.ScriptSession,
+		&history.Project, &history.Profile, &tags); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(tags), &history.Tags); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+	}
+	if len(history.Tags) == 0 {
+		history.Tags = nil
+	}
+	if request != "" && request != "null" {
+		history.Request = &domain.TTSRequest{}
+		if err := json.Unmarshal([]byte(request), history.Request); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal request: %w", err)
+		}
+	}
+	history.CreatedAt = time.Unix(0, createdAt)
+	if credits.Valid {
+		history.Credits = &credits.Float64
+	}
+	return &history, nil
+}
+
+// Delete removes a history record by ID (including associated files)
+func (r *SQLiteHistoryRepository) Delete(ctx context.Context, id int) error {
+	var filePath string
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(ctx, `DELETE FROM history WHERE id = ? RETURNING file_path`, id).Scan(&filePath)
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("history record with ID %d not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete history record: %w", err)
+	}
+
+	// The record is gone either way; a leftover audio file is harmless
+	if filePath != "" {
+		os.Remove(filePath)
+	}
+	return nil
+}
+
+// DeleteMultiple removes multiple history records by IDs
+func (r *SQLiteHistoryRepository) DeleteMultiple(ctx context.Context, ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var filePaths []string
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		for _, id := range ids {
+			var filePath string
+			err := tx.QueryRowContext(ctx, `DELETE FROM history WHERE id = ? RETURNING file_path`, id).Scan(&filePath)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if filePath != "" {
+				filePaths = append(filePaths, filePath)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete history records: %w", err)
+	}
+
+	for _, path := range filePaths {
+		os.Remove(path)
+	}
+	return nil
+}
+
+// Clear removes all history records and audio files and resets the ID counter
+func (r *SQLiteHistoryRepository) Clear(ctx context.Context) error {
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM history`); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, `UPDATE id_counter SET next_id = 1 WHERE name = 'history'`)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to clear history: %w", err)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(r.basePath, "audio"))
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			os.Remove(filepath.Join(r.basePath, "audio", entry.Name()))
+		}
+	}
+	return nil
+}
+
+// Count returns the total number of history records
+func (r *SQLiteHistoryRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM history`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count history records: %w", err)
+	}
+	return count, nil
+}
+
+// Cleanup removes records older than maxAge and the oldest records beyond maxCount
+func (r *SQLiteHistoryRepository) Cleanup(ctx context.Context, maxCount int, maxAge *time.Duration) error {
+	var conditions []string
+	var args []any
+	if maxAge != nil {
+		conditions = append(conditions, `created_at < ?`)
+		args = append(args, time.Now().Add(-*maxAge).UnixNano())
+	}
+	if maxCount > 0 {
+		conditions = append(conditions, `id NOT IN (SELECT id FROM history ORDER BY created_at DESC, id DESC LIMIT ?)`)
+		args = append(args, maxCount)
+	}
+	if len(conditions) == 0 {
+		return nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM history WHERE `+strings.Join(conditions, ` OR `), args...)
+	if err != nil {
+		return fmt.Errorf("failed to find expired history records: %w", err)
+	}
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	return r.DeleteMultiple(ctx, ids)
+}
@@ -0,0 +1,300 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+func newTestSQLiteRepository(t *testing.T, basePath string) *SQLiteHistoryRepository {
+	t.Helper()
+	repo, err := NewSQLiteHistoryRepository(basePath)
+	if err != nil {
+		t.Fatalf("NewSQLiteHistoryRepository() error = %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func testHistory(text, model string, createdAt time.Time) *domain.TTSHistory {
+	credits := 0.5
+	return &domain.TTSHistory{
+		Request:       domain.NewTTSRequestBuilder(model, text).WithOutputFormat(domain.OutputFormatMP3).Build(),
+		FilePath:      "/tmp/" + text + ".mp3",
+		FileFormat:    "mp3",
+		FileSizeBytes: 100,
+		CreatedAt:     createdAt,
+		Text:          text,
+		ModelUUID:     model,
+		Credits:       &credits,
+	}
+}
+
+func TestSQLiteHistoryRepositorySaveAndGet(t *testing.T) {
+	repo := newTestSQLiteRepository(t, t.TempDir())
+	ctx := context.Background()
+
+	history := testHistory("こんにちは", "model-a", time.Now())
+	id, err := repo.Save(ctx, history)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if id != 1 {
+		t.Errorf("first ID = %d, want 1", id)
+	}
+
+	got, err := repo.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if got.Text != history.Text || got.InternalUUID == "" || !got.CreatedAt.Equal(history.CreatedAt) {
+		t.Errorf("GetByID() = %+v", got)
+	}
+	if got.Request == nil || *got.Request.OutputFormat != domain.OutputFormatMP3 {
+		t.Errorf("request was not round-tripped: %+v", got.Request)
+	}
+	if got.Credits == nil || *got.Credits != 0.5 {
+		t.Errorf("Credits = %v, want 0.5", got.Credits)
+	}
+
+	if _, err := repo.GetByID(ctx, 42); err == nil {
+		t.Error("expected an error for a missing record")
+	}
+}
+
+func TestSQLiteHistoryRepositoryList(t *testing.T) {
+	repo := newTestSQLiteRepository(t, t.TempDir())
+	ctx := context.Background()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, h := range []*domain.TTSHistory{
+		testHistory("おはようございます", "model-a", base),
+		testHistory("こんにちは世界", "model-b", base.Add(time.Hour)),
+		testHistory("こんばんは世界", "model-a", base.Add(2*time.Hour)),
+		testHistory("Hello World", "model-a", base.Add(3*time.Hour)),
+	} {
+		if _, err := repo.Save(ctx, h); err != nil {
+			t.Fatalf("Save(%d) error = %v", i, err)
+		}
+	}
+
+	str := func(s string) *string { return &s }
+	at := func(d time.Duration) *time.Time { t := base.Add(d); return &t }
+
+	tests := []struct {
+		name    string
+		request *domain.TTSHistorySearchRequest
+		want    []int
+		total   int
+		hasMore bool
+	}{
+		{"default order", domain.NewTTSHistorySearchRequest().Build(), []int{4, 3, 2, 1}, 4, false},
+		{"pagination", domain.NewTTSHistorySearchRequest().WithLimit(2).WithOffset(1).Build(), []int{3, 2}, 4, true},
+		{"model", domain.NewTTSHistorySearchRequest().WithModelUUID("model-a").WithSorting("created_at", "asc").Build(), []int{1, 3, 4}, 3, false},
+		{"indexed text search", &domain.TTSHistorySearchRequest{Limit: 10, TextContains: str("は世界")}, []int{3, 2}, 2, false},
+		{"short text search", &domain.TTSHistorySearchRequest{Limit: 10, TextContains: str("世界")}, []int{3, 2}, 2, false},
+		{"case insensitive", &domain.TTSHistorySearchRequest{Limit: 10, TextContains: str("hello")}, []int{4}, 1, false},
+		{"date range", &domain.TTSHistorySearchRequest{Limit: 10, StartDate: at(30 * time.Minute), EndDate: at(2 * time.Hour)}, []int{3, 2}, 2, false},
+		{"text sort", &domain.TTSHistorySearchRequest{Limit: 1, SortBy: "text", SortOrder: "asc"}, []int{4}, 4, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := repo.List(ctx, tt.request)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if got := idsOf(response); !equalInts(got, tt.want) {
+				t.Errorf("List() IDs = %v, want %v", got, tt.want)
+			}
+			if response.Total != tt.total || response.HasMore != tt.hasMore {
+				t.Errorf("Total/HasMore = %d/%v, want %d/%v", response.Total, response.HasMore, tt.total, tt.hasMore)
+			}
+		})
+	}
+}
+
+func equalInts(a, b []int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+func TestSQLiteHistoryRepositoryConcurrentSaves(t *testing.T) {
+	dir := t.TempDir()
+	// Two repositories on the same database stand in for two CLI processes
+	repos := []*SQLiteHistoryRepository{newTestSQLiteRepository(t, dir), newTestSQLiteRepository(t, dir)}
+	ctx := context.Background()
+
+	const perRepo = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*perRepo)
+	for _, repo := range repos {
+		for i := 0; i < perRepo; i++ {
+			wg.Add(1)
+			go func(repo *SQLiteHistoryRepository) {
+				defer wg.Done()
+				if _, err := repo.Save(ctx, testHistory("text", "model", time.Now())); err != nil {
+					errs <- err
+				}
+			}(repo)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent Save() error = %v", err)
+	}
+
+	count, _ := repos[0].Count(ctx)
+	if count != 2*perRepo {
+		t.Errorf("Count() = %d, want %d", count, 2*perRepo)
+	}
+	next, _ := repos[1].GetNextID(ctx)
+	if next != 2*perRepo+1 {
+		t.Errorf("GetNextID() = %d, want %d", next, 2*perRepo+1)
+	}
+}
+
+func TestSQLiteHistoryRepositoryDeleteAndCleanup(t *testing.T) {
+	dir := t.TempDir()
+	repo := newTestSQLiteRepository(t, dir)
+	ctx := context.Background()
+
+	audioFile := filepath.Join(dir, "audio", "1.mp3")
+	os.WriteFile(audioFile, []byte("audio"), 0644)
+	first := testHistory("one", "m", time.Now().Add(-48*time.Hour))
+	first.FilePath = audioFile
+	repo.Save(ctx, first)
+	for _, text := range []string{"two", "three", "four"} {
+		repo.Save(ctx, testHistory(text, "m", time.Now()))
+	}
+
+	if err := repo.Delete(ctx, 1); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := os.Stat(audioFile); !os.IsNotExist(err) {
+		t.Error("Delete() must remove the audio file")
+	}
+	if err := repo.Delete(ctx, 1); err == nil {
+		t.Error("deleting a missing record must fail")
+	}
+
+	if err := repo.Cleanup(ctx, 2, nil); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	response, _ := repo.List(ctx, domain.NewTTSHistorySearchRequest().Build())
+	if !equalInts(idsOf(response), []int{4, 3}) {
+		t.Errorf("after Cleanup() IDs = %v, want [4 3]", idsOf(response))
+	}
+
+	if err := repo.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if id, _ := repo.Save(ctx, testHistory("again", "m", time.Now())); id != 1 {
+		t.Errorf("ID after Clear() = %d, want 1", id)
+	}
+}
+
+func idsOf(response *domain.TTSHistoryListResponse) []int {
+	var out []int
+	for _, h := range response.Histories {
+		out = append(out, h.ID)
+	}
+	return out
+}
+
+func TestSQLiteHistoryRepositoryMigratesFileStore(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	fileRepo := NewFileHistoryRepository(dir)
+	for _, text := range []string{"一", "二", "三"} {
+		if _, err := fileRepo.Save(ctx, testHistory(text, "m", time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fileRepo.Delete(ctx, 2)
+
+	repo := newTestSQLiteRepository(t, dir)
+	response, err := repo.List(ctx, domain.NewTTSHistorySearchRequest().Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalInts(idsOf(response), []int{3, 1}) || response.Histories[0].Text != "三" {
+		t.Errorf("migrated records = %v", idsOf(response))
+	}
+	if next, _ := repo.GetNextID(ctx); next != 4 {
+		t.Errorf("GetNextID() after migration = %d, want 4", next)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "metadata.json")); !os.IsNotExist(err) {
+		t.Error("metadata.json must be retired after migration")
+	}
+
+	// Opening again must not import the records twice
+	again := newTestSQLiteRepository(t, dir)
+	if count, _ := again.Count(ctx); count != 2 {
+		t.Errorf("Count() after reopening = %d, want 2", count)
+	}
+}
+
+func TestSQLiteHistoryRepositoryUpgradesSchema(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	// A database created before any migration existed
+	db, err := sql.Open("sqlite", filepath.Join(dir, "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(sqliteHistorySchema); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec(`INSERT INTO history (id, internal_uuid, request, file_path, file_format, file_size_bytes, created_at, text, model_uuid)
+		VALUES (1, 'uuid', 'null', '', 'wav', 0, 0, 'old', 'm')`)
+	db.Exec(`UPDATE id_counter SET next_id = 2`)
+	db.Close()
+
+	repo := newTestSQLiteRepository(t, dir)
+	if old, err := repo.GetByID(ctx, 1); err != nil || old.ParentID != 0 || old.Tags != nil {
+		t.Fatalf("GetByID(1) = %+v, %v", old, err)
+	}
+	child := testHistory("new", "m", time.Now())
+	child.ParentID = 1
+	child.ScriptSession = "script-1"
+	child.Project = "podcast"
+	child.Tags = []string{"ep1", "intro"}
+	id, err := repo.Save(ctx, child)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.GetByID(ctx, id); got.ParentID != 1 || got.ScriptSession != "script-1" {
+		t.Errorf("ParentID/ScriptSession = %d/%q, want 1/script-1", got.ParentID, got.ScriptSession)
+	}
+	response, err := repo.List(ctx, domain.NewTTSHistorySearchRequest().WithScriptSession("script-1").Build())
+	if err != nil || !equalInts(idsOf(response), []int{id}) {
+		t.Errorf("List() by script session = %v, %v", idsOf(response), err)
+	}
+	if got := response.Histories[0]; got.Project != "podcast" || !got.HasTag("intro") {
+		t.Errorf("labels = %q/%v, want podcast/[ep1 intro]", got.Project, got.Tags)
+	}
+	response, err = repo.List(ctx, domain.NewTTSHistorySearchRequest().WithProject("podcast").WithTag("intro").Build())
+	if err != nil || !equalInts(idsOf(response), []int{id}) {
+		t.Errorf("List() by project and tag = %v, %v", idsOf(response), err)
+	}
+	response, err = repo.List(ctx, domain.NewTTSHistorySearchRequest().WithTag("outro").Build())
+	if err != nil || response.Total != 0 {
+		t.Errorf("List() by unknown tag = %v, %v", idsOf(response), err)
+	}
+
+	// Reopening must not re-run migrations
+	newTestSQLiteRepository(t, dir)
+}
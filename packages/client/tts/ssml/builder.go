@@ -0,0 +1,122 @@
+package ssml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Prosody holds the attributes of a <prosody> element; empty fields are
+// omitted. Rate takes x-slow to x-fast, a percentage ("120%") or a
+// multiplier ("1.2"); Pitch takes x-low to x-high or a relative change
+// ("+10%", "-2st"); Volume takes silent, x-soft to x-loud or a relative
+// change ("+6dB").
+type Prosody struct {
+	Rate   string
+	Pitch  string
+	Volume string
+}
+
+// Builder assembles SSML markup with method chaining. Text is escaped.
+// Elements opened with Prosody and Emphasis wrap everything added until the
+// matching End; Build closes any that are still open.
+type Builder struct {
+	markup strings.Builder
+	open   []string
+}
+
+// NewBuilder creates a new SSML builder
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Text adds plain text
+func (b *Builder) Text(text string) *Builder {
+	xml.EscapeText(&b.markup, []byte(text))
+	return b
+}
+
+// Break adds a pause of the given duration
+func (b *Builder) Break(d time.Duration) *Builder {
+	if d < 0 {
+		d = 0
+	}
+	value := fmt.Sprintf("%dms", d.Milliseconds())
+	if d%time.Second == 0 {
+		value = fmt.Sprintf("%ds", d/time.Second)
+	}
+	return b.element("break", true, "time", value)
+}
+
+// BreakStrength adds a pause of the given strength (StrengthNone to StrengthXStrong)
+func (b *Builder) BreakStrength(strength string) *Builder {
+	return b.element("break", true, "strength", strength)
+}
+
+// Prosody opens a <prosody> element
+func (b *Builder) Prosody(p Prosody) *Builder {
+	return b.element("prosody", false, "rate", p.Rate, "pitch", p.Pitch, "volume", p.Volume)
+}
+
+// Emphasis opens an <emphasis> element with the given level (EmphasisStrong,
+// EmphasisModerate, EmphasisNone or EmphasisReduced)
+func (b *Builder) Emphasis(level string) *Builder {
+	return b.element("emphasis", false, "level", level)
+}
+
+// End closes the innermost element opened with Prosody or Emphasis
+func (b *Builder) End() *Builder {
+	if len(b.open) > 0 {
+		fmt.Fprintf(&b.markup, "</%s>", b.open[len(b.open)-1])
+		b.open = b.open[:len(b.open)-1]
+	}
+	return b
+}
+
+// Sub adds text that is read as alias, e.g. Sub("だぶりゅーえいちおー", "WHO")
+func (b *Builder) Sub(alias, text string) *Builder {
+	b.element("sub", false, "alias", alias)
+	return b.Text(text).End()
+}
+
+// Phoneme adds text that is read with the given pronunciation
+func (b *Builder) Phoneme(ph, text string) *Builder {
+	b.element("phoneme", false, "ph", ph)
+	return b.Text(text).End()
+}
+
+// Build closes the open elements and returns the markup, without a <speak>
+// root. The markup is checked strictly, warnings included, so invalid
+// attribute values are reported here rather than by the API.
+func (b *Builder) Build() (string, error) {
+	for len(b.open) > 0 {
+		b.End()
+	}
+	markup := b.markup.String()
+	if errs := Check(markup); len(errs) > 0 {
+		return "", errs
+	}
+	return markup, nil
+}
+
+// element writes a start tag with the non-empty attributes of the given
+// name/value pairs, either self-closing or left open for End
+func (b *Builder) element(name string, empty bool, attributes ...string) *Builder {
+	b.markup.WriteString("<" + name)
+	for i := 0; i+1 < len(attributes); i += 2 {
+		if attributes[i+1] == "" {
+			continue
+		}
+		fmt.Fprintf(&b.markup, ` %s="`, attributes[i])
+		xml.EscapeText(&b.markup, []byte(attributes[i+1]))
+		b.markup.WriteString(`"`)
+	}
+	if empty {
+		b.markup.WriteString("/>")
+		return b
+	}
+	b.markup.WriteString(">")
+	b.open = append(b.open, name)
+	return b
+}
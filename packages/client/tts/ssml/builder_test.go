@@ -0,0 +1,39 @@
+package ssml
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	markup, err := NewBuilder().
+		Text("R&D は ").
+		Sub("だぶりゅーえいちおー", "WHO").
+		Break(1500*time.Millisecond).
+		Prosody(Prosody{Rate: "1.2", Pitch: "+5%"}).
+		Text("速く").
+		Emphasis(EmphasisStrong).
+		Text("強く").
+		End().
+		Break(2*time.Second).
+		BreakStrength(StrengthWeak).
+		Phoneme("ニホン", "日本").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	want := `R&amp;D は <sub alias="だぶりゅーえいちおー">WHO</sub><break time="1500ms"/>` +
+		`<prosody rate="1.2" pitch="+5%">速く<emphasis level="strong">強く</emphasis><break time="2s"/>` +
+		`<break strength="weak"/><phoneme ph="ニホン">日本</phoneme></prosody>`
+	if markup != want {
+		t.Errorf("Build() =\n%s\nwant\n%s", markup, want)
+	}
+
+	if _, err := NewBuilder().Prosody(Prosody{Rate: "very fast"}).Text("あ").Build(); err == nil {
+		t.Error("Build() must reject invalid attribute values")
+	}
+	if markup, err := NewBuilder().Text(`"quoted" <tag>`).Build(); err != nil || markup != "&#34;quoted&#34; &lt;tag&gt;" {
+		t.Errorf("Build() = %q, %v", markup, err)
+	}
+}
@@ -0,0 +1,311 @@
+// Package ssml builds and validates the SSML subset accepted by the Aivis
+// Cloud API when a request sets use_ssml: <speak>, <break>, <prosody>,
+// <emphasis>, <sub> and <phoneme>. Validation runs client-side so that
+// malformed markup is reported with its line and column before it is sent.
+// Markup outside the documented subset is only a warning, since the API may
+// accept more than it documents.
+package ssml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Break strengths
+const (
+	StrengthNone    = "none"
+	StrengthXWeak   = "x-weak"
+	StrengthWeak    = "weak"
+	StrengthMedium  = "medium"
+	StrengthStrong  = "strong"
+	StrengthXStrong = "x-strong"
+)
+
+// Emphasis levels
+const (
+	EmphasisStrong   = "strong"
+	EmphasisModerate = "moderate"
+	EmphasisNone     = "none"
+	EmphasisReduced  = "reduced"
+)
+
+var (
+	timePattern    = regexp.MustCompile(`^\d+(\.\d+)?(ms|s)$`)
+	percentPattern = regexp.MustCompile(`^[+-]?\d+(\.\d+)?%$`)
+	numberPattern  = regexp.MustCompile(`^\d+(\.\d+)?$`)
+	pitchPattern   = regexp.MustCompile(`^[+-]\d+(\.\d+)?(%|st|Hz)$`)
+	volumePattern  = regexp.MustCompile(`^[+-]\d+(\.\d+)?dB$`)
+)
+
+// element describes a supported element: its attributes with their
+// validators, and whether it may contain child elements or text
+type element struct {
+	attributes map[string]func(string) error
+	empty      bool // no content at all
+	textOnly   bool // text but no child elements
+	required   []string
+}
+
+var elements = map[string]element{
+	"speak": {attributes: map[string]func(string) error{"version": anyValue, "xmlns": anyValue}},
+	"break": {
+		attributes: map[string]func(string) error{
+			"time":     validateTime,
+			"strength": oneOf(StrengthNone, StrengthXWeak, StrengthWeak, StrengthMedium, StrengthStrong, StrengthXStrong),
+		},
+		empty: true,
+	},
+	"prosody": {attributes: map[string]func(string) error{
+		"rate":   keywordOr([]string{"x-slow", "slow", "medium", "fast", "x-fast", "default"}, percentPattern, numberPattern),
+		"pitch":  keywordOr([]string{"x-low", "low", "medium", "high", "x-high", "default"}, pitchPattern, percentPattern),
+		"volume": keywordOr([]string{"silent", "x-soft", "soft", "medium", "loud", "x-loud", "default"}, volumePattern, percentPattern),
+	}},
+	"emphasis": {attributes: map[string]func(string) error{
+		"level": oneOf(EmphasisStrong, EmphasisModerate, EmphasisNone, EmphasisReduced),
+	}},
+	"sub": {
+		attributes: map[string]func(string) error{"alias": anyValue},
+		textOnly:   true,
+		required:   []string{"alias"},
+	},
+	"phoneme": {
+		attributes: map[string]func(string) error{"ph": anyValue, "alphabet": anyValue},
+		textOnly:   true,
+		required:   []string{"ph"},
+	},
+}
+
+func anyValue(string) error { return nil }
+
+func oneOf(allowed ...string) func(string) error {
+	return keywordOr(allowed)
+}
+
+func keywordOr(keywords []string, patterns ...*regexp.Regexp) func(string) error {
+	return func(value string) error {
+		for _, keyword := range keywords {
+			if value == keyword {
+				return nil
+			}
+		}
+		for _, pattern := range patterns {
+			if pattern.MatchString(value) {
+				return nil
+			}
+		}
+		if len(patterns) == 0 {
+			return fmt.Errorf("must be one of %s", strings.Join(keywords, ", "))
+		}
+		return fmt.Errorf("must be %s or a relative value", strings.Join(keywords, ", "))
+	}
+}
+
+func validateTime(value string) error {
+	if !timePattern.MatchString(value) {
+		return errors.New("must be a duration such as 500ms or 1.5s")
+	}
+	return nil
+}
+
+// Error is a problem found in SSML markup. Line and Column are 1-based and
+// refer to the text as given, without the <speak> root that is added when
+// the text has none. Warning marks markup that is well-formed but outside
+// the documented subset.
+type Error struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+	Warning bool   `json:"warning,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// Errors is the list of problems found by Check or Validate
+type Errors []*Error
+
+func (e Errors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more)", e[0].Error(), len(e)-1)
+}
+
+const speakRoot = "<speak>"
+
+// Validate checks that text is well-formed SSML. Plain text and markup
+// without a <speak> root are accepted. It returns nil or an Errors value
+// listing the problems that are not warnings; use Check to also see
+// unsupported elements and attribute values.
+func Validate(text string) error {
+	var errs Errors
+	for _, e := range Check(text) {
+		if !e.Warning {
+			errs = append(errs, e)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// Check reports every problem in text: malformed markup as errors, and
+// elements, attributes and values outside the supported subset as warnings.
+// Parsing stops at the first syntax error.
+func Check(text string) Errors {
+	source := text
+	offset := 0
+	if !hasRoot(text) {
+		source = speakRoot + text + "</speak>"
+		offset = len(speakRoot)
+	}
+
+	v := &validator{decoder: xml.NewDecoder(strings.NewReader(source)), text: text, offset: offset}
+	v.decoder.Strict = true
+	v.run()
+	return v.errors
+}
+
+func hasRoot(text string) bool {
+	trimmed := strings.TrimLeft(text, " \t\r\n\ufeff")
+	return strings.HasPrefix(trimmed, "<?xml") || strings.HasPrefix(trimmed, "<speak")
+}
+
+// validator walks the token stream and collects errors
+type validator struct {
+	decoder *xml.Decoder
+	text    string
+	offset  int // bytes of the implicit root before text
+	errors  Errors
+
+	// stack holds the names of the open elements
+	stack  []string
+	rooted bool
+}
+
+// position returns the line and column, counted in characters, of the
+// decoder's current offset in the original text
+func (v *validator) position() (int, int) {
+	offset := int(v.decoder.InputOffset()) - v.offset
+	offset = min(max(offset, 0), len(v.text))
+	before := v.text[:offset]
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return strings.Count(before, "\n") + 1, utf8.RuneCountInString(before[lineStart:]) + 1
+}
+
+func (v *validator) addError(line, column int, format string, args ...any) {
+	v.errors = append(v.errors, &Error{Line: line, Column: column, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) addWarning(line, column int, format string, args ...any) {
+	v.errors = append(v.errors, &Error{Line: line, Column: column, Message: fmt.Sprintf(format, args...), Warning: true})
+}
+
+func (v *validator) run() {
+	for {
+		line, column := v.position()
+		token, err := v.decoder.Token()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			line, column = v.position()
+			var syntaxErr *xml.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				v.addError(line, column, "%s", syntaxErr.Msg)
+			} else {
+				v.addError(line, column, "%v", err)
+			}
+			return
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if len(v.stack) == 0 {
+				if v.rooted {
+					v.addError(line, column, "markup after the closing </speak>")
+				} else if t.Name.Local != "speak" {
+					v.addWarning(line, column, "the root element must be <speak>")
+				}
+				v.rooted = true
+			}
+			v.startElement(t, line, column)
+			v.stack = append(v.stack, t.Name.Local)
+		case xml.EndElement:
+			v.stack = v.stack[:len(v.stack)-1]
+		case xml.CharData:
+			if strings.TrimSpace(string(t)) == "" {
+				continue
+			}
+			if len(v.stack) == 0 {
+				v.addError(line, column, "text outside the <speak> element")
+			} else if parent := v.stack[len(v.stack)-1]; elements[parent].empty {
+				v.addWarning(line, column, "<%s> must be empty", parent)
+			}
+		case xml.ProcInst:
+			if t.Target != "xml" {
+				v.addWarning(line, column, "processing instructions are not supported")
+			}
+		case xml.Directive:
+			v.addWarning(line, column, "directives are not supported")
+		}
+	}
+}
+
+func (v *validator) startElement(t xml.StartElement, line, column int) {
+	name := t.Name.Local
+	spec, ok := elements[name]
+	if !ok {
+		v.addWarning(line, column, "unsupported element <%s>", name)
+		return
+	}
+
+	if name == "speak" && len(v.stack) > 0 {
+		v.addWarning(line, column, "<speak> must be the root element")
+	}
+	if len(v.stack) > 0 {
+		parent := v.stack[len(v.stack)-1]
+		if p := elements[parent]; p.empty || p.textOnly {
+			v.addWarning(line, column, "<%s> cannot contain <%s>", parent, name)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, attr := range t.Attr {
+		if attr.Name.Space == "xmlns" || attr.Name.Space == "http://www.w3.org/XML/1998/namespace" {
+			continue
+		}
+		validate, ok := spec.attributes[attr.Name.Local]
+		if !ok {
+			v.addWarning(line, column, "unsupported attribute %q on <%s>", attr.Name.Local, name)
+			continue
+		}
+		seen[attr.Name.Local] = true
+		if err := validate(attr.Value); err != nil {
+			v.addWarning(line, column, "invalid %s %q on <%s>: %v", attr.Name.Local, attr.Value, name, err)
+		}
+	}
+	for _, attr := range spec.required {
+		if !seen[attr] {
+			v.addWarning(line, column, "<%s> requires the %s attribute", name, attr)
+		}
+	}
+
+	switch name {
+	case "break":
+		if seen["time"] && seen["strength"] {
+			v.addWarning(line, column, "<break> takes either time or strength, not both")
+		}
+	case "prosody":
+		if len(seen) == 0 {
+			v.addWarning(line, column, "<prosody> needs at least one of rate, pitch or volume")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package ssml
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	valid := []string{
+		"こんにちは",
+		`こんにちは<break time="500ms"/>世界`,
+		`<speak version="1.1" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="ja-JP">今日は<prosody rate="120%" pitch="+2st">いい天気</prosody>。</speak>`,
+		`<?xml version="1.0"?>` + "\n" + `<speak><emphasis level="strong">はい</emphasis><break strength="weak"/></speak>`,
+		`<sub alias="だぶりゅーえいちおー">WHO</sub>と<phoneme alphabet="x-katakana" ph="ニホン">日本</phoneme>`,
+		`<prosody volume="+6dB"><prosody rate="slow">ゆっくり</prosody></prosody> &amp; <!-- comment -->`,
+	}
+	for _, text := range valid {
+		if errs := Check(text); len(errs) != 0 {
+			t.Errorf("Check(%q) = %v", text, errs)
+		}
+	}
+
+	for _, tt := range []struct {
+		text         string
+		line, column int
+		message      string
+		warning      bool
+	}{
+		{"一行目\n二行目<voice>x</voice>", 2, 4, "unsupported element <voice>", true},
+		{`あ<break time="fast"/>`, 1, 2, `invalid time "fast" on <break>: must be a duration such as 500ms or 1.5s`, true},
+		{`<break time="1s" strength="weak"/>`, 1, 1, "<break> takes either time or strength, not both", true},
+		{`<break>あ</break>`, 1, 8, "<break> must be empty", true},
+		{`<prosody>あ</prosody>`, 1, 1, "<prosody> needs at least one of rate, pitch or volume", true},
+		{`<prosody speed="1">あ</prosody>`, 1, 1, `unsupported attribute "speed" on <prosody>`, true},
+		{`<emphasis level="loud">あ</emphasis>`, 1, 1, `invalid level "loud" on <emphasis>: must be one of strong, moderate, none, reduced`, true},
+		{`<sub>WHO</sub>`, 1, 1, "<sub> requires the alias attribute", true},
+		{`<sub alias="a"><break/></sub>`, 1, 16, "<sub> cannot contain <break>", true},
+		{`<speak><speak>あ</speak></speak>`, 1, 8, "<speak> must be the root element", true},
+		{"あ\n<prosody rate=\"fast\">い</emphasis>", 2, 34, "element <prosody> closed by </emphasis>", false},
+		{"R&D", 1, 4, "invalid character entity &D (no semicolon)", false},
+		{`<speak>あ</speak>い`, 1, 17, "text outside the <speak> element", false},
+	} {
+		errs := Check(tt.text)
+		if len(errs) == 0 {
+			t.Errorf("Check(%q) found no problems", tt.text)
+			continue
+		}
+		if got := errs[0]; got.Line != tt.line || got.Column != tt.column || got.Message != tt.message || got.Warning != tt.warning {
+			t.Errorf("Check(%q) = %v (warning %v), want line %d, column %d: %s (warning %v)", tt.text, got, got.Warning, tt.line, tt.column, tt.message, tt.warning)
+		}
+	}
+
+	errs := Check(`<break time="x"/><emphasis level="y">あ</emphasis>`)
+	if len(errs) != 2 || errs.Error() != `line 1, column 1: invalid time "x" on <break>: must be a duration such as 500ms or 1.5s (and 1 more)` {
+		t.Errorf("expected both problems, got %v", errs)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// Warnings alone pass
+	if err := Validate(`<voice name="a">あ</voice><break time="soon"/>`); err != nil {
+		t.Errorf("Validate() with unsupported markup error = %v", err)
+	}
+
+	err := Validate(`<voice>あ</voice><prosody rate="fast">い`)
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Warning || errs[0].Message != "element <prosody> closed by </speak>" {
+		t.Errorf("Validate() of malformed markup = %v, want only the syntax error", err)
+	}
+}
@@ -116,3 +116,8 @@ func (a *AudioPlayerServiceAdapter) ClearQueue() {
 func (a *AudioPlayerServiceAdapter) Close() error {
 	return a.globalService.Close()
 }
+
+// PlayLongRequest plays text of any length, starting playback on the first chunk
+func (a *AudioPlayerServiceAdapter) PlayLongRequest(ctx context.Context, request *domain.PlaybackRequest, options *domain.LongTextOptions, historyFilePath string) (*domain.LongTextResult, error) {
+	return a.globalService.PlayLongRequest(ctx, request, options, historyFilePath)
+}
@@ -0,0 +1,392 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// calibrationSamples is the number of most recent billed syntheses the
+// estimate rate is calibrated from
+const calibrationSamples = 50
+
+// reservationTTL is how long a reservation counts against the budgets when
+// its synthesis is never recorded, e.g. because the process died
+const reservationTTL = time.Hour
+
+// errOverBudget aborts the ledger update of a check that does not fit
+var errOverBudget = errors.New("over budget")
+
+var ssmlMarkup = regexp.MustCompile(`<[^>]*>`)
+
+// CountCharacters returns the number of characters of text the API bills
+// for. SSML markup is not spoken and not counted.
+func CountCharacters(text string, useSSML bool) int {
+	if useSSML {
+		text = ssmlMarkup.ReplaceAllString(text, "")
+	}
+	return len([]rune(text))
+}
+
+// requestCharacters counts the billed characters of a request
+func requestCharacters(request *domain.TTSRequest) int {
+	return CountCharacters(request.Text, request.UseSSML != nil && *request.UseSSML)
+}
+
+// BudgetGuard enforces daily and monthly credit budgets. It keeps a local
+// ledger of the credits billed for every synthesis, estimates the cost of a
+// new synthesis from its length, and refuses (or asks its confirm function
+// about) syntheses that would take a period over its budget.
+//
+// The estimate of a synthesis that passes the check is reserved in the
+// ledger in the same locked update, and replaced by the billed credits when
+// the synthesis is recorded. Concurrent syntheses, in this process or
+// others sharing the ledger, therefore cannot overrun a budget together
+// that each of them fits in alone.
+type BudgetGuard struct {
+	repository   domain.BudgetRepository
+	dailyLimit   float64
+	monthlyLimit float64
+	now          func() time.Time
+
+	confirmMu sync.Mutex
+	confirm   domain.BudgetConfirmFunc
+}
+
+// NewBudgetGuard creates a budget guard. A limit of 0 leaves the period
+// unlimited. Without any limit nothing is checked or recorded, so the ledger
+// is only written once a budget is configured.
+func NewBudgetGuard(repository domain.BudgetRepository, dailyLimit, monthlyLimit float64) *BudgetGuard {
+	return &BudgetGuard{
+		repository:   repository,
+		dailyLimit:   dailyLimit,
+		monthlyLimit: monthlyLimit,
+		now:          time.Now,
+	}
+}
+
+// Enabled reports whether a daily or monthly budget is configured
+func (g *BudgetGuard) Enabled() bool {
+	return g.dailyLimit > 0 || g.monthlyLimit > 0
+}
+
+// SetConfirm sets the function asked before a synthesis exceeds a budget;
+// nil refuses such syntheses
+func (g *BudgetGuard) SetConfirm(confirm domain.BudgetConfirmFunc) {
+	g.confirmMu.Lock()
+	defer g.confirmMu.Unlock()
+	g.confirm = confirm
+}
+
+// Status returns the spending of the current day and month
+func (g *BudgetGuard) Status(ctx context.Context) (*domain.BudgetStatus, error) {
+	ledger, err := g.repository.Ledger(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.status(ledger), nil
+}
+
+// status computes the spending of the current day and month from the ledger
+func (g *BudgetGuard) status(ledger *domain.BudgetLedger) *domain.BudgetStatus {
+	now := g.now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	status := &domain.BudgetStatus{
+		Daily:      periodStatus(domain.BudgetPeriodDaily, g.dailyLimit, dayStart, dayStart.AddDate(0, 0, 1), now, ledger),
+		Monthly:    periodStatus(domain.BudgetPeriodMonthly, g.monthlyLimit, monthStart, monthStart.AddDate(0, 1, 0), now, ledger),
+		LedgerPath: g.repository.Path(),
+	}
+	status.CreditsPerCharacter, status.CalibrationSamples = calibrate(ledger.Spends)
+	return status
+}
+
+func periodStatus(period domain.BudgetPeriod, limit float64, start, end, now time.Time, ledger *domain.BudgetLedger) domain.BudgetPeriodStatus {
+	status := domain.BudgetPeriodStatus{Period: period, Limit: limit, Start: start, ResetsAt: end}
+	for _, spend := range ledger.Spends {
+		if !spend.At.Before(start) && spend.At.Before(end) {
+			status.Spent += spend.Credits
+			status.Requests++
+		}
+	}
+	for _, reservation := range ledger.Reservations {
+		if reservation.ExpiresAt.After(now) && !reservation.At.Before(start) && reservation.At.Before(end) {
+			status.Reserved += reservation.Credits
+		}
+	}
+	if limit > 0 {
+		remaining := max(limit-status.Spent-status.Reserved, 0)
+		status.Remaining = &remaining
+	}
+	return status
+}
+
+// calibrate returns the credits billed per character over the most recent
+// billed syntheses, and the number of syntheses it is based on
+func calibrate(spends []domain.BudgetSpend) (float64, int) {
+	var credits float64
+	var characters, samples int
+	for i := len(spends) - 1; i >= 0 && samples < calibrationSamples; i-- {
+		if spends[i].Estimated || spends[i].Characters <= 0 {
+			continue
+		}
+		credits += spends[i].Credits
+		characters += spends[i].Characters
+		samples++
+	}
+	if samples == 0 {
+		return domain.DefaultCreditsPerCharacter, 0
+	}
+	return credits / float64(characters), samples
+}
+
+// Estimate returns the estimated cost of synthesizing characters and the
+// budgets it would exceed
+func (g *BudgetGuard) Estimate(ctx context.Context, characters int) (*domain.BudgetEstimate, error) {
+	status, err := g.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return estimateCost(status, characters), nil
+}
+
+// estimateCost prices characters at the calibrated rate of status and lists
+// the periods the cost would take over budget
+func estimateCost(status *domain.BudgetStatus, characters int) *domain.BudgetEstimate {
+	estimate := &domain.BudgetEstimate{
+		Characters: characters,
+		Credits:    float64(characters) * status.CreditsPerCharacter,
+	}
+	for _, period := range []domain.BudgetPeriodStatus{status.Daily, status.Monthly} {
+		if period.Limited() && period.Spent+period.Reserved+estimate.Credits > period.Limit {
+			estimate.Exceeded = append(estimate.Exceeded, period)
+		}
+	}
+	return estimate
+}
+
+// Reserve checks a synthesis of characters against the budgets and, when it
+// fits or the confirm function allows it, reserves its estimated cost in
+// the ledger. It returns the reservation ID to pass to Record or Release, or
+// a *domain.BudgetExceededError. Nothing is reserved, and the ID is empty,
+// without budgets or when the context carries an approval or a reservation.
+func (g *BudgetGuard) Reserve(ctx context.Context, characters int) (string, error) {
+	if domain.BudgetApproved(ctx) || domain.BudgetReservationID(ctx) != "" || !g.Enabled() {
+		return "", nil
+	}
+
+	reservation := domain.BudgetReservation{ID: newReservationID(), At: g.now()}
+	reservation.ExpiresAt = reservation.At.Add(reservationTTL)
+	var estimate *domain.BudgetEstimate
+	err := g.repository.Update(ctx, func(ledger *domain.BudgetLedger) error {
+		estimate = estimateCost(g.status(ledger), characters)
+		if len(estimate.Exceeded) > 0 {
+			return errOverBudget
+		}
+		reservation.Credits = estimate.Credits
+		ledger.Reservations = append(ledger.Reservations, reservation)
+		return nil
+	})
+	if errors.Is(err, errOverBudget) {
+		// The ledger is not held while asking, as other processes wait on it
+		if err := g.confirmOverrun(ctx, estimate); err != nil {
+			return "", err
+		}
+		reservation.Credits = estimate.Credits
+		err = g.repository.Update(ctx, func(ledger *domain.BudgetLedger) error {
+			ledger.Reservations = append(ledger.Reservations, reservation)
+			return nil
+		})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check credit budget: %w", err)
+	}
+	return reservation.ID, nil
+}
+
+// confirmOverrun asks the confirm function whether a synthesis over budget
+// may proceed, and returns a *domain.BudgetExceededError when it may not
+func (g *BudgetGuard) confirmOverrun(ctx context.Context, estimate *domain.BudgetEstimate) error {
+	// Concurrent syntheses ask one at a time
+	g.confirmMu.Lock()
+	defer g.confirmMu.Unlock()
+	if g.confirm != nil && g.confirm(ctx, estimate) {
+		return nil
+	}
+
+	exceeded := estimate.Exceeded[0]
+	return &domain.BudgetExceededError{
+		Period:    exceeded.Period,
+		Limit:     exceeded.Limit,
+		Spent:     exceeded.Spent,
+		Reserved:  exceeded.Reserved,
+		Estimated: estimate.Credits,
+		ResetsAt:  exceeded.ResetsAt,
+	}
+}
+
+// Release drops what is left of a reservation. An empty ID does nothing.
+func (g *BudgetGuard) Release(ctx context.Context, reservation string) error {
+	if reservation == "" {
+		return nil
+	}
+	return g.repository.Update(ctx, func(ledger *domain.BudgetLedger) error {
+		ledger.Reservations = removeReservation(ledger.Reservations, reservation)
+		return nil
+	})
+}
+
+// Approve reserves the estimated cost of a synthesis of characters as a
+// whole and returns a context under which its individual requests are not
+// checked again. The requests recorded under that context draw the
+// reservation down; call release once they are done to drop the rest.
+func (g *BudgetGuard) Approve(ctx context.Context, characters int) (context.Context, func(), error) {
+	reservation, err := g.Reserve(ctx, characters)
+	if err != nil {
+		return ctx, func() {}, err
+	}
+	release := func() {}
+	if reservation != "" {
+		ctx = domain.WithBudgetReservation(ctx, reservation)
+		// A reservation that cannot be released lapses on its own
+		release = func() { g.Release(context.Background(), reservation) }
+	}
+	return domain.WithBudgetApproval(ctx), release, nil
+}
+
+// ReserveRequest reserves the estimated cost of a single synthesis of
+// characters that is made later, possibly by another process or from the
+// playback queue, and returns a context carrying the reservation. The
+// synthesis made with that context replaces the reservation with what it is
+// billed, or releases it when it fails; a synthesis that never happens
+// leaves it to lapse. Callers must not release it before then.
+func (g *BudgetGuard) ReserveRequest(ctx context.Context, characters int) (context.Context, error) {
+	reservation, err := g.Reserve(ctx, characters)
+	if err != nil {
+		return ctx, err
+	}
+	return domain.WithBudgetReservation(ctx, reservation), nil
+}
+
+// Record adds a completed synthesis to the ledger, using the billing headers
+// of the response and falling back to an estimate when they are missing. The
+// spend replaces the given reservation, or without one draws down the
+// reservation of the approval the context carries. Without budgets nothing
+// is recorded.
+func (g *BudgetGuard) Record(ctx context.Context, reservation string, request *domain.TTSRequest, billing *http.BillingInfo) error {
+	if !g.Enabled() {
+		return nil
+	}
+	spend := domain.BudgetSpend{At: g.now(), ModelUUID: request.ModelUUID}
+
+	var creditsKnown bool
+	if billing != nil {
+		if characters, err := strconv.Atoi(strings.TrimSpace(billing.CharacterCount)); err == nil {
+			spend.Characters = characters
+		}
+		if credits, err := strconv.ParseFloat(strings.TrimSpace(billing.CreditsUsed), 64); err == nil {
+			spend.Credits = credits
+			creditsKnown = true
+		}
+	}
+	if spend.Characters == 0 {
+		spend.Characters = requestCharacters(request)
+	}
+
+	var approval string
+	if domain.BudgetApproved(ctx) {
+		approval = domain.BudgetReservationID(ctx)
+	}
+	return g.repository.Update(ctx, func(ledger *domain.BudgetLedger) error {
+		if !creditsKnown {
+			spend.Credits = estimateCost(g.status(ledger), spend.Characters).Credits
+			spend.Estimated = true
+		}
+		if reservation != "" {
+			ledger.Reservations = removeReservation(ledger.Reservations, reservation)
+		} else {
+			for i := range ledger.Reservations {
+				if ledger.Reservations[i].ID == approval {
+					ledger.Reservations[i].Credits = max(ledger.Reservations[i].Credits-spend.Credits, 0)
+				}
+			}
+		}
+		ledger.Spends = append(ledger.Spends, spend)
+		return nil
+	})
+}
+
+// removeReservation returns reservations without the one with the given ID
+func removeReservation(reservations []domain.BudgetReservation, id string) []domain.BudgetReservation {
+	kept := reservations[:0]
+	for _, reservation := range reservations {
+		if reservation.ID != id {
+			kept = append(kept, reservation)
+		}
+	}
+	return kept
+}
+
+// newReservationID returns a random reservation ID
+func newReservationID() string {
+	var id [8]byte
+	rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// Wrap returns a repository that checks every synthesis against the budgets
+// before forwarding it to next and records what it was billed
+func (g *BudgetGuard) Wrap(next domain.TTSRepository) domain.TTSRepository {
+	return &budgetedTTSRepository{next: next, guard: g}
+}
+
+// budgetedTTSRepository is the repository returned by BudgetGuard.Wrap
+type budgetedTTSRepository struct {
+	next  domain.TTSRepository
+	guard *BudgetGuard
+}
+
+func (r *budgetedTTSRepository) Synthesize(ctx context.Context, request *domain.TTSRequest) (*domain.TTSResponse, error) {
+	// A reservation made for this request by ReserveRequest is billed here
+	// rather than checked again
+	var reservation string
+	if !domain.BudgetApproved(ctx) {
+		reservation = domain.BudgetReservationID(ctx)
+	}
+	if reservation == "" {
+		var err error
+		if reservation, err = r.guard.Reserve(ctx, requestCharacters(request)); err != nil {
+			return nil, err
+		}
+	}
+	response, err := r.next.Synthesize(ctx, request)
+	if err != nil {
+		r.guard.Release(ctx, reservation)
+		return nil, err
+	}
+	// The credits are spent; a ledger that cannot be written must not cost
+	// the caller the audio as well, and the reservation lapses on its own
+	r.guard.Record(ctx, reservation, request, response.BillingInfo)
+	return response, nil
+}
+
+// SynthesizeStream goes through Synthesize, whose response carries the
+// billing headers that a bare stream drops. The audio still streams.
+func (r *budgetedTTSRepository) SynthesizeStream(ctx context.Context, request *domain.TTSRequest) (io.ReadCloser, error) {
+	response, err := r.Synthesize(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return response.AudioData, nil
+}
@@ -0,0 +1,289 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/infrastructure"
+)
+
+// billingTTSRepo bills creditsPerCharacter for every character, like the API
+type billingTTSRepo struct {
+	creditsPerCharacter float64
+	calls               int
+}
+
+func (r *billingTTSRepo) Synthesize(ctx context.Context, request *domain.TTSRequest) (*domain.TTSResponse, error) {
+	r.calls++
+	characters := len([]rune(request.Text))
+	return &domain.TTSResponse{
+		AudioData: io.NopCloser(strings.NewReader("audio")),
+		BillingInfo: &http.BillingInfo{
+			CharacterCount: strconv.Itoa(characters),
+			CreditsUsed:    strconv.FormatFloat(float64(characters)*r.creditsPerCharacter, 'f', -1, 64),
+		},
+	}, nil
+}
+
+func (r *billingTTSRepo) SynthesizeStream(ctx context.Context, request *domain.TTSRequest) (io.ReadCloser, error) {
+	response, err := r.Synthesize(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return response.AudioData, nil
+}
+
+func newTestBudgetGuard(t *testing.T, daily, monthly float64) *BudgetGuard {
+	t.Helper()
+	repository := infrastructure.NewFileBudgetRepository(filepath.Join(t.TempDir(), "budget.json"))
+	return NewBudgetGuard(repository, daily, monthly)
+}
+
+func TestCountCharacters(t *testing.T) {
+	if got := CountCharacters("こんにちは。", false); got != 6 {
+		t.Errorf("CountCharacters() = %d, want 6", got)
+	}
+	if got := CountCharacters(`<speak>こんにちは<break time="500ms"/></speak>`, true); got != 5 {
+		t.Errorf("CountCharacters() with SSML = %d, want 5", got)
+	}
+}
+
+func TestBudgetGuardRefusesOverBudget(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestBudgetGuard(t, 10, 0)
+	api := &billingTTSRepo{creditsPerCharacter: 0.5}
+	repo := guard.Wrap(api)
+
+	// Uncalibrated, 12 characters are estimated at 12 credits
+	long := domain.NewTTSRequestBuilder("model", "あいうえおかきくけこさし").Build()
+	var exceeded *domain.BudgetExceededError
+	if _, err := repo.Synthesize(ctx, long); !errors.As(err, &exceeded) || exceeded.Period != domain.BudgetPeriodDaily {
+		t.Fatalf("Synthesize() error = %v, want a daily BudgetExceededError", err)
+	}
+	if api.calls != 0 {
+		t.Error("a refused synthesis must not reach the API")
+	}
+
+	short := domain.NewTTSRequestBuilder("model", "あいうえお").Build()
+	if _, err := repo.Synthesize(ctx, short); err != nil {
+		t.Fatalf("Synthesize() error = %v", err)
+	}
+
+	status, err := guard.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Daily.Spent != 2.5 || status.Daily.Requests != 1 || *status.Daily.Remaining != 7.5 {
+		t.Errorf("daily status = %+v", status.Daily)
+	}
+	if status.Monthly.Limited() || status.Monthly.Remaining != nil || status.Monthly.Spent != 2.5 {
+		t.Errorf("monthly status = %+v", status.Monthly)
+	}
+	if status.CreditsPerCharacter != 0.5 || status.CalibrationSamples != 1 {
+		t.Errorf("calibration = %v from %d samples, want 0.5 from 1", status.CreditsPerCharacter, status.CalibrationSamples)
+	}
+
+	// Calibrated at 0.5 credits per character, the long text now fits
+	if _, err := repo.Synthesize(ctx, long); err != nil {
+		t.Errorf("Synthesize() after calibration error = %v", err)
+	}
+}
+
+func TestBudgetGuardConfirmAndApproval(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestBudgetGuard(t, 0, 3)
+	repo := guard.Wrap(&billingTTSRepo{creditsPerCharacter: 1})
+	request := domain.NewTTSRequestBuilder("model", "あいうえお").Build()
+
+	var asked *domain.BudgetEstimate
+	guard.SetConfirm(func(ctx context.Context, estimate *domain.BudgetEstimate) bool {
+		asked = estimate
+		return true
+	})
+	if _, err := repo.Synthesize(ctx, request); err != nil {
+		t.Fatalf("confirmed Synthesize() error = %v", err)
+	}
+	if asked == nil || asked.Credits != 5 || len(asked.Exceeded) != 1 || asked.Exceeded[0].Period != domain.BudgetPeriodMonthly {
+		t.Errorf("confirm was asked about %+v", asked)
+	}
+
+	guard.SetConfirm(nil)
+	if _, err := repo.Synthesize(ctx, request); err == nil {
+		t.Error("expected the synthesis to be refused without confirmation")
+	}
+	if _, err := repo.Synthesize(domain.WithBudgetApproval(ctx), request); err != nil {
+		t.Errorf("approved Synthesize() error = %v", err)
+	}
+	if _, _, err := guard.Approve(ctx, 1); err == nil {
+		t.Error("Approve() must check the budget")
+	}
+
+	status, _ := guard.Status(ctx)
+	if status.Monthly.Spent != 10 || *status.Monthly.Remaining != 0 {
+		t.Errorf("monthly status = %+v", status.Monthly)
+	}
+}
+
+func TestBudgetPeriods(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestBudgetGuard(t, 100, 1000)
+	// Mid-month, and recent enough to be within the ledger's retention
+	today := time.Now()
+	now := time.Date(today.Year(), today.Month(), 15, 12, 0, 0, 0, time.Local)
+	request := domain.NewTTSRequestBuilder("model", "あ").Build()
+
+	for _, at := range []time.Time{now.AddDate(0, -1, 0), now.AddDate(0, 0, -1), now} {
+		guard.now = func() time.Time { return at }
+		if err := guard.Record(ctx, "", request, &http.BillingInfo{CharacterCount: "1", CreditsUsed: "4"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Missing billing headers are recorded at the estimate
+	if err := guard.Record(ctx, "", request, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := guard.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Daily.Spent != 8 || status.Daily.Requests != 2 || !status.Daily.ResetsAt.Equal(time.Date(now.Year(), now.Month(), 16, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("daily status = %+v", status.Daily)
+	}
+	if status.Monthly.Spent != 12 || status.Monthly.Requests != 3 || !status.Monthly.Start.Equal(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("monthly status = %+v", status.Monthly)
+	}
+	if status.CalibrationSamples != 3 {
+		t.Errorf("estimated spends must not calibrate, got %d samples", status.CalibrationSamples)
+	}
+}
+
+func TestBudgetGuardReservesUntilRecorded(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "budget.json")
+	// Two guards over one ledger stand for two processes
+	first := NewBudgetGuard(infrastructure.NewFileBudgetRepository(path), 10, 0)
+	second := NewBudgetGuard(infrastructure.NewFileBudgetRepository(path), 10, 0)
+
+	// Uncalibrated, each reservation holds 6 credits: either fits alone
+	reservation, err := first.Reserve(ctx, 6)
+	if err != nil || reservation == "" {
+		t.Fatalf("Reserve() = %q, %v", reservation, err)
+	}
+	var exceeded *domain.BudgetExceededError
+	if _, err := second.Reserve(ctx, 6); !errors.As(err, &exceeded) || exceeded.Reserved != 6 {
+		t.Fatalf("concurrent Reserve() error = %v, want a BudgetExceededError counting the reservation", err)
+	}
+	status, _ := second.Status(ctx)
+	if status.Daily.Reserved != 6 || *status.Daily.Remaining != 4 {
+		t.Errorf("daily status with a reservation = %+v", status.Daily)
+	}
+
+	// Recording replaces the reservation with the billed credits
+	request := domain.NewTTSRequestBuilder("model", "あいうえおか").Build()
+	if err := first.Record(ctx, reservation, request, &http.BillingInfo{CharacterCount: "6", CreditsUsed: "3"}); err != nil {
+		t.Fatal(err)
+	}
+	status, _ = second.Status(ctx)
+	if status.Daily.Reserved != 0 || status.Daily.Spent != 3 {
+		t.Errorf("daily status after Record() = %+v", status.Daily)
+	}
+
+	// A released reservation no longer counts
+	reservation, err = second.Reserve(ctx, 6)
+	if err != nil {
+		t.Fatalf("Reserve() after Record() error = %v", err)
+	}
+	if err := second.Release(ctx, reservation); err != nil {
+		t.Fatal(err)
+	}
+	if status, _ = first.Status(ctx); status.Daily.Reserved != 0 {
+		t.Errorf("daily status after Release() = %+v", status.Daily)
+	}
+
+	// A reservation whose process died lapses
+	if _, err := first.Reserve(ctx, 6); err != nil {
+		t.Fatal(err)
+	}
+	first.now = func() time.Time { return time.Now().Add(reservationTTL + time.Minute) }
+	if status, _ = first.Status(ctx); status.Daily.Reserved != 0 {
+		t.Errorf("lapsed reservation still counts: %+v", status.Daily)
+	}
+}
+
+func TestBudgetGuardApprovalDrawsDown(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestBudgetGuard(t, 100, 0)
+	repo := guard.Wrap(&billingTTSRepo{creditsPerCharacter: 1})
+
+	approved, release, err := guard.Approve(ctx, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range []string{"あいうえお", "かきくけこ"} {
+		if _, err := repo.Synthesize(approved, domain.NewTTSRequestBuilder("model", text).Build()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	status, _ := guard.Status(ctx)
+	if status.Daily.Spent != 10 || status.Daily.Reserved != 10 {
+		t.Errorf("daily status during the approval = %+v, want 10 spent and 10 reserved", status.Daily)
+	}
+
+	release()
+	if status, _ = guard.Status(ctx); status.Daily.Spent != 10 || status.Daily.Reserved != 0 {
+		t.Errorf("daily status after release = %+v", status.Daily)
+	}
+}
+
+func TestBudgetGuardRequestReservationSettledBySynthesis(t *testing.T) {
+	ctx := context.Background()
+	guard := newTestBudgetGuard(t, 100, 0)
+	api := &billingTTSRepo{creditsPerCharacter: 0.5}
+	repo := guard.Wrap(api)
+	request := domain.NewTTSRequestBuilder("model", "あいうえお").Build()
+
+	reserved, err := guard.ReserveRequest(ctx, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The reservation outlives the call that made it, e.g. on the queue or
+	// in the playback daemon, until the synthesis replaces it
+	daemonCtx := domain.WithBudgetReservation(context.Background(), domain.BudgetReservationID(reserved))
+	if status, _ := guard.Status(ctx); status.Daily.Reserved != 5 {
+		t.Fatalf("daily status before the synthesis = %+v, want 5 reserved", status.Daily)
+	}
+	if _, err := repo.Synthesize(daemonCtx, request); err != nil {
+		t.Fatal(err)
+	}
+	status, _ := guard.Status(ctx)
+	if status.Daily.Spent != 2.5 || status.Daily.Reserved != 0 {
+		t.Errorf("daily status after the synthesis = %+v, want 2.5 spent and nothing reserved", status.Daily)
+	}
+	if _, err := guard.Reserve(reserved, 1000); err != nil {
+		t.Errorf("Reserve() under a reservation must not check again: %v", err)
+	}
+}
+
+func TestBudgetGuardWithoutBudgetsRecordsNothing(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "budget.json")
+	guard := NewBudgetGuard(infrastructure.NewFileBudgetRepository(path), 0, 0)
+	repo := guard.Wrap(&billingTTSRepo{creditsPerCharacter: 1})
+
+	if _, err := repo.Synthesize(ctx, domain.NewTTSRequestBuilder("model", "あいうえお").Build()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the ledger was written without budgets (stat error %v)", err)
+	}
+}
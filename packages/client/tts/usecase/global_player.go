@@ -1,6 +1,7 @@
 package usecase
 
 import (
+    "bytes"
     "context"
     "fmt"
     "io"
@@ -8,6 +9,7 @@ import (
     "sync"
     "time"
 
+    "github.com/kajidog/aivis-cloud-cli/client/audio"
     "github.com/kajidog/aivis-cloud-cli/client/common/logger"
     "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
 )
@@ -148,7 +150,7 @@ func (s *GlobalAudioPlayerService) PlayRequestWithHistory(ctx context.Context, r
         case domain.PlaybackModeImmediate:
             // Immediate: stop current playback and clear queue, then play synchronously
             s.logger.Info("Synchronous immediate mode - stopping current playback (with history)")
-            s.Stop()
+            s.interrupt()
             return s.synthesizeAndPlayStreamSyncWithHistory(ctx, request, getOutputFormat(request), historyFilePath)
         case domain.PlaybackModeQueue:
             return s.addToQueueSyncWithHistory(ctx, request, historyFilePath)
@@ -173,7 +175,7 @@ func (s *GlobalAudioPlayerService) PlayRequestWithHistory(ctx context.Context, r
     switch *request.Mode {
     case domain.PlaybackModeImmediate:
         // Immediate: stop current playback and clear queue, then play asynchronously
-        s.Stop()
+        s.interrupt()
         go func() { _ = s.streamingSynthesisAndPlayWithHistory(ctx, request, getOutputFormat(request), historyFilePath) }()
         return nil
     case domain.PlaybackModeQueue:
@@ -234,7 +236,7 @@ func (s *GlobalAudioPlayerService) playWithoutQueueWithHistory(ctx context.Conte
 // playImmediate stops current playback and plays new audio immediately
 func (s *GlobalAudioPlayerService) playImmediate(ctx context.Context, request *domain.PlaybackRequest) error {
 	// Stop current playback and clear queue
-	s.Stop()
+	s.interrupt()
 	
 	// Add small delay to ensure previous playback process is fully stopped
 	// This prevents audio overlap when multiple immediate requests come quickly
@@ -438,7 +440,7 @@ func (s *GlobalAudioPlayerService) streamingSynthesisAndPlayWithHistory(ctx cont
 	go func() {
 		// Create independent context for audio playback to prevent premature cancellation
 		playbackCtx := context.Background()
-		err := s.player.Play(playbackCtx, pipeReader, format)
+		err := playRequest(playbackCtx, s.player, pipeReader, format, request)
 		if err != nil {
 			errChan <- fmt.Errorf("playback failed: %w", err)
 		} else {
@@ -476,8 +478,9 @@ func (s *GlobalAudioPlayerService) streamingSynthesisAndPlayWithHistory(ctx cont
 	
 	// Start streaming synthesis with independent context
 	go func() {
-		// Create independent context for TTS synthesis to prevent premature cancellation
-		synthesisCtx := context.Background()
+		// Detach from the caller's cancellation to prevent premature cancellation,
+		// but keep its values such as a budget approval
+		synthesisCtx := context.WithoutCancel(ctx)
 		err := s.ttsService.SynthesizeStream(synthesisCtx, request.TTSRequest, handler)
 		pipeWriter.Close() // Close writer when done
 		if err != nil {
@@ -517,7 +520,7 @@ func (s *GlobalAudioPlayerService) streamingSynthesisAndPlayWithPlayer(ctx conte
     // Start playback on the provided player
     go func() {
         playbackCtx := context.Background()
-        err := player.Play(playbackCtx, pipeReader, format)
+        err := playRequest(playbackCtx, player, pipeReader, format, request)
         if err != nil { errChan <- fmt.Errorf("playback failed: %w", err) } else { errChan <- nil }
     }()
 
@@ -531,7 +534,7 @@ func (s *GlobalAudioPlayerService) streamingSynthesisAndPlayWithPlayer(ctx conte
 
     // Start synthesis
     go func() {
-        synthesisCtx := context.Background()
+        synthesisCtx := context.WithoutCancel(ctx)
         err := s.ttsService.SynthesizeStream(synthesisCtx, request.TTSRequest, handler)
         pipeWriter.Close()
         if err != nil { errChan <- fmt.Errorf("synthesis failed: %w", err) } else { errChan <- nil }
@@ -593,13 +596,28 @@ func (h *streamingPlaybackHistoryHandler) OnError(err error) {
 // Stop stops current playback and clears queue
 func (s *GlobalAudioPlayerService) Stop() error {
 	s.mu.Lock()
+	dropped := s.queue
 	s.queue = make([]queueItem, 0)
 	s.processing = false
 	s.mu.Unlock()
+	dropQueueItems(dropped)
 	
 	return s.player.Stop()
 }
 
+// interrupt clears the queue and stops current playback for an immediate
+// request, fading the current item out when it asked for a fade-out
+func (s *GlobalAudioPlayerService) interrupt() error {
+	s.mu.Lock()
+	dropped := s.queue
+	s.queue = make([]queueItem, 0)
+	s.processing = false
+	s.mu.Unlock()
+	dropQueueItems(dropped)
+
+	return interruptPlayer(s.player)
+}
+
 // Pause pauses current playback
 func (s *GlobalAudioPlayerService) Pause() error {
 	return s.player.Pause()
@@ -637,8 +655,10 @@ func (s *GlobalAudioPlayerService) GetQueueLength() int {
 // ClearQueue clears all items from the queue
 func (s *GlobalAudioPlayerService) ClearQueue() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	dropped := s.queue
 	s.queue = make([]queueItem, 0)
+	s.mu.Unlock()
+	dropQueueItems(dropped)
 }
 
 // Close closes the audio player service and releases resources
@@ -666,7 +686,7 @@ func (s *GlobalAudioPlayerService) playSynchronous(ctx context.Context, request
 	case domain.PlaybackModeImmediate:
 		// For immediate mode with wait_for_end, stop current and play synchronously
 		s.logger.Info("Synchronous immediate mode - stopping current playback")
-		s.Stop()
+		s.interrupt()
 		return s.synthesizeAndPlayStreamSync(ctx, request, getOutputFormat(request))
 	case domain.PlaybackModeQueue:
 		// For queue mode with wait_for_end, add to queue and wait for completion
@@ -738,6 +758,25 @@ func (s *GlobalAudioPlayerService) synthesizeAndPlayStreamSyncWithHistory(ctx co
 
 // Helper functions
 
+// playRequest plays audioData on player with the start offset and fades of
+// request, when the player supports them
+func playRequest(ctx context.Context, player domain.AudioPlayer, audioData io.Reader, format domain.OutputFormat, request *domain.PlaybackRequest) error {
+	if envelopePlayer, ok := player.(domain.EnvelopePlayer); ok && request != nil {
+		if envelope := request.Envelope(); !envelope.IsZero() {
+			return envelopePlayer.PlayWithEnvelope(ctx, audioData, format, envelope)
+		}
+	}
+	return player.Play(ctx, audioData, format)
+}
+
+// interruptPlayer stops player, letting it fade out when it supports that
+func interruptPlayer(player domain.AudioPlayer) error {
+	if envelopePlayer, ok := player.(domain.EnvelopePlayer); ok {
+		return envelopePlayer.Interrupt()
+	}
+	return player.Stop()
+}
+
 func getOutputFormat(request *domain.PlaybackRequest) domain.OutputFormat {
     format := domain.OutputFormatMP3 // default
 	if request.TTSRequest.OutputFormat != nil {
@@ -753,7 +792,7 @@ func (s *GlobalAudioPlayerService) streamingSynthesisAndPlayWithPlayerAndHistory
 
     go func() {
         playbackCtx := context.Background()
-        err := player.Play(playbackCtx, pipeReader, format)
+        err := playRequest(playbackCtx, player, pipeReader, format, request)
         if err != nil { errChan <- fmt.Errorf("playback failed: %w", err) } else { errChan <- nil }
     }()
 
@@ -771,7 +810,7 @@ func (s *GlobalAudioPlayerService) streamingSynthesisAndPlayWithPlayerAndHistory
     }
 
     go func() {
-        synthesisCtx := context.Background()
+        synthesisCtx := context.WithoutCancel(ctx)
         err := s.ttsService.SynthesizeStream(synthesisCtx, request.TTSRequest, handler)
         pipeWriter.Close()
         if err != nil { errChan <- fmt.Errorf("synthesis failed: %w", err) } else { errChan <- nil }
@@ -786,3 +825,111 @@ func (s *GlobalAudioPlayerService) streamingSynthesisAndPlayWithPlayerAndHistory
     if synthErr != nil { return synthErr }
     return playErr
 }
+
+// PlayLongRequest synthesizes text longer than a single request allows and plays
+// it as one continuous WAV stream. Playback starts as soon as the first chunk is
+// ready; later chunks are synthesized in the background. Like immediate mode it
+// stops current playback first. When historyFilePath is set, the joined audio is
+// also written there as a WAV file.
+func (s *GlobalAudioPlayerService) PlayLongRequest(ctx context.Context, request *domain.PlaybackRequest, options *domain.LongTextOptions, historyFilePath string) (*domain.LongTextResult, error) {
+	if s.ttsService == nil || s.player == nil {
+		return nil, fmt.Errorf("audio player service not initialized")
+	}
+	if request == nil || request.TTSRequest == nil {
+		return nil, fmt.Errorf("invalid request")
+	}
+
+	s.interrupt()
+
+	if player, ok := s.player.(interface{ SetCurrentText(string) }); ok {
+		player.SetCurrentText(request.TTSRequest.Text)
+	}
+	if request.Volume != nil {
+		if err := s.player.SetVolume(*request.Volume); err != nil {
+			return nil, fmt.Errorf("failed to set volume: %w", err)
+		}
+	}
+
+	pipeReader, pipeWriter := io.Pipe()
+	defer pipeReader.Close()
+
+	playErr := make(chan error, 1)
+	go func() {
+		// Independent context so playback is not cut off when synthesis finishes
+		err := playRequest(context.Background(), s.player, pipeReader, domain.OutputFormatWAV, request)
+		if err != nil {
+			pipeReader.CloseWithError(err)
+		}
+		playErr <- err
+	}()
+
+	var handler domain.TTSStreamHandler = &streamingPlaybackHandler{
+		writer:     pipeWriter,
+		firstChunk: true,
+		startTime:  time.Now(),
+		logger:     s.logger,
+	}
+	var recorded *bytes.Buffer
+	if historyFilePath != "" {
+		recorded = &bytes.Buffer{}
+		handler = &recordingStreamHandler{TTSStreamHandler: handler, buffer: recorded}
+	}
+
+	result, err := s.ttsService.SynthesizeLongStream(ctx, request.TTSRequest, options, handler)
+	pipeWriter.CloseWithError(err)
+	if perr := <-playErr; err == nil && perr != nil {
+		err = fmt.Errorf("playback failed: %w", perr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if recorded != nil {
+		if err := writeRecordedWAV(historyFilePath, recorded.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if request.WaitForEnd != nil && *request.WaitForEnd {
+		s.waitForPlaybackEnd(ctx)
+	}
+	return result, nil
+}
+
+// waitForPlaybackEnd blocks until the player is idle or ctx is done
+func (s *GlobalAudioPlayerService) waitForPlaybackEnd(ctx context.Context) {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+	for s.player.IsPlaying() {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// recordingStreamHandler copies streamed audio into a buffer before passing it on
+type recordingStreamHandler struct {
+	domain.TTSStreamHandler
+	buffer *bytes.Buffer
+}
+
+func (h *recordingStreamHandler) OnChunk(chunk *domain.TTSStreamChunk) error {
+	h.buffer.Write(chunk.Data)
+	return h.TTSStreamHandler.OnChunk(chunk)
+}
+
+// writeRecordedWAV rewrites a streamed WAV (open-ended header) as a regular WAV file
+func writeRecordedWAV(path string, stream []byte) error {
+	buffer, err := audio.DecodeWAVBytes(stream)
+	if err != nil {
+		return fmt.Errorf("failed to decode recorded audio: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create history file: %w", err)
+	}
+	defer file.Close()
+	return buffer.WriteWAV(file)
+}
@@ -3,9 +3,11 @@ package usecase
 import (
     "bytes"
     "context"
+    "errors"
     "io"
     "sync"
     "testing"
+    "time"
 
     "github.com/kajidog/aivis-cloud-cli/client/tts/domain"
 )
@@ -142,3 +144,42 @@ func TestPlayRequestWithHistory_Immediate_StopsCurrent(t *testing.T) {
         t.Fatalf("expected Stop() to be called for immediate mode (sync)")
     }
 }
+
+// A caller waiting on a queued item must return when the queue is cleared, so
+// that it releases the budget reservation the item carried
+func TestPlayRequestWithHistory_Queue_Wait_ReturnsWhenCleared(t *testing.T) {
+    synth := NewTTSSynthesizer(&fakeTTSRepo{})
+    mp := &mockPlayer{}
+
+    s := GetGlobalAudioPlayerService()
+    s.Initialize(synth, mp, &AudioPlayerConfig{MaxQueueSize: 10})
+    s.ClearQueue()
+
+    // Simulate ongoing playback so the item stays queued
+    mp.mu.Lock(); mp.playing = true; mp.mu.Unlock()
+    defer func() { mp.mu.Lock(); mp.playing = false; mp.mu.Unlock() }()
+
+    req := newBasicRequest()
+    mode := domain.PlaybackModeQueue
+    req.Mode = &mode
+    wait := true
+    req.WaitForEnd = &wait
+
+    result := make(chan error, 1)
+    go func() { result <- s.PlayRequestWithHistory(context.Background(), req, "test_hist.mp3") }()
+    for deadline := time.Now().Add(time.Second); s.GetQueueLength() == 0; time.Sleep(time.Millisecond) {
+        if time.Now().After(deadline) {
+            t.Fatal("the request was never queued")
+        }
+    }
+
+    s.ClearQueue()
+    select {
+    case err := <-result:
+        if !errors.Is(err, errDroppedFromQueue) {
+            t.Fatalf("expected errDroppedFromQueue, got %v", err)
+        }
+    case <-time.After(time.Second):
+        t.Fatal("the waiting caller did not return after the queue was cleared")
+    }
+}
@@ -0,0 +1,500 @@
+package usecase
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// ExportHistory writes the selected records, their audio files and a manifest
+// to an archive at path. The archive is written to a temporary file first, so
+// an interrupted export never leaves a truncated archive behind.
+func (m *TTSHistoryManager) ExportHistory(ctx context.Context, path string, options *domain.TTSHistoryExportOptions) (*domain.TTSHistoryExportResult, error) {
+	if options == nil {
+		options = &domain.TTSHistoryExportOptions{}
+	}
+	format := options.Format
+	if format == "" {
+		var err error
+		if format, err = archiveFormatFromPath(path); err != nil {
+			return nil, err
+		}
+	}
+
+	request := domain.NewTTSHistorySearchRequest().WithSorting("id", "asc").Build()
+	request.ModelUUID = options.ModelUUID
+	request.StartDate = options.StartDate
+	request.EndDate = options.EndDate
+	histories, err := m.listAllHistory(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+
+	result := &domain.TTSHistoryExportResult{Path: path}
+	manifest := &domain.TTSHistoryManifest{
+		Version:    domain.HistoryArchiveVersion,
+		ExportedAt: time.Now(),
+		Records:    []*domain.TTSHistoryArchiveRecord{},
+	}
+	sourcePaths := make(map[int]string)
+	for _, history := range histories {
+		if options.FromID > 0 && history.ID < options.FromID {
+			continue
+		}
+		if options.ToID > 0 && history.ID > options.ToID {
+			continue
+		}
+
+		record := *history
+		record.FilePath = ""
+		entry := &domain.TTSHistoryArchiveRecord{History: &record}
+		if _, err := os.Stat(history.FilePath); err == nil {
+			entry.AudioFile = fmt.Sprintf("audio/%d%s", history.ID, filepath.Ext(history.FilePath))
+			sourcePaths[history.ID] = history.FilePath
+		} else {
+			result.MissingAudio = append(result.MissingAudio, history.ID)
+		}
+		manifest.Records = append(manifest.Records, entry)
+	}
+	result.Records = len(manifest.Records)
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+	defer tmp.Close()
+
+	writer, err := newArchiveWriter(tmp, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// The manifest goes first so tar archives can be read in a single pass
+	if err := writer.WriteFile(domain.HistoryArchiveManifestName, int64(len(manifestData)), manifest.ExportedAt, bytes.NewReader(manifestData)); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	for _, entry := range manifest.Records {
+		if entry.AudioFile == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := writeArchiveAudio(writer, entry.AudioFile, sourcePaths[entry.History.ID]); err != nil {
+			return nil, fmt.Errorf("failed to add audio of record %d: %w", entry.History.ID, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	return result, nil
+}
+
+// writeArchiveAudio copies one audio file into the archive
+func writeArchiveAudio(writer archiveWriter, name, sourcePath string) error {
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	return writer.WriteFile(name, info.Size(), info.ModTime(), file)
+}
+
+// ImportHistory merges the records of an archive written by ExportHistory
+// into the local store. Records get new sequential IDs and their audio is
+// copied into the history store; records whose InternalUUID already exists
+// locally are skipped, so importing the same archive twice is harmless.
+// Imported records do not trigger the history_max_count cleanup.
+func (m *TTSHistoryManager) ImportHistory(ctx context.Context, path string) (*domain.TTSHistoryImportResult, error) {
+	archive, err := openHistoryArchive(path)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	manifest := archive.manifest
+	if manifest.Version < 1 || manifest.Version > domain.HistoryArchiveVersion {
+		return nil, fmt.Errorf("unsupported history archive version %d", manifest.Version)
+	}
+
+	existing, err := m.listAllHistory(ctx, domain.NewTTSHistorySearchRequest().Build())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	// known maps the InternalUUID of local records to their IDs
+	known := make(map[string]int, len(existing))
+	for _, history := range existing {
+		known[history.InternalUUID] = history.ID
+	}
+
+	storePath, err := m.config.GetHistoryStorePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history store path: %w", err)
+	}
+	audioDir := filepath.Join(storePath, "audio")
+	if err := os.MkdirAll(audioDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create audio directory: %w", err)
+	}
+
+	records := make([]*domain.TTSHistoryArchiveRecord, 0, len(manifest.Records))
+	for _, entry := range manifest.Records {
+		if entry != nil && entry.History != nil {
+			records = append(records, entry)
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].History.ID < records[j].History.ID
+	})
+
+	result := &domain.TTSHistoryImportResult{IDMap: make(map[int]int)}
+	// localIDs maps archive IDs to local IDs, including skipped duplicates, so
+	// regenerated records stay linked to their parent
+	localIDs := make(map[int]int)
+	for _, entry := range records {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		history := *entry.History
+		if localID, ok := known[history.InternalUUID]; ok && history.InternalUUID != "" {
+			localIDs[history.ID] = localID
+			result.Duplicates++
+			continue
+		}
+
+		id, err := m.historyRepo.GetNextID(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to get next ID: %w", err)
+		}
+		oldID := history.ID
+		history.ID = id
+		history.FilePath = ""
+		// Parents always have lower IDs, so they were imported first
+		history.ParentID = localIDs[history.ParentID]
+
+		if entry.AudioFile != "" {
+			filePath := filepath.Join(audioDir, strconv.Itoa(id)+filepath.Ext(entry.AudioFile))
+			size, err := archive.extract(entry.AudioFile, filePath)
+			if err != nil {
+				return result, fmt.Errorf("failed to import audio of record %d: %w", oldID, err)
+			}
+			history.FilePath = filePath
+			history.FileSizeBytes = size
+		}
+
+		if _, err := m.historyRepo.Save(ctx, &history); err != nil {
+			if history.FilePath != "" {
+				os.Remove(history.FilePath)
+			}
+			return result, fmt.Errorf("failed to save record %d: %w", oldID, err)
+		}
+
+		known[history.InternalUUID] = id
+		localIDs[oldID] = id
+		result.IDMap[oldID] = id
+		result.Imported++
+	}
+
+	return result, nil
+}
+
+// archiveFormatFromPath derives the archive format from a file extension
+func archiveFormatFromPath(path string) (domain.HistoryArchiveFormat, error) {
+	lower := strings.ToLower(path)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return domain.HistoryArchiveFormatZip, nil
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return domain.HistoryArchiveFormatTarGz, nil
+	case strings.HasSuffix(lower, ".tar"):
+		return domain.HistoryArchiveFormatTar, nil
+	default:
+		return "", fmt.Errorf("cannot determine archive format from %q (use .zip, .tar or .tar.gz)", filepath.Base(path))
+	}
+}
+
+// archiveWriter adds files to a zip or tar archive
+type archiveWriter interface {
+	WriteFile(name string, size int64, modTime time.Time, r io.Reader) error
+	Close() error
+}
+
+func newArchiveWriter(w io.Writer, format domain.HistoryArchiveFormat) (archiveWriter, error) {
+	switch format {
+	case domain.HistoryArchiveFormatZip:
+		return &zipArchiveWriter{zw: zip.NewWriter(w)}, nil
+	case domain.HistoryArchiveFormatTar:
+		return &tarArchiveWriter{tw: tar.NewWriter(w)}, nil
+	case domain.HistoryArchiveFormatTarGz:
+		gz := gzip.NewWriter(w)
+		return &tarArchiveWriter{tw: tar.NewWriter(gz), gz: gz}, nil
+	default:
+		return nil, fmt.Errorf("unsupported archive format: %s (use zip, tar or tar.gz)", format)
+	}
+}
+
+type zipArchiveWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipArchiveWriter) WriteFile(name string, size int64, modTime time.Time, r io.Reader) error {
+	fw, err := w.zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modTime})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(fw, r)
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarArchiveWriter struct {
+	tw *tar.Writer
+	gz *gzip.Writer
+}
+
+func (w *tarArchiveWriter) WriteFile(name string, size int64, modTime time.Time, r io.Reader) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: size, ModTime: modTime, Typeflag: tar.TypeReg}
+	if err := w.tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.CopyN(w.tw, r, size)
+	return err
+}
+
+func (w *tarArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
+
+// historyArchive gives access to the manifest and audio of an archive. Zip
+// entries are read in place; tar entries are staged in a temporary directory
+// because tar can only be read sequentially.
+type historyArchive struct {
+	manifest *domain.TTSHistoryManifest
+	zip      *zip.ReadCloser
+	zipFiles map[string]*zip.File
+	staged   map[string]string
+	stageDir string
+}
+
+// openHistoryArchive detects the archive format from its content and reads the manifest
+func openHistoryArchive(path string) (*historyArchive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	magic := make([]byte, 4)
+	n, _ := io.ReadFull(file, magic)
+	file.Close()
+
+	var archive *historyArchive
+	switch {
+	case n == 4 && bytes.Equal(magic, []byte("PK\x03\x04")):
+		archive, err = openZipHistoryArchive(path)
+	case n >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+		archive, err = openTarHistoryArchive(path, true)
+	default:
+		archive, err = openTarHistoryArchive(path, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if archive.manifest == nil {
+		archive.Close()
+		return nil, fmt.Errorf("archive has no %s; is it a history export?", domain.HistoryArchiveManifestName)
+	}
+	return archive, nil
+}
+
+func openZipHistoryArchive(path string) (*historyArchive, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive: %w", err)
+	}
+
+	archive := &historyArchive{zip: zr, zipFiles: make(map[string]*zip.File)}
+	for _, f := range zr.File {
+		archive.zipFiles[f.Name] = f
+	}
+	if f, ok := archive.zipFiles[domain.HistoryArchiveManifestName]; ok {
+		rc, err := f.Open()
+		if err != nil {
+			zr.Close()
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		archive.manifest, err = decodeManifest(rc)
+		rc.Close()
+		if err != nil {
+			zr.Close()
+			return nil, err
+		}
+	}
+	return archive, nil
+}
+
+func openTarHistoryArchive(path string, gzipped bool) (*historyArchive, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if gzipped {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read gzip archive: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	stageDir, err := os.MkdirTemp("", "aivis-history-import-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	archive := &historyArchive{staged: make(map[string]string), stageDir: stageDir}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			archive.Close()
+			return nil, fmt.Errorf("failed to read tar archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if header.Name == domain.HistoryArchiveManifestName {
+			if archive.manifest, err = decodeManifest(tr); err != nil {
+				archive.Close()
+				return nil, err
+			}
+			continue
+		}
+
+		// Staged files get generated names; archive paths never reach the file system
+		stagedPath := filepath.Join(stageDir, strconv.Itoa(len(archive.staged)))
+		if err := copyToFile(stagedPath, tr); err != nil {
+			archive.Close()
+			return nil, fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+		archive.staged[header.Name] = stagedPath
+	}
+	return archive, nil
+}
+
+func decodeManifest(r io.Reader) (*domain.TTSHistoryManifest, error) {
+	var manifest domain.TTSHistoryManifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// extract copies the archive entry name to destPath and returns its size
+func (a *historyArchive) extract(name, destPath string) (int64, error) {
+	var src io.ReadCloser
+	var err error
+	if a.zip != nil {
+		f, ok := a.zipFiles[name]
+		if !ok {
+			return 0, fmt.Errorf("%s is missing from the archive", name)
+		}
+		src, err = f.Open()
+	} else {
+		stagedPath, ok := a.staged[name]
+		if !ok {
+			return 0, fmt.Errorf("%s is missing from the archive", name)
+		}
+		src, err = os.Open(stagedPath)
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	if err := copyToFile(destPath, src); err != nil {
+		return 0, err
+	}
+	info, err := os.Stat(destPath)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// Close releases the archive and removes staged files
+func (a *historyArchive) Close() error {
+	if a.stageDir != "" {
+		os.RemoveAll(a.stageDir)
+	}
+	if a.zip != nil {
+		return a.zip.Close()
+	}
+	return nil
+}
+
+// copyToFile writes r to a new file at path, removing it on failure
+func copyToFile(path string, r io.Reader) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, r); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/infrastructure"
+)
+
+func newArchiveTestManager(t *testing.T) (*TTSHistoryManager, string) {
+	t.Helper()
+	storePath := t.TempDir()
+	cfg := config.NewConfig("test-key").WithHistoryStorePath(storePath)
+	repo := infrastructure.NewFileHistoryRepository(storePath)
+	return NewTTSHistoryManager(repo, nil, nil, cfg), storePath
+}
+
+// saveArchiveTestRecord stores a record with an audio file in the manager's store
+func saveArchiveTestRecord(t *testing.T, m *TTSHistoryManager, storePath, text, model string) *domain.TTSHistory {
+	t.Helper()
+	ctx := context.Background()
+	id, err := m.historyRepo.GetNextID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	filePath := filepath.Join(storePath, "audio", text+".wav")
+	os.MkdirAll(filepath.Dir(filePath), 0755)
+	if err := os.WriteFile(filePath, []byte("audio:"+text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	credits := 1.5
+	history := &domain.TTSHistory{
+		ID:            id,
+		Request:       domain.NewTTSRequestBuilder(model, text).WithSpeakingRate(1.2).WithOutputFormat(domain.OutputFormatWAV).Build(),
+		FilePath:      filePath,
+		FileFormat:    "wav",
+		FileSizeBytes: int64(len("audio:" + text)),
+		CreatedAt:     time.Date(2025, 1, id, 0, 0, 0, 0, time.UTC),
+		Text:          text,
+		ModelUUID:     model,
+		Credits:       &credits,
+	}
+	if _, err := m.historyRepo.Save(ctx, history); err != nil {
+		t.Fatal(err)
+	}
+	return history
+}
+
+func TestHistoryExportImportRoundTrip(t *testing.T) {
+	for _, ext := range []string{".zip", ".tar", ".tar.gz"} {
+		t.Run(ext, func(t *testing.T) {
+			ctx := context.Background()
+			source, sourceStore := newArchiveTestManager(t)
+			for _, text := range []string{"one", "two", "three"} {
+				saveArchiveTestRecord(t, source, sourceStore, text, "model-a")
+			}
+			saveArchiveTestRecord(t, source, sourceStore, "other", "model-b")
+
+			archivePath := filepath.Join(t.TempDir(), "history"+ext)
+			modelA := "model-a"
+			exported, err := source.ExportHistory(ctx, archivePath, &domain.TTSHistoryExportOptions{FromID: 2, ModelUUID: &modelA})
+			if err != nil {
+				t.Fatalf("ExportHistory() error = %v", err)
+			}
+			if exported.Records != 2 || len(exported.MissingAudio) != 0 {
+				t.Fatalf("ExportHistory() = %+v, want records 2 and 3", exported)
+			}
+
+			// The target already has a record, so imported IDs must be remapped
+			target, targetStore := newArchiveTestManager(t)
+			saveArchiveTestRecord(t, target, targetStore, "local", "model-a")
+
+			imported, err := target.ImportHistory(ctx, archivePath)
+			if err != nil {
+				t.Fatalf("ImportHistory() error = %v", err)
+			}
+			if imported.Imported != 2 || imported.Duplicates != 0 || imported.IDMap[2] != 2 || imported.IDMap[3] != 3 {
+				t.Fatalf("ImportHistory() = %+v", imported)
+			}
+
+			got, err := target.GetHistory(ctx, imported.IDMap[3])
+			if err != nil {
+				t.Fatal(err)
+			}
+			original, _ := source.GetHistory(ctx, 3)
+			if got.InternalUUID != original.InternalUUID || got.Text != "three" || !got.CreatedAt.Equal(original.CreatedAt) {
+				t.Errorf("imported record = %+v", got)
+			}
+			if got.Request == nil || got.Request.SpeakingRate == nil || *got.Request.SpeakingRate != 1.2 {
+				t.Errorf("request parameters were not preserved: %+v", got.Request)
+			}
+			if got.Credits == nil || *got.Credits != 1.5 {
+				t.Errorf("Credits = %v, want 1.5", got.Credits)
+			}
+			if filepath.Dir(got.FilePath) != filepath.Join(targetStore, "audio") {
+				t.Errorf("audio imported to %s, want the target store", got.FilePath)
+			}
+			if data, err := os.ReadFile(got.FilePath); err != nil || string(data) != "audio:three" {
+				t.Errorf("imported audio = %q, %v", data, err)
+			}
+
+			again, err := target.ImportHistory(ctx, archivePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again.Imported != 0 || again.Duplicates != 2 {
+				t.Errorf("second ImportHistory() = %+v, want only duplicates", again)
+			}
+		})
+	}
+}
+
+func TestHistoryExportMissingAudio(t *testing.T) {
+	ctx := context.Background()
+	m, store := newArchiveTestManager(t)
+	history := saveArchiveTestRecord(t, m, store, "gone", "model")
+	os.Remove(history.FilePath)
+
+	archivePath := filepath.Join(t.TempDir(), "history.zip")
+	exported, err := m.ExportHistory(ctx, archivePath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exported.MissingAudio) != 1 || exported.MissingAudio[0] != history.ID {
+		t.Errorf("MissingAudio = %v, want [%d]", exported.MissingAudio, history.ID)
+	}
+
+	target, _ := newArchiveTestManager(t)
+	imported, err := target.ImportHistory(ctx, archivePath)
+	if err != nil || imported.Imported != 1 {
+		t.Fatalf("ImportHistory() = %+v, %v", imported, err)
+	}
+	got, _ := target.GetHistory(ctx, 1)
+	if got.FilePath != "" || got.Text != "gone" {
+		t.Errorf("record without audio imported as %+v", got)
+	}
+}
+
+func TestHistoryArchiveErrors(t *testing.T) {
+	ctx := context.Background()
+	m, _ := newArchiveTestManager(t)
+
+	if _, err := m.ExportHistory(ctx, filepath.Join(t.TempDir(), "history.rar"), nil); err == nil {
+		t.Error("an unknown extension must be rejected")
+	}
+
+	notArchive := filepath.Join(t.TempDir(), "notes.txt")
+	os.WriteFile(notArchive, []byte("hello"), 0644)
+	if _, err := m.ImportHistory(ctx, notArchive); err == nil {
+		t.Error("importing a file that is not a history archive must fail")
+	}
+}
@@ -8,6 +8,7 @@ import (
     "os"
     "path/filepath"
     "strconv"
+    "strings"
     "time"
 
 	"github.com/kajidog/aivis-cloud-cli/client/common/http"
@@ -21,6 +22,7 @@ type TTSHistoryManager struct {
 	ttsRepo      domain.TTSRepository
 	audioPlayer  domain.AudioPlayer
 	config       *config.Config
+	cache        domain.TTSCache
 }
 
 // NewTTSHistoryManager creates a new TTS history manager
@@ -38,6 +40,33 @@ func NewTTSHistoryManager(
 	}
 }
 
+// SetCache lets history share audio files with the synthesis cache
+func (m *TTSHistoryManager) SetCache(cache domain.TTSCache) {
+	m.cache = cache
+}
+
+// shareCachedAudio hard-links a history file to the identical cached audio, if
+// any, so the same audio is not stored twice. Only files owned by the history
+// store are linked; files written elsewhere belong to the user and may be edited.
+func (m *TTSHistoryManager) shareCachedAudio(ctx context.Context, request *domain.TTSRequest, filePath string) {
+	if m.cache == nil {
+		return
+	}
+	storePath, err := m.config.GetHistoryStorePath()
+	if err != nil {
+		return
+	}
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return
+	}
+	if rel, err := filepath.Rel(storePath, absPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return
+	}
+	// Sharing is only a space optimization; the history file stays valid either way
+	m.cache.ShareAudio(ctx, request, filePath)
+}
+
 // SaveHistory saves a TTS request and response as history
 func (m *TTSHistoryManager) SaveHistory(ctx context.Context, request *domain.TTSRequest, filePath string, credits *float64) (*domain.TTSHistory, error) {
 	if !m.config.HistoryEnabled {
@@ -49,6 +78,7 @@ func (m *TTSHistoryManager) SaveHistory(ctx context.Context, request *domain.TTS
 	if err != nil {
 		return nil, fmt.Errorf("failed to get file info: %w", err)
 	}
+	m.shareCachedAudio(ctx, request, filePath)
 
 	// Extract format from request or file path
 	format := "wav" // default
@@ -69,6 +99,7 @@ func (m *TTSHistoryManager) SaveHistory(ctx context.Context, request *domain.TTS
 		ModelUUID:     request.ModelUUID,
 		Credits:       credits,
 	}
+	domain.HistoryLabelsFrom(ctx).Apply(history)
 
 	// Save history record and get assigned ID
 	id, err := m.historyRepo.Save(ctx, history)
@@ -89,6 +120,13 @@ func (m *TTSHistoryManager) SaveHistory(ctx context.Context, request *domain.TTS
 
 // SaveHistoryWithAudio saves TTS request and audio data as history
 func (m *TTSHistoryManager) SaveHistoryWithAudio(ctx context.Context, request *domain.TTSRequest, audioData io.ReadCloser, billingInfo *http.BillingInfo) (*domain.TTSHistory, error) {
+	return m.saveHistoryWithAudio(ctx, request, audioData, billingInfo, 0, "")
+}
+
+// saveHistoryWithAudio stores audioData in the history store and records it,
+// linked to parentID when the audio was regenerated from another record and
+// to scriptSession when it is a line of a rendered script
+func (m *TTSHistoryManager) saveHistoryWithAudio(ctx context.Context, request *domain.TTSRequest, audioData io.ReadCloser, billingInfo *http.BillingInfo, parentID int, scriptSession string) (*domain.TTSHistory, error) {
 	if !m.config.HistoryEnabled {
 		if audioData != nil {
 			audioData.Close()
@@ -149,6 +187,7 @@ func (m *TTSHistoryManager) SaveHistoryWithAudio(ctx context.Context, request *d
 		os.Remove(filePath) // Clean up on error
 		return nil, fmt.Errorf("failed to write audio data: %w", err)
 	}
+	m.shareCachedAudio(ctx, request, filePath)
 
 	// Extract credits from billing info
 	var credits *float64
@@ -169,7 +208,10 @@ func (m *TTSHistoryManager) SaveHistoryWithAudio(ctx context.Context, request *d
 		Text:          request.Text,
 		ModelUUID:     request.ModelUUID,
 		Credits:       credits,
+		ParentID:      parentID,
+		ScriptSession: scriptSession,
 	}
+	domain.HistoryLabelsFrom(ctx).Apply(history)
 
 	// Save history record
 	if _, err := m.historyRepo.Save(ctx, history); err != nil {
@@ -237,8 +279,19 @@ func (m *TTSHistoryManager) PlayHistory(ctx context.Context, id int, playbackOpt
     // Detach playback from request context to allow asynchronous completion
     playbackCtx := context.Background()
 
+	if playbackOptions.Mode == nil || *playbackOptions.Mode == domain.PlaybackModeImmediate {
+		if err := interruptPlayer(m.audioPlayer); err != nil {
+			return fmt.Errorf("failed to stop current playback: %w", err)
+		}
+	}
+	if playbackOptions.Volume != nil {
+		if err := m.audioPlayer.SetVolume(*playbackOptions.Volume); err != nil {
+			return fmt.Errorf("failed to set volume: %w", err)
+		}
+	}
+
     // Play from in-memory buffer for stability
-    return m.audioPlayer.Play(playbackCtx, bytes.NewReader(data), format)
+    return playRequest(playbackCtx, m.audioPlayer, bytes.NewReader(data), format, playbackOptions)
 }
 
 // DeleteHistory removes a history record
@@ -0,0 +1,124 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// playbackPollInterval is how often PlayHistorySequence checks whether the
+// current item has finished
+const playbackPollInterval = 100 * time.Millisecond
+
+// RegenerateHistory re-submits the request stored in a history record with
+// overrides applied and saves the new audio as a record whose ParentID points
+// to the original. Texts longer than domain.MaxTextLength are synthesized in
+// chunks like the original long-text synthesis.
+func (m *TTSHistoryManager) RegenerateHistory(ctx context.Context, id int, overrides *domain.TTSRequestOverrides) (*domain.TTSHistory, error) {
+	original, err := m.GetHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if original.Request == nil {
+		return nil, fmt.Errorf("history record %d has no stored request to regenerate", id)
+	}
+
+	request := overrides.Apply(original.Request)
+	synthesizer := NewTTSSynthesizer(m.ttsRepo)
+
+	var audioData io.ReadCloser
+	var billingInfo *http.BillingInfo
+	if len(request.Text) > domain.MaxTextLength {
+		var buf bytes.Buffer
+		result, err := synthesizer.SynthesizeLong(ctx, request, nil, &buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to regenerate record %d: %w", id, err)
+		}
+		audioData = io.NopCloser(&buf)
+		billingInfo = result.BillingInfo
+	} else {
+		if err := synthesizer.ValidateRequest(request); err != nil {
+			return nil, err
+		}
+		response, err := synthesizer.Synthesize(ctx, request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to regenerate record %d: %w", id, err)
+		}
+		audioData = response.AudioData
+		billingInfo = response.BillingInfo
+	}
+
+	// The regenerated record keeps the original's labels unless relabeled
+	if domain.HistoryLabelsFrom(ctx) == nil {
+		ctx = domain.WithHistoryLabels(ctx, &domain.HistoryLabels{Project: original.Project, Profile: original.Profile, Tags: original.Tags})
+	}
+	history, err := m.saveHistoryWithAudio(ctx, request, audioData, billingInfo, original.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		return nil, fmt.Errorf("history management is disabled")
+	}
+	return history, nil
+}
+
+// PlayHistorySequence plays the given records one after another, waiting for
+// each to finish and pausing for gap in between, e.g. to A/B compare a record
+// with its regenerated version. Cancelling ctx stops playback.
+func (m *TTSHistoryManager) PlayHistorySequence(ctx context.Context, ids []int, gap time.Duration, onStart func(index int, history *domain.TTSHistory)) error {
+	for i, id := range ids {
+		history, err := m.GetHistory(ctx, id)
+		if err != nil {
+			return err
+		}
+		if i > 0 && gap > 0 {
+			if err := sleepContext(ctx, gap); err != nil {
+				return err
+			}
+		}
+		if onStart != nil {
+			onStart(i, history)
+		}
+		if err := m.PlayHistory(ctx, id, nil); err != nil {
+			return err
+		}
+		if err := m.waitForPlayback(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForPlayback blocks until the audio player is no longer playing
+func (m *TTSHistoryManager) waitForPlayback(ctx context.Context) error {
+	ticker := time.NewTicker(playbackPollInterval)
+	defer ticker.Stop()
+
+	for m.audioPlayer.IsPlaying() {
+		select {
+		case <-ctx.Done():
+			m.audioPlayer.Stop()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// sleepContext waits for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/infrastructure"
+)
+
+func TestTTSRequestOverridesApply(t *testing.T) {
+	styleName := "happy"
+	original := domain.NewTTSRequestBuilder("model-a", "text").
+		WithSpeaker("speaker-a").
+		WithStyleName(styleName).
+		WithSpeakingRate(1.0).
+		Build()
+
+	rate, style := 1.5, 2
+	got := (&domain.TTSRequestOverrides{SpeakingRate: &rate, StyleID: &style}).Apply(original)
+	if *got.SpeakingRate != 1.5 || *got.StyleID != 2 || got.StyleName != nil || *got.SpeakerUUID != "speaker-a" {
+		t.Errorf("Apply() = %+v", got)
+	}
+	if *original.SpeakingRate != 1.0 || original.StyleID != nil {
+		t.Error("Apply() must not modify the stored request")
+	}
+
+	model := "model-b"
+	switched := (&domain.TTSRequestOverrides{ModelUUID: &model}).Apply(original)
+	if switched.ModelUUID != "model-b" || switched.SpeakerUUID != nil || switched.StyleName != nil {
+		t.Errorf("switching models must drop the old speaker and style, got %+v", switched)
+	}
+
+	if copied := (*domain.TTSRequestOverrides)(nil).Apply(original); copied == original || copied.Text != "text" {
+		t.Error("nil overrides must return a copy")
+	}
+}
+
+func TestRegenerateHistory(t *testing.T) {
+	ctx := context.Background()
+	storePath := t.TempDir()
+	cfg := config.NewConfig("test-key").WithHistoryStorePath(storePath)
+	ttsRepo := &wavTTSRepo{format: audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}}
+	player := &mockPlayer{}
+	m := NewTTSHistoryManager(infrastructure.NewFileHistoryRepository(storePath), ttsRepo, player, cfg)
+
+	request := domain.NewTTSRequestBuilder("model", "こんにちは").WithOutputFormat(domain.OutputFormatWAV).Build()
+	response, _ := ttsRepo.Synthesize(ctx, request)
+	original, err := m.SaveHistoryWithAudio(ctx, request, response.AudioData, response.BillingInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pitch := 0.1
+	regenerated, err := m.RegenerateHistory(ctx, original.ID, &domain.TTSRequestOverrides{Pitch: &pitch})
+	if err != nil {
+		t.Fatalf("RegenerateHistory() error = %v", err)
+	}
+	if regenerated.ID == original.ID || regenerated.ParentID != original.ID {
+		t.Errorf("regenerated record ID/ParentID = %d/%d, want new ID linked to %d", regenerated.ID, regenerated.ParentID, original.ID)
+	}
+	if regenerated.Request.Pitch == nil || *regenerated.Request.Pitch != 0.1 || regenerated.Text != "こんにちは" {
+		t.Errorf("regenerated request = %+v", regenerated.Request)
+	}
+	if sent := ttsRepo.requests[len(ttsRepo.requests)-1]; sent.Pitch == nil || *sent.Pitch != 0.1 {
+		t.Error("overrides were not sent to the API")
+	}
+	if _, err := os.Stat(regenerated.FilePath); err != nil || filepath.Dir(regenerated.FilePath) != filepath.Join(storePath, "audio") {
+		t.Errorf("regenerated audio at %q: %v", regenerated.FilePath, err)
+	}
+	if stored, _ := m.GetHistory(ctx, regenerated.ID); stored.ParentID != original.ID {
+		t.Errorf("stored ParentID = %d, want %d", stored.ParentID, original.ID)
+	}
+
+	if err := m.PlayHistorySequence(ctx, []int{original.ID, regenerated.ID}, time.Millisecond, nil); err != nil {
+		t.Fatalf("PlayHistorySequence() error = %v", err)
+	}
+	if player.playCount != 2 {
+		t.Errorf("played %d records, want 2", player.playCount)
+	}
+
+	bare := &domain.TTSHistory{Text: "recovered", FileFormat: "wav"}
+	if _, err := m.historyRepo.Save(ctx, bare); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.RegenerateHistory(ctx, bare.ID, nil); err == nil {
+		t.Error("records without a stored request cannot be regenerated")
+	}
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// fuzzyWeight caps the score of a term that matched only partially, so an
+// exact match always ranks above a fuzzy one
+const fuzzyWeight = 0.9
+
+// SearchHistory ranks history records by how well their text matches the
+// query. Records must match every term; shorter texts that match are ranked
+// higher, and ties are broken by recency.
+func (m *TTSHistoryManager) SearchHistory(ctx context.Context, query *domain.TTSHistoryQuery) (*domain.TTSHistorySearchResponse, error) {
+	terms := strings.Fields(normalizeSearchText(query.Query))
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if query.MinScore < 0 || query.MinScore > 1 {
+		return nil, fmt.Errorf("min score must be between 0 and 1")
+	}
+	minScore := query.MinScore
+	if minScore == 0 && query.Fuzzy {
+		minScore = domain.DefaultFuzzyMinScore
+	}
+	limit := query.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	offset := query.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Filters the repositories support natively narrow the candidates first;
+	// the remaining ones are applied in memory
+	request := domain.NewTTSHistorySearchRequest().Build()
+	request.ModelUUID = query.ModelUUID
+	request.StartDate = query.StartDate
+	request.EndDate = query.EndDate
+	candidates, err := m.listAllHistory(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*domain.TTSHistorySearchResult
+	for _, history := range candidates {
+		if !matchesHistoryQuery(history, query) {
+			continue
+		}
+		score := scoreHistoryText(terms, normalizeSearchText(history.Text), query.Fuzzy)
+		if score <= 0 || score < minScore {
+			continue
+		}
+		results = append(results, &domain.TTSHistorySearchResult{History: history, Score: score})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if !results[i].History.CreatedAt.Equal(results[j].History.CreatedAt) {
+			return results[i].History.CreatedAt.After(results[j].History.CreatedAt)
+		}
+		return results[i].History.ID > results[j].History.ID
+	})
+
+	total := len(results)
+	start := offset
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+
+	return &domain.TTSHistorySearchResponse{
+		Results: results[start:end],
+		Total:   total,
+		Limit:   limit,
+		Offset:  offset,
+		HasMore: end < total,
+	}, nil
+}
+
+// listAllHistory returns every record matching request, ignoring its pagination
+func (m *TTSHistoryManager) listAllHistory(ctx context.Context, request *domain.TTSHistorySearchRequest) ([]*domain.TTSHistory, error) {
+	count, err := m.historyRepo.Count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+
+	all := *request
+	all.Limit = count
+	all.Offset = 0
+
+	response, err := m.historyRepo.List(ctx, &all)
+	if err != nil {
+		return nil, err
+	}
+	return response.Histories, nil
+}
+
+// matchesHistoryQuery applies the query filters to a record
+func matchesHistoryQuery(history *domain.TTSHistory, query *domain.TTSHistoryQuery) bool {
+	if query.ModelUUID != nil && history.ModelUUID != *query.ModelUUID {
+		return false
+	}
+	if query.StartDate != nil && history.CreatedAt.Before(*query.StartDate) {
+		return false
+	}
+	if query.EndDate != nil && history.CreatedAt.After(*query.EndDate) {
+		return false
+	}
+	if query.FileFormat != nil && !strings.EqualFold(history.FileFormat, *query.FileFormat) {
+		return false
+	}
+	if query.MinFileSize != nil && history.FileSizeBytes < *query.MinFileSize {
+		return false
+	}
+	if query.MaxFileSize != nil && history.FileSizeBytes > *query.MaxFileSize {
+		return false
+	}
+
+	// Records without billing information never match a credits range
+	if query.MinCredits != nil || query.MaxCredits != nil {
+		if history.Credits == nil {
+			return false
+		}
+		if query.MinCredits != nil && *history.Credits < *query.MinCredits {
+			return false
+		}
+		if query.MaxCredits != nil && *history.Credits > *query.MaxCredits {
+			return false
+		}
+	}
+
+	if query.SpeakerUUID != nil || query.StyleID != nil || query.StyleName != nil {
+		request := history.Request
+		if request == nil {
+			return false
+		}
+		if query.SpeakerUUID != nil && (request.SpeakerUUID == nil || *request.SpeakerUUID != *query.SpeakerUUID) {
+			return false
+		}
+		if query.StyleID != nil && (request.StyleID == nil || *request.StyleID != *query.StyleID) {
+			return false
+		}
+		if query.StyleName != nil && (request.StyleName == nil || *request.StyleName != *query.StyleName) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// scoreHistoryText scores normalized text against normalized query terms.
+// Each term scores 1 when it occurs in the text and, in fuzzy mode, the share
+// of its character bigrams found in the text otherwise. The mean term score
+// is weighted by how much of the text the query covers, so a text equal to
+// the query scores 1. A term that does not match at all scores the text 0.
+func scoreHistoryText(terms []string, text string, fuzzy bool) float64 {
+	var match float64
+	queryLength := 0
+	for _, term := range terms {
+		score := 0.0
+		if strings.Contains(text, term) {
+			score = 1
+		} else if fuzzy {
+			score = bigramCoverage(term, text) * fuzzyWeight
+		}
+		if score == 0 {
+			return 0
+		}
+		match += score
+		queryLength += utf8.RuneCountInString(term)
+	}
+	match /= float64(len(terms))
+
+	textLength := utf8.RuneCountInString(strings.Join(strings.Fields(text), ""))
+	brevity := 1.0
+	if textLength > queryLength {
+		brevity = float64(queryLength) / float64(textLength)
+	}
+
+	return match * (0.8 + 0.2*brevity)
+}
+
+// bigramCoverage returns the share of the term's character bigrams that occur
+// in text, counting repeated bigrams only as often as the text contains them
+func bigramCoverage(term, text string) float64 {
+	termGrams := bigrams(term)
+	if len(termGrams) == 0 {
+		return 0
+	}
+
+	available := make(map[string]int)
+	for _, gram := range bigrams(text) {
+		available[gram]++
+	}
+
+	matched := 0
+	for _, gram := range termGrams {
+		if available[gram] > 0 {
+			available[gram]--
+			matched++
+		}
+	}
+	return float64(matched) / float64(len(termGrams))
+}
+
+// bigrams splits s into overlapping two-character n-grams, skipping spaces
+func bigrams(s string) []string {
+	runes := []rune(strings.Join(strings.Fields(s), ""))
+	if len(runes) < 2 {
+		return nil
+	}
+
+	grams := make([]string, 0, len(runes)-1)
+	for i := 0; i+1 < len(runes); i++ {
+		grams = append(grams, string(runes[i:i+2]))
+	}
+	return grams
+}
+
+// normalizeSearchText folds text so equivalent spellings compare equal:
+// letters are lower-cased, full-width ASCII and the ideographic space become
+// their ASCII forms, and katakana becomes hiragana
+func normalizeSearchText(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for _, r := range s {
+		switch {
+		case r == '　':
+			r = ' '
+		case r >= '！' && r <= '～':
+			r -= 0xFEE0
+		case r >= 'ァ' && r <= 'ヶ':
+			r -= 0x60
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
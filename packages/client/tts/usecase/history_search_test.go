@@ -0,0 +1,134 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// memoryHistoryRepo serves a fixed set of records; only the methods used by
+// SearchHistory are implemented
+type memoryHistoryRepo struct {
+	domain.TTSHistoryRepository
+	records []*domain.TTSHistory
+}
+
+func (r *memoryHistoryRepo) Count(ctx context.Context) (int, error) {
+	return len(r.records), nil
+}
+
+func (r *memoryHistoryRepo) List(ctx context.Context, request *domain.TTSHistorySearchRequest) (*domain.TTSHistoryListResponse, error) {
+	var out []*domain.TTSHistory
+	for _, h := range r.records {
+		if request.ModelUUID == nil || h.ModelUUID == *request.ModelUUID {
+			out = append(out, h)
+		}
+	}
+	return &domain.TTSHistoryListResponse{Histories: out, Total: len(out)}, nil
+}
+
+func TestNormalizeSearchText(t *testing.T) {
+	tests := map[string]string{
+		"Hello World": "hello world",
+		"ＡＢＣ１２３":      "abc123",
+		"カタカナ　テスト":    "かたかな てすと",
+		"ヴァイオリン":      "ゔぁいおりん",
+	}
+	for in, want := range tests {
+		if got := normalizeSearchText(in); got != want {
+			t.Errorf("normalizeSearchText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestScoreHistoryText(t *testing.T) {
+	score := func(query, text string, fuzzy bool) float64 {
+		return scoreHistoryText([]string{normalizeSearchText(query)}, normalizeSearchText(text), fuzzy)
+	}
+
+	if got := score("こんにちは", "こんにちは", false); got != 1 {
+		t.Errorf("identical text scored %v, want 1", got)
+	}
+	if score("こんにちは", "こんにちは", false) <= score("こんにちは", "こんにちは、今日はいい天気ですね", false) {
+		t.Error("a shorter matching text must rank higher")
+	}
+	if got := score("コンニチハ", "こんにちは", false); got != 1 {
+		t.Errorf("katakana query scored %v against hiragana text, want 1", got)
+	}
+	if got := score("こんにちわ", "こんにちは", false); got != 0 {
+		t.Errorf("non-fuzzy typo scored %v, want 0", got)
+	}
+	typo := score("konichiwa", "konnichiwa everyone", true)
+	if typo <= 0 || typo >= score("konnichiwa", "konnichiwa everyone", true) {
+		t.Errorf("fuzzy typo scored %v, want between 0 and the exact score", typo)
+	}
+	if got := scoreHistoryText([]string{"hello", "moon"}, "hello world", false); got != 0 {
+		t.Errorf("every term must match, got %v", got)
+	}
+}
+
+func TestSearchHistory(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	credits := func(v float64) *float64 { return &v }
+	record := func(id int, text, model string, format string, size int64, credit *float64, style int) *domain.TTSHistory {
+		request := domain.NewTTSRequestBuilder(model, text).WithStyleID(style).Build()
+		return &domain.TTSHistory{
+			ID: id, Text: text, ModelUUID: model, FileFormat: format, FileSizeBytes: size,
+			Credits: credit, Request: request, CreatedAt: base.Add(time.Duration(id) * time.Hour),
+		}
+	}
+	manager := &TTSHistoryManager{historyRepo: &memoryHistoryRepo{records: []*domain.TTSHistory{
+		record(1, "今日は良い天気ですね", "m1", "wav", 1000, credits(1), 0),
+		record(2, "良い天気", "m1", "mp3", 200, credits(0.2), 1),
+		record(3, "明日の天気予報をお伝えします", "m2", "wav", 5000, nil, 0),
+		record(4, "お元気ですか", "m1", "wav", 300, credits(0.3), 0),
+	}}}
+	ctx := context.Background()
+
+	ids := func(response *domain.TTSHistorySearchResponse) []int {
+		var out []int
+		for _, r := range response.Results {
+			out = append(out, r.History.ID)
+		}
+		return out
+	}
+	minSize := int64(500)
+	tests := []struct {
+		name  string
+		query *domain.TTSHistoryQuery
+		want  []int
+	}{
+		{"ranked by relevance", domain.NewTTSHistoryQuery("天気").Build(), []int{2, 1, 3}},
+		{"all terms must match", domain.NewTTSHistoryQuery("良い 天気").Build(), []int{2, 1}},
+		{"model filter", domain.NewTTSHistoryQuery("天気").WithModelUUID("m2").Build(), []int{3}},
+		{"format filter", domain.NewTTSHistoryQuery("天気").WithFileFormat("WAV").Build(), []int{1, 3}},
+		{"style filter", domain.NewTTSHistoryQuery("天気").WithStyleID(1).Build(), []int{2}},
+		{"credits range", domain.NewTTSHistoryQuery("天気").WithCreditsRange(credits(0.5), nil).Build(), []int{1}},
+		{"size range", domain.NewTTSHistoryQuery("天気").WithFileSizeRange(&minSize, nil).Build(), []int{1, 3}},
+		{"fuzzy", domain.NewTTSHistoryQuery("天気予報お伝え").WithFuzzy(true).Build(), []int{3}},
+		{"pagination", domain.NewTTSHistoryQuery("天気").WithLimit(1).WithOffset(1).Build(), []int{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := manager.SearchHistory(ctx, tt.query)
+			if err != nil {
+				t.Fatalf("SearchHistory() error = %v", err)
+			}
+			got := ids(response)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SearchHistory() IDs = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("SearchHistory() IDs = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if _, err := manager.SearchHistory(ctx, domain.NewTTSHistoryQuery("  ").Build()); err == nil {
+		t.Error("an empty query must be rejected")
+	}
+}
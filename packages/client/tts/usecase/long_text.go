@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// SplitText splits text into chunks of at most maxLength bytes (the unit used by
+// ValidateRequest). Chunks break at Japanese sentence endings (。！？) and line
+// breaks; a sentence that is still too long is split at commas (、，), and as a
+// last resort at a character boundary.
+func SplitText(text string, maxLength int) []string {
+	if maxLength <= 0 || maxLength > domain.MaxTextLength {
+		maxLength = domain.MaxTextLength
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+	for _, piece := range splitPieces(text, maxLength) {
+		if current.Len() > 0 && current.Len()+len(piece) > maxLength {
+			flush()
+		}
+		current.WriteString(piece)
+	}
+	flush()
+
+	return chunks
+}
+
+// SplitSentences splits text like SplitText but never packs sentences
+// together, so that each chunk is a single sentence (or part of one that is
+// longer than maxLength). It is used to time subtitles per sentence.
+func SplitSentences(text string, maxLength int) []string {
+	if maxLength <= 0 || maxLength > domain.MaxTextLength {
+		maxLength = domain.MaxTextLength
+	}
+
+	var sentences []string
+	for _, piece := range splitPieces(text, maxLength) {
+		if sentence := strings.TrimSpace(piece); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+	}
+	return sentences
+}
+
+// splitPieces breaks text into sentences of at most maxLength bytes, falling
+// back to clauses and character boundaries for longer sentences
+func splitPieces(text string, maxLength int) []string {
+	var pieces []string
+	for _, sentence := range splitAfter(text, isSentenceEnd) {
+		if len(sentence) <= maxLength {
+			pieces = append(pieces, sentence)
+			continue
+		}
+		for _, clause := range splitAfter(sentence, isClauseEnd) {
+			pieces = append(pieces, splitBytes(clause, maxLength)...)
+		}
+	}
+	return pieces
+}
+
+func isSentenceEnd(r rune) bool {
+	switch r {
+	case '。', '！', '？', '!', '?', '\n':
+		return true
+	}
+	return false
+}
+
+func isClauseEnd(r rune) bool {
+	return r == '、' || r == '，' || r == ','
+}
+
+// isClosing reports whether r closes a quotation that belongs to the preceding sentence
+func isClosing(r rune) bool {
+	switch r {
+	case '」', '』', '）', ')', '】', '”', '’':
+		return true
+	}
+	return false
+}
+
+// splitAfter splits text after every rune matching isEnd, keeping closing
+// brackets and repeated punctuation attached to the preceding piece
+func splitAfter(text string, isEnd func(rune) bool) []string {
+	var pieces []string
+	start := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		i += size
+		if !isEnd(r) {
+			continue
+		}
+		for i < len(text) {
+			next, nextSize := utf8.DecodeRuneInString(text[i:])
+			if next == '\n' || !(isClosing(next) || isEnd(next)) {
+				break
+			}
+			i += nextSize
+		}
+		pieces = append(pieces, text[start:i])
+		start = i
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
+
+// splitBytes cuts text into pieces of at most maxLength bytes without breaking runes
+func splitBytes(text string, maxLength int) []string {
+	var pieces []string
+	for len(text) > maxLength {
+		cut := maxLength
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		pieces = append(pieces, text[:cut])
+		text = text[cut:]
+	}
+	return append(pieces, text)
+}
+
+// SynthesizeLong synthesizes text of any length and writes a single WAV or FLAC
+// file. Chunks are synthesized in parallel and joined with options.ChunkSilence.
+// SSML requests are rejected, since the text is split without regard to markup.
+func (s *TTSSynthesizer) SynthesizeLong(ctx context.Context, request *domain.TTSRequest, options *domain.LongTextOptions, writer io.Writer) (*domain.LongTextResult, error) {
+	format := domain.OutputFormatWAV
+	if request.OutputFormat != nil {
+		format = *request.OutputFormat
+	}
+	if format != domain.OutputFormatWAV && format != domain.OutputFormatFLAC {
+		return nil, &ValidationError{Field: "OutputFormat", Message: "Long text synthesis supports only wav and flac output"}
+	}
+
+	options = normalizeLongTextOptions(options)
+	var combined *audio.Buffer
+	result, err := s.synthesizeChunks(ctx, request, options, func(index, total int, chunk *audio.Buffer) error {
+		if combined == nil {
+			combined = &audio.Buffer{Format: chunk.Format}
+		} else {
+			combined.Data = append(combined.Data, audio.Silence(chunk.Format, options.ChunkSilence)...)
+		}
+		combined.Data = append(combined.Data, chunk.Data...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case domain.OutputFormatFLAC:
+		err = combined.WriteFLAC(writer)
+	default:
+		err = combined.WriteWAV(writer)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write audio: %w", err)
+	}
+
+	result.Duration = combined.Duration()
+	return result, nil
+}
+
+// SynthesizeLongStream synthesizes text of any length as one continuous WAV stream.
+// The first chunk is delivered to the handler as soon as it is ready, so playback
+// can start while later chunks are still being synthesized.
+func (s *TTSSynthesizer) SynthesizeLongStream(ctx context.Context, request *domain.TTSRequest, options *domain.LongTextOptions, handler domain.TTSStreamHandler) (*domain.LongTextResult, error) {
+	options = normalizeLongTextOptions(options)
+
+	var duration time.Duration
+	result, err := s.synthesizeChunks(ctx, request, options, func(index, total int, chunk *audio.Buffer) error {
+		var data bytes.Buffer
+		if index == 0 {
+			if err := audio.WriteStreamingWAVHeader(&data, chunk.Format); err != nil {
+				return err
+			}
+		} else {
+			data.Write(audio.Silence(chunk.Format, options.ChunkSilence))
+			duration += options.ChunkSilence
+		}
+		data.Write(chunk.Data)
+		duration += chunk.Duration()
+
+		return handler.OnChunk(&domain.TTSStreamChunk{
+			Data:      data.Bytes(),
+			Timestamp: time.Now(),
+			IsLast:    index == total-1,
+		})
+	})
+	if err != nil {
+		handler.OnError(err)
+		return nil, err
+	}
+
+	result.Duration = duration
+	return result, handler.OnComplete()
+}
+
+func normalizeLongTextOptions(options *domain.LongTextOptions) *domain.LongTextOptions {
+	defaults := domain.DefaultLongTextOptions()
+	if options == nil {
+		return defaults
+	}
+	normalized := *options
+	if normalized.MaxChunkLength <= 0 || normalized.MaxChunkLength > domain.MaxTextLength {
+		normalized.MaxChunkLength = defaults.MaxChunkLength
+	}
+	if normalized.Concurrency <= 0 {
+		normalized.Concurrency = defaults.Concurrency
+	}
+	if normalized.ChunkSilence < 0 {
+		normalized.ChunkSilence = 0
+	}
+	return &normalized
+}
+
+type chunkResult struct {
+	audio   *audio.Buffer
+	billing *http.BillingInfo
+	err     error
+}
+
+// synthesizeChunks splits the request text, synthesizes the chunks as WAV with
+// bounded parallelism and hands the decoded audio to emit in text order
+func (s *TTSSynthesizer) synthesizeChunks(ctx context.Context, request *domain.TTSRequest, options *domain.LongTextOptions, emit func(index, total int, chunk *audio.Buffer) error) (*domain.LongTextResult, error) {
+	// A chunk boundary inside an element would leave both halves malformed
+	if request.UseSSML != nil && *request.UseSSML {
+		return nil, &ValidationError{Field: "UseSSML", Message: "Long text synthesis does not support SSML"}
+	}
+
+	split := SplitText
+	if options.SentencePerChunk {
+		split = SplitSentences
+	}
+	chunks := split(request.Text, options.MaxChunkLength)
+	if len(chunks) == 0 {
+		return nil, &ValidationError{Field: "Text", Message: "Text is required"}
+	}
+
+	requests := make([]*domain.TTSRequest, len(chunks))
+	for i, text := range chunks {
+		requests[i] = chunkRequest(request, text, i == 0, i == len(chunks)-1)
+		if err := s.ValidateRequest(requests[i]); err != nil {
+			return nil, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+	}
+
+	result := &domain.LongTextResult{Chunks: len(chunks)}
+	var offset time.Duration
+	err := s.synthesizeInOrder(ctx, requests, options.Concurrency, func(i int, r chunkResult) error {
+		if r.err != nil {
+			return fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), r.err)
+		}
+		if i == 0 {
+			result.Format = r.audio.Format
+		} else if r.audio.Format != result.Format {
+			return fmt.Errorf("chunk %d/%d: audio format %s differs from %s", i+1, len(chunks), r.audio.Format, result.Format)
+		}
+
+		if err := emit(i, len(chunks), r.audio); err != nil {
+			return err
+		}
+		if i > 0 {
+			offset += options.ChunkSilence
+		}
+		result.Cues = append(result.Cues, chunkCue(i+1, chunks[i], offset, r.audio))
+		offset += r.audio.Duration()
+		result.BillingInfo = mergeBillingInfo(result.BillingInfo, r.billing)
+
+		if options.OnProgress != nil {
+			options.OnProgress(i+1, len(chunks))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// synthesizeInOrder synthesizes requests with at most concurrency requests in
+// flight and hands each result to emit in request order. Emitting stops at the
+// first error, which is returned.
+func (s *TTSSynthesizer) synthesizeInOrder(ctx context.Context, requests []*domain.TTSRequest, concurrency int, emit func(index int, result chunkResult) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]chan chunkResult, len(requests))
+	for i := range results {
+		results[i] = make(chan chunkResult, 1)
+	}
+
+	// Start requests in order so the first one is ready as early as possible
+	go func() {
+		sem := make(chan struct{}, concurrency)
+		for i := range requests {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				results[i] <- chunkResult{err: ctx.Err()}
+				continue
+			}
+			go func(i int) {
+				defer func() { <-sem }()
+				results[i] <- s.synthesizeChunk(ctx, requests[i])
+			}(i)
+		}
+	}()
+
+	for i := range requests {
+		if err := emit(i, <-results[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkCue times the text of a chunk that starts at offset in the joined audio.
+// The cue covers the audible part of the chunk, so leading and trailing silence
+// does not keep the text on screen; silent chunks span their whole duration.
+func chunkCue(index int, text string, offset time.Duration, chunk *audio.Buffer) domain.SubtitleCue {
+	start, end := chunk.AudibleRange(audio.DefaultTrimThreshold)
+	if start == end {
+		start, end = 0, chunk.Frames()
+	}
+	rate := time.Duration(chunk.Format.SampleRate)
+	return domain.SubtitleCue{
+		Index: index,
+		Start: offset + time.Duration(start)*time.Second/rate,
+		End:   offset + time.Duration(end)*time.Second/rate,
+		Text:  text,
+	}
+}
+
+func (s *TTSSynthesizer) synthesizeChunk(ctx context.Context, request *domain.TTSRequest) chunkResult {
+	response, err := s.repository.Synthesize(ctx, request)
+	if err != nil {
+		return chunkResult{err: err}
+	}
+	defer response.AudioData.Close()
+
+	buffer, err := audio.DecodeWAV(response.AudioData)
+	if err != nil {
+		return chunkResult{err: fmt.Errorf("failed to decode chunk audio: %w", err)}
+	}
+	return chunkResult{audio: buffer, billing: response.BillingInfo}
+}
+
+// chunkRequest derives the request for one chunk. Chunks are always requested as
+// WAV so they can be joined losslessly, and only the outer chunks keep the
+// leading and trailing silence of the original request.
+func chunkRequest(request *domain.TTSRequest, text string, first, last bool) *domain.TTSRequest {
+	chunk := *request
+	chunk.Text = text
+
+	wav := domain.OutputFormatWAV
+	chunk.OutputFormat = &wav
+	chunk.OutputBitrate = nil
+
+	zero := 0.0
+	if !first {
+		chunk.LeadingSilenceSeconds = &zero
+	}
+	if !last {
+		chunk.TrailingSilenceSeconds = &zero
+	}
+	return &chunk
+}
+
+// mergeBillingInfo accumulates per-chunk billing headers into a single summary
+func mergeBillingInfo(total, next *http.BillingInfo) *http.BillingInfo {
+	if next == nil {
+		return total
+	}
+	if total == nil {
+		merged := *next
+		return &merged
+	}
+
+	total.CreditsUsed = sumNumeric(total.CreditsUsed, next.CreditsUsed)
+	total.CharacterCount = sumNumeric(total.CharacterCount, next.CharacterCount)
+	if next.BillingMode != "" {
+		total.BillingMode = next.BillingMode
+	}
+	// Chunks finish out of order; the lowest balance is the most recent one
+	if remaining, err := strconv.ParseFloat(next.CreditsRemaining, 64); err == nil {
+		if current, err := strconv.ParseFloat(total.CreditsRemaining, 64); err != nil || remaining < current {
+			total.CreditsRemaining = next.CreditsRemaining
+		}
+	}
+	if next.RateLimitRemaining != "" {
+		total.RateLimitRequests = next.RateLimitRequests
+		total.RateLimitRemaining = next.RateLimitRemaining
+		total.RateLimitReset = next.RateLimitReset
+	}
+	return total
+}
+
+func sumNumeric(a, b string) string {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	switch {
+	case errA != nil && errB != nil:
+		return a
+	case errA != nil:
+		return b
+	case errB != nil:
+		return a
+	}
+	return strconv.FormatFloat(x+y, 'f', -1, 64)
+}
@@ -0,0 +1,346 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/common/http"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+func TestSplitText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		maxLength int
+		want      []string
+	}{
+		{
+			name:      "fits in one chunk",
+			text:      "こんにちは。元気ですか？",
+			maxLength: 100,
+			want:      []string{"こんにちは。元気ですか？"},
+		},
+		{
+			name:      "splits at sentence endings",
+			text:      "一文目です。二文目です！三文目ですか？",
+			maxLength: 21,
+			want:      []string{"一文目です。", "二文目です！", "三文目ですか？"},
+		},
+		{
+			name:      "packs sentences greedily",
+			text:      "あ。い。う。え。お。",
+			maxLength: 12,
+			want:      []string{"あ。い。", "う。え。", "お。"},
+		},
+		{
+			name:      "keeps closing brackets with the sentence",
+			text:      "「行くよ！」と言った。",
+			maxLength: 18,
+			want:      []string{"「行くよ！」", "と言った。"},
+		},
+		{
+			name:      "splits at line breaks",
+			text:      "見出し\n本文です。",
+			maxLength: 15,
+			want:      []string{"見出し", "本文です。"},
+		},
+		{
+			name:      "falls back to commas",
+			text:      "とても長い文で、読点で区切られて、まだ続きます。",
+			maxLength: 30,
+			want:      []string{"とても長い文で、", "読点で区切られて、", "まだ続きます。"},
+		},
+		{
+			name:      "hard splits without breaking characters",
+			text:      strings.Repeat("あ", 10),
+			maxLength: 7,
+			want:      []string{"ああ", "ああ", "ああ", "ああ", "ああ"},
+		},
+		{
+			name:      "drops blank chunks",
+			text:      "\n\n  \n",
+			maxLength: 10,
+			want:      nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitText(tt.text, tt.maxLength)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("SplitText() = %q, want %q", got, tt.want)
+			}
+			for _, chunk := range got {
+				if len(chunk) > tt.maxLength {
+					t.Errorf("chunk %q exceeds %d bytes", chunk, tt.maxLength)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitTextRespectsRequestLimit(t *testing.T) {
+	text := strings.Repeat("これは長い小説の一文です。", 1000)
+	chunks := SplitText(text, 0)
+	if len(chunks) < 2 {
+		t.Fatalf("expected multiple chunks, got %d", len(chunks))
+	}
+	if strings.Join(chunks, "") != text {
+		t.Error("chunks do not reassemble to the original text")
+	}
+	for i, chunk := range chunks {
+		if len(chunk) > domain.MaxTextLength {
+			t.Errorf("chunk %d is %d bytes", i, len(chunk))
+		}
+	}
+}
+
+// wavTTSRepo returns a short WAV per request whose samples encode the request text length
+type wavTTSRepo struct {
+	mu       sync.Mutex
+	format   audio.Format
+	requests []*domain.TTSRequest
+	delay    func(text string) time.Duration
+	padding  int // silent frames around audible speech; zero keeps the samples quiet
+}
+
+func (r *wavTTSRepo) Synthesize(ctx context.Context, request *domain.TTSRequest) (*domain.TTSResponse, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, request)
+	remaining := 100 - len(r.requests)
+	r.mu.Unlock()
+
+	if r.delay != nil {
+		time.Sleep(r.delay(request.Text))
+	}
+
+	pcm := make([]byte, 10*r.format.BlockAlign())
+	for i := 0; i < len(pcm); i += 2 {
+		pcm[i] = byte(len(request.Text))
+		if r.padding > 0 {
+			pcm[i+1] = 0x10
+		}
+	}
+	if r.padding > 0 {
+		silence := make([]byte, r.padding*r.format.BlockAlign())
+		pcm = append(append(silence, pcm...), silence...)
+	}
+	var out bytes.Buffer
+	(&audio.Buffer{Format: r.format, Data: pcm}).WriteWAV(&out)
+
+	return &domain.TTSResponse{
+		AudioData: io.NopCloser(&out),
+		BillingInfo: &http.BillingInfo{
+			CreditsUsed:      "0.5",
+			CreditsRemaining: fmt.Sprint(remaining),
+		},
+	}, nil
+}
+
+func (r *wavTTSRepo) SynthesizeStream(ctx context.Context, request *domain.TTSRequest) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func TestSynthesizeLong(t *testing.T) {
+	format := audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}
+	repo := &wavTTSRepo{
+		format: format,
+		// Later chunks finish first to check that output keeps text order
+		delay: func(text string) time.Duration {
+			return time.Duration(20-len(text)) * time.Millisecond
+		},
+	}
+	synth := NewTTSSynthesizer(repo)
+
+	request := domain.NewTTSRequestBuilder("model", "あ。いい。ううう。").
+		WithOutputFormat(domain.OutputFormatWAV).
+		WithLeadingSilence(0.5).
+		WithTrailingSilence(0.5).
+		Build()
+	options := &domain.LongTextOptions{MaxChunkLength: 12, Concurrency: 3, ChunkSilence: 5 * time.Millisecond}
+
+	var out bytes.Buffer
+	result, err := synth.SynthesizeLong(context.Background(), request, options, &out)
+	if err != nil {
+		t.Fatalf("SynthesizeLong() error = %v", err)
+	}
+
+	if result.Chunks != 3 {
+		t.Errorf("Chunks = %d, want 3", result.Chunks)
+	}
+	if result.BillingInfo == nil || result.BillingInfo.CreditsUsed != "1.5" || result.BillingInfo.CreditsRemaining != "97" {
+		t.Errorf("BillingInfo = %+v, want 1.5 credits used and 97 remaining", result.BillingInfo)
+	}
+
+	decoded, err := audio.DecodeWAVBytes(out.Bytes())
+	if err != nil {
+		t.Fatalf("output is not a valid WAV: %v", err)
+	}
+	if decoded.Format != format {
+		t.Errorf("format = %+v, want %+v", decoded.Format, format)
+	}
+	// 3 chunks of 10 frames plus 2 gaps of 5 frames
+	if decoded.Frames() != 40 {
+		t.Errorf("Frames() = %d, want 40", decoded.Frames())
+	}
+	var order []byte
+	for i := 0; i < len(decoded.Data); i += 2 {
+		if v := decoded.Data[i]; v != 0 && (len(order) == 0 || order[len(order)-1] != v) {
+			order = append(order, v)
+		}
+	}
+	if !bytes.Equal(order, []byte{byte(len("あ。")), byte(len("いい。")), byte(len("ううう。"))}) {
+		t.Errorf("chunks were joined out of order: %v", order)
+	}
+
+	for _, req := range repo.requests {
+		if req.OutputFormat == nil || *req.OutputFormat != domain.OutputFormatWAV {
+			t.Error("chunks must be requested as WAV")
+		}
+		leading, trailing := *req.LeadingSilenceSeconds, *req.TrailingSilenceSeconds
+		switch req.Text {
+		case "あ。":
+			if leading != 0.5 || trailing != 0 {
+				t.Errorf("first chunk silence = %v/%v", leading, trailing)
+			}
+		case "ううう。":
+			if leading != 0 || trailing != 0.5 {
+				t.Errorf("last chunk silence = %v/%v", leading, trailing)
+			}
+		}
+	}
+}
+
+func TestSplitSentences(t *testing.T) {
+	got := SplitSentences("あ。い。\n\n「う！」と言った。", 100)
+	want := []string{"あ。", "い。", "「う！」", "と言った。"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("SplitSentences() = %q, want %q", got, want)
+	}
+	// Sentences longer than the limit are still split
+	if got := SplitSentences(strings.Repeat("あ", 4)+"。", 6); len(got) != 3 {
+		t.Errorf("SplitSentences() = %q, want 3 pieces", got)
+	}
+}
+
+func TestSynthesizeLongSubtitles(t *testing.T) {
+	repo := &wavTTSRepo{format: audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}, padding: 3}
+	synth := NewTTSSynthesizer(repo)
+
+	request := domain.NewTTSRequestBuilder("model", "あ。いい。ううう。").Build()
+	options := &domain.LongTextOptions{SentencePerChunk: true, ChunkSilence: 5 * time.Millisecond}
+
+	result, err := synth.SynthesizeLong(context.Background(), request, options, io.Discard)
+	if err != nil {
+		t.Fatalf("SynthesizeLong() error = %v", err)
+	}
+
+	// Each chunk is 16 frames with speech in frames 3 to 13, and chunks are 5 frames apart
+	want := []domain.SubtitleCue{
+		{Index: 1, Start: 3 * time.Millisecond, End: 13 * time.Millisecond, Text: "あ。"},
+		{Index: 2, Start: 24 * time.Millisecond, End: 34 * time.Millisecond, Text: "いい。"},
+		{Index: 3, Start: 45 * time.Millisecond, End: 55 * time.Millisecond, Text: "ううう。"},
+	}
+	if result.Chunks != 3 || fmt.Sprint(result.Cues) != fmt.Sprint(want) {
+		t.Errorf("Cues = %+v, want %+v", result.Cues, want)
+	}
+	if result.Duration != 58*time.Millisecond {
+		t.Errorf("Duration = %v, want 58ms", result.Duration)
+	}
+}
+
+func TestSynthesizeLongFLAC(t *testing.T) {
+	repo := &wavTTSRepo{format: audio.Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}}
+	synth := NewTTSSynthesizer(repo)
+
+	request := domain.NewTTSRequestBuilder("model", "一。二。").WithOutputFormat(domain.OutputFormatFLAC).Build()
+	var out bytes.Buffer
+	if _, err := synth.SynthesizeLong(context.Background(), request, &domain.LongTextOptions{MaxChunkLength: 6}, &out); err != nil {
+		t.Fatalf("SynthesizeLong() error = %v", err)
+	}
+	if !bytes.HasPrefix(out.Bytes(), []byte("fLaC")) {
+		t.Error("output is not FLAC")
+	}
+}
+
+func TestSynthesizeLongRejectsLossyFormats(t *testing.T) {
+	synth := NewTTSSynthesizer(&wavTTSRepo{})
+	request := domain.NewTTSRequestBuilder("model", "text").WithOutputFormat(domain.OutputFormatMP3).Build()
+	if _, err := synth.SynthesizeLong(context.Background(), request, nil, io.Discard); err == nil {
+		t.Error("expected an error for mp3 output")
+	}
+}
+
+func TestSynthesizeLongRejectsSSML(t *testing.T) {
+	repo := &wavTTSRepo{format: audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}}
+	synth := NewTTSSynthesizer(repo)
+	request := domain.NewTTSRequestBuilder("model", "<prosody rate=\"slow\">一文目。二文目。</prosody>").WithSSML(true).Build()
+
+	var validationErr *ValidationError
+	if _, err := synth.SynthesizeLong(context.Background(), request, nil, io.Discard); !errors.As(err, &validationErr) || validationErr.Field != "UseSSML" {
+		t.Errorf("SynthesizeLong() error = %v, want a UseSSML validation error", err)
+	}
+	options := &domain.LongTextOptions{SentencePerChunk: true}
+	if _, err := synth.SynthesizeLongStream(context.Background(), request, options, &collectingStreamHandler{}); !errors.As(err, &validationErr) {
+		t.Errorf("SynthesizeLongStream() error = %v, want a validation error", err)
+	}
+	if len(repo.requests) != 0 {
+		t.Errorf("%d chunks were synthesized", len(repo.requests))
+	}
+}
+
+type collectingStreamHandler struct {
+	chunks    []*domain.TTSStreamChunk
+	completed bool
+}
+
+func (h *collectingStreamHandler) OnChunk(chunk *domain.TTSStreamChunk) error {
+	h.chunks = append(h.chunks, chunk)
+	return nil
+}
+func (h *collectingStreamHandler) OnComplete() error { h.completed = true; return nil }
+func (h *collectingStreamHandler) OnError(err error) {}
+
+func TestSynthesizeLongStream(t *testing.T) {
+	format := audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}
+	synth := NewTTSSynthesizer(&wavTTSRepo{format: format})
+	request := domain.NewTTSRequestBuilder("model", "あ。い。う。").Build()
+
+	handler := &collectingStreamHandler{}
+	result, err := synth.SynthesizeLongStream(context.Background(), request,
+		&domain.LongTextOptions{MaxChunkLength: 6, ChunkSilence: 10 * time.Millisecond}, handler)
+	if err != nil {
+		t.Fatalf("SynthesizeLongStream() error = %v", err)
+	}
+
+	if len(handler.chunks) != 3 || !handler.completed {
+		t.Fatalf("got %d chunks (completed=%v), want 3", len(handler.chunks), handler.completed)
+	}
+	if !handler.chunks[2].IsLast || handler.chunks[0].IsLast {
+		t.Error("only the final chunk should be marked last")
+	}
+	if result.Duration != 50*time.Millisecond {
+		t.Errorf("Duration = %v, want 50ms", result.Duration)
+	}
+
+	var stream bytes.Buffer
+	for _, chunk := range handler.chunks {
+		stream.Write(chunk.Data)
+	}
+	decoded, err := audio.DecodeWAVBytes(stream.Bytes())
+	if err != nil {
+		t.Fatalf("stream is not a valid WAV: %v", err)
+	}
+	if decoded.Frames() != 50 {
+		t.Errorf("Frames() = %d, want 50", decoded.Frames())
+	}
+}
@@ -26,12 +26,28 @@ type AudioPlayerService struct {
 // queueItem represents an item in the playback queue
 type queueItem struct {
     request *domain.PlaybackRequest
+    // ctx carries the budget reservation of the request, if any, which its
+    // synthesis settles whenever the item is played
     ctx     context.Context
     done    chan error
     // optional: when non-empty, save streaming audio concurrently to this path
     historyFilePath string
 }
 
+// errDroppedFromQueue is returned to the callers waiting on queued items that
+// are cleared before they are played
+var errDroppedFromQueue = fmt.Errorf("playback was cleared from the queue before it started")
+
+// dropQueueItems tells the callers waiting on items cleared from the queue
+// that they will not be played, so they return and release what they hold
+func dropQueueItems(items []queueItem) {
+	for _, item := range items {
+		if item.done != nil {
+			item.done <- errDroppedFromQueue
+		}
+	}
+}
+
 // NewAudioPlayerService creates a new audio player service
 func NewAudioPlayerService(ttsService *TTSSynthesizer, player domain.AudioPlayer, config *domain.PlaybackConfig) *AudioPlayerService {
 	return NewAudioPlayerServiceWithLogger(ttsService, player, config, logger.NewNoop())
@@ -133,12 +149,14 @@ func (s *AudioPlayerService) PlayRequest(ctx context.Context, request *domain.Pl
 // playImmediate stops current playback and plays new audio immediately
 func (s *AudioPlayerService) playImmediate(ctx context.Context, request *domain.PlaybackRequest) error {
 	// Stop current playback
-	s.player.Stop()
+	interruptPlayer(s.player)
 	
 	// Clear queue
 	s.mu.Lock()
+	dropped := s.queue
 	s.queue = make([]queueItem, 0)
 	s.mu.Unlock()
+	dropQueueItems(dropped)
 	
 	return s.synthesizeAndPlay(ctx, request)
 }
@@ -272,8 +290,10 @@ func (s *AudioPlayerService) synthesizeAndPlay(ctx context.Context, request *dom
 // Stop stops current playback and clears queue
 func (s *AudioPlayerService) Stop() error {
 	s.mu.Lock()
+	dropped := s.queue
 	s.queue = make([]queueItem, 0)
 	s.mu.Unlock()
+	dropQueueItems(dropped)
 	
 	return s.player.Stop()
 }
@@ -315,8 +335,10 @@ func (s *AudioPlayerService) GetQueueLength() int {
 // ClearQueue clears all items from the queue
 func (s *AudioPlayerService) ClearQueue() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	dropped := s.queue
 	s.queue = make([]queueItem, 0)
+	s.mu.Unlock()
+	dropQueueItems(dropped)
 }
 
 // synthesizeAndPlayStream performs streaming TTS synthesis with progressive playback
@@ -332,7 +354,7 @@ func (s *AudioPlayerService) synthesizeAndPlayStream(ctx context.Context, reques
 	go func() {
 		// Create independent context for audio playback to prevent premature cancellation
 		playbackCtx := context.Background()
-		err := s.player.Play(playbackCtx, pipeReader, format)
+		err := playRequest(playbackCtx, s.player, pipeReader, format, request)
 		if err != nil {
 			errChan <- fmt.Errorf("playback failed: %w", err)
 		} else {
@@ -0,0 +1,341 @@
+package usecase
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// ParseScript reads a dialogue script in the format described by domain.Script.
+// Errors name the offending line.
+func ParseScript(r io.Reader) (*domain.Script, error) {
+	script := &domain.Script{Voices: make(map[string]*domain.TTSRequestOverrides)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	number := 0
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if number == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+
+		case strings.HasPrefix(line, "@"):
+			fields := strings.Fields(line)
+			switch fields[0] {
+			case "@voice":
+				if len(fields) < 2 {
+					return nil, fmt.Errorf("line %d: @voice needs an alias", number)
+				}
+				alias := fields[1]
+				voice, err := parseScriptParams(fields[2:])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", number, err)
+				}
+				if voice.ModelUUID == nil {
+					return nil, fmt.Errorf("line %d: voice %q needs model=<model-uuid>", number, alias)
+				}
+				script.Voices[alias] = voice
+			case "@pause":
+				if len(fields) != 2 {
+					return nil, fmt.Errorf("line %d: @pause needs a duration, e.g. @pause 1.5s", number)
+				}
+				pause, err := parseScriptDuration(fields[1])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", number, err)
+				}
+				script.Lines = append(script.Lines, domain.ScriptLine{Number: number, Pause: pause})
+			default:
+				return nil, fmt.Errorf("line %d: unknown directive %s (use @voice or @pause)", number, fields[0])
+			}
+
+		case strings.HasPrefix(line, "["):
+			end := strings.Index(line, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: missing ] after the voice alias", number)
+			}
+			fields := strings.Fields(line[1:end])
+			if len(fields) == 0 {
+				return nil, fmt.Errorf("line %d: missing voice alias", number)
+			}
+			text := strings.TrimSpace(line[end+1:])
+			if text == "" {
+				return nil, fmt.Errorf("line %d: no text to speak", number)
+			}
+			entry := domain.ScriptLine{Number: number, Voice: fields[0], Text: text}
+			if len(fields) > 1 {
+				overrides, err := parseScriptParams(fields[1:])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", number, err)
+				}
+				entry.Overrides = overrides
+			}
+			script.Lines = append(script.Lines, entry)
+
+		default:
+			return nil, fmt.Errorf("line %d: expected [voice] text, @voice or @pause", number)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return script, nil
+}
+
+// parseScriptParams parses key=value voice parameters
+func parseScriptParams(fields []string) (*domain.TTSRequestOverrides, error) {
+	params := &domain.TTSRequestOverrides{}
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("invalid parameter %q (use key=value)", field)
+		}
+
+		number := func() (*float64, error) {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s value %q", key, value)
+			}
+			return &f, nil
+		}
+		var err error
+		switch key {
+		case "model":
+			params.ModelUUID = &value
+		case "speaker":
+			params.SpeakerUUID = &value
+		case "style":
+			if id, convErr := strconv.Atoi(value); convErr == nil {
+				params.StyleID, params.StyleName = &id, nil
+			} else {
+				params.StyleName, params.StyleID = &value, nil
+			}
+		case "rate":
+			params.SpeakingRate, err = number()
+		case "intensity":
+			params.EmotionalIntensity, err = number()
+		case "tempo":
+			params.TempoDynamics, err = number()
+		case "pitch":
+			params.Pitch, err = number()
+		case "volume":
+			params.Volume, err = number()
+		default:
+			return nil, fmt.Errorf("unknown parameter %q (use model, speaker, style, rate, intensity, tempo, pitch or volume)", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return params, nil
+}
+
+// parseScriptDuration accepts Go durations (500ms, 1.5s) and plain seconds
+func parseScriptDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, convErr := strconv.ParseFloat(value, 64)
+		if convErr != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d = time.Duration(seconds * float64(time.Second))
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("pause must not be negative: %s", value)
+	}
+	return d, nil
+}
+
+// RenderScript synthesizes every spoken line of script and writes them as a
+// single WAV or FLAC track, separated by options.Gap or by the pause
+// directives. Lines are synthesized in parallel and converted to the format of
+// the first line, so voices with different output formats can be mixed.
+func (s *TTSSynthesizer) RenderScript(ctx context.Context, script *domain.Script, options *domain.ScriptOptions, writer io.Writer) (*domain.ScriptResult, error) {
+	options = normalizeScriptOptions(options)
+	if options.OutputFormat != domain.OutputFormatWAV && options.OutputFormat != domain.OutputFormatFLAC {
+		return nil, &ValidationError{Field: "OutputFormat", Message: "Scripts can be rendered only as wav or flac"}
+	}
+
+	// Resolve voices and work out the silence before each spoken line
+	var (
+		lines    []*domain.ScriptLineResult
+		requests []*domain.TTSRequest
+		silences []time.Duration
+		pending  time.Duration
+		paused   bool
+	)
+	for i := range script.Lines {
+		line := &script.Lines[i]
+		if line.IsPause() {
+			pending += line.Pause
+			paused = true
+			continue
+		}
+
+		request, err := s.scriptLineRequest(script, line, options)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.Number, err)
+		}
+		if !paused && len(requests) > 0 {
+			pending = options.Gap
+		}
+		lines = append(lines, &domain.ScriptLineResult{Number: line.Number, Voice: line.Voice, Text: line.Text, Request: request})
+		requests = append(requests, request)
+		silences = append(silences, pending)
+		pending, paused = 0, false
+	}
+	if len(requests) == 0 {
+		return nil, &ValidationError{Field: "Script", Message: "Script has no lines to speak"}
+	}
+
+	result := &domain.ScriptResult{Lines: lines}
+	var track *audio.Buffer
+	err := s.synthesizeInOrder(ctx, requests, options.Concurrency, func(i int, r chunkResult) error {
+		line := lines[i]
+		if r.err != nil {
+			return fmt.Errorf("line %d: %w", line.Number, r.err)
+		}
+		line.Audio = r.audio
+		line.BillingInfo = r.billing
+
+		converted := r.audio
+		if track == nil {
+			track = &audio.Buffer{Format: r.audio.Format}
+		} else {
+			var err error
+			if converted, err = convertAudio(r.audio, track.Format); err != nil {
+				return fmt.Errorf("line %d: %w", line.Number, err)
+			}
+		}
+		track.Data = append(track.Data, audio.Silence(track.Format, silences[i])...)
+
+		cue := chunkCue(i+1, line.Text, track.Duration(), converted)
+		line.Start, line.End = cue.Start, cue.End
+		track.Data = append(track.Data, converted.Data...)
+		result.BillingInfo = mergeBillingInfo(result.BillingInfo, r.billing)
+
+		if options.OnProgress != nil {
+			options.OnProgress(i+1, len(requests))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	track.Data = append(track.Data, audio.Silence(track.Format, pending)...)
+
+	if options.OutputFormat == domain.OutputFormatFLAC {
+		err = track.WriteFLAC(writer)
+	} else {
+		err = track.WriteWAV(writer)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write audio: %w", err)
+	}
+
+	result.Format = track.Format
+	result.Duration = track.Duration()
+	return result, nil
+}
+
+// scriptLineRequest builds the request for a spoken line from its voice and
+// the line's own overrides. Lines are requested as WAV without leading or
+// trailing silence; the track inserts its own gaps.
+func (s *TTSSynthesizer) scriptLineRequest(script *domain.Script, line *domain.ScriptLine, options *domain.ScriptOptions) (*domain.TTSRequest, error) {
+	voice, ok := script.Voices[line.Voice]
+	if !ok && options.ResolveVoice != nil {
+		var err error
+		if voice, err = options.ResolveVoice(line.Voice); err != nil {
+			return nil, err
+		}
+		ok = voice != nil
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown voice %q (define it with @voice)", line.Voice)
+	}
+	if voice.ModelUUID == nil {
+		return nil, fmt.Errorf("voice %q has no model", line.Voice)
+	}
+
+	base := domain.NewTTSRequestBuilder(*voice.ModelUUID, line.Text).
+		WithOutputFormat(domain.OutputFormatWAV).
+		WithLeadingSilence(0).
+		WithTrailingSilence(0).
+		Build()
+	request := line.Overrides.Apply(voice.Apply(base))
+	if err := s.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// convertAudio converts b to the channel count and sample rate of format
+func convertAudio(b *audio.Buffer, format audio.Format) (*audio.Buffer, error) {
+	if b.Format.BitsPerSample != format.BitsPerSample {
+		return nil, fmt.Errorf("cannot join %d-bit audio with %d-bit audio", b.Format.BitsPerSample, format.BitsPerSample)
+	}
+	converted, err := b.ConvertChannels(format.Channels)
+	if err != nil {
+		return nil, err
+	}
+	return converted.Resample(format.SampleRate)
+}
+
+func normalizeScriptOptions(options *domain.ScriptOptions) *domain.ScriptOptions {
+	defaults := domain.DefaultScriptOptions()
+	if options == nil {
+		return defaults
+	}
+	normalized := *options
+	if normalized.Concurrency <= 0 {
+		normalized.Concurrency = defaults.Concurrency
+	}
+	if normalized.Gap < 0 {
+		normalized.Gap = 0
+	}
+	if normalized.OutputFormat == "" {
+		normalized.OutputFormat = defaults.OutputFormat
+	}
+	return &normalized
+}
+
+// SaveScriptHistory saves every line of a rendered script as a history record,
+// grouped under a new script session that is stored in result.Session. Nothing
+// is saved when history is disabled.
+func (m *TTSHistoryManager) SaveScriptHistory(ctx context.Context, result *domain.ScriptResult) error {
+	if !m.config.HistoryEnabled {
+		return nil
+	}
+
+	session := uuid.New().String()
+	for _, line := range result.Lines {
+		if line.Audio == nil {
+			continue
+		}
+		var data bytes.Buffer
+		if err := line.Audio.WriteWAV(&data); err != nil {
+			return fmt.Errorf("line %d: %w", line.Number, err)
+		}
+		history, err := m.saveHistoryWithAudio(ctx, line.Request, io.NopCloser(&data), line.BillingInfo, 0, session)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line.Number, err)
+		}
+		line.HistoryID = history.ID
+	}
+	result.Session = session
+	return nil
+}
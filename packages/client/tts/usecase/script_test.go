@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/audio"
+	"github.com/kajidog/aivis-cloud-cli/client/config"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/infrastructure"
+)
+
+const testScript = `# A short dialogue
+@voice a model=m1 speaker=s1 style=2
+@voice b model=m2 style=Happy rate=1.2
+
+[a] あ。
+[b pitch=0.1] いい。
+@pause 50ms
+@pause 0.02
+[c] ううう。
+@pause 10ms
+`
+
+func TestParseScript(t *testing.T) {
+	script, err := ParseScript(strings.NewReader(testScript))
+	if err != nil {
+		t.Fatalf("ParseScript() error = %v", err)
+	}
+
+	a, b := script.Voices["a"], script.Voices["b"]
+	if a == nil || *a.ModelUUID != "m1" || *a.SpeakerUUID != "s1" || *a.StyleID != 2 {
+		t.Errorf("voice a = %+v", a)
+	}
+	if b == nil || *b.ModelUUID != "m2" || *b.StyleName != "Happy" || *b.SpeakingRate != 1.2 || b.StyleID != nil {
+		t.Errorf("voice b = %+v", b)
+	}
+
+	if len(script.Lines) != 6 {
+		t.Fatalf("parsed %d lines, want 6", len(script.Lines))
+	}
+	if line := script.Lines[1]; line.Number != 6 || line.Voice != "b" || line.Text != "いい。" || *line.Overrides.Pitch != 0.1 {
+		t.Errorf("line 6 = %+v", line)
+	}
+	if line := script.Lines[3]; !line.IsPause() || line.Pause != 20*time.Millisecond {
+		t.Errorf("line 8 = %+v, want a 20ms pause", line)
+	}
+
+	for _, tt := range []struct{ script, want string }{
+		{"[a]", "line 1: no text to speak"},
+		{"[a text", "line 1: missing ]"},
+		{"@voice a style=1", `line 1: voice "a" needs model`},
+		{"\n@voice a model=m pitch=high", `line 2: invalid pitch value "high"`},
+		{"[a speed=2] text", `line 1: unknown parameter "speed"`},
+		{"@pause soon", `line 1: invalid duration "soon"`},
+		{"@music on", "line 1: unknown directive @music"},
+		{"just text", "line 1: expected [voice] text"},
+	} {
+		if _, err := ParseScript(strings.NewReader(tt.script)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseScript(%q) error = %v, want %q", tt.script, err, tt.want)
+		}
+	}
+}
+
+func TestRenderScript(t *testing.T) {
+	repo := &wavTTSRepo{format: audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}, padding: 3}
+	synth := NewTTSSynthesizer(repo)
+	script, err := ParseScript(strings.NewReader(testScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := "m3"
+	options := &domain.ScriptOptions{
+		Gap: 5 * time.Millisecond,
+		ResolveVoice: func(alias string) (*domain.TTSRequestOverrides, error) {
+			if alias != "c" {
+				return nil, fmt.Errorf("unexpected alias %q", alias)
+			}
+			return &domain.TTSRequestOverrides{ModelUUID: &model}, nil
+		},
+	}
+
+	var out bytes.Buffer
+	result, err := synth.RenderScript(context.Background(), script, options, &out)
+	if err != nil {
+		t.Fatalf("RenderScript() error = %v", err)
+	}
+
+	// Lines are 16 frames with speech in frames 3 to 13: a gap of 5 frames
+	// follows the first line and 70 frames of pauses the second
+	wantTimes := [][2]time.Duration{{3, 13}, {24, 34}, {110, 120}}
+	if len(result.Lines) != 3 {
+		t.Fatalf("rendered %d lines, want 3", len(result.Lines))
+	}
+	for i, line := range result.Lines {
+		if line.Start != wantTimes[i][0]*time.Millisecond || line.End != wantTimes[i][1]*time.Millisecond {
+			t.Errorf("line %d spans %v-%v, want %v", line.Number, line.Start, line.End, wantTimes[i])
+		}
+	}
+	// The trailing pause is kept
+	if result.Duration != 133*time.Millisecond {
+		t.Errorf("Duration = %v, want 133ms", result.Duration)
+	}
+	if decoded, err := audio.DecodeWAVBytes(out.Bytes()); err != nil || decoded.Frames() != 133 {
+		t.Errorf("output is not a 133 frame WAV: %v", err)
+	}
+	if result.BillingInfo == nil || result.BillingInfo.CreditsUsed != "1.5" {
+		t.Errorf("BillingInfo = %+v, want 1.5 credits used", result.BillingInfo)
+	}
+
+	b := result.Lines[1].Request
+	if b.ModelUUID != "m2" || *b.StyleName != "Happy" || *b.SpeakingRate != 1.2 || *b.Pitch != 0.1 {
+		t.Errorf("line request = %+v, want voice b with the line's pitch", b)
+	}
+	if *b.OutputFormat != domain.OutputFormatWAV || *b.LeadingSilenceSeconds != 0 || *b.TrailingSilenceSeconds != 0 {
+		t.Error("lines must be requested as WAV without silence")
+	}
+	if result.Lines[2].Request.ModelUUID != "m3" {
+		t.Errorf("voice c was not resolved: %+v", result.Lines[2].Request)
+	}
+
+	unknown, _ := ParseScript(strings.NewReader("[x] text"))
+	if _, err := synth.RenderScript(context.Background(), unknown, nil, &out); err == nil || !strings.Contains(err.Error(), `unknown voice "x"`) {
+		t.Errorf("expected an unknown voice error, got %v", err)
+	}
+}
+
+func TestSaveScriptHistory(t *testing.T) {
+	ctx := context.Background()
+	storePath := t.TempDir()
+	cfg := config.NewConfig("test-key").WithHistoryStorePath(storePath)
+	repo := &wavTTSRepo{format: audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}}
+	historyRepo := infrastructure.NewFileHistoryRepository(storePath)
+	m := NewTTSHistoryManager(historyRepo, repo, &mockPlayer{}, cfg)
+
+	script, _ := ParseScript(strings.NewReader("@voice a model=m\n[a] one.\n[a] two."))
+	result, err := NewTTSSynthesizer(repo).RenderScript(ctx, script, nil, &bytes.Buffer{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.SaveScriptHistory(ctx, result); err != nil {
+		t.Fatalf("SaveScriptHistory() error = %v", err)
+	}
+	if result.Session == "" {
+		t.Fatal("no script session was assigned")
+	}
+
+	response, err := m.ListHistory(ctx, domain.NewTTSHistorySearchRequest().WithScriptSession(result.Session).WithSorting("id", "asc").Build())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Histories) != 2 || response.Histories[0].Text != "one." || response.Histories[1].Text != "two." {
+		t.Fatalf("session records = %+v", response.Histories)
+	}
+	for i, line := range result.Lines {
+		if line.HistoryID != response.Histories[i].ID {
+			t.Errorf("line %d HistoryID = %d, want %d", line.Number, line.HistoryID, response.Histories[i].ID)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// WriteSubtitles writes cues as an SRT or WebVTT file
+func WriteSubtitles(w io.Writer, format domain.SubtitleFormat, cues []domain.SubtitleCue) error {
+	var separator string
+	switch format {
+	case domain.SubtitleFormatSRT:
+		separator = ","
+	case domain.SubtitleFormatVTT:
+		separator = "."
+	default:
+		return fmt.Errorf("unsupported subtitle format: %s", format)
+	}
+
+	out := bufio.NewWriter(w)
+	if format == domain.SubtitleFormatVTT {
+		out.WriteString("WEBVTT\n\n")
+	}
+	for i, cue := range cues {
+		index := cue.Index
+		if index == 0 {
+			index = i + 1
+		}
+		fmt.Fprintf(out, "%d\n%s --> %s\n%s\n\n",
+			index,
+			formatCueTimestamp(cue.Start, separator),
+			formatCueTimestamp(cue.End, separator),
+			cueText(cue.Text))
+	}
+	return out.Flush()
+}
+
+// formatCueTimestamp formats d as hh:mm:ss followed by milliseconds
+func formatCueTimestamp(d time.Duration, separator string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, ms/60000%60, ms/1000%60, separator, ms%1000)
+}
+
+// cueText drops blank lines, which would end the cue early in both formats
+func cueText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
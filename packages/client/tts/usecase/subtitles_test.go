@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+func TestWriteSubtitles(t *testing.T) {
+	cues := []domain.SubtitleCue{
+		{Index: 1, Start: 120 * time.Millisecond, End: 1500 * time.Millisecond, Text: "こんにちは。"},
+		{Index: 2, Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 4*time.Second, Text: "一行目\n\n二行目"},
+	}
+
+	tests := []struct {
+		format domain.SubtitleFormat
+		want   string
+	}{
+		{domain.SubtitleFormatSRT, "1\n00:00:00,120 --> 00:00:01,500\nこんにちは。\n\n" +
+			"2\n01:02:03,045 --> 01:02:04,000\n一行目\n二行目\n\n"},
+		{domain.SubtitleFormatVTT, "WEBVTT\n\n" +
+			"1\n00:00:00.120 --> 00:00:01.500\nこんにちは。\n\n" +
+			"2\n01:02:03.045 --> 01:02:04.000\n一行目\n二行目\n\n"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var out bytes.Buffer
+			if err := WriteSubtitles(&out, tt.format, cues); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("WriteSubtitles() =\n%q\nwant\n%q", out.String(), tt.want)
+			}
+		})
+	}
+
+	if err := WriteSubtitles(&bytes.Buffer{}, "ass", cues); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
@@ -3,10 +3,12 @@ package usecase
 import (
 	"bufio"
 	"context"
+	"fmt"
 	"io"
 	"time"
 
 	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+	"github.com/kajidog/aivis-cloud-cli/client/tts/ssml"
 )
 
 // TTSSynthesizer handles text-to-speech synthesis use cases
@@ -105,8 +107,14 @@ func (s *TTSSynthesizer) ValidateRequest(request *domain.TTSRequest) error {
 		return &ValidationError{Field: "Text", Message: "Text is required"}
 	}
 
-	if len(request.Text) > 3000 {
-		return &ValidationError{Field: "Text", Message: "Text must not exceed 3000 characters"}
+	if len(request.Text) > domain.MaxTextLength {
+		return &ValidationError{Field: "Text", Message: fmt.Sprintf("Text must not exceed %d characters", domain.MaxTextLength)}
+	}
+
+	if request.UseSSML != nil && *request.UseSSML {
+		if err := ssml.Validate(request.Text); err != nil {
+			return &ValidationError{Field: "Text", Message: "Invalid SSML: " + err.Error()}
+		}
 	}
 
 	// Validate style configuration
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+func TestValidateRequestSSML(t *testing.T) {
+	s := NewTTSSynthesizer(nil)
+	markup := `こんにちは<break time="1s"/><prosody rate="fast">世界</prosody>`
+
+	if err := s.ValidateRequest(domain.NewTTSRequestBuilder("model", markup).WithSSML(true).Build()); err != nil {
+		t.Errorf("ValidateRequest() error = %v", err)
+	}
+
+	// Markup outside the documented subset is left for the API to judge
+	unsupported := `こんにちは<voice name="a">世界</voice><break time="soon"/>`
+	if err := s.ValidateRequest(domain.NewTTSRequestBuilder("model", unsupported).WithSSML(true).Build()); err != nil {
+		t.Errorf("ValidateRequest() with unsupported markup error = %v", err)
+	}
+
+	// Markup is only checked when SSML is enabled
+	malformed := "こんにちは<break time=\"1s\">"
+	if err := s.ValidateRequest(domain.NewTTSRequestBuilder("model", malformed).Build()); err != nil {
+		t.Errorf("ValidateRequest() without SSML error = %v", err)
+	}
+
+	err := s.ValidateRequest(domain.NewTTSRequestBuilder("model", malformed).WithSSML(true).Build())
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "Text" {
+		t.Fatalf("ValidateRequest() error = %v, want a Text validation error", err)
+	}
+	if want := "Invalid SSML: line 1, column 23: element <break> closed by </speak>"; validationErr.Message != want {
+		t.Errorf("Message = %q, want %q", validationErr.Message, want)
+	}
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+// UsageReport aggregates the history records of the report's period and
+// labels, joined with the server's usage of the period when server is not nil
+func (m *TTSHistoryManager) UsageReport(ctx context.Context, options domain.UsageReportOptions, server *domain.ServerUsage) (*domain.UsageReport, error) {
+	request := &domain.TTSHistorySearchRequest{SortBy: "created_at", SortOrder: "asc"}
+	if !options.Start.IsZero() {
+		request.StartDate = &options.Start
+	}
+	if !options.End.IsZero() {
+		request.EndDate = &options.End
+	}
+	if options.Project != "" {
+		request.Project = &options.Project
+	}
+	if options.Tag != "" {
+		request.Tag = &options.Tag
+	}
+	histories, err := m.listAllHistory(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list history: %w", err)
+	}
+	return BuildUsageReport(options, histories, server)
+}
+
+// BuildUsageReport aggregates history records into the groups of a usage
+// report. server, when not nil, is the usage the server reports for the same
+// period; its per-model numbers are joined into the rows of a model report,
+// unless the report is filtered by project or tag and so covers only part of
+// the account's usage.
+func BuildUsageReport(options domain.UsageReportOptions, histories []*domain.TTSHistory, server *domain.ServerUsage) (*domain.UsageReport, error) {
+	switch options.GroupBy {
+	case "":
+		options.GroupBy = domain.UsageGroupByModel
+	case domain.UsageGroupByModel, domain.UsageGroupByDay, domain.UsageGroupByProfile,
+		domain.UsageGroupByProject, domain.UsageGroupByTag:
+	default:
+		return nil, fmt.Errorf("unsupported group: %s", options.GroupBy)
+	}
+
+	report := &domain.UsageReport{UsageReportOptions: options, Server: server}
+	rows := make(map[string]*domain.UsageReportRow)
+	row := func(group string) *domain.UsageReportRow {
+		r, ok := rows[group]
+		if !ok {
+			r = &domain.UsageReportRow{Group: group}
+			rows[group] = r
+		}
+		return r
+	}
+
+	for _, history := range histories {
+		if !inUsageReport(options, history) {
+			continue
+		}
+		characters := historyCharacters(history)
+		addUsage(&report.Total, history, characters)
+		for _, group := range usageGroups(options.GroupBy, history) {
+			addUsage(row(group), history, characters)
+		}
+	}
+
+	if options.GroupBy == domain.UsageGroupByModel && server != nil && options.Project == "" && options.Tag == "" {
+		for _, model := range server.Models {
+			r := row(model.ModelUUID)
+			requests, credits := model.Requests, model.Credits
+			r.ModelName = model.ModelName
+			r.ServerRequests = &requests
+			r.ServerCredits = &credits
+		}
+		requests, credits := server.Requests, server.Credits
+		report.Total.ServerRequests = &requests
+		report.Total.ServerCredits = &credits
+	}
+
+	report.Rows = make([]domain.UsageReportRow, 0, len(rows))
+	for _, r := range rows {
+		report.Rows = append(report.Rows, *r)
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		return report.Rows[i].Group < report.Rows[j].Group
+	})
+	return report, nil
+}
+
+// inUsageReport reports whether a record falls within the period and the
+// label filters of a report
+func inUsageReport(options domain.UsageReportOptions, history *domain.TTSHistory) bool {
+	if !options.Start.IsZero() && history.CreatedAt.Before(options.Start) {
+		return false
+	}
+	if !options.End.IsZero() && history.CreatedAt.After(options.End) {
+		return false
+	}
+	if options.Project != "" && history.Project != options.Project {
+		return false
+	}
+	if options.Tag != "" && !history.HasTag(options.Tag) {
+		return false
+	}
+	return true
+}
+
+// usageGroups returns the groups a record counts in
+func usageGroups(groupBy domain.UsageGroupBy, history *domain.TTSHistory) []string {
+	label := func(value string) []string {
+		if value == "" {
+			return []string{domain.UsageGroupUnlabeled}
+		}
+		return []string{value}
+	}
+	switch groupBy {
+	case domain.UsageGroupByDay:
+		return []string{history.CreatedAt.Local().Format("2006-01-02")}
+	case domain.UsageGroupByProfile:
+		return label(history.Profile)
+	case domain.UsageGroupByProject:
+		return label(history.Project)
+	case domain.UsageGroupByTag:
+		if len(history.Tags) == 0 {
+			return label("")
+		}
+		return history.Tags
+	default:
+		return label(history.ModelUUID)
+	}
+}
+
+// historyCharacters counts the billed characters of a record
+func historyCharacters(history *domain.TTSHistory) int {
+	if history.Request != nil {
+		return requestCharacters(history.Request)
+	}
+	return CountCharacters(history.Text, false)
+}
+
+func addUsage(row *domain.UsageReportRow, history *domain.TTSHistory, characters int) {
+	row.Requests++
+	row.Characters += characters
+	if history.Credits != nil {
+		row.Credits += *history.Credits
+	} else {
+		row.UnbilledRequests++
+	}
+}
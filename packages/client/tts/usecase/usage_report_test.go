@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kajidog/aivis-cloud-cli/client/tts/domain"
+)
+
+func testUsageHistories() []*domain.TTSHistory {
+	credits := func(c float64) *float64 { return &c }
+	day := time.Date(2026, 5, 10, 12, 0, 0, 0, time.Local)
+	return []*domain.TTSHistory{
+		{ID: 1, Text: "hello", ModelUUID: "model-a", CreatedAt: day, Credits: credits(5), Project: "podcast", Profile: "narrator", Tags: []string{"intro", "ep1"}},
+		{ID: 2, Text: "world!", ModelUUID: "model-a", CreatedAt: day.Add(24 * time.Hour), Credits: credits(6), Project: "podcast", Tags: []string{"ep1"}},
+		{ID: 3, Text: "abc", ModelUUID: "model-b", CreatedAt: day.Add(24 * time.Hour)},
+		{ID: 4, Text: "out of range", ModelUUID: "model-a", CreatedAt: day.AddDate(0, 1, 0), Credits: credits(100)},
+	}
+}
+
+func usageRows(report *domain.UsageReport) map[string]domain.UsageReportRow {
+	rows := make(map[string]domain.UsageReportRow)
+	for _, row := range report.Rows {
+		rows[row.Group] = row
+	}
+	return rows
+}
+
+func TestBuildUsageReport(t *testing.T) {
+	options := domain.UsageReportOptions{
+		Start: time.Date(2026, 5, 1, 0, 0, 0, 0, time.Local),
+		End:   time.Date(2026, 5, 31, 23, 59, 59, 0, time.Local),
+	}
+	server := &domain.ServerUsage{Requests: 4, Credits: 15, Models: []domain.ServerModelUsage{
+		{ModelUUID: "model-a", ModelName: "A", Requests: 2, Credits: 11},
+		{ModelUUID: "model-c", ModelName: "C", Requests: 2, Credits: 4},
+	}}
+
+	report, err := BuildUsageReport(options, testUsageHistories(), server)
+	if err != nil {
+		t.Fatalf("BuildUsageReport() error = %v", err)
+	}
+	if report.GroupBy != domain.UsageGroupByModel {
+		t.Errorf("GroupBy = %q, want model by default", report.GroupBy)
+	}
+	if report.Total.Requests != 3 || report.Total.Characters != 14 || report.Total.Credits != 11 || report.Total.UnbilledRequests != 1 {
+		t.Errorf("Total = %+v", report.Total)
+	}
+	if report.Total.ServerCredits == nil || *report.Total.ServerCredits != 15 {
+		t.Errorf("Total server credits = %v, want 15", report.Total.ServerCredits)
+	}
+	rows := usageRows(report)
+	if a := rows["model-a"]; a.Requests != 2 || a.Credits != 11 || a.ModelName != "A" || a.ServerCredits == nil || *a.ServerCredits != 11 {
+		t.Errorf("model-a row = %+v", a)
+	}
+	if b := rows["model-b"]; b.Requests != 1 || b.ServerRequests != nil {
+		t.Errorf("model-b row = %+v, want local usage only", b)
+	}
+	if c := rows["model-c"]; c.Requests != 0 || c.ServerRequests == nil || *c.ServerRequests != 2 {
+		t.Errorf("model-c row = %+v, want server usage only", c)
+	}
+	if len(report.Rows) != 3 || report.Rows[0].Group != "model-a" {
+		t.Errorf("Rows = %+v, want 3 rows sorted by group", report.Rows)
+	}
+}
+
+func TestBuildUsageReportGroups(t *testing.T) {
+	histories := testUsageHistories()[:3]
+	tests := []struct {
+		groupBy domain.UsageGroupBy
+		want    map[string]int
+	}{
+		{groupBy: domain.UsageGroupByDay, want: map[string]int{"2026-05-10": 1, "2026-05-11": 2}},
+		{groupBy: domain.UsageGroupByProfile, want: map[string]int{"narrator": 1, domain.UsageGroupUnlabeled: 2}},
+		{groupBy: domain.UsageGroupByProject, want: map[string]int{"podcast": 2, domain.UsageGroupUnlabeled: 1}},
+		{groupBy: domain.UsageGroupByTag, want: map[string]int{"intro": 1, "ep1": 2, domain.UsageGroupUnlabeled: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.groupBy), func(t *testing.T) {
+			report, err := BuildUsageReport(domain.UsageReportOptions{GroupBy: tt.groupBy}, histories, nil)
+			if err != nil {
+				t.Fatalf("BuildUsageReport() error = %v", err)
+			}
+			rows := usageRows(report)
+			if len(rows) != len(tt.want) {
+				t.Errorf("Rows = %+v, want %v", report.Rows, tt.want)
+			}
+			for group, requests := range tt.want {
+				if rows[group].Requests != requests {
+					t.Errorf("group %q: %d requests, want %d", group, rows[group].Requests, requests)
+				}
+			}
+			if report.Total.Requests != 3 {
+				t.Errorf("Total requests = %d, want each record counted once", report.Total.Requests)
+			}
+		})
+	}
+
+	if _, err := BuildUsageReport(domain.UsageReportOptions{GroupBy: "voice"}, histories, nil); err == nil {
+		t.Error("expected an unsupported group to fail")
+	}
+}
+
+func TestBuildUsageReportFilters(t *testing.T) {
+	server := &domain.ServerUsage{Requests: 4, Credits: 15, Models: []domain.ServerModelUsage{{ModelUUID: "model-c", Requests: 4, Credits: 15}}}
+	report, err := BuildUsageReport(domain.UsageReportOptions{Tag: "ep1"}, testUsageHistories(), server)
+	if err != nil {
+		t.Fatalf("BuildUsageReport() error = %v", err)
+	}
+	if report.Total.Requests != 2 || report.Total.Credits != 11 || report.Total.ServerCredits != nil {
+		t.Errorf("Total = %+v, want the tagged records without server usage", report.Total)
+	}
+	if len(report.Rows) != 1 || report.Rows[0].Group != "model-a" {
+		t.Errorf("Rows = %+v, want the tagged model only", report.Rows)
+	}
+
+	report, _ = BuildUsageReport(domain.UsageReportOptions{Project: "other"}, testUsageHistories(), nil)
+	if report.Total.Requests != 0 || len(report.Rows) != 0 {
+		t.Errorf("report of an unknown project = %+v", report)
+	}
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// CreditBalance is the credit balance of an account. The API reports it
+// either as a plain number of credits or as an object with a breakdown by
+// kind of credit; both shapes decode into a CreditBalance.
+type CreditBalance struct {
+	// Total is the spendable balance. When the API omits it, it is the sum of
+	// the breakdown.
+	Total float64 `json:"total"`
+
+	// Paid, Free and Bonus break the total down by how the credits were
+	// obtained. They are all zero when the API reported only a number.
+	Paid  float64 `json:"paid,omitempty"`
+	Free  float64 `json:"free,omitempty"`
+	Bonus float64 `json:"bonus,omitempty"`
+
+	// Expirations lists credits that expire, soonest first as reported
+	Expirations []CreditExpiration `json:"expirations,omitempty"`
+}
+
+// CreditExpiration is an amount of credits that expires at a point in time
+type CreditExpiration struct {
+	Credits   float64   `json:"credits"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Kind is the kind of the expiring credits (paid, free or bonus), if known
+	Kind string `json:"kind,omitempty"`
+}
+
+// HasBreakdown reports whether the API broke the balance down by kind
+func (b *CreditBalance) HasBreakdown() bool {
+	return b.Paid != 0 || b.Free != 0 || b.Bonus != 0
+}
+
+// NextExpiration returns the expiration due soonest after now, or nil
+func (b *CreditBalance) NextExpiration(now time.Time) *CreditExpiration {
+	var next *CreditExpiration
+	for i := range b.Expirations {
+		expiration := &b.Expirations[i]
+		if expiration.ExpiresAt.Before(now) {
+			continue
+		}
+		if next == nil || expiration.ExpiresAt.Before(next.ExpiresAt) {
+			next = expiration
+		}
+	}
+	return next
+}
+
+// creditBalanceObject is the object shape of the balance
+type creditBalanceObject struct {
+	Total       *flexibleFloat `json:"total"`
+	Paid        flexibleFloat  `json:"paid"`
+	Free        flexibleFloat  `json:"free"`
+	Bonus       flexibleFloat  `json:"bonus"`
+	Expirations []struct {
+		Credits   flexibleFloat   `json:"credits"`
+		ExpiresAt json.RawMessage `json:"expires_at"`
+		Kind      string          `json:"kind"`
+	} `json:"expirations"`
+}
+
+// UnmarshalJSON decodes a number (or numeric string) as the total, or an
+// object with a breakdown. Expirations without a valid expires_at are
+// skipped rather than failing the whole balance.
+func (b *CreditBalance) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] != '{' {
+		var total flexibleFloat
+		if err := json.Unmarshal(data, &total); err != nil {
+			return fmt.Errorf("invalid credit balance %s: %w", data, err)
+		}
+		*b = CreditBalance{Total: float64(total)}
+		return nil
+	}
+
+	var object creditBalanceObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return fmt.Errorf("invalid credit balance: %w", err)
+	}
+	balance := CreditBalance{
+		Paid:  float64(object.Paid),
+		Free:  float64(object.Free),
+		Bonus: float64(object.Bonus),
+	}
+	if object.Total != nil {
+		balance.Total = float64(*object.Total)
+	} else {
+		balance.Total = balance.Paid + balance.Free + balance.Bonus
+	}
+	for _, e := range object.Expirations {
+		var expiresAt time.Time
+		if err := json.Unmarshal(e.ExpiresAt, &expiresAt); err != nil || expiresAt.IsZero() {
+			continue
+		}
+		balance.Expirations = append(balance.Expirations, CreditExpiration{Credits: float64(e.Credits), ExpiresAt: expiresAt, Kind: e.Kind})
+	}
+	*b = balance
+	return nil
+}
+
+// flexibleFloat decodes a JSON number, a numeric string or null (as zero)
+type flexibleFloat float64
+
+func (f *flexibleFloat) UnmarshalJSON(data []byte) error {
+	var value float64
+	if err := json.Unmarshal(data, &value); err == nil {
+		*f = flexibleFloat(value)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("expected a number, got %s", data)
+	}
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fmt.Errorf("expected a number, got %q", s)
+	}
+	*f = flexibleFloat(value)
+	return nil
+}
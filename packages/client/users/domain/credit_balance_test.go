@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCreditBalanceUnmarshal(t *testing.T) {
+	tests := []struct {
+		name      string
+		json      string
+		total     float64
+		breakdown bool
+	}{
+		{name: "number", json: `{"credit_balance": 1234.5}`, total: 1234.5},
+		{name: "numeric string", json: `{"credit_balance": "1000"}`, total: 1000},
+		{name: "object", json: `{"credit_balance": {"total": 300, "paid": 200, "free": 100}}`, total: 300, breakdown: true},
+		{name: "object without total", json: `{"credit_balance": {"paid": "200", "bonus": 50}}`, total: 250, breakdown: true},
+		{name: "undocumented fields", json: `{"credit_balance": {"paid": 200, "paid_credits": 200, "balance": 42}}`, total: 200, breakdown: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var me UserMe
+			if err := json.Unmarshal([]byte(tt.json), &me); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if me.CreditBalance == nil || me.CreditBalance.Total != tt.total || me.CreditBalance.HasBreakdown() != tt.breakdown {
+				t.Errorf("CreditBalance = %+v, want total %v (breakdown %v)", me.CreditBalance, tt.total, tt.breakdown)
+			}
+		})
+	}
+
+	var me UserMe
+	if err := json.Unmarshal([]byte(`{"credit_balance": null}`), &me); err != nil || me.CreditBalance != nil {
+		t.Errorf("null balance: %+v, %v", me.CreditBalance, err)
+	}
+	if err := json.Unmarshal([]byte(`{"credit_balance": "lots"}`), &me); err == nil {
+		t.Error("expected a non-numeric balance to fail")
+	}
+}
+
+func TestCreditBalanceExpirations(t *testing.T) {
+	var balance CreditBalance
+	data := `{"total": 100, "expirations": [
+		{"credits": 10, "expires_at": "2026-03-01T00:00:00Z", "kind": "free"},
+		{"credits": 20, "expires_at": "2026-01-01T00:00:00Z", "kind": "bonus"},
+		{"credits": 5, "expires_at": "next month"},
+		{"credits": 5, "expires_at": 1767225600},
+		{"credits": 5},
+		{"credits": 30, "expires_at": "2025-01-01T00:00:00Z"}
+	]}`
+	if err := json.Unmarshal([]byte(data), &balance); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if len(balance.Expirations) != 3 || balance.Expirations[0].Kind != "free" || balance.Expirations[1].Credits != 20 {
+		t.Fatalf("Expirations = %+v", balance.Expirations)
+	}
+
+	next := balance.NextExpiration(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC))
+	if next == nil || next.Credits != 20 || next.Kind != "bonus" {
+		t.Errorf("NextExpiration() = %+v, want the 20 bonus credits", next)
+	}
+	if next := balance.NextExpiration(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)); next != nil {
+		t.Errorf("NextExpiration() after all expirations = %+v", next)
+	}
+}
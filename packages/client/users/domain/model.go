@@ -19,8 +19,8 @@ type User struct {
 // UserMe represents extended user information for /v1/users/me endpoint
 type UserMe struct {
 	User
-	CreditBalance interface{}   `json:"credit_balance,omitempty"` // Can be number or object
-	Settings      *UserSettings `json:"settings,omitempty"`
+	CreditBalance *CreditBalance `json:"credit_balance,omitempty"`
+	Settings      *UserSettings  `json:"settings,omitempty"`
 }
 
 // UserSettings represents user's account settings